@@ -0,0 +1,253 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/bundle"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+type artifactsExportCliOptions struct {
+	Output                         string
+	GreptimeDBChartVersion         string
+	GreptimeDBOperatorChartVersion string
+	ChartRepository                string
+	SkipImages                     bool
+}
+
+type artifactsImportCliOptions struct {
+	Registry string
+}
+
+type artifactsPruneCliOptions struct {
+	Keep int
+}
+
+type artifactsPathCliOptions struct {
+	Name string
+}
+
+func NewArtifactsCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "Manage the artifacts for installing GreptimeDB cluster",
+		Long: `Export and import the charts and images for installing GreptimeDB cluster on Kubernetes without internet access,
+and inspect and clean the binaries and charts downloaded to the cache of gtctl`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewArtifactsExportCommand(l))
+	cmd.AddCommand(NewArtifactsImportCommand(l))
+	cmd.AddCommand(NewArtifactsListCommand(l))
+	cmd.AddCommand(NewArtifactsPruneCommand(l))
+	cmd.AddCommand(NewArtifactsPathCommand(l))
+
+	return cmd
+}
+
+func NewArtifactsExportCommand(l logger.Logger) *cobra.Command {
+	var options artifactsExportCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the charts and images into a bundle",
+		Long: `Export the charts of GreptimeDB cluster, operator and etcd, and the images used by them, into a '.tar.gz' bundle,
+which can be imported by 'gtctl artifacts import' in the environment without internet access`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bundle.Export(context.TODO(), &bundle.ExportOptions{
+				Output:                         options.Output,
+				GreptimeDBChartVersion:         options.GreptimeDBChartVersion,
+				GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
+				ChartRepository:                options.ChartRepository,
+				SkipImages:                     options.SkipImages,
+			}, l)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "gtctl-artifacts.tar.gz", "The path of the bundle.")
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "greptimedb-chart-version", "", "The greptimedb helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "greptimedb-operator-chart-version", "", "The greptimedb-operator helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.ChartRepository, "chart-repo", "", "The private chart repository or OCI registry to download charts from.")
+	cmd.Flags().BoolVar(&options.SkipImages, "skip-images", false, "Export only the charts without the images.")
+
+	return cmd
+}
+
+func NewArtifactsImportCommand(l logger.Logger) *cobra.Command {
+	var options artifactsImportCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import the charts and images from a bundle",
+		Long: `Import the charts of bundle exported by 'gtctl artifacts export' into the cache of gtctl, and load its images
+into the local docker, which are pushed to the private image registry if it's specified`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("bundle should be set")
+			}
+
+			return bundle.Import(context.TODO(), &bundle.ImportOptions{
+				Bundle:   args[0],
+				Registry: options.Registry,
+			}, l)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Registry, "registry", "", "The private image registry to push the images to, e.g. 'registry.example.com'.")
+
+	return cmd
+}
+
+func NewArtifactsListCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the downloaded artifacts",
+		Long:  `List the versions of the binaries and charts downloaded to the cache of gtctl with their sizes`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mm, err := metadata.New("")
+			if err != nil {
+				return err
+			}
+
+			cached, err := mm.ListArtifacts()
+			if err != nil {
+				return fmt.Errorf("error listing the artifacts: %v", err)
+			}
+			if len(cached) == 0 {
+				l.V(0).Infof("No artifacts are downloaded to '%s'.", mm.GetWorkingDir())
+				return nil
+			}
+
+			var total int64
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"NAME", "TYPE", "VERSION", "SIZE", "MODIFIED"})
+			for _, artifact := range cached {
+				total += artifact.Size
+				table.Append([]string{
+					artifact.Name,
+					string(artifact.Type),
+					artifact.Version,
+					fileutils.HumanSize(artifact.Size),
+					artifact.ModTime.Format("2006-01-02 15:04:05"),
+				})
+			}
+			table.SetFooter([]string{"", "", "TOTAL", fileutils.HumanSize(total), ""})
+			table.Render()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewArtifactsPruneCommand(l logger.Logger) *cobra.Command {
+	var options artifactsPruneCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the old versions of the downloaded artifacts",
+		Long: `Remove all but the newest versions of every binary and chart downloaded to the cache of gtctl,
+the binaries used by the clusters are always kept`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.Keep < 0 {
+				return fmt.Errorf("the number of versions to keep should not be negative")
+			}
+
+			mm, err := metadata.New("")
+			if err != nil {
+				return err
+			}
+
+			pruned, err := mm.PruneArtifacts(options.Keep)
+			if err != nil {
+				return fmt.Errorf("error pruning the artifacts: %v", err)
+			}
+
+			var freed int64
+			for _, artifact := range pruned {
+				freed += artifact.Size
+				l.V(0).Infof("Removed %s '%s' %s (%s)", artifact.Type, artifact.Name, artifact.Version, fileutils.HumanSize(artifact.Size))
+			}
+			l.V(0).Infof("Pruned %d artifacts, %s is freed.", len(pruned), fileutils.HumanSize(freed))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&options.Keep, "keep", 3, "The number of the newest versions to keep for every artifact.")
+
+	return cmd
+}
+
+func NewArtifactsPathCommand(l logger.Logger) *cobra.Command {
+	var options artifactsPathCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "path <version>",
+		Short: "Print the paths of the downloaded artifacts of the version",
+		Long:  `Print the directories of the binaries and charts of the version in the cache of gtctl`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mm, err := metadata.New("")
+			if err != nil {
+				return err
+			}
+
+			cached, err := mm.ListArtifacts()
+			if err != nil {
+				return fmt.Errorf("error listing the artifacts: %v", err)
+			}
+
+			found := false
+			for _, artifact := range cached {
+				if artifact.Version != args[0] || (options.Name != "" && artifact.Name != options.Name) {
+					continue
+				}
+				found = true
+				fmt.Println(artifact.Path)
+			}
+			if !found {
+				return fmt.Errorf("no artifacts of version '%s' are downloaded", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Name, "name", "", "Only print the path of the artifact with the name, e.g. 'greptime' and 'greptimedb-cluster'.")
+
+	return cmd
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type benchRunCliOptions struct {
+	Namespace     string
+	Database      string
+	Workload      string
+	Scale         int
+	Duration      time.Duration
+	Workers       int
+	BatchSize     int
+	QueryInterval time.Duration
+	Output        string
+
+	// The options for benchmarking GreptimeDB cluster in bare-metal.
+	BareMetal bool
+}
+
+func NewBenchRunCommand(l logger.Logger) *cobra.Command {
+	var options benchRunCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the TSBS-style workload against a GreptimeDB cluster",
+		Long: `Run the TSBS-style workload against a GreptimeDB cluster through the HTTP API of its frontend: the samples of the
+simulated hosts are written by the InfluxDB line protocol as fast as the cluster accepts them, while the queries of TSBS
+are issued on the written data periodically. The ingest throughput and the latencies of writes and queries are reported
+when the duration elapses or it's interrupted. The written data is left in the database, which can be dropped by
+'gtctl cluster exec <name> --sql "DROP DATABASE <database>"'`,
+		Example: `  gtctl bench run mycluster --workload cpu-only --scale 100 --duration 5m
+  gtctl bench run mycluster --bare-metal --workers 8 --batch-size 5000 -o json`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if err := opt.ValidateOutputFormat(options.Output, opt.OutputFormatTable, opt.OutputFormatJSON, opt.OutputFormatYAML); err != nil {
+				return err
+			}
+			if options.Scale <= 0 || options.Workers <= 0 || options.BatchSize <= 0 {
+				return fmt.Errorf("--scale, --workers and --batch-size should be positive")
+			}
+			if options.Duration <= 0 {
+				return fmt.Errorf("--duration should be positive")
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			benchOptions := &opt.BenchOptions{
+				Namespace: options.Namespace,
+				Name:      clusterName,
+				BenchOptions: connector.BenchOptions{
+					Database:      options.Database,
+					Workload:      options.Workload,
+					Scale:         options.Scale,
+					Duration:      options.Duration,
+					Workers:       options.Workers,
+					BatchSize:     options.BatchSize,
+					QueryInterval: options.QueryInterval,
+				},
+			}
+			if options.QueryInterval == 0 {
+				// Zero disables the queries on the command line.
+				benchOptions.QueryInterval = -1
+			}
+
+			var (
+				report *connector.BenchReport
+				err    error
+			)
+			if options.BareMetal {
+				cluster, cerr := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				if cerr != nil {
+					return cerr
+				}
+				bm, _ := cluster.(*baremetal.Cluster)
+				report, err = bm.Bench(ctx, benchOptions)
+			} else {
+				cluster, cerr := newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				if cerr != nil {
+					return cerr
+				}
+				k8s, _ := cluster.(*kubernetes.Cluster)
+				report, err = k8s.Bench(ctx, benchOptions)
+			}
+			if err != nil {
+				return err
+			}
+
+			if opt.IsMachineReadable(options.Output) {
+				return opt.RenderOutput(os.Stdout, options.Output, report)
+			}
+			renderBenchReport(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVarP(&options.Database, "database", "d", connector.DefaultBenchDatabase, "The database that the workload is written into, it's created if it doesn't exist.")
+	cmd.Flags().StringVar(&options.Workload, "workload", connector.BenchWorkloadCPUOnly, "The workload to generate, only 'cpu-only' is supported now.")
+	cmd.Flags().IntVar(&options.Scale, "scale", connector.DefaultBenchScale, "The number of simulated hosts.")
+	cmd.Flags().DurationVar(&options.Duration, "duration", connector.DefaultBenchDuration, "How long the workload is generated for.")
+	cmd.Flags().IntVar(&options.Workers, "workers", connector.DefaultBenchWorkers, "The number of concurrent writers.")
+	cmd.Flags().IntVar(&options.BatchSize, "batch-size", connector.DefaultBenchBatchSize, "The max number of rows written by one request.")
+	cmd.Flags().DurationVar(&options.QueryInterval, "query-interval", connector.DefaultBenchQueryInterval, "The interval between the queries issued during the ingestion, 0 disables the queries.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of report, can be 'table', 'json' and 'yaml'.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Benchmark the greptimedb cluster on bare-metal environment.")
+
+	return cmd
+}
+
+// renderBenchReport prints the ingest throughput, followed by the latencies of writes and queries in the table.
+func renderBenchReport(report *connector.BenchReport) {
+	fmt.Printf("Workload:     %s (%d hosts)\n", report.Workload, report.Scale)
+	fmt.Printf("Duration:     %s\n", (time.Duration(report.DurationSeconds * float64(time.Second))).Truncate(time.Millisecond))
+	fmt.Printf("Rows:         %d (%.1f rows/s)\n", report.Rows, report.RowsPerSecond)
+	fmt.Printf("Metrics:      %d (%.1f metrics/s)\n", report.Metrics, report.MetricsPerSecond)
+	fmt.Printf("Write errors: %d\n\n", report.WriteErrors)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+
+	milliseconds := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) + "ms" }
+	table.SetHeader([]string{"Request", "Count", "Errors", "Mean", "P50", "P95", "P99", "Max"})
+	for _, latency := range append([]*connector.BenchLatency{report.Write}, report.Queries...) {
+		table.Append([]string{
+			latency.Name,
+			strconv.Itoa(latency.Count),
+			strconv.Itoa(latency.Errors),
+			milliseconds(latency.Mean),
+			milliseconds(latency.P50),
+			milliseconds(latency.P95),
+			milliseconds(latency.P99),
+			milliseconds(latency.Max),
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type chaosCliOptions struct {
+	ComponentType string
+	Index         int
+}
+
+func NewChaosCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "chaos",
+		Short: "Inject faults into GreptimeDB cluster",
+		Long: `Inject faults into the replicas of GreptimeDB cluster in bare-metal mode, so the failover of cluster can be
+exercised with the same tool that deployed it. Use the chaos tools of Kubernetes, e.g. Chaos Mesh, for the clusters on it`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(newChaosActionCommand(l, opt.ChaosKill,
+		"Kill the replicas of component",
+		`Kill the processes of replicas by SIGKILL without letting them exit gracefully, which simulates their crashes.
+They are restarted by gtctl if the cluster is created with the restart policy`))
+	cmd.AddCommand(newChaosActionCommand(l, opt.ChaosPause,
+		"Pause the replicas of component",
+		`Pause the processes of replicas by SIGSTOP, so they are alive but unresponsive, e.g. like in a long GC pause,
+until they are resumed by 'gtctl chaos resume'`))
+	cmd.AddCommand(newChaosActionCommand(l, opt.ChaosResume,
+		"Resume the paused replicas of component",
+		`Resume the processes of replicas paused by 'gtctl chaos pause' by SIGCONT`))
+	cmd.AddCommand(newChaosActionCommand(l, opt.ChaosPartition,
+		"Partition the replicas of component from the network",
+		`Partition the replicas from the network by iptables on Linux until they are healed by 'gtctl chaos heal': the
+packets to the ports they listen on are dropped, and so are the packets sent by them, which are matched by the
+cgroup v2 that they are moved into. It requires the root privilege, run gtctl by 'sudo -E' to keep its working dir`))
+	cmd.AddCommand(newChaosActionCommand(l, opt.ChaosHeal,
+		"Heal the partitioned replicas of component",
+		`Remove the iptables rules of partitioning the replicas by 'gtctl chaos partition', and move them back to their
+original cgroups. It requires the root privilege, run gtctl by 'sudo -E' to keep its working dir`))
+
+	return cmd
+}
+
+// newChaosActionCommand returns the command that takes the chaos action on the replicas of bare-metal cluster.
+func newChaosActionCommand(l logger.Logger, action, short, long string) *cobra.Command {
+	var options chaosCliOptions
+
+	cmd := &cobra.Command{
+		Use:               action,
+		Short:             short,
+		Long:              long,
+		Example:           fmt.Sprintf("  gtctl chaos %s mycluster -c datanode --index 1", action),
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if len(options.ComponentType) == 0 {
+				return fmt.Errorf("component type is required")
+			}
+
+			clusterName := args[0]
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+			bm, _ := cluster.(*baremetal.Cluster)
+
+			return bm.Chaos(context.Background(), &opt.ChaosOptions{
+				Name:          clusterName,
+				ComponentType: greptimedbclusterv1alpha1.ComponentKind(options.ComponentType),
+				Index:         options.Index,
+				Action:        action,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.ComponentType, "component", "c", "", "Component of GreptimeDB cluster, can be 'frontend', 'datanode', 'meta', 'flownode' and the datanode groups.")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("component", completeComponents(true)))
+	cmd.Flags().IntVar(&options.Index, "index", -1, "The index of the replica of component, all the replicas if it's negative.")
+
+	return cmd
+}
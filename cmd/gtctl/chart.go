@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type chartListVersionsCliOptions struct {
+	Chart           string
+	ChartRepository string
+	Limit           int
+}
+
+func NewChartCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "chart",
+		Short: "Inspect the helm charts for installing GreptimeDB cluster",
+		Long:  `Inspect the helm charts of GreptimeDB cluster, operator and etcd, so the chart versions can be pinned for reproducible deployments`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewChartListVersionsCommand(l))
+
+	return cmd
+}
+
+func NewChartListVersionsCommand(l logger.Logger) *cobra.Command {
+	var options chartListVersionsCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "list-versions",
+		Short: "List the versions of the chart",
+		Long:  `List the versions of the chart in the chart index, the latest version comes first`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			am, err := artifacts.NewManager(l, artifacts.WithChartRepository(options.ChartRepository))
+			if err != nil {
+				return err
+			}
+
+			versions, err := am.ListChartVersions(context.TODO(), options.Chart)
+			if err != nil {
+				return fmt.Errorf("error listing versions of chart '%s': %v", options.Chart, err)
+			}
+			if options.Limit > 0 && len(versions) > options.Limit {
+				versions = versions[:options.Limit]
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"VERSION", "APP-VERSION", "CREATED"})
+			for _, version := range versions {
+				created := ""
+				if !version.Created.IsZero() {
+					created = version.Created.Format("2006-01-02 15:04:05")
+				}
+				table.Append([]string{version.Version, version.AppVersion, created})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Chart, "chart", artifacts.GreptimeDBClusterChartName, "The name of the chart, e.g. 'greptimedb-cluster' and 'greptimedb-operator'.")
+	cmd.Flags().StringVar(&options.ChartRepository, "chart-repo", "", "The private chart repository to list the chart versions from, the OCI registry is not supported.")
+	cmd.Flags().IntVar(&options.Limit, "limit", 0, "The maximum number of the latest versions to list, 0 means no limit.")
+
+	return cmd
+}
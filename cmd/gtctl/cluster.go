@@ -45,6 +45,27 @@ func NewClusterCommand(l logger.Logger) *cobra.Command {
 	cmd.AddCommand(NewGetClusterCommand(l))
 	cmd.AddCommand(NewListClustersCommand(l))
 	cmd.AddCommand(NewConnectCommand(l))
+	cmd.AddCommand(NewRestartClusterCommand(l))
+	cmd.AddCommand(NewCertsCommand(l))
+	cmd.AddCommand(NewLogsCommand(l))
+	cmd.AddCommand(NewStatusCommand(l))
+	cmd.AddCommand(NewTopCommand(l))
+	cmd.AddCommand(NewStartClusterCommand(l))
+	cmd.AddCommand(NewStopClusterCommand(l))
+	cmd.AddCommand(NewUpgradeClusterCommand(l))
+	cmd.AddCommand(NewApplyClusterCommand(l))
+	cmd.AddCommand(NewAdoptClusterCommand(l))
+	cmd.AddCommand(NewBackupClusterCommand(l))
+	cmd.AddCommand(NewRestoreClusterCommand(l))
+	cmd.AddCommand(NewConfigCommand(l))
+	cmd.AddCommand(NewDiagnoseClusterCommand(l))
+	cmd.AddCommand(NewDoctorCommand(l))
+	cmd.AddCommand(NewExecCommand(l))
+	cmd.AddCommand(NewSeedCommand(l))
+	cmd.AddCommand(NewMonitorCommand(l))
+	cmd.AddCommand(NewPortForwardCommand(l))
+	cmd.AddCommand(NewExportClusterCommand(l))
+	cmd.AddCommand(NewHistoryCommand(l))
 
 	return cmd
 }
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+type clusterAdoptCliOptions struct {
+	Namespace string
+}
+
+func NewAdoptClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterAdoptCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "adopt",
+		Short: "Adopt a GreptimeDB cluster on Kubernetes that is not created by gtctl",
+		Long: `Adopt a GreptimeDBCluster or GreptimeDBStandalone on Kubernetes that is deployed by helm or GitOps and reconciled by greptimedb-operator.
+Nothing is changed on Kubernetes, the cluster is registered with the kubeconfig context it's found in, so the commands like get, status, connect and scale
+work on it without recreating anything.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			clusterName := args[0]
+
+			mm, err := metadata.New("")
+			if err != nil {
+				return err
+			}
+			recorded, err := mm.GetKubeContext(options.Namespace, clusterName)
+			if err != nil {
+				return err
+			}
+			if recorded != nil && !recorded.Adopted {
+				return fmt.Errorf("cluster '%s' in namespace '%s' is created by gtctl, it doesn't need to be adopted", clusterName, options.Namespace)
+			}
+
+			kubeconfig, kubeContext := kubeConfigFlags(cmd)
+			cluster, err := kubernetes.NewCluster(l, kubernetes.WithKubeConfig(kubeconfig, kubeContext))
+			if err != nil {
+				return err
+			}
+			k8s, _ := cluster.(*kubernetes.Cluster)
+			adopted, err := k8s.Adopt(context.Background(), &opt.AdoptOptions{Namespace: options.Namespace, Name: clusterName})
+			if err != nil {
+				return err
+			}
+
+			current, err := kube.CurrentContext(kubeconfig, kubeContext)
+			if err != nil {
+				return err
+			}
+			if err = mm.RecordKubeContext(options.Namespace, clusterName, &metadata.KubeContext{
+				Kubeconfig: kubeconfig,
+				Context:    current,
+				Adopted:    true,
+				ManagedBy:  adopted.ManagedBy,
+			}); err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Cluster '%s' in namespace '%s' is adopted from context '%s'!", logger.Bold(clusterName), options.Namespace, current)
+			l.V(0).Infof("  Phase: %s, version: %s", adopted.Phase, adopted.Version)
+			if len(adopted.ManagedBy) > 0 {
+				if len(adopted.Release) > 0 {
+					l.V(0).Infof("  Managed by: %s (%s)", adopted.ManagedBy, adopted.Release)
+				} else {
+					l.V(0).Infof("  Managed by: %s", adopted.ManagedBy)
+				}
+				l.Warnf("The changes made by gtctl may be reverted by %s, the cluster should be changed through %s in the long run.",
+					adopted.ManagedBy, adopted.ManagedBy)
+			}
+			l.V(0).Infof("\nOperate the cluster by 'gtctl cluster get|status|connect|scale %s -n %s'.", clusterName, options.Namespace)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+
+	return cmd
+}
+
+// warnAdoptedCluster warns that the deleted cluster may be recreated if it's adopted from the tool that deploys it.
+func warnAdoptedCluster(l logger.Logger, namespace, name string) {
+	mm, err := metadata.New("")
+	if err != nil {
+		return
+	}
+	recorded, err := mm.GetKubeContext(namespace, name)
+	if err != nil || recorded == nil || !recorded.Adopted || len(recorded.ManagedBy) == 0 {
+		return
+	}
+	l.Warnf("Cluster '%s' is adopted from %s, it may be recreated by %s unless it's removed from there as well.",
+		name, recorded.ManagedBy, recorded.ManagedBy)
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterApplyCliOptions struct {
+	Config  string
+	Profile string
+	Set     []string
+	Vars    map[string]string
+
+	DryRun                 bool
+	Yes                    bool
+	Timeout                int
+	EnableCache            bool
+	UseGreptimeCNArtifacts bool
+	DrainTimeout           int
+	EventSinks             []string
+}
+
+func NewApplyClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterApplyCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconcile a running GreptimeDB cluster to the edited config",
+		Long: `Reconcile a running GreptimeDB cluster in bare-metal mode to the edited config. The config is diffed against the recorded config of the cluster,
+and the plan is printed before only the necessary changes are made: the greptime version is upgraded, the components whose configs are changed are restarted,
+and the components whose replicas are changed are scaled.`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if len(options.Config) == 0 {
+				return fmt.Errorf("the config file is required")
+			}
+
+			var (
+				ctx         = context.Background()
+				cancel      context.CancelFunc
+				clusterName = args[0]
+			)
+			defer recordAudit(cmd, l, audit.OperationApply, "", clusterName, time.Now(), &err)
+
+			if options.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+				defer cancel()
+			}
+
+			base, err := os.ReadFile(options.Config)
+			if err != nil {
+				return err
+			}
+			desired, err := config.ValidateBareMetalConfig(base, options.Profile, options.Set, options.Vars)
+			if err != nil {
+				return err
+			}
+
+			bus, err := newEventBus(l, options.EventSinks, "", clusterName)
+			if err != nil {
+				return err
+			}
+			defer bus.Close()
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(),
+				baremetal.WithEnableCache(options.EnableCache),
+				baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second), baremetal.WithEvents(bus))
+			if err != nil {
+				return err
+			}
+			bm, _ := cluster.(*baremetal.Cluster)
+
+			applyOptions := &opt.ApplyOptions{
+				Name:                   clusterName,
+				Config:                 desired,
+				UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+			}
+			plan, err := bm.PlanApply(ctx, applyOptions)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), plan.String())
+			if plan.Empty() || options.DryRun {
+				return nil
+			}
+
+			if !options.Yes {
+				p := &prompter{in: bufio.NewReader(os.Stdin), out: cmd.OutOrStdout()}
+				confirmed, err := p.confirm(fmt.Sprintf("\nApply the changes to cluster '%s'?", clusterName), false)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					l.V(0).Infof("The changes are not applied.")
+					return nil
+				}
+			}
+
+			return bm.Apply(ctx, applyOptions, plan)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Config, "file", "f", "", "The edited config file of the cluster in bare-metal mode.")
+	cmd.Flags().StringVar(&options.Profile, "profile", "", fmt.Sprintf("The profile applied onto the config, one of: %s.", strings.Join(config.BareMetalProfiles(), ", ")))
+	cmd.Flags().StringArrayVar(&options.Set, "set", []string{}, "Set values of the config on the command line (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringToStringVar(&options.Vars, "var", nil, "The variables to expand in the config, e.g. --var VERSION=latest for '${VERSION}'.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Only print the plan without applying it.")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "Apply the plan without confirmation.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", -1, "Timeout in seconds for the command to complete, -1 means no timeout.")
+	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(binaries).")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries).")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting the components to exit gracefully before killing them.")
+	addEventSinkFlag(cmd, &options.EventSinks)
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterBackupCliOptions struct {
+	Output string
+}
+
+func NewBackupClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterBackupCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the data of GreptimeDB cluster",
+		Long: `Back up the metadata and the data directories of a GreptimeDB cluster in bare-metal mode into a '.tar.gz' or '.tar.zst' archive.
+If the cluster is running, its replicas are paused by SIGSTOP until the archive is written, so the backup is consistent.
+The paused replicas can't serve any requests in the meantime, resume them by 'gtctl chaos resume' if the backup is interrupted.`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			output := options.Output
+			if len(output) == 0 {
+				output = fmt.Sprintf("%s.tar.gz", clusterName)
+			}
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.Backup(context.TODO(), &opt.BackupOptions{
+				Name:   clusterName,
+				Output: output,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The path of the backup archive, must end with '.tar.gz' or '.tar.zst', default is '<cluster name>.tar.gz'.")
+
+	return cmd
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/certs"
+)
+
+type clusterCertsGenerateOptions struct {
+	Hosts []string
+	Force bool
+}
+
+func NewCertsCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "Manage the TLS certificates of GreptimeDB cluster",
+		Long:  `Manage the TLS certificates of GreptimeDB cluster in bare-metal mode`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewGenerateCertsCommand(l))
+
+	return cmd
+}
+
+func NewGenerateCertsCommand(l logger.Logger) *cobra.Command {
+	var options clusterCertsGenerateOptions
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate self-signed certificates for GreptimeDB cluster",
+		Long: `Generate a self-signed CA and a server certificate signed by it into the data directory of
+the bare-metal cluster, which are used by the MySQL, Postgres and gRPC servers of frontend with
+'tls.autoGenerate' enabled. The HTTP server of frontend doesn't support TLS and serves plain HTTP`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			mm, err := metadata.New("")
+			if err != nil {
+				return err
+			}
+			mm.AllocateClusterScopeDirs(args[0])
+			csd := mm.GetClusterScopeDirs()
+
+			dir := components.CertsDir(components.WorkingDirs{DataDir: csd.DataDir})
+			exists, err := certs.Exists(dir)
+			if err != nil {
+				return err
+			}
+			if exists && !options.Force {
+				return fmt.Errorf("certificates already exist in %s, use '--force' to overwrite them", dir)
+			}
+
+			hosts := options.Hosts
+			if len(hosts) == 0 {
+				hosts = components.CertHosts(nil)
+			}
+			paths, err := certs.Generate(dir, hosts)
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Generated self-signed certificates for '%v' in %s", hosts, logger.Bold(dir))
+			l.V(0).Infof("The clients can verify the servers with CA certificate: %s", logger.Bold(paths.CACert))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.Hosts, "hosts", nil, "The hosts that the server certificate is issued for, use 'localhost', '127.0.0.1' and the hostname if not specified.")
+	cmd.Flags().BoolVar(&options.Force, "force", false, "Overwrite the existing certificates.")
+
+	return cmd
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterConfigValidateCliOptions struct {
+	File    string
+	Profile string
+	Vars    map[string]string
+	Set     []string
+}
+
+func NewConfigCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration of GreptimeDB cluster",
+		Long:  `Manage the configuration of GreptimeDB cluster in bare-metal mode`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewValidateConfigCommand(l))
+
+	return cmd
+}
+
+func NewValidateConfigCommand(l logger.Logger) *cobra.Command {
+	var options clusterConfigValidateCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration of GreptimeDB cluster",
+		Long: `Validate the configuration of GreptimeDB cluster in bare-metal mode, all the problems are reported at once
+with the lines of config file, including the unsupported fields, the invalid values, the missing files and the duplicated ports`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(options.File) == 0 {
+				return fmt.Errorf("config file should be set by '-f'")
+			}
+
+			raw, err := os.ReadFile(options.File)
+			if err != nil {
+				return err
+			}
+
+			if _, err = config.ValidateBareMetalConfig(raw, options.Profile, options.Set, options.Vars); err != nil {
+				return fmt.Errorf("invalid config '%s': %v", options.File, err)
+			}
+
+			l.V(0).Infof("Config '%s' is valid", options.File)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The configuration file to validate.")
+	cmd.Flags().StringVar(&options.Profile, "profile", "", fmt.Sprintf("The profile applied onto the configuration, one of: %s.", strings.Join(config.BareMetalProfiles(), ", ")))
+	cmd.Flags().StringToStringVar(&options.Vars, "var", nil, "The variables to expand in the configuration, e.g. --var VERSION=latest for '${VERSION}'.")
+	cmd.Flags().StringArrayVar(&options.Set, "set", []string{}, "Set values onto the configuration (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+
+	return cmd
+}
@@ -23,13 +23,17 @@ import (
 	"github.com/spf13/cobra"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
-	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
 type clusterConnectCliOptions struct {
 	Namespace string
 	Protocol  string
+	Database  string
+
+	// The options for connecting to GreptimeDB cluster in bare-metal.
+	BareMetal bool
 }
 
 func NewConnectCommand(l logger.Logger) *cobra.Command {
@@ -38,7 +42,11 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "connect",
 		Short: "Connect to a GreptimeDB cluster",
-		Long:  `Connect to a GreptimeDB cluster`,
+		Long: `Connect to a GreptimeDB cluster by the mysql or psql client, the built-in SQL shell on the HTTP API,
+or expose the gRPC endpoint for the GreptimeDB clients. The built-in SQL shell is used if the mysql or psql
+client is not installed. The address of the first running frontend replica is discovered from the cluster
+in bare-metal mode`,
+		ValidArgsFunction: completeClusterNames(false),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
@@ -48,9 +56,18 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 				ctx         = context.TODO()
 				clusterName = args[0]
 				protocol    opt.ConnectProtocol
+				cluster     opt.Deployer
+				err         error
 			)
 
-			cluster, err := kubernetes.NewCluster(l)
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				}
+			}
 			if err != nil {
 				return err
 			}
@@ -60,6 +77,10 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 				protocol = opt.MySQL
 			case "pg", "psql", "postgres":
 				protocol = opt.Postgres
+			case "http":
+				protocol = opt.HTTP
+			case "grpc":
+				protocol = opt.GRPC
 			default:
 				return fmt.Errorf("unsupported connection protocol: %s", options.Protocol)
 			}
@@ -67,6 +88,7 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 				Namespace: options.Namespace,
 				Name:      clusterName,
 				Protocol:  protocol,
+				Database:  options.Database,
 			}
 
 			return cluster.Connect(ctx, connectOptions)
@@ -74,7 +96,10 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
-	cmd.Flags().StringVarP(&options.Protocol, "protocol", "p", "mysql", "Specify a database protocol, like mysql or pg.")
+	cmd.Flags().StringVarP(&options.Protocol, "protocol", "p", "mysql", "Specify a database protocol, like mysql, pg, http or grpc.")
+	cmd.Flags().StringVarP(&options.Database, "database", "d", "public", "The database that the statements are executed in by the http protocol.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Connect to the greptimedb cluster on bare-metal environment.")
+	addDeployerFlag(cmd)
 
 	return cmd
 }
@@ -19,18 +19,29 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 	"github.com/GreptimeTeam/gtctl/pkg/status"
 )
@@ -39,6 +50,7 @@ type clusterCreateCliOptions struct {
 	// The options for deploying GreptimeDBCluster in K8s.
 	Namespace                      string
 	OperatorNamespace              string
+	OperatorWatchNamespace         string
 	EtcdNamespace                  string
 	StorageClassName               string
 	StorageSize                    string
@@ -46,11 +58,35 @@ type clusterCreateCliOptions struct {
 	GreptimeDBChartVersion         string
 	GreptimeDBOperatorChartVersion string
 	ImageRegistry                  string
+	ImagePullSecrets               []string
+	ChartRepository                string
 	EtcdEndpoint                   string
 	EtcdChartVersion               string
 	EtcdStorageClassName           string
 	EtcdStorageSize                string
 	EtcdClusterSize                string
+	FlownodeReplicas               int
+	EnableMonitoring               bool
+	StandaloneImage                string
+
+	// The kubeconfig and context set by the global flags.
+	Kubeconfig  string
+	KubeContext string
+
+	// The remote WAL of cluster in K8s.
+	EnableRemoteWAL   bool
+	KafkaEndpoints    []string
+	DeployKafka       bool
+	KafkaChartVersion string
+
+	// The object storage that the datanodes store data in on K8s.
+	ObjectStorage config.ObjectStorage
+
+	// The resources of components in K8s.
+	FrontendResources componentResources
+	DatanodeResources componentResources
+	MetaResources     componentResources
+	FlownodeResources componentResources
 
 	// Values files that set in command line.
 	GreptimeDBClusterValuesFile  string
@@ -60,20 +96,89 @@ type clusterCreateCliOptions struct {
 	// The options for deploying GreptimeDBCluster in bare-metal.
 	BareMetal          bool
 	Config             string
+	Profile            string
+	Vars               map[string]string
 	GreptimeBinVersion string
+	GreptimeBuild      string
+	GreptimeSource     string
+	SourceProfile      string
+	SourceFeatures     []string
 	EnableCache        bool
+	SkipVerify         bool
+	ArtifactPlatform   string
 	UseMemoryMeta      bool
+	DrainTimeout       int
+	FollowLogs         bool
+	Standalone         bool
+	Detach             bool
+	Resume             bool
+	KeepOnFailure      bool
+
+	// Docker runs the cluster in the docker containers with the same config as bare-metal.
+	Docker bool
+
+	// Deployer creates the cluster by the custom deployer of plugin.
+	Deployer string
+
+	// EventSinks are where the lifecycle events of cluster are published to.
+	EventSinks []string
 
 	// Common options.
 	Timeout int
 	DryRun  bool
 	Set     config.SetValues
 
+	// Interactive walks the user through the options of creating the cluster.
+	Interactive bool
+
+	// The render-only mode of dry-run on Kubernetes, which outputs the manifests to stdout or the directory.
+	Output    string
+	OutputDir string
+
+	// The seed data that is loaded once the cluster is healthy.
+	InitSQL      string
+	InitData     string
+	InitTable    string
+	InitDatabase string
+
+	// WaitForSQL verifies that the cluster serves SQL before the creation succeeds.
+	WaitForSQL        bool
+	SQLSmokeTest      bool
+	WaitForSQLTimeout int
+
 	// If UseGreptimeCNArtifacts is true, the creation will download the artifacts(charts and binaries) from 'downloads.greptime.cn'.
 	// Also, it will use ACR registry for charts images.
 	UseGreptimeCNArtifacts bool
 }
 
+// componentResources is the cpu and memory of one component in K8s, which are both its requests and limits.
+type componentResources struct {
+	CPU    string
+	Memory string
+}
+
+func (r *componentResources) addFlags(cmd *cobra.Command, component string) {
+	cmd.Flags().StringVar(&r.CPU, component+"-cpu", "", fmt.Sprintf("The cpu requests and limits of %s, e.g. 500m, which override the ones in values file.", component))
+	cmd.Flags().StringVar(&r.Memory, component+"-memory", "", fmt.Sprintf("The memory requests and limits of %s, e.g. 1Gi, which override the ones in values file.", component))
+}
+
+func (r *componentResources) isSet() bool {
+	return len(r.CPU) > 0 || len(r.Memory) > 0
+}
+
+// validate validates the cpu and memory are valid quantities.
+func (r *componentResources) validate() error {
+	for _, quantity := range []string{r.CPU, r.Memory} {
+		if len(quantity) == 0 {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return fmt.Errorf("invalid resource quantity '%s': %v", quantity, err)
+		}
+	}
+	return nil
+}
+
 func NewCreateClusterCommand(l logger.Logger) *cobra.Command {
 	var options clusterCreateCliOptions
 
@@ -81,36 +186,106 @@ func NewCreateClusterCommand(l logger.Logger) *cobra.Command {
 		Use:   "create",
 		Short: "Create a GreptimeDB cluster",
 		Long:  `Create a GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			options.Kubeconfig, options.KubeContext = kubeConfigFlags(cmd)
+			options.Deployer, _ = cmd.Flags().GetString(deployerFlag)
+			if options.Interactive {
+				if len(options.Config) > 0 || options.Docker {
+					return fmt.Errorf("--interactive can't be set with --config and --docker")
+				}
+
+				// The prompts go to stderr, so they are not mixed with the output of created cluster.
+				name, apply, err := runCreateWizard(os.Stdin, os.Stderr, args, &options)
+				if err != nil || !apply {
+					return err
+				}
+				args = []string{name}
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			defer recordAudit(cmd, l, audit.OperationCreate, options.Namespace, args[0], time.Now(), &err)
 			return NewCluster(args, &options, l)
 		},
 	}
 
 	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of deploying greptimedb-operator.")
+	cmd.Flags().StringVar(&options.OperatorWatchNamespace, "watch-namespace", "", "Restrict greptimedb-operator to watch only the namespace with the namespace-scoped RBAC rules, its CRDs should be installed in advance.")
 	cmd.Flags().StringVar(&options.StorageClassName, "storage-class-name", "null", "Datanode storage class name.")
+	cmd.Flags().StringVar(&options.StorageClassName, "storage-class", "null", "Datanode storage class name, the alias of '--storage-class-name'.")
 	cmd.Flags().StringVar(&options.StorageSize, "storage-size", "10Gi", "Datanode persistent volume size.")
 	cmd.Flags().StringVar(&options.StorageRetainPolicy, "retain-policy", "Retain", "Datanode pvc retain policy.")
+	cmd.Flags().StringVar(&options.StorageRetainPolicy, "storage-retain-policy", "Retain", "Datanode pvc retain policy, can be 'Retain' and 'Delete', the alias of '--retain-policy'.")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
-	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Output the manifests without applying them.")
+	cmd.Flags().BoolVarP(&options.Interactive, "interactive", "i", false, "Walk through the deployment mode, version, replicas and storage of the cluster interactively, the resulting config is written to a file before creating the cluster.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Output the manifests without applying them, or the commands, directories and files of creating the bare-metal cluster without running and creating them.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "Output the created cluster in the format like 'gtctl cluster get', can be 'json' and 'yaml', or only the manifests in 'yaml' with '--dry-run' on Kubernetes. The logs are written to stderr.")
+	cmd.Flags().StringVar(&options.OutputDir, "output-dir", "", "Render the manifests with '--dry-run' on Kubernetes into the directory, one file per component, e.g. for committing them to a GitOps repository.")
 	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for the command to complete, -1 means no timeout, default is 10 min.")
-	cmd.Flags().StringArrayVar(&options.Set.RawConfig, "set", []string{}, "set values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringArrayVar(&options.Set.RawConfig, "set", []string{}, "set values on the command line for greptimedb cluster, etcd and operator, or the configuration in bare-metal mode (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringArrayVar(&options.Set.ValuesFiles, "values", []string{}, "The values files deep-merged in order into the values of greptimedb cluster, etcd and operator, whose top-level keys 'cluster', 'etcd' and 'operator' are the values of each chart, the other keys are the values of cluster (can specify multiple).")
 	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "greptimedb-chart-version", "", "The greptimedb helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "chart-version", "", "The greptimedb helm chart version to pin, the alias of '--greptimedb-chart-version', see 'gtctl chart list-versions'.")
 	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "greptimedb-operator-chart-version", "", "The greptimedb-operator helm chart version, use latest version if not specified.")
 	cmd.Flags().StringVar(&options.EtcdChartVersion, "etcd-chart-version", "", "The greptimedb-etcd helm chart version, use latest version if not specified.")
 	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().StringArrayVar(&options.ImagePullSecrets, "image-pull-secret", []string{}, "The secret to pull images from the private image registry (can specify multiple).")
+	cmd.Flags().StringVar(&options.ChartRepository, "chart-repo", "", "The private chart repository or OCI registry to download charts from, e.g. 'https://charts.example.com' or 'oci://registry.example.com/charts'.")
 	cmd.Flags().StringVar(&options.EtcdNamespace, "etcd-namespace", "default", "The namespace of etcd cluster.")
 	cmd.Flags().StringVar(&options.EtcdStorageClassName, "etcd-storage-class-name", "null", "The etcd storage class name.")
 	cmd.Flags().StringVar(&options.EtcdStorageSize, "etcd-storage-size", "10Gi", "the etcd persistent volume size.")
 	cmd.Flags().StringVar(&options.EtcdClusterSize, "etcd-cluster-size", "1", "the etcd cluster size.")
+	cmd.Flags().IntVar(&options.FlownodeReplicas, "flownode-replicas", 0, "The replicas of flownode, the flownode is not deployed if it's 0.")
+	cmd.Flags().BoolVar(&options.EnableMonitoring, "enable-monitoring", false, "Deploy the self-monitoring of cluster with the Grafana dashboards, which collects the metrics and logs of all components.")
+	cmd.Flags().StringVar(&options.ObjectStorage.Type, "object-storage-type", "", "The type of object storage that the datanodes store data in on Kubernetes, can be 's3', 'oss', 'gcs' and 'azblob'.")
+	cmd.Flags().StringVar(&options.ObjectStorage.Bucket, "object-storage-bucket", "", "The bucket of object storage, or the container for azblob.")
+	cmd.Flags().StringVar(&options.ObjectStorage.Root, "object-storage-root", "", "The root path of data in the bucket of object storage.")
+	cmd.Flags().StringVar(&options.ObjectStorage.Region, "object-storage-region", "", "The region of object storage.")
+	cmd.Flags().StringVar(&options.ObjectStorage.Endpoint, "object-storage-endpoint", "", "The endpoint of object storage, e.g. 'https://s3.us-west-2.amazonaws.com'.")
+	cmd.Flags().StringVar(&options.ObjectStorage.CredentialsFile, "object-storage-credentials-file", "", "The credentials file of object storage, which is the service account key file for gcs, or a YAML file of 'accessKeyID' and 'secretAccessKey' (s3 and oss), 'accountName' and 'accountKey' (azblob). It's stored in a secret of the cluster.")
+	cmd.Flags().BoolVar(&options.EnableRemoteWAL, "enable-remote-wal", false, "Use kafka as the remote WAL of datanodes on Kubernetes, the kafka is set by '--kafka-endpoints' or deployed by '--deploy-kafka'.")
+	cmd.Flags().StringSliceVar(&options.KafkaEndpoints, "kafka-endpoints", nil, "The broker endpoints of the kafka used as the remote WAL, e.g. 'kafka.default.svc.cluster.local:9092'.")
+	cmd.Flags().BoolVar(&options.DeployKafka, "deploy-kafka", false, "Deploy a single node kafka by chart in the namespace of cluster as its remote WAL, which implies '--enable-remote-wal'.")
+	cmd.Flags().StringVar(&options.KafkaChartVersion, "kafka-chart-version", artifacts.DefaultKafkaChartVersion, "The kafka helm chart version that '--deploy-kafka' deploys.")
+	options.FrontendResources.addFlags(cmd, "frontend")
+	options.DatanodeResources.addFlags(cmd, "datanode")
+	options.MetaResources.addFlags(cmd, "meta")
+	options.FlownodeResources.addFlags(cmd, "flownode")
 	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Deploy the greptimedb cluster on bare-metal environment.")
-	cmd.Flags().StringVar(&options.GreptimeBinVersion, "greptime-bin-version", "", "The version of greptime binary(can be override by config file).")
+	addDeployerFlag(cmd)
+	addEventSinkFlag(cmd, &options.EventSinks)
+	cmd.Flags().BoolVar(&options.Docker, "docker", false, "Run the greptimedb cluster in docker containers by docker compose with the same configuration as bare-metal mode, '--dry-run' outputs the compose file.")
+	cmd.Flags().StringVar(&options.GreptimeBinVersion, "greptime-bin-version", "", "The version of greptime binary(can be override by config file), 'nightly' for the latest nightly build.")
+	cmd.Flags().StringVar(&options.GreptimeBinVersion, "use-greptime-version", "", "The version of greptime binary, the alias of '--greptime-bin-version'.")
+	cmd.Flags().StringVar(&options.GreptimeBuild, "use-greptime-build", "", fmt.Sprintf("The commit whose greptime binary built by the CI is used in bare-metal mode, it's downloaded from GitHub Actions with the token of %s.", artifacts.GitHubTokenEnvKey))
 	cmd.Flags().StringVar(&options.Config, "config", "", "Configuration to deploy the greptimedb cluster on bare-metal environment.")
+	cmd.Flags().StringVar(&options.Profile, "profile", "", fmt.Sprintf("The profile applied onto the configuration in bare-metal mode, one of: %s.", strings.Join(config.BareMetalProfiles(), ", ")))
+	cmd.Flags().StringToStringVar(&options.Vars, "var", nil, "The variables to expand in the configuration in bare-metal mode, e.g. --var VERSION=latest for '${VERSION}'.")
 	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(charts and binaries).")
+	cmd.Flags().StringVar(&options.GreptimeSource, "use-greptime-source", "", "The greptimedb source tree that greptime is built from by cargo in bare-metal mode, the built binary is cached by the commit.")
+	cmd.Flags().StringVar(&options.SourceProfile, "greptime-source-profile", artifacts.DefaultCargoProfile, "The cargo profile to build greptime from source with.")
+	cmd.Flags().StringSliceVar(&options.SourceFeatures, "greptime-source-features", nil, "The cargo features to build greptime from source with.")
+	cmd.Flags().BoolVar(&options.SkipVerify, "skip-verify", false, "Skip verifying the sha256 checksums and signatures of the downloaded binaries in bare-metal mode, e.g. for the air-gapped mirrors that don't publish them.")
+	cmd.Flags().StringVar(&options.ArtifactPlatform, "artifact-platform", "", "The platform that the binaries are downloaded for in bare-metal mode instead of the current one, e.g. 'linux/arm64' to provision the hosts of a different architecture.")
 	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and binaries).")
 	cmd.Flags().StringVar(&options.GreptimeDBClusterValuesFile, "greptimedb-cluster-values-file", "", "The values file for greptimedb cluster.")
 	cmd.Flags().StringVar(&options.EtcdClusterValuesFile, "etcd-cluster-values-file", "", "The values file for etcd cluster.")
 	cmd.Flags().StringVar(&options.GreptimeDBOperatorValuesFile, "greptimedb-operator-values-file", "", "The values file for greptimedb operator.")
 	cmd.Flags().BoolVar(&options.UseMemoryMeta, "use-memory-meta", false, "Bootstrap the whole cluster without installing etcd for testing purposes through using the memory storage of metasrv in bare-metal mode.")
+	cmd.Flags().BoolVar(&options.Standalone, "standalone", false, "Run a single GreptimeDB standalone instead of the distributed components, which is a GreptimeDBStandalone on Kubernetes using the storage of '--storage-class-name' and '--storage-size'.")
+	cmd.Flags().StringVar(&options.StandaloneImage, "standalone-image", kubernetes.DefaultStandaloneImage, "The image of the GreptimeDB standalone on Kubernetes.")
+	cmd.Flags().BoolVar(&options.Detach, "detach", false, "Keep the cluster running in background after gtctl exits in bare-metal mode, stop it by 'gtctl cluster stop'. The components are not supervised after gtctl exits, so it can't work with the restart policies and the log rotation.")
+	cmd.Flags().BoolVar(&options.Resume, "resume", false, "Resume the failed creation of the cluster in bare-metal mode from the failed component with the configuration that it was created with, the components that are still running are kept.")
+	cmd.Flags().BoolVar(&options.KeepOnFailure, "keep-on-failure", false, "Leave the started components running for debugging if the creation fails in bare-metal mode, it can be resumed by '--resume' later.")
+	cmd.Flags().BoolVar(&options.FollowLogs, "follow-logs", false, "Stream the logs of all the components to the terminal in bare-metal mode.")
+	cmd.Flags().StringVar(&options.InitSQL, "init-sql", "", "The SQL script, or the directory of '.sql' scripts executed in the order of names, to execute once the cluster is healthy.")
+	cmd.Flags().StringVar(&options.InitData, "init-data", "", "The csv or parquet file to load into the table once the cluster is healthy, the parquet file is only supported in bare-metal mode.")
+	cmd.Flags().StringVar(&options.InitTable, "table", "", "The table that the init data is loaded into, default is the name of the init data file without extension.")
+	cmd.Flags().StringVar(&options.InitDatabase, "init-database", "public", "The database that the init SQL scripts and data are executed and loaded in.")
+	cmd.Flags().BoolVar(&options.WaitForSQL, "wait-for-sql", false, "Wait until 'SELECT 1' succeeds through the MySQL and HTTP endpoints of the cluster before the creation succeeds, which catches the cluster that is healthy but not functional yet.")
+	cmd.Flags().BoolVar(&options.SQLSmokeTest, "sql-smoke-test", false, "Create, write, read and drop a table through the MySQL and HTTP endpoints besides 'SELECT 1', which implies '--wait-for-sql'.")
+	cmd.Flags().IntVar(&options.WaitForSQLTimeout, "wait-for-sql-timeout", int(connector.DefaultVerifyTimeout.Seconds()), "Timeout in seconds for waiting the cluster to serve SQL with '--wait-for-sql'.")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting the components to exit gracefully before killing them in bare-metal mode.")
 
 	return cmd
 }
@@ -121,12 +296,77 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 		return fmt.Errorf("cluster name should be set")
 	}
 
+	if len(options.Deployer) > 0 && (options.BareMetal || options.Docker) {
+		return fmt.Errorf("--%s can't be set with --bare-metal and --docker", deployerFlag)
+	}
+	if options.Docker {
+		return createDockerCluster(args[0], options, l)
+	}
+
+	if !options.BareMetal && (len(options.Profile) > 0 || len(options.Vars) > 0) {
+		return fmt.Errorf("--profile and --var are only supported in bare-metal mode")
+	}
+	if !options.BareMetal && (options.Resume || options.KeepOnFailure) {
+		return fmt.Errorf("--resume and --keep-on-failure are only supported in bare-metal mode")
+	}
+	if options.Resume && (options.DryRun || len(options.Config) > 0 || options.Standalone) {
+		return fmt.Errorf("the resumed cluster is created with the configuration that it was created with, " +
+			"--resume can't be set with --dry-run, --config and --standalone")
+	}
+
 	var (
 		clusterName = args[0]
 		ctx         = context.Background()
 		cancel      context.CancelFunc
 	)
 
+	if options.BareMetal && options.FlownodeReplicas > 0 {
+		return fmt.Errorf("--flownode-replicas is not supported in bare-metal mode, set the flownode in the configuration instead")
+	}
+	if options.BareMetal && len(options.Set.ValuesFiles) > 0 {
+		return fmt.Errorf("--values is only supported on Kubernetes, use --config in bare-metal mode")
+	}
+	if options.BareMetal && (len(options.ChartRepository) > 0 || len(options.ImagePullSecrets) > 0) {
+		return fmt.Errorf("--chart-repo and --image-pull-secret are only supported on Kubernetes")
+	}
+	if options.BareMetal && options.EnableMonitoring {
+		return fmt.Errorf("--enable-monitoring is only supported on Kubernetes")
+	}
+	if !options.BareMetal && options.Standalone && (options.FlownodeReplicas > 0 || options.EnableMonitoring) {
+		return fmt.Errorf("--flownode-replicas and --enable-monitoring are not supported by the standalone on Kubernetes")
+	}
+	if !options.BareMetal {
+		if err := validateStorage(options); err != nil {
+			return err
+		}
+	}
+	if err := setupRenderOutput(options, l); err != nil {
+		return err
+	}
+	if len(options.ObjectStorage.Type) > 0 || len(options.ObjectStorage.Bucket) > 0 {
+		if options.BareMetal {
+			return fmt.Errorf("the object storage flags are only supported on Kubernetes, set the storage in the configuration instead")
+		}
+		if err := validateObjectStorage(&options.ObjectStorage); err != nil {
+			return err
+		}
+	}
+	if err := validateRemoteWAL(options); err != nil {
+		return err
+	}
+	if options.FlownodeReplicas <= 0 && options.FlownodeResources.isSet() {
+		return fmt.Errorf("--flownode-cpu and --flownode-memory should be set with --flownode-replicas")
+	}
+	for _, resources := range []componentResources{options.FrontendResources, options.DatanodeResources,
+		options.MetaResources, options.FlownodeResources} {
+		if options.BareMetal && resources.isSet() {
+			return fmt.Errorf("the resources of components are not supported in bare-metal mode, set them in the configuration instead")
+		}
+		if err := resources.validate(); err != nil {
+			return err
+		}
+	}
+
 	if options.Timeout > 0 {
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
 		defer cancel()
@@ -134,7 +374,12 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	spinner, err := status.NewSpinner()
+	// The progress goes to stderr with the logs if the created cluster is output to stdout.
+	var spinnerOpts []status.Option
+	if opt.IsMachineReadable(options.Output) {
+		spinnerOpts = append(spinnerOpts, status.WithWriter(os.Stderr))
+	}
+	spinner, err := status.NewSpinner(spinnerOpts...)
 	if err != nil {
 		return err
 	}
@@ -154,6 +399,7 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 			EtcdStorageSize:        options.EtcdStorageSize,
 			EtcdClusterSize:        options.EtcdClusterSize,
 			ConfigValues:           options.Set.EtcdConfig,
+			Values:                 options.Set.EtcdValues,
 			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
 			ValuesFile:             options.EtcdClusterValuesFile,
 		},
@@ -161,8 +407,11 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 			GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
 			ImageRegistry:                  options.ImageRegistry,
 			ConfigValues:                   options.Set.OperatorConfig,
+			Values:                         options.Set.OperatorValues,
 			UseGreptimeCNArtifacts:         options.UseGreptimeCNArtifacts,
 			ValuesFile:                     options.GreptimeDBOperatorValuesFile,
+			Namespace:                      options.OperatorNamespace,
+			WatchNamespace:                 options.OperatorWatchNamespace,
 		},
 		Cluster: &opt.CreateClusterOptions{
 			GreptimeDBChartVersion:      options.GreptimeDBChartVersion,
@@ -173,58 +422,202 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 			DatanodeStorageRetainPolicy: options.StorageRetainPolicy,
 			EtcdEndPoints:               fmt.Sprintf("%s.%s:2379", kubernetes.EtcdClusterName(clusterName), options.EtcdNamespace),
 			ConfigValues:                options.Set.ClusterConfig,
+			Values:                      options.Set.ClusterValues,
 			UseGreptimeCNArtifacts:      options.UseGreptimeCNArtifacts,
 			ValuesFile:                  options.GreptimeDBClusterValuesFile,
 		},
 		Spinner: spinner,
 	}
+	if options.FlownodeReplicas > 0 {
+		createOptions.Cluster.FlownodeEnabled = "true"
+		createOptions.Cluster.FlownodeReplicas = strconv.Itoa(options.FlownodeReplicas)
+	}
+	if len(options.ImagePullSecrets) > 0 {
+		pullSecrets := fmt.Sprintf("{%s}", strings.Join(options.ImagePullSecrets, ","))
+		createOptions.Etcd.ImagePullSecrets = pullSecrets
+		createOptions.Operator.ImagePullSecrets = pullSecrets
+		createOptions.Cluster.ImagePullSecrets = pullSecrets
+	}
+	clusterOptions := createOptions.Cluster
+	if options.EnableMonitoring {
+		clusterOptions.MonitoringEnabled, clusterOptions.GrafanaEnabled = "true", "true"
+	}
+	clusterOptions.FrontendCPU, clusterOptions.FrontendCPULimit = options.FrontendResources.CPU, options.FrontendResources.CPU
+	clusterOptions.FrontendMemory, clusterOptions.FrontendMemoryLimit = options.FrontendResources.Memory, options.FrontendResources.Memory
+	clusterOptions.DatanodeCPU, clusterOptions.DatanodeCPULimit = options.DatanodeResources.CPU, options.DatanodeResources.CPU
+	clusterOptions.DatanodeMemory, clusterOptions.DatanodeMemoryLimit = options.DatanodeResources.Memory, options.DatanodeResources.Memory
+	clusterOptions.MetaCPU, clusterOptions.MetaCPULimit = options.MetaResources.CPU, options.MetaResources.CPU
+	clusterOptions.MetaMemory, clusterOptions.MetaMemoryLimit = options.MetaResources.Memory, options.MetaResources.Memory
+	clusterOptions.FlownodeCPU, clusterOptions.FlownodeCPULimit = options.FlownodeResources.CPU, options.FlownodeResources.CPU
+	clusterOptions.FlownodeMemory, clusterOptions.FlownodeMemoryLimit = options.FlownodeResources.Memory, options.FlownodeResources.Memory
+	if len(options.ObjectStorage.Type) > 0 {
+		createOptions.Cluster.ObjectStorage = &options.ObjectStorage
+	}
+	if options.DeployKafka {
+		createOptions.Kafka = &opt.CreateKafkaOptions{
+			KafkaChartVersion:      options.KafkaChartVersion,
+			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+			ImageRegistry:          options.ImageRegistry,
+			ImagePullSecrets:       createOptions.Cluster.ImagePullSecrets,
+		}
+		options.KafkaEndpoints = []string{kubernetes.KafkaEndpoint(clusterName, options.Namespace)}
+	}
+	if len(options.KafkaEndpoints) > 0 {
+		createOptions.Cluster.RemoteWALEnabled = "true"
+		createOptions.Cluster.KafkaBrokerEndpoints = fmt.Sprintf("{%s}", strings.Join(options.KafkaEndpoints, ","))
+	}
+	if options.Standalone && !options.BareMetal {
+		createOptions.Standalone = &opt.CreateStandaloneOptions{
+			Image:            options.StandaloneImage,
+			ImagePullSecrets: options.ImagePullSecrets,
+			StorageClassName: options.StorageClassName,
+			StorageSize:      options.StorageSize,
+			RetainPolicy:     options.StorageRetainPolicy,
+		}
+		if len(options.ImageRegistry) > 0 && options.StandaloneImage == kubernetes.DefaultStandaloneImage {
+			createOptions.Standalone.Image = options.ImageRegistry + "/" + kubernetes.DefaultStandaloneImage
+		}
+	}
+	if createOptions.Seed, err = seedOptions(options); err != nil {
+		return err
+	}
+	if options.WaitForSQL || options.SQLSmokeTest {
+		createOptions.Verify = &connector.VerifyOptions{
+			SmokeTest: options.SQLSmokeTest,
+			Timeout:   time.Duration(options.WaitForSQLTimeout) * time.Second,
+		}
+	}
 
-	var cluster opt.Operations
-	if options.BareMetal {
+	// The events of clusters created by the custom deployers are unknown.
+	var bus *events.Bus
+	if !options.DryRun && len(options.Deployer) == 0 {
+		namespace := options.Namespace
+		if options.BareMetal {
+			namespace = ""
+		}
+		if bus, err = newEventBus(l, options.EventSinks, namespace, clusterName); err != nil {
+			return err
+		}
+		defer bus.Close()
+	}
+
+	var cluster opt.Deployer
+	if len(options.Deployer) > 0 {
+		l.V(0).Infof("Creating GreptimeDB cluster '%s' by deployer '%s'", logger.Bold(clusterName), logger.Bold(options.Deployer))
+
+		if cluster, err = newDeployerByName(options.Deployer, l); err != nil {
+			return err
+		}
+	} else if options.BareMetal {
 		l.V(0).Infof("Creating GreptimeDB cluster '%s' on bare-metal", logger.Bold(clusterName))
 
 		var opts []baremetal.Option
 		opts = append(opts, baremetal.WithEnableCache(options.EnableCache), baremetal.WithMetastore(options.UseMemoryMeta))
-		if len(options.GreptimeBinVersion) > 0 {
-			opts = append(opts, baremetal.WithGreptimeVersion(options.GreptimeBinVersion))
-		}
-		if len(options.Config) > 0 {
-			var cfg config.BareMetalClusterConfig
-			raw, err := os.ReadFile(options.Config)
+		opts = append(opts, baremetal.WithSkipVerify(options.SkipVerify))
+		if len(options.ArtifactPlatform) > 0 {
+			platform, err := artifacts.ParsePlatform(options.ArtifactPlatform)
 			if err != nil {
 				return err
 			}
-			if err = yaml.Unmarshal(raw, &cfg); err != nil {
-				return err
+			opts = append(opts, baremetal.WithArtifactPlatform(&platform))
+		}
+		if len(options.GreptimeSource) > 0 {
+			if len(options.GreptimeBinVersion) > 0 || len(options.GreptimeBuild) > 0 {
+				return fmt.Errorf("the source of greptime can't be set with the version or the build")
+			}
+			if len(options.ArtifactPlatform) > 0 {
+				return fmt.Errorf("the source of greptime is built for the current platform, it can't be set with the artifact platform")
 			}
+			opts = append(opts, baremetal.WithGreptimeSource(&artifacts.SourceBuildOptions{
+				SourceDir:   options.GreptimeSource,
+				Profile:     options.SourceProfile,
+				Features:    options.SourceFeatures,
+				EnableCache: options.EnableCache,
+				Output:      os.Stderr,
+			}))
+		}
+		opts = append(opts, baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second))
+		opts = append(opts, baremetal.WithDetach(options.Detach))
+		opts = append(opts, baremetal.WithLogRotation(!options.Detach && !options.KeepOnFailure))
+		opts = append(opts, baremetal.WithDryRun(options.DryRun))
+		opts = append(opts, baremetal.WithKeepOnFailure(options.KeepOnFailure))
+		opts = append(opts, baremetal.WithEvents(bus))
 
-			opts = append(opts, baremetal.WithReplaceConfig(&cfg))
+		if options.Resume {
+			// The resumed cluster is created with the recorded config in its existing dirs.
+			opts = append(opts, baremetal.WithCreateNoDirs())
+		} else {
+			var cfg *config.BareMetalClusterConfig
+			if cfg, err = validateBareMetalConfig(options); err != nil {
+				return err
+			}
+			opts = append(opts, baremetal.WithReplaceConfig(cfg))
+			// The standalone config is filled after the cluster config is replaced.
+			opts = append(opts, baremetal.WithStandalone(options.Standalone))
 		}
 
 		cluster, err = baremetal.NewCluster(l, clusterName, opts...)
 		if err != nil {
 			return err
 		}
+
+		if options.FollowLogs && !options.DryRun {
+			go func() {
+				logsOptions := &opt.LogsOptions{Name: clusterName, Tail: -1, Follow: true, Writer: os.Stdout}
+				if err := cluster.Logs(ctx, logsOptions); err != nil {
+					l.Warnf("Failed to follow the logs of cluster '%s': %v", clusterName, err)
+				}
+			}()
+		}
 	} else {
 		l.V(0).Infof("Creating GreptimeDB cluster '%s' in namespace '%s'", logger.Bold(clusterName), logger.Bold(options.Namespace))
 
 		cluster, err = kubernetes.NewCluster(l,
 			kubernetes.WithDryRun(options.DryRun),
-			kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second))
+			kubernetes.WithChartRepository(options.ChartRepository),
+			kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second),
+			kubernetes.WithKubeConfig(options.Kubeconfig, options.KubeContext),
+			kubernetes.WithManifestsOutput(renderWriter(options), options.OutputDir),
+			kubernetes.WithEvents(bus))
 		if err != nil {
 			return err
 		}
 	}
 
-	if err = cluster.Create(ctx, createOptions); err != nil {
+	if options.Resume {
+		bm, _ := cluster.(*baremetal.Cluster)
+		err = bm.Resume(ctx, createOptions)
+	} else {
+		err = cluster.Create(ctx, createOptions)
+	}
+	if err != nil {
 		return err
 	}
 
-	if !options.DryRun {
+	if !options.BareMetal && len(options.Deployer) == 0 && !options.DryRun {
+		// Record the context, so the later operations of the cluster target the same Kubernetes cluster.
+		if err = recordKubeContext(options.Kubeconfig, options.KubeContext, options.Namespace, clusterName); err != nil {
+			l.Warnf("Failed to record the context of cluster '%s': %v", clusterName, err)
+		}
+	}
+
+	// The ways to access the clusters of custom deployers are unknown.
+	if !options.DryRun && len(options.Deployer) == 0 {
 		printTips(l, clusterName, options)
 	}
 
-	if options.BareMetal {
+	if opt.IsMachineReadable(options.Output) && !options.DryRun {
+		if err = cluster.Get(ctx, &opt.GetOptions{
+			Namespace: options.Namespace,
+			Name:      clusterName,
+			Output:    options.Output,
+			Writer:    os.Stdout,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if options.BareMetal && !options.DryRun {
 		bm, _ := cluster.(*baremetal.Cluster)
 		if err = bm.Wait(ctx, false); err != nil {
 			return err
@@ -234,18 +627,210 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 	return nil
 }
 
+// setupRenderOutput validates the render-only mode and the output of created cluster, and redirects
+// the logs to stderr so only the manifests or the created cluster are written to stdout.
+func setupRenderOutput(options *clusterCreateCliOptions, l logger.Logger) error {
+	if len(options.Output) == 0 && len(options.OutputDir) == 0 {
+		return nil
+	}
+
+	if !options.DryRun && len(options.OutputDir) == 0 {
+		if err := opt.ValidateOutputFormat(options.Output, opt.OutputFormatJSON, opt.OutputFormatYAML); err != nil {
+			return err
+		}
+		logToStderr(l)
+		return nil
+	}
+
+	if options.BareMetal || !options.DryRun {
+		return fmt.Errorf("--output-dir and --output with --dry-run are only supported on Kubernetes")
+	}
+	if len(options.Output) > 0 && options.Output != opt.OutputFormatYAML {
+		return fmt.Errorf("unsupported output format '%s', only '%s' is supported", options.Output, opt.OutputFormatYAML)
+	}
+
+	if len(options.Output) > 0 && len(options.OutputDir) == 0 {
+		logToStderr(l)
+	}
+	return nil
+}
+
+// logToStderr redirects the logs to stderr, so the stdout can be piped to other tools.
+func logToStderr(l logger.Logger) {
+	type writerSetter interface {
+		SetWriter(io.Writer)
+	}
+	if w, ok := l.(writerSetter); ok {
+		w.SetWriter(os.Stderr)
+	}
+}
+
+// renderWriter returns the writer that the manifests are rendered to in the render-only mode.
+func renderWriter(options *clusterCreateCliOptions) io.Writer {
+	if options.Output == opt.OutputFormatYAML && len(options.OutputDir) == 0 {
+		return os.Stdout
+	}
+	return nil
+}
+
+// validateStorage validates the storage of datanodes on Kubernetes.
+func validateStorage(options *clusterCreateCliOptions) error {
+	if _, err := resource.ParseQuantity(options.StorageSize); err != nil {
+		return fmt.Errorf("invalid storage size '%s': %v", options.StorageSize, err)
+	}
+
+	switch greptimedbclusterv1alpha1.StorageRetainPolicyType(options.StorageRetainPolicy) {
+	case greptimedbclusterv1alpha1.RetainStorageRetainPolicyTypeRetain, greptimedbclusterv1alpha1.RetainStorageRetainPolicyTypeDelete:
+		return nil
+	default:
+		return fmt.Errorf("invalid storage retain policy '%s', it should be '%s' or '%s'", options.StorageRetainPolicy,
+			greptimedbclusterv1alpha1.RetainStorageRetainPolicyTypeRetain, greptimedbclusterv1alpha1.RetainStorageRetainPolicyTypeDelete)
+	}
+}
+
+// validateRemoteWAL validates the remote WAL set in command line.
+func validateRemoteWAL(options *clusterCreateCliOptions) error {
+	if !options.EnableRemoteWAL && !options.DeployKafka && len(options.KafkaEndpoints) == 0 {
+		return nil
+	}
+
+	if options.BareMetal {
+		return fmt.Errorf("--enable-remote-wal, --kafka-endpoints and --deploy-kafka are only supported on Kubernetes, set the WAL in the configuration instead")
+	}
+	if options.Standalone {
+		return fmt.Errorf("the remote WAL is not supported by the standalone on Kubernetes")
+	}
+	if options.DeployKafka && len(options.KafkaEndpoints) > 0 {
+		return fmt.Errorf("--deploy-kafka and --kafka-endpoints can't be set at the same time")
+	}
+	if !options.DeployKafka && len(options.KafkaEndpoints) == 0 {
+		return fmt.Errorf("--enable-remote-wal should be set with --kafka-endpoints or --deploy-kafka")
+	}
+	if len(options.KafkaEndpoints) > 0 && !options.EnableRemoteWAL {
+		return fmt.Errorf("--kafka-endpoints should be set with --enable-remote-wal")
+	}
+
+	return nil
+}
+
+// validateObjectStorage validates the object storage set in command line.
+func validateObjectStorage(storage *config.ObjectStorage) error {
+	switch storage.Type {
+	case config.ObjectStorageS3, config.ObjectStorageOSS, config.ObjectStorageGCS, config.ObjectStorageAzblob:
+	default:
+		return fmt.Errorf("invalid object storage type '%s'", storage.Type)
+	}
+
+	if len(storage.Bucket) == 0 {
+		return fmt.Errorf("--object-storage-bucket should be set with --object-storage-type")
+	}
+	if len(storage.Endpoint) > 0 {
+		if u, err := url.Parse(storage.Endpoint); err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("invalid object storage endpoint '%s'", storage.Endpoint)
+		}
+	}
+	if len(storage.CredentialsFile) > 0 {
+		if _, err := os.Stat(storage.CredentialsFile); err != nil {
+			return fmt.Errorf("invalid object storage credentials file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// seedOptions returns the seed data to load into the cluster, or nil if there is no seed data.
+func seedOptions(options *clusterCreateCliOptions) (*connector.SeedOptions, error) {
+	if len(options.InitTable) > 0 && len(options.InitData) == 0 {
+		return nil, fmt.Errorf("--table should be set with --init-data")
+	}
+	if len(options.InitSQL) == 0 && len(options.InitData) == 0 {
+		return nil, nil
+	}
+
+	seed := &connector.SeedOptions{
+		Database: options.InitDatabase,
+		SQLPath:  options.InitSQL,
+		DataPath: options.InitData,
+		Table:    options.InitTable,
+	}
+	if len(seed.DataPath) > 0 {
+		ext := filepath.Ext(seed.DataPath)
+		if strings.EqualFold(ext, ".parquet") && !options.BareMetal {
+			return nil, fmt.Errorf("loading the parquet file is only supported in bare-metal mode")
+		}
+		if len(seed.Table) == 0 {
+			seed.Table = strings.TrimSuffix(filepath.Base(seed.DataPath), ext)
+		}
+	}
+
+	return seed, nil
+}
+
+// validateBareMetalConfig renders the bare-metal cluster config by applying the profile and the values set in
+// command line onto the base config, which is the config file if it's specified, or the default config, and
+// validates the rendered config, all the problems of it are reported at once.
+func validateBareMetalConfig(options *clusterCreateCliOptions) (*config.BareMetalClusterConfig, error) {
+	var (
+		base []byte
+		err  error
+	)
+
+	if len(options.Config) > 0 {
+		if base, err = os.ReadFile(options.Config); err != nil {
+			return nil, err
+		}
+	} else {
+		cfg := config.DefaultBareMetalConfig()
+		if len(options.GreptimeBinVersion) > 0 {
+			cfg.Cluster.Artifact.Version = options.GreptimeBinVersion
+		}
+		if len(options.GreptimeBuild) > 0 {
+			if len(options.GreptimeBinVersion) > 0 {
+				return nil, fmt.Errorf("the version and the build of greptime can't be both set")
+			}
+			cfg.Cluster.Artifact.Version = artifacts.DevBuildVersion(options.GreptimeBuild)
+		}
+		if base, err = yaml.Marshal(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return config.ValidateBareMetalConfig(base, options.Profile, options.Set.RawConfig, options.Vars)
+}
+
 func printTips(l logger.Logger, clusterName string, options *clusterCreateCliOptions) {
+	service := connector.FrontendServiceName(clusterName)
+	if options.Standalone {
+		service = kubernetes.StandaloneServiceName(clusterName)
+	}
+
+	// The kubectl commands should target the same Kubernetes cluster that the cluster is created on.
+	kubectlArgs := "-n " + options.Namespace
+	if options.Kubeconfig != "" {
+		kubectlArgs += " --kubeconfig " + options.Kubeconfig
+	}
+	if options.KubeContext != "" {
+		kubectlArgs += " --context " + options.KubeContext
+	}
+
 	l.V(0).Infof("\nNow you can use the following commands to access the GreptimeDB cluster:")
 	l.V(0).Infof("\n%s", logger.Bold("MySQL >"))
-	if !options.BareMetal {
-		l.V(0).Infof("%s", fmt.Sprintf("%s kubectl port-forward svc/%s-frontend -n %s 4002:4002 > connections-mysql.out &", logger.Bold("$"), clusterName, options.Namespace))
+	if !options.BareMetal && !options.Docker {
+		l.V(0).Infof("%s", fmt.Sprintf("%s kubectl port-forward svc/%s %s 4002:4002 > connections-mysql.out &", logger.Bold("$"), service, kubectlArgs))
 	}
 	l.V(0).Infof("%s", fmt.Sprintf("%s mysql -h 127.0.0.1 -P 4002", logger.Bold("$")))
 	l.V(0).Infof("\n%s", logger.Bold("PostgreSQL >"))
-	if !options.BareMetal {
-		l.V(0).Infof("%s", fmt.Sprintf("%s kubectl port-forward svc/%s-frontend -n %s 4003:4003 > connections-pg.out &", logger.Bold("$"), clusterName, options.Namespace))
+	if !options.BareMetal && !options.Docker {
+		l.V(0).Infof("%s", fmt.Sprintf("%s kubectl port-forward svc/%s %s 4003:4003 > connections-pg.out &", logger.Bold("$"), service, kubectlArgs))
 	}
 	l.V(0).Infof("%s", fmt.Sprintf("%s psql -h 127.0.0.1 -p 4003 -d public", logger.Bold("$")))
+	if options.EnableMonitoring {
+		l.V(0).Infof("\n%s", logger.Bold("Grafana >"))
+		l.V(0).Infof("%s", fmt.Sprintf("%s gtctl cluster monitor open %s -n %s", logger.Bold("$"), clusterName, options.Namespace))
+		l.V(0).Infof("%s", fmt.Sprintf("Then visit http://127.0.0.1:%d, the password of user 'admin' is printed by:", defaultGrafanaPort))
+		l.V(0).Infof("%s", fmt.Sprintf("%s kubectl get secret %s %s -o jsonpath='{.data.admin-password}' | base64 -d",
+			logger.Bold("$"), connector.GrafanaServiceName(clusterName), kubectlArgs))
+	}
 	l.V(0).Infof("\nThank you for using %s! Check for more information on %s. 😊", logger.Bold("GreptimeDB"), logger.Bold("https://greptime.com"))
 	l.V(0).Infof("\n%s 🔑", logger.Bold("Invest in Data, Harvest over Time."))
 }
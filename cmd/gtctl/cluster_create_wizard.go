@@ -0,0 +1,380 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	wizardModeBareMetal  = "bare-metal"
+	wizardModeKubernetes = "kubernetes"
+
+	wizardTopologyCluster    = "cluster"
+	wizardTopologyStandalone = "standalone"
+
+	// wizardStorageLocal stores the data in the local disk in bare-metal mode, or in the persistent volumes on Kubernetes.
+	wizardStorageLocal = "local"
+
+	defaultWizardClusterName = "mycluster"
+)
+
+// runCreateWizard walks the user through the options of creating the cluster. The resulting config in bare-metal
+// mode, or the values file on Kubernetes, is written to a file and the options are filled with it, so the cluster
+// can be created again by the printed command. It returns the name of cluster, and false if the user chooses not
+// to create the cluster right now.
+func runCreateWizard(in io.Reader, out io.Writer, args []string, options *clusterCreateCliOptions) (string, bool, error) {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+
+	name := defaultWizardClusterName
+	if len(args) > 0 {
+		name = args[0]
+	}
+	name, err := p.ask("Cluster name", name)
+	if err != nil {
+		return "", false, err
+	}
+
+	mode := wizardModeKubernetes
+	if options.BareMetal {
+		mode = wizardModeBareMetal
+	}
+	if mode, err = p.choose("Deployment mode", []string{wizardModeBareMetal, wizardModeKubernetes}, mode); err != nil {
+		return "", false, err
+	}
+	options.BareMetal = mode == wizardModeBareMetal
+
+	topology := wizardTopologyCluster
+	if options.Standalone {
+		topology = wizardTopologyStandalone
+	}
+	if topology, err = p.choose("Topology", []string{wizardTopologyCluster, wizardTopologyStandalone}, topology); err != nil {
+		return "", false, err
+	}
+	options.Standalone = topology == wizardTopologyStandalone
+
+	var file string
+	if options.BareMetal {
+		file, err = runBareMetalWizard(p, name, options)
+	} else {
+		file, err = runKubernetesWizard(p, name, options)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	command := wizardCommand(name, file, options)
+	fmt.Fprintf(out, "\nThe config is written to '%s', the cluster can be created by:\n\n  %s\n\n", file, command)
+	apply, err := p.confirm("Create the cluster now", true)
+	if err != nil {
+		return "", false, err
+	}
+
+	return name, apply, nil
+}
+
+// runBareMetalWizard prompts the version, replicas and storage of the cluster in bare-metal mode,
+// and writes the config to the file.
+func runBareMetalWizard(p *prompter, name string, options *clusterCreateCliOptions) (string, error) {
+	cfg := config.DefaultBareMetalConfig()
+
+	version := options.GreptimeBinVersion
+	if len(version) == 0 {
+		version = artifacts.LatestVersionTag
+	}
+	version, err := p.ask(fmt.Sprintf("GreptimeDB version, e.g. 'v0.4.1', '%s' and '%s'", artifacts.LatestVersionTag, artifacts.NightlyVersionTag), version)
+	if err != nil {
+		return "", err
+	}
+	cfg.Cluster.Artifact.Version = version
+
+	if options.Standalone {
+		cfg.Cluster.Standalone = config.DefaultStandaloneConfig()
+	} else {
+		for _, replicas := range []struct {
+			component string
+			value     *int
+		}{
+			{"frontend", &cfg.Cluster.Frontend.Replicas},
+			{"datanode", &cfg.Cluster.Datanode.Replicas},
+			{"meta", &cfg.Cluster.MetaSrv.Replicas},
+		} {
+			if *replicas.value, err = p.askInt(fmt.Sprintf("Replicas of %s", replicas.component), *replicas.value); err != nil {
+				return "", err
+			}
+		}
+
+		storage, err := askObjectStorage(p)
+		if err != nil {
+			return "", err
+		}
+		if storage != nil {
+			cfg.Cluster.Datanode.Storage = storage
+		} else if cfg.Cluster.Datanode.DataDir, err = p.ask("Data directory of datanodes, the cluster directory if it's empty", ""); err != nil {
+			return "", err
+		}
+	}
+
+	file, err := p.ask("Write the config to", name+".yaml")
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return "", err
+	}
+
+	// The version is in the config.
+	options.Config, options.GreptimeBinVersion = file, ""
+	return file, nil
+}
+
+// runKubernetesWizard prompts the namespace, version, replicas and storage of the cluster on Kubernetes,
+// and writes the values of cluster to the file.
+func runKubernetesWizard(p *prompter, name string, options *clusterCreateCliOptions) (string, error) {
+	namespace, err := p.ask("Namespace", options.Namespace)
+	if err != nil {
+		return "", err
+	}
+	options.Namespace = namespace
+
+	version, err := p.ask("GreptimeDB version, e.g. 'v0.4.1', the one of the chart if it's empty", "")
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]interface{})
+	if options.Standalone {
+		if len(version) > 0 {
+			options.StandaloneImage = strings.TrimSuffix(kubernetes.DefaultStandaloneImage, ":"+artifacts.LatestVersionTag) + ":" + version
+		}
+	} else {
+		if len(version) > 0 {
+			values["image"] = map[string]interface{}{"tag": version}
+		}
+		for _, replicas := range []struct {
+			component string
+			value     int
+		}{
+			{"frontend", 1},
+			{"datanode", 3},
+			{"meta", 1},
+		} {
+			n, err := p.askInt(fmt.Sprintf("Replicas of %s", replicas.component), replicas.value)
+			if err != nil {
+				return "", err
+			}
+			values[replicas.component] = map[string]interface{}{"replicas": n}
+		}
+
+		storage, err := askObjectStorage(p)
+		if err != nil {
+			return "", err
+		}
+		if storage != nil {
+			options.ObjectStorage = *storage
+		}
+	}
+
+	// The persistent volumes store the data of standalone, or the WAL and the cache of datanodes with object storage.
+	storageClass := options.StorageClassName
+	if storageClass == "null" {
+		storageClass = ""
+	}
+	if storageClass, err = p.ask("Storage class of the persistent volumes, the default one if it's empty", storageClass); err != nil {
+		return "", err
+	}
+	if len(storageClass) > 0 {
+		options.StorageClassName = storageClass
+	}
+	if options.StorageSize, err = p.ask("Storage size of the persistent volumes", options.StorageSize); err != nil {
+		return "", err
+	}
+
+	file, err := p.ask("Write the values to", name+"-values.yaml")
+	if err != nil {
+		return "", err
+	}
+	data := []byte("{}\n")
+	if len(values) > 0 {
+		if data, err = yaml.Marshal(map[string]interface{}{"cluster": values}); err != nil {
+			return "", err
+		}
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return "", err
+	}
+
+	options.Set.ValuesFiles = append(options.Set.ValuesFiles, file)
+	return file, nil
+}
+
+// askObjectStorage prompts the storage of datanodes, it returns nil if the data is stored locally.
+func askObjectStorage(p *prompter) (*config.ObjectStorage, error) {
+	storageType, err := p.choose("Storage of datanodes", []string{wizardStorageLocal, config.ObjectStorageS3,
+		config.ObjectStorageOSS, config.ObjectStorageGCS, config.ObjectStorageAzblob}, wizardStorageLocal)
+	if err != nil || storageType == wizardStorageLocal {
+		return nil, err
+	}
+
+	storage := &config.ObjectStorage{Type: storageType}
+	for _, field := range []struct {
+		question string
+		value    *string
+		required bool
+	}{
+		{"Bucket, or the container for azblob", &storage.Bucket, true},
+		{"Root path of data in the bucket", &storage.Root, false},
+		{"Region", &storage.Region, false},
+		{"Endpoint, e.g. 'https://s3.us-west-2.amazonaws.com'", &storage.Endpoint, false},
+		{"Credentials file", &storage.CredentialsFile, false},
+	} {
+		for {
+			if *field.value, err = p.ask(field.question, ""); err != nil {
+				return nil, err
+			}
+			if !field.required || len(*field.value) > 0 {
+				break
+			}
+			fmt.Fprintf(p.out, "It's required.\n")
+		}
+	}
+
+	return storage, validateObjectStorage(storage)
+}
+
+// wizardCommand returns the command that creates the same cluster as the wizard.
+func wizardCommand(name, file string, options *clusterCreateCliOptions) string {
+	args := []string{"gtctl", "cluster", "create", name}
+	if options.BareMetal {
+		args = append(args, "--bare-metal", "--config", file)
+		return strings.Join(args, " ")
+	}
+
+	args = append(args, "-n", options.Namespace, "--values", file)
+	if options.Standalone {
+		args = append(args, "--standalone")
+		if options.StandaloneImage != kubernetes.DefaultStandaloneImage {
+			args = append(args, "--standalone-image", options.StandaloneImage)
+		}
+	}
+	if options.StorageClassName != "null" {
+		args = append(args, "--storage-class-name", options.StorageClassName)
+	}
+	args = append(args, "--storage-size", options.StorageSize)
+
+	storage := options.ObjectStorage
+	for _, flag := range []struct{ name, value string }{
+		{"--object-storage-type", storage.Type},
+		{"--object-storage-bucket", storage.Bucket},
+		{"--object-storage-root", storage.Root},
+		{"--object-storage-region", storage.Region},
+		{"--object-storage-endpoint", storage.Endpoint},
+		{"--object-storage-credentials-file", storage.CredentialsFile},
+	} {
+		if len(flag.value) > 0 {
+			args = append(args, flag.name, flag.value)
+		}
+	}
+
+	return strings.Join(args, " ")
+}
+
+// prompter prompts the questions and reads the answers line by line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask returns the answer to the question, or the default value if the answer is empty.
+func (p *prompter) ask(question, defaultValue string) (string, error) {
+	if len(defaultValue) > 0 {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		if err == io.EOF {
+			return "", fmt.Errorf("the input is closed before answering '%s'", question)
+		}
+		return "", err
+	}
+
+	if answer := strings.TrimSpace(line); len(answer) > 0 {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
+
+// choose returns the choice to the question, it's asked again until one of the choices is answered.
+func (p *prompter) choose(question string, choices []string, defaultValue string) (string, error) {
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(choices, "/")), defaultValue)
+		if err != nil {
+			return "", err
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return answer, nil
+			}
+		}
+		fmt.Fprintf(p.out, "Invalid choice '%s'.\n", answer)
+	}
+}
+
+// askInt returns the positive number to the question, it's asked again until a positive number is answered.
+func (p *prompter) askInt(question string, defaultValue int) (int, error) {
+	for {
+		answer, err := p.ask(question, strconv.Itoa(defaultValue))
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n > 0 {
+			return n, nil
+		}
+		fmt.Fprintf(p.out, "Invalid number '%s', it should be greater than 0.\n", answer)
+	}
+}
+
+// confirm returns whether the answer to the question is yes.
+func (p *prompter) confirm(question string, defaultValue bool) (bool, error) {
+	answer := "n"
+	if defaultValue {
+		answer = "y"
+	}
+	answer, err := p.choose(question, []string{"y", "n"}, answer)
+	if err != nil {
+		return false, err
+	}
+	return answer == "y", nil
+}
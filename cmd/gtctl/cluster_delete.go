@@ -19,62 +19,125 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
-	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/docker"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
 type clusterDeleteOptions struct {
-	Namespace    string
-	TearDownEtcd bool
+	Namespace         string
+	TearDownEtcd      bool
+	DeletePVCs        bool
+	DeleteOperator    bool
+	OperatorNamespace string
+	WatchNamespace    string
+	DryRun            bool
 
 	// The options for deleting GreptimeDB cluster in bare-metal.
-	BareMetal bool
+	BareMetal  bool
+	RetainData bool
+	RetainLogs bool
+	Components []string
+
+	// Docker deletes the GreptimeDB cluster in the docker containers.
+	Docker bool
 }
 
 func NewDeleteClusterCommand(l logger.Logger) *cobra.Command {
 	var options clusterDeleteOptions
 
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a GreptimeDB cluster",
-		Long:  `Delete a GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		Use:               "delete",
+		Short:             "Delete a GreptimeDB cluster",
+		Long:              `Delete a GreptimeDB cluster`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
 			}
 
 			clusterName := args[0]
 			var (
-				cluster opt.Operations
-				err     error
+				cluster opt.Deployer
 				ctx     = context.TODO()
 			)
 
-			if options.BareMetal {
-				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
-			} else {
-				cluster, err = kubernetes.NewCluster(l)
+			if options.Docker && (options.BareMetal || options.RetainLogs || len(options.Components) > 0) {
+				return fmt.Errorf("'--bare-metal', '--retain-logs' and '--component' can't be set with '--docker'")
+			}
+			if !options.BareMetal && !options.Docker && (options.RetainData || options.RetainLogs || len(options.Components) > 0) {
+				return fmt.Errorf("'--retain-data', '--retain-logs' and '--component' are only supported in bare-metal mode")
+			}
+			if (options.BareMetal || options.Docker) && (options.DeletePVCs || options.DeleteOperator || options.DryRun) {
+				return fmt.Errorf("'--delete-pvcs', '--delete-operator' and '--dry-run' are only supported on Kubernetes")
+			}
+			defer recordAudit(cmd, l, audit.OperationDelete, options.Namespace, clusterName, time.Now(), &err)
+
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				} else if options.Docker {
+					cluster, err = docker.NewCluster(l)
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				}
 			}
 			if err != nil {
 				return err
 			}
 
+			if !options.BareMetal && !options.Docker {
+				warnAdoptedCluster(l, options.Namespace, clusterName)
+			}
+
 			deleteOptions := &opt.DeleteOptions{
-				Namespace: options.Namespace,
-				Name:      clusterName,
+				Namespace:              options.Namespace,
+				Name:                   clusterName,
+				TearDownEtcd:           options.TearDownEtcd,
+				DeletePVCs:             options.DeletePVCs,
+				DeleteOperator:         options.DeleteOperator,
+				OperatorNamespace:      options.OperatorNamespace,
+				OperatorWatchNamespace: options.WatchNamespace,
+				DryRun:                 options.DryRun,
+				RetainData:             options.RetainData,
+				RetainLogs:             options.RetainLogs,
+				Components:             options.Components,
+			}
+			if err = cluster.Delete(ctx, deleteOptions); err != nil {
+				return err
+			}
+
+			if !options.BareMetal && !options.Docker && !options.DryRun {
+				if err = removeKubeContext(options.Namespace, clusterName); err != nil {
+					l.Warnf("Failed to remove the recorded context of cluster '%s': %v", clusterName, err)
+				}
 			}
-			return cluster.Delete(ctx, deleteOptions)
+			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
 	cmd.Flags().BoolVar(&options.TearDownEtcd, "tear-down-etcd", false, "Tear down etcd cluster.")
+	cmd.Flags().BoolVar(&options.TearDownEtcd, "delete-etcd", false, "Delete the etcd cluster of GreptimeDB cluster, the alias of '--tear-down-etcd'.")
+	cmd.Flags().BoolVar(&options.DeletePVCs, "delete-pvcs", false, "Delete the PersistentVolumeClaims of the datanodes, and of the etcd if it's deleted, the data volumes are kept by default.")
+	cmd.Flags().BoolVar(&options.DeleteOperator, "delete-operator", false, "Delete the greptimedb-operator, its CRDs are kept.")
+	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of greptimedb-operator.")
+	cmd.Flags().StringVar(&options.WatchNamespace, "watch-namespace", "", "The namespace that greptimedb-operator is installed to watch only.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Print the resources that would be deleted without deleting them.")
 	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Get the greptimedb cluster on bare-metal environment.")
+	addDeployerFlag(cmd)
+	cmd.Flags().BoolVar(&options.Docker, "docker", false, "Delete the greptimedb cluster in docker containers.")
+	cmd.Flags().BoolVar(&options.RetainData, "retain-data", false, "Keep the data of the deleted cluster or components in bare-metal mode, or the volumes in docker mode, which is reused if the cluster is created again with the same name.")
+	cmd.Flags().BoolVar(&options.RetainLogs, "retain-logs", false, "Keep the logs of the deleted cluster or components in bare-metal mode.")
+	cmd.Flags().StringSliceVarP(&options.Components, "component", "c", nil, "Only clean up the replicas of the components in bare-metal mode, e.g. 'frontend', 'datanode-hot' and 'etcd', the cluster itself is kept.")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("component", completeComponents(false)))
 
 	return cmd
 }
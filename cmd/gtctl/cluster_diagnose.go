@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterDiagnoseCliOptions struct {
+	Output string
+	Redact bool
+}
+
+func NewDiagnoseClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterDiagnoseCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Collect the diagnostics of GreptimeDB cluster",
+		Long: `Collect the logs, configs, args and health of replicas, the metadata of metasrv and the environment info
+of GreptimeDB cluster in bare-metal mode into a '.tar.gz' archive, which can be attached to the bug reports`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			output := options.Output
+			if len(output) == 0 {
+				output = fmt.Sprintf("%s-diagnostics-%s.tar.gz", clusterName, time.Now().Format("20060102150405"))
+			}
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.Diagnose(context.TODO(), &opt.DiagnoseOptions{
+				Name:   clusterName,
+				Output: output,
+				Redact: options.Redact,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The path of the diagnostics archive, default is '<cluster name>-diagnostics-<timestamp>.tar.gz'.")
+	cmd.Flags().BoolVar(&options.Redact, "redact", false, "Redact the credentials, e.g. passwords, secret keys and tokens, in the collected files.")
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/docker"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// createDockerCluster creates the cluster in the docker containers by docker compose, the cluster is
+// described by the same configuration, profile and values set in command line as in bare-metal mode.
+func createDockerCluster(clusterName string, options *clusterCreateCliOptions, l logger.Logger) error {
+	if options.BareMetal {
+		return fmt.Errorf("--docker and --bare-metal can't be set together")
+	}
+	if len(options.Set.ValuesFiles) > 0 || len(options.ChartRepository) > 0 || len(options.ImagePullSecrets) > 0 {
+		return fmt.Errorf("--values, --chart-repo and --image-pull-secret are only supported on Kubernetes")
+	}
+	if len(options.InitSQL) > 0 || len(options.InitData) > 0 {
+		return fmt.Errorf("--init-sql and --init-data are not supported in docker mode")
+	}
+
+	cfg, err := validateBareMetalConfig(options)
+	if err != nil {
+		return err
+	}
+	if options.Standalone && cfg.Cluster.Standalone == nil {
+		cfg.Cluster.Standalone = config.DefaultStandaloneConfig()
+	}
+
+	if options.DryRun {
+		// Only the compose file is written to stdout.
+		logToStderr(l)
+	} else {
+		l.V(0).Infof("Creating GreptimeDB cluster '%s' in docker", logger.Bold(clusterName))
+	}
+
+	cluster, err := docker.NewCluster(l,
+		docker.WithReplaceConfig(cfg),
+		docker.WithImageRegistry(options.ImageRegistry),
+		docker.WithMetastore(options.UseMemoryMeta),
+		docker.WithDryRun(options.DryRun, nil))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err = cluster.Create(ctx, &opt.CreateOptions{Name: clusterName}); err != nil {
+		return err
+	}
+
+	if !options.DryRun {
+		printTips(l, clusterName, options)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/doctor"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+type clusterDoctorCliOptions struct {
+	BareMetal         bool
+	Config            string
+	MinDiskSpace      int
+	Namespace         string
+	OperatorNamespace string
+	Output            string
+}
+
+func NewDoctorCommand(l logger.Logger) *cobra.Command {
+	var options clusterDoctorCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check whether the environment is ready to create GreptimeDB cluster",
+		Long: `Check whether the environment is ready to create GreptimeDB cluster, and print how to fix the problems found.
+In bare-metal mode, the limit of open files, the free disk space, the ports of the cluster config and the glibc version are checked.
+On Kubernetes, the kubectl and helm commands, the access to the Kubernetes API and the permissions of creating the operator and the clusters are checked.
+The hosts of the artifact endpoints are resolved in both modes. It fails if any check fails, the warnings are only printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opt.ValidateOutputFormat(options.Output, opt.OutputFormatTable, opt.OutputFormatJSON, opt.OutputFormatYAML); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			var results []*doctor.Result
+			if options.BareMetal {
+				checks, err := bareMetalChecks(ctx, l, &options)
+				if err != nil {
+					return err
+				}
+				results = append(results, checks...)
+			} else {
+				results = append(results, kubernetesChecks(ctx, cmd, &options)...)
+			}
+
+			hosts, err := artifacts.EndpointHosts()
+			if err != nil {
+				return err
+			}
+			results = append(results, doctor.CheckDNS(ctx, net.DefaultResolver, hosts))
+
+			if opt.IsMachineReadable(options.Output) {
+				if err = opt.RenderOutput(os.Stdout, options.Output, results); err != nil {
+					return err
+				}
+			} else {
+				renderDoctorResults(l, results)
+			}
+
+			failures, warnings := doctor.Count(results)
+			if failures > 0 {
+				return fmt.Errorf("%d check(s) failed and %d warned", failures, warnings)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Check the environment of creating the greptimedb cluster on bare-metal environment.")
+	cmd.Flags().StringVar(&options.Config, "config", "", "The configuration of the cluster on bare-metal environment whose ports are checked, the default configuration is checked if it's not set.")
+	cmd.Flags().IntVar(&options.MinDiskSpace, "min-disk-space", doctor.DefaultMinDiskSpace>>30, "The minimum free disk space in GiB to store the data and logs of the cluster on bare-metal environment.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of deploying greptimedb-operator.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of the checks, can be 'table', 'json' and 'yaml'.")
+
+	return cmd
+}
+
+// bareMetalChecks checks the resources of the local host and the ports of the cluster config.
+func bareMetalChecks(ctx context.Context, l logger.Logger, options *clusterDoctorCliOptions) ([]*doctor.Result, error) {
+	var (
+		base []byte
+		err  error
+	)
+	if len(options.Config) > 0 {
+		if base, err = os.ReadFile(options.Config); err != nil {
+			return nil, err
+		}
+	} else if base, err = yaml.Marshal(config.DefaultBareMetalConfig()); err != nil {
+		return nil, err
+	}
+	cfg, err := config.ValidateBareMetalConfig(base, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := baremetal.ListenAddrs(cfg, l)
+	if err != nil {
+		return nil, err
+	}
+
+	mm, err := metadata.New("")
+	if err != nil {
+		return nil, err
+	}
+
+	// The checks that are not applicable to the platform return nil.
+	var results []*doctor.Result
+	for _, result := range []*doctor.Result{
+		doctor.CheckOpenFiles(),
+		doctor.CheckDiskSpace(mm.GetWorkingDir(), uint64(options.MinDiskSpace)<<30),
+		doctor.CheckPorts(addrs),
+		doctor.CheckGlibc(ctx),
+	} {
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// kubernetesChecks checks the commands and the access to the Kubernetes cluster that the global flags select.
+func kubernetesChecks(ctx context.Context, cmd *cobra.Command, options *clusterDoctorCliOptions) []*doctor.Result {
+	results := []*doctor.Result{
+		doctor.CheckCommand("kubectl", "Install kubectl to inspect the clusters, see https://kubernetes.io/docs/tasks/tools/."),
+		doctor.CheckCommand("helm", "Install helm to inspect the releases of the operator and the etcd, see https://helm.sh/docs/intro/install/."),
+	}
+
+	client, result := doctor.CheckKubeAPI(kubeConfigFlags(cmd))
+	results = append(results, result)
+	if client != nil {
+		results = append(results, doctor.CheckKubePermissions(ctx, client, options.Namespace, options.OperatorNamespace))
+	}
+	return results
+}
+
+// renderDoctorResults prints the results in the table, followed by the remediation of the problems.
+func renderDoctorResults(l logger.Logger, results []*doctor.Result) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+
+	table.SetHeader([]string{"Check", "Status", "Message"})
+	for _, result := range results {
+		table.Append([]string{result.Name, string(result.Status), result.Message})
+	}
+	table.Render()
+
+	for _, result := range results {
+		if len(result.Remediation) > 0 {
+			l.V(0).Infof("\n%s %s", logger.Bold(fmt.Sprintf("[%s] %s:", result.Status, result.Name)), result.Remediation)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterExecCliOptions struct {
+	Namespace string
+	Database  string
+	SQL       string
+	PromQL    string
+	Start     string
+	End       string
+	Step      string
+	Format    string
+
+	// The options for executing on GreptimeDB cluster in bare-metal.
+	BareMetal bool
+}
+
+func NewExecCommand(l logger.Logger) *cobra.Command {
+	var options clusterExecCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Execute SQL or PromQL query on a GreptimeDB cluster",
+		Long: `Execute SQL or PromQL query on a GreptimeDB cluster non-interactively through its HTTP API, and output
+the results in table, json or csv format. It fails if the query fails, so it can be used to smoke-test clusters in scripts`,
+		Example: `  gtctl cluster exec mycluster --sql "SELECT 1"
+  gtctl cluster exec mycluster --bare-metal --promql "up" -o json`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if (len(options.SQL) > 0) == (len(options.PromQL) > 0) {
+				return fmt.Errorf("one of --sql and --promql should be set")
+			}
+			switch options.Format {
+			case connector.OutputFormatTable, connector.OutputFormatJSON, connector.OutputFormatCSV:
+			default:
+				return fmt.Errorf("unsupported output format: %s", options.Format)
+			}
+
+			var (
+				clusterName = args[0]
+				cluster     opt.Deployer
+				err         error
+			)
+
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			return cluster.Exec(context.TODO(), &opt.ExecOptions{
+				Namespace: options.Namespace,
+				Name:      clusterName,
+				ExecQuery: connector.ExecQuery{
+					Database: options.Database,
+					SQL:      options.SQL,
+					PromQL:   options.PromQL,
+					Start:    options.Start,
+					End:      options.End,
+					Step:     options.Step,
+					Format:   options.Format,
+				},
+				Writer: os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVarP(&options.Database, "database", "d", "public", "The database that the query is executed in.")
+	cmd.Flags().StringVar(&options.SQL, "sql", "", "The SQL statements to execute.")
+	cmd.Flags().StringVar(&options.PromQL, "promql", "", "The PromQL query to execute.")
+	cmd.Flags().StringVar(&options.Start, "start", "", "The start time of PromQL range query, in RFC3339 or unix timestamp, default is now.")
+	cmd.Flags().StringVar(&options.End, "end", "", "The end time of PromQL range query, in RFC3339 or unix timestamp, default is now.")
+	cmd.Flags().StringVar(&options.Step, "step", connector.DefaultPromQLStep, "The resolution step of PromQL range query.")
+	cmd.Flags().StringVarP(&options.Format, "output", "o", connector.OutputFormatTable, "The output format of results, like table, json or csv.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Execute on the greptimedb cluster on bare-metal environment.")
+	addDeployerFlag(cmd)
+
+	return cmd
+}
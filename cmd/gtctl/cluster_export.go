@@ -0,0 +1,368 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/gitops"
+	"github.com/GreptimeTeam/gtctl/pkg/helm"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterExportGitOpsCliOptions struct {
+	Tool            string
+	Namespace       string
+	GitOpsNamespace string
+	OutputDir       string
+	ChartRepository string
+
+	GreptimeDBChartVersion         string
+	GreptimeDBOperatorChartVersion string
+	EtcdChartVersion               string
+
+	ImageRegistry       string
+	StorageClassName    string
+	StorageSize         string
+	StorageRetainPolicy string
+
+	EtcdNamespace        string
+	EtcdStorageClassName string
+	EtcdStorageSize      string
+	EtcdClusterSize      string
+
+	SkipEtcd          bool
+	IncludeOperator   bool
+	OperatorNamespace string
+
+	Set config.SetValues
+}
+
+type clusterExportSystemdCliOptions struct {
+	OutputDir              string
+	User                   string
+	UseGreptimeCNArtifacts bool
+}
+
+func NewExportClusterCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "export",
+		Short: "Export the GreptimeDB cluster definition",
+		Long:  `Export the GreptimeDB cluster definition in the formats of other tools`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewExportGitOpsCommand(l))
+	cmd.AddCommand(NewExportSystemdCommand(l))
+
+	return cmd
+}
+
+func NewExportGitOpsCommand(l logger.Logger) *cobra.Command {
+	var options clusterExportGitOpsCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "gitops <name>",
+		Short: "Export the GreptimeDB cluster as the resources of Argo CD or Flux",
+		Long: `Export the GreptimeDB cluster as the Argo CD Applications or the Flux HelmReleases with the values of charts,
+so the cluster bootstrapped by gtctl can be handed over to the GitOps pipelines`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			return exportGitOps(l, args[0], &options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Tool, "tool", "", "The GitOps tool that the cluster is handed over to, can be 'argocd' and 'flux'.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.GitOpsNamespace, "gitops-namespace", "", "The namespace of the GitOps resources, default is 'argocd' for Argo CD and 'flux-system' for Flux.")
+	cmd.Flags().StringVar(&options.OutputDir, "output-dir", "", "Write the GitOps resources and the values of charts into the directory instead of stdout.")
+	cmd.Flags().StringVar(&options.ChartRepository, "chart-repo", "", "The private chart repository or OCI registry that the GitOps tool pulls charts from, e.g. 'https://charts.example.com' or 'oci://registry.example.com/charts'.")
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "chart-version", "", "The greptimedb helm chart version to pin, use the latest version if not specified.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "operator-chart-version", "", "The greptimedb-operator helm chart version to pin, use the latest version if not specified.")
+	cmd.Flags().StringVar(&options.EtcdChartVersion, "etcd-chart-version", artifacts.DefaultEtcdChartVersion, "The etcd helm chart version to pin.")
+	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().StringVar(&options.StorageClassName, "storage-class-name", "null", "Datanode storage class name.")
+	cmd.Flags().StringVar(&options.StorageSize, "storage-size", "10Gi", "Datanode persistent volume size.")
+	cmd.Flags().StringVar(&options.StorageRetainPolicy, "storage-retain-policy", "Retain", "Datanode pvc retain policy, can be 'Retain' and 'Delete'.")
+	cmd.Flags().StringVar(&options.EtcdNamespace, "etcd-namespace", "default", "The namespace of etcd cluster.")
+	cmd.Flags().StringVar(&options.EtcdStorageClassName, "etcd-storage-class-name", "null", "The etcd storage class name.")
+	cmd.Flags().StringVar(&options.EtcdStorageSize, "etcd-storage-size", "10Gi", "the etcd persistent volume size.")
+	cmd.Flags().StringVar(&options.EtcdClusterSize, "etcd-cluster-size", "1", "the etcd cluster size.")
+	cmd.Flags().BoolVar(&options.SkipEtcd, "skip-etcd", false, "Skip exporting the etcd cluster, e.g. it's managed outside of the GitOps pipelines.")
+	cmd.Flags().BoolVar(&options.IncludeOperator, "include-operator", false, "Export the greptimedb-operator as well.")
+	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of deploying greptimedb-operator.")
+	cmd.Flags().StringArrayVar(&options.Set.RawConfig, "set", []string{}, "set values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringArrayVar(&options.Set.ValuesFiles, "values", []string{}, "The values files deep-merged in order into the values of greptimedb cluster, etcd and operator, whose top-level keys 'cluster', 'etcd' and 'operator' are the values of each chart, the other keys are the values of cluster (can specify multiple).")
+
+	return cmd
+}
+
+func NewExportSystemdCommand(l logger.Logger) *cobra.Command {
+	var options clusterExportSystemdCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "systemd <name>",
+		Short: "Export the GreptimeDB cluster as the systemd units",
+		Long: `Export each replica of the GreptimeDB cluster in bare-metal mode as a systemd service with the same command line,
+which is ordered after the components it depends on (etcd → metasrv → datanode → frontend), and all the services are
+grouped by the target 'greptimedb-<name>.target', so the cluster created by gtctl can be handed over to systemd.
+The env of replicas is written to the environment files only readable by the owner under the cluster dir, which are
+referred by the units, so the secrets like the password of metadata store are not in the units`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			return exportSystemd(l, args[0], &options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.OutputDir, "output-dir", "", "Write the unit files into the directory instead of stdout.")
+	cmd.Flags().StringVar(&options.User, "user", "", "The user that the replicas run as, the user of systemd is used if not specified.")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries).")
+
+	return cmd
+}
+
+func exportSystemd(l logger.Logger, clusterName string, options *clusterExportSystemdCliOptions) error {
+	if len(options.OutputDir) == 0 {
+		logToStderr(l)
+	}
+
+	cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+	if err != nil {
+		return err
+	}
+	bm, _ := cluster.(*baremetal.Cluster)
+	units, err := bm.ExportSystemd(context.TODO(), &opt.ExportSystemdOptions{
+		Name:                   clusterName,
+		User:                   options.User,
+		UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(options.OutputDir) == 0 {
+		for i, unit := range units {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n%s", unit.Name, unit.Render())
+		}
+		return nil
+	}
+
+	if err = os.MkdirAll(options.OutputDir, 0755); err != nil {
+		return err
+	}
+	for _, unit := range units {
+		if err = os.WriteFile(filepath.Join(options.OutputDir, unit.Name), unit.Render(), 0644); err != nil {
+			return err
+		}
+	}
+	l.V(0).Infof("The systemd units of cluster '%s' are written to '%s'", clusterName, options.OutputDir)
+	l.V(0).Infof("To hand the cluster over to systemd after stopping it by 'gtctl cluster stop %s', copy the units into '/etc/systemd/system' and run: %s",
+		clusterName, logger.Bold(fmt.Sprintf("systemctl daemon-reload && systemctl enable --now %s", units[0].Name)))
+
+	return nil
+}
+
+func exportGitOps(l logger.Logger, clusterName string, options *clusterExportGitOpsCliOptions) error {
+	tool := gitops.Tool(options.Tool)
+	if tool != gitops.ToolArgoCD && tool != gitops.ToolFlux {
+		return fmt.Errorf("'--tool' should be '%s' or '%s'", gitops.ToolArgoCD, gitops.ToolFlux)
+	}
+
+	if len(options.OutputDir) == 0 {
+		logToStderr(l)
+	}
+
+	if err := options.Set.Parse(); err != nil {
+		return err
+	}
+
+	releases, err := gitOpsReleases(l, clusterName, options)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := gitops.Export(&gitops.ExportOptions{
+		Tool:      tool,
+		Namespace: options.GitOpsNamespace,
+		Releases:  releases,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(options.OutputDir) == 0 {
+		_, err = os.Stdout.Write(manifests)
+		return err
+	}
+
+	if err = os.MkdirAll(options.OutputDir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(options.OutputDir, fmt.Sprintf("%s-%s.yaml", tool, clusterName))
+	if err = os.WriteFile(file, manifests, 0644); err != nil {
+		return err
+	}
+	l.V(0).Infof("The %s resources of cluster '%s' are written to '%s'", tool, clusterName, file)
+
+	// The values are also written alone, so they can be reviewed and edited apart from the GitOps resources.
+	for _, release := range releases {
+		values, err := helm.Values(release.Values).OutputValues()
+		if err != nil {
+			return err
+		}
+		file = filepath.Join(options.OutputDir, fmt.Sprintf("values-%s.yaml", release.Name))
+		if err = os.WriteFile(file, values, 0644); err != nil {
+			return err
+		}
+		l.V(0).Infof("The values of release '%s' are written to '%s'", release.Name, file)
+	}
+
+	return nil
+}
+
+// gitOpsReleases returns the releases of the cluster, whose values are the same as the ones 'gtctl cluster create' installs.
+func gitOpsReleases(l logger.Logger, clusterName string, options *clusterExportGitOpsCliOptions) ([]*gitops.Release, error) {
+	am, err := artifacts.NewManager(l, artifacts.WithChartRepository(options.ChartRepository))
+	if err != nil {
+		return nil, err
+	}
+
+	repository, etcdRepository := artifacts.GreptimeChartRepositoryURL, strings.TrimSuffix(artifacts.EtcdOCIRegistry, "/"+artifacts.EtcdChartName)
+	if len(options.ChartRepository) > 0 {
+		repository, etcdRepository = strings.TrimSuffix(options.ChartRepository, "/"), strings.TrimSuffix(options.ChartRepository, "/")
+	}
+
+	var releases []*gitops.Release
+
+	if options.IncludeOperator {
+		version, err := pinnedChartVersion(l, am, artifacts.GreptimeDBOperatorChartName, options.GreptimeDBOperatorChartVersion)
+		if err != nil {
+			return nil, err
+		}
+		values, err := helm.ToHelmValues(opt.CreateOperatorOptions{
+			ImageRegistry: options.ImageRegistry,
+			ConfigValues:  options.Set.OperatorConfig,
+		}, "", options.Set.OperatorValues...)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, &gitops.Release{
+			Name:       artifacts.GreptimeDBOperatorChartName,
+			Namespace:  options.OperatorNamespace,
+			Chart:      artifacts.GreptimeDBOperatorChartName,
+			Version:    version,
+			Repository: repository,
+			Values:     values,
+		})
+	}
+
+	if !options.SkipEtcd {
+		values, err := helm.ToHelmValues(opt.CreateEtcdOptions{
+			ImageRegistry:        options.ImageRegistry,
+			EtcdStorageClassName: options.EtcdStorageClassName,
+			EtcdStorageSize:      options.EtcdStorageSize,
+			EtcdClusterSize:      options.EtcdClusterSize,
+			ConfigValues:         kubernetes.EtcdDisableRBACConfig + options.Set.EtcdConfig,
+		}, "", options.Set.EtcdValues...)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, &gitops.Release{
+			Name:       kubernetes.EtcdClusterName(clusterName),
+			Namespace:  options.EtcdNamespace,
+			Chart:      artifacts.EtcdChartName,
+			Version:    options.EtcdChartVersion,
+			Repository: etcdRepository,
+			Values:     values,
+		})
+	}
+
+	version, err := pinnedChartVersion(l, am, artifacts.GreptimeDBClusterChartName, options.GreptimeDBChartVersion)
+	if err != nil {
+		return nil, err
+	}
+	values, err := helm.ToHelmValues(opt.CreateClusterOptions{
+		ImageRegistry:               options.ImageRegistry,
+		InitializerImageRegistry:    options.ImageRegistry,
+		DatanodeStorageClassName:    options.StorageClassName,
+		DatanodeStorageSize:         options.StorageSize,
+		DatanodeStorageRetainPolicy: options.StorageRetainPolicy,
+		EtcdEndPoints:               fmt.Sprintf("%s.%s:2379", kubernetes.EtcdClusterName(clusterName), options.EtcdNamespace),
+		ConfigValues:                options.Set.ClusterConfig,
+	}, "", options.Set.ClusterValues...)
+	if err != nil {
+		return nil, err
+	}
+	releases = append(releases, &gitops.Release{
+		Name:       clusterName,
+		Namespace:  options.Namespace,
+		Chart:      artifacts.GreptimeDBClusterChartName,
+		Version:    version,
+		Repository: repository,
+		Values:     values,
+	})
+
+	return releases, nil
+}
+
+// pinnedChartVersion returns the version if it's set, or the latest version of the chart, because the GitOps
+// resources should always pin the chart version to be reproducible.
+func pinnedChartVersion(l logger.Logger, am artifacts.Manager, chart, version string) (string, error) {
+	if len(version) > 0 {
+		return version, nil
+	}
+
+	versions, err := am.ListChartVersions(context.TODO(), chart)
+	if err != nil {
+		return "", fmt.Errorf("error resolving the latest version of chart '%s', please pin it by the flags: %v", chart, err)
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no versions of chart '%s' found", chart)
+	}
+	l.V(0).Infof("Pinning chart '%s' to the latest version '%s'", chart, versions[0].Version)
+
+	return versions[0].Version, nil
+}
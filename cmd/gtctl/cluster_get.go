@@ -26,12 +26,13 @@ import (
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
-	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
 type clusterGetCliOptions struct {
-	Namespace string
+	Namespace  string
+	Output     string
+	ShowConfig bool
 
 	// The options for getting GreptimeDB cluster in bare-metal.
 	BareMetal bool
@@ -43,9 +44,10 @@ func NewGetClusterCommand(l logger.Logger) *cobra.Command {
 	table := tablewriter.NewWriter(os.Stdout)
 
 	cmd := &cobra.Command{
-		Use:   "get",
-		Short: "Get GreptimeDB cluster",
-		Long:  `Get GreptimeDB cluster`,
+		Use:               "get",
+		Short:             "Get GreptimeDB cluster",
+		Long:              `Get GreptimeDB cluster`,
+		ValidArgsFunction: completeClusterNames(false),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
@@ -54,30 +56,63 @@ func NewGetClusterCommand(l logger.Logger) *cobra.Command {
 			var (
 				ctx         = context.TODO()
 				err         error
-				cluster     opt.Operations
+				cluster     opt.Deployer
 				clusterName = args[0]
 			)
 
-			if options.BareMetal {
-				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
-			} else {
-				cluster, err = kubernetes.NewCluster(l)
+			if err = validateOutputFormat(options.Output, options.BareMetal); err != nil {
+				return err
+			}
+			if options.ShowConfig && options.Output != opt.OutputFormatTable && options.Output != opt.OutputFormatYAML {
+				return fmt.Errorf("the config of cluster is only shown in the '%s' format", opt.OutputFormatYAML)
+			}
+
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				}
 			}
 			if err != nil {
 				return err
 			}
 
 			getOptions := &opt.GetOptions{
-				Namespace: options.Namespace,
-				Name:      clusterName,
-				Table:     table,
+				Namespace:  options.Namespace,
+				Name:       clusterName,
+				Table:      table,
+				Output:     options.Output,
+				Writer:     os.Stdout,
+				ShowConfig: options.ShowConfig,
 			}
 			return cluster.Get(ctx, getOptions)
 		},
 	}
 
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of cluster, can be 'table', 'json' and 'yaml', and 'wide' on Kubernetes.")
 	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Get the greptimedb cluster on bare-metal environment.")
+	cmd.Flags().BoolVar(&options.ShowConfig, "show-config", false, "Print the effective config of cluster in yaml, which can be used to snapshot the cluster or reproduce it elsewhere.")
+	addDeployerFlag(cmd)
 
 	return cmd
 }
+
+// validateOutputFormat validates the output format of getting and listing clusters.
+func validateOutputFormat(output string, bareMetal bool) error {
+	switch output {
+	case opt.OutputFormatTable:
+		return nil
+	case opt.OutputFormatWide:
+		if bareMetal {
+			return fmt.Errorf("output format '%s' is only supported on Kubernetes", output)
+		}
+		return nil
+	case opt.OutputFormatJSON, opt.OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format '%s'", output)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+const auditConfigMapTimeout = 10 * time.Second
+
+type clusterHistoryCliOptions struct {
+	Namespace string
+	BareMetal bool
+	ConfigMap bool
+	Output    string
+}
+
+func NewHistoryCommand(l logger.Logger) *cobra.Command {
+	var options clusterHistoryCliOptions
+	table := tablewriter.NewWriter(os.Stdout)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the audit log of the mutations of GreptimeDB cluster",
+		Long: `Show the audit log of the mutations of GreptimeDB cluster, e.g. creating, scaling, upgrading and deleting,
+with the time, user, flags and result of each of them. The audit logs are kept in '~/.gtctl', and the ones of the
+clusters on Kubernetes are also kept in the ConfigMaps in their namespaces with 'gtctl config set auditConfigMap on'`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if err := opt.ValidateOutputFormat(options.Output, opt.OutputFormatTable, opt.OutputFormatJSON, opt.OutputFormatYAML); err != nil {
+				return err
+			}
+			if options.BareMetal && options.ConfigMap {
+				return fmt.Errorf("--configmap is only supported on Kubernetes")
+			}
+
+			name, namespace := args[0], options.Namespace
+			if options.BareMetal {
+				namespace = ""
+			}
+
+			var (
+				records []*audit.Record
+				err     error
+			)
+			if options.ConfigMap {
+				records, err = readAuditConfigMap(cmd, l, namespace, name)
+			} else {
+				var mm metadata.Manager
+				if mm, err = metadata.New(""); err == nil {
+					records, err = audit.Read(audit.Path(mm.GetWorkingDir(), namespace, name))
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			if opt.IsMachineReadable(options.Output) {
+				if records == nil {
+					records = []*audit.Record{}
+				}
+				return opt.RenderOutput(os.Stdout, options.Output, records)
+			}
+			if len(records) == 0 {
+				return fmt.Errorf("no history of cluster '%s'", name)
+			}
+			renderHistory(table, records)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Show the history of the greptimedb cluster on bare-metal environment or in docker containers.")
+	cmd.Flags().BoolVar(&options.ConfigMap, "configmap", false, "Show the history recorded in the ConfigMap of the cluster on Kubernetes, which is shared by all the users of the cluster.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of history, can be 'table', 'json' and 'yaml'.")
+
+	return cmd
+}
+
+// recordAudit records the mutation of the cluster by cmd into the audit log, and the ConfigMap of the cluster on Kubernetes
+// if it's enabled in the global config. It's deferred with the error of the mutation, the dry runs are not recorded, and
+// the errors of recording are only warned since the mutation is already done.
+func recordAudit(cmd *cobra.Command, l logger.Logger, operation, namespace, name string, started time.Time, err *error) {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return
+	}
+
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		flags[flag.Name] = flag.Value.String()
+	})
+
+	mode := deploymentMode(cmd)
+	if mode != globalconfig.DeploymentModeKubernetes {
+		namespace = ""
+	}
+	record := audit.NewRecord(operation, mode, namespace, name, flags, time.Since(started), *err)
+
+	mm, merr := metadata.New("")
+	if merr == nil {
+		merr = audit.Append(audit.Path(mm.GetWorkingDir(), namespace, name), record)
+	}
+	if merr != nil {
+		l.Warnf("Failed to record the audit log of cluster '%s': %v", name, merr)
+	}
+
+	if mode != globalconfig.DeploymentModeKubernetes {
+		return
+	}
+	if cfg, cerr := globalconfig.Load(""); cerr != nil || !cfg.AuditConfigMap {
+		return
+	}
+	if cerr := appendAuditConfigMap(cmd, l, namespace, name, record); cerr != nil {
+		l.Warnf("Failed to record the audit log of cluster '%s' into ConfigMap '%s': %v", name, auditConfigMapName(name), cerr)
+	}
+}
+
+// auditConfigMapName returns the name of the ConfigMap that keeps the audit log of the cluster on Kubernetes.
+func auditConfigMapName(name string) string {
+	return fmt.Sprintf("%s-gtctl-audit", name)
+}
+
+func appendAuditConfigMap(cmd *cobra.Command, l logger.Logger, namespace, name string, record *audit.Record) error {
+	client, err := newAuditClient(cmd, l, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditConfigMapTimeout)
+	defer cancel()
+
+	data, err := client.GetConfigMapData(ctx, auditConfigMapName(name), namespace)
+	if err != nil {
+		return err
+	}
+	log, err := audit.AppendLatest(data[audit.ConfigMapKey], record, audit.MaxConfigMapRecords)
+	if err != nil {
+		return err
+	}
+	return client.ApplyConfigMap(ctx, auditConfigMapName(name), namespace, map[string]string{audit.ConfigMapKey: log})
+}
+
+func readAuditConfigMap(cmd *cobra.Command, l logger.Logger, namespace, name string) ([]*audit.Record, error) {
+	client, err := newAuditClient(cmd, l, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditConfigMapTimeout)
+	defer cancel()
+
+	data, err := client.GetConfigMapData(ctx, auditConfigMapName(name), namespace)
+	if err != nil {
+		return nil, err
+	}
+	return audit.Unmarshal([]byte(data[audit.ConfigMapKey]))
+}
+
+func newAuditClient(cmd *cobra.Command, l logger.Logger, namespace, name string) (*kube.Client, error) {
+	kubeconfig, kubeContext, err := clusterKubeConfig(cmd, l, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return kube.NewClient(kubeconfig, kubeContext)
+}
+
+func renderHistory(table *tablewriter.Table, records []*audit.Record) {
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+
+	table.SetHeader([]string{"Time", "User", "Operation", "Result", "Duration", "Flags", "Error"})
+	for _, record := range records {
+		flags := make([]string, 0, len(record.Flags))
+		for k, v := range record.Flags {
+			flags = append(flags, fmt.Sprintf("--%s=%s", k, v))
+		}
+		sort.Strings(flags)
+
+		table.Append([]string{
+			record.Timestamp.Local().Format(time.RFC3339),
+			record.User,
+			record.Operation,
+			record.Result,
+			(time.Duration(record.DurationMillis) * time.Millisecond).String(),
+			strings.Join(flags, " "),
+			record.Error,
+		})
+	}
+	table.Render()
+}
@@ -18,38 +18,133 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
-	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
+type clusterListCliOptions struct {
+	Output        string
+	Namespace     string
+	AllNamespaces bool
+
+	// The options for listing GreptimeDB clusters in bare-metal.
+	BareMetal bool
+
+	// The options for listing GreptimeDB clusters on Kubernetes only.
+	Kubernetes bool
+}
+
 func NewListClustersCommand(l logger.Logger) *cobra.Command {
+	var options clusterListCliOptions
+
 	table := tablewriter.NewWriter(os.Stdout)
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all GreptimeDB clusters",
-		Long:  `List all GreptimeDB clusters`,
+		Long: `List all GreptimeDB clusters, the clusters on bare-metal environment and the ones on Kubernetes
+are listed together unless '--bare-metal' or '--kubernetes' is set.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var ctx = context.Background()
+			var (
+				ctx     = context.Background()
+				cluster opt.Deployer
+				err     error
+			)
 
-			cluster, err := kubernetes.NewCluster(l)
-			if err != nil {
+			if options.BareMetal && options.Kubernetes {
+				return fmt.Errorf("'--bare-metal' and '--kubernetes' can't be set at the same time")
+			}
+			if err = validateOutputFormat(options.Output, !options.Kubernetes); err != nil {
 				return err
 			}
+			if options.AllNamespaces {
+				options.Namespace = ""
+			}
 
-			return cluster.List(ctx, &opt.ListOptions{
+			listOptions := &opt.ListOptions{
 				GetOptions: opt.GetOptions{
-					Table: table,
+					Namespace: options.Namespace,
+					Table:     table,
+					Output:    options.Output,
+					Writer:    os.Stdout,
 				},
-			})
+			}
+
+			deployer, err := newDeployer(cmd, l)
+			if err != nil {
+				return err
+			}
+			if deployer != nil {
+				return deployer.List(ctx, listOptions)
+			}
+
+			switch {
+			case options.BareMetal:
+				// Listing clusters is not scoped to any cluster.
+				cluster, err = baremetal.NewCluster(l, "", baremetal.WithCreateNoDirs())
+			case options.Kubernetes:
+				cluster, err = newKubernetesCluster(cmd, l, "", "")
+			default:
+				if opt.IsMachineReadable(options.Output) {
+					logToStderr(l)
+				}
+				return listAllClusters(ctx, cmd, l, listOptions)
+			}
+			if err != nil {
+				return err
+			}
+
+			return cluster.List(ctx, listOptions)
 		},
 	}
 
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of clusters, can be 'table', 'json' and 'yaml', and 'wide' with '--kubernetes'.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "List the clusters on Kubernetes in the namespace, all namespaces if it's empty.")
+	cmd.Flags().BoolVarP(&options.AllNamespaces, "all-namespaces", "A", false, "List the clusters on Kubernetes in all namespaces, which overrides '--namespace'.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "List the greptimedb clusters on bare-metal environment only.")
+	cmd.Flags().BoolVar(&options.Kubernetes, "kubernetes", false, "List the greptimedb clusters on Kubernetes only.")
+	addDeployerFlag(cmd)
+
 	return cmd
 }
+
+// listAllClusters lists the clusters on bare-metal environment and the ones on Kubernetes in one view.
+// The clusters on Kubernetes are skipped if it's unreachable, since many users only run bare-metal clusters.
+func listAllClusters(ctx context.Context, cmd *cobra.Command, l logger.Logger, options *opt.ListOptions) error {
+	var summaries []*opt.ClusterSummary
+
+	bareMetalCluster, err := baremetal.NewCluster(l, "", baremetal.WithCreateNoDirs())
+	if err != nil {
+		return err
+	}
+	bareMetalSummaries, err := bareMetalCluster.(opt.Summarizer).Summarize(ctx, options.Namespace)
+	if err != nil {
+		return err
+	}
+	summaries = append(summaries, bareMetalSummaries...)
+
+	kubernetesCluster, err := newKubernetesCluster(cmd, l, "", "")
+	if err != nil {
+		l.V(1).Infof("Skip listing the clusters on Kubernetes: %v", err)
+	} else {
+		kubernetesSummaries, err := kubernetesCluster.(opt.Summarizer).Summarize(ctx, options.Namespace)
+		if err != nil {
+			l.Warnf("Failed to list the clusters on Kubernetes: %v", err)
+		}
+		summaries = append(summaries, kubernetesSummaries...)
+	}
+
+	if len(summaries) == 0 && !opt.IsMachineReadable(options.Output) {
+		return fmt.Errorf("clusters not found")
+	}
+
+	opt.SortSummaries(summaries)
+	return opt.RenderSummaries(&options.GetOptions, summaries)
+}
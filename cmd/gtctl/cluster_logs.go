@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/docker"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterLogsCliOptions struct {
+	ComponentType string
+	Tail          int
+	Follow        bool
+	Grep          []string
+	Level         string
+	Since         string
+	Until         string
+	JSON          bool
+	Docker        bool
+}
+
+func NewLogsCommand(l logger.Logger) *cobra.Command {
+	var options clusterLogsCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs of GreptimeDB cluster",
+		Long: `Print the logs of all the replicas of GreptimeDB cluster in bare-metal mode, or of the containers of cluster in docker mode.
+In bare-metal mode, the lines of all the replicas are merged in the order of timestamps, and can be filtered by
+the patterns, level and time range`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			var cluster opt.Deployer
+			var err error
+			if options.Docker {
+				cluster, err = docker.NewCluster(l)
+			} else {
+				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			}
+			if err != nil {
+				return err
+			}
+
+			return cluster.Logs(ctx, &opt.LogsOptions{
+				Name:      clusterName,
+				Component: options.ComponentType,
+				Tail:      options.Tail,
+				Follow:    options.Follow,
+				Patterns:  options.Grep,
+				Level:     options.Level,
+				Since:     options.Since,
+				Until:     options.Until,
+				JSON:      options.JSON,
+				Writer:    os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.ComponentType, "component", "c", "", "Component of GreptimeDB cluster, can be 'frontend', 'datanode', 'meta', 'flownode', 'etcd' and 'kafka', all the components if not specified.")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("component", completeComponents(true)))
+	cmd.Flags().IntVar(&options.Tail, "tail", -1, "Lines of recent logs of each replica to print before filtering, -1 means all the lines.")
+	cmd.Flags().BoolVarP(&options.Follow, "follow", "f", false, "Keep on printing the new logs.")
+	cmd.Flags().StringArrayVarP(&options.Grep, "grep", "g", nil, "Only print the lines that match the regular expression, can be repeated to match any of them.")
+	cmd.Flags().StringVar(&options.Level, "level", "", "Only print the lines of the level or higher, can be 'trace', 'debug', 'info', 'warn' and 'error'.")
+	cmd.Flags().StringVar(&options.Since, "since", "", "Only print the lines since the RFC3339 timestamp or the duration before now, e.g. '10m'.")
+	cmd.Flags().StringVar(&options.Until, "until", "", "Only print the lines until the RFC3339 timestamp or the duration before now, e.g. '1m'.")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print each line as a json object with the replica field, the json logs of components are kept as they are.")
+	cmd.Flags().BoolVar(&options.Docker, "docker", false, "Print the logs of the greptimedb cluster in docker containers.")
+
+	return cmd
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// defaultGrafanaPort is the default local port that the Grafana is forwarded to.
+const defaultGrafanaPort = 3000
+
+type clusterMonitorOpenCliOptions struct {
+	Namespace string
+	Port      int
+}
+
+type clusterMonitorServeCliOptions struct {
+	Addr string
+}
+
+type clusterMonitorExportCliOptions struct {
+	Addr      string
+	OutputDir string
+}
+
+func NewMonitorCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Manage the monitoring of GreptimeDB cluster",
+		Long: `Manage the monitoring of GreptimeDB cluster that is created with '--enable-monitoring' on Kubernetes,
+or serve the metrics of GreptimeDB cluster in bare-metal mode for the local Prometheus and Grafana`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewMonitorOpenCommand(l))
+	cmd.AddCommand(NewMonitorServeCommand(l))
+	cmd.AddCommand(NewMonitorExportCommand(l))
+
+	return cmd
+}
+
+func NewMonitorOpenCommand(l logger.Logger) *cobra.Command {
+	var options clusterMonitorOpenCliOptions
+
+	cmd := &cobra.Command{
+		Use:               "open",
+		Short:             "Open the Grafana of GreptimeDB cluster",
+		Long:              `Forward the Grafana of GreptimeDB cluster to local until it's interrupted, the dashboards of cluster are on it`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			cluster, err := newKubernetesCluster(cmd, l, options.Namespace, args[0])
+			if err != nil {
+				return err
+			}
+
+			k8s, _ := cluster.(*kubernetes.Cluster)
+			return k8s.OpenMonitor(context.TODO(), &opt.MonitorOptions{
+				Namespace: options.Namespace,
+				Name:      args[0],
+				Port:      options.Port,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().IntVarP(&options.Port, "port", "p", defaultGrafanaPort, "The local port that the Grafana is forwarded to.")
+
+	return cmd
+}
+
+func NewMonitorServeCommand(l logger.Logger) *cobra.Command {
+	var options clusterMonitorServeCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the metrics of GreptimeDB cluster in bare-metal mode",
+		Long: `Serve the metrics of all the replicas of GreptimeDB cluster in bare-metal mode on one local address until
+it's interrupted, the samples are labeled by the replica and component`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.ServeMetrics(ctx, &opt.MonitorOptions{
+				Name: clusterName,
+				Addr: options.Addr,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Addr, "addr", baremetal.DefaultMetricsAddr, "The local address that the metrics are served on.")
+
+	return cmd
+}
+
+func NewMonitorExportCommand(l logger.Logger) *cobra.Command {
+	var options clusterMonitorExportCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the Prometheus scrape config and Grafana dashboard of GreptimeDB cluster in bare-metal mode",
+		Long: `Export the Prometheus scrape config of the metrics served by 'gtctl cluster monitor serve', and the Grafana
+dashboard of GreptimeDB cluster in bare-metal mode on them`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.ExportMonitoring(context.TODO(), &opt.MonitorOptions{
+				Name:      clusterName,
+				Addr:      options.Addr,
+				OutputDir: options.OutputDir,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Addr, "addr", baremetal.DefaultMetricsAddr, "The local address that the metrics are served on.")
+	cmd.Flags().StringVarP(&options.OutputDir, "output-dir", "o", "", "The dir that the config and dashboard are written to, default is '<name>-monitoring'.")
+
+	return cmd
+}
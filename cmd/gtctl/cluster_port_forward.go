@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterPortForwardCliOptions struct {
+	Namespace  string
+	Address    string
+	PortOffset int
+}
+
+func NewPortForwardCommand(l logger.Logger) *cobra.Command {
+	var options clusterPortForwardCliOptions
+
+	cmd := &cobra.Command{
+		Use:               "port-forward",
+		Short:             "Forward the ports of GreptimeDB cluster to local",
+		Long:              `Forward the MySQL, Postgres, HTTP and gRPC ports of the frontend of GreptimeDB cluster on Kubernetes to local until it's interrupted, the dropped port-forwarding is restarted automatically`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if options.PortOffset < 0 {
+				return fmt.Errorf("port offset should be equal or greater than 0")
+			}
+
+			cluster, err := newKubernetesCluster(cmd, l, options.Namespace, args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			k8s, _ := cluster.(*kubernetes.Cluster)
+			return k8s.PortForward(ctx, &opt.PortForwardOptions{
+				Namespace:  options.Namespace,
+				Name:       args[0],
+				Address:    options.Address,
+				PortOffset: options.PortOffset,
+				Table:      tablewriter.NewWriter(os.Stdout),
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.Address, "address", "127.0.0.1", "The local address that the ports are forwarded to.")
+	cmd.Flags().IntVar(&options.PortOffset, "port-offset", 0, "The offset added to the port of each service to get its local port, e.g. 10000 forwards the MySQL port 4002 to 14002.")
+
+	return cmd
+}
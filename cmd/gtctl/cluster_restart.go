@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterRestartCliOptions struct {
+	Namespace     string
+	ComponentType string
+	Timeout       int
+
+	// The options for restarting GreptimeDB cluster in bare-metal.
+	BareMetal              bool
+	UseGreptimeCNArtifacts bool
+	DrainTimeout           int
+}
+
+func NewRestartClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterRestartCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Restart one component of GreptimeDB cluster",
+		Long: `Restart one component of GreptimeDB cluster without tearing down the whole cluster.
+On bare-metal, the restarted replicas are not supervised by the gtctl process running the cluster, i.e. they are not
+restarted on exit and their crashes are not recorded, until the cluster is stopped and started again.`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if len(options.ComponentType) == 0 {
+				return fmt.Errorf("component type is required")
+			}
+
+			var (
+				ctx         = context.Background()
+				cancel      context.CancelFunc
+				err         error
+				cluster     opt.Deployer
+				clusterName = args[0]
+			)
+
+			if options.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+				defer cancel()
+			}
+
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(),
+						baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second))
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			restartOptions := &opt.RestartOptions{
+				Namespace:              options.Namespace,
+				Name:                   clusterName,
+				ComponentType:          greptimedbclusterv1alpha1.ComponentKind(options.ComponentType),
+				UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+			}
+			return cluster.Restart(ctx, restartOptions)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.ComponentType, "component", "c", "", "Component of GreptimeDB cluster, can be 'frontend', 'datanode', 'meta' and 'flownode'.")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("component", completeComponents(false)))
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 300, "Timeout in seconds for the command to complete, -1 means no timeout.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Restart the greptimedb cluster on bare-metal environment.")
+	addDeployerFlag(cmd)
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries).")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting the component to exit gracefully before killing it.")
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterRestoreCliOptions struct {
+	Input string
+}
+
+func NewRestoreClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterRestoreCliOptions
+
+	cmd := &cobra.Command{
+		Use:               "restore",
+		Short:             "Restore GreptimeDB cluster from backup",
+		Long:              `Recreate a GreptimeDB cluster in bare-metal mode from the archive of 'gtctl cluster backup', the restored cluster is started by 'gtctl cluster start'`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if len(options.Input) == 0 {
+				return fmt.Errorf("the backup archive should be set by '--input'")
+			}
+
+			clusterName := args[0]
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			if err != nil {
+				return err
+			}
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.Restore(context.TODO(), &opt.RestoreOptions{
+				Name:  clusterName,
+				Input: options.Input,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Input, "input", "i", "", "The path of the backup archive.")
+
+	return cmd
+}
@@ -24,7 +24,9 @@ import (
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"github.com/spf13/cobra"
 
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
@@ -34,6 +36,11 @@ type clusterScaleCliOptions struct {
 	ComponentType string
 	Replicas      int32
 	Timeout       int
+
+	// The options for scaling GreptimeDB cluster in bare-metal.
+	BareMetal              bool
+	UseGreptimeCNArtifacts bool
+	DrainTimeout           int
 }
 
 func (s clusterScaleCliOptions) validate() error {
@@ -41,7 +48,9 @@ func (s clusterScaleCliOptions) validate() error {
 		return fmt.Errorf("component type is required")
 	}
 
-	if s.ComponentType != string(greptimedbclusterv1alpha1.FrontendComponentKind) &&
+	// The components of bare-metal cluster are validated when scaling, e.g. the datanode groups.
+	if !s.BareMetal &&
+		s.ComponentType != string(greptimedbclusterv1alpha1.FrontendComponentKind) &&
 		s.ComponentType != string(greptimedbclusterv1alpha1.DatanodeComponentKind) &&
 		s.ComponentType != string(greptimedbclusterv1alpha1.MetaComponentKind) {
 		return fmt.Errorf("component type is invalid")
@@ -60,8 +69,11 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "scale",
 		Short: "Scale GreptimeDB cluster",
-		Long:  `Scale GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		Long: `Scale GreptimeDB cluster, the command blocks until the scaled replicas are ready or timed out.
+On bare-metal, the new replicas are not supervised by the gtctl process running the cluster, i.e. they are not
+restarted on exit and their crashes are not recorded, until the cluster is stopped and started again.`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
 			}
@@ -69,6 +81,7 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 			if err := options.validate(); err != nil {
 				return err
 			}
+			defer recordAudit(cmd, l, audit.OperationScale, options.Namespace, args[0], time.Now(), &err)
 
 			var (
 				ctx    = context.Background()
@@ -80,25 +93,40 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 				defer cancel()
 			}
 
-			cluster, err := kubernetes.NewCluster(l)
+			var cluster opt.Deployer
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, args[0], baremetal.WithCreateNoDirs(),
+						baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second))
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, args[0], kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second))
+				}
+			}
 			if err != nil {
 				return err
 			}
 
 			scaleOptions := &opt.ScaleOptions{
-				Name:          args[0],
-				Namespace:     options.Namespace,
-				NewReplicas:   options.Replicas,
-				ComponentType: greptimedbclusterv1alpha1.ComponentKind(options.ComponentType),
+				Name:                   args[0],
+				Namespace:              options.Namespace,
+				NewReplicas:            options.Replicas,
+				ComponentType:          greptimedbclusterv1alpha1.ComponentKind(options.ComponentType),
+				UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
 			}
 			return cluster.Scale(ctx, scaleOptions)
 		},
 	}
 
 	cmd.Flags().StringVarP(&options.ComponentType, "component", "c", "", "Component of GreptimeDB cluster, can be 'frontend', 'datanode' and 'meta'.")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("component", completeComponents(false)))
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
 	cmd.Flags().Int32Var(&options.Replicas, "replicas", 0, "The replicas of component of GreptimeDB cluster.")
-	cmd.Flags().IntVar(&options.Timeout, "timeout", 300, "Timeout in seconds for the command to complete, default is no timeout.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 300, "Timeout in seconds for waiting the scaled replicas to be ready, -1 means no timeout.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Scale the greptimedb cluster on bare-metal environment, the 'flownode' and datanode groups like 'datanode-hot' can also be scaled.")
+	addDeployerFlag(cmd)
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries).")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting the replicas to exit gracefully before killing them when scaling down in bare-metal mode.")
 
 	return cmd
 }
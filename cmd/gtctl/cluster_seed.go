@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterSeedMetricsCliOptions struct {
+	Namespace     string
+	Database      string
+	TablePrefix   string
+	Tables        int
+	Hosts         int
+	RowsPerSecond int
+	Duration      time.Duration
+
+	// The options for seeding GreptimeDB cluster in bare-metal.
+	BareMetal bool
+}
+
+func NewSeedCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Seed GreptimeDB cluster with the generated data",
+		Long:  `Seed the running GreptimeDB cluster with the generated data, e.g. for demos and tests`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewSeedMetricsCommand(l))
+
+	return cmd
+}
+
+func NewSeedMetricsCommand(l logger.Logger) *cobra.Command {
+	var options clusterSeedMetricsCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Write synthetic metrics into GreptimeDB cluster continuously",
+		Long: `Write synthetic metrics into GreptimeDB cluster through the HTTP API of its frontend continuously, so the Grafana
+dashboards and queries have something to show during demos and tests. Each table has a series of each host with the
+'host' and 'region' tags and a 'value' field, and the rows are written at the current time every second until
+interrupted or the duration elapses`,
+		Example: `  gtctl cluster seed metrics mycluster --tables 10 --rows-per-sec 1000
+  gtctl cluster seed metrics mycluster --bare-metal --duration 10m`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if options.Tables <= 0 || options.Hosts <= 0 || options.RowsPerSecond <= 0 {
+				return fmt.Errorf("--tables, --hosts and --rows-per-sec should be positive")
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			seedOptions := &opt.SeedMetricsOptions{
+				Namespace: options.Namespace,
+				Name:      clusterName,
+				MetricsOptions: connector.MetricsOptions{
+					Database:      options.Database,
+					TablePrefix:   options.TablePrefix,
+					Tables:        options.Tables,
+					Hosts:         options.Hosts,
+					RowsPerSecond: options.RowsPerSecond,
+					Duration:      options.Duration,
+				},
+			}
+
+			if options.BareMetal {
+				cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				if err != nil {
+					return err
+				}
+				bm, _ := cluster.(*baremetal.Cluster)
+				return bm.SeedMetrics(ctx, seedOptions)
+			}
+
+			cluster, err := newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+			if err != nil {
+				return err
+			}
+			k8s, _ := cluster.(*kubernetes.Cluster)
+			return k8s.SeedMetrics(ctx, seedOptions)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVarP(&options.Database, "database", "d", "public", "The database that the metrics are written into, it's created if it doesn't exist.")
+	cmd.Flags().StringVar(&options.TablePrefix, "table-prefix", connector.DefaultMetricsTablePrefix, "The prefix of the names of tables, which are followed by their indexes.")
+	cmd.Flags().IntVar(&options.Tables, "tables", connector.DefaultMetricsTables, "The number of tables.")
+	cmd.Flags().IntVar(&options.Hosts, "hosts", connector.DefaultMetricsHosts, "The number of hosts, each of them has a series in every table.")
+	cmd.Flags().IntVar(&options.RowsPerSecond, "rows-per-sec", connector.DefaultMetricsRowsPerSecond, "The number of rows written per second.")
+	cmd.Flags().DurationVar(&options.Duration, "duration", 0, "How long the metrics are written for, 0 means until interrupted.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Seed the greptimedb cluster on bare-metal environment.")
+
+	return cmd
+}
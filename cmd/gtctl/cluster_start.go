@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+)
+
+type clusterStartCliOptions struct {
+	Detach                 bool
+	EnableCache            bool
+	UseGreptimeCNArtifacts bool
+	DrainTimeout           int
+	EventSinks             []string
+}
+
+func NewStartClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterStartCliOptions
+
+	cmd := &cobra.Command{
+		Use:               "start",
+		Short:             "Start a stopped GreptimeDB cluster",
+		Long:              `Start a stopped GreptimeDB cluster in bare-metal mode, which reuses the config and the data of the cluster`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			spinner, err := status.NewSpinner()
+			if err != nil {
+				return err
+			}
+
+			bus, err := newEventBus(l, options.EventSinks, "", clusterName)
+			if err != nil {
+				return err
+			}
+			defer bus.Close()
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(),
+				baremetal.WithEnableCache(options.EnableCache), baremetal.WithDetach(options.Detach),
+				baremetal.WithLogRotation(!options.Detach),
+				baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second), baremetal.WithEvents(bus))
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Starting GreptimeDB cluster '%s' on bare-metal", logger.Bold(clusterName))
+			bm, _ := cluster.(*baremetal.Cluster)
+			if err = bm.Start(ctx, &opt.StartOptions{
+				Name:                   clusterName,
+				UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+				Spinner:                spinner,
+			}); err != nil {
+				return err
+			}
+
+			return bm.Wait(ctx, false)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Detach, "detach", "d", false, "Keep the cluster running in background after gtctl exits, stop it by 'gtctl cluster stop'. The components are not supervised after gtctl exits, so it can't work with the restart policies and the log rotation.")
+	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(binaries).")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries).")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting the components to exit gracefully before killing them.")
+	addEventSinkFlag(cmd, &options.EventSinks)
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/docker"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterStatusCliOptions struct {
+	Watch    bool
+	Interval time.Duration
+	Docker   bool
+	Output   string
+}
+
+func NewStatusCommand(l logger.Logger) *cobra.Command {
+	var options clusterStatusCliOptions
+	table := tablewriter.NewWriter(os.Stdout)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Check the status of each replica of GreptimeDB cluster",
+		Long: `Check the status of each replica of GreptimeDB cluster in bare-metal mode, which tells whether
+the process of replica is dead or alive but unhealthy. The uptime, restart count, cpu and memory usage
+and endpoints of each replica are shown as well, and they are refreshed periodically with '--watch'.
+The state of the container of each replica is shown instead in docker mode`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			if err := opt.ValidateOutputFormat(options.Output, opt.OutputFormatTable, opt.OutputFormatJSON, opt.OutputFormatYAML); err != nil {
+				return err
+			}
+			if options.Watch && options.Output != opt.OutputFormatTable {
+				return fmt.Errorf("output format '%s' is not supported with --watch", options.Output)
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			statusOptions := &opt.StatusOptions{
+				Name:     clusterName,
+				Table:    table,
+				Watch:    options.Watch,
+				Interval: options.Interval,
+				Output:   options.Output,
+				Writer:   os.Stdout,
+			}
+
+			var cluster opt.Deployer
+			var err error
+			if options.Docker {
+				cluster, err = docker.NewCluster(l)
+			} else {
+				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+			}
+			if err != nil {
+				return err
+			}
+			return cluster.Status(ctx, statusOptions)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Refresh the status periodically until interrupted.")
+	cmd.Flags().DurationVar(&options.Interval, "interval", baremetal.DefaultStatusInterval, "The interval of refreshing the status in watch mode.")
+	cmd.Flags().BoolVar(&options.Docker, "docker", false, "Check the status of the greptimedb cluster in docker containers.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", opt.OutputFormatTable, "The output format of status, can be 'table', 'json' and 'yaml'.")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterStopCliOptions struct {
+	Timeout      int
+	DrainTimeout int
+}
+
+func NewStopClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterStopCliOptions
+
+	cmd := &cobra.Command{
+		Use:               "stop",
+		Short:             "Stop a running GreptimeDB cluster",
+		Long:              `Stop a running GreptimeDB cluster in bare-metal mode gracefully, the cluster can be started again by 'gtctl cluster start'`,
+		ValidArgsFunction: completeClusterNames(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			var (
+				ctx         = context.Background()
+				cancel      context.CancelFunc
+				clusterName = args[0]
+			)
+
+			if options.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+				defer cancel()
+			}
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(),
+				baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second))
+			if err != nil {
+				return err
+			}
+
+			bm, _ := cluster.(*baremetal.Cluster)
+			return bm.Stop(ctx, &opt.StopOptions{Name: clusterName})
+		},
+	}
+
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 300, "Timeout in seconds for the command to complete, -1 means no timeout.")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting each component to exit gracefully before killing it.")
+
+	return cmd
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterTopCliOptions struct {
+	Namespace string
+	Interval  time.Duration
+	Once      bool
+
+	// The options for showing the resource usage of GreptimeDB cluster in bare-metal.
+	BareMetal bool
+}
+
+func NewTopCommand(l logger.Logger) *cobra.Command {
+	var options clusterTopCliOptions
+	table := tablewriter.NewWriter(os.Stdout)
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show the live resource usage of each replica of GreptimeDB cluster",
+		Long: `Show the cpu, memory and open files of the process, and the disk usage of the data dir of each replica
+of GreptimeDB cluster in bare-metal mode, or the cpu and memory usage of each pod reported by the metrics-server
+on Kubernetes. They are refreshed periodically until interrupted unless '--once' is set`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			clusterName := args[0]
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			topOptions := &opt.TopOptions{
+				Namespace: options.Namespace,
+				Name:      clusterName,
+				Table:     table,
+				Interval:  options.Interval,
+				Once:      options.Once,
+				Writer:    os.Stdout,
+			}
+
+			if options.BareMetal {
+				cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				if err != nil {
+					return err
+				}
+				bm, _ := cluster.(*baremetal.Cluster)
+				return bm.Top(ctx, topOptions)
+			}
+
+			cluster, err := newKubernetesCluster(cmd, l, options.Namespace, clusterName)
+			if err != nil {
+				return err
+			}
+			k8s, _ := cluster.(*kubernetes.Cluster)
+			return k8s.Top(ctx, topOptions)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().DurationVar(&options.Interval, "interval", 0, "The interval of refreshing the resource usage, default is 2s in bare-metal mode and 5s on Kubernetes.")
+	cmd.Flags().BoolVar(&options.Once, "once", false, "Show the resource usage only once instead of refreshing it, the cpu usage is the average since the replica was started in bare-metal mode.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Show the resource usage of the greptimedb cluster on bare-metal environment.")
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/audit"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type clusterUpgradeCliOptions struct {
+	GreptimeVersion string
+	GreptimeBuild   string
+	Timeout         int
+
+	// The options for upgrading GreptimeDB cluster on Kubernetes.
+	Namespace                      string
+	OperatorNamespace              string
+	OperatorWatchNamespace         string
+	GreptimeDBOperatorChartVersion string
+	UseGreptimeCNArtifacts         bool
+
+	// The options for upgrading GreptimeDB cluster in bare-metal.
+	BareMetal    bool
+	EnableCache  bool
+	DrainTimeout int
+
+	EventSinks []string
+}
+
+func NewUpgradeClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterUpgradeCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the greptime version of GreptimeDB cluster",
+		Long: `Upgrade the greptime version of GreptimeDB cluster. On bare-metal, the replicas are restarted one at a time and the restarted replicas are rolled back if the upgrade fails.
+The upgraded replicas on bare-metal are not supervised by the gtctl process running the cluster until it's stopped and started again.
+On Kubernetes, the images of the cluster are upgraded and rolled out by the operator, the cluster is rolled back if the rollout fails or times out.`,
+		ValidArgsFunction: completeClusterNames(false),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+			if len(options.GreptimeBuild) > 0 {
+				if len(options.GreptimeVersion) > 0 {
+					return fmt.Errorf("the version and the build of greptime can't be both set")
+				}
+				if !options.BareMetal {
+					return fmt.Errorf("the build of greptime is only supported in bare-metal mode")
+				}
+				options.GreptimeVersion = artifacts.DevBuildVersion(options.GreptimeBuild)
+			}
+			if len(options.GreptimeVersion) == 0 {
+				return fmt.Errorf("greptime version is required")
+			}
+			var (
+				ctx         = context.Background()
+				cancel      context.CancelFunc
+				clusterName = args[0]
+			)
+			defer recordAudit(cmd, l, audit.OperationUpgrade, options.Namespace, clusterName, time.Now(), &err)
+
+			if options.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+				defer cancel()
+			}
+
+			var cluster opt.Deployer
+			cluster, err = newDeployer(cmd, l)
+			if err == nil && cluster == nil {
+				// The events of clusters in bare-metal mode have no namespace.
+				namespace := options.Namespace
+				if options.BareMetal {
+					namespace = ""
+				}
+				var bus *events.Bus
+				if bus, err = newEventBus(l, options.EventSinks, namespace, clusterName); err != nil {
+					return err
+				}
+				defer bus.Close()
+
+				if options.BareMetal {
+					cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(),
+						baremetal.WithEnableCache(options.EnableCache),
+						baremetal.WithDrainTimeout(time.Duration(options.DrainTimeout)*time.Second), baremetal.WithEvents(bus))
+				} else {
+					cluster, err = newKubernetesCluster(cmd, l, options.Namespace, clusterName,
+						kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second), kubernetes.WithEvents(bus))
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			// The namespace and the operator are ignored in bare-metal mode.
+			return cluster.Upgrade(ctx, &opt.UpgradeOptions{
+				Namespace:                      options.Namespace,
+				Name:                           clusterName,
+				GreptimeVersion:                options.GreptimeVersion,
+				UseGreptimeCNArtifacts:         options.UseGreptimeCNArtifacts,
+				GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
+				OperatorNamespace:              options.OperatorNamespace,
+				OperatorWatchNamespace:         options.OperatorWatchNamespace,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&options.GreptimeVersion, "use-greptime-version", "", "The version of greptime binary to upgrade to, e.g. 'v0.9.0', or 'nightly' for the latest nightly build in bare-metal mode.")
+	cmd.Flags().StringVar(&options.GreptimeBuild, "use-greptime-build", "", fmt.Sprintf("The commit whose greptime binary built by the CI is upgraded to in bare-metal mode, it's downloaded from GitHub Actions with the token of %s.", artifacts.GitHubTokenEnvKey))
+	cmd.Flags().StringVar(&options.GreptimeVersion, "version", "", "The version of greptime to upgrade to, the alias of '--use-greptime-version'.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of greptimedb-operator.")
+	cmd.Flags().StringVar(&options.OperatorWatchNamespace, "watch-namespace", "", "The only namespace that the upgraded greptimedb-operator watches.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "greptimedb-operator-chart-version", "", "The chart version that greptimedb-operator is upgraded to before the cluster, the operator is not upgraded if it's empty.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "chart-version", "", "The chart version to pin that greptimedb-operator is upgraded to, the alias of '--greptimedb-operator-chart-version'.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for the command to complete, -1 means no timeout.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Upgrade the greptimedb cluster on bare-metal environment.")
+	addDeployerFlag(cmd)
+	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(binaries).")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(binaries or charts).")
+	cmd.Flags().IntVar(&options.DrainTimeout, "drain-timeout", int(baremetal.DefaultDrainTimeout.Seconds()), "Timeout in seconds for waiting each replica to exit gracefully before killing it.")
+	addEventSinkFlag(cmd, &options.EventSinks)
+
+	return cmd
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// completionTimeout is the timeout of querying the Kubernetes API for the completions, so the shell is not stuck
+// when the Kubernetes cluster is unreachable.
+const completionTimeout = 5 * time.Second
+
+// namespaceFlags are the flags that are completed with the namespaces in the Kubernetes cluster.
+var namespaceFlags = []string{"namespace", "operator-namespace", "watch-namespace", "etcd-namespace", "gitops-namespace"}
+
+func NewCompletionCommand(_ logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate the autocompletion script for the specified shell",
+		Long: `Generate the autocompletion script of gtctl for the specified shell. Besides the commands and flags,
+the names of clusters, components and namespaces are completed by querying the local state of the clusters
+in bare-metal mode and the Kubernetes API.
+
+To load the completions in current shell session:
+
+  bash:       source <(gtctl completion bash)
+  zsh:        source <(gtctl completion zsh)
+  fish:       gtctl completion fish | source
+  powershell: gtctl completion powershell | Out-String | Invoke-Expression
+
+To load the completions for every new session, write the output to the completion directory of the shell,
+e.g. '/etc/bash_completion.d/gtctl' for bash and '${fpath[1]}/_gtctl' for zsh.`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			default:
+				return fmt.Errorf("unsupported shell '%s'", args[0])
+			}
+		},
+	}
+
+	return cmd
+}
+
+// completeClusterNames completes the first argument with the names of clusters. The clusters in bare-metal mode
+// are completed if bareMetal is true or '--bare-metal' is set, otherwise the clusters in the namespace of
+// '--namespace' on Kubernetes.
+func completeClusterNames(bareMetal bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var (
+			names []string
+			err   error
+		)
+		if bareMetal || isBareMetal(cmd) {
+			names, err = baremetal.ListClusterNames("")
+		} else {
+			names, err = kubernetesClusterNames(cmd)
+		}
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("error listing clusters: %v", err), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeComponents completes '--component' with the names of the components of the cluster in the first argument,
+// or the kinds of components if the cluster is unknown.
+func completeComponents(bareMetal bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names := []string{
+			string(greptimedbclusterv1alpha1.FrontendComponentKind),
+			string(greptimedbclusterv1alpha1.DatanodeComponentKind),
+			string(greptimedbclusterv1alpha1.MetaComponentKind),
+			components.FlownodeComponentName,
+		}
+
+		if len(args) > 0 {
+			var (
+				clusterNames []string
+				err          error
+			)
+			if bareMetal || isBareMetal(cmd) {
+				clusterNames, err = baremetal.ClusterComponentNames("", args[0])
+			} else {
+				clusterNames, err = kubernetesComponentNames(cmd, args[0])
+			}
+			if err == nil {
+				names = clusterNames
+			} else {
+				cobra.CompDebugln(fmt.Sprintf("error getting the components of cluster '%s': %v", args[0], err), true)
+			}
+		}
+
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeNamespaces completes the namespace flags with the namespaces in the Kubernetes cluster.
+func completeNamespaces(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	client, err := kube.NewClient(kubeConfigFlags(cmd))
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("error creating the Kubernetes client: %v", err), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	namespaces, err := client.ListNamespaces(ctx)
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("error listing namespaces: %v", err), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return filterCompletions(namespaces, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerFlagCompletions registers the completions of the namespace flags of cmd and all its subcommands.
+func registerFlagCompletions(cmd *cobra.Command) {
+	for _, name := range namespaceFlags {
+		if cmd.Flags().Lookup(name) != nil {
+			cobra.CheckErr(cmd.RegisterFlagCompletionFunc(name, completeNamespaces))
+		}
+	}
+
+	for _, subcommand := range cmd.Commands() {
+		registerFlagCompletions(subcommand)
+	}
+}
+
+// kubernetesClusterNames lists the names of the clusters and standalones in the namespace of '--namespace',
+// or in all the namespaces if the command has no such flag.
+func kubernetesClusterNames(cmd *cobra.Command) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	client, err := kube.NewClient(kubeConfigFlags(cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	inNamespace := func(ns string) bool {
+		return namespace == "" || ns == namespace
+	}
+
+	clusters, err := client.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, cluster := range clusters.Items {
+		if inNamespace(cluster.Namespace) {
+			names = append(names, cluster.Name)
+		}
+	}
+
+	// The GreptimeDBStandalone is not supported by the operators of early versions.
+	standalones, err := client.ListStandalones(ctx)
+	if err == nil {
+		for _, standalone := range standalones {
+			if inNamespace(standalone.Namespace) {
+				names = append(names, standalone.Name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// kubernetesComponentNames returns the kinds of the components deployed in the cluster on Kubernetes.
+func kubernetesComponentNames(cmd *cobra.Command, name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	client, err := kube.NewClient(kubeConfigFlags(cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	cluster, err := client.GetCluster(ctx, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if cluster.Spec.Frontend != nil {
+		names = append(names, string(greptimedbclusterv1alpha1.FrontendComponentKind))
+	}
+	if cluster.Spec.Datanode != nil {
+		names = append(names, string(greptimedbclusterv1alpha1.DatanodeComponentKind))
+	}
+	if cluster.Spec.Meta != nil {
+		names = append(names, string(greptimedbclusterv1alpha1.MetaComponentKind))
+	}
+	return names, nil
+}
+
+// isBareMetal returns whether '--bare-metal' of the command is set.
+func isBareMetal(cmd *cobra.Command) bool {
+	bareMetal, _ := cmd.Flags().GetBool("bare-metal")
+	return bareMetal
+}
+
+// filterCompletions returns the candidates that start with toComplete.
+func filterCompletions(candidates []string, toComplete string) []string {
+	var filtered []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
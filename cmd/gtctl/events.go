@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// addEventSinkFlag adds the flag of the sinks that the lifecycle events of cluster are published to.
+func addEventSinkFlag(cmd *cobra.Command, sinks *[]string) {
+	cmd.Flags().StringArrayVar(sinks, "event-sink", nil, "Publish the lifecycle events of cluster, e.g. the components are started or crashed and the cluster is ready or upgraded, to the sink, which is 'stdout', 'stderr', 'webhook:<url>' or 'slack:<url>' (can specify multiple). The 'eventSinks' in the global config are used if it's not set.")
+}
+
+// newEventBus creates the bus that publishes the events of cluster to the sinks set by the flag, or the ones in
+// the global config. It returns nil if there are no sinks, which drops the events.
+func newEventBus(l logger.Logger, specs []string, namespace, name string) (*events.Bus, error) {
+	if len(specs) == 0 {
+		cfg, err := globalconfig.Load("")
+		if err != nil {
+			return nil, err
+		}
+		specs = cfg.EventSinks
+	}
+
+	sinks, err := events.ParseSinks(specs)
+	if err != nil {
+		return nil, err
+	}
+	return events.NewBus(l, name, namespace, sinks...), nil
+}
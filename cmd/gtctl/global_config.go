@@ -0,0 +1,183 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func NewGlobalConfigCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the global config of gtctl",
+		Long: fmt.Sprintf(`Manage the defaults in '~/%s' that are shared by all the commands, the flags set in the command line
+take precedence over them. The keys are: %s`, globalconfig.File, strings.Join(globalconfig.Keys(), ", ")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewGlobalConfigSetCommand(l))
+	cmd.AddCommand(NewGlobalConfigGetCommand(l))
+	cmd.AddCommand(NewGlobalConfigListCommand(l))
+
+	return cmd
+}
+
+func NewGlobalConfigSetCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set the value of the key in the global config, the empty value unsets it",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := globalconfig.Load("")
+			if err != nil {
+				return err
+			}
+			if err = cfg.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			if err = globalconfig.Save("", cfg); err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Set '%s' to '%s'", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func NewGlobalConfigGetCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Get the value of the key in the global config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := globalconfig.Load("")
+			if err != nil {
+				return err
+			}
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("%s", value)
+			return nil
+		},
+	}
+}
+
+func NewGlobalConfigListCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all the keys and their values in the global config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := globalconfig.Load("")
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"KEY", "VALUE"})
+			for _, key := range globalconfig.Keys() {
+				value, err := cfg.Get(key)
+				if err != nil {
+					return err
+				}
+				table.Append([]string{key, value})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+}
+
+// applyGlobalConfig applies the defaults in the global config to the flags of cmd that are not set in the command line.
+func applyGlobalConfig(cmd *cobra.Command, cfg *globalconfig.Config) error {
+	flags := cmd.Flags()
+	setDefault := func(name, value string) error {
+		if value == "" {
+			return nil
+		}
+		if flag := flags.Lookup(name); flag != nil && !flag.Changed {
+			return flags.Set(name, value)
+		}
+		return nil
+	}
+
+	if cfg.LogLevel != nil {
+		if err := setDefault("verbosity", strconv.Itoa(int(*cfg.LogLevel))); err != nil {
+			return err
+		}
+	}
+
+	// The namespace of operator is not the one of the clusters.
+	if !strings.HasPrefix(cmd.CommandPath(), "gtctl operator") {
+		if err := setDefault("namespace", cfg.Namespace); err != nil {
+			return err
+		}
+	}
+
+	if err := setDefault("greptime-bin-version", cfg.GreptimeVersion); err != nil {
+		return err
+	}
+
+	switch cfg.DeploymentMode {
+	case globalconfig.DeploymentModeBareMetal, globalconfig.DeploymentModeDocker:
+		// Listing clusters discovers the clusters of all deployment modes.
+		if cmd.CommandPath() == "gtctl cluster list" {
+			break
+		}
+
+		// The deployment mode set in the command line is not overridden.
+		bareMetal, docker := flags.Lookup("bare-metal"), flags.Lookup("docker")
+		if (bareMetal == nil || !bareMetal.Changed) && (docker == nil || !docker.Changed) {
+			if err := setDefault(cfg.DeploymentMode, "true"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.TelemetryDisabled() {
+		// The greptime processes started in bare-metal mode inherit the environment of gtctl.
+		for k, v := range components.DisableTelemetryEnv() {
+			if err := os.Setenv(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+const (
+	kubeconfigFlag  = "kubeconfig"
+	kubeContextFlag = "context"
+)
+
+// addKubeConfigFlags adds the global flags that select the Kubernetes cluster to operate on.
+func addKubeConfigFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(kubeconfigFlag, "", "Path to the kubeconfig file, the default kubeconfig of kubectl is used if it's empty.")
+	cmd.PersistentFlags().String(kubeContextFlag, "", "The name of the kubeconfig context to use, the context recorded on creating the cluster or the current context is used if it's empty.")
+}
+
+// kubeConfigFlags returns the kubeconfig and context set by the global flags.
+func kubeConfigFlags(cmd *cobra.Command) (kubeconfig, kubeContext string) {
+	kubeconfig, _ = cmd.Flags().GetString(kubeconfigFlag)
+	kubeContext, _ = cmd.Flags().GetString(kubeContextFlag)
+	return kubeconfig, kubeContext
+}
+
+// newKubernetesCluster creates the operations of clusters on Kubernetes. It targets the kubeconfig and context
+// set by the global flags, or the ones recorded on creating the cluster named name in namespace if the flags
+// are not set, so the cluster is always operated on the Kubernetes cluster that it's created on.
+func newKubernetesCluster(cmd *cobra.Command, l logger.Logger, namespace, name string, opts ...kubernetes.Option) (opt.Deployer, error) {
+	kubeconfig, kubeContext, err := clusterKubeConfig(cmd, l, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewCluster(l, append(opts, kubernetes.WithKubeConfig(kubeconfig, kubeContext))...)
+}
+
+// clusterKubeConfig returns the kubeconfig and context set by the flags, or the ones that the cluster is created with.
+func clusterKubeConfig(cmd *cobra.Command, l logger.Logger, namespace, name string) (string, string, error) {
+	kubeconfig, kubeContext := kubeConfigFlags(cmd)
+	if kubeconfig == "" && kubeContext == "" && name != "" {
+		mm, err := metadata.New("")
+		if err != nil {
+			return "", "", err
+		}
+
+		recorded, err := mm.GetKubeContext(namespace, name)
+		if err != nil {
+			return "", "", err
+		}
+		if recorded != nil {
+			l.V(1).Infof("Using the context '%s' that cluster '%s' is created with", recorded.Context, name)
+			kubeconfig, kubeContext = recorded.Kubeconfig, recorded.Context
+		}
+	}
+	return kubeconfig, kubeContext, nil
+}
+
+// recordKubeContext records the kubeconfig and context that the cluster named name in namespace is created with.
+func recordKubeContext(kubeconfig, kubeContext, namespace, name string) error {
+	current, err := kube.CurrentContext(kubeconfig, kubeContext)
+	if err != nil {
+		return err
+	}
+
+	mm, err := metadata.New("")
+	if err != nil {
+		return err
+	}
+	return mm.RecordKubeContext(namespace, name, &metadata.KubeContext{Kubeconfig: kubeconfig, Context: current})
+}
+
+// removeKubeContext removes the recorded kubeconfig and context of the cluster named name in namespace.
+func removeKubeContext(namespace, name string) error {
+	mm, err := metadata.New("")
+	if err != nil {
+		return err
+	}
+	return mm.RemoveKubeContext(namespace, name)
+}
@@ -19,12 +19,19 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 	"github.com/GreptimeTeam/gtctl/pkg/plugins"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+	"github.com/GreptimeTeam/gtctl/pkg/telemetry"
 	"github.com/GreptimeTeam/gtctl/pkg/version"
 )
 
@@ -38,7 +45,9 @@ func NewRootCommand() *cobra.Command {
 /____/`
 
 	var (
-		verbosity int32
+		verbosity       int32
+		artifactMirrors []string
+		plain           bool
 
 		l = logger.New(os.Stdout, log.Level(verbosity), logger.WithColored())
 	)
@@ -50,6 +59,32 @@ func NewRootCommand() *cobra.Command {
 		Version:      version.Get().String(),
 		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// The defaults in the global config are applied to the flags that are not set in the command line.
+			cfg, err := globalconfig.Load("")
+			if err != nil {
+				return err
+			}
+			if err = applyGlobalConfig(cmd, cfg); err != nil {
+				return err
+			}
+
+			// The artifacts managers created by all the commands pick up the mirrors from the environment variable.
+			if len(artifactMirrors) > 0 {
+				if err := os.Setenv(artifacts.ArtifactMirrorsEnvKey, strings.Join(artifactMirrors, ",")); err != nil {
+					return err
+				}
+			}
+
+			// The spinners and the progress bars created by all the commands pick up the plain mode from the environment variable.
+			if plain {
+				if err := os.Setenv(status.PlainEnvKey, "true"); err != nil {
+					return err
+				}
+				if c, ok := l.(interface{ SetColored(bool) }); ok {
+					c.SetColored(false)
+				}
+			}
+
 			type verboser interface {
 				SetVerbosity(log.Level)
 			}
@@ -63,22 +98,48 @@ func NewRootCommand() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().Int32VarP(&verbosity, "verbosity", "v", 0, "info log verbosity, higher value produces more output")
+	cmd.PersistentFlags().StringSliceVar(&artifactMirrors, "artifact-mirror", nil, fmt.Sprintf("The mirrors to download the binaries and charts from in order before the official sources, "+
+		"e.g. 'https://mirror.example.com/greptime' and '%s', which override the ones set by the %s environment variable "+
+		"and the 'artifactMirrors' in '~/%s'.", artifacts.GreptimeCNMirror, artifacts.ArtifactMirrorsEnvKey, globalconfig.File))
+	cmd.PersistentFlags().BoolVar(&plain, "plain", false, "Report the progress of long running operations in plain timestamped lines without colors instead of the spinners and the progress bars, which is the default if it's not a terminal.")
+	cmd.PersistentFlags().Bool(telemetryDryRunFlag, false, fmt.Sprintf("Preview the full payload of the usage telemetry in stderr instead of reporting it, "+
+		"the telemetry is only reported if it's opted in by 'gtctl config set telemetry on' with the endpoint set by 'gtctl config set telemetryEndpoint <url>', "+
+		"and it's turned off by '%s=off'.", telemetry.EnvKey))
+	addKubeConfigFlags(cmd)
 
 	// Add all top level subcommands.
 	cmd.AddCommand(NewVersionCommand(l))
 	cmd.AddCommand(NewClusterCommand(l))
 	cmd.AddCommand(NewPlaygroundCommand(l))
+	cmd.AddCommand(NewBenchCommand(l))
+	cmd.AddCommand(NewChaosCommand(l))
+	cmd.AddCommand(NewArtifactsCommand(l))
+	cmd.AddCommand(NewOperatorCommand(l))
+	cmd.AddCommand(NewChartCommand(l))
+	cmd.AddCommand(NewSelfCommand(l))
+	cmd.AddCommand(NewGlobalConfigCommand(l))
+	cmd.AddCommand(NewCompletionCommand(l))
+	cmd.AddCommand(NewPluginCommand(l))
+
+	// The completion command of cobra is replaced by the one above.
+	cmd.CompletionOptions.DisableDefaultCmd = true
+	registerFlagCompletions(cmd)
 
 	return cmd
 }
 
 func main() {
+	// Run the binary of replica with its limits if gtctl is re-executed to do so.
+	components.ExecWithLimits()
+
 	pm, err := plugins.NewManager()
 	if err != nil {
 		panic(err)
 	}
 
-	if len(os.Args) > 1 && pm.ShouldRun(os.Args[1]) {
+	// The builtin commands take precedence over the plugins of the same names.
+	root := NewRootCommand()
+	if len(os.Args) > 1 && !isBuiltinCommand(root, os.Args[1:]) && pm.ShouldRun(os.Args[1:]...) {
 		if err = pm.Run(os.Args[1:]); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -86,7 +147,10 @@ func main() {
 		os.Exit(0)
 	}
 
-	if err = NewRootCommand().Execute(); err != nil {
+	started := time.Now()
+	cmd, err := root.ExecuteC()
+	reportUsage(cmd, time.Since(started), err)
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
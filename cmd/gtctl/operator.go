@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// operatorInstallCliOptions are the options of installing and upgrading the operator.
+type operatorInstallCliOptions struct {
+	Namespace              string
+	WatchNamespace         string
+	ChartVersion           string
+	ChartRepository        string
+	ImageRegistry          string
+	ImagePullSecrets       []string
+	ValuesFile             string
+	Set                    []string
+	UseGreptimeCNArtifacts bool
+	SkipCRDs               bool
+	DryRun                 bool
+	Timeout                int
+}
+
+func NewOperatorCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "operator",
+		Short: "Manage greptimedb-operator",
+		Long:  `Manage the lifecycle of greptimedb-operator on Kubernetes independently from the clusters`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewInstallOperatorCommand(l))
+	cmd.AddCommand(NewUpgradeOperatorCommand(l))
+	cmd.AddCommand(NewUninstallOperatorCommand(l))
+	cmd.AddCommand(NewOperatorStatusCommand(l))
+
+	return cmd
+}
+
+// addOperatorInstallFlags adds the flags of installing and upgrading the operator.
+func addOperatorInstallFlags(cmd *cobra.Command, options *operatorInstallCliOptions) {
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "The namespace of greptimedb-operator.")
+	cmd.Flags().StringVar(&options.WatchNamespace, "watch-namespace", "", "Restrict greptimedb-operator to watch only the namespace with the namespace-scoped RBAC rules, its CRDs should be installed in advance.")
+	cmd.Flags().StringVar(&options.ChartVersion, "version", "", "The greptimedb-operator helm chart version to pin, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.ChartRepository, "chart-repo", "", "The private chart repository or OCI registry to download charts from, e.g. 'https://charts.example.com' or 'oci://registry.example.com/charts'.")
+	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().StringArrayVar(&options.ImagePullSecrets, "image-pull-secret", []string{}, "The secret to pull images from the private image registry (can specify multiple).")
+	cmd.Flags().StringVar(&options.ValuesFile, "values-file", "", "The values file for greptimedb-operator.")
+	cmd.Flags().StringArrayVar(&options.Set, "set", []string{}, "Set values of greptimedb-operator chart on the command line (can specify multiple or separate values with commas: eg. key1=val1,key2=val2).")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and images).")
+	cmd.Flags().BoolVar(&options.SkipCRDs, "skip-crds", false, "Skip installing the CRDs, e.g. they are managed by the cluster admin.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Output the manifests without applying them.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for greptimedb-operator to be ready, -1 means no timeout.")
+}
+
+// newOperatorCluster creates the operations on Kubernetes to manage the operator.
+func newOperatorCluster(cmd *cobra.Command, l logger.Logger, options *operatorInstallCliOptions) (*kubernetes.Cluster, error) {
+	kubeconfig, kubeContext := kubeConfigFlags(cmd)
+	cluster, err := kubernetes.NewCluster(l,
+		kubernetes.WithDryRun(options.DryRun),
+		kubernetes.WithChartRepository(options.ChartRepository),
+		kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second),
+		kubernetes.WithKubeConfig(kubeconfig, kubeContext))
+	if err != nil {
+		return nil, err
+	}
+
+	k8s, _ := cluster.(*kubernetes.Cluster)
+	return k8s, nil
+}
+
+// createOperatorOptions converts the command line options to the options of installing the operator.
+func (o *operatorInstallCliOptions) createOperatorOptions() *opt.CreateOperatorOptions {
+	options := &opt.CreateOperatorOptions{
+		GreptimeDBOperatorChartVersion: o.ChartVersion,
+		UseGreptimeCNArtifacts:         o.UseGreptimeCNArtifacts,
+		ValuesFile:                     o.ValuesFile,
+		Namespace:                      o.Namespace,
+		WatchNamespace:                 o.WatchNamespace,
+		SkipCRDs:                       o.SkipCRDs,
+		ImageRegistry:                  o.ImageRegistry,
+		ConfigValues:                   strings.Join(o.Set, ","),
+	}
+	if len(o.ImagePullSecrets) > 0 {
+		options.ImagePullSecrets = fmt.Sprintf("{%s}", strings.Join(o.ImagePullSecrets, ","))
+	}
+	return options
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func NewInstallOperatorCommand(l logger.Logger) *cobra.Command {
+	var options operatorInstallCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install greptimedb-operator",
+		Long:  `Install greptimedb-operator and its CRDs on Kubernetes, the operator is upgraded if it's already installed`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster, err := newOperatorCluster(cmd, l, &options)
+			if err != nil {
+				return err
+			}
+
+			if err = cluster.InstallOperator(context.TODO(), options.createOperatorOptions()); err != nil {
+				return err
+			}
+			if !options.DryRun {
+				l.V(0).Infof("Operator is installed in namespace '%s'!", options.Namespace)
+			}
+			return nil
+		},
+	}
+
+	addOperatorInstallFlags(cmd, &options)
+
+	return cmd
+}
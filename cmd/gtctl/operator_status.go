@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type operatorStatusCliOptions struct {
+	Namespace string
+}
+
+func NewOperatorStatusCommand(l logger.Logger) *cobra.Command {
+	var options operatorStatusCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of greptimedb-operator",
+		Long:  `Show the chart version, image and readiness of greptimedb-operator on Kubernetes and whether its CRDs are installed`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster, err := newOperatorCluster(cmd, l, &operatorInstallCliOptions{})
+			if err != nil {
+				return err
+			}
+
+			return cluster.OperatorStatus(context.TODO(), &opt.OperatorStatusOptions{
+				Namespace: options.Namespace,
+				Table:     tablewriter.NewWriter(os.Stdout),
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "The namespace of greptimedb-operator.")
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type operatorUninstallCliOptions struct {
+	Namespace      string
+	WatchNamespace string
+	DeleteCRDs     bool
+	DryRun         bool
+}
+
+func NewUninstallOperatorCommand(l logger.Logger) *cobra.Command {
+	var options operatorUninstallCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall greptimedb-operator",
+		Long:  `Uninstall greptimedb-operator on Kubernetes, its CRDs are kept unless '--delete-crds' is set`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.DeleteCRDs && len(options.WatchNamespace) > 0 {
+				return fmt.Errorf("'--delete-crds' is not supported with '--watch-namespace', the CRDs are managed by the cluster admin")
+			}
+
+			cluster, err := newOperatorCluster(cmd, l, &operatorInstallCliOptions{DryRun: options.DryRun})
+			if err != nil {
+				return err
+			}
+
+			return cluster.UninstallOperator(context.TODO(), &opt.UninstallOperatorOptions{
+				Namespace:      options.Namespace,
+				WatchNamespace: options.WatchNamespace,
+				DeleteCRDs:     options.DeleteCRDs,
+				DryRun:         options.DryRun,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "The namespace of greptimedb-operator.")
+	cmd.Flags().StringVar(&options.WatchNamespace, "watch-namespace", "", "The namespace that greptimedb-operator is installed to watch only.")
+	cmd.Flags().BoolVar(&options.DeleteCRDs, "delete-crds", false, "Delete the CRDs of greptimedb-operator, which also deletes all the GreptimeDB clusters and standalones.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Print the resources that would be deleted without deleting them.")
+
+	return cmd
+}
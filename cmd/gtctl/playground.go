@@ -24,10 +24,10 @@ import (
 )
 
 func NewPlaygroundCommand(l logger.Logger) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "playground",
 		Short: "Starts a GreptimeDB cluster playground",
-		Long:  "Starts a GreptimeDB cluster playground in bare-metal mode",
+		Long:  "Starts a GreptimeDB cluster playground in bare-metal mode, or on a local Kubernetes cluster by 'gtctl playground up'",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			rng, err := codename.DefaultRNG()
 			if err != nil {
@@ -44,4 +44,9 @@ func NewPlaygroundCommand(l logger.Logger) *cobra.Command {
 			return NewCluster([]string{playgroundName}, playgroundOptions, l)
 		},
 	}
+
+	cmd.AddCommand(NewPlaygroundUpCommand(l))
+	cmd.AddCommand(NewPlaygroundDownCommand(l))
+
+	return cmd
 }
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/playground"
+)
+
+type playgroundDownCliOptions struct {
+	Provider    string
+	Name        string
+	ClusterName string
+	Namespace   string
+}
+
+func NewPlaygroundDownCommand(l logger.Logger) *cobra.Command {
+	var options playgroundDownCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Tears down the GreptimeDB cluster playground on the local Kubernetes cluster",
+		Long:  `Delete the local Kubernetes cluster created by 'gtctl playground up' with everything deployed on it`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider, err := playground.NewProvider(options.Provider, l)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.TODO()
+			exists, err := provider.Exists(ctx, options.Name)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("the %s cluster '%s' of playground not found", provider.Name(), options.Name)
+			}
+
+			l.V(0).Infof("Deleting the %s cluster '%s' of playground", provider.Name(), logger.Bold(options.Name))
+			if err = provider.Delete(ctx, options.Name); err != nil {
+				return err
+			}
+
+			if err = removeKubeContext(options.Namespace, options.ClusterName); err != nil {
+				l.Warnf("Failed to remove the recorded context of cluster '%s': %v", options.ClusterName, err)
+			}
+
+			l.V(0).Infof("The playground '%s' is torn down", logger.Bold(options.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Provider, "provider", playground.ProviderKind, "The provider of the local Kubernetes cluster, can be 'kind' and 'k3d'.")
+	cmd.Flags().StringVar(&options.Name, "name", playground.DefaultName, "The name of the local Kubernetes cluster.")
+	cmd.Flags().StringVar(&options.ClusterName, "cluster-name", "mycluster", "The name of GreptimeDB cluster in the playground.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster in the playground.")
+
+	return cmd
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/playground"
+)
+
+type playgroundUpCliOptions struct {
+	Provider    string
+	Name        string
+	ClusterName string
+	Namespace   string
+
+	GreptimeDBChartVersion string
+	ImageRegistry          string
+	Timeout                int
+
+	NoPortForward bool
+	PortOffset    int
+}
+
+func NewPlaygroundUpCommand(l logger.Logger) *cobra.Command {
+	var options playgroundUpCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Starts a GreptimeDB cluster playground on a local Kubernetes cluster",
+		Long: `Create a local Kubernetes cluster by kind or k3d, install greptimedb-operator, etcd and a small GreptimeDB cluster on it,
+then forward the ports of the cluster to local until it's interrupted. The existing playground is reused`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.PortOffset < 0 {
+				return fmt.Errorf("port offset should be equal or greater than 0")
+			}
+
+			return playgroundUp(l, &options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Provider, "provider", playground.ProviderKind, "The provider of the local Kubernetes cluster, can be 'kind' and 'k3d'.")
+	cmd.Flags().StringVar(&options.Name, "name", playground.DefaultName, "The name of the local Kubernetes cluster.")
+	cmd.Flags().StringVar(&options.ClusterName, "cluster-name", "mycluster", "The name of GreptimeDB cluster.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "chart-version", "", "The greptimedb helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 900, "Timeout in seconds for creating GreptimeDB cluster, -1 means no timeout, default is 15 min.")
+	cmd.Flags().BoolVar(&options.NoPortForward, "no-port-forward", false, "Don't forward the ports of GreptimeDB cluster to local after it's created.")
+	cmd.Flags().IntVar(&options.PortOffset, "port-offset", 0, "The offset added to the port of each service to get its local port, e.g. 10000 forwards the MySQL port 4002 to 14002.")
+
+	return cmd
+}
+
+func playgroundUp(l logger.Logger, options *playgroundUpCliOptions) error {
+	provider, err := playground.NewProvider(options.Provider, l)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	exists, err := provider.Exists(ctx, options.Name)
+	if err != nil {
+		return err
+	}
+	kubeContext := provider.KubeContext(options.Name)
+
+	if exists {
+		l.V(0).Infof("The %s cluster '%s' of playground already exists, reusing it", provider.Name(), logger.Bold(options.Name))
+	} else {
+		l.V(0).Infof("Creating the %s cluster '%s' of playground", provider.Name(), logger.Bold(options.Name))
+		if err = provider.Create(ctx, options.Name); err != nil {
+			return err
+		}
+
+		// A small cluster that fits in the local Kubernetes cluster, whose storage is deleted with the cluster.
+		createOptions := &clusterCreateCliOptions{
+			Namespace:              options.Namespace,
+			OperatorNamespace:      options.Namespace,
+			EtcdNamespace:          options.Namespace,
+			GreptimeDBChartVersion: options.GreptimeDBChartVersion,
+			ImageRegistry:          options.ImageRegistry,
+			StorageClassName:       "null",
+			StorageSize:            "1Gi",
+			StorageRetainPolicy:    "Delete",
+			EtcdStorageClassName:   "null",
+			EtcdStorageSize:        "1Gi",
+			EtcdClusterSize:        "1",
+			KubeContext:            kubeContext,
+			Timeout:                options.Timeout,
+		}
+		if err = NewCluster([]string{options.ClusterName}, createOptions, l); err != nil {
+			return err
+		}
+	}
+
+	if options.NoPortForward {
+		l.V(0).Infof("\nRun '%s' to forward the ports of the playground to local", logger.Bold(fmt.Sprintf(
+			"gtctl cluster port-forward %s -n %s --context %s", options.ClusterName, options.Namespace, kubeContext)))
+		return nil
+	}
+
+	cluster, err := kubernetes.NewCluster(l, kubernetes.WithKubeConfig("", kubeContext))
+	if err != nil {
+		return err
+	}
+
+	l.V(0).Infof("\nThe playground is ready, connect to GreptimeDB cluster '%s' by:", logger.Bold(options.ClusterName))
+	l.V(0).Infof("%s mysql -h 127.0.0.1 -P %d", logger.Bold("$"), 4002+options.PortOffset)
+	l.V(0).Infof("%s psql -h 127.0.0.1 -p %d -d public", logger.Bold("$"), 4003+options.PortOffset)
+	l.V(0).Infof("%s curl http://127.0.0.1:%d/health", logger.Bold("$"), 4000+options.PortOffset)
+	l.V(0).Infof("\nForwarding the ports until it's interrupted, run '%s' to tear down the playground\n",
+		logger.Bold(fmt.Sprintf("gtctl playground down --provider %s --name %s", provider.Name(), options.Name)))
+
+	k8s, _ := cluster.(*kubernetes.Cluster)
+	return k8s.PortForward(ctx, &opt.PortForwardOptions{
+		Namespace:  options.Namespace,
+		Name:       options.ClusterName,
+		Address:    "127.0.0.1",
+		PortOffset: options.PortOffset,
+		Table:      tablewriter.NewWriter(os.Stdout),
+	})
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/plugins"
+)
+
+// deployerFlag is the persistent flag of the cluster commands to operate the clusters by the custom deployer.
+const deployerFlag = "deployer"
+
+func NewPluginCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage the plugins of gtctl",
+		Long: fmt.Sprintf(`Manage the plugins of gtctl, which are discovered in '~/%s', the current working directory and $PATH,
+or only the paths set by the %s environment variable:
+
+  * The executable 'gtctl-<name>' runs as the subcommand 'gtctl <name>', and 'gtctl-foo-bar' runs as 'gtctl foo bar'.
+  * The executable 'gtctl-deployer-<name>' operates the clusters by 'gtctl cluster --deployer <name>', it's only
+    discovered in '~/%s' or the absolute paths of %s. It's run with the operation, e.g. 'create', as its
+    only arg, and the path of the JSON file of the operation options in the %s environment variable.`,
+			plugins.PluginsDir, plugins.PluginSearchPathsEnvKey, plugins.PluginsDir, plugins.PluginSearchPathsEnvKey,
+			plugins.DeployerOptionsEnvKey),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewPluginListCommand(l))
+
+	return cmd
+}
+
+func NewPluginListCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the discovered plugins",
+		Long:  `List the discovered plugins, the command plugins overshadowed by the builtin commands are never run`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pm, err := plugins.NewManager()
+			if err != nil {
+				return err
+			}
+
+			discovered, err := pm.Plugins()
+			if err != nil {
+				return err
+			}
+			if len(discovered) == 0 {
+				l.V(0).Infof("No plugins are found.")
+				return nil
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"NAME", "KIND", "PATH", "NOTE"})
+			for _, plugin := range discovered {
+				var note string
+				switch {
+				case plugin.Path == "":
+					note = "compiled into gtctl"
+				case plugin.Kind == plugins.KindCommand && isBuiltinCommand(cmd.Root(), strings.Split(plugin.Name, "-")):
+					note = "overshadowed by the builtin command"
+				}
+				table.Append([]string{plugin.Name, plugin.Kind, plugin.Path, note})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// isBuiltinCommand returns whether args run one of the builtin subcommands of root.
+func isBuiltinCommand(root *cobra.Command, args []string) bool {
+	c, _, err := root.Find(args)
+	return err == nil && c != root
+}
+
+// newDeployer creates the custom deployer set by '--deployer', it's nil if it's not set.
+func newDeployer(cmd *cobra.Command, l logger.Logger) (opt.Deployer, error) {
+	name, _ := cmd.Flags().GetString(deployerFlag)
+	if name == "" {
+		return nil, nil
+	}
+	if bareMetal, _ := cmd.Flags().GetBool("bare-metal"); bareMetal {
+		return nil, fmt.Errorf("--%s can't be set with --bare-metal", deployerFlag)
+	}
+	if docker, _ := cmd.Flags().GetBool("docker"); docker {
+		return nil, fmt.Errorf("--%s can't be set with --docker", deployerFlag)
+	}
+	return newDeployerByName(name, l)
+}
+
+func newDeployerByName(name string, l logger.Logger) (opt.Deployer, error) {
+	pm, err := plugins.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	return pm.NewDeployer(name, l)
+}
+
+func addDeployerFlag(cmd *cobra.Command) {
+	cmd.Flags().String(deployerFlag, "", "Operate the greptimedb cluster by the custom deployer, which is compiled into gtctl or run as the deployer plugin, see 'gtctl plugin --help'.")
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	semverutils "github.com/GreptimeTeam/gtctl/pkg/utils/semver"
+	"github.com/GreptimeTeam/gtctl/pkg/version"
+)
+
+type selfUpgradeCliOptions struct {
+	Version                string
+	Check                  bool
+	UseGreptimeCNArtifacts bool
+}
+
+func NewSelfCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "self",
+		Short: "Manage gtctl itself",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewSelfUpgradeCommand(l))
+
+	return cmd
+}
+
+func NewSelfUpgradeCommand(l logger.Logger) *cobra.Command {
+	var options selfUpgradeCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade gtctl to the latest or the specified version",
+		Long: `Download the gtctl release of the current OS and architecture, verify its checksum,
+and replace the running gtctl with it`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return selfUpgrade(context.TODO(), l, &options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Version, "version", artifacts.LatestVersionTag, "The version of gtctl to upgrade to.")
+	cmd.Flags().BoolVar(&options.Check, "check", false, "Only report the versions of gtctl that are available to upgrade to.")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "Download gtctl from the CN region.")
+
+	return cmd
+}
+
+func selfUpgrade(ctx context.Context, l logger.Logger, options *selfUpgradeCliOptions) error {
+	current := version.Get().GitVersion
+
+	am, err := artifacts.NewManager(l)
+	if err != nil {
+		return err
+	}
+
+	if options.Check {
+		versions, err := am.ListReleaseVersions(ctx, artifacts.GtctlBinName)
+		if err != nil {
+			return err
+		}
+
+		var available []string
+		for _, v := range versions {
+			// All the releases are available if the current version is not a release, e.g. built from source.
+			if newer, err := semverutils.Compare(v, current); err != nil || newer {
+				available = append(available, v)
+			}
+		}
+
+		l.V(0).Infof("Current version: %s", logger.Bold(current))
+		if len(available) == 0 {
+			l.V(0).Infof("gtctl is up to date.")
+			return nil
+		}
+		l.V(0).Infof("Available versions:")
+		for _, v := range available {
+			l.V(0).Infof("  %s", v)
+		}
+		return nil
+	}
+
+	src, err := am.NewSource(artifacts.GtctlBinName, options.Version, artifacts.ArtifactTypeBinary, options.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+	if src.Version == current {
+		l.V(0).Infof("gtctl is already %s.", logger.Bold(current))
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "gtctl-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	destDir := filepath.Join(tempDir, "pkg")
+	binPath, err := am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
+		BinaryInstallDir: filepath.Join(tempDir, "bin"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = fileutils.ReplaceFile(binPath, executable); err != nil {
+		return fmt.Errorf("failed to replace '%s', check whether it's writable: %v", executable, err)
+	}
+
+	l.V(0).Infof("gtctl is upgraded from %s to %s.", current, logger.Bold(src.Version))
+	return nil
+}
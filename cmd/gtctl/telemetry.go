@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+	"github.com/GreptimeTeam/gtctl/pkg/telemetry"
+	"github.com/GreptimeTeam/gtctl/pkg/version"
+)
+
+const telemetryDryRunFlag = "telemetry-dry-run"
+
+// reportUsage reports the usage of the executed command if the telemetry is opted in, or previews the full payload
+// to stderr by '--telemetry-dry-run'. The errors of reporting are ignored since they should never fail the command.
+func reportUsage(cmd *cobra.Command, elapsed time.Duration, err error) {
+	if cmd == nil || cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return
+	}
+
+	var opts []telemetry.Option
+	if dryRun, _ := cmd.Flags().GetBool(telemetryDryRunFlag); dryRun {
+		opts = append(opts, telemetry.WithPreview(os.Stderr))
+	} else {
+		cfg, err := globalconfig.Load("")
+		if err != nil || !telemetry.Enabled(cfg) {
+			return
+		}
+		opts = append(opts, telemetry.WithEndpoint(cfg.TelemetryEndpoint))
+	}
+
+	var flags []string
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		flags = append(flags, flag.Name)
+	})
+	command := strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()), " ")
+
+	event := telemetry.NewEvent(version.Get().GitVersion, command, flags, deploymentMode(cmd), elapsed, err)
+	_ = telemetry.NewReporter(opts...).Report(context.Background(), event)
+}
+
+// deploymentMode returns the deployment mode that the command runs in, it's empty if the command has no modes.
+// The names of custom deployers are not included.
+func deploymentMode(cmd *cobra.Command) string {
+	if deployer, _ := cmd.Flags().GetString(deployerFlag); deployer != "" {
+		return deployerFlag
+	}
+	for _, mode := range []string{globalconfig.DeploymentModeBareMetal, globalconfig.DeploymentModeDocker} {
+		if flag := cmd.Flags().Lookup(mode); flag != nil && flag.Value.String() == "true" {
+			return mode
+		}
+	}
+	if cmd.Flags().Lookup(globalconfig.DeploymentModeBareMetal) != nil {
+		return globalconfig.DeploymentModeKubernetes
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// NightlyVersionTag is the tag of the latest nightly build of greptime.
+	NightlyVersionTag = "nightly"
+
+	// DevBuildVersionPrefix is the prefix of the version of the development build of greptime, which is
+	// built by the CI of the commit in GitHub Actions, e.g. 'build-1a2b3c4'.
+	DevBuildVersionPrefix = "build-"
+
+	// GitHubTokenEnvKey is the environment variable of the GitHub token, which is required to download
+	// the development builds from GitHub Actions.
+	GitHubTokenEnvKey = "GITHUB_TOKEN"
+)
+
+// DevBuildVersion returns the version of the development build of the commit.
+func DevBuildVersion(commit string) string {
+	return DevBuildVersionPrefix + commit
+}
+
+// devBuildCommit returns the commit of the development build version, and false if it's not one.
+func devBuildCommit(version string) (string, bool) {
+	if !strings.HasPrefix(version, DevBuildVersionPrefix) {
+		return "", false
+	}
+	commit := strings.TrimPrefix(version, DevBuildVersionPrefix)
+	return commit, len(commit) > 0
+}
+
+// resolveNightlyVersion resolves the nightly tag to the version of the latest nightly build of greptime,
+// e.g. 'v0.4.0-nightly-20230807', from the mirrors or the GitHub releases.
+func (m *manager) resolveNightlyVersion(ctx context.Context, fromCNRegion bool) (string, error) {
+	for _, mirror := range m.sourceMirrors(fromCNRegion) {
+		version, err := m.getVersionInfoFromMirror(mirror, ArtifactTypeBinary, GreptimeBinName, true)
+		if err == nil {
+			return version, nil
+		}
+		m.logger.V(3).Infof("Failed to get the nightly version of greptime from mirror '%s': %v", mirror, err)
+	}
+
+	releases, _, err := m.githubClient().Repositories.ListReleases(ctx, GreptimeGitHubOrg, GreptimeDBGithubRepo,
+		&github.ListOptions{PerPage: 50})
+	if err != nil {
+		return "", err
+	}
+
+	// The releases are listed from the newest.
+	for _, release := range releases {
+		if strings.Contains(release.GetTagName(), NightlyVersionTag) {
+			return release.GetTagName(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no nightly release of greptime is found")
+}
+
+// setDevBuildSource locates the greptime package built by the CI of the commit for current platform,
+// and sets the download URL of it to src.
+func (m *manager) setDevBuildSource(ctx context.Context, src *Source, commit string) error {
+	if os.Getenv(GitHubTokenEnvKey) == "" {
+		return fmt.Errorf("the %s environment variable is required to download the build of commit '%s' "+
+			"from GitHub Actions", GitHubTokenEnvKey, commit)
+	}
+	client := m.githubClient()
+
+	// The short commit is resolved to the full one that the workflow runs are filtered by.
+	sha, _, err := client.Repositories.GetCommitSHA1(ctx, GreptimeGitHubOrg, GreptimeDBGithubRepo, commit, "")
+	if err != nil {
+		return fmt.Errorf("error resolving commit '%s': %v", commit, err)
+	}
+
+	runs, _, err := client.Actions.ListRepositoryWorkflowRuns(ctx, GreptimeGitHubOrg, GreptimeDBGithubRepo,
+		&github.ListWorkflowRunsOptions{HeadSHA: sha, Status: "success"})
+	if err != nil {
+		return fmt.Errorf("error listing the workflow runs of commit '%s': %v", sha, err)
+	}
+
+	// The package is named like 'greptime-linux-amd64-<version>'.
+	platform := fmt.Sprintf("%s-%s-%s", GreptimeBinName, src.Platform.OS, src.Platform.Arch)
+	for _, run := range runs.WorkflowRuns {
+		list, _, err := client.Actions.ListWorkflowRunArtifacts(ctx, GreptimeGitHubOrg, GreptimeDBGithubRepo,
+			run.GetID(), &github.ListOptions{PerPage: 100})
+		if err != nil {
+			return fmt.Errorf("error listing the artifacts of workflow run %d: %v", run.GetID(), err)
+		}
+
+		for _, artifact := range list.Artifacts {
+			if artifact.GetExpired() || !strings.HasPrefix(artifact.GetName(), platform) {
+				continue
+			}
+
+			// The artifact is downloaded from the short-lived URL that doesn't require the token.
+			downloadURL, _, err := client.Actions.DownloadArtifact(ctx, GreptimeGitHubOrg, GreptimeDBGithubRepo,
+				artifact.GetID(), false)
+			if err != nil {
+				return fmt.Errorf("error getting the download URL of artifact '%s': %v", artifact.GetName(), err)
+			}
+
+			m.logger.V(3).Infof("Found the build '%s' of commit '%s' in workflow run '%s'",
+				artifact.GetName(), sha, run.GetHTMLURL())
+			src.URL = downloadURL.String()
+			src.FileName = artifact.GetName() + fileutils.ZipExtension
+			src.Commit = sha
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no unexpired build of '%s' is found in the successful workflow runs of commit '%s'", platform, sha)
+}
+
+// githubClient returns the GitHub client, which is authenticated by the token of GITHUB_TOKEN if it's set.
+func (m *manager) githubClient() *github.Client {
+	token := os.Getenv(GitHubTokenEnvKey)
+	if token == "" {
+		return github.NewClient(m.client)
+	}
+
+	return github.NewClient(&http.Client{
+		Transport: &tokenTransport{token: token, base: m.client.Transport},
+	})
+}
+
+// tokenTransport authenticates the requests to GitHub API by the token.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
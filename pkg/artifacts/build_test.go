@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevBuildCommit(t *testing.T) {
+	commit, ok := devBuildCommit(DevBuildVersion("1a2b3c4"))
+	assert.True(t, ok)
+	assert.Equal(t, "1a2b3c4", commit)
+
+	_, ok = devBuildCommit(DevBuildVersionPrefix)
+	assert.False(t, ok)
+
+	_, ok = devBuildCommit("v0.4.1")
+	assert.False(t, ok)
+}
+
+func TestResolveNightlyVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ArtifactMirrorsEnvKey, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/greptimedb/latest-nightly-version.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("v0.4.0-nightly-20230807\n"))
+	}))
+	defer server.Close()
+
+	m := newTestManager(t, WithMirrors(server.URL))
+	version, err := m.resolveNightlyVersion(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.4.0-nightly-20230807", version)
+}
+
+func TestDevBuildRequiresToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(GitHubTokenEnvKey, "")
+
+	m := newTestManager(t)
+	_, err := m.NewSource(GreptimeBinName, DevBuildVersion("1a2b3c4"), ArtifactTypeBinary, false)
+	assert.ErrorContains(t, err, GitHubTokenEnvKey)
+}
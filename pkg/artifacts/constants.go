@@ -17,6 +17,9 @@
 package artifacts
 
 const (
+	// GreptimeChartRepositoryURL is the URL of the Greptime helm chart repository.
+	GreptimeChartRepositoryURL = "https://greptimeteam.github.io/helm-charts"
+
 	// GreptimeChartIndexURL is the URL of the Greptime chart index.
 	GreptimeChartIndexURL = "https://raw.githubusercontent.com/GreptimeTeam/helm-charts/gh-pages/index.yaml"
 
@@ -41,12 +44,18 @@ const (
 	// EtcdOCIRegistry is the OCI registry of the etcd chart.
 	EtcdOCIRegistry = "oci://registry-1.docker.io/bitnamicharts/etcd"
 
+	// KafkaOCIRegistry is the OCI registry of the kafka chart.
+	KafkaOCIRegistry = "oci://registry-1.docker.io/bitnamicharts/kafka"
+
 	// GreptimeGitHubOrg is the GitHub organization of Greptime.
 	GreptimeGitHubOrg = "GreptimeTeam"
 
 	// GreptimeDBGithubRepo is the GitHub repository of GreptimeDB.
 	GreptimeDBGithubRepo = "greptimedb"
 
+	// GtctlGithubRepo is the GitHub repository of gtctl.
+	GtctlGithubRepo = "gtctl"
+
 	// EtcdGitHubOrg is the GitHub organization of etcd.
 	EtcdGitHubOrg = "etcd-io"
 
@@ -59,6 +68,9 @@ const (
 	// EtcdBinName is the artifact name of etcd.
 	EtcdBinName = "etcd"
 
+	// GtctlBinName is the artifact name of gtctl itself.
+	GtctlBinName = "gtctl"
+
 	// GreptimeDBClusterChartName is the chart name of GreptimeDB.
 	GreptimeDBClusterChartName = "greptimedb-cluster"
 
@@ -71,6 +83,12 @@ const (
 	// DefaultEtcdChartVersion is the default etcd chart version.
 	DefaultEtcdChartVersion = "9.2.0"
 
+	// KafkaChartName is the chart name of kafka.
+	KafkaChartName = "kafka"
+
+	// DefaultKafkaChartVersion is the default kafka chart version, which runs kafka in KRaft mode.
+	DefaultKafkaChartVersion = "26.8.5"
+
 	// DefaultEtcdBinVersion is the default etcd binary version.
 	DefaultEtcdBinVersion = "v3.5.7"
 )
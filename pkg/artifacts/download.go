@@ -0,0 +1,563 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// DefaultDownloadConcurrency is the default number of the chunks that are downloaded in parallel.
+	DefaultDownloadConcurrency = 4
+
+	// DefaultDownloadRetries is the default number of retries of a failed chunk.
+	DefaultDownloadRetries = 5
+
+	// defaultRetryBackoff is the initial backoff between the retries, it doubles after every retry.
+	defaultRetryBackoff = time.Second
+
+	// maxRetryBackoff is the upper bound of the backoff between the retries.
+	maxRetryBackoff = 30 * time.Second
+
+	// minParallelDownloadSize is the minimum size of the file that is downloaded in parallel chunks,
+	// the small files like charts are not worth splitting.
+	minParallelDownloadSize = 8 << 20
+
+	// partialFileSuffix is the suffix of the file that is still being downloaded.
+	partialFileSuffix = ".part"
+
+	// progressFileSuffix is the suffix of the file next to the partial file, which records the progress of its chunks.
+	progressFileSuffix = ".progress"
+
+	// progressSaveInterval is the interval of saving the progress of chunks.
+	progressSaveInterval = time.Second
+
+	// progressRefreshInterval is the interval of refreshing the progress bar.
+	progressRefreshInterval = 200 * time.Millisecond
+
+	// plainProgressInterval is the interval of printing the plain progress lines of the file whose size is unknown.
+	plainProgressInterval = 10 * time.Second
+)
+
+// chunk is a byte range [start, end] of the file to download.
+type chunk struct {
+	start, end int64
+
+	// written is the number of bytes of the chunk that are already downloaded, it's accessed atomically.
+	written int64
+}
+
+// downloadProgress is the progress of the chunks of the partial file, which is saved next to it,
+// so the download resumes from it in the next run after it fails or gtctl is interrupted.
+type downloadProgress struct {
+	Size int64 `json:"size"`
+
+	// Chunks are the start, end and written bytes of every chunk.
+	Chunks [][3]int64 `json:"chunks"`
+}
+
+// downloadFromHTTP downloads the http URL to dest. If the server supports range requests, the file is downloaded
+// in parallel chunks and every chunk resumes from where it stopped when it fails. The file is written to
+// '<dest>.part' and only renamed to dest after the whole file is downloaded. The partial file is kept with the
+// progress of its chunks in '<dest>.part.progress' if the download fails, so the next run resumes from it.
+func (m *manager) downloadFromHTTP(ctx context.Context, httpURL string, dest string) error {
+	size, acceptRanges, err := m.probe(ctx, httpURL)
+	if err != nil {
+		return err
+	}
+
+	partialFile := dest + partialFileSuffix
+	progressFile := partialFile + progressFileSuffix
+	f, err := os.OpenFile(partialFile, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bar := newProgressBar(m.progressOutput, path.Base(dest), size)
+	defer bar.done()
+
+	if size <= 0 || !acceptRanges {
+		m.logger.V(3).Infof("The server of '%s' doesn't support range requests, download it in a single stream", httpURL)
+		// The single stream starts over, so the progress of the partial file is no longer valid.
+		if err = removeIfExists(progressFile); err != nil {
+			return err
+		}
+		err = m.downloadStream(ctx, httpURL, f, bar)
+	} else {
+		err = m.downloadChunks(ctx, httpURL, f, size, bar, progressFile)
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = removeIfExists(progressFile); err != nil {
+		return err
+	}
+	return os.Rename(partialFile, dest)
+}
+
+// probe returns the size of the http URL and whether the server supports range requests.
+func (m *manager) probe(ctx context.Context, httpURL string) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, httpURL, nil)
+	if err != nil {
+		return 0, false, err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, false, errNotFound
+	}
+
+	// Some servers don't allow HEAD requests, fall back to the single stream download.
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, nil
+	}
+
+	return resp.ContentLength, resp.Header.Get("Accept-Ranges") == "bytes", nil
+}
+
+// downloadStream downloads the whole http URL in a single request, it starts over on every retry.
+func (m *manager) downloadStream(ctx context.Context, httpURL string, f *os.File, bar *progressBar) error {
+	return m.retry(ctx, httpURL, func() error {
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		bar.reset()
+
+		resp, err := m.get(ctx, httpURL, "")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		_, err = io.Copy(f, io.TeeReader(resp.Body, bar))
+		return err
+	})
+}
+
+// downloadChunks splits the file into chunks and downloads them in parallel. It resumes from the chunks saved in
+// the progress file, and saves their progress periodically and after they fail.
+func (m *manager) downloadChunks(ctx context.Context, httpURL string, f *os.File, size int64, bar *progressBar, progressFile string) error {
+	chunks := loadChunks(progressFile, f, size)
+	if chunks != nil {
+		m.logger.V(3).Infof("Resume downloading '%s' from the progress in '%s'", httpURL, progressFile)
+		for _, c := range chunks {
+			bar.add(c.written)
+		}
+	} else {
+		if err := f.Truncate(size); err != nil {
+			return err
+		}
+		chunks = splitChunks(size, m.downloadConcurrency)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		errs error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	saved := make(chan struct{})
+	stopSaving := make(chan struct{})
+	go func() {
+		defer close(saved)
+		ticker := time.NewTicker(progressSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopSaving:
+				return
+			case <-ticker.C:
+				if err := saveChunks(progressFile, f, size, chunks); err != nil {
+					m.logger.V(3).Infof("Failed to save the progress of '%s': %v", httpURL, err)
+				}
+			}
+		}
+	}()
+
+	for _, c := range chunks {
+		wg.Add(1)
+		go func(c *chunk) {
+			defer wg.Done()
+			if err := m.downloadChunk(ctx, httpURL, f, c, bar); err != nil {
+				once.Do(func() {
+					errs = err
+					// No need to go on with the other chunks if one of them fails for good.
+					cancel()
+				})
+			}
+		}(c)
+	}
+	wg.Wait()
+	close(stopSaving)
+	<-saved
+
+	if errs != nil {
+		if err := saveChunks(progressFile, f, size, chunks); err != nil {
+			m.logger.Warnf("Failed to save the progress of '%s', it will be downloaded from the start next time: %v", httpURL, err)
+		}
+	}
+	return errs
+}
+
+// loadChunks returns the chunks in the progress file if they are of the partial file of size, or nil otherwise.
+func loadChunks(progressFile string, f *os.File, size int64) []*chunk {
+	data, err := os.ReadFile(progressFile)
+	if err != nil {
+		return nil
+	}
+	var progress downloadProgress
+	if err = json.Unmarshal(data, &progress); err != nil || progress.Size != size || len(progress.Chunks) == 0 {
+		return nil
+	}
+	if info, err := f.Stat(); err != nil || info.Size() != size {
+		return nil
+	}
+
+	chunks := make([]*chunk, 0, len(progress.Chunks))
+	for _, c := range progress.Chunks {
+		chunks = append(chunks, &chunk{start: c[0], end: c[1], written: c[2]})
+	}
+	return chunks
+}
+
+// saveChunks saves the progress of chunks to the progress file, after the written bytes are synced to
+// the partial file, so the progress never goes ahead of the data on disk.
+func saveChunks(progressFile string, f *os.File, size int64, chunks []*chunk) error {
+	progress := downloadProgress{Size: size}
+	for _, c := range chunks {
+		progress.Chunks = append(progress.Chunks, [3]int64{c.start, c.end, atomic.LoadInt64(&c.written)})
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmpFile := progressFile + ".tmp"
+	if err = os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, progressFile)
+}
+
+// removeIfExists removes the file, it returns nil if the file doesn't exist.
+func removeIfExists(file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// downloadChunk downloads a chunk of the file, every retry resumes from the bytes that are already written.
+func (m *manager) downloadChunk(ctx context.Context, httpURL string, f *os.File, c *chunk, bar *progressBar) error {
+	return m.retry(ctx, httpURL, func() error {
+		start := c.start + atomic.LoadInt64(&c.written)
+		if start > c.end {
+			return nil
+		}
+
+		resp, err := m.get(ctx, httpURL, fmt.Sprintf("bytes=%d-%d", start, c.end))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusPartialContent {
+			return permanent(fmt.Errorf("server of '%s' ignores the range request, status code: %d", httpURL, resp.StatusCode))
+		}
+
+		// The written bytes are counted on every write, so they can be saved while downloading.
+		w := &offsetWriter{w: f, offset: start, written: &c.written}
+		if _, err = io.Copy(w, io.TeeReader(resp.Body, bar)); err != nil {
+			return err
+		}
+		if end := c.start + atomic.LoadInt64(&c.written); end <= c.end {
+			return fmt.Errorf("unexpected EOF of chunk [%d, %d] at %d", c.start, c.end, end)
+		}
+		return nil
+	})
+}
+
+// offsetWriter writes to the file from the offset, and adds the number of written bytes to written.
+type offsetWriter struct {
+	w       io.WriterAt
+	offset  int64
+	written *int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	atomic.AddInt64(o.written, int64(n))
+	return n, err
+}
+
+// get sends the GET request to the http URL with the optional range header.
+func (m *manager) get(ctx context.Context, httpURL, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, permanent(errNotFound)
+	}
+
+	err = fmt.Errorf("download failed, status code: %d", resp.StatusCode)
+	// The client errors won't go away by retrying, except for the rate limiting.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, permanent(err)
+	}
+	return nil, err
+}
+
+// retry runs fn until it succeeds, fails with a permanent error or runs out of the retries.
+// The backoff between the retries doubles every time.
+func (m *manager) retry(ctx context.Context, httpURL string, fn func() error) error {
+	backoff := m.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var perr *permanentError
+		if errors.As(err, &perr) {
+			return perr.err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= m.downloadRetries {
+			return fmt.Errorf("failed to download '%s' after %d retries: %v", httpURL, m.downloadRetries, err)
+		}
+
+		m.logger.V(3).Infof("Failed to download '%s': %v, retry in %s", httpURL, err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// permanentError is the error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// splitChunks splits the file of size into at most n chunks.
+func splitChunks(size int64, n int) []*chunk {
+	if n < 1 || size < minParallelDownloadSize {
+		n = 1
+	}
+
+	chunkSize := (size + int64(n) - 1) / int64(n)
+	var chunks []*chunk
+	for start := int64(0); start < size; start += chunkSize {
+		end := start + chunkSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		chunks = append(chunks, &chunk{start: start, end: end})
+	}
+	return chunks
+}
+
+// progressBar renders the download progress on the terminal, or prints the plain progress lines on every tenth
+// of the file if it's not a terminal, it's a no-op if the output is nil.
+type progressBar struct {
+	out     io.Writer
+	plain   bool
+	name    string
+	total   int64
+	current int64
+
+	// The last printed tenth and time of the plain progress, which are only accessed by the rendering goroutine.
+	lastTenth   int64
+	lastPrinted time.Time
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// newProgressBar creates a progress bar of the file name with total bytes, total is unknown if it's not positive.
+func newProgressBar(out io.Writer, name string, total int64) *progressBar {
+	bar := &progressBar{
+		out:   out,
+		name:  name,
+		total: total,
+		stop:  make(chan struct{}),
+	}
+	if out == nil {
+		return bar
+	}
+	bar.plain = status.IsPlain(out)
+	bar.lastPrinted = time.Now()
+
+	bar.stopped.Add(1)
+	go func() {
+		defer bar.stopped.Done()
+		ticker := time.NewTicker(progressRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bar.stop:
+				bar.finish()
+				return
+			case <-ticker.C:
+				bar.render()
+			}
+		}
+	}()
+
+	return bar
+}
+
+// Write implements io.Writer to count the downloaded bytes.
+func (b *progressBar) Write(p []byte) (int, error) {
+	atomic.AddInt64(&b.current, int64(len(p)))
+	return len(p), nil
+}
+
+func (b *progressBar) add(n int64) {
+	atomic.AddInt64(&b.current, n)
+}
+
+func (b *progressBar) reset() {
+	atomic.StoreInt64(&b.current, 0)
+}
+
+func (b *progressBar) done() {
+	if b.out == nil {
+		return
+	}
+	close(b.stop)
+	b.stopped.Wait()
+}
+
+func (b *progressBar) render() {
+	if !b.plain {
+		fmt.Fprintf(b.out, "\r%s", b.String())
+		return
+	}
+
+	if b.total > 0 {
+		tenth := atomic.LoadInt64(&b.current) * 10 / b.total
+		if tenth <= b.lastTenth || tenth >= 10 {
+			return
+		}
+		b.lastTenth = tenth
+	} else if time.Since(b.lastPrinted) < plainProgressInterval {
+		return
+	}
+	b.lastPrinted = time.Now()
+	fmt.Fprint(b.out, status.PlainLine(b.String()))
+}
+
+func (b *progressBar) finish() {
+	if b.plain {
+		fmt.Fprint(b.out, status.PlainLine(b.String()))
+		return
+	}
+	b.render()
+	fmt.Fprintln(b.out)
+}
+
+// String returns the current progress, e.g. 'greptime.tgz [=========>          ]  50% 10.0MiB/20.0MiB'.
+func (b *progressBar) String() string {
+	const width = 30
+
+	current := atomic.LoadInt64(&b.current)
+	if b.total <= 0 {
+		return fmt.Sprintf("%s %s", b.name, fileutils.HumanSize(current))
+	}
+	if current > b.total {
+		current = b.total
+	}
+
+	filled := int(current * width / b.total)
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+
+	return fmt.Sprintf("%s [%s] %3d%% %s/%s", b.name, bar, current*100/b.total, fileutils.HumanSize(current), fileutils.HumanSize(b.total))
+}
+
+// defaultProgressOutput returns stderr, which is rendered as the progress bar if it's a terminal,
+// or the plain progress lines in logs.
+func defaultProgressOutput() io.Writer {
+	return os.Stderr
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func newTestManager(t *testing.T, opts ...Option) *manager {
+	opts = append([]Option{WithProgressOutput(nil)}, opts...)
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()), opts...)
+	assert.NoError(t, err)
+	mgr := m.(*manager)
+	mgr.retryBackoff = time.Millisecond
+	return mgr
+}
+
+func TestDownloadFromHTTP(t *testing.T) {
+	content := make([]byte, minParallelDownloadSize+12345)
+	rand.New(rand.NewSource(0)).Read(content)
+
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		key := r.Method + r.URL.Path
+		requests[key]++
+		attempt := requests[key]
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/ranges":
+			http.ServeContent(w, r, "greptime.tgz", time.Time{}, bytes.NewReader(content))
+		case "/flaky":
+			// The first request of every chunk is cut off in the middle, and the retry should resume from there.
+			if attempt <= DefaultDownloadConcurrency && r.Method == http.MethodGet {
+				w = &truncatedWriter{ResponseWriter: w, remaining: 512}
+			}
+			http.ServeContent(w, r, "greptime.tgz", time.Time{}, bytes.NewReader(content))
+		case "/no-ranges":
+			_, _ = w.Write(content)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"parallel chunks", "/ranges", false},
+		{"resume chunks", "/flaky", false},
+		{"single stream", "/no-ranges", false},
+		{"server error", "/broken", true},
+		{"not found", "/missing", true},
+	}
+
+	m := newTestManager(t, WithDownloadRetries(2))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "greptime.tgz")
+			err := m.downloadFromHTTP(context.Background(), server.URL+tt.path, dest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				_, err = os.Stat(dest)
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			data, err := os.ReadFile(dest)
+			assert.NoError(t, err)
+			assert.True(t, bytes.Equal(content, data))
+
+			_, err = os.Stat(dest + partialFileSuffix)
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestResumeDownloadAcrossRuns(t *testing.T) {
+	content := make([]byte, minParallelDownloadSize+12345)
+	rand.New(rand.NewSource(0)).Read(content)
+
+	// The runs download from different paths, so the late requests of the first run are told apart.
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			switch r.URL.Path {
+			case "/interrupted":
+				w = &truncatedWriter{ResponseWriter: w, remaining: 512}
+			case "/resumed":
+				mu.Lock()
+				ranges = append(ranges, r.Header.Get("Range"))
+				mu.Unlock()
+			}
+		}
+		http.ServeContent(w, r, "greptime.tgz", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	// The first run fails without retries, and keeps the partial file with its progress.
+	dest := filepath.Join(t.TempDir(), "greptime.tgz")
+	m := newTestManager(t, WithDownloadRetries(0))
+	assert.Error(t, m.downloadFromHTTP(context.Background(), server.URL+"/interrupted", dest))
+	_, err := os.Stat(dest + partialFileSuffix)
+	assert.NoError(t, err)
+	partial, err := os.Open(dest + partialFileSuffix)
+	assert.NoError(t, err)
+	chunks := loadChunks(dest+partialFileSuffix+progressFileSuffix, partial, int64(len(content)))
+	assert.NoError(t, partial.Close())
+	assert.Len(t, chunks, DefaultDownloadConcurrency)
+
+	// The next run only downloads the rest of every chunk.
+	assert.NoError(t, m.downloadFromHTTP(context.Background(), server.URL+"/resumed", dest))
+
+	var (
+		want    []string
+		resumed int64
+	)
+	for _, c := range chunks {
+		resumed += c.written
+		if c.start+c.written <= c.end {
+			want = append(want, fmt.Sprintf("bytes=%d-%d", c.start+c.written, c.end))
+		}
+	}
+	mu.Lock()
+	assert.ElementsMatch(t, want, ranges)
+	mu.Unlock()
+	// At least the chunk that failed the first run has been partly downloaded.
+	assert.GreaterOrEqual(t, resumed, int64(512))
+
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(content, data))
+	for _, suffix := range []string{partialFileSuffix, partialFileSuffix + progressFileSuffix} {
+		_, err = os.Stat(dest + suffix)
+		assert.True(t, os.IsNotExist(err))
+	}
+}
+
+// truncatedWriter drops the response body after the remaining bytes are written.
+type truncatedWriter struct {
+	http.ResponseWriter
+	remaining int
+}
+
+func (w *truncatedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		p = p[:w.remaining]
+	}
+	w.remaining -= len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func TestSplitChunks(t *testing.T) {
+	chunks := splitChunks(minParallelDownloadSize+1, 4)
+	assert.Len(t, chunks, 4)
+	assert.Equal(t, int64(0), chunks[0].start)
+	assert.Equal(t, int64(minParallelDownloadSize), chunks[3].end)
+	for i := 1; i < len(chunks); i++ {
+		assert.Equal(t, chunks[i-1].end+1, chunks[i].start)
+	}
+
+	// The small files are downloaded in a single chunk.
+	chunks = splitChunks(1024, 4)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, int64(1023), chunks[0].end)
+}
+
+func TestProgressBar(t *testing.T) {
+	bar := newProgressBar(nil, "greptime.tgz", 4<<20)
+	_, _ = bar.Write(make([]byte, 2<<20))
+	assert.Equal(t, "greptime.tgz [===============>              ]  50% 2.0MiB/4.0MiB", bar.String())
+
+	bar = newProgressBar(nil, "greptime.tgz", -1)
+	_, _ = bar.Write(make([]byte, 1536))
+	assert.Equal(t, "greptime.tgz 1.5KiB", bar.String())
+}
+
+func TestPlainProgress(t *testing.T) {
+	var out bytes.Buffer
+	bar := newProgressBar(&out, "greptime.tgz", 4<<20)
+	assert.True(t, bar.plain)
+
+	_, _ = bar.Write(make([]byte, 4<<20))
+	bar.done()
+
+	// Only the final line is printed once the whole file is downloaded.
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 1)
+	assert.True(t, strings.HasSuffix(lines[0], " greptime.tgz [==============================] 100% 4.0MiB/4.0MiB"), lines[0])
+	assert.NotContains(t, out.String(), "\r")
+}
@@ -24,10 +24,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"time"
 
-	"github.com/google/go-github/v53/github"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
@@ -48,6 +47,16 @@ type Manager interface {
 
 	// DownloadTo downloads the artifact from the source to the dest and returns the path of the artifact.
 	DownloadTo(ctx context.Context, from *Source, destDir string, opts *DownloadOptions) (string, error)
+
+	// ListChartVersions lists the versions of the chart in the chart index, the latest version comes first.
+	ListChartVersions(ctx context.Context, name string) ([]*repo.ChartVersion, error)
+
+	// ListReleaseVersions lists the versions of the binary in the GitHub releases, the latest version comes first.
+	ListReleaseVersions(ctx context.Context, name string) ([]string, error)
+
+	// BuildFromSource builds the greptime binary of src, which is created by NewSourceBuild, from the source tree
+	// and installs it to installDir, it returns the path of the installed binary.
+	BuildFromSource(ctx context.Context, src *Source, installDir string, opts *SourceBuildOptions) (string, error)
 }
 
 // ArtifactType is the type of the artifact.
@@ -72,6 +81,10 @@ type Source struct {
 	// The URL of the artifact. It can be the normal http/https URL or the OCI URL.
 	URL string
 
+	// FallbackURLs are tried in order if the artifact fails to be downloaded from URL,
+	// e.g. the mirrors that come after the first one and the official source.
+	FallbackURLs []string
+
 	// The Version of the artifact.
 	Version string
 
@@ -80,6 +93,16 @@ type Source struct {
 
 	// Indicates whether the artifact is from the CN region.
 	FromCNRegion bool
+
+	// ChecksumURL is the URL of the published sha256 checksums of the binary.
+	ChecksumURL string
+
+	// Commit is the commit of the development build of greptime or the source tree it's built from,
+	// it's empty for the releases.
+	Commit string
+
+	// Platform is the platform that the binary is built for, it's always the current platform for the charts and gtctl.
+	Platform Platform
 }
 
 // DownloadOptions is the options for downloading the artifact.
@@ -89,27 +112,130 @@ type DownloadOptions struct {
 
 	// If the artifact is a binary, the manager will install the binary to the BinaryInstallDir after downloading its package.
 	BinaryInstallDir string
+
+	// SkipVerify skips verifying the checksum and signature of the downloaded binary,
+	// e.g. for the air-gapped mirrors that don't publish them.
+	SkipVerify bool
 }
 
 // manager is the implementation of Manager interface.
 type manager struct {
 	logger logger.Logger
+
+	// chartRepository is the private chart repository, which is either the http chart repository
+	// or the OCI registry, that all the charts are downloaded from if it's set.
+	chartRepository string
+
+	client *http.Client
+
+	// mirrors are the mirrors that the artifacts are downloaded from before the official sources.
+	mirrors []string
+
+	// proxy is the HTTP(S) proxy to download the artifacts with.
+	proxy string
+
+	// downloadConcurrency is the number of the chunks of a file that are downloaded in parallel.
+	downloadConcurrency int
+
+	// downloadRetries is the number of retries of a failed download.
+	downloadRetries int
+
+	// retryBackoff is the initial backoff between the retries.
+	retryBackoff time.Duration
+
+	// progressOutput is where the progress bar of the downloads is rendered, no progress bar if it's nil.
+	progressOutput io.Writer
+
+	// platform is the platform that the binaries are downloaded for.
+	platform Platform
 }
 
 var _ Manager = &manager{}
 
 type Option func(*manager)
 
+// WithChartRepository downloads all the charts from the private chart repository, e.g. 'https://charts.example.com'
+// or 'oci://registry.example.com/charts', instead of the public ones.
+func WithChartRepository(repository string) Option {
+	return func(m *manager) {
+		m.chartRepository = strings.TrimSuffix(repository, "/")
+	}
+}
+
+// WithMirrors downloads the artifacts from the mirrors in order before the official sources, e.g.
+// 'https://mirror.example.com/greptime' and 'greptime-cn'. It overrides the mirrors set by the
+// GTCTL_ARTIFACT_MIRRORS environment variable and the global config.
+func WithMirrors(mirrors ...string) Option {
+	return func(m *manager) {
+		m.mirrors = mirrors
+	}
+}
+
+// WithProxy downloads the artifacts with the HTTP(S) proxy, which overrides the proxy in the global config
+// and the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func WithProxy(proxy string) Option {
+	return func(m *manager) {
+		m.proxy = proxy
+	}
+}
+
+// WithDownloadConcurrency downloads the large files in n parallel chunks.
+func WithDownloadConcurrency(n int) Option {
+	return func(m *manager) {
+		m.downloadConcurrency = n
+	}
+}
+
+// WithDownloadRetries retries the failed downloads n times with exponential backoff.
+func WithDownloadRetries(n int) Option {
+	return func(m *manager) {
+		m.downloadRetries = n
+	}
+}
+
+// WithProgressOutput renders the progress bar of the downloads to out, nil disables the progress bar.
+// By default, the progress is rendered to stderr, in plain lines if it's not a terminal or '--plain' is set.
+func WithProgressOutput(out io.Writer) Option {
+	return func(m *manager) {
+		m.progressOutput = out
+	}
+}
+
+// WithPlatform downloads the binaries for the platform instead of the current one, e.g. to provision
+// the remote hosts of a different architecture.
+func WithPlatform(platform Platform) Option {
+	return func(m *manager) {
+		m.platform = platform
+	}
+}
+
 // NewManager creates a new Manager with workingDir, logger and other options.
 func NewManager(logger logger.Logger, opts ...Option) (Manager, error) {
+	mirrors, proxy, err := defaultMirrorsAndProxy()
+	if err != nil {
+		return nil, err
+	}
+
 	m := &manager{
-		logger: logger,
+		logger:              logger,
+		mirrors:             mirrors,
+		proxy:               proxy,
+		downloadConcurrency: DefaultDownloadConcurrency,
+		downloadRetries:     DefaultDownloadRetries,
+		retryBackoff:        defaultRetryBackoff,
+		progressOutput:      defaultProgressOutput(),
+		platform:            CurrentPlatform(),
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
 
+	m.mirrors = resolveMirrors(m.mirrors)
+	if m.client, err = newHTTPClient(m.proxy); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
@@ -119,6 +245,29 @@ func (m *manager) NewSource(name, version string, typ ArtifactType, fromCNRegion
 		Type:         typ,
 		Version:      version,
 		FromCNRegion: fromCNRegion,
+		Platform:     m.platform,
+	}
+	if typ == ArtifactTypeChart || name == GtctlBinName {
+		// The charts are platform-independent and gtctl is upgraded in place.
+		src.Platform = CurrentPlatform()
+	}
+
+	if typ == ArtifactTypeBinary && name == GreptimeBinName {
+		// The development build is downloaded from GitHub Actions instead of the releases.
+		if commit, ok := devBuildCommit(version); ok {
+			if err := m.setDevBuildSource(context.TODO(), src, commit); err != nil {
+				return nil, err
+			}
+			return src, nil
+		}
+
+		if version == NightlyVersionTag {
+			nightlyVersion, err := m.resolveNightlyVersion(context.TODO(), fromCNRegion)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving the nightly version of greptime: %v", err)
+			}
+			src.Version = nightlyVersion
+		}
 	}
 
 	if version == LatestVersionTag || len(version) == 0 {
@@ -131,29 +280,43 @@ func (m *manager) NewSource(name, version string, typ ArtifactType, fromCNRegion
 
 	if src.Type == ArtifactTypeChart {
 		src.FileName = m.chartFileName(src.Name, src.Version)
-		if src.FromCNRegion {
-			// The download URL example: 'https://downloads.greptime.cn/releases/charts/etcd/9.2.0/etcd-9.2.0.tgz'.
-			src.URL = fmt.Sprintf("%s/%s/%s/%s", GreptimeCNCharts, src.Name, src.Version, src.FileName)
+		if len(m.chartRepository) > 0 {
+			if registry.IsOCI(m.chartRepository) {
+				// The download URL example: 'oci://registry.example.com/charts/greptimedb-cluster:0.1.2'.
+				src.URL = fmt.Sprintf("%s/%s", m.chartRepository, src.Name)
+			} else {
+				// The download URL example: 'https://charts.example.com/greptimedb-cluster-0.1.2.tgz'.
+				src.URL = fmt.Sprintf("%s/%s", m.chartRepository, src.FileName)
+			}
 		} else {
-			// Specify the OCI registry URL for the etcd chart.
-			if src.Name == EtcdChartName {
+			var originURL string
+			// Specify the OCI registry URL for the etcd and kafka charts.
+			switch src.Name {
+			case EtcdChartName:
 				// The download URL example: 'oci://registry-1.docker.io/bitnamicharts/etcd:9.2.0'.
-				src.URL = EtcdOCIRegistry
-			} else {
+				originURL = EtcdOCIRegistry
+			case KafkaChartName:
+				// The download URL example: 'oci://registry-1.docker.io/bitnamicharts/kafka:26.8.5'.
+				originURL = KafkaOCIRegistry
+			default:
 				// The download URL example: 'https://github.com/GreptimeTeam/helm-charts/releases/download/greptimedb-0.1.1-alpha.3/greptimedb-0.1.1-alpha.3.tgz'.
-				src.URL = fmt.Sprintf("%s/%s/%s", GreptimeChartReleaseDownloadURL, strings.TrimSuffix(src.FileName, fileutils.TgzExtension), src.FileName)
+				originURL = fmt.Sprintf("%s/%s/%s", GreptimeChartReleaseDownloadURL, strings.TrimSuffix(src.FileName, fileutils.TgzExtension), src.FileName)
 			}
+
+			// The download URL example in the mirror: 'https://downloads.greptime.cn/releases/charts/etcd/9.2.0/etcd-9.2.0.tgz'.
+			setSourceURLs(src, m.sourceMirrors(src.FromCNRegion), originURL)
 		}
 	}
 
 	if src.Type == ArtifactTypeBinary {
 		if src.Name == EtcdBinName {
-			downloadURL, err := m.etcdBinaryDownloadURL(src.Version, src.FromCNRegion)
+			downloadURL, err := m.etcdBinaryDownloadURL(src.Version)
 			if err != nil {
 				return nil, err
 			}
-			src.URL = downloadURL
-			src.FileName = path.Base(src.URL)
+			src.FileName = path.Base(downloadURL)
+			setSourceURLs(src, m.sourceMirrors(src.FromCNRegion), downloadURL)
+			src.ChecksumURL = binaryChecksumURL(src.Name, src.URL)
 		}
 
 		if src.Name == GreptimeBinName {
@@ -167,12 +330,20 @@ func (m *manager) NewSource(name, version string, typ ArtifactType, fromCNRegion
 				specificVersion = latestVersion
 			}
 
-			downloadURL, err := m.greptimeBinaryDownloadURL(specificVersion, src.FromCNRegion)
+			downloadURL, err := m.greptimeBinaryDownloadURL(specificVersion)
 			if err != nil {
 				return nil, err
 			}
-			src.URL = downloadURL
-			src.FileName = path.Base(src.URL)
+			src.FileName = path.Base(downloadURL)
+			setSourceURLs(src, m.sourceMirrors(src.FromCNRegion), downloadURL)
+			src.ChecksumURL = binaryChecksumURL(src.Name, src.URL)
+		}
+
+		if src.Name == GtctlBinName {
+			downloadURL := gtctlBinaryDownloadURL(src.Version)
+			src.FileName = path.Base(downloadURL)
+			setSourceURLs(src, m.sourceMirrors(src.FromCNRegion), downloadURL)
+			src.ChecksumURL = binaryChecksumURL(src.Name, src.URL)
 		}
 	}
 
@@ -205,15 +376,16 @@ func (m *manager) DownloadTo(ctx context.Context, from *Source, destDir string,
 			return "", err
 		}
 
-		// Download the helm chart from OCI registry.
-		if registry.IsOCI(from.URL) && from.Type == ArtifactTypeChart {
-			if err := m.downloadFromOCI(from.URL, from.Version, destDir); err != nil {
-				return "", err
+		var err error
+		for i, candidate := range from.candidates() {
+			if i > 0 {
+				m.logger.Warnf("Failed to download the artifact: %v, falling back to '%s'", err, candidate.URL)
+			}
+			if err = m.download(ctx, candidate, destDir, artifactFile, opts); err == nil {
+				break
 			}
-			return artifactFile, nil
 		}
-
-		if err := m.downloadFromHTTP(ctx, from.URL, artifactFile); err != nil {
+		if err != nil {
 			return "", err
 		}
 	}
@@ -225,45 +397,63 @@ func (m *manager) DownloadTo(ctx context.Context, from *Source, destDir string,
 		if err := m.installBinaries(artifactFile, opts.BinaryInstallDir); err != nil {
 			return "", err
 		}
-		return filepath.Join(filepath.Dir(destDir), "bin", from.Name), nil
+		return InstalledBinaryPath(from, destDir), nil
 	}
 
 	return artifactFile, nil
 }
 
-func (m *manager) downloadFromHTTP(ctx context.Context, httpURL string, dest string) error {
-	httpClient := &http.Client{}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
-	if err != nil {
-		return err
+// download downloads the artifact from the URL of the source to the artifactFile in destDir.
+func (m *manager) download(ctx context.Context, from *Source, destDir, artifactFile string, opts *DownloadOptions) error {
+	// Download the helm chart from OCI registry.
+	if registry.IsOCI(from.URL) && from.Type == ArtifactTypeChart {
+		return m.downloadFromOCI(from.URL, from.Version, destDir)
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return err
+	if err := m.downloadFromHTTP(ctx, from.URL, artifactFile); err != nil {
+		return fmt.Errorf("failed to download '%s': %v", from.URL, err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed, status code: %d", resp.StatusCode)
+
+	if from.Type == ArtifactTypeBinary && !opts.SkipVerify {
+		if err := m.verifyBinary(ctx, from, artifactFile); err != nil {
+			// The unverified file should not be reused as the cache.
+			os.Remove(artifactFile)
+			return err
+		}
 	}
-	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	return nil
+}
+
+// InstalledBinaryPath returns the path of binary that is installed after the artifact is downloaded to destDir.
+func InstalledBinaryPath(from *Source, destDir string) string {
+	return filepath.Join(filepath.Dir(destDir), "bin", from.Name)
+}
+
+// errNotFound is returned when the file to fetch is not published.
+var errNotFound = fmt.Errorf("download failed, status code: %d", http.StatusNotFound)
+
+// fetch returns the content of the http URL, it returns errNotFound if the URL is not found.
+func (m *manager) fetch(ctx context.Context, httpURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	file, err := os.Create(dest)
+	resp, err := m.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	_, err = file.Write(data)
-	if err != nil {
-		return err
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed, status code: %d", resp.StatusCode)
 	}
 
-	return nil
+	return io.ReadAll(resp.Body)
 }
 
 func (m *manager) downloadFromOCI(registryURL, version, dest string) error {
@@ -302,7 +492,7 @@ func (m *manager) chartIndexFile(ctx context.Context, indexURL string) (*repo.In
 		return nil, err
 	}
 
-	rsp, err := http.DefaultClient.Do(req)
+	rsp, err := m.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -344,6 +534,37 @@ func (m *manager) chartIndexFile(ctx context.Context, indexURL string) (*repo.In
 	return indexFile, nil
 }
 
+func (m *manager) ListChartVersions(ctx context.Context, name string) ([]*repo.ChartVersion, error) {
+	indexURL, err := m.chartIndexURL()
+	if err != nil {
+		return nil, err
+	}
+
+	indexFile, err := m.chartIndexFile(ctx, indexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, ok := indexFile.Entries[name]
+	if !ok {
+		return nil, fmt.Errorf("chart %s not found", name)
+	}
+
+	// The Entries are already sorted by version, the latest version comes first.
+	return versions, nil
+}
+
+// chartIndexURL returns the URL of the chart index file, which is not available in the OCI registry.
+func (m *manager) chartIndexURL() (string, error) {
+	if len(m.chartRepository) == 0 {
+		return GreptimeChartIndexURL, nil
+	}
+	if registry.IsOCI(m.chartRepository) {
+		return "", fmt.Errorf("the chart index is not available in the OCI registry '%s'", m.chartRepository)
+	}
+	return m.chartRepository + "/index.yaml", nil
+}
+
 // latestChartVersion returns the latest chart version.
 func (m *manager) latestChartVersion(indexFile *repo.IndexFile, chartName string) (*repo.ChartVersion, error) {
 	if versions, ok := indexFile.Entries[chartName]; ok {
@@ -367,38 +588,34 @@ func (m *manager) chartFileName(chartName, version string) string {
 
 // latestGitHubReleaseVersion returns the latest GitHub release version. It's used to locate the latest version of the latest greptime binary.
 func (m *manager) latestGitHubReleaseVersion(org, repo string) (string, error) {
-	client := github.NewClient(nil)
-	release, _, err := client.Repositories.GetLatestRelease(context.Background(), org, repo)
+	release, _, err := m.githubClient().Repositories.GetLatestRelease(context.Background(), org, repo)
 	if err != nil {
 		return "", err
 	}
 	return *release.TagName, nil
 }
 
-func (m *manager) etcdBinaryDownloadURL(version string, fromCNRegion bool) (string, error) {
+// etcdBinaryDownloadURL returns the download URL of the etcd binary in the GitHub release.
+func (m *manager) etcdBinaryDownloadURL(version string) (string, error) {
 	var ext string
 
-	switch runtime.GOOS {
-	case "darwin":
+	switch m.platform.OS {
+	case "darwin", "windows":
 		ext = fileutils.ZipExtension
 	case "linux":
 		ext = fileutils.TarGzExtension
 	default:
-		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+		return "", fmt.Errorf("unsupported OS: %s", m.platform.OS)
 	}
 
-	var downloadURL string
-	if fromCNRegion {
-		downloadURL = EtcdCNBinaries
-	} else {
-		downloadURL = fmt.Sprintf("https://github.com/%s/%s/releases/download", EtcdGitHubOrg, EtcdGithubRepo)
-	}
+	downloadURL := fmt.Sprintf("https://github.com/%s/%s/releases/download", EtcdGitHubOrg, EtcdGithubRepo)
 
 	// For the function stability, we always use the specific version of etcd.
-	return fmt.Sprintf("%s/%s/etcd-%s-%s-%s%s", downloadURL, version, version, runtime.GOOS, runtime.GOARCH, ext), nil
+	return fmt.Sprintf("%s/%s/etcd-%s-%s-%s%s", downloadURL, version, version, m.platform.OS, m.platform.Arch, ext), nil
 }
 
-func (m *manager) greptimeBinaryDownloadURL(version string, fromCNRegion bool) (string, error) {
+// greptimeBinaryDownloadURL returns the download URL of the greptime binary in the GitHub release.
+func (m *manager) greptimeBinaryDownloadURL(version string) (string, error) {
 	newVersion, err := isBreakingVersion(version)
 	if err != nil {
 		return "", err
@@ -406,17 +623,12 @@ func (m *manager) greptimeBinaryDownloadURL(version string, fromCNRegion bool) (
 
 	var packageName string
 	if newVersion {
-		packageName = fmt.Sprintf("greptime-%s-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH, version)
+		packageName = fmt.Sprintf("greptime-%s-%s-%s.tar.gz", m.platform.OS, m.platform.Arch, version)
 	} else {
-		packageName = fmt.Sprintf("greptime-%s-%s.tgz", runtime.GOOS, runtime.GOARCH)
+		packageName = fmt.Sprintf("greptime-%s-%s.tgz", m.platform.OS, m.platform.Arch)
 	}
 
-	var downloadURL string
-	if fromCNRegion {
-		downloadURL = GreptimeDBCNBinaries
-	} else {
-		downloadURL = fmt.Sprintf("https://github.com/%s/%s/releases/download", GreptimeGitHubOrg, GreptimeDBGithubRepo)
-	}
+	downloadURL := fmt.Sprintf("https://github.com/%s/%s/releases/download", GreptimeGitHubOrg, GreptimeDBGithubRepo)
 
 	return fmt.Sprintf("%s/%s/%s", downloadURL, version, packageName), nil
 }
@@ -437,6 +649,11 @@ func (m *manager) installBinaries(downloadFile, installDir string) error {
 		return err
 	}
 
+	// The development builds are the packages zipped by GitHub Actions.
+	if err := uncompressNested(tempDir); err != nil {
+		return err
+	}
+
 	m.logger.V(3).Infof("Installing binaries '%s' to '%s'", downloadFile, installDir)
 
 	if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
@@ -444,6 +661,17 @@ func (m *manager) installBinaries(downloadFile, installDir string) error {
 			return err
 		}
 
+		// The zip archives don't keep the permissions, the binaries of the known names are made executable.
+		if info.Mode().IsRegular() && (info.Name() == GreptimeBinName || info.Name() == EtcdBinName || info.Name() == GtctlBinName) {
+			if err := os.Chmod(path, 0755); err != nil {
+				return err
+			}
+			info, err = os.Stat(path)
+			if err != nil {
+				return err
+			}
+		}
+
 		if info.Mode().IsRegular() && (info.Mode()&0111 != 0) { // Move the executable file to the installDir.
 			newFilePath := filepath.Join(installDir, info.Name())
 			if path != newFilePath {
@@ -461,16 +689,52 @@ func (m *manager) installBinaries(downloadFile, installDir string) error {
 	return nil
 }
 
+// uncompressNested uncompresses the archives in dir into their directories.
+func uncompressNested(dir string) error {
+	var archives []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && (strings.HasSuffix(path, fileutils.TarGzExtension) ||
+			strings.HasSuffix(path, fileutils.TgzExtension) || strings.HasSuffix(path, fileutils.ZipExtension)) {
+			archives = append(archives, path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, archive := range archives {
+		if err := fileutils.Uncompress(archive, filepath.Dir(archive)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // resolveLatestVersion resolves the latest tag to the specific version.
 func (m *manager) resolveLatestVersion(typ ArtifactType, name string, fromCNRegion bool) (string, error) {
-	if fromCNRegion {
-		return m.getVersionInfoFromS3(typ, name, false)
+	// The charts are always downloaded from the private chart repository if it's set.
+	if typ != ArtifactTypeChart || len(m.chartRepository) == 0 {
+		for _, mirror := range m.sourceMirrors(fromCNRegion) {
+			version, err := m.getVersionInfoFromMirror(mirror, typ, name, false)
+			if err == nil {
+				return version, nil
+			}
+			m.logger.V(3).Infof("Failed to get the latest version of '%s' from mirror '%s': %v", name, mirror, err)
+		}
 	}
 
 	switch typ {
 	case ArtifactTypeChart:
+		indexURL, err := m.chartIndexURL()
+		if err != nil {
+			return "", fmt.Errorf("the version of chart '%s' should be specified: %v", name, err)
+		}
+
 		// Use chart index file to locate the latest chart version.
-		indexFile, err := m.chartIndexFile(context.TODO(), GreptimeChartIndexURL)
+		indexFile, err := m.chartIndexFile(context.TODO(), indexURL)
 		if err != nil {
 			return "", err
 		}
@@ -481,8 +745,12 @@ func (m *manager) resolveLatestVersion(typ ArtifactType, name string, fromCNRegi
 		}
 		return chartVersion.Version, nil
 	case ArtifactTypeBinary:
-		// Get the latest version of the latest greptime binary.
-		latestVersion, err := m.latestGitHubReleaseVersion(GreptimeGitHubOrg, GreptimeDBGithubRepo)
+		// Get the latest version of the latest greptime or gtctl binary.
+		org, repo := GreptimeGitHubOrg, GreptimeDBGithubRepo
+		if name == GtctlBinName {
+			repo = GtctlGithubRepo
+		}
+		latestVersion, err := m.latestGitHubReleaseVersion(org, repo)
 		if err != nil {
 			return "", err
 		}
@@ -492,8 +760,8 @@ func (m *manager) resolveLatestVersion(typ ArtifactType, name string, fromCNRegi
 	}
 }
 
-// getVersionInfoFromS3 gets the latest version info from S3.
-func (m *manager) getVersionInfoFromS3(typ ArtifactType, name string, nightly bool) (string, error) {
+// getVersionInfoFromMirror gets the latest version info from the mirror, e.g. the S3 bucket in CN region.
+func (m *manager) getVersionInfoFromMirror(mirror string, typ ArtifactType, name string, nightly bool) (string, error) {
 	// Note: it uses 'greptimedb' directory to store the greptime binary.
 	if name == GreptimeBinName {
 		name = "greptimedb"
@@ -501,12 +769,12 @@ func (m *manager) getVersionInfoFromS3(typ ArtifactType, name string, nightly bo
 	var latestVersionInfoURL string
 	switch typ {
 	case ArtifactTypeChart:
-		latestVersionInfoURL = fmt.Sprintf("%s/charts/%s/latest-version.txt", GreptimeReleaseBucketCN, name)
+		latestVersionInfoURL = fmt.Sprintf("%s/charts/%s/latest-version.txt", mirror, name)
 	case ArtifactTypeBinary:
 		if nightly {
-			latestVersionInfoURL = fmt.Sprintf("%s/%s/latest-nightly-version.txt", GreptimeReleaseBucketCN, name)
+			latestVersionInfoURL = fmt.Sprintf("%s/%s/latest-nightly-version.txt", mirror, name)
 		} else {
-			latestVersionInfoURL = fmt.Sprintf("%s/%s/latest-version.txt", GreptimeReleaseBucketCN, name)
+			latestVersionInfoURL = fmt.Sprintf("%s/%s/latest-version.txt", mirror, name)
 		}
 	default:
 		return "", fmt.Errorf("unsupported artifact type: %s", string(typ))
@@ -517,8 +785,7 @@ func (m *manager) getVersionInfoFromS3(typ ArtifactType, name string, nightly bo
 		return "", err
 	}
 
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := m.client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -19,6 +19,8 @@ package artifacts
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -282,3 +284,90 @@ func destDir(workingDir string, src *Source) string {
 
 	return fmt.Sprintf("%s/artifacts/%s/%s/%s/pkg", workingDir, artifactsDir, src.Name, src.Version)
 }
+
+func TestNewSourceWithChartRepository(t *testing.T) {
+	l := logger.New(os.Stdout, log.Level(4), logger.WithColored())
+
+	tests := []struct {
+		repository string
+		name       string
+		version    string
+		url        string
+		err        bool
+	}{
+		{"https://charts.example.com/", GreptimeDBClusterChartName, "0.1.2", "https://charts.example.com/greptimedb-cluster-0.1.2.tgz", false},
+		{"oci://registry.example.com/charts", EtcdChartName, DefaultEtcdChartVersion, "oci://registry.example.com/charts/etcd", false},
+		{"oci://registry.example.com/charts", GreptimeDBOperatorChartName, LatestVersionTag, "", true},
+	}
+	for _, tt := range tests {
+		m, err := NewManager(l, WithChartRepository(tt.repository))
+		if err != nil {
+			t.Fatalf("failed to create artifacts manager: %v", err)
+		}
+
+		src, err := m.NewSource(tt.name, tt.version, ArtifactTypeChart, false)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected error for '%s' of '%s'", tt.name, tt.repository)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to create source: %v", err)
+		}
+		if src.URL != tt.url {
+			t.Errorf("expected URL '%s', got '%s'", tt.url, src.URL)
+		}
+	}
+}
+
+func TestListChartVersions(t *testing.T) {
+	const index = `apiVersion: v1
+entries:
+  greptimedb-cluster:
+  - name: greptimedb-cluster
+    version: 0.1.2
+    appVersion: 0.4.1
+    urls:
+    - greptimedb-cluster-0.1.2.tgz
+  - name: greptimedb-cluster
+    version: 0.1.10
+    appVersion: 0.4.4
+    urls:
+    - greptimedb-cluster-0.1.10.tgz
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	l := logger.New(os.Stdout, log.Level(4), logger.WithColored())
+	m, err := NewManager(l, WithChartRepository(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	versions, err := m.ListChartVersions(context.Background(), GreptimeDBClusterChartName)
+	if err != nil {
+		t.Fatalf("failed to list chart versions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "0.1.10" || versions[1].Version != "0.1.2" {
+		t.Errorf("unexpected chart versions: %v", versions)
+	}
+
+	if _, err = m.ListChartVersions(context.Background(), GreptimeDBOperatorChartName); err == nil {
+		t.Errorf("expected error for the chart not in the index")
+	}
+
+	m, err = NewManager(l, WithChartRepository("oci://registry.example.com/charts"))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+	if _, err = m.ListChartVersions(context.Background(), GreptimeDBClusterChartName); err == nil {
+		t.Errorf("expected error for the OCI registry")
+	}
+}
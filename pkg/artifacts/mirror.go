@@ -0,0 +1,169 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+)
+
+const (
+	// ArtifactMirrorsEnvKey is the environment variable of the comma-separated artifact mirrors,
+	// e.g. 'GTCTL_ARTIFACT_MIRRORS=https://mirror.example.com/greptime,greptime-cn'.
+	ArtifactMirrorsEnvKey = "GTCTL_ARTIFACT_MIRRORS"
+
+	// GreptimeCNMirror is the alias of the mirror in CN region, which is the same as '--use-greptime-cn-artifacts'.
+	GreptimeCNMirror = "greptime-cn"
+)
+
+// ParseMirrors parses the comma-separated mirrors.
+func ParseMirrors(mirrors string) []string {
+	var parsed []string
+	for _, mirror := range strings.Split(mirrors, ",") {
+		if mirror = strings.TrimSpace(mirror); mirror != "" {
+			parsed = append(parsed, mirror)
+		}
+	}
+	return parsed
+}
+
+// EndpointHosts returns the hosts that the artifacts are downloaded from, which are the hosts of the mirrors set by
+// the environment variable or the global config, and the hosts of the official sources.
+func EndpointHosts() ([]string, error) {
+	mirrors, _, err := defaultMirrorsAndProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		hosts []string
+		seen  = make(map[string]bool)
+	)
+	origins := []string{GreptimeChartRepositoryURL, GreptimeChartIndexURL, GreptimeChartReleaseDownloadURL,
+		"https://github.com", "https://objects.githubusercontent.com"}
+	for _, endpoint := range append(resolveMirrors(mirrors), origins...) {
+		u, err := url.Parse(endpoint)
+		if err != nil || len(u.Hostname()) == 0 || seen[u.Hostname()] {
+			continue
+		}
+		seen[u.Hostname()] = true
+		hosts = append(hosts, u.Hostname())
+	}
+	return hosts, nil
+}
+
+// resolveMirrors expands the aliases of mirrors, and drops the duplicated ones and the trailing slashes.
+func resolveMirrors(mirrors []string) []string {
+	var (
+		resolved []string
+		seen     = make(map[string]bool)
+	)
+	for _, mirror := range mirrors {
+		if mirror == GreptimeCNMirror {
+			mirror = GreptimeReleaseBucketCN
+		}
+		mirror = strings.TrimSuffix(mirror, "/")
+		if mirror == "" || seen[mirror] {
+			continue
+		}
+		seen[mirror] = true
+		resolved = append(resolved, mirror)
+	}
+	return resolved
+}
+
+// defaultMirrorsAndProxy returns the mirrors set by the environment variable, or the ones in the global config,
+// and the proxy in the global config.
+func defaultMirrorsAndProxy() ([]string, string, error) {
+	cfg, err := globalconfig.Load("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	mirrors := cfg.ArtifactMirrors
+	if env := os.Getenv(ArtifactMirrorsEnvKey); env != "" {
+		mirrors = ParseMirrors(env)
+	}
+
+	return mirrors, cfg.Proxy, nil
+}
+
+// sourceMirrors returns the mirrors to download the artifact from, the mirror in CN region comes first if fromCNRegion is true.
+func (m *manager) sourceMirrors(fromCNRegion bool) []string {
+	if fromCNRegion {
+		return resolveMirrors(append([]string{GreptimeCNMirror}, m.mirrors...))
+	}
+	return m.mirrors
+}
+
+// mirrorURL returns the URL of the artifact file in the mirror.
+func mirrorURL(mirror string, src *Source) string {
+	switch {
+	case src.Type == ArtifactTypeChart:
+		return fmt.Sprintf("%s/charts/%s/%s/%s", mirror, src.Name, src.Version, src.FileName)
+	case src.Name == GreptimeBinName:
+		// Note: it uses 'greptimedb' directory to store the greptime binary.
+		return fmt.Sprintf("%s/greptimedb/%s/%s", mirror, src.Version, src.FileName)
+	default:
+		return fmt.Sprintf("%s/%s/%s/%s", mirror, src.Name, src.Version, src.FileName)
+	}
+}
+
+// setSourceURLs sets the URLs of the artifact in the mirrors and the origin URL in order.
+func setSourceURLs(src *Source, mirrors []string, originURL string) {
+	urls := make([]string, 0, len(mirrors)+1)
+	for _, mirror := range mirrors {
+		urls = append(urls, mirrorURL(mirror, src))
+	}
+	urls = append(urls, originURL)
+
+	src.URL, src.FallbackURLs = urls[0], urls[1:]
+}
+
+// candidates returns the sources of all the URLs of the artifact in order.
+func (src *Source) candidates() []*Source {
+	candidates := []*Source{src}
+	for _, fallbackURL := range src.FallbackURLs {
+		candidate := *src
+		candidate.URL, candidate.FallbackURLs = fallbackURL, nil
+		if candidate.Type == ArtifactTypeBinary {
+			candidate.ChecksumURL = binaryChecksumURL(candidate.Name, fallbackURL)
+		}
+		candidates = append(candidates, &candidate)
+	}
+	return candidates
+}
+
+// newHTTPClient creates the http client with the proxy, or the proxy from the environment variables if it's empty.
+func newHTTPClient(proxy string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy '%s': %v", proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	t.Setenv(ArtifactMirrorsEnvKey, " https://a.example.com/, greptime-cn,,https://a.example.com")
+	m := newTestManager(t)
+	assert.Equal(t, []string{"https://a.example.com", GreptimeReleaseBucketCN}, m.mirrors)
+
+	// The mirrors of option override the environment variable.
+	m = newTestManager(t, WithMirrors("https://b.example.com"))
+	assert.Equal(t, []string{"https://b.example.com"}, m.mirrors)
+	assert.Equal(t, []string{GreptimeReleaseBucketCN, "https://b.example.com"}, m.sourceMirrors(true))
+}
+
+func TestNewSourceWithMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ArtifactMirrorsEnvKey, "")
+
+	m := newTestManager(t, WithMirrors("https://mirror.example.com/greptime"))
+
+	src, err := m.NewSource(EtcdBinName, "v3.5.7", ArtifactTypeBinary, true)
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		assert.Error(t, err)
+		return
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%s/etcd/v3.5.7/%s", GreptimeReleaseBucketCN, src.FileName), src.URL)
+	assert.Equal(t, []string{
+		fmt.Sprintf("https://mirror.example.com/greptime/etcd/v3.5.7/%s", src.FileName),
+		fmt.Sprintf("https://github.com/etcd-io/etcd/releases/download/v3.5.7/%s", src.FileName),
+	}, src.FallbackURLs)
+
+	src, err = m.NewSource(GreptimeBinName, "v0.4.1", ArtifactTypeBinary, false)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://mirror.example.com/greptime/greptimedb/v0.4.1/%s", src.FileName), src.URL)
+	assert.Equal(t, fmt.Sprintf("https://mirror.example.com/greptime/greptimedb/v0.4.1/%s", "greptime-"+runtime.GOOS+"-"+runtime.GOARCH+"-v0.4.1.sha256sum"), src.ChecksumURL)
+
+	src, err = m.NewSource(GtctlBinName, "v0.1.9", ArtifactTypeBinary, false)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://mirror.example.com/greptime/gtctl/v0.1.9/gtctl-%s-%s.tgz", runtime.GOOS, runtime.GOARCH), src.URL)
+	assert.Equal(t, fmt.Sprintf("https://mirror.example.com/greptime/gtctl/v0.1.9/gtctl-%s-%s.sha256sum", runtime.GOOS, runtime.GOARCH), src.ChecksumURL)
+	assert.Equal(t, []string{fmt.Sprintf("https://github.com/GreptimeTeam/gtctl/releases/download/v0.1.9/gtctl-%s-%s.tgz", runtime.GOOS, runtime.GOARCH)}, src.FallbackURLs)
+
+	src, err = m.NewSource(EtcdChartName, DefaultEtcdChartVersion, ArtifactTypeChart, false)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://mirror.example.com/greptime/charts/etcd/%s/etcd-%s.tgz", DefaultEtcdChartVersion, DefaultEtcdChartVersion), src.URL)
+	assert.Equal(t, []string{EtcdOCIRegistry}, src.FallbackURLs)
+
+	// The private chart repository takes precedence over the mirrors.
+	m = newTestManager(t, WithMirrors("https://mirror.example.com/greptime"), WithChartRepository("https://charts.example.com"))
+	src, err = m.NewSource(GreptimeDBClusterChartName, "0.1.2", ArtifactTypeChart, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://charts.example.com/greptimedb-cluster-0.1.2.tgz", src.URL)
+	assert.Empty(t, src.FallbackURLs)
+}
+
+func TestDownloadFromMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ArtifactMirrorsEnvKey, "")
+
+	content := []byte("greptimedb-cluster chart")
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/charts/greptimedb-cluster/0.1.2/greptimedb-cluster-0.1.2.tgz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer good.Close()
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer bad.Close()
+
+	m := newTestManager(t, WithMirrors(bad.URL, good.URL))
+	src, err := m.NewSource(GreptimeDBClusterChartName, "0.1.2", ArtifactTypeChart, false)
+	assert.NoError(t, err)
+
+	artifactFile, err := m.DownloadTo(context.Background(), src, t.TempDir(), &DownloadOptions{})
+	assert.NoError(t, err)
+	data, err := os.ReadFile(artifactFile)
+	assert.NoError(t, err)
+	assert.Equal(t, content, data)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Platform is the OS and architecture that the binaries are built for, e.g. 'linux/arm64'.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// supportedPlatforms are the platforms that the greptime and etcd binaries are published for.
+var supportedPlatforms = []Platform{
+	{OS: "linux", Arch: "amd64"},
+	{OS: "linux", Arch: "arm64"},
+	{OS: "darwin", Arch: "amd64"},
+	{OS: "darwin", Arch: "arm64"},
+	{OS: "windows", Arch: "amd64"},
+}
+
+// The aliases of the OS and architecture names, e.g. from 'uname -sm'.
+var (
+	osAliases = map[string]string{
+		"macos": "darwin",
+		"osx":   "darwin",
+	}
+	archAliases = map[string]string{
+		"aarch64": "arm64",
+		"x86_64":  "amd64",
+		"x64":     "amd64",
+	}
+)
+
+// CurrentPlatform returns the platform that gtctl is running on.
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// ParsePlatform parses the platform like 'linux/arm64' or 'linux-arm64', the aliases like 'aarch64' and
+// 'x86_64' are accepted. It returns an error if the binaries are not published for the platform.
+func ParsePlatform(s string) (Platform, error) {
+	sep := "/"
+	if !strings.Contains(s, sep) {
+		sep = "-"
+	}
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), sep)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Platform{}, fmt.Errorf("invalid platform '%s', it should be like 'linux/arm64'", s)
+	}
+
+	p := Platform{OS: parts[0], Arch: parts[1]}
+	if alias, ok := osAliases[p.OS]; ok {
+		p.OS = alias
+	}
+	if alias, ok := archAliases[p.Arch]; ok {
+		p.Arch = alias
+	}
+
+	for _, supported := range supportedPlatforms {
+		if p == supported {
+			return p, nil
+		}
+	}
+
+	names := make([]string, 0, len(supportedPlatforms))
+	for _, supported := range supportedPlatforms {
+		names = append(names, supported.String())
+	}
+	return Platform{}, fmt.Errorf("unsupported platform '%s', supported platforms: %s", s, strings.Join(names, ", "))
+}
+
+// String returns the platform like 'linux/arm64'.
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// IsCurrent returns true if the platform is the one that gtctl is running on, the binaries of
+// the other platforms can't run on the local host.
+func (p Platform) IsCurrent() bool {
+	return p == CurrentPlatform()
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		platform string
+		want     Platform
+		wantErr  bool
+	}{
+		{"linux/amd64", Platform{OS: "linux", Arch: "amd64"}, false},
+		{"linux-arm64", Platform{OS: "linux", Arch: "arm64"}, false},
+		{"Linux/aarch64", Platform{OS: "linux", Arch: "arm64"}, false},
+		{"macos/arm64", Platform{OS: "darwin", Arch: "arm64"}, false},
+		{"darwin/x86_64", Platform{OS: "darwin", Arch: "amd64"}, false},
+		{"windows/amd64", Platform{OS: "windows", Arch: "amd64"}, false},
+		{"windows/arm64", Platform{}, true},
+		{"linux", Platform{}, true},
+		{"linux/", Platform{}, true},
+		{"linux/arm64/v8", Platform{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePlatform(tt.platform)
+		if tt.wantErr {
+			assert.Error(t, err, tt.platform)
+			continue
+		}
+		assert.NoError(t, err, tt.platform)
+		assert.Equal(t, tt.want, got, tt.platform)
+	}
+}
+
+func TestNewSourceWithPlatform(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ArtifactMirrorsEnvKey, "")
+
+	tests := []struct {
+		platform     Platform
+		wantEtcd     string
+		wantGreptime string
+	}{
+		{
+			Platform{OS: "linux", Arch: "arm64"},
+			"https://github.com/etcd-io/etcd/releases/download/v3.5.7/etcd-v3.5.7-linux-arm64.tar.gz",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-linux-arm64-v0.4.1.tar.gz",
+		},
+		{
+			Platform{OS: "darwin", Arch: "arm64"},
+			"https://github.com/etcd-io/etcd/releases/download/v3.5.7/etcd-v3.5.7-darwin-arm64.zip",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-darwin-arm64-v0.4.1.tar.gz",
+		},
+		{
+			Platform{OS: "windows", Arch: "amd64"},
+			"https://github.com/etcd-io/etcd/releases/download/v3.5.7/etcd-v3.5.7-windows-amd64.zip",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-windows-amd64-v0.4.1.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		m := newTestManager(t, WithPlatform(tt.platform))
+
+		src, err := m.NewSource(EtcdBinName, "v3.5.7", ArtifactTypeBinary, false)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantEtcd, src.URL)
+		assert.Equal(t, tt.platform, src.Platform)
+
+		src, err = m.NewSource(GreptimeBinName, "v0.4.1", ArtifactTypeBinary, false)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantGreptime, src.URL)
+		assert.Equal(t, tt.platform, src.Platform)
+
+		// The charts and gtctl itself are always for the current platform.
+		src, err = m.NewSource(EtcdChartName, DefaultEtcdChartVersion, ArtifactTypeChart, false)
+		assert.NoError(t, err)
+		assert.Equal(t, CurrentPlatform(), src.Platform)
+	}
+}
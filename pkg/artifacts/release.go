@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// gtctlBinaryDownloadURL returns the download URL of the gtctl binary in the GitHub release,
+// e.g. 'https://github.com/GreptimeTeam/gtctl/releases/download/v0.1.9/gtctl-linux-amd64.tgz'.
+func gtctlBinaryDownloadURL(version string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s-%s-%s.tgz",
+		GreptimeGitHubOrg, GtctlGithubRepo, version, GtctlBinName, runtime.GOOS, runtime.GOARCH)
+}
+
+func (m *manager) ListReleaseVersions(ctx context.Context, name string) ([]string, error) {
+	var org, repo string
+	switch name {
+	case GreptimeBinName:
+		org, repo = GreptimeGitHubOrg, GreptimeDBGithubRepo
+	case GtctlBinName:
+		org, repo = GreptimeGitHubOrg, GtctlGithubRepo
+	case EtcdBinName:
+		org, repo = EtcdGitHubOrg, EtcdGithubRepo
+	default:
+		return nil, fmt.Errorf("unsupported binary: %s", name)
+	}
+
+	releases, _, err := m.githubClient().Repositories.ListReleases(ctx, org, repo, &github.ListOptions{PerPage: 50})
+	if err != nil {
+		return nil, fmt.Errorf("error listing the releases of '%s/%s': %v", org, repo, err)
+	}
+
+	// The releases are listed from the newest.
+	var versions []string
+	for _, release := range releases {
+		if release.GetDraft() || release.GetPrerelease() {
+			continue
+		}
+		versions = append(versions, release.GetTagName())
+	}
+	return versions, nil
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// DefaultCargoProfile is the default cargo profile to build greptime from source with.
+	DefaultCargoProfile = "release"
+
+	// SourceBuildVersionPrefix is the prefix of the version of the greptime binary built from source,
+	// e.g. 'source-1a2b3c4d5e6f-release'.
+	SourceBuildVersionPrefix = "source-"
+
+	// dirtySuffix is the suffix of the version built from the source tree with uncommitted changes.
+	dirtySuffix = "-dirty"
+)
+
+// SourceBuildOptions is the options for building greptime from the local source tree.
+type SourceBuildOptions struct {
+	// SourceDir is the root directory of the greptimedb source tree.
+	SourceDir string
+
+	// Profile is the cargo profile, e.g. 'release' and 'dev'.
+	Profile string
+
+	// Features are the cargo features to enable.
+	Features []string
+
+	// If EnableCache is true, the binary built from the same commit, profile and features is reused.
+	// The source tree with uncommitted changes is always rebuilt.
+	EnableCache bool
+
+	// Output is where the output of cargo goes.
+	Output io.Writer
+}
+
+// NewSourceBuild returns the source of the greptime binary built from the source tree, its version is keyed by
+// the commit of the tree, the profile and the features, so the binaries are cached in the artifacts directory.
+func NewSourceBuild(opts *SourceBuildOptions) (*Source, error) {
+	sourceDir, err := filepath.Abs(opts.SourceDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "Cargo.toml")); err != nil {
+		return nil, fmt.Errorf("'%s' is not the greptimedb source tree: %v", opts.SourceDir, err)
+	}
+
+	commit, err := git(sourceDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("error getting the commit of '%s': %v", sourceDir, err)
+	}
+	changes, err := git(sourceDir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return nil, fmt.Errorf("error getting the status of '%s': %v", sourceDir, err)
+	}
+
+	short := commit
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	version := fmt.Sprintf("%s%s-%s", SourceBuildVersionPrefix, short, cargoProfile(opts))
+	if len(opts.Features) > 0 {
+		features := append([]string(nil), opts.Features...)
+		sort.Strings(features)
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(strings.Join(features, ",")))
+		version = fmt.Sprintf("%s-%08x", version, h.Sum32())
+	}
+	if len(changes) > 0 {
+		version += dirtySuffix
+	}
+
+	return &Source{
+		Name:     GreptimeBinName,
+		Version:  version,
+		Type:     ArtifactTypeBinary,
+		Commit:   commit,
+		Platform: CurrentPlatform(),
+	}, nil
+}
+
+func (m *manager) BuildFromSource(ctx context.Context, src *Source, installDir string, opts *SourceBuildOptions) (string, error) {
+	binPath := filepath.Join(installDir, GreptimeBinName)
+	if opts.EnableCache && !strings.HasSuffix(src.Version, dirtySuffix) {
+		if exist, _ := fileutils.IsFileExists(binPath); exist {
+			m.logger.V(3).Infof("The greptime binary of '%s' already exists, skip building.", src.Version)
+			return binPath, nil
+		}
+	}
+
+	args := []string{"build", "--profile", cargoProfile(opts), "--bin", GreptimeBinName}
+	if len(opts.Features) > 0 {
+		args = append(args, "--features", strings.Join(opts.Features, ","))
+	}
+
+	m.logger.V(0).Infof("Building greptime in '%s' by 'cargo %s'...", opts.SourceDir, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, "cargo", args...)
+	cmd.Dir = opts.SourceDir
+	if opts.Output != nil {
+		cmd.Stdout, cmd.Stderr = opts.Output, opts.Output
+	}
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to build greptime in '%s': %v", opts.SourceDir, err)
+	}
+
+	if err := fileutils.EnsureDir(installDir); err != nil {
+		return "", err
+	}
+	if err := fileutils.CopyFile(filepath.Join(cargoTargetDir(opts.SourceDir), cargoProfileDir(cargoProfile(opts)), GreptimeBinName), binPath); err != nil {
+		return "", fmt.Errorf("failed to install the built greptime binary: %v", err)
+	}
+	if err := os.Chmod(binPath, 0755); err != nil {
+		return "", err
+	}
+
+	return binPath, nil
+}
+
+func cargoProfile(opts *SourceBuildOptions) string {
+	if opts.Profile == "" {
+		return DefaultCargoProfile
+	}
+	return opts.Profile
+}
+
+// cargoProfileDir returns the output directory of the profile, the 'dev' and 'test' profiles output to 'debug'.
+func cargoProfileDir(profile string) string {
+	switch profile {
+	case "dev", "test":
+		return "debug"
+	case "bench":
+		return "release"
+	default:
+		return profile
+	}
+}
+
+// cargoTargetDir returns the target directory of cargo, which can be changed by CARGO_TARGET_DIR.
+func cargoTargetDir(sourceDir string) string {
+	if dir := os.Getenv("CARGO_TARGET_DIR"); dir != "" {
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+		return filepath.Join(sourceDir, dir)
+	}
+	return filepath.Join(sourceDir, "target")
+}
+
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSourceBuild(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	sourceDir := t.TempDir()
+	_, err := NewSourceBuild(&SourceBuildOptions{SourceDir: sourceDir})
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(sourceDir, "Cargo.toml"), []byte("[workspace]\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "Cargo.toml"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		_, err := git(sourceDir, args...)
+		assert.NoError(t, err)
+	}
+	commit, err := git(sourceDir, "rev-parse", "HEAD")
+	assert.NoError(t, err)
+
+	src, err := NewSourceBuild(&SourceBuildOptions{SourceDir: sourceDir})
+	assert.NoError(t, err)
+	assert.Equal(t, GreptimeBinName, src.Name)
+	assert.Equal(t, commit, src.Commit)
+	assert.Equal(t, SourceBuildVersionPrefix+commit[:12]+"-release", src.Version)
+
+	// The features in different order are built into the same binary.
+	src1, err := NewSourceBuild(&SourceBuildOptions{SourceDir: sourceDir, Profile: "dev", Features: []string{"a", "b"}})
+	assert.NoError(t, err)
+	src2, err := NewSourceBuild(&SourceBuildOptions{SourceDir: sourceDir, Profile: "dev", Features: []string{"b", "a"}})
+	assert.NoError(t, err)
+	assert.Equal(t, src1.Version, src2.Version)
+	assert.True(t, strings.HasPrefix(src1.Version, SourceBuildVersionPrefix+commit[:12]+"-dev-"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(sourceDir, "Cargo.toml"), []byte("[workspace]\nmembers = []\n"), 0644))
+	src, err = NewSourceBuild(&SourceBuildOptions{SourceDir: sourceDir})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(src.Version, dirtySuffix))
+}
+
+func TestCargoProfileDir(t *testing.T) {
+	assert.Equal(t, "debug", cargoProfileDir("dev"))
+	assert.Equal(t, "debug", cargoProfileDir("test"))
+	assert.Equal(t, "release", cargoProfileDir("release"))
+	assert.Equal(t, "release", cargoProfileDir("bench"))
+	assert.Equal(t, "nightly", cargoProfileDir("nightly"))
+
+	t.Setenv("CARGO_TARGET_DIR", "")
+	assert.Equal(t, filepath.Join("/src", "target"), cargoTargetDir("/src"))
+	t.Setenv("CARGO_TARGET_DIR", "out")
+	assert.Equal(t, filepath.Join("/src", "out"), cargoTargetDir("/src"))
+	t.Setenv("CARGO_TARGET_DIR", "/tmp/target")
+	assert.Equal(t, "/tmp/target", cargoTargetDir("/src"))
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// etcdChecksumsFileName is the file of the sha256 checksums of all the assets of etcd release.
+	etcdChecksumsFileName = "SHA256SUMS"
+
+	// greptimeChecksumExtension is the extension of the sha256 checksum file of each greptime package.
+	greptimeChecksumExtension = ".sha256sum"
+
+	// The cosign keyless signature and certificate are published besides the binary package if it's signed.
+	cosign                     = "cosign"
+	cosignSignatureExtension   = ".sig"
+	cosignCertificateExtension = ".pem"
+
+	// githubOIDCIssuer is the OIDC issuer of the certificates of binaries signed in GitHub Actions.
+	githubOIDCIssuer = "https://token.actions.githubusercontent.com"
+)
+
+// binaryChecksumURL returns the URL of the sha256 checksums of the binary package downloaded from url.
+func binaryChecksumURL(name, url string) string {
+	switch name {
+	case EtcdBinName:
+		// The checksum URL example: 'https://github.com/etcd-io/etcd/releases/download/v3.5.7/SHA256SUMS'.
+		return url[:strings.LastIndex(url, "/")+1] + etcdChecksumsFileName
+	case GreptimeBinName, GtctlBinName:
+		// The checksum URL example: 'https://github.com/GreptimeTeam/greptimedb/releases/download/v0.9.0/greptime-linux-amd64-v0.9.0.sha256sum'.
+		for _, ext := range []string{fileutils.TarGzExtension, fileutils.TgzExtension} {
+			if strings.HasSuffix(url, ext) {
+				return strings.TrimSuffix(url, ext) + greptimeChecksumExtension
+			}
+		}
+	}
+	return ""
+}
+
+// signerIdentity returns the identity of the GitHub workflows that sign the binary.
+func signerIdentity(name string) string {
+	switch name {
+	case EtcdBinName:
+		return fmt.Sprintf("^https://github.com/%s/%s/", EtcdGitHubOrg, EtcdGithubRepo)
+	case GtctlBinName:
+		return fmt.Sprintf("^https://github.com/%s/%s/", GreptimeGitHubOrg, GtctlGithubRepo)
+	default:
+		return fmt.Sprintf("^https://github.com/%s/%s/", GreptimeGitHubOrg, GreptimeDBGithubRepo)
+	}
+}
+
+// verifyBinary verifies the downloaded binary package by its published sha256 checksum,
+// and by its cosign signature if it's published.
+func (m *manager) verifyBinary(ctx context.Context, from *Source, file string) error {
+	// GitHub Actions doesn't publish the checksums of the artifacts, which are downloaded from GitHub directly.
+	if from.Commit != "" {
+		m.logger.Warnf("The build of commit '%s' has no published checksum, skip verifying it.", from.Commit)
+		return nil
+	}
+
+	if err := m.verifyChecksum(ctx, from, file); err != nil {
+		return err
+	}
+	return m.verifySignature(ctx, from, file)
+}
+
+func (m *manager) verifyChecksum(ctx context.Context, from *Source, file string) error {
+	if len(from.ChecksumURL) == 0 {
+		return fmt.Errorf("no checksum is published for '%s', use '--skip-verify' to skip verifying it", from.FileName)
+	}
+
+	checksums, err := m.fetch(ctx, from.ChecksumURL)
+	if err != nil {
+		return fmt.Errorf("error fetching the checksum of '%s' from '%s', use '--skip-verify' for the mirrors "+
+			"without checksums: %v", from.FileName, from.ChecksumURL, err)
+	}
+	expected, err := parseChecksum(checksums, from.FileName)
+	if err != nil {
+		return err
+	}
+
+	actual, err := sha256File(file)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(expected, actual) {
+		return fmt.Errorf("checksum mismatch of '%s': expected sha256 '%s', got '%s', the file may be corrupted "+
+			"or tampered with", from.FileName, expected, actual)
+	}
+
+	m.logger.V(3).Infof("The sha256 checksum of '%s' is verified", from.FileName)
+	return nil
+}
+
+func (m *manager) verifySignature(ctx context.Context, from *Source, file string) error {
+	signature, err := m.fetch(ctx, from.URL+cosignSignatureExtension)
+	if errors.Is(err, errNotFound) {
+		m.logger.V(3).Infof("No signature is published for '%s', skip verifying it", from.FileName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching the signature of '%s': %v", from.FileName, err)
+	}
+	certificate, err := m.fetch(ctx, from.URL+cosignCertificateExtension)
+	if err != nil {
+		return fmt.Errorf("error fetching the certificate of the signature of '%s': %v", from.FileName, err)
+	}
+
+	if _, err = exec.LookPath(cosign); err != nil {
+		m.logger.Warnf("The signature of '%s' is not verified since '%s' is not installed", from.FileName, cosign)
+		return nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "gtctl-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	signatureFile, certificateFile := filepath.Join(tempDir, "signature"), filepath.Join(tempDir, "certificate")
+	if err = os.WriteFile(signatureFile, signature, 0600); err != nil {
+		return err
+	}
+	if err = os.WriteFile(certificateFile, certificate, 0600); err != nil {
+		return err
+	}
+
+	output, err := exec.CommandContext(ctx, cosign, "verify-blob",
+		"--signature", signatureFile,
+		"--certificate", certificateFile,
+		"--certificate-identity-regexp", signerIdentity(from.Name),
+		"--certificate-oidc-issuer", githubOIDCIssuer,
+		file).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("invalid signature of '%s': %v: %s", from.FileName, err, strings.TrimSpace(string(output)))
+	}
+
+	m.logger.V(3).Infof("The signature of '%s' is verified", from.FileName)
+	return nil
+}
+
+// parseChecksum returns the checksum of fileName in the output of sha256sum, which is either one checksum
+// for the file, or the lines of '<checksum>  <file>' for multiple files.
+func parseChecksum(checksums []byte, fileName string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(string(checksums)), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && len(lines) == 1:
+			return fields[0], nil
+		case len(fields) >= 2 && path.Base(strings.TrimPrefix(fields[1], "*")) == fileName:
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum of '%s' found in the published checksums", fileName)
+}
+
+func sha256File(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestBinaryChecksumURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{EtcdBinName, "https://github.com/etcd-io/etcd/releases/download/v3.5.7/etcd-v3.5.7-linux-amd64.tar.gz",
+			"https://github.com/etcd-io/etcd/releases/download/v3.5.7/SHA256SUMS"},
+		{GreptimeBinName, "https://github.com/GreptimeTeam/greptimedb/releases/download/v0.9.0/greptime-linux-amd64-v0.9.0.tar.gz",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.9.0/greptime-linux-amd64-v0.9.0.sha256sum"},
+		{GreptimeBinName, "https://github.com/GreptimeTeam/greptimedb/releases/download/v0.3.2/greptime-linux-amd64.tgz",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.3.2/greptime-linux-amd64.sha256sum"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, binaryChecksumURL(tt.name, tt.url))
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	checksum, err := parseChecksum([]byte("abc123\n"), "greptime-linux-amd64-v0.9.0.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", checksum)
+
+	checksums := []byte("abc123  etcd-v3.5.7-darwin-amd64.zip\ndef456 *etcd-v3.5.7-linux-amd64.tar.gz\n")
+	checksum, err = parseChecksum(checksums, "etcd-v3.5.7-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "def456", checksum)
+
+	_, err = parseChecksum(checksums, "etcd-v3.5.7-linux-arm64.tar.gz")
+	assert.Error(t, err)
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	content := []byte("greptime")
+	sum := sha256.Sum256(content)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good.sha256sum":
+			_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  greptime.tar.gz\n"))
+		case "/bad.sha256sum":
+			_, _ = w.Write([]byte("0000  greptime.tar.gz\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "greptime.tar.gz")
+	assert.NoError(t, os.WriteFile(file, content, 0644))
+
+	am, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()))
+	assert.NoError(t, err)
+	m := am.(*manager)
+	ctx := context.Background()
+
+	src := &Source{Name: GreptimeBinName, FileName: "greptime.tar.gz", ChecksumURL: server.URL + "/good.sha256sum"}
+	assert.NoError(t, m.verifyChecksum(ctx, src, file))
+
+	src.ChecksumURL = server.URL + "/bad.sha256sum"
+	assert.ErrorContains(t, m.verifyChecksum(ctx, src, file), "checksum mismatch")
+
+	src.ChecksumURL = server.URL + "/missing.sha256sum"
+	assert.ErrorContains(t, m.verifyChecksum(ctx, src, file), "--skip-verify")
+
+	// The signature is optional.
+	src.URL = server.URL + "/greptime.tar.gz"
+	assert.NoError(t, m.verifySignature(ctx, src, file))
+}
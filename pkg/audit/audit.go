@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package audit records the mutations of clusters, e.g. creating, scaling, upgrading and deleting, into the
+// append-only audit logs, one JSON record per line.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// FileName is the name of the audit log in the directory of cluster.
+	FileName = "audit.log"
+
+	// ConfigMapKey is the key of the audit log in the ConfigMap of cluster on Kubernetes.
+	ConfigMapKey = FileName
+
+	// MaxConfigMapRecords is the max number of the latest records kept in the ConfigMap,
+	// since the size of ConfigMap is limited to 1MiB.
+	MaxConfigMapRecords = 500
+
+	// kubernetesDir is the directory of the audit logs of the clusters on Kubernetes relative to the working directory,
+	// the ones of the clusters on bare-metal environment are in their own directories.
+	kubernetesDir = "kubernetes"
+
+	redacted = "******"
+)
+
+// The operations that mutate the clusters.
+const (
+	OperationCreate  = "create"
+	OperationScale   = "scale"
+	OperationUpgrade = "upgrade"
+	OperationDelete  = "delete"
+	OperationApply   = "apply"
+)
+
+// The results of the operations.
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// Record is the record of one mutation of cluster.
+type Record struct {
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	User      string    `json:"user" yaml:"user"`
+	Operation string    `json:"operation" yaml:"operation"`
+	Cluster   string    `json:"cluster" yaml:"cluster"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Mode      string    `json:"mode" yaml:"mode"`
+
+	// Flags are all the flags set in the command line, the values of sensitive flags like passwords are redacted.
+	Flags map[string]string `json:"flags,omitempty" yaml:"flags,omitempty"`
+
+	Result         string `json:"result" yaml:"result"`
+	Error          string `json:"error,omitempty" yaml:"error,omitempty"`
+	DurationMillis int64  `json:"durationMillis" yaml:"durationMillis"`
+}
+
+// NewRecord creates the record of the operation on the cluster that has run for elapsed and failed with err if it's not nil.
+func NewRecord(operation, mode, namespace, name string, flags map[string]string, elapsed time.Duration, err error) *Record {
+	record := &Record{
+		Timestamp:      time.Now().Add(-elapsed).UTC().Truncate(time.Millisecond),
+		User:           currentUser(),
+		Operation:      operation,
+		Cluster:        name,
+		Namespace:      namespace,
+		Mode:           mode,
+		Flags:          make(map[string]string, len(flags)),
+		Result:         ResultSucceeded,
+		DurationMillis: elapsed.Milliseconds(),
+	}
+	for k, v := range flags {
+		// The values like '--set auth.password=...' are also redacted.
+		if isSensitive(k) || isSensitive(v) {
+			v = redacted
+		}
+		record.Flags[k] = v
+	}
+	if err != nil {
+		record.Result, record.Error = ResultFailed, err.Error()
+	}
+	return record
+}
+
+// Path returns the path of the audit log of the cluster in the working directory of gtctl. The audit log of the cluster
+// on Kubernetes is keyed by its namespace, which is empty for the clusters on bare-metal environment.
+func Path(workingDir, namespace, name string) string {
+	if namespace == "" {
+		return filepath.Join(workingDir, name, FileName)
+	}
+	return filepath.Join(workingDir, kubernetesDir, namespace, name, FileName)
+}
+
+// Append appends the record to the audit log in path, the audit log is created if it doesn't exist.
+func Append(path string, record *Record) error {
+	line, err := Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := fileutils.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(line)
+	return err
+}
+
+// Read reads all the records of the audit log in path, no records are returned if it doesn't exist.
+func Read(path string) ([]*Record, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
+
+// Marshal marshals the record into one line of the audit log.
+func Marshal(record *Record) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Unmarshal unmarshals the lines of the audit log into the records.
+func Unmarshal(data []byte) ([]*Record, error) {
+	var records []*Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("invalid audit record at line %d: %v", n, err)
+		}
+		records = append(records, &record)
+	}
+	return records, scanner.Err()
+}
+
+// AppendLatest appends the record to the lines of the audit log in data, and keeps the latest max records.
+func AppendLatest(data string, record *Record, max int) (string, error) {
+	line, err := Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.SplitAfter(data+string(line), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// isSensitive returns whether s mentions the secrets, so the value of flag should be redacted.
+func isSensitive(s string) bool {
+	s = strings.ToLower(s)
+	for _, word := range []string{"password", "secret", "token", "access-key", "access_key"} {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := Path(t.TempDir(), "default", "mycluster")
+
+	records, err := Read(path)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	flags := map[string]string{"replicas": "3", "set": "[auth.password=greptime]", "object-storage-secret-access-key": "xxx"}
+	assert.NoError(t, Append(path, NewRecord(OperationScale, "kubernetes", "default", "mycluster", flags, time.Second, nil)))
+	assert.NoError(t, Append(path, NewRecord(OperationDelete, "kubernetes", "default", "mycluster", nil, time.Second, errors.New("boom"))))
+
+	records, err = Read(path)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, OperationScale, records[0].Operation)
+	assert.Equal(t, ResultSucceeded, records[0].Result)
+	assert.Equal(t, map[string]string{"replicas": "3", "set": redacted, "object-storage-secret-access-key": redacted}, records[0].Flags)
+	assert.Equal(t, int64(1000), records[0].DurationMillis)
+	assert.NotEmpty(t, records[0].User)
+	assert.Equal(t, ResultFailed, records[1].Result)
+	assert.Equal(t, "boom", records[1].Error)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{\n"), 0644))
+	_, err = Read(path)
+	assert.Error(t, err)
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("/home/.gtctl", "mycluster", FileName), Path("/home/.gtctl", "", "mycluster"))
+	assert.Equal(t, filepath.Join("/home/.gtctl", "kubernetes", "default", "mycluster", FileName), Path("/home/.gtctl", "default", "mycluster"))
+}
+
+func TestAppendLatest(t *testing.T) {
+	var (
+		data string
+		err  error
+	)
+	for _, operation := range []string{OperationCreate, OperationScale, OperationUpgrade} {
+		data, err = AppendLatest(data, NewRecord(operation, "kubernetes", "default", "mycluster", nil, 0, nil), 2)
+		assert.NoError(t, err)
+	}
+
+	records, err := Unmarshal([]byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, OperationScale, records[0].Operation)
+	assert.Equal(t, OperationUpgrade, records[1].Operation)
+}
@@ -0,0 +1,295 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/helm"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// manifestFileName is the manifest of the artifacts in the bundle.
+	manifestFileName = "artifacts.yaml"
+
+	// chartsDir is the directory of chart files in the bundle.
+	chartsDir = "charts"
+
+	// imagesFileName is the images saved by 'docker save' in the bundle.
+	imagesFileName = "images.tar"
+
+	// docker is the client to pull, save, load and push the images.
+	docker = "docker"
+)
+
+// imagePattern matches the images in the rendered manifests of charts.
+var imagePattern = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:\s*["']?([^\s"']+)["']?\s*$`)
+
+// Manifest is the manifest of the artifacts in the bundle.
+type Manifest struct {
+	Charts []Chart  `yaml:"charts"`
+	Images []string `yaml:"images,omitempty"`
+}
+
+// Chart is the chart file in the bundle.
+type Chart struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	File    string `yaml:"file"`
+}
+
+// ExportOptions is the options to export the artifacts for installing GreptimeDB cluster on Kubernetes.
+type ExportOptions struct {
+	// Output is the path of the bundle.
+	Output string
+
+	// The versions of charts, the latest versions are exported if they are empty.
+	GreptimeDBChartVersion         string
+	GreptimeDBOperatorChartVersion string
+
+	// ChartRepository is the private chart repository to download charts from.
+	ChartRepository string
+
+	// SkipImages exports only the charts, the images are pulled by the Kubernetes cluster itself.
+	SkipImages bool
+}
+
+// ImportOptions is the options to import the bundle exported by Export.
+type ImportOptions struct {
+	// Bundle is the path of the bundle.
+	Bundle string
+
+	// Registry is the private image registry that the images are pushed to, e.g. 'registry.example.com',
+	// the images are only loaded into the local docker if it's empty.
+	Registry string
+}
+
+// Export bundles the charts of GreptimeDB cluster, operator and etcd, and the images used by them,
+// into a gzipped tarball that can be imported in the environment without internet access.
+func Export(ctx context.Context, options *ExportOptions, l logger.Logger) error {
+	hl, err := helm.NewLoader(l, helm.WithChartRepository(options.ChartRepository))
+	if err != nil {
+		return err
+	}
+
+	workDir, err := os.MkdirTemp("", "gtctl-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	if err = fileutils.EnsureDir(filepath.Join(workDir, chartsDir)); err != nil {
+		return err
+	}
+
+	var (
+		manifest Manifest
+		images   = make(map[string]bool)
+	)
+	for _, chart := range []Chart{
+		{Name: artifacts.GreptimeDBClusterChartName, Version: options.GreptimeDBChartVersion},
+		{Name: artifacts.GreptimeDBOperatorChartName, Version: options.GreptimeDBOperatorChartVersion},
+		{Name: artifacts.EtcdChartName, Version: artifacts.DefaultEtcdChartVersion},
+	} {
+		opts := &helm.LoadOptions{
+			ReleaseName:   chart.Name,
+			Namespace:     "default",
+			ChartName:     chart.Name,
+			ChartVersion:  chart.Version,
+			ValuesOptions: struct{}{},
+			EnableCache:   true,
+		}
+
+		l.V(0).Infof("Exporting chart '%s'...", chart.Name)
+		chartFile, err := hl.DownloadChart(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("error downloading chart '%s': %v", chart.Name, err)
+		}
+		chart.Version = opts.ChartVersion
+		chart.File = filepath.Base(chartFile)
+		if err = fileutils.CopyFile(chartFile, filepath.Join(workDir, chartsDir, chart.File)); err != nil {
+			return err
+		}
+		manifest.Charts = append(manifest.Charts, chart)
+
+		// The images are the ones in the manifests rendered by the default values.
+		manifests, err := hl.LoadAndRenderChart(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("error rendering chart '%s': %v", chart.Name, err)
+		}
+		for _, image := range imagesOf(manifests) {
+			images[image] = true
+		}
+	}
+
+	if !options.SkipImages {
+		for image := range images {
+			manifest.Images = append(manifest.Images, image)
+		}
+		sort.Strings(manifest.Images)
+
+		for _, image := range manifest.Images {
+			l.V(0).Infof("Exporting image '%s'...", image)
+			if err = runDocker(ctx, l, "pull", image); err != nil {
+				return err
+			}
+		}
+		args := append([]string{"save", "-o", filepath.Join(workDir, imagesFileName)}, manifest.Images...)
+		if err = runDocker(ctx, l, args...); err != nil {
+			return err
+		}
+	}
+
+	out, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(workDir, manifestFileName), out, 0644); err != nil {
+		return err
+	}
+
+	if err = fileutils.ArchiveTarGz(options.Output, map[string]string{
+		manifestFileName: filepath.Join(workDir, manifestFileName),
+		chartsDir:        filepath.Join(workDir, chartsDir),
+		imagesFileName:   filepath.Join(workDir, imagesFileName),
+	}); err != nil {
+		return err
+	}
+	l.V(0).Infof("The artifacts are exported to '%s'", options.Output)
+
+	return nil
+}
+
+// Import imports the charts of bundle into the cache of gtctl, so the cluster can be created without
+// downloading them, and loads the images of bundle, which are pushed to the private registry if it's set.
+func Import(ctx context.Context, options *ImportOptions, l logger.Logger) error {
+	hl, err := helm.NewLoader(l)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := os.MkdirTemp("", "gtctl-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	if err = fileutils.ExtractTarGz(options.Bundle, workDir); err != nil {
+		return fmt.Errorf("error extracting bundle '%s': %v", options.Bundle, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, manifestFileName))
+	if err != nil {
+		return fmt.Errorf("invalid bundle '%s': %v", options.Bundle, err)
+	}
+	var manifest Manifest
+	if err = yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid bundle '%s': %v", options.Bundle, err)
+	}
+
+	versions := make(map[string]string)
+	for _, chart := range manifest.Charts {
+		l.V(0).Infof("Importing chart '%s' (%s)...", chart.Name, chart.Version)
+		if err = hl.ImportChart(filepath.Join(workDir, chartsDir, chart.File), chart.Name, chart.Version); err != nil {
+			return fmt.Errorf("error importing chart '%s': %v", chart.Name, err)
+		}
+		versions[chart.Name] = chart.Version
+	}
+
+	if len(manifest.Images) > 0 {
+		l.V(0).Infof("Loading %d images...", len(manifest.Images))
+		if err = runDocker(ctx, l, "load", "-i", filepath.Join(workDir, imagesFileName)); err != nil {
+			return err
+		}
+
+		if len(options.Registry) > 0 {
+			for _, image := range manifest.Images {
+				target := retagImage(image, options.Registry)
+				l.V(0).Infof("Pushing image '%s'...", target)
+				if err = runDocker(ctx, l, "tag", image, target); err != nil {
+					return err
+				}
+				if err = runDocker(ctx, l, "push", target); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	tips := fmt.Sprintf("gtctl cluster create <cluster name> --greptimedb-chart-version %s --greptimedb-operator-chart-version %s",
+		versions[artifacts.GreptimeDBClusterChartName], versions[artifacts.GreptimeDBOperatorChartName])
+	if len(options.Registry) > 0 {
+		tips += fmt.Sprintf(" --image-registry %s", options.Registry)
+	}
+	l.V(0).Infof("The artifacts are imported, create the cluster with them by:\n%s %s", logger.Bold("$"), tips)
+
+	return nil
+}
+
+// imagesOf returns the sorted and deduplicated images in the manifests.
+func imagesOf(manifests []byte) []string {
+	seen := make(map[string]bool)
+	var images []string
+	for _, match := range imagePattern.FindAllSubmatch(manifests, -1) {
+		image := string(match[1])
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// retagImage replaces the registry of the image with the registry, e.g. 'docker.io/greptime/greptimedb:latest'
+// is retagged as 'registry.example.com/greptime/greptimedb:latest', so the charts pull it from the registry
+// by setting the image registry.
+func retagImage(image, registry string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image = parts[1]
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+// runDocker runs the docker command and outputs to the terminal.
+func runDocker(ctx context.Context, l logger.Logger, args ...string) error {
+	if _, err := exec.LookPath(docker); err != nil {
+		return fmt.Errorf("'%s' is required to export or import the images: %v", docker, err)
+	}
+
+	l.V(3).Infof("Running '%s %s'", docker, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, docker, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running '%s %s': %v", docker, args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagesOf(t *testing.T) {
+	manifests := []byte(`
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: manager
+          image: "docker.io/greptime/greptimedb-operator:v0.1.0"
+        - image: docker.io/bitnami/etcd:3.5.7
+---
+apiVersion: greptime.io/v1alpha1
+kind: GreptimeDBCluster
+spec:
+  base:
+    main:
+      image: 'docker.io/greptime/greptimedb:v0.4.0'
+  initializer:
+    image: docker.io/greptime/greptimedb-initializer:0.1.0
+  frontend:
+    main:
+      image: docker.io/greptime/greptimedb:v0.4.0
+`)
+
+	assert.Equal(t, []string{
+		"docker.io/bitnami/etcd:3.5.7",
+		"docker.io/greptime/greptimedb-initializer:0.1.0",
+		"docker.io/greptime/greptimedb-operator:v0.1.0",
+		"docker.io/greptime/greptimedb:v0.4.0",
+	}, imagesOf(manifests))
+}
+
+func TestRetagImage(t *testing.T) {
+	tests := []struct {
+		image    string
+		registry string
+		expected string
+	}{
+		{"docker.io/greptime/greptimedb:v0.4.0", "registry.example.com", "registry.example.com/greptime/greptimedb:v0.4.0"},
+		{"greptime/greptimedb:v0.4.0", "registry.example.com/mirror/", "registry.example.com/mirror/greptime/greptimedb:v0.4.0"},
+		{"localhost:5000/busybox", "registry.example.com", "registry.example.com/busybox"},
+		{"busybox", "registry.example.com", "registry.example.com/busybox"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, retagImage(tt.image, tt.registry))
+	}
+}
@@ -0,0 +1,486 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"gopkg.in/yaml.v3"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// FieldChange is the change of one field of the config, the path is the yaml keys joined by '.',
+// e.g. "cluster.frontend.replicas", and the datanode groups are keyed by their names.
+type FieldChange struct {
+	Path string
+	From string
+	To   string
+}
+
+// ComponentChange is the change of one component that is applied by restarting or scaling it.
+type ComponentChange struct {
+	Component greptimedbclusterv1alpha1.ComponentKind
+	Fields    []*FieldChange
+
+	// FromReplicas and ToReplicas are the replicas of component before and after scaling.
+	FromReplicas int
+	ToReplicas   int
+}
+
+// ApplyPlan is the plan of reconciling a running cluster to the desired config. Only the necessary
+// changes are made, i.e. the greptime version is upgraded, the components whose configs are changed
+// are restarted, and the components whose replicas are changed are scaled.
+type ApplyPlan struct {
+	Name string
+
+	// Version is the greptime version that the cluster is upgraded to, it's empty if it's not changed.
+	// All the replicas are restarted one at a time by the upgrade with the desired configs.
+	Version     string
+	FromVersion string
+
+	Restarts []*ComponentChange
+	Scales   []*ComponentChange
+
+	// Updates are the changes that only take effect when the cluster is started next time,
+	// they are recorded in the cluster metadata without restarting any component.
+	Updates []*FieldChange
+
+	// config is the desired config with the replicas and the artifact of the running cluster,
+	// which is recorded before the components are upgraded, restarted and scaled.
+	config *config.BareMetalClusterConfig
+}
+
+// Empty returns whether there is nothing to apply.
+func (p *ApplyPlan) Empty() bool {
+	return len(p.Version) == 0 && len(p.Restarts) == 0 && len(p.Scales) == 0 && len(p.Updates) == 0
+}
+
+// String renders the plan like 'terraform plan', the changed fields are listed under each action.
+func (p *ApplyPlan) String() string {
+	if p.Empty() {
+		return fmt.Sprintf("Cluster '%s' is up to date with the config, there is nothing to apply.\n", p.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cluster '%s' will be changed as follows:\n\n", p.Name)
+	if len(p.Version) > 0 {
+		fmt.Fprintf(&b, "  ~ upgrade greptime from '%s' to '%s', all the replicas are restarted one at a time\n",
+			p.FromVersion, p.Version)
+	}
+	for _, restart := range p.Restarts {
+		fmt.Fprintf(&b, "  ~ restart '%s'\n", restart.Component)
+		writeFieldChanges(&b, restart.Fields)
+	}
+	for _, scale := range p.Scales {
+		fmt.Fprintf(&b, "  ~ scale '%s' from %d to %d replicas\n", scale.Component, scale.FromReplicas, scale.ToReplicas)
+	}
+	if len(p.Updates) > 0 {
+		fmt.Fprintf(&b, "  ~ update the config that takes effect on the next start\n")
+		writeFieldChanges(&b, p.Updates)
+	}
+
+	upgrades := 0
+	if len(p.Version) > 0 {
+		upgrades = 1
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to upgrade, %d to restart, %d to scale, %d to update.\n",
+		upgrades, len(p.Restarts), len(p.Scales), len(p.Updates))
+
+	return b.String()
+}
+
+func writeFieldChanges(b *strings.Builder, changes []*FieldChange) {
+	for _, change := range changes {
+		fmt.Fprintf(b, "      %s: %s -> %s\n", change.Path, change.From, change.To)
+	}
+}
+
+// PlanApply diffs the desired config against the config recorded for the running cluster, and
+// returns the plan to reconcile the cluster. It fails if any change can't be applied without
+// recreating the cluster, e.g. the changes of etcd, hosts or the deployed components.
+func (c *Cluster) PlanApply(ctx context.Context, options *opt.ApplyOptions) (*ApplyPlan, error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return nil, err
+	}
+	if err = notSupportedOnHosts(cluster, "apply"); err != nil {
+		return nil, err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if !running {
+		return nil, fmt.Errorf("cluster '%s' is not running", options.Name)
+	}
+
+	plan, err := planApply(cluster.Config, options.Config)
+	if err != nil {
+		return nil, err
+	}
+	plan.Name = options.Name
+
+	return plan, nil
+}
+
+// Apply executes the plan returned by PlanApply. The desired config is recorded first, then the
+// cluster is upgraded or the changed components are restarted in the order of their dependencies,
+// and the components are scaled at last.
+func (c *Cluster) Apply(ctx context.Context, options *opt.ApplyOptions, plan *ApplyPlan) error {
+	if plan.Empty() {
+		return nil
+	}
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	cluster.Config = plan.config
+	if err = c.mm.UpdateClusterMetadata(cluster); err != nil {
+		return fmt.Errorf("failed to update the metadata of cluster '%s': %v", options.Name, err)
+	}
+
+	if len(plan.Version) > 0 {
+		if err = c.Upgrade(ctx, &opt.UpgradeOptions{
+			Name:                   options.Name,
+			GreptimeVersion:        plan.Version,
+			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The components running their own binaries are skipped by the upgrade, they are still restarted for the changes.
+	binaries, _ := componentPaths(plan.config.Cluster, "")
+	for _, restart := range plan.Restarts {
+		if len(plan.Version) > 0 && len(binaries[string(restart.Component)]) == 0 {
+			continue
+		}
+		if err = c.Restart(ctx, &opt.RestartOptions{
+			Name:                   options.Name,
+			ComponentType:          restart.Component,
+			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, scale := range plan.Scales {
+		if err = c.Scale(ctx, &opt.ScaleOptions{
+			Name:                   options.Name,
+			ComponentType:          scale.Component,
+			NewReplicas:            int32(scale.ToReplicas),
+			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+		}); err != nil {
+			return err
+		}
+	}
+
+	c.logger.V(0).Infof("Cluster '%s' is reconciled to the config!", options.Name)
+
+	return nil
+}
+
+// The top-level fields of cluster config that are applied by restarting all the components,
+// and the ones that only take effect on the next start.
+var (
+	restartAllFields = map[string]bool{"env": true, "logFormat": true}
+	nextStartFields  = map[string]bool{"logRotation": true, "crashDump": true, "startupConcurrency": true}
+)
+
+// planApply diffs the desired config against the current config and classifies the changes into
+// the upgrade, restarts, scales and updates. The desired config is normalized and modified in place.
+func planApply(current, desired *config.BareMetalClusterConfig) (*ApplyPlan, error) {
+	if err := normalizeDesiredConfig(current, desired); err != nil {
+		return nil, err
+	}
+
+	changes, err := diffConfigs(current, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		plan        = &ApplyPlan{config: desired}
+		restarts    = make(map[string][]*FieldChange)
+		restartAll  []*FieldChange
+		unsupported []string
+	)
+	for _, change := range changes {
+		keys := strings.SplitN(change.Path, ".", 4)
+		if keys[0] != "cluster" || len(keys) < 2 {
+			unsupported = append(unsupported, change.Path)
+			continue
+		}
+
+		switch field := keys[1]; {
+		case change.Path == "cluster.artifact.version" && len(desired.Cluster.Artifact.Local) == 0:
+			plan.Version, plan.FromVersion = desired.Cluster.Artifact.Version, current.Cluster.Artifact.Version
+		case restartAllFields[field]:
+			restartAll = append(restartAll, change)
+		case nextStartFields[field]:
+			plan.Updates = append(plan.Updates, change)
+		case field == "datanodeGroups" && len(keys) > 3:
+			name := components.DatanodeGroupName(keys[2])
+			if keys[3] == "replicas" {
+				plan.Scales = append(plan.Scales, scaleChange(greptimedbclusterv1alpha1.ComponentKind(name), current, desired))
+			} else {
+				restarts[name] = append(restarts[name], change)
+			}
+		case isComponentField(field) && len(keys) > 2:
+			kind := greptimedbclusterv1alpha1.ComponentKind(field)
+			if keys[2] != "replicas" {
+				restarts[field] = append(restarts[field], change)
+			} else if _, _, err = scalableConfig(current.Cluster, kind); err == nil {
+				plan.Scales = append(plan.Scales, scaleChange(kind, current, desired))
+			} else {
+				unsupported = append(unsupported, change.Path)
+			}
+		default:
+			// The deployed components are added or removed, or the other fields are changed.
+			unsupported = append(unsupported, change.Path)
+		}
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("the changes of %s can't be applied to the running cluster, "+
+			"the cluster should be recreated for them", strings.Join(unsupported, ", "))
+	}
+
+	for _, kind := range restartOrder(current.Cluster) {
+		fields := restarts[string(kind)]
+		if len(restartAll) > 0 {
+			fields = append(append([]*FieldChange{}, restartAll...), fields...)
+		}
+		if len(fields) > 0 {
+			plan.Restarts = append(plan.Restarts, &ComponentChange{Component: kind, Fields: fields})
+		}
+	}
+
+	// The replicas and the artifact are changed by the scales and the upgrade after they succeed.
+	for _, scale := range plan.Scales {
+		replicas, _, _ := scalableConfig(desired.Cluster, scale.Component)
+		*replicas = scale.FromReplicas
+	}
+	desired.Cluster.Artifact = current.Cluster.Artifact
+
+	return plan, nil
+}
+
+// isComponentField returns whether the top-level field of cluster config is the config of one component.
+func isComponentField(field string) bool {
+	switch greptimedbclusterv1alpha1.ComponentKind(field) {
+	case greptimedbclusterv1alpha1.FrontendComponentKind, greptimedbclusterv1alpha1.MetaComponentKind,
+		greptimedbclusterv1alpha1.DatanodeComponentKind, components.FlownodeComponentName,
+		components.StandaloneComponentName:
+		return true
+	default:
+		return false
+	}
+}
+
+func scaleChange(kind greptimedbclusterv1alpha1.ComponentKind,
+	current, desired *config.BareMetalClusterConfig) *ComponentChange {
+	from, _, _ := scalableConfig(current.Cluster, kind)
+	to, _, _ := scalableConfig(desired.Cluster, kind)
+	return &ComponentChange{Component: kind, FromReplicas: *from, ToReplicas: *to}
+}
+
+// restartOrder returns the components of cluster in the order of their dependencies,
+// i.e. metasrv, datanodes, flownode and frontend.
+func restartOrder(cfg *config.BareMetalClusterComponentsConfig) []greptimedbclusterv1alpha1.ComponentKind {
+	if cfg.Standalone != nil {
+		return []greptimedbclusterv1alpha1.ComponentKind{components.StandaloneComponentName}
+	}
+
+	kinds := []greptimedbclusterv1alpha1.ComponentKind{
+		greptimedbclusterv1alpha1.MetaComponentKind,
+		greptimedbclusterv1alpha1.DatanodeComponentKind,
+	}
+	for _, group := range cfg.DatanodeGroups {
+		kinds = append(kinds, greptimedbclusterv1alpha1.ComponentKind(components.DatanodeGroupName(group.Name)))
+	}
+	if cfg.Flownode != nil {
+		kinds = append(kinds, components.FlownodeComponentName)
+	}
+	return append(kinds, greptimedbclusterv1alpha1.FrontendComponentKind)
+}
+
+// normalizeDesiredConfig applies the offsets onto the desired config as creating the cluster, and keeps the
+// allocated addresses and the pinned node ids of the running cluster, so they are not reported as changes.
+func normalizeDesiredConfig(current, desired *config.BareMetalClusterConfig) error {
+	if err := applyOffsets(desired.Cluster); err != nil {
+		return err
+	}
+
+	cur, des := current.Cluster, desired.Cluster
+	if cur.AutoPortAllocation && des.AutoPortAllocation {
+		keepReplicaAddrs := func(current config.ReplicaAddrs, desired *config.ReplicaAddrs) {
+			if len(*desired) == 0 {
+				*desired = current
+			}
+		}
+		if cur.Standalone != nil && des.Standalone != nil {
+			keepReplicaAddrs(cur.Standalone.ReplicaAddrs, &des.Standalone.ReplicaAddrs)
+		}
+		keepReplicaAddrs(cur.Frontend.ReplicaAddrs, &des.Frontend.ReplicaAddrs)
+		keepReplicaAddrs(cur.MetaSrv.ReplicaAddrs, &des.MetaSrv.ReplicaAddrs)
+		keepReplicaAddrs(cur.Datanode.ReplicaAddrs, &des.Datanode.ReplicaAddrs)
+		if cur.Flownode != nil && des.Flownode != nil {
+			keepReplicaAddrs(cur.Flownode.ReplicaAddrs, &des.Flownode.ReplicaAddrs)
+		}
+		for _, group := range des.DatanodeGroups {
+			if running := datanodeGroup(cur, group.Name); running != nil {
+				keepReplicaAddrs(running.ReplicaAddrs, &group.ReplicaAddrs)
+			}
+		}
+	}
+
+	for _, group := range des.DatanodeGroups {
+		if running := datanodeGroup(cur, group.Name); running != nil && group.NodeID == 0 {
+			group.NodeID = running.NodeID
+		}
+	}
+
+	return nil
+}
+
+func datanodeGroup(cfg *config.BareMetalClusterComponentsConfig, name string) *config.DatanodeGroup {
+	for _, group := range cfg.DatanodeGroups {
+		if group.Name == name {
+			return group
+		}
+	}
+	return nil
+}
+
+// diffConfigs returns the changed fields between the configs in the order of their paths.
+func diffConfigs(current, desired *config.BareMetalClusterConfig) ([]*FieldChange, error) {
+	from, err := configValues(current)
+	if err != nil {
+		return nil, err
+	}
+	to, err := configValues(desired)
+	if err != nil {
+		return nil, err
+	}
+	return diffValues("", from, to), nil
+}
+
+// configValues converts the config into the generic values by its yaml, and the datanode groups are keyed by their names.
+func configValues(cfg *config.BareMetalClusterConfig) (interface{}, error) {
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err = yaml.Unmarshal(out, &values); err != nil {
+		return nil, err
+	}
+
+	if cluster, ok := values["cluster"].(map[string]interface{}); ok {
+		if groups, ok := cluster["datanodeGroups"].([]interface{}); ok {
+			byName := make(map[string]interface{}, len(groups))
+			for _, group := range groups {
+				if group, ok := group.(map[string]interface{}); ok {
+					byName[fmt.Sprint(group["name"])] = group
+				}
+			}
+			cluster["datanodeGroups"] = byName
+		}
+	}
+
+	return values, nil
+}
+
+// diffValues compares the values recursively by the keys of maps, the lists are compared as a whole.
+func diffValues(path string, from, to interface{}) []*FieldChange {
+	fromMap, fromOK := asMap(from)
+	toMap, toOK := asMap(to)
+	if !fromOK || !toOK {
+		if reflect.DeepEqual(from, to) {
+			return nil
+		}
+		return []*FieldChange{{Path: path, From: formatValue(from), To: formatValue(to)}}
+	}
+
+	keys := make(map[string]bool)
+	for key := range fromMap {
+		keys[key] = true
+	}
+	for key := range toMap {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []*FieldChange
+	for _, key := range sorted {
+		child := key
+		if len(path) > 0 {
+			child = path + "." + key
+		}
+		changes = append(changes, diffValues(child, fromMap[key], toMap[key])...)
+	}
+	return changes
+}
+
+// asMap returns the map keyed by strings, the maps with non-string keys, e.g. the replica addresses, are converted.
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for key, v := range m {
+			converted[fmt.Sprint(key)] = v
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	if m, ok := asMap(value); ok {
+		value = m
+	}
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(out)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestPlanApply(t *testing.T) {
+	current := config.DefaultBareMetalConfig()
+	current.Cluster.DatanodeGroups = []*config.DatanodeGroup{{Name: "cold", Datanode: *current.Cluster.Datanode}}
+	current.Cluster.DatanodeGroups[0].NodeID = 10
+
+	plan, err := planApply(current, config.DefaultBareMetalConfig())
+	assert.Error(t, err, "removing the datanode group can't be applied")
+	assert.Nil(t, plan)
+
+	desired := config.DefaultBareMetalConfig()
+	desired.Cluster.DatanodeGroups = []*config.DatanodeGroup{{Name: "cold", Datanode: *desired.Cluster.Datanode}}
+	plan, err = planApply(current, desired)
+	assert.NoError(t, err)
+	assert.True(t, plan.Empty(), "the pinned node id of datanode group is kept")
+
+	desired = config.DefaultBareMetalConfig()
+	desired.Cluster.DatanodeGroups = []*config.DatanodeGroup{{Name: "cold", Datanode: *desired.Cluster.Datanode}}
+	desired.Cluster.Artifact.Version = "v0.99.0"
+	desired.Cluster.MetaSrv.LogLevel = "debug"
+	desired.Cluster.Frontend.Replicas = current.Cluster.Frontend.Replicas + 2
+	desired.Cluster.DatanodeGroups[0].Replicas = 1
+	desired.Cluster.LogFormat = config.LogFormatJSON
+	desired.Cluster.StartupConcurrency = 8
+
+	plan, err = planApply(current, desired)
+	assert.NoError(t, err)
+	assert.False(t, plan.Empty())
+	assert.Equal(t, "v0.99.0", plan.Version)
+	assert.Equal(t, current.Cluster.Artifact.Version, plan.FromVersion)
+
+	var restarts []greptimedbclusterv1alpha1.ComponentKind
+	for _, restart := range plan.Restarts {
+		restarts = append(restarts, restart.Component)
+		assert.Equal(t, "cluster.logFormat", restart.Fields[0].Path)
+	}
+	assert.Equal(t, restartOrder(current.Cluster), restarts, "all the components are restarted for the log format")
+	assert.Equal(t, "cluster.meta.logLevel", plan.Restarts[0].Fields[1].Path)
+	assert.Equal(t, `"debug"`, plan.Restarts[0].Fields[1].To)
+
+	assert.Len(t, plan.Scales, 2)
+	assert.Equal(t, components.DatanodeGroupName("cold"), string(plan.Scales[0].Component))
+	assert.Equal(t, greptimedbclusterv1alpha1.FrontendComponentKind, plan.Scales[1].Component)
+	assert.Equal(t, current.Cluster.Frontend.Replicas+2, plan.Scales[1].ToReplicas)
+
+	assert.Len(t, plan.Updates, 1)
+	assert.Equal(t, "cluster.startupConcurrency", plan.Updates[0].Path)
+
+	// The recorded config keeps the replicas and the version until they are scaled and upgraded.
+	assert.Equal(t, current.Cluster.Frontend.Replicas, plan.config.Cluster.Frontend.Replicas)
+	assert.Equal(t, current.Cluster.Artifact.Version, plan.config.Cluster.Artifact.Version)
+	assert.Equal(t, "debug", plan.config.Cluster.MetaSrv.LogLevel)
+	assert.Contains(t, plan.String(), "Plan: 1 to upgrade, 4 to restart, 2 to scale, 1 to update.")
+}
+
+func TestPlanApplyUnsupported(t *testing.T) {
+	for name, modify := range map[string]func(cfg *config.BareMetalClusterConfig){
+		"meta replicas": func(cfg *config.BareMetalClusterConfig) { cfg.Cluster.MetaSrv.Replicas++ },
+		"etcd":          func(cfg *config.BareMetalClusterConfig) { cfg.Etcd.Artifact.Version = "v0.0.1" },
+		"flownode": func(cfg *config.BareMetalClusterConfig) {
+			cfg.Cluster.Flownode = &config.Flownode{RPCAddr: "0.0.0.0:6800", HTTPAddr: "0.0.0.0:6801", Replicas: 1}
+		},
+		"auto ports": func(cfg *config.BareMetalClusterConfig) { cfg.Cluster.AutoPortAllocation = true },
+	} {
+		desired := config.DefaultBareMetalConfig()
+		modify(desired)
+		_, err := planApply(config.DefaultBareMetalConfig(), desired)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	changes := diffValues("", map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": "x", "d": []interface{}{"y"}},
+		"e": map[interface{}]interface{}{0: "z"},
+	}, map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": "x", "d": []interface{}{"y", "w"}},
+		"e": map[interface{}]interface{}{0: "z", 1: "v"},
+		"f": true,
+	})
+
+	assert.Equal(t, []*FieldChange{
+		{Path: "b.d", From: `["y"]`, To: `["y","w"]`},
+		{Path: "e.1", From: "<none>", To: `"v"`},
+		{Path: "f", From: "<none>", To: "true"},
+	}, changes)
+}
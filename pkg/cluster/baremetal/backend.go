@@ -0,0 +1,224 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	// backendCheckTimeout is the timeout of checking the connectivity of metadata store backend.
+	backendCheckTimeout = 10 * time.Second
+
+	// mysqlBackendTLSConfigName is the name that the TLS config of mysql backend is registered with.
+	mysqlBackendTLSConfigName = "gtctl-backend"
+)
+
+// checkBackendStorage checks the connectivity of metadata store backend before starting metasrv,
+// so the misconfigured backend is reported instead of a crash loop of metasrv.
+func (c *Cluster) checkBackendStorage(ctx context.Context) error {
+	storage := c.config.Cluster.MetaSrv.BackendStorage
+	if storage == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendCheckTimeout)
+	defer cancel()
+
+	c.logger.V(3).Infof("Checking the connectivity of %s backend storage '%v'", storage.Kind, storage.Endpoints)
+
+	var err error
+	switch storage.Kind {
+	case config.BackendStorageEtcd:
+		err = checkEtcdBackend(ctx, storage)
+	case config.BackendStoragePostgres:
+		err = checkPostgresBackend(ctx, storage)
+	case config.BackendStorageMySQL:
+		err = checkMySQLBackend(ctx, storage)
+	default:
+		err = fmt.Errorf("unknown kind of backend storage '%s'", storage.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("%s backend storage '%v' is not available, please check the 'backendStorage' "+
+			"of metasrv in cluster config: %v", storage.Kind, storage.Endpoints, err)
+	}
+
+	return nil
+}
+
+// checkEtcdBackend checks whether any of the etcd endpoints is reachable.
+func checkEtcdBackend(ctx context.Context, storage *config.BackendStorage) error {
+	var dialer net.Dialer
+	var lastErr error
+	for _, endpoint := range storage.Endpoints {
+		conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return conn.Close()
+	}
+	return lastErr
+}
+
+func checkPostgresBackend(ctx context.Context, storage *config.BackendStorage) error {
+	password, err := components.BackendPassword(storage)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := backendTLSConfig(storage)
+	if err != nil {
+		return err
+	}
+
+	db := pg.Connect(&pg.Options{
+		Addr:      storage.Endpoints[0],
+		User:      storage.User,
+		Password:  password,
+		Database:  storage.Database,
+		TLSConfig: tlsConfig,
+	})
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "SELECT 1")
+	return err
+}
+
+func checkMySQLBackend(ctx context.Context, storage *config.BackendStorage) error {
+	password, err := components.BackendPassword(storage)
+	if err != nil {
+		return err
+	}
+
+	cfg := mysql.Config{
+		Net:                  "tcp",
+		Addr:                 storage.Endpoints[0],
+		User:                 storage.User,
+		Passwd:               password,
+		DBName:               storage.Database,
+		AllowNativePasswords: true,
+	}
+
+	tlsConfig, err := backendTLSConfig(storage)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		if err = mysql.RegisterTLSConfig(mysqlBackendTLSConfigName, tlsConfig); err != nil {
+			return err
+		}
+		defer mysql.DeregisterTLSConfig(mysqlBackendTLSConfigName)
+		cfg.TLSConfig = mysqlBackendTLSConfigName
+	}
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.PingContext(ctx)
+}
+
+// backendTLSConfig builds the TLS config by the TLS options of backend storage,
+// it returns nil if the TLS is disabled.
+func backendTLSConfig(storage *config.BackendStorage) (*tls.Config, error) {
+	options := storage.TLS
+	if options == nil || len(options.Mode) == 0 || options.Mode == config.BackendTLSModeDisable {
+		return nil, nil
+	}
+
+	host, _, err := net.SplitHostPort(storage.Endpoints[0])
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: host,
+		// The server certificate is only verified in 'verify_ca' and 'verify_full' mode.
+		InsecureSkipVerify: options.Mode == config.BackendTLSModePrefer || options.Mode == config.BackendTLSModeRequire,
+	}
+
+	if len(options.CACertPath) > 0 {
+		ca, err := os.ReadFile(options.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert '%s': %v", options.CACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid CA cert in '%s'", options.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(options.CertPath) > 0 {
+		cert, err := tls.LoadX509KeyPair(options.CertPath, options.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert '%s': %v", options.CertPath, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// In 'verify_ca' mode, the certificate chain is verified without checking the hostname.
+	if options.Mode == config.BackendTLSModeVerifyCA {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertChain(rawCerts, tlsConfig.RootCAs)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyCertChain verifies the certificate chain of server without checking the hostname.
+func verifyCertChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no certificate from server")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// backupMetadataFile is the metadata of cluster in the archive of backup.
+	backupMetadataFile = "metadata.yaml"
+
+	// backupDataDir is the data dir of cluster in the archive of backup.
+	backupDataDir = metadata.ClusterDataDir
+)
+
+// Backup archives the metadata and the data dir of the cluster, which include the data of datanodes and
+// the metadata stored in the embedded etcd. The archive is a '.tar.gz' or '.tar.zst' by its extension.
+// If the cluster is running, its replicas are paused during archiving, so the data on disk stays unchanged
+// and the backup is consistent like the one of a crashed cluster, which is recovered from the WAL on restore.
+func (c *Cluster) Backup(ctx context.Context, options *opt.BackupOptions) error {
+	if !strings.HasSuffix(options.Output, fileutils.TarGzExtension) && !strings.HasSuffix(options.Output, fileutils.TarZstExtension) {
+		return fmt.Errorf("the backup archive should end with '%s' or '%s': %s",
+			fileutils.TarGzExtension, fileutils.TarZstExtension, options.Output)
+	}
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "backup"); err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+
+	for _, warning := range backupWarnings(cluster) {
+		c.logger.Warn(warning)
+	}
+
+	csd := c.mm.GetClusterScopeDirs()
+	paths := map[string]string{
+		backupMetadataFile: csd.ConfigPath,
+		backupDataDir:      csd.DataDir,
+	}
+	// The data of datanodes placed out of the cluster are archived into the data dir.
+	for _, datanode := range datanodeConfigs(cluster.Config.Cluster) {
+		if len(datanode.config.DataDir) == 0 {
+			continue
+		}
+		for i := 0; i < datanode.config.Replicas; i++ {
+			replica := fmt.Sprintf("%s.%d", datanode.name, i)
+			paths[path.Join(backupDataDir, replica)] = path.Join(datanode.config.DataDir, replica)
+		}
+	}
+
+	if running {
+		c.logger.V(0).Infof("Pausing the replicas of running cluster '%s' until it's backed up...", options.Name)
+		paused, err := c.pauseReplicas(csd.PidsDir)
+		if err != nil {
+			return err
+		}
+		defer c.resumeReplicas(paused)
+	}
+
+	c.logger.V(0).Infof("Backing up cluster '%s' to %s...", options.Name, options.Output)
+	if err = fileutils.ArchiveTarball(options.Output, paths); err != nil {
+		return fmt.Errorf("failed to back up cluster '%s': %v", options.Name, err)
+	}
+	c.logger.V(0).Infof("Cluster '%s' is backed up!", options.Name)
+
+	return nil
+}
+
+// Restore recreates the cluster from the archive of backup. The data of datanodes are restored into the
+// data dir of cluster, even if they were placed out of the cluster. The restored cluster is stopped,
+// it can be started by 'gtctl cluster start'.
+func (c *Cluster) Restore(_ context.Context, options *opt.RestoreOptions) error {
+	// The cluster dir of the deleted cluster may still exist with its audit log.
+	csd := c.mm.GetClusterScopeDirs()
+	if _, err := os.Stat(csd.ConfigPath); err == nil {
+		return fmt.Errorf("cluster '%s' already exists", options.Name)
+	}
+
+	// Extract the archive next to the cluster dir, so the data dir can be moved into the cluster dir.
+	extractDir := csd.BaseDir + ".restoring"
+	if err := fileutils.DeleteDirIfExists(extractDir); err != nil {
+		return err
+	}
+	defer func() {
+		if err := fileutils.DeleteDirIfExists(extractDir); err != nil {
+			c.logger.Warnf("failed to clean up '%s': %v", extractDir, err)
+		}
+	}()
+
+	c.logger.V(0).Infof("Restoring cluster '%s' from %s...", options.Name, options.Input)
+	if err := fileutils.ExtractTarball(options.Input, extractDir); err != nil {
+		return fmt.Errorf("failed to extract '%s': %v", options.Input, err)
+	}
+
+	backup, err := readMetadata(path.Join(extractDir, backupMetadataFile))
+	if err != nil {
+		return fmt.Errorf("invalid backup '%s': %v", options.Input, err)
+	}
+	for _, datanode := range datanodeConfigs(backup.Config.Cluster) {
+		datanode.config.DataDir = ""
+	}
+
+	if err = c.mm.CreateClusterScopeDirs(backup.Config); err != nil {
+		return err
+	}
+	restored, err := readMetadata(csd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	restored.ForegroundPid = 0
+	restored.Detached = backup.Detached
+	restored.UseMemoryMeta = backup.UseMemoryMeta
+	if err = c.mm.UpdateClusterMetadata(restored); err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(path.Join(extractDir, backupDataDir)); err == nil {
+		if err = os.Remove(csd.DataDir); err != nil {
+			return err
+		}
+		if err = os.Rename(path.Join(extractDir, backupDataDir), csd.DataDir); err != nil {
+			return err
+		}
+	}
+	c.logger.V(0).Infof("Cluster '%s' is restored, start it by 'gtctl cluster start %s'", options.Name, options.Name)
+
+	return nil
+}
+
+// pauseReplicas pauses all the running replicas of cluster by SIGSTOP, and returns the pids of the paused ones.
+// All the replicas are paused at the same point, so the data of datanodes and the metadata are consistent.
+func (c *Cluster) pauseReplicas(pidsDir string) (map[string]int, error) {
+	paused := make(map[string]int)
+	for replica, recorded := range collectPidsForBareMetal(pidsDir) {
+		if !components.IsRecordedProcessRunning(filepath.Join(pidsDir, replica)) {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(recorded))
+		if err != nil {
+			continue
+		}
+
+		if err = components.PauseProcess(pid); err != nil {
+			c.resumeReplicas(paused)
+			return nil, fmt.Errorf("error pausing replica '%s' (pid '%d'): %v", replica, pid, err)
+		}
+		paused[replica] = pid
+	}
+	return paused, nil
+}
+
+// resumeReplicas resumes the replicas paused by pauseReplicas.
+func (c *Cluster) resumeReplicas(paused map[string]int) {
+	for replica, pid := range paused {
+		if err := components.ResumeProcess(pid); err != nil {
+			c.logger.Warnf("failed to resume replica '%s' (pid '%d'), resume it by 'gtctl chaos resume': %v", replica, pid, err)
+		}
+	}
+}
+
+// backupWarnings returns the warnings about the data that is not stored in the data dir of cluster.
+func backupWarnings(cluster *config.BareMetalClusterMetadata) []string {
+	var (
+		cfg      = cluster.Config.Cluster
+		warnings []string
+	)
+
+	if cfg.Standalone == nil {
+		switch {
+		case cluster.UseMemoryMeta:
+			warnings = append(warnings, "The metadata stored in the memory of metasrv is not backed up")
+		case cfg.MetaSrv.Backend != config.MetaSrvBackendEmbeddedEtcd:
+			warnings = append(warnings, "The metadata stored in the external metadata store is not backed up")
+		}
+	}
+	if wal := cfg.WAL; wal != nil && wal.Kafka != nil && wal.Kafka.Embedded == nil {
+		warnings = append(warnings, "The WAL stored in the external Kafka is not backed up")
+	}
+	for _, datanode := range datanodeConfigs(cfg) {
+		if datanode.config.Storage != nil {
+			warnings = append(warnings, fmt.Sprintf("The data of '%s' stored in the object storage is not backed up", datanode.name))
+		}
+	}
+
+	return warnings
+}
+
+// namedDatanode is the config of datanode with the name of its component.
+type namedDatanode struct {
+	name   string
+	config *config.Datanode
+}
+
+// datanodeConfigs returns the configs of the default datanode and the datanode groups.
+func datanodeConfigs(cfg *config.BareMetalClusterComponentsConfig) []namedDatanode {
+	if cfg.Standalone != nil {
+		return nil
+	}
+
+	datanodes := []namedDatanode{{name: string(greptimedbclusterv1alpha1.DatanodeComponentKind), config: cfg.Datanode}}
+	for _, group := range cfg.DatanodeGroups {
+		datanodes = append(datanodes, namedDatanode{name: components.DatanodeGroupName(group.Name), config: &group.Datanode})
+	}
+	return datanodes
+}
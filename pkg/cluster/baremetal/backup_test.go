@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestBackupWarnings(t *testing.T) {
+	cluster := &config.BareMetalClusterMetadata{Config: config.DefaultBareMetalConfig()}
+	cluster.Config.Cluster.MetaSrv.Backend = config.MetaSrvBackendEmbeddedEtcd
+	assert.Empty(t, backupWarnings(cluster))
+
+	cluster.UseMemoryMeta = true
+	cluster.Config.Cluster.DatanodeGroups = []*config.DatanodeGroup{{
+		Name:     "cold",
+		Datanode: config.Datanode{Storage: &config.ObjectStorage{}},
+	}}
+	assert.Equal(t, []string{
+		"The metadata stored in the memory of metasrv is not backed up",
+		"The data of 'datanode-cold' stored in the object storage is not backed up",
+	}, backupWarnings(cluster))
+}
+
+func TestPauseReplicas(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the state of process is read from procfs")
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	pidsDir := t.TempDir()
+	for replica, pid := range map[string]int{"datanode.0": cmd.Process.Pid, "frontend.0": 0} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(pidsDir, replica), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(pidsDir, replica, "pid"), []byte(strconv.Itoa(pid)), 0644))
+	}
+
+	c := &Cluster{logger: logger.New(io.Discard, 0)}
+	paused, err := c.pauseReplicas(pidsDir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"datanode.0": cmd.Process.Pid}, paused)
+	assert.Eventually(t, func() bool {
+		return processState(t, cmd.Process.Pid) == "T"
+	}, time.Second, 10*time.Millisecond)
+
+	c.resumeReplicas(paused)
+	assert.Eventually(t, func() bool {
+		return processState(t, cmd.Process.Pid) != "T"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// processState returns the state of process in '/proc/<pid>/stat', e.g. 'S' for sleeping and 'T' for stopped.
+func processState(t *testing.T, pid int) string {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	assert.NoError(t, err)
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return fields[0]
+}
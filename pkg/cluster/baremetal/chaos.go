@@ -0,0 +1,123 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+// Chaos takes the chaos action on the replicas of one component of cluster, so the failover of cluster can be exercised.
+// The killed replicas are restarted by the supervisor if the cluster is created with the restart policy, while the
+// paused and partitioned ones stay so until they are resumed and healed.
+func (c *Cluster) Chaos(ctx context.Context, options *opt.ChaosOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "chaos"); err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	component, err := c.component(options.ComponentType)
+	if err != nil {
+		return err
+	}
+	replicas, err := chaosReplicas(component.Status(ctx), options.Index, options.Action == opt.ChaosHeal)
+	if err != nil {
+		return fmt.Errorf("error choosing the replicas of component '%s': %v", component.Name(), err)
+	}
+
+	for _, replica := range replicas {
+		switch options.Action {
+		case opt.ChaosKill:
+			err = components.KillProcess(replica.Pid)
+		case opt.ChaosPause:
+			err = components.PauseProcess(replica.Pid)
+		case opt.ChaosResume:
+			err = components.ResumeProcess(replica.Pid)
+		case opt.ChaosPartition:
+			err = partitionReplica(chaosMark(options.Name, replica.Replica), replica.Pid,
+				replicaPorts(component.ListenAddrs(), replica.Replica), c.replicaPidDir(replica.Replica), c.logger)
+		case opt.ChaosHeal:
+			err = healReplica(chaosMark(options.Name, replica.Replica), replica.Pid, c.replicaPidDir(replica.Replica))
+		default:
+			return fmt.Errorf("unknown chaos action '%s'", options.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("error taking chaos action '%s' on replica '%s': %v", options.Action, replica.Replica, err)
+		}
+		c.logger.V(0).Infof("Chaos action '%s' is taken on replica '%s' (pid '%d') of cluster '%s'",
+			options.Action, replica.Replica, replica.Pid, options.Name)
+	}
+
+	return nil
+}
+
+// chaosReplicas returns the replicas that the chaos action is taken on, e.g. the one of index or all of them if index is
+// negative. Only the replicas whose processes are alive are returned, unless all is set for cleaning up the faults.
+func chaosReplicas(statuses []components.ReplicaStatus, index int, all bool) ([]components.ReplicaStatus, error) {
+	if index >= len(statuses) {
+		return nil, fmt.Errorf("index %d is out of the %d replicas", index, len(statuses))
+	}
+	if index >= 0 {
+		statuses = statuses[index : index+1]
+	}
+
+	var replicas []components.ReplicaStatus
+	for _, status := range statuses {
+		alive := status.State == components.ReplicaStateRunning || status.State == components.ReplicaStateUnhealthy
+		if alive || (all && status.Pid > 0) {
+			replicas = append(replicas, status)
+		}
+	}
+	if len(replicas) == 0 {
+		return nil, fmt.Errorf("no replica is alive")
+	}
+	return replicas, nil
+}
+
+// replicaPorts returns the ports that the replica listens on.
+func replicaPorts(addrs []components.ListenAddr, replica string) []string {
+	var ports []string
+	for _, addr := range addrs {
+		if addr.Replica != replica {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(addr.Addr); err == nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// chaosMark returns the comment that marks the firewall rules of partitioning the replica, which is also the name
+// of the cgroup that the replica is moved into, so they can be found and removed by healing.
+func chaosMark(name, replica string) string {
+	return fmt.Sprintf("gtctl-chaos-%s-%s", name, replica)
+}
+
+// replicaPidDir returns the pid dir of replica, where the state of partitioning the replica is recorded.
+func (c *Cluster) replicaPidDir(replica string) string {
+	return filepath.Join(c.mm.GetClusterScopeDirs().PidsDir, replica)
+}
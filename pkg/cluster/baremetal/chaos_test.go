@@ -0,0 +1,63 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+func TestChaosReplicas(t *testing.T) {
+	statuses := []components.ReplicaStatus{
+		{Replica: "datanode.0", Pid: 100, State: components.ReplicaStateRunning},
+		{Replica: "datanode.1", Pid: 101, State: components.ReplicaStateUnhealthy},
+		{Replica: "datanode.2", Pid: 102, State: components.ReplicaStateDead},
+		{Replica: "datanode.3", State: components.ReplicaStateStopped},
+	}
+
+	replicas, err := chaosReplicas(statuses, -1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, statuses[:2], replicas)
+
+	replicas, err = chaosReplicas(statuses, 1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, statuses[1:2], replicas)
+
+	// The faults of the exited replicas are cleaned up too.
+	replicas, err = chaosReplicas(statuses, -1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, statuses[:3], replicas)
+
+	_, err = chaosReplicas(statuses, 2, false)
+	assert.EqualError(t, err, "no replica is alive")
+	_, err = chaosReplicas(statuses, 4, false)
+	assert.EqualError(t, err, "index 4 is out of the 4 replicas")
+}
+
+func TestReplicaPorts(t *testing.T) {
+	addrs := []components.ListenAddr{
+		{Replica: "datanode.0", Arg: "--http-addr", Addr: "0.0.0.0:4000"},
+		{Replica: "datanode.0", Arg: "--rpc-addr", Addr: "0.0.0.0:14100"},
+		{Replica: "datanode.1", Arg: "--http-addr", Addr: "0.0.0.0:4001"},
+		{Replica: "datanode.1", Arg: "--rpc-addr", Addr: "0.0.0.0:14101"},
+	}
+	assert.Equal(t, []string{"4001", "14101"}, replicaPorts(addrs, "datanode.1"))
+	assert.Empty(t, replicaPorts(addrs, "datanode.2"))
+}
@@ -18,14 +18,19 @@ package baremetal
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
-	"github.com/GreptimeTeam/gtctl/pkg/cluster"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/components"
 	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 )
@@ -34,7 +39,30 @@ type Cluster struct {
 	config        *config.BareMetalClusterConfig
 	createNoDirs  bool
 	enableCache   bool
+	skipVerify    bool
 	useMemoryMeta bool
+	detach        bool
+	rotateLogs    bool
+	keepOnFailure bool
+	drainTimeout  time.Duration
+
+	// resumed is the checkpoint of the failed creation that is resumed, it's nil if it's a new creation.
+	resumed *config.CreateCheckpoint
+
+	// checkpointMu serializes the checkpoints of the components that are started in parallel.
+	checkpointMu sync.Mutex
+
+	// sourceBuild builds greptime from the local source tree if it's not nil.
+	sourceBuild *artifacts.SourceBuildOptions
+
+	// artifactPlatform is the platform that the binaries are downloaded for, it's the current platform if it's nil.
+	artifactPlatform *artifacts.Platform
+
+	// dryRun records what would be created and run instead of doing it, it's nil if not in dry-run mode.
+	dryRun *components.DryRun
+
+	// events publishes the lifecycle events of cluster, nothing is published if it's nil.
+	events *events.Bus
 
 	am artifacts.Manager
 	mm metadata.Manager
@@ -52,18 +80,111 @@ type ClusterComponents struct {
 	Datanode components.ClusterComponent
 	Frontend components.ClusterComponent
 	Etcd     components.ClusterComponent
+
+	// Flownode is nil if it's not specified in the cluster config.
+	Flownode components.ClusterComponent
+
+	// Kafka is nil if the embedded Kafka WAL is not specified in the cluster config.
+	Kafka components.ClusterComponent
+
+	// DatanodeGroups are started after the Datanode in order.
+	DatanodeGroups []components.ClusterComponent
+
+	// Standalone is the only component if the standalone is specified in the cluster config.
+	Standalone components.ClusterComponent
+}
+
+// datanodes returns the default datanodes and the datanode groups.
+func (cc *ClusterComponents) datanodes() []components.ClusterComponent {
+	if cc.Datanode == nil {
+		return cc.DatanodeGroups
+	}
+	return append([]components.ClusterComponent{cc.Datanode}, cc.DatanodeGroups...)
 }
 
 func NewClusterComponents(config *config.BareMetalClusterComponentsConfig, workingDirs components.WorkingDirs,
 	wg *sync.WaitGroup, logger logger.Logger, useMemoryMeta bool) *ClusterComponents {
-	return &ClusterComponents{
-		MetaSrv:  components.NewMetaSrv(config.MetaSrv, workingDirs, wg, logger, useMemoryMeta),
-		Datanode: components.NewDataNode(config.Datanode, config.MetaSrv.ServerAddr, workingDirs, wg, logger),
-		Frontend: components.NewFrontend(config.Frontend, config.MetaSrv.ServerAddr, workingDirs, wg, logger),
-		Etcd:     components.NewEtcd(workingDirs, wg, logger),
+	if config.Standalone != nil {
+		standalone := *config.Standalone
+		standalone.Env = mergeEnv(mergeEnv(config.Env,
+			components.LogFormatEnv(config.LogFormat, components.StandaloneComponentName)), standalone.Env)
+		return &ClusterComponents{
+			Standalone: components.NewStandalone(&standalone, workingDirs, wg, logger),
+		}
+	}
+
+	// Merge the cluster level env into the copies of component configs,
+	// so the cluster config itself is left untouched. The env that configures WAL, heartbeat
+	// and log format is merged between the cluster level env and the component level env.
+	datanodeKind := string(greptimedbclusterv1alpha1.DatanodeComponentKind)
+	frontendKind := string(greptimedbclusterv1alpha1.FrontendComponentKind)
+	metaSrvEnv := mergeEnv(mergeEnv(config.Env, components.WALEnv(config.WAL, components.MetaSrvComponentName)),
+		components.LogFormatEnv(config.LogFormat, components.MetaSrvComponentName))
+	datanodeEnv := mergeEnv(mergeEnv(mergeEnv(config.Env, components.WALEnv(config.WAL, datanodeKind)),
+		components.HeartbeatEnv(config.MetaSrv.Heartbeat, datanodeKind)),
+		components.LogFormatEnv(config.LogFormat, datanodeKind))
+	frontendEnv := mergeEnv(mergeEnv(config.Env, components.HeartbeatEnv(config.MetaSrv.Heartbeat, frontendKind)),
+		components.LogFormatEnv(config.LogFormat, frontendKind))
+
+	metaSrv, datanode, frontend := *config.MetaSrv, *config.Datanode, *config.Frontend
+	metaSrv.Env = mergeEnv(metaSrvEnv, metaSrv.Env)
+	datanode.Env = mergeEnv(datanodeEnv, datanode.Env)
+	frontend.Env = mergeEnv(frontendEnv, frontend.Env)
+
+	metaSrvAddr := metaSrvAddrs(config)
+	cc := &ClusterComponents{
+		MetaSrv:  components.NewMetaSrv(&metaSrv, workingDirs, wg, logger, useMemoryMeta),
+		Datanode: components.NewDataNode(&datanode, metaSrvAddr, workingDirs, wg, logger),
+		Frontend: components.NewFrontend(&frontend, metaSrvAddr, workingDirs, wg, logger),
+		Etcd:     components.NewEtcd(config.MetaSrv.StoreAddr, workingDirs, wg, logger),
+	}
+	if config.Flownode != nil {
+		flownode := *config.Flownode
+		flownode.Env = mergeEnv(mergeEnv(mergeEnv(config.Env,
+			components.HeartbeatEnv(config.MetaSrv.Heartbeat, components.FlownodeComponentName)),
+			components.LogFormatEnv(config.LogFormat, components.FlownodeComponentName)), flownode.Env)
+		cc.Flownode = components.NewFlownode(&flownode, metaSrvAddr, workingDirs, wg, logger)
+	}
+	for i, nodeID := range config.DatanodeGroupNodeIDs() {
+		group := config.DatanodeGroups[i]
+		datanode := group.Datanode
+		datanode.NodeID = nodeID
+		datanode.Env = mergeEnv(datanodeEnv, datanode.Env)
+		cc.DatanodeGroups = append(cc.DatanodeGroups, components.NewDatanodeGroup(group.Name, &datanode,
+			metaSrvAddr, workingDirs, wg, logger))
 	}
+	if config.WAL != nil && config.WAL.Kafka != nil && config.WAL.Kafka.Embedded != nil {
+		cc.Kafka = components.NewKafka(config.WAL.Kafka.Embedded, workingDirs, wg, logger)
+	}
+
+	return cc
+}
+
+// useEmbeddedEtcd returns whether the embedded etcd should be deployed as the metadata store.
+func (c *Cluster) useEmbeddedEtcd() bool {
+	return !c.useMemoryMeta && c.cc.Standalone == nil &&
+		c.config.Cluster.MetaSrv.Backend == config.MetaSrvBackendEmbeddedEtcd
 }
 
+// mergeEnv merges the env of cluster level and component level, the latter takes precedence.
+func mergeEnv(cluster, component map[string]string) map[string]string {
+	if len(cluster) == 0 {
+		return component
+	}
+
+	merged := make(map[string]string, len(cluster)+len(component))
+	for k, v := range cluster {
+		merged[k] = v
+	}
+	for k, v := range component {
+		merged[k] = v
+	}
+	return merged
+}
+
+// DefaultDrainTimeout is the default timeout of waiting for the components to exit gracefully.
+const DefaultDrainTimeout = 30 * time.Second
+
 type Option func(cluster *Cluster)
 
 // WithReplaceConfig replaces current cluster config with given config.
@@ -85,26 +206,113 @@ func WithEnableCache(enableCache bool) Option {
 	}
 }
 
+// WithSkipVerify skips verifying the checksums and signatures of the downloaded binaries.
+func WithSkipVerify(skipVerify bool) Option {
+	return func(c *Cluster) {
+		c.skipVerify = skipVerify
+	}
+}
+
+// WithGreptimeSource builds greptime from the local source tree and uses the built binary for the cluster.
+func WithGreptimeSource(opts *artifacts.SourceBuildOptions) Option {
+	return func(c *Cluster) {
+		c.sourceBuild = opts
+	}
+}
+
+// WithArtifactPlatform downloads the binaries for the platform instead of the current one,
+// which only works for the clusters deployed on the remote hosts.
+func WithArtifactPlatform(platform *artifacts.Platform) Option {
+	return func(c *Cluster) {
+		c.artifactPlatform = platform
+	}
+}
+
 func WithMetastore(useMemoryMeta bool) Option {
 	return func(c *Cluster) {
 		c.useMemoryMeta = useMemoryMeta
 	}
 }
 
+// WithDrainTimeout sets the timeout of waiting for the components to exit gracefully
+// before they are killed.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(c *Cluster) {
+		c.drainTimeout = timeout
+	}
+}
+
+// WithDetach keeps the components running in background after the gtctl process exits.
+func WithDetach(detach bool) Option {
+	return func(c *Cluster) {
+		c.detach = detach
+	}
+}
+
+// WithLogRotation rotates the logs of components by the log rotation of cluster config. The logs are piped
+// through gtctl to be rotated, so it's only enabled when the components are stopped as gtctl exits, i.e. they
+// are created or started in the foreground, otherwise the components would lose their logs after gtctl exits.
+func WithLogRotation(rotateLogs bool) Option {
+	return func(c *Cluster) {
+		c.rotateLogs = rotateLogs
+	}
+}
+
+// WithKeepOnFailure leaves the started components running when the creation fails for debugging,
+// and the creation can be resumed by Resume.
+func WithKeepOnFailure(keepOnFailure bool) Option {
+	return func(c *Cluster) {
+		c.keepOnFailure = keepOnFailure
+	}
+}
+
+// WithStandalone runs the cluster in standalone mode, the default standalone
+// config is used if it's not specified in the cluster config.
+func WithStandalone(standalone bool) Option {
+	return func(c *Cluster) {
+		if standalone && c.config.Cluster.Standalone == nil {
+			c.config.Cluster.Standalone = config.DefaultStandaloneConfig()
+		}
+	}
+}
+
+// WithDryRun prints the directories, files and commands of creating the cluster without doing it.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Cluster) {
+		if dryRun {
+			c.dryRun = &components.DryRun{}
+		}
+	}
+}
+
+// WithEvents publishes the lifecycle events of cluster to the bus, e.g. the replicas are started or crashed.
+// The crashes are only observed when the cluster runs in the foreground of gtctl.
+func WithEvents(bus *events.Bus) Option {
+	return func(c *Cluster) {
+		c.events = bus
+	}
+}
+
 func WithCreateNoDirs() Option {
 	return func(c *Cluster) {
 		c.createNoDirs = true
 	}
 }
 
-func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Operations, error) {
+func NewCluster(l logger.Logger, clusterName string, opts ...Option) (opt.Deployer, error) {
+	// The config of cluster is named after the cluster, which conflicts with the state of cluster.
+	if fmt.Sprintf("%s.yaml", clusterName) == metadata.ClusterStateFileName {
+		return nil, fmt.Errorf("cluster name '%s' is reserved", clusterName)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
 	c := &Cluster{
-		logger: l,
-		config: config.DefaultBareMetalConfig(),
-		ctx:    ctx,
-		stop:   stop,
+		logger:       l,
+		config:       config.DefaultBareMetalConfig(),
+		drainTimeout: DefaultDrainTimeout,
+		ctx:          ctx,
+		stop:         stop,
 	}
 
 	for _, opt := range opts {
@@ -125,7 +333,11 @@ func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Op
 	c.mm = mm
 
 	// Configure Artifact Manager.
-	am, err := artifacts.NewManager(l)
+	var amOpts []artifacts.Option
+	if c.artifactPlatform != nil {
+		amOpts = append(amOpts, artifacts.WithPlatform(*c.artifactPlatform))
+	}
+	am, err := artifacts.NewManager(l, amOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -134,16 +346,109 @@ func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Op
 	// Configure Cluster Components.
 	mm.AllocateClusterScopeDirs(clusterName)
 	if !c.createNoDirs {
-		if err = mm.CreateClusterScopeDirs(c.config); err != nil {
+		// The built binary is recorded as the local artifact in the cluster config,
+		// so the cluster is restarted and scaled with it.
+		if c.sourceBuild != nil {
+			if err = c.buildFromSource(); err != nil {
+				return nil, err
+			}
+		}
+
+		// Apply the offsets and allocate the ports before the cluster config is recorded in the metadata.
+		shifted := c.config.Cluster.PortOffset > 0
+		if err = applyOffsets(c.config.Cluster); err != nil {
+			return nil, fmt.Errorf("failed to apply offsets: %v", err)
+		}
+		if shifted && len(c.config.Hosts) == 0 {
+			if err = checkOtherClusters(mm, clusterName, c.config.Cluster); err != nil {
+				return nil, err
+			}
+		}
+		if c.config.Cluster.AutoPortAllocation {
+			if err = allocatePorts(c.config.Cluster); err != nil {
+				return nil, fmt.Errorf("failed to allocate ports: %v", err)
+			}
+		}
+		if err = applyPlacement(c.config); err != nil {
+			return nil, fmt.Errorf("failed to place the replicas on hosts: %v", err)
+		}
+		if c.dryRun != nil {
+			err = c.recordClusterScopeDirs()
+		} else {
+			err = mm.CreateClusterScopeDirs(c.config)
+		}
+		if err != nil {
 			return nil, err
 		}
 	}
 	csd := mm.GetClusterScopeDirs()
-	c.cc = NewClusterComponents(c.config.Cluster, components.WorkingDirs{
-		DataDir: csd.DataDir,
-		LogsDir: csd.LogsDir,
-		PidsDir: csd.PidsDir,
-	}, &c.wg, c.logger, c.useMemoryMeta)
+	workingDirs := components.WorkingDirs{
+		DataDir:      csd.DataDir,
+		LogsDir:      csd.LogsDir,
+		PidsDir:      csd.PidsDir,
+		CrashesDir:   csd.CrashesDir,
+		CrashDump:    c.config.Cluster.CrashDump,
+		DrainTimeout: c.drainTimeout,
+		Events:       c.events,
+		DryRun:       c.dryRun,
+	}
+	if c.rotateLogs {
+		workingDirs.LogRotation = c.config.Cluster.LogRotation
+	}
+	c.cc = NewClusterComponents(c.config.Cluster, workingDirs, &c.wg, c.logger, c.useMemoryMeta)
 
 	return c, nil
 }
+
+// checkPortConflicts checks the listen addresses of the components before starting them.
+func (c *Cluster) checkPortConflicts(ccs ...components.ClusterComponent) error {
+	var addrs []components.ListenAddr
+	for _, cc := range ccs {
+		if cc == nil {
+			continue
+		}
+		addrs = append(addrs, cc.ListenAddrs()...)
+	}
+
+	return components.CheckPortConflicts(addrs)
+}
+
+// stopComponents stops all the components gracefully in the reverse order of starting.
+func (c *Cluster) stopComponents(ctx context.Context) error {
+	// The cluster may have been scaled by the other gtctl processes,
+	// so the components are reloaded from the cluster metadata.
+	if cluster, err := c.get(ctx, &opt.GetOptions{}); err == nil {
+		c.loadComponents(cluster)
+	}
+	if c.isMultiHost() {
+		return c.stopRemote(ctx)
+	}
+
+	ordered := []components.ClusterComponent{
+		c.cc.Standalone,
+		c.cc.Frontend,
+		c.cc.Flownode,
+	}
+	for i := len(c.cc.DatanodeGroups) - 1; i >= 0; i-- {
+		ordered = append(ordered, c.cc.DatanodeGroups[i])
+	}
+	ordered = append(ordered, c.cc.Datanode, c.cc.MetaSrv, c.cc.Kafka, c.cc.Etcd)
+
+	for _, component := range ordered {
+		if component == nil {
+			continue
+		}
+		if err := c.stopComponent(ctx, component); err != nil {
+			return err
+		}
+	}
+	c.recordState(ctx)
+
+	return nil
+}
+
+// stopComponent stops the component and waits for each of its replicas to exit in drain timeout.
+func (c *Cluster) stopComponent(ctx context.Context, component components.ClusterComponent) error {
+	c.logger.V(3).Infof("Stopping component '%s' with drain timeout %s", component.Name(), c.drainTimeout)
+	return component.Stop(ctx)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestMergeEnv(t *testing.T) {
+	cluster := map[string]string{
+		"RUST_BACKTRACE": "1",
+		"TZ":             "UTC",
+	}
+	component := map[string]string{
+		"RUST_BACKTRACE":        "full",
+		"AWS_ACCESS_KEY_ID":     "key",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+	}
+
+	assert.Equal(t, map[string]string{
+		"RUST_BACKTRACE":        "full",
+		"TZ":                    "UTC",
+		"AWS_ACCESS_KEY_ID":     "key",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+	}, mergeEnv(cluster, component))
+	assert.Equal(t, cluster, mergeEnv(cluster, nil))
+	assert.Equal(t, component, mergeEnv(nil, component))
+
+	// The input maps should be left untouched.
+	assert.Equal(t, "1", cluster["RUST_BACKTRACE"])
+}
+
+func TestNewClusterComponentsStandalone(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig().Cluster
+	cfg.Env = map[string]string{"RUST_BACKTRACE": "1"}
+	cfg.Standalone = config.DefaultStandaloneConfig()
+
+	cc := NewClusterComponents(cfg, components.WorkingDirs{}, nil, nil, false)
+	assert.NotNil(t, cc.Standalone)
+	assert.Equal(t, components.StandaloneComponentName, cc.Standalone.Name())
+	assert.Nil(t, cc.MetaSrv)
+	assert.Nil(t, cc.Frontend)
+	assert.Empty(t, cc.datanodes())
+
+	// The cluster config is left untouched.
+	assert.Empty(t, cfg.Standalone.Env)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+)
+
+// connectArgs are the args of listen addresses that serve each protocol.
+var connectArgs = map[opt.ConnectProtocol]string{
+	opt.MySQL:    "--mysql-addr",
+	opt.Postgres: "--postgres-addr",
+	opt.HTTP:     "--http-addr",
+	opt.GRPC:     "--rpc-addr",
+}
+
+// Connect connects to the first running replica of frontend, or the standalone, by the protocol.
+// The address of replica is discovered from the cluster metadata, which includes the allocated ports.
+func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	protocol := options.Protocol
+	if client := protocol.Client(); len(client) > 0 && !connector.IsClientInstalled(client) {
+		c.logger.Warnf("The '%s' client is not installed, falling back to the built-in SQL shell on HTTP API", client)
+		protocol = opt.HTTP
+	}
+
+	arg, ok := connectArgs[protocol]
+	if !ok {
+		return fmt.Errorf("unsupported connect protocol type")
+	}
+	addr, err := c.connectAddr(ctx, arg)
+	if err != nil {
+		return err
+	}
+	c.logger.V(3).Infof("Connecting to %s of cluster '%s'", addr, options.Name)
+
+	switch protocol {
+	case opt.MySQL:
+		return connector.MysqlAt(addr, c.logger)
+	case opt.Postgres:
+		return connector.PostgresSQLAt(addr, c.logger)
+	case opt.HTTP:
+		return connector.SQLShell(ctx, addr, options.Database, os.Stdin, os.Stdout)
+	default:
+		return connector.GRPCAt(addr, c.logger)
+	}
+}
+
+// Exec executes the query through the HTTP API of the first running replica of frontend, or the standalone.
+func (c *Cluster) Exec(ctx context.Context, options *opt.ExecOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	addr, err := c.connectAddr(ctx, connectArgs[opt.HTTP])
+	if err != nil {
+		return err
+	}
+	c.logger.V(3).Infof("Executing query on %s of cluster '%s'", addr, options.Name)
+
+	return connector.Exec(ctx, addr, &options.ExecQuery, options.Writer)
+}
+
+// Bench generates the load of benchmark against the HTTP API of the first running replica of frontend, or the standalone.
+func (c *Cluster) Bench(ctx context.Context, options *opt.BenchOptions) (*connector.BenchReport, error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return nil, err
+	}
+	c.loadComponents(cluster)
+
+	addr, err := c.connectAddr(ctx, connectArgs[opt.HTTP])
+	if err != nil {
+		return nil, err
+	}
+	c.logger.V(3).Infof("Benchmarking on %s of cluster '%s'", addr, options.Name)
+
+	return connector.Bench(ctx, addr, &options.BenchOptions, c.logger)
+}
+
+// SeedMetrics writes the synthetic metrics through the HTTP API of the first running replica of frontend, or the standalone.
+func (c *Cluster) SeedMetrics(ctx context.Context, options *opt.SeedMetricsOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	addr, err := c.connectAddr(ctx, connectArgs[opt.HTTP])
+	if err != nil {
+		return err
+	}
+	c.logger.V(3).Infof("Writing metrics on %s of cluster '%s'", addr, options.Name)
+
+	return connector.SeedMetrics(ctx, addr, &options.MetricsOptions, c.logger)
+}
+
+// connectAddr returns the address of arg of the first running replica of frontend, or the standalone.
+// The unspecified host of address is replaced, so it's connectable.
+func (c *Cluster) connectAddr(ctx context.Context, arg string) (string, error) {
+	component := c.cc.Frontend
+	if c.cc.Standalone != nil {
+		component = c.cc.Standalone
+	}
+
+	running := make(map[string]bool)
+	for _, status := range component.Status(ctx) {
+		running[status.Replica] = status.State == components.ReplicaStateRunning
+	}
+
+	configured := false
+	for _, addr := range component.ListenAddrs() {
+		if addr.Arg != arg {
+			continue
+		}
+		configured = true
+		if running[addr.Replica] {
+			return components.HealthCheckAddr(addr.Addr, "")
+		}
+	}
+
+	if !configured {
+		return "", fmt.Errorf("'%s' of %s is not configured", arg, component.Name())
+	}
+	return "", fmt.Errorf("no replica of %s is running", component.Name())
+}
@@ -20,23 +20,63 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/certs"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
 func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error {
+	// The binaries of the other platforms can only be distributed to the hosts.
+	if c.artifactPlatform != nil && !c.artifactPlatform.IsCurrent() && !c.isMultiHost() {
+		return fmt.Errorf("the binaries for platform '%s' can't run on the local host of platform '%s', "+
+			"the artifact platform only works with the hosts", c.artifactPlatform, artifacts.CurrentPlatform())
+	}
+	if c.isMultiHost() {
+		return c.createRemote(ctx, options)
+	}
+	if c.dryRun != nil {
+		return c.createDryRun(ctx, options)
+	}
+	if c.detach {
+		if err := checkDetachable(c.config.Cluster); err != nil {
+			return err
+		}
+		c.logger.Warnf("The components of detached cluster are not supervised after gtctl exits, " +
+			"their crashes are neither recorded nor reported as events")
+	}
+	if c.keepOnFailure && c.config.Cluster.LogRotation != nil {
+		c.logger.Warnf("The logs of components are not rotated, since they may be kept running after gtctl exits on failure")
+	}
+
 	spinner := options.Spinner
 
+	if err := c.recordForeground(ctx); err != nil {
+		return err
+	}
+
+	// The steps are numbered in the progress, e.g. '[1/2] Installing Etcd Cluster...'.
+	steps, step := 1, 0
+	if c.cc.Standalone == nil && c.useEmbeddedEtcd() {
+		steps++
+	}
+	if c.cc.Standalone == nil && c.cc.Kafka != nil {
+		steps++
+	}
 	withSpinner := func(target string, f func(context.Context, *opt.CreateOptions) error) error {
+		step++
 		if spinner != nil {
-			spinner.Start(fmt.Sprintf("Installing %s...", target))
+			spinner.Start(status.StepStatus(step, steps, fmt.Sprintf("Installing %s...", target)))
 		}
 
 		if err := f(ctx, options); err != nil {
@@ -52,18 +92,86 @@ func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error
 		return nil
 	}
 
-	if c.useMemoryMeta {
+	if c.cc.Standalone != nil {
+		if err := withSpinner("GreptimeDB Standalone", c.createStandalone); err != nil {
+			return c.abortCreation(ctx, options, err)
+		}
+		if err := c.verify(ctx, options); err != nil {
+			return c.abortCreation(ctx, options, err)
+		}
+		c.clearCheckpoint(ctx)
+		c.recordState(ctx)
+		c.events.Publish(&events.Event{Type: events.TypeClusterReady, Message: "standalone is ready"})
+		return c.seed(ctx, options)
+	}
+
+	if c.useEmbeddedEtcd() {
 		if err := withSpinner("Etcd Cluster", c.createEtcdCluster); err != nil {
-			return err
+			return c.abortCreation(ctx, options, err)
+		}
+	}
+	if c.cc.Kafka != nil {
+		if err := withSpinner("Kafka", c.createKafka); err != nil {
+			return c.abortCreation(ctx, options, err)
 		}
 	}
 	if err := withSpinner("GreptimeDB Cluster", c.createCluster); err != nil {
+		return c.abortCreation(ctx, options, err)
+	}
+	if err := c.verify(ctx, options); err != nil {
+		return c.abortCreation(ctx, options, err)
+	}
+	c.clearCheckpoint(ctx)
+	c.recordState(ctx)
+	c.events.Publish(&events.Event{Type: events.TypeClusterReady, Message: "cluster is ready"})
+
+	return c.seed(ctx, options)
+}
+
+// seed loads the seed data into the healthy cluster, the cluster is closed if the seeding fails,
+// just like it fails to be created.
+func (c *Cluster) seed(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Seed == nil {
+		return nil
+	}
+
+	addr, err := c.connectAddr(ctx, connectArgs[opt.HTTP])
+	if err == nil {
+		err = connector.Seed(ctx, addr, options.Seed, c.logger)
+	}
+	if err != nil {
 		if err := c.Wait(ctx, true); err != nil {
 			return err
 		}
-		return err
+		return fmt.Errorf("error seeding cluster '%s': %v", options.Name, err)
+	}
+
+	return nil
+}
+
+// createDryRun records the directories, files and commands of creating the cluster and prints them,
+// nothing is created or run.
+func (c *Cluster) createDryRun(ctx context.Context, options *opt.CreateOptions) error {
+	var create []func(context.Context, *opt.CreateOptions) error
+	if c.cc.Standalone != nil {
+		create = append(create, c.createStandalone)
+	} else {
+		if c.useEmbeddedEtcd() {
+			create = append(create, c.createEtcdCluster)
+		}
+		if c.cc.Kafka != nil {
+			create = append(create, c.createKafka)
+		}
+		create = append(create, c.createCluster)
 	}
 
+	for _, f := range create {
+		if err := f(ctx, options); err != nil {
+			return err
+		}
+	}
+	c.renderDryRun()
+
 	return nil
 }
 
@@ -73,53 +181,152 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 	}
 	clusterOpt := options.Cluster
 
+	var ccs []components.ClusterComponent
+	for _, component := range append([]components.ClusterComponent{c.cc.MetaSrv, c.cc.Flownode, c.cc.Frontend}, c.cc.datanodes()...) {
+		if component != nil && !c.isResumed(component) {
+			ccs = append(ccs, component)
+		}
+	}
+	if err := c.checkPortConflicts(ccs...); err != nil {
+		return err
+	}
+	if c.dryRun == nil {
+		if err := c.checkBackendStorage(ctx); err != nil {
+			return err
+		}
+	}
+
+	binPath, err := c.greptimeBinary(ctx, clusterOpt.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+
+	// The components are started tier by tier in the order of their dependencies, each tier is ready before
+	// the next one is started, and the components of one tier, i.e. the datanode groups, are started in parallel.
+	// They are started one by one in dry-run mode, so the recorded commands are in order.
+	limit := c.config.Cluster.Concurrency()
+	if c.dryRun != nil {
+		limit = 1
+	}
+	tiers := c.clusterTiers()
+
+	var total, started int
+	for _, tier := range tiers {
+		total += len(tier)
+	}
+	for _, tier := range tiers {
+		names := make([]string, 0, len(tier))
+		tasks := make([]func(context.Context) error, 0, len(tier))
+		for _, component := range tier {
+			component := component
+			names = append(names, component.Name())
+			tasks = append(tasks, func(ctx context.Context) error {
+				return c.startComponent(ctx, component, binPath)
+			})
+		}
+
+		started += len(tier)
+		if c.dryRun == nil {
+			options.Spinner.Progress(fmt.Sprintf("starting %s, %d/%d", strings.Join(names, ", "), started, total))
+		}
+		if err := runInParallel(ctx, limit, tasks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createStandalone starts the standalone instead of the distributed components.
+func (c *Cluster) createStandalone(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Cluster == nil {
+		return fmt.Errorf("missing create greptimedb cluster options")
+	}
+
+	if c.isResumed(c.cc.Standalone) {
+		return nil
+	}
+	if err := c.checkPortConflicts(c.cc.Standalone); err != nil {
+		return err
+	}
+
+	binPath, err := c.greptimeBinary(ctx, options.Cluster.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+
+	return c.startComponent(ctx, c.cc.Standalone, binPath)
+}
+
+// greptimeBinary returns the path of greptime binary, it will download the binary if it's not a local artifact.
+func (c *Cluster) greptimeBinary(ctx context.Context, useGreptimeCNArtifacts bool) (string, error) {
 	var binPath string
 	if c.config.Cluster.Artifact != nil {
 		if c.config.Cluster.Artifact.Local != "" {
 			binPath = c.config.Cluster.Artifact.Local
 
-			// Ensure the binary path exists.
-			if exist, _ := fileutils.IsFileExists(binPath); !exist {
-				return fmt.Errorf("greptimedb cluster artifact '%s' is not exist", binPath)
+			// Ensure the binary path exists, the binary built from source doesn't exist in dry-run mode.
+			if exist, _ := fileutils.IsFileExists(binPath); !exist && c.dryRun == nil {
+				return "", fmt.Errorf("greptimedb cluster artifact '%s' is not exist", binPath)
 			}
 		} else {
 			src, err := c.am.NewSource(artifacts.GreptimeBinName, c.config.Cluster.Artifact.Version,
-				artifacts.ArtifactTypeBinary, clusterOpt.UseGreptimeCNArtifacts)
+				artifacts.ArtifactTypeBinary, useGreptimeCNArtifacts)
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			destDir, err := c.mm.AllocateArtifactFilePath(src, false)
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			installDir, err := c.mm.AllocateArtifactFilePath(src, true)
 			if err != nil {
-				return err
+				return "", err
+			}
+
+			// The binary is not downloaded in dry-run mode.
+			if c.dryRun != nil {
+				return artifacts.InstalledBinaryPath(src, destDir), nil
 			}
 
 			artifactFile, err := c.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
 				EnableCache:      c.enableCache,
 				BinaryInstallDir: installDir,
+				SkipVerify:       c.skipVerify,
 			})
 			if err != nil {
-				return err
+				return "", err
 			}
 			binPath = artifactFile
 		}
 	}
 
-	if err := c.cc.MetaSrv.Start(c.ctx, c.stop, binPath); err != nil {
+	return binPath, nil
+}
+
+// buildFromSource builds greptime from the local source tree, and uses the built binary as the local artifact.
+func (c *Cluster) buildFromSource() error {
+	src, err := artifacts.NewSourceBuild(c.sourceBuild)
+	if err != nil {
 		return err
 	}
-	if err := c.cc.Datanode.Start(c.ctx, c.stop, binPath); err != nil {
+
+	installDir, err := c.mm.AllocateArtifactFilePath(src, true)
+	if err != nil {
 		return err
 	}
-	if err := c.cc.Frontend.Start(c.ctx, c.stop, binPath); err != nil {
-		return err
+
+	// The binary is not built in dry-run mode.
+	binPath := path.Join(installDir, artifacts.GreptimeBinName)
+	if c.dryRun == nil {
+		if binPath, err = c.am.BuildFromSource(c.ctx, src, installDir, c.sourceBuild); err != nil {
+			return err
+		}
 	}
 
+	c.config.Cluster.Artifact = &config.Artifact{Local: binPath, Version: src.Version}
 	return nil
 }
 
@@ -127,8 +334,25 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 	if options.Etcd == nil {
 		return fmt.Errorf("missing create etcd cluster options")
 	}
-	etcdOpt := options.Etcd
 
+	if c.isResumed(c.cc.Etcd) {
+		return nil
+	}
+
+	binPath, err := c.etcdBinary(ctx, options.Etcd.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkPortConflicts(c.cc.Etcd); err != nil {
+		return err
+	}
+
+	return c.startComponent(ctx, c.cc.Etcd, binPath)
+}
+
+// etcdBinary returns the path of etcd binary, it will download the binary if it's not a local artifact.
+func (c *Cluster) etcdBinary(ctx context.Context, useGreptimeCNArtifacts bool) (string, error) {
 	var binPath string
 	if c.config.Etcd.Artifact != nil {
 		if c.config.Etcd.Artifact.Local != "" {
@@ -136,95 +360,60 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 
 			// Ensure the binary path exists.
 			if exist, _ := fileutils.IsFileExists(binPath); !exist {
-				return fmt.Errorf("etcd artifact '%s' is not exist", binPath)
+				return "", fmt.Errorf("etcd artifact '%s' is not exist", binPath)
 			}
 		} else {
 			src, err := c.am.NewSource(artifacts.EtcdBinName, c.config.Etcd.Artifact.Version,
-				artifacts.ArtifactTypeBinary, etcdOpt.UseGreptimeCNArtifacts)
+				artifacts.ArtifactTypeBinary, useGreptimeCNArtifacts)
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			destDir, err := c.mm.AllocateArtifactFilePath(src, false)
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			installDir, err := c.mm.AllocateArtifactFilePath(src, true)
 			if err != nil {
-				return err
+				return "", err
 			}
 
-			artifactFile, err := c.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
-				EnableCache:      c.enableCache,
-				BinaryInstallDir: installDir,
-			})
-			if err != nil {
-				return err
+			if c.dryRun != nil {
+				binPath = artifacts.InstalledBinaryPath(src, destDir)
+			} else {
+				artifactFile, err := c.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
+					EnableCache:      c.enableCache,
+					BinaryInstallDir: installDir,
+					SkipVerify:       c.skipVerify,
+				})
+				if err != nil {
+					return "", err
+				}
+				binPath = artifactFile
 			}
-			binPath = artifactFile
 		}
 	}
 
-	if err := c.cc.Etcd.Start(c.ctx, c.stop, binPath); err != nil {
-		return err
-	}
-	if err := c.checkEtcdHealth(binPath); err != nil {
-		return err
-	}
-
-	return nil
+	return binPath, nil
 }
 
-func (c *Cluster) checkEtcdHealth(etcdBin string) error {
-	// It's very likely that "etcdctl" is under the same directory of "etcd".
-	etcdctlBin := path.Join(etcdBin, "../etcdctl")
-	exists, err := fileutils.IsFileExists(etcdctlBin)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		c.logger.V(3).Infof("'etcdctl' is not found under the same directory of 'etcd', skip checking the healthy of Etcd.")
+// createKafka starts the embedded Kafka as the WAL of cluster.
+func (c *Cluster) createKafka(ctx context.Context, _ *opt.CreateOptions) error {
+	if c.isResumed(c.cc.Kafka) {
 		return nil
 	}
 
-	for retry := 0; retry < 10; retry++ {
-		outputRaw, err := exec.Command(etcdctlBin, "endpoint", "status").Output()
-		if err != nil {
-			return err
-		}
-		output := string(outputRaw)
-		statuses := strings.Split(output, "\n")
-
-		hasLeader := false
-		for i := 0; i < len(statuses); i++ {
-			fields := strings.Split(statuses[i], ",")
-
-			// We are checking Etcd status with default output format("--write-out=simple"), example output:
-			// 127.0.0.1:2379, 8e9e05c52164694d, 3.5.0, 131 kB, true, false, 3, 75, 75,
-			//
-			// The output fields are corresponding to the following table's columns (with format "--write-out=table"):
-			// +----------------+------------------+---------+---------+-----------+------------+-----------+------------+--------------------+--------+
-			// |    ENDPOINT    |        ID        | VERSION | DB SIZE | IS LEADER | IS LEARNER | RAFT TERM | RAFT INDEX | RAFT APPLIED INDEX | ERRORS |
-			// +----------------+------------------+---------+---------+-----------+------------+-----------+------------+--------------------+--------+
-			// | 127.0.0.1:2379 | 8e9e05c52164694d |   3.5.0 |  131 kB |      true |      false |         3 |         72 |                 72 |        |
-			// +----------------+------------------+---------+---------+-----------+------------+-----------+------------+--------------------+--------+
-			//
-			// So we can just check the "IS LEADER" field.
-			if strings.TrimSpace(fields[4]) == "true" {
-				hasLeader = true
-				break
-			}
-		}
-		if hasLeader {
-			return nil
-		}
+	binPath := path.Join(c.config.Cluster.WAL.Kafka.Embedded.Home, "bin", "kafka-server-start.sh")
+	if exist, _ := fileutils.IsFileExists(binPath); !exist {
+		return fmt.Errorf("kafka script '%s' is not exist", binPath)
+	}
 
-		time.Sleep(1 * time.Second)
+	if err := c.checkPortConflicts(c.cc.Kafka); err != nil {
+		return err
 	}
 
-	csd := c.mm.GetClusterScopeDirs()
-	return fmt.Errorf("etcd is not ready in 10 second! You can find its logs in %s", path.Join(csd.LogsDir, "etcd"))
+	return c.startComponent(ctx, c.cc.Kafka, binPath)
 }
 
 func (c *Cluster) Wait(ctx context.Context, close bool) error {
@@ -234,13 +423,45 @@ func (c *Cluster) Wait(ctx context.Context, close bool) error {
 	}
 
 	csd := c.mm.GetClusterScopeDirs()
+	if c.isMultiHost() {
+		if close {
+			c.logger.Warnf("The cluster(version=%s) run on hosts has been shutting down...", v)
+			return c.stopComponents(context.Background())
+		}
+		c.logger.V(0).Infof("The cluster(version=%s) is running on hosts %s now...", v, strings.Join(c.hostNames(), ", "))
+		c.logger.V(0).Infof("To view dashboard by accessing: %s", logger.Bold(c.dashboardURL()))
+		c.logger.V(0).Infof("To check the status of cluster by running: %s",
+			logger.Bold(fmt.Sprintf("gtctl cluster status %s", filepath.Base(csd.BaseDir))))
+		c.logger.V(0).Infof("To stop the cluster by running: %s",
+			logger.Bold(fmt.Sprintf("gtctl cluster stop %s", filepath.Base(csd.BaseDir))))
+		return nil
+	}
+	if !close && c.detach {
+		if err := c.detachForeground(ctx); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("The cluster(version=%s) is running in background in bare-metal mode now...", v)
+		c.logger.V(0).Infof("To view dashboard by accessing: %s", logger.Bold(c.dashboardURL()))
+		c.logger.V(0).Infof("To stop the cluster by running: %s",
+			logger.Bold(fmt.Sprintf("gtctl cluster stop %s", filepath.Base(csd.BaseDir))))
+		return nil
+	}
 	if !close {
 		c.logger.V(0).Infof("The cluster(pid=%d, version=%s) is running in bare-metal mode now...", os.Getpid(), v)
-		c.logger.V(0).Infof("To view dashboard by accessing: %s", logger.Bold("http://localhost:4000/dashboard/"))
+		c.logger.V(0).Infof("To view dashboard by accessing: %s", logger.Bold(c.dashboardURL()))
+		if tls := c.config.Cluster.Frontend.TLS; len(components.TLSMode(tls)) > 0 {
+			caCert := tls.CACertPath
+			if tls.AutoGenerate {
+				caCert = certs.PathsIn(components.CertsDir(components.WorkingDirs{DataDir: csd.DataDir})).CACert
+			}
+			c.logger.V(0).Infof("The frontend is serving TLS in '%s' mode, the CA certificate is: %s",
+				components.TLSMode(tls), logger.Bold(caCert))
+			c.logger.Warnf("The HTTP server of frontend still serves plain HTTP, since GreptimeDB doesn't support TLS for it")
+		}
 	} else {
 		c.logger.Warnf("The cluster(pid=%d, version=%s) run in bare-metal has been shutting down...", os.Getpid(), v)
 		c.logger.Warnf("To view the failure by browsing logs in: %s", logger.Bold(csd.LogsDir))
-		return nil
+		return c.stopComponents(context.Background())
 	}
 
 	// Wait for all the sub-processes to exit.
@@ -250,14 +471,33 @@ func (c *Cluster) Wait(ctx context.Context, close bool) error {
 	return nil
 }
 
-func (c *Cluster) wait(_ context.Context) error {
-	c.wg.Wait()
+// dashboardURL returns the dashboard URL served by the first replica of frontend, or the standalone.
+func (c *Cluster) dashboardURL() string {
+	replicaAddrs, httpAddr := c.config.Cluster.Frontend.ReplicaAddrs, c.config.Cluster.Frontend.HTTPAddr
+	if standalone := c.config.Cluster.Standalone; standalone != nil {
+		replicaAddrs, httpAddr = standalone.ReplicaAddrs, standalone.HTTPAddr
+	}
+	addr, err := components.HealthCheckAddr(components.ReplicaAddr(replicaAddrs, "--http-addr", httpAddr, 0), "")
+	if err != nil {
+		return "http://localhost:4000/dashboard/"
+	}
+	return fmt.Sprintf("http://%s/dashboard/", addr)
+}
 
+func (c *Cluster) wait(_ context.Context) error {
 	// We ignore the context from input params, since
 	// it is not the context of current cluster.
 	<-c.ctx.Done()
 
 	csd := c.mm.GetClusterScopeDirs()
 	c.logger.V(0).Infof("Cluster is shutting down, don't worry, it still remain in %s", logger.Bold(csd.BaseDir))
+
+	// The context of current cluster is done, use a new one to stop the components.
+	if err := c.stopComponents(context.Background()); err != nil {
+		return err
+	}
+
+	// Wait for all the sub-processes to exit.
+	c.wg.Wait()
 	return nil
 }
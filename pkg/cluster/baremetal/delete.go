@@ -19,10 +19,13 @@ package baremetal
 import (
 	"context"
 	"fmt"
-	"os"
-	"syscall"
+	"path"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
@@ -32,39 +35,121 @@ func (c *Cluster) Delete(ctx context.Context, options *opt.DeleteOptions) error
 		return err
 	}
 
-	running, ferr, serr := c.isClusterRunning(cluster.ForegroundPid)
-	if ferr != nil {
-		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, ferr)
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
 	}
-	if running || serr == nil {
+	if running {
 		return fmt.Errorf("cluster '%s' is running, please stop it before deleting", options.Name)
 	}
 
-	csd := c.mm.GetClusterScopeDirs()
-	c.logger.V(0).Infof("Deleting cluster configurations and runtime directories in %s", csd.BaseDir)
-	if err = c.delete(ctx, csd.BaseDir); err != nil {
-		return err
+	c.loadComponents(cluster)
+
+	var paths []string
+	if c.isMultiHost() {
+		// The dirs of replicas are on the hosts, only the metadata of cluster is kept locally.
+		if err = c.deleteRemote(ctx, options); err != nil {
+			return err
+		}
+		if len(options.Components) > 0 {
+			c.logger.V(0).Info("Deleted!")
+			return nil
+		}
+		paths = []string{c.mm.GetClusterScopeDirs().BaseDir}
+	} else if len(options.Components) > 0 {
+		if paths, err = c.deletedComponentPaths(ctx, options); err != nil {
+			return err
+		}
+	} else {
+		paths = c.deletedClusterPaths(options)
+	}
+
+	c.logger.V(0).Infof("Deleting %s", strings.Join(paths, ", "))
+	for _, p := range paths {
+		if err = c.delete(ctx, p); err != nil {
+			return err
+		}
 	}
 	c.logger.V(0).Info("Deleted!")
 
 	return nil
 }
 
-func (c *Cluster) delete(_ context.Context, baseDir string) error {
-	return fileutils.DeleteDirIfExists(baseDir)
+// deletedClusterPaths returns the paths to be deleted for deleting the whole cluster. The cluster dir is
+// deleted entirely unless the data or the logs are retained, in which case the retained dirs are
+// kept in the cluster dir and will be reused if the cluster is created again with the same name.
+func (c *Cluster) deletedClusterPaths(options *opt.DeleteOptions) []string {
+	csd := c.mm.GetClusterScopeDirs()
+
+	var paths []string
+	if !options.RetainData && !options.RetainLogs {
+		paths = append(paths, csd.BaseDir)
+	} else {
+		paths = append(paths, csd.ConfigPath, csd.StatePath, csd.PidsDir)
+		if !options.RetainLogs {
+			paths = append(paths, csd.LogsDir)
+		}
+		if !options.RetainData {
+			paths = append(paths, csd.DataDir)
+		}
+	}
+
+	// The data of datanodes placed out of the cluster dir are deleted by replicas.
+	if !options.RetainData {
+		for _, datanode := range datanodeConfigs(c.config.Cluster) {
+			if len(datanode.config.DataDir) == 0 {
+				continue
+			}
+			for i := 0; i < datanode.config.Replicas; i++ {
+				paths = append(paths, path.Join(datanode.config.DataDir, fmt.Sprintf("%s.%d", datanode.name, i)))
+			}
+		}
+	}
+
+	return paths
 }
 
-// isClusterRunning checks the current status of cluster by sending signal to process.
-func (c *Cluster) isClusterRunning(pid int) (runs bool, f error, s error) {
-	p, f := os.FindProcess(pid)
-	if f != nil {
-		return false, f, nil
+// deletedComponentPaths returns the pids, logs and data dirs of the replicas of the components to be deleted.
+func (c *Cluster) deletedComponentPaths(ctx context.Context, options *opt.DeleteOptions) ([]string, error) {
+	csd := c.mm.GetClusterScopeDirs()
+	_, dataDirs := componentPaths(c.config.Cluster, csd.DataDir)
+
+	var paths []string
+	for _, name := range options.Components {
+		component, err := c.deletableComponent(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, status := range component.Status(ctx) {
+			paths = append(paths, path.Join(csd.PidsDir, status.Replica))
+			if !options.RetainLogs {
+				paths = append(paths, path.Join(csd.LogsDir, status.Replica))
+			}
+			if dataDir, ok := dataDirs[component.Name()]; ok && !options.RetainData {
+				paths = append(paths, path.Join(dataDir, status.Replica))
+			}
+		}
 	}
 
-	s = p.Signal(syscall.Signal(0))
-	if s != nil {
-		return false, nil, s
+	return paths, nil
+}
+
+// deletableComponent returns the component by its name or its kind, e.g. both 'metasrv' and 'meta' are accepted.
+func (c *Cluster) deletableComponent(name string) (components.ClusterComponent, error) {
+	for _, component := range append(c.orderedComponents(), c.cc.Etcd) {
+		if component != nil && component.Name() == name {
+			return component, nil
+		}
 	}
+	return c.component(greptimedbclusterv1alpha1.ComponentKind(name))
+}
+
+func (c *Cluster) delete(_ context.Context, p string) error {
+	return fileutils.DeleteDirIfExists(p)
+}
 
-	return true, nil, nil
+// isClusterRunning checks whether the process of cluster is running.
+func (c *Cluster) isClusterRunning(pid int) bool {
+	return components.IsProcessRunning(pid)
 }
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestDeletedClusterPaths(t *testing.T) {
+	mm, err := metadata.New(t.TempDir())
+	assert.NoError(t, err)
+	mm.AllocateClusterScopeDirs("test")
+	csd := mm.GetClusterScopeDirs()
+
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.DatanodeGroups = []*config.DatanodeGroup{{
+		Name:     "hot",
+		Datanode: config.Datanode{Replicas: 1, DataDir: "/mnt/ssd"},
+	}}
+	c := &Cluster{config: cfg, mm: mm}
+
+	assert.Equal(t, []string{csd.BaseDir, path.Join("/mnt/ssd", "datanode-hot.0")},
+		c.deletedClusterPaths(&opt.DeleteOptions{}))
+	assert.Equal(t, []string{csd.ConfigPath, csd.StatePath, csd.PidsDir, csd.LogsDir},
+		c.deletedClusterPaths(&opt.DeleteOptions{RetainData: true}))
+	assert.Equal(t, []string{csd.ConfigPath, csd.StatePath, csd.PidsDir, csd.DataDir, path.Join("/mnt/ssd", "datanode-hot.0")},
+		c.deletedClusterPaths(&opt.DeleteOptions{RetainLogs: true}))
+}
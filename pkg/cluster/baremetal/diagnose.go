@@ -0,0 +1,309 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/version"
+)
+
+// diagnoseHTTPTimeout is the timeout of each request to the HTTP endpoints of replicas.
+const diagnoseHTTPTimeout = 5 * time.Second
+
+// metaSrvAdminEndpoints are the admin endpoints of metasrv that dump its metadata.
+var metaSrvAdminEndpoints = []string{"leader", "heartbeat", "node-lease"}
+
+var (
+	// credentialPattern matches the values of the keys that look like credentials,
+	// e.g. "password: xxx", "SECRET_ACCESS_KEY=xxx" and "user_provider=xxx".
+	credentialPattern = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|access[_-]?key|credential|user[_-]?provider)[\w-]*["']?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,]+)`)
+)
+
+// Diagnose collects the logs, configs, args and HTTP endpoint responses of the replicas of cluster,
+// the metadata dumped from metasrv and the environment info into a '.tar.gz' archive, which can be
+// attached to the bug reports. Everything is collected in best effort, the failures are recorded
+// in the archive instead, so the cluster is not required to be running.
+func (c *Cluster) Diagnose(ctx context.Context, options *opt.DiagnoseOptions) error {
+	if !strings.HasSuffix(options.Output, fileutils.TarGzExtension) {
+		return fmt.Errorf("only the '%s' archive is supported: %s", fileutils.TarGzExtension, options.Output)
+	}
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "diagnose"); err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	dir, err := os.MkdirTemp("", "gtctl-diagnose-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			c.logger.Warnf("failed to clean up '%s': %v", dir, err)
+		}
+	}()
+
+	c.logger.V(0).Infof("Collecting diagnostics of cluster '%s'...", options.Name)
+	d := &diagnostics{dir: dir, redact: options.Redact}
+	c.collectDiagnostics(ctx, d, options.Name, cluster)
+	if d.err != nil {
+		return fmt.Errorf("failed to collect diagnostics of cluster '%s': %v", options.Name, d.err)
+	}
+
+	if err = fileutils.ArchiveTarGz(options.Output, map[string]string{
+		fmt.Sprintf("%s-diagnostics", options.Name): dir,
+	}); err != nil {
+		return fmt.Errorf("failed to archive diagnostics of cluster '%s': %v", options.Name, err)
+	}
+	c.logger.V(0).Infof("Diagnostics of cluster '%s' are collected into %s", options.Name, options.Output)
+	if !options.Redact {
+		c.logger.Warnf("The credentials in the diagnostics are not redacted, use '--redact' to redact them")
+	}
+
+	return nil
+}
+
+// collectDiagnostics collects the diagnostics of cluster into the dir of d.
+func (c *Cluster) collectDiagnostics(ctx context.Context, d *diagnostics, name string,
+	cluster *config.BareMetalClusterMetadata) {
+	csd := c.mm.GetClusterScopeDirs()
+	client := &http.Client{Timeout: diagnoseHTTPTimeout}
+
+	d.writeFile("environment.txt", environmentInfo(cluster))
+	d.copyFile("metadata.yaml", csd.ConfigPath)
+	d.copyFile("state.yaml", csd.StatePath)
+
+	bulk, _, _, err := c.collectStatus(ctx, name, nil)
+	if err != nil {
+		d.writeFile("status.txt", fmt.Sprintf("failed to collect status: %v\n", err))
+	} else {
+		var view bytes.Buffer
+		c.renderStatus(tablewriter.NewWriter(&view), bulk)
+		d.writeFile("status.txt", view.String())
+	}
+
+	for _, component := range c.orderedComponents() {
+		for _, status := range component.Status(ctx) {
+			args := "the process is not alive\n"
+			if status.State == components.ReplicaStateRunning || status.State == components.ReplicaStateUnhealthy {
+				if argv, err := components.ReadProcessArgs(status.Pid); err != nil {
+					args = fmt.Sprintf("failed to read args: %v\n", err)
+				} else {
+					args = strings.Join(argv, "\n") + "\n"
+				}
+			}
+			d.writeFile(path.Join("args", status.Replica), args)
+		}
+
+		for _, addr := range component.ListenAddrs() {
+			if addr.Arg != "--http-addr" {
+				continue
+			}
+			d.writeFile(path.Join("health", addr.Replica), httpGet(ctx, client, addr.Addr, "/health"))
+			if component == c.cc.MetaSrv {
+				for _, endpoint := range metaSrvAdminEndpoints {
+					d.writeFile(path.Join("metasrv", addr.Replica, endpoint),
+						httpGet(ctx, client, addr.Addr, "/admin/"+endpoint))
+				}
+			}
+		}
+	}
+
+	for name, file := range componentConfigFiles(cluster.Config.Cluster) {
+		d.copyFile(path.Join("configs", name), file)
+	}
+
+	d.copyDir("logs", csd.LogsDir)
+
+	// The core dumps of crashes are not collected, since they are too large to be attached.
+	if crashes, err := components.ReadCrashes(csd.CrashesDir); err != nil {
+		d.writeFile("crashes.txt", fmt.Sprintf("failed to read crashes: %v\n", err))
+	} else if len(crashes) > 0 {
+		d.copyFile(path.Join("crashes", components.CrashIndexFileName), path.Join(csd.CrashesDir, components.CrashIndexFileName))
+		for _, crash := range crashes {
+			d.copyFile(path.Join("crashes", crash.Dir, components.CrashLogFileName),
+				path.Join(csd.CrashesDir, crash.Dir, components.CrashLogFileName))
+			if crash.Backtrace {
+				d.copyFile(path.Join("crashes", crash.Dir, components.CrashBacktraceFileName),
+					path.Join(csd.CrashesDir, crash.Dir, components.CrashBacktraceFileName))
+			}
+		}
+	}
+}
+
+// environmentInfo returns the info of the environment that the cluster runs in.
+func environmentInfo(cluster *config.BareMetalClusterMetadata) string {
+	var b strings.Builder
+
+	b.WriteString(version.Get().String())
+	fmt.Fprintf(&b, "NumCPU: %d\n", runtime.NumCPU())
+	if hostname, err := os.Hostname(); err == nil {
+		fmt.Fprintf(&b, "Hostname: %s\n", hostname)
+	}
+	if kernel, err := os.ReadFile("/proc/version"); err == nil {
+		fmt.Fprintf(&b, "Kernel: %s\n", strings.TrimSpace(string(kernel)))
+	}
+	if artifact := cluster.Config.Cluster.Artifact; artifact != nil {
+		fmt.Fprintf(&b, "GreptimeDBArtifact: version=%s local=%s\n", artifact.Version, artifact.Local)
+	}
+	if artifact := cluster.Config.Etcd.Artifact; artifact != nil {
+		fmt.Fprintf(&b, "EtcdArtifact: version=%s local=%s\n", artifact.Version, artifact.Local)
+	}
+	fmt.Fprintf(&b, "CollectionDate: %s\n", time.Now().Format(time.RFC3339))
+
+	return b.String()
+}
+
+// componentConfigFiles returns the config files of components, keyed by the names in the archive.
+func componentConfigFiles(cfg *config.BareMetalClusterComponentsConfig) map[string]string {
+	files := make(map[string]string)
+	add := func(name, common string, configs map[int]string) {
+		if len(common) > 0 {
+			files[name+path.Ext(common)] = common
+		}
+		for replica, file := range configs {
+			if len(file) > 0 {
+				files[fmt.Sprintf("%s.%d%s", name, replica, path.Ext(file))] = file
+			}
+		}
+	}
+
+	if cfg.Standalone != nil {
+		add(components.StandaloneComponentName, cfg.Standalone.Config, nil)
+		return files
+	}
+
+	add(string(greptimedbclusterv1alpha1.FrontendComponentKind), cfg.Frontend.Config, cfg.Frontend.Configs)
+	add(components.MetaSrvComponentName, cfg.MetaSrv.Config, cfg.MetaSrv.Configs)
+	for _, datanode := range datanodeConfigs(cfg) {
+		add(datanode.name, datanode.config.Config, datanode.config.Configs)
+	}
+	if cfg.Flownode != nil {
+		add(components.FlownodeComponentName, cfg.Flownode.Config, cfg.Flownode.Configs)
+	}
+
+	return files
+}
+
+// httpGet returns the status and body of response, or the error of request.
+func httpGet(ctx context.Context, client *http.Client, addr, api string) string {
+	host, err := components.HealthCheckAddr(addr, "")
+	if err != nil {
+		return fmt.Sprintf("invalid address '%s': %v\n", addr, err)
+	}
+
+	url := fmt.Sprintf("http://%s%s", host, api)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Sprintf("GET %s: %v\n", url, err)
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return fmt.Sprintf("GET %s: %v\n", url, err)
+	}
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return fmt.Sprintf("GET %s: %s\nfailed to read body: %v\n", url, rsp.Status, err)
+	}
+	return fmt.Sprintf("GET %s: %s\n\n%s\n", url, rsp.Status, body)
+}
+
+// diagnostics writes the collected files into dir, the credentials are redacted if redact is set.
+// The first error of writing is kept in err, and the following writes are skipped.
+type diagnostics struct {
+	dir    string
+	redact bool
+	err    error
+}
+
+// writeFile writes the content into the file of name.
+func (d *diagnostics) writeFile(name, content string) {
+	if d.err != nil {
+		return
+	}
+
+	if d.redact {
+		content = redact(content)
+	}
+	file := filepath.Join(d.dir, name)
+	if d.err = fileutils.EnsureDir(filepath.Dir(file)); d.err != nil {
+		return
+	}
+	d.err = os.WriteFile(file, []byte(content), 0644)
+}
+
+// copyFile copies the file of src into the file of name, the missing src is recorded in the file instead.
+func (d *diagnostics) copyFile(name, src string) {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		d.writeFile(name, fmt.Sprintf("failed to read '%s': %v\n", src, err))
+		return
+	}
+	d.writeFile(name, string(content))
+}
+
+// copyDir copies the files under the src dir into the dir of name.
+func (d *diagnostics) copyDir(name, src string) {
+	if err := filepath.WalkDir(src, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		d.copyFile(filepath.Join(name, rel), p)
+		return nil
+	}); err != nil {
+		d.writeFile(name+".txt", fmt.Sprintf("failed to collect '%s': %v\n", src, err))
+	}
+}
+
+// redact replaces the credentials in the content with a placeholder.
+func redact(content string) string {
+	content = credentialPattern.ReplaceAllString(content, "${1}<redacted>")
+	return components.Redact(content)
+}
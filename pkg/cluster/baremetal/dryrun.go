@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// recordClusterScopeDirs records the cluster scope directories and the metadata of cluster
+// in dry-run mode, which are created by the metadata manager otherwise.
+func (c *Cluster) recordClusterScopeDirs() error {
+	csd := c.mm.GetClusterScopeDirs()
+	for _, dir := range []string{csd.BaseDir, csd.LogsDir, csd.DataDir, csd.PidsDir} {
+		c.dryRun.AddDir(dir)
+	}
+
+	out, err := yaml.Marshal(config.BareMetalClusterMetadata{
+		Config:        c.config,
+		CreationDate:  time.Now(),
+		ClusterDir:    csd.BaseDir,
+		ForegroundPid: os.Getpid(),
+	})
+	if err != nil {
+		return err
+	}
+	c.dryRun.AddFile(csd.ConfigPath, string(out))
+
+	return nil
+}
+
+// renderDryRun prints the directories, files and commands that are recorded in dry-run mode.
+func (c *Cluster) renderDryRun() {
+	c.logger.V(0).Infof("\nDirectories to be created:")
+	for _, dir := range c.dryRun.Dirs {
+		c.logger.V(0).Infof("  %s", dir)
+	}
+
+	c.logger.V(0).Infof("\nFiles to be generated:")
+	for _, file := range c.dryRun.Files {
+		c.logger.V(0).Infof("  # %s\n%s", file.Path, indent(strings.TrimRight(file.Content, "\n"), "    "))
+	}
+
+	c.logger.V(0).Infof("\nCommands to be run:")
+	for _, command := range c.dryRun.Commands {
+		c.logger.V(0).Infof("  # %s\n    %s", command.Name, commandLine(command))
+	}
+}
+
+// commandLine renders the command as a shell command line, the env is prefixed in order of keys.
+// The passwords in the env and argv are redacted, since the command line is only printed.
+func commandLine(command components.DryRunCommand) string {
+	keys := make([]string, 0, len(command.Env))
+	for k := range command.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	words := make([]string, 0, len(keys)+len(command.Argv))
+	for _, k := range keys {
+		words = append(words, fmt.Sprintf("%s=%s", k, shellQuote(components.Redact(command.Env[k]))))
+	}
+	for _, arg := range command.Argv {
+		words = append(words, shellQuote(components.Redact(arg)))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes the word by single quotes if it contains any character that is special to shell.
+func shellQuote(word string) string {
+	if len(word) > 0 && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,@%+", r))
+	}) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// indent prefixes each line of s with the prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// writeEffectiveConfig writes the effective config of cluster in yaml. It's the recorded config, in which the profile,
+// the values set in command line, the offsets and the allocated ports are already resolved, so it reproduces the
+// cluster by 'gtctl cluster create --bare-metal --config'. The command lines that the replicas are started with,
+// i.e. the generated args and env, are written ahead of it as comments.
+func (c *Cluster) writeEffectiveConfig(name string, cluster *config.BareMetalClusterMetadata, w io.Writer) error {
+	fmt.Fprintf(w, "# The effective config of cluster '%s', which is created at %s.\n", name, cluster.CreationDate.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "# Reproduce the cluster by 'gtctl cluster create %s --bare-metal --config <this file>'.\n", name)
+
+	// The replicas on hosts are started by gtctl on each host, their commands are not reconstructed here.
+	if len(cluster.Config.Hosts) == 0 {
+		commands, err := c.replicaCommands(cluster)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "#\n# The replicas are started with:\n")
+		for _, command := range commands {
+			fmt.Fprintf(w, "#   %s: %s\n", command.Name, commandLine(command))
+		}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cluster.Config); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// replicaCommands reconstructs the commands of the replicas and their hooks in the order of their dependencies by
+// starting the components in dry-run mode. The binaries are named by their artifacts instead of the
+// paths in the cache, since they may not have been downloaded on this host.
+func (c *Cluster) replicaCommands(cluster *config.BareMetalClusterMetadata) ([]components.DryRunCommand, error) {
+	c.loadComponents(cluster)
+
+	csd := c.mm.GetClusterScopeDirs()
+	dryRun := &components.DryRun{}
+	c.cc = NewClusterComponents(c.config.Cluster, components.WorkingDirs{
+		DataDir: csd.DataDir,
+		LogsDir: csd.LogsDir,
+		PidsDir: csd.PidsDir,
+		DryRun:  dryRun,
+	}, &c.wg, c.logger, c.useMemoryMeta)
+
+	for _, tier := range c.dependencyTiers() {
+		for _, component := range tier {
+			binary := artifacts.GreptimeBinName
+			switch component.Name() {
+			case components.EtcdComponentName:
+				binary = artifacts.EtcdBinName
+			case components.KafkaComponentName:
+				binary = path.Join(c.config.Cluster.WAL.Kafka.Embedded.Home, "bin", "kafka-server-start.sh")
+			}
+			if err := component.Start(c.ctx, c.stop, binary); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return dryRun.Commands, nil
+}
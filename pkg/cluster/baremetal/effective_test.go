@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	mm, err := metadata.New(t.TempDir())
+	assert.NoError(t, err)
+	mm.AllocateClusterScopeDirs("test")
+
+	cluster := &config.BareMetalClusterMetadata{
+		Config:       config.DefaultBareMetalConfig(),
+		CreationDate: time.Now(),
+	}
+	cluster.Config.Cluster.PortOffset = 20000
+	assert.NoError(t, applyOffsets(cluster.Config.Cluster))
+
+	c := &Cluster{mm: mm, logger: logger.New(io.Discard, 0)}
+	var out bytes.Buffer
+	assert.NoError(t, c.writeEffectiveConfig("test", cluster, &out))
+
+	assert.Contains(t, out.String(), "#   metasrv.0: greptime ")
+	assert.Contains(t, out.String(), "#   frontend.0: greptime ")
+	assert.Contains(t, out.String(), "--http-addr=0.0.0.0:24000")
+
+	// The comments are ignored, so the output can be used as the config to reproduce the cluster.
+	var reproduced config.BareMetalClusterConfig
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &reproduced))
+	assert.Equal(t, cluster.Config, &reproduced)
+}
@@ -23,24 +23,72 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v3"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
 	cfg "github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
+// ClusterView is the cluster output in the json or yaml format by getting and listing clusters.
+type ClusterView struct {
+	Name            string          `json:"name" yaml:"name"`
+	State           string          `json:"state" yaml:"state"`
+	GreptimeVersion string          `json:"greptimeVersion" yaml:"greptimeVersion"`
+	EtcdVersion     string          `json:"etcdVersion,omitempty" yaml:"etcdVersion,omitempty"`
+	CreationDate    string          `json:"creationDate" yaml:"creationDate"`
+	ClusterDir      string          `json:"clusterDir" yaml:"clusterDir"`
+	Components      []ComponentView `json:"components" yaml:"components"`
+}
+
+// ComponentView is the replicas of one component of cluster.
+type ComponentView struct {
+	Name     string        `json:"name" yaml:"name"`
+	Replicas []ReplicaView `json:"replicas" yaml:"replicas"`
+}
+
+// ReplicaView is one replica of component, its pid is zero if it's not running.
+type ReplicaView struct {
+	Name      string            `json:"name" yaml:"name"`
+	Pid       int               `json:"pid" yaml:"pid"`
+	Restarts  int               `json:"restarts" yaml:"restarts"`
+	Endpoints map[string]string `json:"endpoints" yaml:"endpoints"`
+}
+
+// The states of cluster in ClusterView.
+const (
+	clusterStateRunning = "running"
+	clusterStateStopped = "stopped"
+)
+
 func (c *Cluster) Get(ctx context.Context, options *opt.GetOptions) error {
 	cluster, err := c.get(ctx, options)
 	if err != nil {
 		return err
 	}
+	if options.ShowConfig {
+		return c.writeEffectiveConfig(options.Name, cluster, options.Writer)
+	}
+
+	state, err := readState(c.mm.GetClusterScopeDirs().StatePath)
+	if err != nil {
+		return err
+	}
+
+	if opt.IsMachineReadable(options.Output) {
+		running, _ := c.isClusterAlive(cluster)
+		return opt.RenderOutput(options.Writer, options.Output, newClusterView(options.Name, cluster, state, running))
+	}
 
-	c.renderGetView(options.Table, cluster)
+	c.renderGetView(options.Table, cluster, state)
 
 	return nil
 }
@@ -63,11 +111,17 @@ func (c *Cluster) get(_ context.Context, options *opt.GetOptions) (*cfg.BareMeta
 		return nil, fmt.Errorf("cluster %s is not exist", options.Name)
 	}
 
-	var cluster cfg.BareMetalClusterMetadata
-	in, err := os.ReadFile(csd.ConfigPath)
+	return readMetadata(csd.ConfigPath)
+}
+
+// readMetadata reads the metadata of cluster from its config path.
+func readMetadata(configPath string) (*cfg.BareMetalClusterMetadata, error) {
+	in, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, err
 	}
+
+	var cluster cfg.BareMetalClusterMetadata
 	if err = yaml.Unmarshal(in, &cluster); err != nil {
 		return nil, err
 	}
@@ -80,10 +134,11 @@ func (c *Cluster) configGetView(table *tablewriter.Table) {
 	table.SetRowLine(true)
 }
 
-func (c *Cluster) renderGetView(table *tablewriter.Table, data *cfg.BareMetalClusterMetadata) {
+func (c *Cluster) renderGetView(table *tablewriter.Table, data *cfg.BareMetalClusterMetadata,
+	state *cfg.BareMetalClusterState) {
 	c.configGetView(table)
 
-	headers, footers, bulk := collectClusterInfoFromBareMetal(data)
+	headers, footers, bulk := collectClusterInfoFromBareMetal(data, state)
 	table.SetHeader(headers)
 	table.AppendBulk(bulk)
 	table.Render()
@@ -93,12 +148,16 @@ func (c *Cluster) renderGetView(table *tablewriter.Table, data *cfg.BareMetalClu
 	}
 }
 
-func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
+// collectClusterInfoFromBareMetal collects the replicas of cluster from its recorded state, or from
+// its config if the state is not recorded. The pids are always collected from the pid files, since
+// the replicas may have been restarted since the state is recorded.
+func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata, state *cfg.BareMetalClusterState) (
 	headers, footers []string, bulk [][]string) {
-	headers = []string{"COMPONENT", "PID"}
+	headers = []string{"COMPONENT", "PID", "RESTARTS", "ENDPOINTS"}
 
 	pidsDir := path.Join(data.ClusterDir, metadata.ClusterPidsDir)
 	pidsMap := collectPidsForBareMetal(pidsDir)
+	endpointsMap := collectEndpointsForBareMetal(data.Config.Cluster)
 
 	var (
 		date = data.CreationDate.String()
@@ -109,16 +168,46 @@ func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
 				if val, ok := pidsMap[key]; ok {
 					pid = fmt.Sprintf(".%d: %s", i, val)
 				}
-				bulk = append(bulk, []string{name, pid})
+				bulk = append(bulk, []string{name, pid, collectRestartsForBareMetal(pidsDir, key), endpointsMap[key]})
 			}
 		}
 	)
 
-	rows(string(greptimedbclusterv1alpha1.FrontendComponentKind), data.Config.Cluster.Frontend.Replicas)
-	rows(string(greptimedbclusterv1alpha1.DatanodeComponentKind), data.Config.Cluster.Datanode.Replicas)
-	rows(string(greptimedbclusterv1alpha1.MetaComponentKind), data.Config.Cluster.MetaSrv.Replicas)
+	if state != nil {
+		for _, component := range state.Components {
+			for i, replica := range component.Replicas {
+				pid := "N/A"
+				if val, ok := pidsMap[replica.Name]; ok {
+					pid = fmt.Sprintf(".%d: %s", i, val)
+				}
+				bulk = append(bulk, []string{component.Name, pid,
+					collectRestartsForBareMetal(pidsDir, replica.Name), stateEndpoints(replica.Addrs)})
+			}
+		}
+	} else if data.Config.Cluster.Standalone != nil {
+		rows(components.StandaloneComponentName, 1)
+	} else {
+		rows(string(greptimedbclusterv1alpha1.FrontendComponentKind), data.Config.Cluster.Frontend.Replicas)
+		rows(string(greptimedbclusterv1alpha1.DatanodeComponentKind), data.Config.Cluster.Datanode.Replicas)
+		for _, group := range data.Config.Cluster.DatanodeGroups {
+			rows(components.DatanodeGroupName(group.Name), group.Replicas)
+		}
+		rows(string(greptimedbclusterv1alpha1.MetaComponentKind), data.Config.Cluster.MetaSrv.Replicas)
+		if data.Config.Cluster.Flownode != nil {
+			rows(components.FlownodeComponentName, data.Config.Cluster.Flownode.Replicas)
+		}
 
-	bulk = append(bulk, []string{"etcd", pidsMap["etcd"]})
+		if wal := data.Config.Cluster.WAL; wal != nil && wal.Kafka != nil && wal.Kafka.Embedded != nil {
+			bulk = append(bulk, []string{components.KafkaComponentName, pidsMap[components.KafkaComponentName],
+				collectRestartsForBareMetal(pidsDir, components.KafkaComponentName),
+				components.EmbeddedKafkaAddr(wal.Kafka.Embedded)})
+		}
+
+		if data.Config.Cluster.MetaSrv.Backend == cfg.MetaSrvBackendEmbeddedEtcd {
+			bulk = append(bulk, []string{components.EtcdComponentName, pidsMap[components.EtcdComponentName],
+				collectRestartsForBareMetal(pidsDir, components.EtcdComponentName), data.Config.Cluster.MetaSrv.StoreAddr})
+		}
+	}
 
 	config, err := yaml.Marshal(data.Config)
 	footers = []string{
@@ -136,11 +225,93 @@ func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
 	return headers, footers, bulk
 }
 
+// newClusterView collects the replicas of cluster from its recorded state, or from its config if the state
+// is not recorded, the same as collectClusterInfoFromBareMetal.
+func newClusterView(name string, data *cfg.BareMetalClusterMetadata, state *cfg.BareMetalClusterState, running bool) *ClusterView {
+	view := &ClusterView{
+		Name:         name,
+		State:        clusterStateStopped,
+		CreationDate: data.CreationDate.String(),
+		ClusterDir:   data.ClusterDir,
+	}
+	if running {
+		view.State = clusterStateRunning
+	}
+	if data.Config.Cluster.Artifact != nil {
+		view.GreptimeVersion = data.Config.Cluster.Artifact.Version
+	}
+	if data.Config.Etcd != nil && data.Config.Etcd.Artifact != nil {
+		view.EtcdVersion = data.Config.Etcd.Artifact.Version
+	}
+
+	pidsDir := path.Join(data.ClusterDir, metadata.ClusterPidsDir)
+	pidsMap := collectPidsForBareMetal(pidsDir)
+	replicaView := func(replica string, endpoints map[string]string) ReplicaView {
+		pid, _ := strconv.Atoi(strings.TrimSpace(pidsMap[replica]))
+		restarts, _ := strconv.Atoi(strings.TrimSpace(collectRestartsForBareMetal(pidsDir, replica)))
+		if endpoints == nil {
+			endpoints = make(map[string]string)
+		}
+		return ReplicaView{Name: replica, Pid: pid, Restarts: restarts, Endpoints: endpoints}
+	}
+
+	if state != nil {
+		for _, component := range state.Components {
+			componentView := ComponentView{Name: component.Name, Replicas: []ReplicaView{}}
+			for _, replica := range component.Replicas {
+				componentView.Replicas = append(componentView.Replicas, replicaView(replica.Name, replica.Addrs))
+			}
+			view.Components = append(view.Components, componentView)
+		}
+		return view
+	}
+
+	// The components are only used for resolving their replicas and addresses.
+	cc := NewClusterComponents(data.Config.Cluster, components.WorkingDirs{}, nil, nil, false)
+	ccs := append([]components.ClusterComponent{cc.Standalone, cc.MetaSrv}, cc.datanodes()...)
+	ccs = append(ccs, cc.Flownode, cc.Frontend, cc.Kafka)
+	if cc.Standalone == nil && data.Config.Cluster.MetaSrv.Backend == cfg.MetaSrvBackendEmbeddedEtcd {
+		ccs = append(ccs, cc.Etcd)
+	}
+	for _, component := range ccs {
+		if component == nil {
+			continue
+		}
+
+		var (
+			replicas  []string
+			endpoints = make(map[string]map[string]string)
+		)
+		for _, addr := range component.ListenAddrs() {
+			if endpoints[addr.Replica] == nil {
+				replicas = append(replicas, addr.Replica)
+				endpoints[addr.Replica] = make(map[string]string)
+			}
+			endpoints[addr.Replica][addr.Arg] = addr.Addr
+		}
+
+		componentView := ComponentView{Name: component.Name(), Replicas: []ReplicaView{}}
+		for _, replica := range replicas {
+			componentView.Replicas = append(componentView.Replicas, replicaView(replica, endpoints[replica]))
+		}
+		view.Components = append(view.Components, componentView)
+	}
+
+	return view
+}
+
 // collectPidsForBareMetal returns the pid of each component.
 func collectPidsForBareMetal(pidsDir string) map[string]string {
 	ret := make(map[string]string)
 
 	if err := filepath.WalkDir(pidsDir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			// No component has been started, or the dir has been removed while walking.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 		if d.IsDir() {
 			if d.Name() == metadata.ClusterPidsDir {
 				return nil
@@ -148,6 +319,10 @@ func collectPidsForBareMetal(pidsDir string) map[string]string {
 
 			pidPath := filepath.Join(path, "pid")
 			pid, err := os.ReadFile(pidPath)
+			if os.IsNotExist(err) {
+				// The component has been stopped.
+				return nil
+			}
 			if err != nil {
 				return err
 			}
@@ -161,3 +336,45 @@ func collectPidsForBareMetal(pidsDir string) map[string]string {
 
 	return ret
 }
+
+// collectEndpointsForBareMetal returns the listen addresses of each replica.
+func collectEndpointsForBareMetal(config *cfg.BareMetalClusterComponentsConfig) map[string]string {
+	ret := make(map[string]string)
+
+	// The components are only used for resolving their addresses.
+	cc := NewClusterComponents(config, components.WorkingDirs{}, nil, nil, false)
+	ccs := append([]components.ClusterComponent{cc.Standalone, cc.Frontend, cc.MetaSrv, cc.Flownode}, cc.datanodes()...)
+	for _, component := range ccs {
+		if component == nil {
+			continue
+		}
+		for _, addr := range component.ListenAddrs() {
+			endpoint := fmt.Sprintf("%s=%s", addr.Arg, addr.Addr)
+			if len(ret[addr.Replica]) > 0 {
+				endpoint = "\n" + endpoint
+			}
+			ret[addr.Replica] += endpoint
+		}
+	}
+
+	return ret
+}
+
+// stateEndpoints returns the listen addresses of replica recorded in the state, sorted by args.
+func stateEndpoints(addrs map[string]string) string {
+	endpoints := make([]string, 0, len(addrs))
+	for arg, addr := range addrs {
+		endpoints = append(endpoints, fmt.Sprintf("%s=%s", arg, addr))
+	}
+	sort.Strings(endpoints)
+	return strings.Join(endpoints, "\n")
+}
+
+// collectRestartsForBareMetal returns the restart count of the replica of component.
+func collectRestartsForBareMetal(pidsDir, replica string) string {
+	restarts, err := os.ReadFile(filepath.Join(pidsDir, replica, components.RestartsFileName))
+	if err != nil {
+		return "N/A"
+	}
+	return string(restarts)
+}
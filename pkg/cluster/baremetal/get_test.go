@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/GreptimeTeam/gtctl/pkg/config"
 )
 
 func TestCollectPidsForBareMetal(t *testing.T) {
@@ -35,3 +37,89 @@ func TestCollectPidsForBareMetal(t *testing.T) {
 
 	assert.Equal(t, want, ret)
 }
+
+func TestCollectRestartsForBareMetal(t *testing.T) {
+	pidsPath := filepath.Join("testdata", "pids")
+
+	assert.Equal(t, "2", collectRestartsForBareMetal(pidsPath, "a"))
+	assert.Equal(t, "N/A", collectRestartsForBareMetal(pidsPath, "b"))
+}
+
+func TestCollectClusterInfoFromState(t *testing.T) {
+	data := &cfg.BareMetalClusterMetadata{
+		Config:     cfg.DefaultBareMetalConfig(),
+		ClusterDir: "testdata",
+	}
+	state := &cfg.BareMetalClusterState{
+		Components: []*cfg.ComponentState{{
+			Name: "a",
+			Replicas: []*cfg.ReplicaState{{
+				Name: "a",
+				Addrs: map[string]string{
+					"--rpc-addr":  "127.0.0.1:4001",
+					"--http-addr": "127.0.0.1:4000",
+				},
+			}},
+		}, {
+			Name:     "d",
+			Replicas: []*cfg.ReplicaState{{Name: "d.0"}},
+		}},
+	}
+
+	_, _, bulk := collectClusterInfoFromBareMetal(data, state)
+	assert.Equal(t, [][]string{
+		{"a", ".0: 123", "2", "--http-addr=127.0.0.1:4000\n--rpc-addr=127.0.0.1:4001"},
+		{"d", "N/A", "N/A", ""},
+	}, bulk)
+}
+
+func TestNewClusterViewFromState(t *testing.T) {
+	data := &cfg.BareMetalClusterMetadata{
+		Config:     cfg.DefaultBareMetalConfig(),
+		ClusterDir: "testdata",
+	}
+	state := &cfg.BareMetalClusterState{
+		Components: []*cfg.ComponentState{{
+			Name: "a",
+			Replicas: []*cfg.ReplicaState{{
+				Name:  "a",
+				Addrs: map[string]string{"--http-addr": "127.0.0.1:4000"},
+			}},
+		}, {
+			Name:     "d",
+			Replicas: []*cfg.ReplicaState{{Name: "d.0"}},
+		}},
+	}
+
+	view := newClusterView("mycluster", data, state, true)
+	assert.Equal(t, "mycluster", view.Name)
+	assert.Equal(t, clusterStateRunning, view.State)
+	assert.Equal(t, data.Config.Cluster.Artifact.Version, view.GreptimeVersion)
+	assert.Equal(t, []ComponentView{{
+		Name:     "a",
+		Replicas: []ReplicaView{{Name: "a", Pid: 123, Restarts: 2, Endpoints: map[string]string{"--http-addr": "127.0.0.1:4000"}}},
+	}, {
+		Name:     "d",
+		Replicas: []ReplicaView{{Name: "d.0", Endpoints: map[string]string{}}},
+	}}, view.Components)
+
+	// The replicas are resolved from the config without the state.
+	view = newClusterView("mycluster", data, nil, false)
+	assert.Equal(t, clusterStateStopped, view.State)
+	var names []string
+	for _, component := range view.Components {
+		names = append(names, component.Name)
+	}
+	assert.Contains(t, names, "frontend")
+	assert.Contains(t, names, "datanode")
+}
+
+func TestStateComponents(t *testing.T) {
+	assert.Equal(t, "N/A", stateComponents(nil))
+	assert.Equal(t, "frontend=1,datanode=3", stateComponents(&cfg.BareMetalClusterState{
+		Components: []*cfg.ComponentState{
+			{Name: "frontend", Replicas: make([]*cfg.ReplicaState, 1)},
+			{Name: "datanode", Replicas: make([]*cfg.ReplicaState, 3)},
+		},
+	}))
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// serverAddrArg is the arg of the address that metasrv registers itself with, which is
+// recorded in the ReplicaAddrs of metasrv for the replicas placed on the hosts.
+const serverAddrArg = "--server-addr"
+
+// placement is how the replicas of one component are placed on the hosts in multi-host mode.
+type placement struct {
+	name     string
+	replicas int
+
+	// hosts are the names of hosts that the replicas are placed on in turn, all the hosts are used if it's empty.
+	hosts        []string
+	replicaAddrs *config.ReplicaAddrs
+}
+
+// placements returns the placements of the distributed components.
+func placements(cfg *config.BareMetalClusterComponentsConfig) []placement {
+	placements := []placement{
+		{components.MetaSrvComponentName, cfg.MetaSrv.Replicas, cfg.MetaSrv.Hosts, &cfg.MetaSrv.ReplicaAddrs},
+		{string(greptimedbclusterv1alpha1.DatanodeComponentKind), cfg.Datanode.Replicas, cfg.Datanode.Hosts, &cfg.Datanode.ReplicaAddrs},
+	}
+	for _, group := range cfg.DatanodeGroups {
+		placements = append(placements, placement{components.DatanodeGroupName(group.Name), group.Replicas, group.Hosts, &group.ReplicaAddrs})
+	}
+	if flownode := cfg.Flownode; flownode != nil {
+		placements = append(placements, placement{components.FlownodeComponentName, flownode.Replicas, flownode.Hosts, &flownode.ReplicaAddrs})
+	}
+	return append(placements, placement{string(greptimedbclusterv1alpha1.FrontendComponentKind),
+		cfg.Frontend.Replicas, cfg.Frontend.Hosts, &cfg.Frontend.ReplicaAddrs})
+}
+
+// replicaHosts returns the hosts that the replicas are placed on, keyed by the name of replica, e.g. "frontend.0".
+// The replicas of each component are placed on its hosts in turn, and the embedded etcd is placed with the first
+// replica of metasrv. It's empty if the cluster is not deployed on multiple hosts.
+func replicaHosts(cfg *config.BareMetalClusterConfig) map[string]*config.Host {
+	replicaHosts := make(map[string]*config.Host)
+	if len(cfg.Hosts) == 0 {
+		return replicaHosts
+	}
+
+	hosts := make(map[string]*config.Host, len(cfg.Hosts))
+	var all []string
+	for _, host := range cfg.Hosts {
+		hosts[host.Name] = host
+		all = append(all, host.Name)
+	}
+
+	for _, p := range placements(cfg.Cluster) {
+		names := p.hosts
+		if len(names) == 0 {
+			names = all
+		}
+		for i := 0; i < p.replicas; i++ {
+			replicaHosts[replicaName(p.name, i)] = hosts[names[i%len(names)]]
+		}
+	}
+	replicaHosts[components.EtcdComponentName] = replicaHosts[replicaName(components.MetaSrvComponentName, 0)]
+
+	return replicaHosts
+}
+
+// applyPlacement records the listen addresses of the replicas placed on the hosts in the ReplicaAddrs of
+// components, whose loopback and unspecified hosts are replaced by the address of the host, so the replicas
+// are reachable by the other hosts. The metasrv registers itself with its bind address, and the embedded
+// etcd listens on the host of the first metasrv.
+func applyPlacement(cfg *config.BareMetalClusterConfig) error {
+	hosts := replicaHosts(cfg)
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	cc := NewClusterComponents(cfg.Cluster, components.WorkingDirs{}, nil, nil, false)
+	listenAddrs := make(map[string][]components.ListenAddr)
+	for _, component := range append([]components.ClusterComponent{cc.MetaSrv, cc.Flownode, cc.Frontend}, cc.datanodes()...) {
+		if component != nil {
+			listenAddrs[component.Name()] = component.ListenAddrs()
+		}
+	}
+
+	for _, p := range placements(cfg.Cluster) {
+		if *p.replicaAddrs == nil {
+			*p.replicaAddrs = make(config.ReplicaAddrs)
+		}
+		for _, addr := range listenAddrs[p.name] {
+			placed, err := placedAddr(addr.Addr, hosts[addr.Replica])
+			if err != nil {
+				return err
+			}
+
+			replica, err := replicaIndex(addr.Replica)
+			if err != nil {
+				return err
+			}
+			if (*p.replicaAddrs)[replica] == nil {
+				(*p.replicaAddrs)[replica] = make(map[string]string)
+			}
+			(*p.replicaAddrs)[replica][addr.Arg] = placed
+			if p.name == components.MetaSrvComponentName && addr.Arg == "--bind-addr" {
+				(*p.replicaAddrs)[replica][serverAddrArg] = placed
+			}
+		}
+	}
+
+	if cfg.Cluster.MetaSrv.Backend == config.MetaSrvBackendEmbeddedEtcd {
+		storeAddr, err := placedAddr(cfg.Cluster.MetaSrv.StoreAddr, hosts[components.EtcdComponentName])
+		if err != nil {
+			return err
+		}
+		cfg.Cluster.MetaSrv.StoreAddr = storeAddr
+	}
+
+	return nil
+}
+
+// metaSrvAddrs returns the addresses that the other components connect to metasrv with, which are the
+// server addresses of the replicas placed on the hosts, or the server address of metasrv on the local host.
+func metaSrvAddrs(cfg *config.BareMetalClusterComponentsConfig) string {
+	var addrs []string
+	for i := 0; i < cfg.MetaSrv.Replicas; i++ {
+		if addr := cfg.MetaSrv.ReplicaAddrs[i][serverAddrArg]; len(addr) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return cfg.MetaSrv.ServerAddr
+	}
+	return strings.Join(addrs, ",")
+}
+
+// placedAddr replaces the loopback and unspecified host of addr by the address of host.
+func placedAddr(addr string, host *config.Host) (string, error) {
+	if host == nil {
+		return addr, nil
+	}
+
+	h, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(h); len(h) == 0 || h == "localhost" || ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+		h = host.Address
+	}
+	return net.JoinHostPort(h, port), nil
+}
+
+func replicaName(component string, replica int) string {
+	return fmt.Sprintf("%s.%d", component, replica)
+}
+
+// replicaIndex returns the index of replica by its name, e.g. 0 for "frontend.0".
+func replicaIndex(replica string) (int, error) {
+	i := strings.LastIndex(replica, ".")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid name of replica '%s'", replica)
+	}
+	return strconv.Atoi(replica[i+1:])
+}
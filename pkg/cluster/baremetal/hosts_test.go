@@ -0,0 +1,95 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestReplicaHosts(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	assert.Empty(t, replicaHosts(cfg))
+
+	cfg.Hosts = []*config.Host{{Name: "node1", Address: "10.0.0.1"}, {Name: "node2", Address: "10.0.0.2"}}
+	cfg.Cluster.Frontend.Hosts = []string{"node2"}
+
+	hosts := replicaHosts(cfg)
+	names := make(map[string]string)
+	for replica, host := range hosts {
+		names[replica] = host.Name
+	}
+	assert.Equal(t, map[string]string{
+		"metasrv.0":  "node1",
+		"etcd":       "node1",
+		"datanode.0": "node1",
+		"datanode.1": "node2",
+		"datanode.2": "node1",
+		"frontend.0": "node2",
+	}, names)
+}
+
+func TestApplyPlacement(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Hosts = []*config.Host{{Name: "node1", Address: "10.0.0.1"}, {Name: "node2", Address: "10.0.0.2"}}
+	cfg.Cluster.MetaSrv.Replicas = 2
+	cfg.Cluster.Datanode.Replicas = 2
+
+	assert.NoError(t, applyPlacement(cfg))
+
+	assert.Equal(t, "10.0.0.1:2379", cfg.Cluster.MetaSrv.StoreAddr)
+	assert.Equal(t, "10.0.0.2:14301", cfg.Cluster.Datanode.ReplicaAddrs[1]["--http-addr"])
+	assert.Equal(t, "10.0.0.1:14001", cfg.Cluster.MetaSrv.ReplicaAddrs[0]["--http-addr"])
+	assert.Equal(t, cfg.Cluster.MetaSrv.ReplicaAddrs[1]["--bind-addr"], cfg.Cluster.MetaSrv.ReplicaAddrs[1][serverAddrArg])
+	assert.Equal(t, cfg.Cluster.MetaSrv.ReplicaAddrs[0][serverAddrArg]+","+cfg.Cluster.MetaSrv.ReplicaAddrs[1][serverAddrArg],
+		metaSrvAddrs(cfg.Cluster))
+}
+
+func TestMetaSrvAddrs(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig().Cluster
+	assert.Equal(t, cfg.MetaSrv.ServerAddr, metaSrvAddrs(cfg))
+}
+
+func TestPlacedAddr(t *testing.T) {
+	host := &config.Host{Name: "node1", Address: "10.0.0.1"}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"0.0.0.0:4000", "10.0.0.1:4000"},
+		{"127.0.0.1:4000", "10.0.0.1:4000"},
+		{"localhost:4000", "10.0.0.1:4000"},
+		{":4000", "10.0.0.1:4000"},
+		{"192.168.0.1:4000", "192.168.0.1:4000"},
+	}
+	for _, tt := range tests {
+		addr, err := placedAddr(tt.addr, host)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, addr)
+	}
+
+	addr, err := placedAddr("127.0.0.1:4000", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:4000", addr)
+
+	_, err = placedAddr("invalid", host)
+	assert.Error(t, err)
+}
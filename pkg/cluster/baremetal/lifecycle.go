@@ -0,0 +1,212 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+// foregroundCheckInterval is the interval of checking whether the foreground gtctl process has exited.
+const foregroundCheckInterval = 100 * time.Millisecond
+
+// Start starts the stopped cluster with the config and the data that it was created with.
+func (c *Cluster) Start(ctx context.Context, options *opt.StartOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if running {
+		return fmt.Errorf("cluster '%s' is already running", options.Name)
+	}
+
+	c.loadComponents(cluster)
+
+	return c.Create(ctx, &opt.CreateOptions{
+		Name:    options.Name,
+		Cluster: &opt.CreateClusterOptions{UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts},
+		Etcd:    &opt.CreateEtcdOptions{UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts},
+		Spinner: options.Spinner,
+	})
+}
+
+// Stop stops the running cluster gracefully. The cluster that runs in foreground is stopped by
+// its gtctl process, and the detached cluster is stopped by the pids recorded in the pids dir.
+func (c *Cluster) Stop(ctx context.Context, options *opt.StopOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if !running {
+		return fmt.Errorf("cluster '%s' is not running", options.Name)
+	}
+
+	foreground := c.isForegroundRunning(cluster)
+
+	c.logger.V(0).Infof("Stopping cluster '%s'...", options.Name)
+	if foreground {
+		if err = c.stopForeground(ctx, cluster.ForegroundPid); err != nil {
+			return err
+		}
+	}
+
+	// The components have been stopped by the foreground process unless it was killed before stopping them,
+	// e.g. it can't be asked to exit gracefully from another console on Windows, so they are stopped anyway.
+	c.loadComponents(cluster)
+	if err = c.stopComponents(ctx); err != nil {
+		return err
+	}
+	c.logger.V(0).Infof("Cluster '%s' is stopped!", options.Name)
+
+	return nil
+}
+
+// stopForeground asks the foreground gtctl process of cluster to exit, which stops
+// the components gracefully before exiting, and waits for it to exit.
+func (c *Cluster) stopForeground(ctx context.Context, pid int) error {
+	if err := components.TerminateProcess(pid); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(foregroundCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !c.isClusterRunning(pid) {
+				return nil
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("gtctl process (pid '%d') of cluster did not exit: %v", pid, ctx.Err())
+		}
+	}
+}
+
+// recordForeground records current gtctl process as the foreground process of cluster, and how
+// the cluster runs, so that the cluster can be started again in the same way.
+func (c *Cluster) recordForeground(ctx context.Context) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	cluster.ForegroundPid = os.Getpid()
+	cluster.Detached = c.detach
+	cluster.UseMemoryMeta = c.useMemoryMeta
+
+	return c.mm.UpdateClusterMetadata(cluster)
+}
+
+// detachForeground clears the foreground process of cluster, since current gtctl process
+// exits and leaves the components running in background.
+func (c *Cluster) detachForeground(ctx context.Context) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	cluster.ForegroundPid = 0
+	if err = c.mm.UpdateClusterMetadata(cluster); err != nil {
+		return err
+	}
+	c.recordState(ctx)
+
+	return nil
+}
+
+// checkDetachable returns an error if the cluster relies on gtctl to supervise its components, i.e. restarting
+// the exited replicas and rotating the logs, which stops once the gtctl process of detached cluster exits.
+// The services exported by 'gtctl cluster export systemd' can be used for the long-lived supervision instead.
+func checkDetachable(cfg *config.BareMetalClusterComponentsConfig) error {
+	if cfg.LogRotation != nil {
+		return fmt.Errorf("the log rotation can't work in detached mode, the logs are not rotated after gtctl exits")
+	}
+
+	_, restarts := componentSettings(cfg)
+	names := make([]string, 0, len(restarts))
+	for name := range restarts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if restart := restarts[name]; restart != nil && len(restart.Policy) > 0 && restart.Policy != config.RestartPolicyNever {
+			return fmt.Errorf("the restart policy '%s' of '%s' can't work in detached mode, "+
+				"the replicas are not restarted after gtctl exits", restart.Policy, name)
+		}
+	}
+	return nil
+}
+
+// isClusterAlive checks whether the cluster is running. The cluster is running if its foreground
+// gtctl process is alive, or if any of its recorded processes is alive when it's detached.
+// The recorded pids are checked against the start time of processes, since they may be reused.
+func (c *Cluster) isClusterAlive(cluster *config.BareMetalClusterMetadata) (bool, error) {
+	if c.isForegroundRunning(cluster) {
+		return true, nil
+	}
+	if cluster.Config != nil && len(cluster.Config.Hosts) > 0 {
+		return c.isRemoteAlive(cluster)
+	}
+	if !cluster.Detached {
+		return false, nil
+	}
+
+	pidsDir := filepath.Join(cluster.ClusterDir, metadata.ClusterPidsDir)
+	for replica := range collectPidsForBareMetal(pidsDir) {
+		if components.IsRecordedProcessRunning(filepath.Join(pidsDir, replica)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isForegroundRunning checks whether the foreground gtctl process of cluster is running. The process records
+// itself in the metadata of cluster after it starts, so the process started after the metadata was last
+// updated is another one reusing the pid, e.g. after a reboot.
+func (c *Cluster) isForegroundRunning(cluster *config.BareMetalClusterMetadata) bool {
+	if cluster.ForegroundPid <= 0 {
+		return false
+	}
+
+	metadataFile := filepath.Join(cluster.ClusterDir, fmt.Sprintf("%s.yaml", filepath.Base(cluster.ClusterDir)))
+	info, err := os.Stat(metadataFile)
+	if err != nil {
+		return c.isClusterRunning(cluster.ForegroundPid)
+	}
+	return components.IsProcessRunningSince(cluster.ForegroundPid, info.ModTime())
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestCheckDetachable(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig().Cluster
+	assert.NoError(t, checkDetachable(cfg))
+
+	cfg.Frontend.Restart = &config.Restart{Policy: config.RestartPolicyNever}
+	assert.NoError(t, checkDetachable(cfg))
+
+	cfg.Frontend.Restart.Policy = config.RestartPolicyOnFailure
+	assert.ErrorContains(t, checkDetachable(cfg), "restart policy 'on-failure' of 'frontend'")
+
+	cfg.Frontend.Restart = nil
+	cfg.LogRotation = &config.LogRotation{MaxSizeMB: 100}
+	assert.ErrorContains(t, checkDetachable(cfg), "log rotation")
+}
+
+func TestIsClusterAlive(t *testing.T) {
+	c := &Cluster{}
+	clusterDir := t.TempDir()
+	cluster := &config.BareMetalClusterMetadata{ClusterDir: clusterDir}
+
+	running, err := c.isClusterAlive(cluster)
+	assert.NoError(t, err)
+	assert.False(t, running)
+
+	cluster.ForegroundPid = os.Getpid()
+	running, err = c.isClusterAlive(cluster)
+	assert.NoError(t, err)
+	assert.True(t, running)
+
+	// The detached cluster is running if any of its recorded processes is alive.
+	cluster.ForegroundPid = 0
+	cluster.Detached = true
+	running, err = c.isClusterAlive(cluster)
+	assert.NoError(t, err)
+	assert.False(t, running)
+
+	pidDir := filepath.Join(clusterDir, metadata.ClusterPidsDir, "frontend.0")
+	assert.NoError(t, os.MkdirAll(pidDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "pid"), []byte(strconv.Itoa(os.Getpid())), 0644))
+	running, err = c.isClusterAlive(cluster)
+	assert.NoError(t, err)
+	assert.True(t, running)
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	cfg "github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+// List lists all the clusters under the working directory of gtctl, the components
+// of each cluster are collected from its recorded state.
+func (c *Cluster) List(_ context.Context, options *opt.ListOptions) error {
+	names, err := c.mm.ListClusters()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("clusters not found")
+	}
+
+	clusters := c.collectClusterList(names)
+	if opt.IsMachineReadable(options.Output) {
+		views := make([]*ClusterView, 0, len(clusters))
+		for _, cluster := range clusters {
+			views = append(views, newClusterView(cluster.name, cluster.metadata, cluster.state, cluster.running))
+		}
+		return opt.RenderOutput(options.Writer, options.Output, views)
+	}
+
+	var bulk [][]string
+	for _, cluster := range clusters {
+		status := clusterStateStopped
+		if cluster.running {
+			status = clusterStateRunning
+		}
+		bulk = append(bulk, []string{cluster.name, status, stateComponents(cluster.state),
+			cluster.metadata.CreationDate.String(), cluster.metadata.ClusterDir})
+	}
+	c.renderListView(options.Table, bulk)
+
+	return nil
+}
+
+var _ opt.Summarizer = &Cluster{}
+
+// Summarize summarizes the clusters under the working directory of gtctl, the namespace is ignored
+// since the clusters on bare-metal environment have no namespaces.
+func (c *Cluster) Summarize(_ context.Context, _ string) ([]*opt.ClusterSummary, error) {
+	names, err := c.mm.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := c.collectClusterList(names)
+	summaries := make([]*opt.ClusterSummary, 0, len(clusters))
+	for _, cluster := range clusters {
+		status := clusterStateStopped
+		if cluster.running {
+			status = clusterStateRunning
+		}
+		summary := &opt.ClusterSummary{
+			Name:              cluster.name,
+			Mode:              opt.ModeBareMetal,
+			Status:            status,
+			CreationTimestamp: cluster.metadata.CreationDate,
+		}
+		if cluster.metadata.Config != nil && cluster.metadata.Config.Cluster != nil && cluster.metadata.Config.Cluster.Artifact != nil {
+			summary.Version = cluster.metadata.Config.Cluster.Artifact.Version
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// listedCluster is one of the clusters under the working directory of gtctl.
+type listedCluster struct {
+	name     string
+	metadata *cfg.BareMetalClusterMetadata
+	state    *cfg.BareMetalClusterState
+	running  bool
+}
+
+// collectClusterList returns the clusters of names, the cluster whose metadata can't be read is skipped.
+func (c *Cluster) collectClusterList(names []string) []*listedCluster {
+	var clusters []*listedCluster
+	for _, name := range names {
+		clusterDir := filepath.Join(c.mm.GetWorkingDir(), name)
+		cluster, err := readMetadata(filepath.Join(clusterDir, fmt.Sprintf("%s.yaml", name)))
+		if err != nil {
+			c.logger.Warnf("failed to read the metadata of cluster '%s': %v", name, err)
+			continue
+		}
+		state, err := readState(filepath.Join(clusterDir, metadata.ClusterStateFileName))
+		if err != nil {
+			c.logger.Warnf("failed to read the state of cluster '%s': %v", name, err)
+		}
+
+		running, _ := c.isClusterAlive(cluster)
+		clusters = append(clusters, &listedCluster{name: name, metadata: cluster, state: state, running: running})
+	}
+	return clusters
+}
+
+// ListClusterNames lists the names of the clusters under the working directory of gtctl in homeDir,
+// the home directory of current user is used if homeDir is empty.
+func ListClusterNames(homeDir string) ([]string, error) {
+	mm, err := metadata.New(homeDir)
+	if err != nil {
+		return nil, err
+	}
+	return mm.ListClusters()
+}
+
+// ClusterComponentNames returns the names of the components of the cluster, e.g. 'frontend' and 'datanode-hot',
+// which are collected from its recorded state, or its config if it has never been started. The metasrv is named
+// by its kind 'meta' as the component flags accept.
+func ClusterComponentNames(homeDir, name string) ([]string, error) {
+	mm, err := metadata.New(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterDir := filepath.Join(mm.GetWorkingDir(), name)
+	cluster, err := readMetadata(filepath.Join(clusterDir, fmt.Sprintf("%s.yaml", name)))
+	if err != nil {
+		return nil, err
+	}
+	state, err := readState(filepath.Join(clusterDir, metadata.ClusterStateFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	view := newClusterView(name, cluster, state, false)
+	names := make([]string, 0, len(view.Components))
+	for _, component := range view.Components {
+		if component.Name == components.MetaSrvComponentName {
+			names = append(names, string(greptimedbclusterv1alpha1.MetaComponentKind))
+			continue
+		}
+		names = append(names, component.Name)
+	}
+	return names, nil
+}
+
+// stateComponents returns the replicas of each component recorded in the state, e.g. "frontend=1,datanode=3".
+func stateComponents(state *cfg.BareMetalClusterState) string {
+	if state == nil {
+		return "N/A"
+	}
+
+	replicas := make([]string, 0, len(state.Components))
+	for _, component := range state.Components {
+		replicas = append(replicas, fmt.Sprintf("%s=%d", component.Name, len(component.Replicas)))
+	}
+	return strings.Join(replicas, ",")
+}
+
+func (c *Cluster) configListView(table *tablewriter.Table) {
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+}
+
+func (c *Cluster) renderListView(table *tablewriter.Table, bulk [][]string) {
+	c.configListView(table)
+
+	table.SetHeader([]string{"Name", "State", "Components", "Creation Date", "Cluster Dir"})
+	table.AppendBulk(bulk)
+	table.Render()
+}
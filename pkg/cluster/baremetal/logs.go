@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+// Logs prints the logs of the replicas of cluster with the colored prefixes of replicas, the lines
+// are filtered by the patterns, level and time range, and merged in the order of timestamps.
+func (c *Cluster) Logs(ctx context.Context, options *opt.LogsOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "logs"); err != nil {
+		return err
+	}
+
+	filter, err := components.NewLogFilter(options.Patterns, options.Level, options.Since, options.Until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	csd := c.mm.GetClusterScopeDirs()
+	return components.FollowLogs(ctx, options.Writer, csd.LogsDir, matchReplica(options.Component),
+		options.Tail, options.Follow, filter, options.JSON)
+}
+
+// matchReplica returns the matcher of the replicas of component, it matches all the replicas if
+// the component is empty. The component can be either the kind of component or its name.
+func matchReplica(component string) func(replica string) bool {
+	if len(component) == 0 {
+		return func(string) bool { return true }
+	}
+
+	name := component
+	if component == string(greptimedbclusterv1alpha1.MetaComponentKind) {
+		name = components.MetaSrvComponentName
+	}
+	return func(replica string) bool {
+		return replica == name || strings.HasPrefix(replica, name+".")
+	}
+}
@@ -0,0 +1,428 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+const (
+	// DefaultMetricsAddr is the default local address that the aggregated metrics of cluster are served on.
+	DefaultMetricsAddr = "127.0.0.1:9099"
+
+	// metricsScrapeTimeout is the timeout of scraping the metrics of each replica.
+	metricsScrapeTimeout = 5 * time.Second
+
+	// replicaUpMetric tells whether the metrics of replica are scraped successfully.
+	replicaUpMetric = "gtctl_replica_up"
+
+	prometheusConfigFile = "prometheus.yaml"
+	grafanaDashboardFile = "grafana-dashboard.json"
+)
+
+// metricsTarget is the address that serves the metrics of replica.
+type metricsTarget struct {
+	component string
+	replica   string
+	addr      string
+}
+
+// scrapedMetrics is the metrics of replica in the Prometheus text format, or the error of scraping them.
+type scrapedMetrics struct {
+	target metricsTarget
+	text   string
+	err    error
+}
+
+// ServeMetrics serves the metrics of all the replicas of cluster on the '/metrics' of one local address
+// until the context is done. The replicas are scraped on each request, their samples are labeled by the
+// replica and component, and 'gtctl_replica_up' tells whether the scrape of each replica succeeds.
+func (c *Cluster) ServeMetrics(ctx context.Context, options *opt.MonitorOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "serving metrics"); err != nil {
+		return err
+	}
+
+	addr := options.Addr
+	if len(addr) == 0 {
+		addr = DefaultMetricsAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on '%s': %v", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", c.metricsHandler(options.Name))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: metricsScrapeTimeout}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsScrapeTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	c.logger.V(0).Infof("Serving the metrics of cluster '%s' on 'http://%s/metrics', press Ctrl+C to stop",
+		options.Name, listener.Addr())
+	if err = server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// metricsHandler returns the handler that scrapes and merges the metrics of the replicas of cluster.
+func (c *Cluster) metricsHandler(name string) http.HandlerFunc {
+	// The components are reloaded by each request, since the cluster may have been scaled.
+	var mu sync.Mutex
+	client := &http.Client{Timeout: metricsScrapeTimeout}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cluster, err := c.get(r.Context(), &opt.GetOptions{Name: name})
+		if err != nil {
+			mu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.loadComponents(cluster)
+		targets := c.metricsTargets()
+		mu.Unlock()
+
+		scrapes := make([]scrapedMetrics, len(targets))
+		var wg sync.WaitGroup
+		for i, target := range targets {
+			wg.Add(1)
+			go func(i int, target metricsTarget) {
+				defer wg.Done()
+				text, err := scrapeMetrics(r.Context(), client, target.addr)
+				if err != nil {
+					c.logger.V(1).Infof("failed to scrape the metrics of '%s': %v", target.replica, err)
+				}
+				scrapes[i] = scrapedMetrics{target: target, text: text, err: err}
+			}(i, target)
+		}
+		wg.Wait()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = io.WriteString(w, mergeMetrics(scrapes))
+	}
+}
+
+// metricsTargets returns the addresses that serve the metrics of each replica of cluster,
+// which are the http addresses of the GreptimeDB components and the client address of etcd.
+func (c *Cluster) metricsTargets() []metricsTarget {
+	var targets []metricsTarget
+	for _, component := range c.orderedComponents() {
+		for _, addr := range component.ListenAddrs() {
+			if addr.Arg != "--http-addr" && addr.Arg != "--listen-client-urls" {
+				continue
+			}
+			if target, err := components.HealthCheckAddr(addr.Addr, ""); err == nil {
+				targets = append(targets, metricsTarget{
+					component: component.Name(),
+					replica:   addr.Replica,
+					addr:      target,
+				})
+			}
+		}
+	}
+	return targets
+}
+
+func scrapeMetrics(ctx context.Context, client *http.Client, addr string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metrics", addr), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status '%s'", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// metricFamily is the samples of one metric with its HELP and TYPE, which are kept from the first replica.
+type metricFamily struct {
+	help    string
+	typ     string
+	samples []string
+}
+
+// mergeMetrics merges the metrics of replicas in the Prometheus text format into one exposition. The samples
+// of the same metric are grouped under one HELP and TYPE, since the duplicated ones are rejected by Prometheus.
+func mergeMetrics(scrapes []scrapedMetrics) string {
+	families := make(map[string]*metricFamily)
+	var names []string
+	family := func(name string) *metricFamily {
+		f, ok := families[name]
+		if !ok {
+			f = &metricFamily{}
+			families[name] = f
+			names = append(names, name)
+		}
+		return f
+	}
+
+	up := family(replicaUpMetric)
+	up.help = fmt.Sprintf("# HELP %s Whether the metrics of replica are scraped successfully.", replicaUpMetric)
+	up.typ = fmt.Sprintf("# TYPE %s gauge", replicaUpMetric)
+
+	for _, scrape := range scrapes {
+		labels := fmt.Sprintf("component=%q,replica=%q", scrape.target.component, scrape.target.replica)
+		if scrape.err != nil {
+			up.samples = append(up.samples, fmt.Sprintf("%s{%s} 0", replicaUpMetric, labels))
+			continue
+		}
+		up.samples = append(up.samples, fmt.Sprintf("%s{%s} 1", replicaUpMetric, labels))
+
+		// The samples of histogram and summary are suffixed by '_bucket', '_sum' and '_count' of their TYPE.
+		var current string
+		for _, line := range strings.Split(scrape.text, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+
+			if strings.HasPrefix(line, "#") {
+				fields := strings.Fields(line)
+				if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+					continue
+				}
+				current = fields[2]
+				f := family(current)
+				if fields[1] == "HELP" && len(f.help) == 0 {
+					f.help = line
+				} else if fields[1] == "TYPE" && len(f.typ) == 0 {
+					f.typ = line
+				}
+				continue
+			}
+
+			name := metricName(line)
+			if len(current) == 0 || (name != current && !strings.HasPrefix(name, current+"_")) {
+				current = name
+			}
+			f := family(current)
+			f.samples = append(f.samples, addLabels(line, labels))
+		}
+	}
+
+	var b strings.Builder
+	for _, name := range names {
+		f := families[name]
+		if len(f.samples) == 0 {
+			continue
+		}
+		for _, line := range []string{f.help, f.typ} {
+			if len(line) > 0 {
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+		for _, sample := range f.samples {
+			b.WriteString(sample)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// metricName returns the name of metric of the sample line.
+func metricName(sample string) string {
+	if i := strings.IndexAny(sample, "{ \t"); i >= 0 {
+		return sample[:i]
+	}
+	return sample
+}
+
+// addLabels adds the labels to the sample line, e.g. 'up{job="a"} 1' with 'replica="b"' is 'up{replica="b",job="a"} 1'.
+func addLabels(sample, labels string) string {
+	name := metricName(sample)
+	rest := sample[len(name):]
+	if !strings.HasPrefix(rest, "{") {
+		return fmt.Sprintf("%s{%s}%s", name, labels, rest)
+	}
+	if strings.HasPrefix(rest, "{}") {
+		return fmt.Sprintf("%s{%s}%s", name, labels, rest[2:])
+	}
+	return fmt.Sprintf("%s{%s,%s", name, labels, rest[1:])
+}
+
+// ExportMonitoring writes the Prometheus scrape config of the metrics served by ServeMetrics, and the
+// Grafana dashboard of the cluster on them into the output dir, which is '<name>-monitoring' by default.
+func (c *Cluster) ExportMonitoring(ctx context.Context, options *opt.MonitorOptions) error {
+	if _, err := c.get(ctx, &opt.GetOptions{Name: options.Name}); err != nil {
+		return err
+	}
+
+	addr := options.Addr
+	if len(addr) == 0 {
+		addr = DefaultMetricsAddr
+	}
+	target, err := components.HealthCheckAddr(addr, "")
+	if err != nil {
+		return fmt.Errorf("invalid metrics address '%s': %v", addr, err)
+	}
+
+	outputDir := options.OutputDir
+	if len(outputDir) == 0 {
+		outputDir = fmt.Sprintf("%s-monitoring", options.Name)
+	}
+	if err = os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	prometheusConfig, err := prometheusScrapeConfig(options.Name, target)
+	if err != nil {
+		return err
+	}
+	dashboard, err := grafanaDashboard(options.Name)
+	if err != nil {
+		return err
+	}
+
+	for file, content := range map[string][]byte{
+		prometheusConfigFile: prometheusConfig,
+		grafanaDashboardFile: dashboard,
+	} {
+		if err = os.WriteFile(path.Join(outputDir, file), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	c.logger.V(0).Infof("The Prometheus scrape config is written to '%s', and the Grafana dashboard is written to '%s'",
+		path.Join(outputDir, prometheusConfigFile), path.Join(outputDir, grafanaDashboardFile))
+	c.logger.V(0).Infof("Run 'gtctl cluster monitor serve %s --addr %s' to serve the metrics for Prometheus", options.Name, addr)
+	return nil
+}
+
+type prometheusConfig struct {
+	ScrapeConfigs []prometheusScrape `yaml:"scrape_configs"`
+}
+
+type prometheusScrape struct {
+	JobName        string                   `yaml:"job_name"`
+	ScrapeInterval string                   `yaml:"scrape_interval"`
+	StaticConfigs  []prometheusStaticConfig `yaml:"static_configs"`
+}
+
+type prometheusStaticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// prometheusScrapeConfig returns the Prometheus config that scrapes the metrics of cluster served on target,
+// all the samples are labeled by the cluster name.
+func prometheusScrapeConfig(name, target string) ([]byte, error) {
+	return yaml.Marshal(prometheusConfig{
+		ScrapeConfigs: []prometheusScrape{{
+			JobName:        fmt.Sprintf("gtctl-%s", name),
+			ScrapeInterval: "15s",
+			StaticConfigs: []prometheusStaticConfig{{
+				Targets: []string{target},
+				Labels:  map[string]string{"cluster": name},
+			}},
+		}},
+	})
+}
+
+// grafanaPanel is the time series panel of Grafana dashboard, which shows the expr by replica.
+type grafanaPanel struct {
+	title string
+	expr  string
+	unit  string
+}
+
+// grafanaDashboard returns the Grafana dashboard of the process metrics of each replica of cluster, the
+// Prometheus datasource is selected on the dashboard.
+func grafanaDashboard(name string) ([]byte, error) {
+	selector := fmt.Sprintf(`{cluster=%q}`, name)
+	panels := []grafanaPanel{
+		{title: "Replica up", expr: replicaUpMetric + selector, unit: "none"},
+		{title: "CPU usage", expr: fmt.Sprintf("rate(process_cpu_seconds_total%s[$__rate_interval])", selector), unit: "percentunit"},
+		{title: "Resident memory", expr: "process_resident_memory_bytes" + selector, unit: "bytes"},
+		{title: "Open files", expr: "process_open_fds" + selector, unit: "none"},
+	}
+
+	datasource := map[string]string{"type": "prometheus", "uid": "${datasource}"}
+	var grafanaPanels []map[string]interface{}
+	for i, panel := range panels {
+		grafanaPanels = append(grafanaPanels, map[string]interface{}{
+			"id":         i + 1,
+			"type":       "timeseries",
+			"title":      panel.title,
+			"datasource": datasource,
+			"gridPos":    map[string]int{"h": 8, "w": 12, "x": (i % 2) * 12, "y": (i / 2) * 8},
+			"fieldConfig": map[string]interface{}{
+				"defaults":  map[string]string{"unit": panel.unit},
+				"overrides": []interface{}{},
+			},
+			"targets": []map[string]interface{}{{
+				"refId":        "A",
+				"datasource":   datasource,
+				"expr":         panel.expr,
+				"legendFormat": "{{replica}}",
+			}},
+		})
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"uid":           fmt.Sprintf("gtctl-%s", name),
+		"title":         fmt.Sprintf("GreptimeDB cluster %s", name),
+		"tags":          []string{"greptimedb", "gtctl"},
+		"schemaVersion": 38,
+		"refresh":       "10s",
+		"time":          map[string]string{"from": "now-1h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{{
+				"name":  "datasource",
+				"label": "Datasource",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": grafanaPanels,
+	}, "", "  ")
+}
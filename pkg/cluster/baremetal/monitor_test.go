@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddLabels(t *testing.T) {
+	labels := `replica="frontend.0"`
+	tests := []struct {
+		sample   string
+		expected string
+	}{
+		{"up 1", `up{replica="frontend.0"} 1`},
+		{"up{} 1", `up{replica="frontend.0"} 1`},
+		{`http_requests_total{method="GET",code="200"} 3 1700000000`, `http_requests_total{replica="frontend.0",method="GET",code="200"} 3 1700000000`},
+		{"up\t1", `up{replica="frontend.0"}` + "\t1"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, addLabels(tt.sample, labels))
+	}
+}
+
+func TestMergeMetrics(t *testing.T) {
+	replicaMetrics := `# HELP process_open_fds Number of open file descriptors.
+# TYPE process_open_fds gauge
+process_open_fds 12
+# HELP request_duration_seconds The request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="+Inf"} 2
+request_duration_seconds_sum 0.5
+request_duration_seconds_count 2
+untyped_metric 1
+`
+	scrapes := []scrapedMetrics{
+		{target: metricsTarget{component: "frontend", replica: "frontend.0"}, text: replicaMetrics},
+		{target: metricsTarget{component: "frontend", replica: "frontend.1"}, text: replicaMetrics},
+		{target: metricsTarget{component: "datanode", replica: "datanode.0"}, err: errors.New("connection refused")},
+	}
+
+	expected := `# HELP gtctl_replica_up Whether the metrics of replica are scraped successfully.
+# TYPE gtctl_replica_up gauge
+gtctl_replica_up{component="frontend",replica="frontend.0"} 1
+gtctl_replica_up{component="frontend",replica="frontend.1"} 1
+gtctl_replica_up{component="datanode",replica="datanode.0"} 0
+# HELP process_open_fds Number of open file descriptors.
+# TYPE process_open_fds gauge
+process_open_fds{component="frontend",replica="frontend.0"} 12
+process_open_fds{component="frontend",replica="frontend.1"} 12
+# HELP request_duration_seconds The request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{component="frontend",replica="frontend.0",le="+Inf"} 2
+request_duration_seconds_sum{component="frontend",replica="frontend.0"} 0.5
+request_duration_seconds_count{component="frontend",replica="frontend.0"} 2
+request_duration_seconds_bucket{component="frontend",replica="frontend.1",le="+Inf"} 2
+request_duration_seconds_sum{component="frontend",replica="frontend.1"} 0.5
+request_duration_seconds_count{component="frontend",replica="frontend.1"} 2
+untyped_metric{component="frontend",replica="frontend.0"} 1
+untyped_metric{component="frontend",replica="frontend.1"} 1
+`
+	assert.Equal(t, expected, mergeMetrics(scrapes))
+}
+
+func TestPrometheusScrapeConfig(t *testing.T) {
+	out, err := prometheusScrapeConfig("mycluster", "localhost:9099")
+	assert.NoError(t, err)
+
+	var config prometheusConfig
+	assert.NoError(t, yaml.Unmarshal(out, &config))
+	assert.Len(t, config.ScrapeConfigs, 1)
+	assert.Equal(t, "gtctl-mycluster", config.ScrapeConfigs[0].JobName)
+	assert.Equal(t, []string{"localhost:9099"}, config.ScrapeConfigs[0].StaticConfigs[0].Targets)
+	assert.Equal(t, "mycluster", config.ScrapeConfigs[0].StaticConfigs[0].Labels["cluster"])
+}
+
+func TestGrafanaDashboard(t *testing.T) {
+	out, err := grafanaDashboard("mycluster")
+	assert.NoError(t, err)
+
+	var dashboard struct {
+		UID    string `json:"uid"`
+		Panels []struct {
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	assert.NoError(t, json.Unmarshal(out, &dashboard))
+	assert.Equal(t, "gtctl-mycluster", dashboard.UID)
+	assert.Len(t, dashboard.Panels, 4)
+	assert.Equal(t, `gtctl_replica_up{cluster="mycluster"}`, dashboard.Panels[0].Targets[0].Expr)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"sync"
+)
+
+// runInParallel runs the tasks in parallel, at most limit of them at the same time. It returns the error
+// of the first failed task, and the tasks that have not started are skipped once any task fails.
+func runInParallel(ctx context.Context, limit int, tasks []func(context.Context) error) error {
+	if limit <= 0 {
+		limit = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		errs error
+		sem  = make(chan struct{}, limit)
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, task := range tasks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(task func(context.Context) error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := task(ctx); err != nil {
+				once.Do(func() {
+					errs = err
+					cancel()
+				})
+			}
+		}(task)
+	}
+	wg.Wait()
+
+	if errs == nil {
+		// The parent context is done before all the tasks are started.
+		errs = ctx.Err()
+	}
+	return errs
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInParallel(t *testing.T) {
+	var running, maxRunning, finished int32
+	task := func(ctx context.Context) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			prev := atomic.LoadInt32(&maxRunning)
+			if n <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&finished, 1)
+		return nil
+	}
+
+	tasks := make([]func(context.Context) error, 8)
+	for i := range tasks {
+		tasks[i] = task
+	}
+	assert.NoError(t, runInParallel(context.Background(), 3, tasks))
+	assert.Equal(t, int32(8), finished)
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Greater(t, maxRunning, int32(1))
+
+	// The tasks that have not started are skipped once any task fails.
+	finished = 0
+	failed := func(context.Context) error {
+		return fmt.Errorf("failed to start")
+	}
+	err := runInParallel(context.Background(), 1, append([]func(context.Context) error{failed}, tasks...))
+	assert.EqualError(t, err, "failed to start")
+	assert.Less(t, finished, int32(8))
+}
@@ -0,0 +1,179 @@
+//go:build linux
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	iptablesCommand = "iptables"
+
+	// cgroupRoot is where the unified hierarchy of cgroup v2 is mounted.
+	cgroupRoot = "/sys/fs/cgroup"
+
+	// partitionStateFile is the file under the pid dir of replica that records the cgroup it was in before partitioned.
+	partitionStateFile = "partition"
+)
+
+// partitionReplica isolates the replica from the network by iptables, the rules are marked by mark: the packets to
+// the ports it listens on are dropped, and so are the packets sent by it, which are matched by the cgroup v2 named
+// after mark that it's moved into. Its outgoing packets are not dropped without cgroup v2, e.g. the heartbeats.
+func partitionReplica(mark string, pid int, ports []string, pidDir string, l logger.Logger) error {
+	if err := checkIptables(); err != nil {
+		return err
+	}
+
+	for _, port := range ports {
+		if err := runIptables("-I", "INPUT", "-p", "tcp", "--dport", port,
+			"-m", "comment", "--comment", mark, "-j", "DROP"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		l.Warnf("Only the incoming packets of replica are dropped since cgroup v2 is not available: %v", err)
+		return nil
+	}
+	original, err := processCgroup(pid)
+	if err != nil {
+		return err
+	}
+	if original != "/"+mark {
+		if err = os.WriteFile(filepath.Join(pidDir, partitionStateFile), []byte(original), 0644); err != nil {
+			return err
+		}
+	}
+	if err = os.MkdirAll(filepath.Join(cgroupRoot, mark), 0755); err != nil {
+		return err
+	}
+	if err = moveToCgroup(pid, "/"+mark); err != nil {
+		return err
+	}
+	return runIptables("-I", "OUTPUT", "-m", "cgroup", "--path", mark,
+		"-m", "comment", "--comment", mark, "-j", "DROP")
+}
+
+// healReplica removes the iptables rules marked by mark, and moves the replica back to the cgroup it was in.
+func healReplica(mark string, pid int, pidDir string) error {
+	if err := checkIptables(); err != nil {
+		return err
+	}
+
+	for _, chain := range []string{"INPUT", "OUTPUT"} {
+		out, err := exec.Command(iptablesCommand, "-S", chain).Output()
+		if err != nil {
+			return fmt.Errorf("error listing the rules of chain '%s': %v", chain, err)
+		}
+		for _, args := range markedRuleDeletions(string(out), mark) {
+			if err = runIptables(args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	stateFile := filepath.Join(pidDir, partitionStateFile)
+	original, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pid > 0 {
+		// The process may have exited since it's partitioned.
+		if err = moveToCgroup(pid, strings.TrimSpace(string(original))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err = os.Remove(filepath.Join(cgroupRoot, mark)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Remove(stateFile)
+}
+
+// markedRuleDeletions returns the args of deleting the rules marked by mark from the rules listed by 'iptables -S'.
+func markedRuleDeletions(rules, mark string) [][]string {
+	var deletions [][]string
+	scanner := bufio.NewScanner(strings.NewReader(rules))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "-A" {
+			continue
+		}
+
+		marked := false
+		for i, field := range fields {
+			fields[i] = strings.Trim(field, `"`)
+			if i > 0 && fields[i-1] == "--comment" && fields[i] == mark {
+				marked = true
+			}
+		}
+		if marked {
+			deletions = append(deletions, append([]string{"-D"}, fields[1:]...))
+		}
+	}
+	return deletions
+}
+
+// processCgroup returns the cgroup v2 of the process, which is relative to cgroupRoot.
+func processCgroup(pid int) (string, error) {
+	raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+	return "", fmt.Errorf("process '%d' is not in any cgroup v2", pid)
+}
+
+// moveToCgroup moves the process into the cgroup v2, which is relative to cgroupRoot.
+func moveToCgroup(pid int, cgroup string) error {
+	return os.WriteFile(filepath.Join(cgroupRoot, cgroup, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// checkIptables checks that the iptables is available and can be run.
+func checkIptables() error {
+	if _, err := exec.LookPath(iptablesCommand); err != nil {
+		return fmt.Errorf("'%s' is required to partition the replicas: %v", iptablesCommand, err)
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("partitioning the replicas requires the root privilege, run gtctl by 'sudo -E' to keep its working dir")
+	}
+	return nil
+}
+
+func runIptables(args ...string) error {
+	if out, err := exec.Command(iptablesCommand, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("error running '%s %s': %v: %s", iptablesCommand, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
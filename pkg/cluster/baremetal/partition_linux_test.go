@@ -0,0 +1,37 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkedRuleDeletions(t *testing.T) {
+	rules := `-P INPUT ACCEPT
+-A INPUT -p tcp -m tcp --dport 4001 -m comment --comment gtctl-chaos-mycluster-datanode.1 -j DROP
+-A INPUT -p tcp -m tcp --dport 4000 -m comment --comment gtctl-chaos-mycluster-datanode.0 -j DROP
+-A INPUT -p tcp -m tcp --dport 14101 -m comment --comment "gtctl-chaos-mycluster-datanode.1" -j DROP
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+`
+	assert.Equal(t, [][]string{
+		{"-D", "INPUT", "-p", "tcp", "-m", "tcp", "--dport", "4001", "-m", "comment", "--comment", "gtctl-chaos-mycluster-datanode.1", "-j", "DROP"},
+		{"-D", "INPUT", "-p", "tcp", "-m", "tcp", "--dport", "14101", "-m", "comment", "--comment", "gtctl-chaos-mycluster-datanode.1", "-j", "DROP"},
+	}, markedRuleDeletions(rules, "gtctl-chaos-mycluster-datanode.1"))
+	assert.Empty(t, markedRuleDeletions(rules, "gtctl-chaos-mycluster-frontend.0"))
+}
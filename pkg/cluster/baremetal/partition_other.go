@@ -0,0 +1,35 @@
+//go:build !linux
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// partitionReplica is only supported on Linux, which has iptables.
+func partitionReplica(_ string, _ int, _ []string, _ string, _ logger.Logger) error {
+	return fmt.Errorf("partitioning the replicas is only supported on linux")
+}
+
+// healReplica is only supported on Linux, which has iptables.
+func healReplica(_ string, _ int, _ string) error {
+	return fmt.Errorf("healing the partitioned replicas is only supported on linux")
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+// ListenAddrs returns the listen addresses of all the components that the cluster config starts on the local host,
+// which are checked for conflicts before the components are started. The offsets of config are applied, and there
+// are no addresses to check if the ports are allocated automatically.
+func ListenAddrs(cfg *config.BareMetalClusterConfig, l logger.Logger) ([]components.ListenAddr, error) {
+	if cfg.Cluster.AutoPortAllocation {
+		return nil, nil
+	}
+	if err := applyOffsets(cfg.Cluster); err != nil {
+		return nil, err
+	}
+	return listenAddrs(cfg.Cluster, l), nil
+}
+
+// listenAddrs returns the listen addresses of all the components as they are configured.
+func listenAddrs(cfg *config.BareMetalClusterComponentsConfig, l logger.Logger) []components.ListenAddr {
+	var wg sync.WaitGroup
+	cc := NewClusterComponents(cfg, components.WorkingDirs{}, &wg, l, false)
+	ccs := append([]components.ClusterComponent{cc.Standalone, cc.MetaSrv, cc.Frontend, cc.Flownode, cc.Kafka},
+		cc.datanodes()...)
+	if cc.Standalone == nil && cfg.MetaSrv.Backend == config.MetaSrvBackendEmbeddedEtcd {
+		ccs = append(ccs, cc.Etcd)
+	}
+
+	var addrs []components.ListenAddr
+	for _, component := range ccs {
+		if component != nil {
+			addrs = append(addrs, component.ListenAddrs()...)
+		}
+	}
+	return addrs
+}
+
+// applyOffsets shifts the ports of the configured listen addresses by the PortOffset, and the node ids
+// of datanodes by the NodeIDBase. The external addresses, e.g. the store address of an external etcd,
+// are kept. The offsets are cleared after being applied, so they will not be applied twice.
+func applyOffsets(cfg *config.BareMetalClusterComponentsConfig) error {
+	if cfg.NodeIDBase > 0 {
+		cfg.Datanode.NodeID += cfg.NodeIDBase
+		for _, group := range cfg.DatanodeGroups {
+			// The group without node id follows the previous datanodes.
+			if group.NodeID > 0 {
+				group.NodeID += cfg.NodeIDBase
+			}
+		}
+		cfg.NodeIDBase = 0
+	}
+
+	if cfg.PortOffset == 0 {
+		return nil
+	}
+	offset := cfg.PortOffset
+	cfg.PortOffset = 0
+
+	addrs := []*string{
+		&cfg.Frontend.GRPCAddr, &cfg.Frontend.HTTPAddr, &cfg.Frontend.MysqlAddr, &cfg.Frontend.PostgresAddr,
+		&cfg.MetaSrv.ServerAddr, &cfg.MetaSrv.HTTPAddr,
+		&cfg.Datanode.RPCAddr, &cfg.Datanode.HTTPAddr,
+	}
+	replicaAddrs := []config.ReplicaAddrs{cfg.Frontend.ReplicaAddrs, cfg.MetaSrv.ReplicaAddrs, cfg.Datanode.ReplicaAddrs}
+
+	// The bind address of metasrv has a default that is set by gtctl, so it's always shifted.
+	if len(cfg.MetaSrv.BindAddr) == 0 {
+		cfg.MetaSrv.BindAddr = net.JoinHostPort("127.0.0.1", "3002")
+	}
+	addrs = append(addrs, &cfg.MetaSrv.BindAddr)
+	if cfg.MetaSrv.Backend == config.MetaSrvBackendEmbeddedEtcd {
+		addrs = append(addrs, &cfg.MetaSrv.StoreAddr)
+	}
+
+	for _, group := range cfg.DatanodeGroups {
+		addrs = append(addrs, &group.RPCAddr, &group.HTTPAddr)
+		replicaAddrs = append(replicaAddrs, group.ReplicaAddrs)
+	}
+	if flownode := cfg.Flownode; flownode != nil {
+		addrs = append(addrs, &flownode.RPCAddr, &flownode.HTTPAddr)
+		replicaAddrs = append(replicaAddrs, flownode.ReplicaAddrs)
+	}
+	if standalone := cfg.Standalone; standalone != nil {
+		addrs = append(addrs, &standalone.GRPCAddr, &standalone.HTTPAddr, &standalone.MysqlAddr, &standalone.PostgresAddr)
+		replicaAddrs = append(replicaAddrs, standalone.ReplicaAddrs)
+	}
+	if wal := cfg.WAL; wal != nil && wal.Kafka != nil && wal.Kafka.Embedded != nil {
+		wal.Kafka.Embedded.Addr = components.EmbeddedKafkaAddr(wal.Kafka.Embedded)
+		addrs = append(addrs, &wal.Kafka.Embedded.Addr)
+	}
+
+	for _, replicas := range replicaAddrs {
+		for _, argAddrs := range replicas {
+			for arg, addr := range argAddrs {
+				shifted, err := shiftPort(addr, offset)
+				if err != nil {
+					return err
+				}
+				argAddrs[arg] = shifted
+			}
+		}
+	}
+	for _, addr := range addrs {
+		shifted, err := shiftPort(*addr, offset)
+		if err != nil {
+			return err
+		}
+		*addr = shifted
+	}
+
+	return nil
+}
+
+// checkOtherClusters checks that the ports of cluster do not overlap the ports recorded by the other clusters on
+// the local host, so the clusters shifted by the PortOffset can run side by side, even if some of them are stopped
+// now. The ports that are in use are checked again as the components are started.
+func checkOtherClusters(mm metadata.Manager, name string, cfg *config.BareMetalClusterComponentsConfig) error {
+	if cfg.AutoPortAllocation {
+		return nil
+	}
+
+	names, err := mm.ListClusters()
+	if err != nil {
+		return err
+	}
+	owners := make(map[string]string)
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		cluster, err := readMetadata(filepath.Join(mm.GetWorkingDir(), other, fmt.Sprintf("%s.yaml", other)))
+		if err != nil || cluster.Config == nil || len(cluster.Config.Hosts) > 0 {
+			// The clusters that can't be read, or are deployed on the other hosts, are skipped.
+			continue
+		}
+		for _, addr := range listenAddrs(cluster.Config.Cluster, logger.New(io.Discard, 0)) {
+			if _, port, err := net.SplitHostPort(addr.Addr); err == nil {
+				owners[port] = fmt.Sprintf("%s of cluster '%s'", addr.Replica, other)
+			}
+		}
+	}
+
+	var conflicts []string
+	for _, addr := range listenAddrs(cfg, logger.New(io.Discard, 0)) {
+		_, port, err := net.SplitHostPort(addr.Addr)
+		if err != nil {
+			continue
+		}
+		if owner, ok := owners[port]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s is the port of %s", addr, owner))
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("found %d port conflict(s) with the other clusters, use another port offset:\n  - %s",
+			len(conflicts), strings.Join(conflicts, "\n  - "))
+	}
+	return nil
+}
+
+// shiftPort shifts the port of addr by the offset, the empty addr is left to the default of binary.
+func shiftPort(addr string, offset int) (string, error) {
+	if len(addr) == 0 {
+		return addr, nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	portInt, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+	if portInt+offset > 65535 {
+		return "", fmt.Errorf("port of '%s' exceeds 65535 after being shifted by %d", addr, offset)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(portInt+offset)), nil
+}
+
+// allocatePorts allocates free ports for the listen addresses of each replica, and records
+// the allocated addresses in the ReplicaAddrs of each component. The host of configured
+// address is kept, and the address that is not configured is left to the default of binary.
+func allocatePorts(cfg *config.BareMetalClusterComponentsConfig) error {
+	// Hold all the allocated ports until the allocation is done, so they will not be allocated twice.
+	allocator := &portAllocator{}
+	defer allocator.release()
+
+	var err error
+
+	if standalone := cfg.Standalone; standalone != nil {
+		standalone.ReplicaAddrs, err = allocator.allocateReplicaAddrs(1, map[string]string{
+			"--http-addr":     standalone.HTTPAddr,
+			"--rpc-addr":      standalone.GRPCAddr,
+			"--mysql-addr":    standalone.MysqlAddr,
+			"--postgres-addr": standalone.PostgresAddr,
+		})
+		return err
+	}
+
+	frontend := cfg.Frontend
+	if frontend.ReplicaAddrs, err = allocator.allocateReplicaAddrs(frontend.Replicas, map[string]string{
+		"--http-addr":     frontend.HTTPAddr,
+		"--rpc-addr":      frontend.GRPCAddr,
+		"--mysql-addr":    frontend.MysqlAddr,
+		"--postgres-addr": frontend.PostgresAddr,
+	}); err != nil {
+		return err
+	}
+
+	datanodes := []*config.Datanode{cfg.Datanode}
+	for _, group := range cfg.DatanodeGroups {
+		datanodes = append(datanodes, &group.Datanode)
+	}
+	for _, datanode := range datanodes {
+		if datanode.ReplicaAddrs, err = allocator.allocateReplicaAddrs(datanode.Replicas, map[string]string{
+			"--http-addr": datanode.HTTPAddr,
+			"--rpc-addr":  datanode.RPCAddr,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The bind address of metasrv is not allocated, since the other
+	// components connect to metasrv through its server address.
+	metaSrv := cfg.MetaSrv
+	if metaSrv.ReplicaAddrs, err = allocator.allocateReplicaAddrs(metaSrv.Replicas, map[string]string{
+		"--http-addr": metaSrv.HTTPAddr,
+	}); err != nil {
+		return err
+	}
+
+	if flownode := cfg.Flownode; flownode != nil {
+		if flownode.ReplicaAddrs, err = allocator.allocateReplicaAddrs(flownode.Replicas, map[string]string{
+			"--http-addr": flownode.HTTPAddr,
+			"--rpc-addr":  flownode.RPCAddr,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// portAllocator allocates the free ports by listening on port 0.
+type portAllocator struct {
+	listeners []net.Listener
+}
+
+// allocateReplicaAddrs allocates free ports for the addresses of args of each replica.
+func (a *portAllocator) allocateReplicaAddrs(replicas int, argAddrs map[string]string) (config.ReplicaAddrs, error) {
+	replicaAddrs := make(config.ReplicaAddrs)
+	for i := 0; i < replicas; i++ {
+		replicaAddrs[i] = make(map[string]string)
+		for arg, addr := range argAddrs {
+			if len(addr) == 0 {
+				continue
+			}
+
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			allocated, err := a.allocate(host)
+			if err != nil {
+				return nil, err
+			}
+			replicaAddrs[i][arg] = allocated
+		}
+	}
+
+	return replicaAddrs, nil
+}
+
+// allocate allocates a free port on the host, the port is held until release.
+func (a *portAllocator) allocate(host string) (string, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return "", err
+	}
+	a.listeners = append(a.listeners, l)
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// release releases all the allocated ports.
+func (a *portAllocator) release() {
+	for _, l := range a.listeners {
+		_ = l.Close()
+	}
+	a.listeners = nil
+}
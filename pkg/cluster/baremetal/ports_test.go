@@ -0,0 +1,136 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestAllocatePorts(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig().Cluster
+	cfg.Frontend.MysqlAddr = ""
+
+	assert.NoError(t, allocatePorts(cfg))
+
+	allocated := make(map[string]bool)
+	check := func(replicaAddrs config.ReplicaAddrs, replicas int, args ...string) {
+		assert.Len(t, replicaAddrs, replicas)
+		for i := 0; i < replicas; i++ {
+			assert.Len(t, replicaAddrs[i], len(args))
+			for _, arg := range args {
+				addr := replicaAddrs[i][arg]
+				host, _, err := net.SplitHostPort(addr)
+				assert.NoError(t, err)
+				assert.Equal(t, "0.0.0.0", host)
+				assert.False(t, allocated[addr], "address %s is allocated twice", addr)
+				allocated[addr] = true
+			}
+		}
+	}
+
+	check(cfg.Frontend.ReplicaAddrs, cfg.Frontend.Replicas, "--http-addr", "--rpc-addr", "--postgres-addr")
+	check(cfg.Datanode.ReplicaAddrs, cfg.Datanode.Replicas, "--http-addr", "--rpc-addr")
+	check(cfg.MetaSrv.ReplicaAddrs, cfg.MetaSrv.Replicas, "--http-addr")
+}
+
+func TestApplyOffsets(t *testing.T) {
+	// The http port 14001 of metasrv shifted by 100 is the rpc port of the 2nd datanode, so there is only one.
+	cfg := config.DefaultBareMetalConfig().Cluster
+	cfg.PortOffset = 100
+	cfg.NodeIDBase = 10
+	cfg.Datanode.Replicas = 1
+	cfg.Frontend.MysqlAddr = ""
+	cfg.Datanode.ReplicaAddrs = config.ReplicaAddrs{1: {"--http-addr": "0.0.0.0:15300"}}
+	cfg.DatanodeGroups = []*config.DatanodeGroup{
+		{Name: "hot", Datanode: config.Datanode{Replicas: 1, RPCAddr: "0.0.0.0:15100", HTTPAddr: "0.0.0.0:15250"}},
+	}
+
+	assert.NoError(t, applyOffsets(cfg))
+	assert.Equal(t, "0.0.0.0:4100", cfg.Frontend.HTTPAddr)
+	assert.Empty(t, cfg.Frontend.MysqlAddr)
+	assert.Equal(t, "127.0.0.1:2479", cfg.MetaSrv.StoreAddr)
+	assert.Equal(t, "127.0.0.1:3102", cfg.MetaSrv.BindAddr)
+	assert.Equal(t, "0.0.0.0:15400", cfg.Datanode.ReplicaAddrs[1]["--http-addr"])
+	assert.Equal(t, "0.0.0.0:15200", cfg.DatanodeGroups[0].RPCAddr)
+	assert.Equal(t, 10, cfg.Datanode.NodeID)
+	assert.Equal(t, []int{11}, cfg.DatanodeGroupNodeIDs())
+
+	// The offsets are applied only once.
+	assert.Zero(t, cfg.PortOffset)
+	assert.Zero(t, cfg.NodeIDBase)
+	assert.NoError(t, applyOffsets(cfg))
+	assert.Equal(t, "0.0.0.0:4100", cfg.Frontend.HTTPAddr)
+
+	// The store address of external etcd is kept.
+	cfg = config.DefaultBareMetalConfig().Cluster
+	cfg.MetaSrv.Backend = ""
+	cfg.Datanode.Replicas = 1
+	cfg.PortOffset = 100
+	assert.NoError(t, applyOffsets(cfg))
+	assert.Equal(t, "127.0.0.1:2379", cfg.MetaSrv.StoreAddr)
+
+	cfg.PortOffset = 65000
+	assert.Error(t, applyOffsets(cfg))
+
+}
+
+func TestCheckOtherClusters(t *testing.T) {
+	mm, err := metadata.New(t.TempDir())
+	assert.NoError(t, err)
+	mm.AllocateClusterScopeDirs("default")
+	assert.NoError(t, mm.CreateClusterScopeDirs(config.DefaultBareMetalConfig()))
+
+	// The http port 14001 of metasrv shifted by 100 is the rpc port of the 2nd datanode of the other cluster.
+	cfg := config.DefaultBareMetalConfig().Cluster
+	cfg.PortOffset = 100
+	assert.NoError(t, applyOffsets(cfg))
+	err = checkOtherClusters(mm, "shifted", cfg)
+	assert.ErrorContains(t, err, "of metasrv.0 (--http-addr) is the port of datanode.1 of cluster 'default'")
+
+	cfg = config.DefaultBareMetalConfig().Cluster
+	cfg.PortOffset = 20000
+	assert.NoError(t, applyOffsets(cfg))
+	assert.NoError(t, checkOtherClusters(mm, "shifted", cfg))
+
+	// The cluster itself is not checked.
+	assert.NoError(t, checkOtherClusters(mm, "default", config.DefaultBareMetalConfig().Cluster))
+}
+
+func TestListenAddrs(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.PortOffset = 20000
+
+	addrs, err := ListenAddrs(cfg, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	assert.Contains(t, addrs, components.ListenAddr{Replica: "frontend.0", Arg: "--http-addr", Addr: "0.0.0.0:24000"})
+
+	// The ports allocated automatically are not checked.
+	cfg = config.DefaultBareMetalConfig()
+	cfg.Cluster.AutoPortAllocation = true
+	addrs, err = ListenAddrs(cfg, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	assert.Empty(t, addrs)
+}
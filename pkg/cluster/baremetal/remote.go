@@ -0,0 +1,741 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	"github.com/GreptimeTeam/gtctl/pkg/remote"
+)
+
+const (
+	// remoteArtifactsDir is where the binaries are distributed to under the working dir of gtctl on the host.
+	// The binaries are keyed by their checksums, so they are shared by the clusters and copied only once.
+	remoteArtifactsDir = "artifacts"
+
+	// remoteConfigsDir is where the config files of replicas are copied to under the cluster dir on the host.
+	remoteConfigsDir = "configs"
+
+	// remoteReadyTimeout is the timeout of waiting for the replicas of one component on the hosts to be healthy.
+	remoteReadyTimeout = 3 * time.Minute
+
+	// remoteHealthTimeout is the timeout of checking the health of one replica on the host.
+	remoteHealthTimeout = 3 * time.Second
+
+	// multiHostMode is the mode of the cluster deployed on multiple hosts.
+	multiHostMode = "multi-host bare-metal"
+)
+
+// replicaStateUnreachable means the host that the replica is placed on can't be connected over SSH.
+const replicaStateUnreachable components.ReplicaState = "unreachable"
+
+// isMultiHost returns whether the cluster is deployed on the hosts over SSH instead of the local host.
+func (c *Cluster) isMultiHost() bool {
+	return len(c.config.Hosts) > 0
+}
+
+// hostNames returns the names of the hosts of cluster.
+func (c *Cluster) hostNames() []string {
+	names := make([]string, 0, len(c.config.Hosts))
+	for _, host := range c.config.Hosts {
+		names = append(names, host.Name)
+	}
+	return names
+}
+
+// notSupportedOnHosts returns the error of the operation that is not supported by the cluster deployed on the hosts.
+func notSupportedOnHosts(cluster *config.BareMetalClusterMetadata, operation string) error {
+	if cluster.Config == nil || len(cluster.Config.Hosts) == 0 {
+		return nil
+	}
+	return opt.NotSupportedError(operation, multiHostMode)
+}
+
+// remoteWorkingDirs returns the dirs of cluster on the hosts, which are relative to the working dir of gtctl on each host.
+func remoteWorkingDirs(name string, dryRun *components.DryRun) components.WorkingDirs {
+	return components.WorkingDirs{
+		DataDir: path.Join(name, metadata.ClusterDataDir),
+		LogsDir: path.Join(name, metadata.ClusterLogsDir),
+		PidsDir: path.Join(name, metadata.ClusterPidsDir),
+		DryRun:  dryRun,
+	}
+}
+
+// remoteComponents returns the components deployed on the hosts in the order of starting.
+func (c *Cluster) remoteComponents(cc *ClusterComponents) []components.ClusterComponent {
+	var ordered []components.ClusterComponent
+	if c.useEmbeddedEtcd() {
+		ordered = append(ordered, cc.Etcd)
+	}
+	ordered = append(append(ordered, cc.MetaSrv), cc.datanodes()...)
+	if cc.Flownode != nil {
+		ordered = append(ordered, cc.Flownode)
+	}
+	return append(ordered, cc.Frontend)
+}
+
+// replicaNames returns the names of the replicas of component in order.
+func replicaNames(component components.ClusterComponent) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, addr := range component.ListenAddrs() {
+		if !seen[addr.Replica] {
+			seen[addr.Replica] = true
+			names = append(names, addr.Replica)
+		}
+	}
+	return names
+}
+
+// runOnHost runs the script under the working dir of gtctl on the host.
+func runOnHost(ctx context.Context, host *config.Host, script string) (string, error) {
+	wd := remote.Quote(host.HostWorkingDir())
+	return remote.NewClient(host).Run(ctx, fmt.Sprintf("mkdir -p %s && cd %s || exit 1\n%s", wd, wd, script))
+}
+
+// hostScripts are the lines of scripts to run on each host, the hosts are kept in the order of adding.
+type hostScripts struct {
+	hosts   []*config.Host
+	scripts map[string][]string
+}
+
+func (s *hostScripts) add(host *config.Host, line string) {
+	if s.scripts == nil {
+		s.scripts = make(map[string][]string)
+	}
+	if _, ok := s.scripts[host.Name]; !ok {
+		s.hosts = append(s.hosts, host)
+	}
+	s.scripts[host.Name] = append(s.scripts[host.Name], line)
+}
+
+// run runs the scripts on the hosts in parallel, at most limit of hosts at the same time.
+func (s *hostScripts) run(ctx context.Context, limit int) error {
+	tasks := make([]func(context.Context) error, 0, len(s.hosts))
+	for _, host := range s.hosts {
+		host := host
+		tasks = append(tasks, func(ctx context.Context) error {
+			_, err := runOnHost(ctx, host, strings.Join(s.scripts[host.Name], "\n"))
+			return err
+		})
+	}
+	return runInParallel(ctx, limit, tasks)
+}
+
+// createRemote deploys the components on the hosts over SSH in order, each of them is healthy before the next one
+// is deployed. The components are planned as in dry-run mode with the dirs on the hosts, and the planned dirs,
+// files and commands are applied on the hosts that the replicas are placed on. The replicas are supervised on
+// the hosts, so the cluster keeps on running after gtctl exits.
+func (c *Cluster) createRemote(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Cluster == nil {
+		return fmt.Errorf("missing create greptimedb cluster options")
+	}
+
+	greptime, err := c.greptimeBinary(ctx, options.Cluster.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+	var etcd string
+	if c.useEmbeddedEtcd() {
+		if etcd, err = c.etcdBinary(ctx, options.Etcd != nil && options.Etcd.UseGreptimeCNArtifacts); err != nil {
+			return err
+		}
+	}
+
+	name := filepath.Base(c.mm.GetClusterScopeDirs().BaseDir)
+	d := &remoteDeployer{
+		cluster:  c,
+		name:     name,
+		hosts:    replicaHosts(c.config),
+		binaries: make(map[string]string),
+	}
+
+	spinner := options.Spinner
+	if c.dryRun == nil && spinner != nil {
+		spinner.Start(fmt.Sprintf("Installing GreptimeDB Cluster on hosts %s...", strings.Join(c.hostNames(), ", ")))
+	}
+	abort := func(err error) error {
+		c.logger.Warnf("To view the failure by browsing logs in '%s' on the hosts", remoteWorkingDirs(name, nil).LogsDir)
+		if c.keepOnFailure {
+			c.logger.Warnf("The started replicas are kept running on the hosts for debugging")
+		} else if err := c.stopRemote(context.Background()); err != nil {
+			c.logger.Warnf("Failed to stop the started replicas on the hosts: %v", err)
+		}
+		return err
+	}
+
+	started := c.remoteComponents(c.cc)
+	for i, component := range started {
+		plan := &components.DryRun{}
+		planned := c.remoteComponents(NewClusterComponents(c.config.Cluster, remoteWorkingDirs(name, plan),
+			&c.wg, c.logger, c.useMemoryMeta))[i]
+
+		binary := greptime
+		if component.Name() == components.EtcdComponentName {
+			binary = etcd
+		}
+		if err = planned.Start(c.ctx, c.stop, binary); err == nil {
+			err = d.apply(ctx, plan)
+		}
+		if err == nil && c.dryRun == nil {
+			spinner.Progress(fmt.Sprintf("starting %s, %d/%d", component.Name(), i+1, len(started)))
+			err = c.waitForRemoteReady(ctx, component)
+		}
+		if err != nil {
+			if c.dryRun == nil {
+				if spinner != nil {
+					spinner.Stop(false, "Installing GreptimeDB Cluster on hosts failed")
+				}
+				return abort(err)
+			}
+			return err
+		}
+	}
+
+	if c.dryRun != nil {
+		c.renderDryRun()
+		return nil
+	}
+	if spinner != nil {
+		spinner.Stop(true, "Installing GreptimeDB Cluster on hosts successfully 🎉")
+	}
+	if err = c.verify(ctx, options); err != nil {
+		return abort(err)
+	}
+	c.events.Publish(&events.Event{Type: events.TypeClusterReady,
+		Message: fmt.Sprintf("cluster is ready on hosts %s", strings.Join(c.hostNames(), ", "))})
+	return nil
+}
+
+// remoteDeployer applies the planned components on the hosts.
+type remoteDeployer struct {
+	cluster *Cluster
+	name    string
+
+	// hosts are the hosts that the replicas are placed on, keyed by the name of replica.
+	hosts map[string]*config.Host
+
+	// binaries are the paths of the distributed binaries on the hosts, keyed by the name of host and the local path.
+	binaries map[string]string
+
+	// mu guards the binaries, which are distributed to the hosts in parallel.
+	mu sync.Mutex
+}
+
+// apply applies the planned dirs, files and commands of one component on the hosts that its replicas are placed on,
+// or records them in dry-run mode. The dirs and files of one replica are applied on its host only.
+func (d *remoteDeployer) apply(ctx context.Context, plan *components.DryRun) error {
+	var replicas []string
+	for _, command := range plan.Commands {
+		if d.hosts[command.Name] == nil {
+			return fmt.Errorf("replica '%s' is not placed on any host", command.Name)
+		}
+		replicas = append(replicas, command.Name)
+	}
+
+	var scripts hostScripts
+	for _, dir := range plan.Dirs {
+		for _, host := range d.placedHosts(dir, replicas) {
+			scripts.add(host, fmt.Sprintf("mkdir -p %s", remote.Quote(dir)))
+			d.record(host, dir, nil, nil)
+		}
+	}
+	for _, file := range plan.Files {
+		for _, host := range d.placedHosts(file.Path, replicas) {
+			scripts.add(host, fmt.Sprintf("printf '%%s' %s > %s", remote.Quote(file.Content), remote.Quote(file.Path)))
+			d.record(host, "", &file, nil)
+		}
+	}
+
+	// The binaries are distributed to the hosts in parallel before the replicas are started.
+	limit := d.cluster.config.Cluster.Concurrency()
+	var tasks []func(context.Context) error
+	distributed := make(map[string]bool)
+	for _, command := range plan.Commands {
+		host, local := d.hosts[command.Name], command.Argv[0]
+		if key := fmt.Sprintf("%s:%s", host.Name, local); !distributed[key] {
+			distributed[key] = true
+			tasks = append(tasks, func(ctx context.Context) error {
+				_, err := d.distribute(ctx, host, local)
+				return err
+			})
+		}
+	}
+	if err := runInParallel(ctx, limit, tasks); err != nil {
+		return err
+	}
+
+	dirs := remoteWorkingDirs(d.name, nil)
+	for _, command := range plan.Commands {
+		host := d.hosts[command.Name]
+		binary, err := d.distribute(ctx, host, command.Argv[0])
+		if err != nil {
+			return err
+		}
+
+		args := make([]string, 0, len(command.Argv)-1)
+		for _, arg := range command.Argv[1:] {
+			// The config files are copied to the hosts.
+			if local := strings.TrimPrefix(arg, "-c="); local != arg {
+				configFile := path.Join(d.name, remoteConfigsDir, command.Name, filepath.Base(local))
+				if err = d.copy(ctx, host, local, configFile); err != nil {
+					return err
+				}
+				arg = "-c=" + configFile
+			}
+			args = append(args, arg)
+		}
+
+		process := &remote.Process{
+			Name:   command.Name,
+			Binary: binary,
+			Args:   args,
+			PidDir: path.Join(dirs.PidsDir, command.Name),
+			LogDir: path.Join(dirs.LogsDir, command.Name),
+		}
+		if len(command.Env) > 0 {
+			process.EnvFile = path.Join(process.PidDir, remote.EnvFileName)
+			if err = d.copyEnv(ctx, host, command.Env, process.EnvFile); err != nil {
+				return err
+			}
+		}
+		scripts.add(host, remote.StartScript(process))
+		d.record(host, "", nil, &components.DryRunCommand{Name: command.Name, Env: command.Env, Argv: append([]string{binary}, args...)})
+	}
+
+	if d.cluster.dryRun != nil {
+		return nil
+	}
+	return scripts.run(ctx, limit)
+}
+
+// record records the dir, file or command applied on the host in dry-run mode.
+func (d *remoteDeployer) record(host *config.Host, dir string, file *components.DryRunFile, command *components.DryRunCommand) {
+	dryRun := d.cluster.dryRun
+	if dryRun == nil {
+		return
+	}
+
+	switch {
+	case len(dir) > 0:
+		dryRun.AddDir(fmt.Sprintf("%s:%s", host.Name, path.Join(host.HostWorkingDir(), dir)))
+	case file != nil:
+		dryRun.AddFile(fmt.Sprintf("%s:%s", host.Name, path.Join(host.HostWorkingDir(), file.Path)), file.Content)
+	case command != nil:
+		dryRun.AddCommand(fmt.Sprintf("%s on host '%s'", command.Name, host.Name), command.Env, command.Argv...)
+	}
+}
+
+// placedHosts returns the host of the replica whose dir contains p, or the hosts of all the replicas
+// if p is shared by the replicas, e.g. the dir of certificates.
+func (d *remoteDeployer) placedHosts(p string, replicas []string) []*config.Host {
+	for _, replica := range replicas {
+		if strings.Contains("/"+p+"/", "/"+replica+"/") {
+			return []*config.Host{d.hosts[replica]}
+		}
+	}
+
+	var hosts []*config.Host
+	seen := make(map[string]bool)
+	for _, replica := range replicas {
+		if host := d.hosts[replica]; !seen[host.Name] {
+			seen[host.Name] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// distribute copies the local binary to the host once, and returns its path under the working dir on the host.
+// The copied binary is verified by its checksum before it's used.
+func (d *remoteDeployer) distribute(ctx context.Context, host *config.Host, local string) (string, error) {
+	key := fmt.Sprintf("%s:%s", host.Name, local)
+	d.mu.Lock()
+	binary, ok := d.binaries[key]
+	d.mu.Unlock()
+	if ok {
+		return binary, nil
+	}
+
+	// The binary may not be downloaded or built in dry-run mode.
+	checksum, err := fileChecksum(local)
+	if err != nil && d.cluster.dryRun == nil {
+		return "", err
+	}
+	if err != nil {
+		checksum = "<checksum>"
+	} else {
+		checksum = checksum[:16]
+	}
+	binary = path.Join(remoteArtifactsDir, filepath.Base(local), checksum, filepath.Base(local))
+	if d.cluster.dryRun != nil {
+		d.setBinary(key, binary)
+		return binary, nil
+	}
+
+	target := path.Join(host.HostWorkingDir(), binary)
+	client := remote.NewClient(host)
+	if existing, err := client.Checksum(ctx, target); err != nil {
+		return "", err
+	} else if strings.HasPrefix(existing, checksum) {
+		d.cluster.logger.V(3).Infof("The binary '%s' already exists on host '%s', skip distributing.", local, host.Name)
+		d.setBinary(key, binary)
+		return binary, nil
+	}
+
+	d.cluster.logger.V(0).Infof("Distributing '%s' to host '%s'...", local, host.Name)
+	tmp := target + ".tmp"
+	if err = d.copy(ctx, host, local, path.Join(remoteArtifactsDir, filepath.Base(local), checksum, filepath.Base(local)+".tmp")); err != nil {
+		return "", err
+	}
+	copied, err := client.Checksum(ctx, tmp)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(copied, checksum) {
+		return "", fmt.Errorf("checksum mismatch of '%s' copied to host '%s'", local, host.Name)
+	}
+	if _, err = client.Run(ctx, fmt.Sprintf("chmod 755 %[1]s && mv -f %[1]s %[2]s", remote.Quote(tmp), remote.Quote(target))); err != nil {
+		return "", err
+	}
+	d.setBinary(key, binary)
+
+	return binary, nil
+}
+
+func (d *remoteDeployer) setBinary(key, binary string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.binaries[key] = binary
+}
+
+// copy copies the local file to p under the working dir on the host, nothing is copied in dry-run mode.
+func (d *remoteDeployer) copy(ctx context.Context, host *config.Host, local, p string) error {
+	if d.cluster.dryRun != nil {
+		return nil
+	}
+
+	target := path.Join(host.HostWorkingDir(), p)
+	client := remote.NewClient(host)
+	if _, err := client.Run(ctx, fmt.Sprintf("mkdir -p %s", remote.Quote(path.Dir(target)))); err != nil {
+		return err
+	}
+	return client.Copy(ctx, local, target)
+}
+
+// copyEnv copies the env file of process to p on the host, which is only readable by the owner, so the secrets
+// in env, e.g. the password of metadata store, are neither in the scripts nor in the command lines on the host.
+func (d *remoteDeployer) copyEnv(ctx context.Context, host *config.Host, env map[string]string, p string) error {
+	if d.cluster.dryRun != nil {
+		return nil
+	}
+
+	// The temporary file is created with mode 0600, which is kept by the copy.
+	f, err := os.CreateTemp("", "gtctl-env-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(remote.RenderEnvFile(env)); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if err = d.copy(ctx, host, f.Name(), p+".tmp"); err != nil {
+		return err
+	}
+	_, err = runOnHost(ctx, host, fmt.Sprintf("chmod 600 %[1]s && mv -f %[1]s %[2]s", remote.Quote(p+".tmp"), remote.Quote(p)))
+	return err
+}
+
+// fileChecksum returns the hex sha256 checksum of the file.
+func fileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// waitForRemoteReady waits for all the replicas of component on the hosts to be healthy.
+func (c *Cluster) waitForRemoteReady(ctx context.Context, component components.ClusterComponent) error {
+	ctx, cancel := context.WithTimeout(ctx, remoteReadyTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	addrs := healthAddrs(component)
+	for {
+		var unhealthy []string
+		for _, replica := range replicaNames(component) {
+			if !isHealthy(ctx, component, addrs[replica]) {
+				unhealthy = append(unhealthy, replica)
+			}
+		}
+		if len(unhealthy) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("replicas of %s on the hosts are not healthy in %s: %s",
+				component.Name(), remoteReadyTimeout, strings.Join(unhealthy, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthAddrs returns the addresses to check the health of the replicas of component, keyed by the name of replica.
+func healthAddrs(component components.ClusterComponent) map[string]string {
+	arg := components.HealthCheckArg(component)
+	addrs := make(map[string]string)
+	for _, addr := range component.ListenAddrs() {
+		if addr.Arg != arg && addr.Arg != "--listen-client-urls" {
+			continue
+		}
+		if health, err := components.HealthCheckAddr(addr.Addr, ""); err == nil {
+			addrs[addr.Replica] = health
+		}
+	}
+	return addrs
+}
+
+// isHealthy checks the health of the replica of component on addr through the health check of component.
+func isHealthy(ctx context.Context, component components.ClusterComponent, addr string) bool {
+	ctx, cancel := context.WithTimeout(ctx, remoteHealthTimeout)
+	defer cancel()
+
+	return components.CheckHealth(ctx, component, addr) == nil
+}
+
+// stopRemote stops the replicas on the hosts gracefully in the reverse order of starting.
+func (c *Cluster) stopRemote(ctx context.Context) error {
+	hosts := replicaHosts(c.config)
+	dirs := remoteWorkingDirs(filepath.Base(c.mm.GetClusterScopeDirs().BaseDir), nil)
+
+	ordered := c.remoteComponents(c.cc)
+	for i := len(ordered) - 1; i >= 0; i-- {
+		var scripts hostScripts
+		for _, replica := range replicaNames(ordered[i]) {
+			scripts.add(hosts[replica], remote.StopScript(path.Join(dirs.PidsDir, replica), c.drainTimeout))
+		}
+
+		c.logger.V(3).Infof("Stopping component '%s' on hosts with drain timeout %s", ordered[i].Name(), c.drainTimeout)
+		if err := scripts.run(ctx, c.config.Cluster.Concurrency()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// probeRemote probes the processes of the replicas on the hosts, the hosts that can't be connected are
+// returned with the errors, keyed by the name of host.
+func probeRemote(ctx context.Context, cfg *config.BareMetalClusterConfig, name string) (
+	map[string]*remote.ProcessState, map[string]error) {
+	hosts := replicaHosts(cfg)
+	dirs := remoteWorkingDirs(name, nil)
+
+	var scripts hostScripts
+	for _, host := range cfg.Hosts {
+		for replica, placed := range hosts {
+			if placed == host {
+				scripts.add(host, remote.ProbeScript(replica, path.Join(dirs.PidsDir, replica)))
+			}
+		}
+	}
+
+	states, unreachable := make(map[string]*remote.ProcessState), make(map[string]error)
+	for _, host := range scripts.hosts {
+		output, err := runOnHost(ctx, host, strings.Join(scripts.scripts[host.Name], "\n"))
+		if err == nil {
+			var probed map[string]*remote.ProcessState
+			if probed, err = remote.ParseProbes(output); err == nil {
+				for replica, state := range probed {
+					states[replica] = state
+				}
+				continue
+			}
+		}
+		unreachable[host.Name] = err
+	}
+
+	return states, unreachable
+}
+
+// isRemoteAlive checks whether any replica of cluster is running on the hosts.
+func (c *Cluster) isRemoteAlive(cluster *config.BareMetalClusterMetadata) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteReadyTimeout)
+	defer cancel()
+
+	states, unreachable := probeRemote(ctx, cluster.Config, filepath.Base(cluster.ClusterDir))
+	for host, err := range unreachable {
+		return false, fmt.Errorf("host '%s' is unreachable: %v", host, err)
+	}
+	for _, state := range states {
+		if state.Alive {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// collectRemoteStatus collects the status of each replica on the hosts as the rows of table and the views, and
+// the replicas that are not running. The replica is running if its process is alive and it's healthy.
+func (c *Cluster) collectRemoteStatus(ctx context.Context) (bulk [][]string, views []*ReplicaStatusView, failed []string) {
+	hosts := replicaHosts(c.config)
+	states, unreachable := probeRemote(ctx, c.config, filepath.Base(c.mm.GetClusterScopeDirs().BaseDir))
+
+	for _, component := range c.orderedComponents() {
+		endpoints := make(map[string]map[string]string)
+		for _, addr := range component.ListenAddrs() {
+			if endpoints[addr.Replica] == nil {
+				endpoints[addr.Replica] = make(map[string]string)
+			}
+			endpoints[addr.Replica][addr.Arg] = addr.Addr
+		}
+		addrs := healthAddrs(component)
+
+		for _, replica := range replicaNames(component) {
+			host := hosts[replica]
+			view := &ReplicaStatusView{
+				Replica:   replica,
+				Host:      host.Name,
+				State:     string(components.ReplicaStateStopped),
+				Endpoints: endpoints[replica],
+			}
+
+			state := states[replica]
+			switch {
+			case unreachable[host.Name] != nil:
+				view.State, view.Reason = string(replicaStateUnreachable), unreachable[host.Name].Error()
+			case state == nil || state.Pid <= 0:
+			case !state.Alive:
+				view.Pid, view.Restarts = state.Pid, state.Restarts
+				view.State, view.Reason = string(components.ReplicaStateDead), "the process exited unexpectedly"
+			case !isHealthy(ctx, component, addrs[replica]):
+				view.Pid, view.Restarts = state.Pid, state.Restarts
+				view.State, view.Reason = string(components.ReplicaStateUnhealthy), "the health check failed"
+			default:
+				view.Pid, view.Restarts = state.Pid, state.Restarts
+				view.State = string(components.ReplicaStateRunning)
+			}
+
+			pid, restarts := "N/A", "N/A"
+			if view.Pid > 0 {
+				pid, restarts = strconv.Itoa(view.Pid), strconv.Itoa(view.Restarts)
+			}
+			bulk = append(bulk, []string{fmt.Sprintf("%s (%s)", replica, host.Name), pid, view.State, "N/A", restarts,
+				"N/A", "N/A", "N/A", stateEndpoints(view.Endpoints), view.Reason})
+			views = append(views, view)
+			if view.State != string(components.ReplicaStateRunning) {
+				failed = append(failed, fmt.Sprintf("%s (%s)", replica, view.State))
+			}
+		}
+	}
+
+	return bulk, views, failed
+}
+
+// deleteRemote deletes the dirs of cluster, or the dirs of the replicas of components on the hosts.
+func (c *Cluster) deleteRemote(ctx context.Context, options *opt.DeleteOptions) error {
+	hosts := replicaHosts(c.config)
+	name := filepath.Base(c.mm.GetClusterScopeDirs().BaseDir)
+	dirs := remoteWorkingDirs(name, nil)
+
+	var scripts hostScripts
+	remove := func(host *config.Host, p string) {
+		scripts.add(host, fmt.Sprintf("rm -rf %s", remote.Quote(p)))
+	}
+
+	if len(options.Components) == 0 {
+		for _, host := range c.config.Hosts {
+			if !options.RetainData && !options.RetainLogs {
+				remove(host, name)
+				continue
+			}
+			remove(host, dirs.PidsDir)
+			remove(host, path.Join(name, remoteConfigsDir))
+			if !options.RetainLogs {
+				remove(host, dirs.LogsDir)
+			}
+			if !options.RetainData {
+				remove(host, dirs.DataDir)
+			}
+		}
+
+		// The data of datanodes placed out of the cluster dir are deleted by replicas.
+		if !options.RetainData {
+			for _, datanode := range datanodeConfigs(c.config.Cluster) {
+				if len(datanode.config.DataDir) == 0 {
+					continue
+				}
+				for i := 0; i < datanode.config.Replicas; i++ {
+					replica := replicaName(datanode.name, i)
+					remove(hosts[replica], path.Join(datanode.config.DataDir, replica))
+				}
+			}
+		}
+	} else {
+		_, dataDirs := componentPaths(c.config.Cluster, dirs.DataDir)
+		for _, componentName := range options.Components {
+			component, err := c.deletableComponent(componentName)
+			if err != nil {
+				return err
+			}
+
+			for _, replica := range replicaNames(component) {
+				remove(hosts[replica], path.Join(dirs.PidsDir, replica))
+				if !options.RetainLogs {
+					remove(hosts[replica], path.Join(dirs.LogsDir, replica))
+				}
+				if dataDir, ok := dataDirs[component.Name()]; ok && !options.RetainData {
+					remove(hosts[replica], path.Join(dataDir, replica))
+				}
+			}
+		}
+	}
+
+	c.logger.V(0).Infof("Deleting the cluster '%s' on hosts %s", name, strings.Join(c.hostNames(), ", "))
+	return scripts.run(ctx, c.config.Cluster.Concurrency())
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func (c *Cluster) Restart(ctx context.Context, options *opt.RestartOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "restart"); err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if !running {
+		return fmt.Errorf("cluster '%s' is not running", options.Name)
+	}
+
+	c.loadComponents(cluster)
+
+	component, err := c.component(options.ComponentType)
+	if err != nil {
+		return err
+	}
+
+	c.logger.V(0).Infof("Stopping component '%s' of cluster '%s'...", component.Name(), options.Name)
+	if err = c.stopComponent(ctx, component); err != nil {
+		return err
+	}
+	if err = c.checkPortConflicts(component); err != nil {
+		return err
+	}
+	if component == c.cc.MetaSrv {
+		if err = c.checkBackendStorage(ctx); err != nil {
+			return err
+		}
+	}
+
+	binPath, err := c.greptimeBinary(ctx, options.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+
+	c.logger.V(0).Infof("Starting component '%s' of cluster '%s'...", component.Name(), options.Name)
+	if err = component.Start(c.ctx, c.stop, binPath); err != nil {
+		return err
+	}
+	c.recordState(ctx)
+	c.logger.V(0).Infof("Component '%s' of cluster '%s' is restarted!", component.Name(), options.Name)
+	c.warnUnsupervised(component.Name())
+
+	return nil
+}
+
+// warnUnsupervised warns that the replicas of components started by current gtctl process are not supervised
+// after it exits, since it's not the foreground gtctl process of cluster. They are not restarted by the restart
+// policies, and their crashes are neither recorded nor reported as events. They are supervised again once
+// the cluster is stopped and started by 'gtctl cluster stop' and 'gtctl cluster start'.
+func (c *Cluster) warnUnsupervised(names ...string) {
+	if len(names) == 0 {
+		return
+	}
+	c.logger.Warnf("The replicas of '%s' are not supervised after this gtctl exits, they are not restarted on exit "+
+		"and their crashes are not recorded, stop and start the cluster to supervise them again", strings.Join(names, "', '"))
+}
+
+// loadComponents rebuilds the cluster components by the config that the cluster was created with.
+func (c *Cluster) loadComponents(cluster *config.BareMetalClusterMetadata) {
+	c.config = cluster.Config
+	c.useMemoryMeta = c.useMemoryMeta || cluster.UseMemoryMeta
+	csd := c.mm.GetClusterScopeDirs()
+	c.cc = NewClusterComponents(c.config.Cluster, components.WorkingDirs{
+		DataDir:      csd.DataDir,
+		LogsDir:      csd.LogsDir,
+		PidsDir:      csd.PidsDir,
+		CrashesDir:   csd.CrashesDir,
+		CrashDump:    c.config.Cluster.CrashDump,
+		DrainTimeout: c.drainTimeout,
+		Events:       c.events,
+	}, &c.wg, c.logger, c.useMemoryMeta)
+}
+
+// component returns the cluster component by its kind.
+func (c *Cluster) component(kind greptimedbclusterv1alpha1.ComponentKind) (components.ClusterComponent, error) {
+	if c.cc.Standalone != nil {
+		if kind != components.StandaloneComponentName {
+			return nil, fmt.Errorf("only the standalone is deployed in the cluster")
+		}
+		return c.cc.Standalone, nil
+	}
+
+	switch kind {
+	case greptimedbclusterv1alpha1.FrontendComponentKind:
+		return c.cc.Frontend, nil
+	case greptimedbclusterv1alpha1.DatanodeComponentKind:
+		return c.cc.Datanode, nil
+	case greptimedbclusterv1alpha1.MetaComponentKind:
+		return c.cc.MetaSrv, nil
+	case components.FlownodeComponentName:
+		if c.cc.Flownode == nil {
+			return nil, fmt.Errorf("flownode is not deployed in the cluster")
+		}
+		return c.cc.Flownode, nil
+	default:
+		for _, group := range c.cc.DatanodeGroups {
+			if group.Name() == string(kind) {
+				return group, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown component '%s'", kind)
+	}
+}
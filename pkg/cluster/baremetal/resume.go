@@ -0,0 +1,171 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// Resume continues the failed creation of cluster with the config that it was created with. The components
+// that have been started and are still running are kept, and the others are started in order from the failed one.
+func (c *Cluster) Resume(ctx context.Context, options *opt.CreateOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "resumed"); err != nil {
+		return err
+	}
+	if cluster.Checkpoint == nil {
+		return fmt.Errorf("cluster '%s' has been created, there is no failed creation to resume", options.Name)
+	}
+	if c.isForegroundRunning(cluster) {
+		return fmt.Errorf("cluster '%s' is being run by gtctl process (pid '%d')", options.Name, cluster.ForegroundPid)
+	}
+
+	c.loadComponents(cluster)
+
+	// The completed components are kept only if all of their replicas are still running, the others,
+	// including the replicas of the failed component that are left running, are stopped to be started again.
+	c.resumed = &config.CreateCheckpoint{}
+	for _, component := range c.orderedComponents() {
+		if cluster.Checkpoint.IsCompleted(component.Name()) && isRunning(ctx, component) {
+			c.logger.V(0).Infof("Component '%s' is running, skip starting it", component.Name())
+			c.resumed.Completed = append(c.resumed.Completed, component.Name())
+			continue
+		}
+		if err = c.stopComponent(ctx, component); err != nil {
+			return fmt.Errorf("error stopping component '%s': %v", component.Name(), err)
+		}
+	}
+	if cluster.Checkpoint.Failed != "" {
+		c.logger.V(0).Infof("Resuming the creation of cluster '%s' from component '%s', which failed: %s",
+			options.Name, cluster.Checkpoint.Failed, cluster.Checkpoint.Error)
+	}
+
+	return c.Create(ctx, options)
+}
+
+// isRunning returns true if all the replicas of component are running.
+func isRunning(ctx context.Context, component components.ClusterComponent) bool {
+	statuses := component.Status(ctx)
+	for _, status := range statuses {
+		if status.State != components.ReplicaStateRunning {
+			return false
+		}
+	}
+	return len(statuses) > 0
+}
+
+// isResumed returns true if the component is kept running by the resumed creation.
+func (c *Cluster) isResumed(component components.ClusterComponent) bool {
+	return c.resumed != nil && c.resumed.IsCompleted(component.Name())
+}
+
+// startComponent starts the component unless it's kept running by the resumed creation,
+// and checkpoints whether it's started.
+func (c *Cluster) startComponent(ctx context.Context, component components.ClusterComponent, binPath string) error {
+	if c.isResumed(component) {
+		return nil
+	}
+
+	err := component.Start(c.ctx, c.stop, binPath)
+	c.checkpoint(ctx, component.Name(), err)
+	return err
+}
+
+// checkpoint records that the component has been started, or failed to start if failure is not nil.
+// The failure of recording is only warned, since it only affects resuming the creation.
+func (c *Cluster) checkpoint(ctx context.Context, name string, failure error) {
+	if c.dryRun != nil {
+		return
+	}
+
+	c.checkpointMu.Lock()
+	defer c.checkpointMu.Unlock()
+
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err == nil {
+		if cluster.Checkpoint == nil {
+			cluster.Checkpoint = &config.CreateCheckpoint{}
+		}
+		checkpoint := cluster.Checkpoint
+		if failure != nil {
+			checkpoint.Failed, checkpoint.Error = name, failure.Error()
+		} else {
+			if !checkpoint.IsCompleted(name) {
+				checkpoint.Completed = append(checkpoint.Completed, name)
+			}
+			checkpoint.Failed, checkpoint.Error = "", ""
+		}
+		err = c.mm.UpdateClusterMetadata(cluster)
+	}
+	if err != nil {
+		c.logger.Warnf("failed to record the checkpoint of creating cluster: %v", err)
+	}
+}
+
+// clearCheckpoint clears the checkpoint once all the components have been started.
+func (c *Cluster) clearCheckpoint(ctx context.Context) {
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err == nil && cluster.Checkpoint != nil {
+		cluster.Checkpoint = nil
+		err = c.mm.UpdateClusterMetadata(cluster)
+	}
+	if err != nil {
+		c.logger.Warnf("failed to clear the checkpoint of creating cluster: %v", err)
+	}
+}
+
+// abortCreation stops the started components when the creation fails, or leaves them running in background
+// for debugging if keepOnFailure is set. Either way, the creation can be resumed from the failed component.
+func (c *Cluster) abortCreation(ctx context.Context, options *opt.CreateOptions, cause error) error {
+	name := filepath.Base(c.mm.GetClusterScopeDirs().BaseDir)
+	defer c.logger.V(0).Infof("To resume the creation by running: %s",
+		logger.Bold(fmt.Sprintf("gtctl cluster create %s --bare-metal --resume", name)))
+
+	if !c.keepOnFailure {
+		if err := c.Wait(ctx, true); err != nil {
+			return err
+		}
+		return cause
+	}
+
+	// The kept components are stopped by their pids as the detached cluster, since current gtctl process exits.
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err == nil {
+		cluster.ForegroundPid, cluster.Detached = 0, true
+		err = c.mm.UpdateClusterMetadata(cluster)
+	}
+	if err != nil {
+		c.logger.Warnf("failed to detach the kept components of cluster: %v", err)
+	}
+	c.recordState(ctx)
+
+	c.logger.Warnf("The started components of cluster '%s' are kept running for debugging", options.Name)
+	c.logger.Warnf("To view the failure by browsing logs in: %s", logger.Bold(c.mm.GetClusterScopeDirs().LogsDir))
+	c.logger.V(0).Infof("To stop the cluster by running: %s", logger.Bold(fmt.Sprintf("gtctl cluster stop %s", name)))
+
+	return cause
+}
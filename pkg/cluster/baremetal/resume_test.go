@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestCheckpoint(t *testing.T) {
+	mm, err := metadata.New(t.TempDir())
+	assert.NoError(t, err)
+	mm.AllocateClusterScopeDirs("test")
+
+	cfg := config.DefaultBareMetalConfig()
+	assert.NoError(t, mm.CreateClusterScopeDirs(cfg))
+	c := &Cluster{config: cfg, mm: mm, logger: logger.New(io.Discard, 0)}
+	ctx := context.Background()
+
+	// The cluster that has been created can't be resumed.
+	assert.Error(t, c.Resume(ctx, &opt.CreateOptions{Name: "test"}))
+
+	checkpoint := func() *config.CreateCheckpoint {
+		cluster, err := c.get(ctx, &opt.GetOptions{})
+		assert.NoError(t, err)
+		return cluster.Checkpoint
+	}
+
+	c.checkpoint(ctx, "metasrv", nil)
+	c.checkpoint(ctx, "datanode", fmt.Errorf("datanode is not ready"))
+	assert.Equal(t, &config.CreateCheckpoint{
+		Completed: []string{"metasrv"},
+		Failed:    "datanode",
+		Error:     "datanode is not ready",
+	}, checkpoint())
+
+	// The failure is cleared once the failed component is started by the resumed creation.
+	c.checkpoint(ctx, "datanode", nil)
+	c.checkpoint(ctx, "metasrv", nil)
+	assert.Equal(t, &config.CreateCheckpoint{Completed: []string{"metasrv", "datanode"}}, checkpoint())
+	assert.True(t, checkpoint().IsCompleted("datanode"))
+	assert.False(t, checkpoint().IsCompleted("frontend"))
+
+	c.clearCheckpoint(ctx)
+	assert.Nil(t, checkpoint())
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// Scale starts or stops the replicas of one component of a running cluster, and records the
+// new replicas in the cluster metadata. The frontend, flownode, datanode and datanode groups
+// can be scaled, the new datanodes are waited to join the cluster through metasrv.
+func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "scale"); err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if !running {
+		return fmt.Errorf("cluster '%s' is not running", options.Name)
+	}
+	if cluster.Config.Cluster.Standalone != nil {
+		return fmt.Errorf("cluster '%s' runs in standalone mode and can't be scaled", options.Name)
+	}
+
+	replicas, datanode, err := scalableConfig(cluster.Config.Cluster, options.ComponentType)
+	if err != nil {
+		return err
+	}
+	oldReplicas, newReplicas := *replicas, int(options.NewReplicas)
+	options.OldReplicas = int32(oldReplicas)
+	if newReplicas == oldReplicas {
+		c.logger.V(0).Infof("Component '%s' of cluster '%s' already has %d replicas", options.ComponentType, options.Name, newReplicas)
+		return nil
+	}
+
+	// The node ids of datanode groups follow the previous datanodes by default, pin them
+	// before scaling, so the running datanode groups will not be assigned new node ids.
+	pinDatanodeGroupNodeIDs(cluster.Config.Cluster)
+	*replicas = newReplicas
+	if err = config.ValidateConfig(cluster.Config); err != nil {
+		return err
+	}
+	c.loadComponents(cluster)
+
+	component, err := c.component(options.ComponentType)
+	if err != nil {
+		return err
+	}
+	scalable, ok := component.(components.ScalableComponent)
+	if !ok {
+		return fmt.Errorf("component '%s' can't be scaled in bare-metal mode", component.Name())
+	}
+
+	c.logger.V(0).Infof("Scaling component '%s' of cluster '%s' from %d to %d...",
+		component.Name(), options.Name, oldReplicas, newReplicas)
+	if newReplicas > oldReplicas {
+		err = c.scaleUp(ctx, scalable, datanode, oldReplicas, newReplicas, options.UseGreptimeCNArtifacts)
+	} else {
+		err = c.scaleDown(ctx, scalable, datanode, newReplicas, oldReplicas)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = c.mm.UpdateClusterMetadata(cluster); err != nil {
+		return fmt.Errorf("failed to update the metadata of cluster '%s': %v", options.Name, err)
+	}
+	c.recordState(ctx)
+	c.logger.V(0).Infof("Component '%s' of cluster '%s' is scaled to %d replicas!", component.Name(), options.Name, newReplicas)
+
+	return nil
+}
+
+// scaleUp starts the replicas in [from, to), and waits for the new datanodes to join the cluster.
+func (c *Cluster) scaleUp(ctx context.Context, component components.ScalableComponent, datanode *config.Datanode,
+	from, to int, useGreptimeCNArtifacts bool) error {
+	var addrs []components.ListenAddr
+	for _, addr := range component.ListenAddrs() {
+		if isNewReplica(component.Name(), addr.Replica, from) {
+			addrs = append(addrs, addr)
+		}
+	}
+	if err := components.CheckPortConflicts(addrs); err != nil {
+		return err
+	}
+
+	binPath, err := c.greptimeBinary(ctx, useGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+	if err = component.StartReplicas(c.ctx, c.stop, binPath, from, to); err != nil {
+		return err
+	}
+	c.warnUnsupervised(component.Name())
+
+	if datanode != nil {
+		nodeIDs := datanodeIDs(datanode, from, to)
+		c.logger.V(0).Infof("Waiting for datanodes %v to join the cluster...", nodeIDs)
+		if err = components.WaitForDatanodes(ctx, c.config.Cluster.MetaSrv, nodeIDs, c.logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scaleDown stops the replicas in [from, to) gracefully. The regions on the stopped datanodes
+// are not migrated by gtctl, so it warns before stopping them.
+func (c *Cluster) scaleDown(ctx context.Context, component components.ScalableComponent, datanode *config.Datanode,
+	from, to int) error {
+	if datanode != nil {
+		c.logger.Warnf("The regions on datanodes %v are not migrated by gtctl, they should be migrated by "+
+			"'ADMIN migrate_region(...)' before scaling down.", datanodeIDs(datanode, from, to))
+		if c.config.Cluster.MetaSrv.EnableRegionFailover {
+			c.logger.Warnf("The remaining regions will be failed over to the other datanodes by metasrv.")
+		} else {
+			c.logger.Warnf("The remaining regions will be unavailable until the datanodes are scaled up again.")
+		}
+	}
+
+	// Each replica is killed if it doesn't exit in the drain timeout, see components.WorkingDirs.
+	return component.StopReplicas(ctx, from, to)
+}
+
+// scalableConfig returns the replicas in the config of component that can be scaled,
+// and the datanode config if the component is datanode or datanode group.
+func scalableConfig(cfg *config.BareMetalClusterComponentsConfig,
+	kind greptimedbclusterv1alpha1.ComponentKind) (*int, *config.Datanode, error) {
+	switch kind {
+	case greptimedbclusterv1alpha1.FrontendComponentKind:
+		return &cfg.Frontend.Replicas, nil, nil
+	case greptimedbclusterv1alpha1.DatanodeComponentKind:
+		return &cfg.Datanode.Replicas, cfg.Datanode, nil
+	case components.FlownodeComponentName:
+		if cfg.Flownode == nil {
+			return nil, nil, fmt.Errorf("flownode is not deployed in the cluster")
+		}
+		return &cfg.Flownode.Replicas, nil, nil
+	default:
+		for _, group := range cfg.DatanodeGroups {
+			if components.DatanodeGroupName(group.Name) == string(kind) {
+				return &group.Replicas, &group.Datanode, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("component '%s' can't be scaled in bare-metal mode", kind)
+	}
+}
+
+// datanodeIDs returns the node ids of the replicas in [from, to) of datanode.
+func datanodeIDs(datanode *config.Datanode, from, to int) []int {
+	var nodeIDs []int
+	for i := from; i < to; i++ {
+		nodeIDs = append(nodeIDs, datanode.NodeID+i)
+	}
+	return nodeIDs
+}
+
+// pinDatanodeGroupNodeIDs records the node id of each datanode group in its config.
+func pinDatanodeGroupNodeIDs(cfg *config.BareMetalClusterComponentsConfig) {
+	for i, nodeID := range cfg.DatanodeGroupNodeIDs() {
+		cfg.DatanodeGroups[i].NodeID = nodeID
+	}
+}
+
+// isNewReplica returns whether the replica of component is not less than from, e.g. "datanode.3" for from 3.
+func isNewReplica(name, replica string, from int) bool {
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(replica, name+"."), "%d", &index); err != nil {
+		return false
+	}
+	return index >= from
+}
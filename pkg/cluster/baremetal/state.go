@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// recordState records the runtime state of cluster in the cluster dir. The failure
+// of recording is only warned, since the cluster itself is not affected by it.
+func (c *Cluster) recordState(ctx context.Context) {
+	state, err := c.collectState(ctx)
+	if err == nil {
+		err = c.mm.WriteClusterState(state)
+	}
+	if err != nil {
+		c.logger.Warnf("failed to record the state of cluster: %v", err)
+	}
+}
+
+// collectState collects the runtime state of each replica of the cluster components.
+func (c *Cluster) collectState(ctx context.Context) (*config.BareMetalClusterState, error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	csd := c.mm.GetClusterScopeDirs()
+	state := &config.BareMetalClusterState{
+		Name:          filepath.Base(csd.BaseDir),
+		ClusterDir:    csd.BaseDir,
+		DataDir:       csd.DataDir,
+		LogsDir:       csd.LogsDir,
+		PidsDir:       csd.PidsDir,
+		ForegroundPid: cluster.ForegroundPid,
+		StartTime:     cluster.CreationDate,
+		UpdateTime:    time.Now(),
+		Artifacts: map[string]*config.Artifact{
+			artifacts.GreptimeBinName: c.config.Cluster.Artifact,
+		},
+	}
+	if c.useEmbeddedEtcd() {
+		state.Artifacts[artifacts.EtcdBinName] = c.config.Etcd.Artifact
+	}
+
+	binaries, dataDirs := componentPaths(c.config.Cluster, csd.DataDir)
+	for _, component := range c.orderedComponents() {
+		addrs := make(map[string]map[string]string)
+		for _, addr := range component.ListenAddrs() {
+			if addrs[addr.Replica] == nil {
+				addrs[addr.Replica] = make(map[string]string)
+			}
+			addrs[addr.Replica][addr.Arg] = addr.Addr
+		}
+
+		componentState := &config.ComponentState{Name: component.Name(), Binary: binaries[component.Name()]}
+		for _, status := range component.Status(ctx) {
+			replica := &config.ReplicaState{
+				Name:   status.Replica,
+				Pid:    status.Pid,
+				State:  string(status.State),
+				Addrs:  addrs[status.Replica],
+				LogDir: path.Join(csd.LogsDir, status.Replica),
+				PidDir: path.Join(csd.PidsDir, status.Replica),
+			}
+			if dataDir, ok := dataDirs[component.Name()]; ok {
+				replica.DataDir = path.Join(dataDir, status.Replica)
+			}
+			componentState.Replicas = append(componentState.Replicas, replica)
+		}
+		state.Components = append(state.Components, componentState)
+	}
+
+	return state, nil
+}
+
+// orderedComponents returns the deployed components of cluster in the order of starting.
+func (c *Cluster) orderedComponents() []components.ClusterComponent {
+	ordered := append([]components.ClusterComponent{c.cc.Standalone, c.cc.MetaSrv}, c.cc.datanodes()...)
+	ordered = append(ordered, c.cc.Flownode, c.cc.Frontend, c.cc.Kafka)
+	if c.useEmbeddedEtcd() {
+		ordered = append(ordered, c.cc.Etcd)
+	}
+
+	deployed := ordered[:0]
+	for _, component := range ordered {
+		if component != nil {
+			deployed = append(deployed, component)
+		}
+	}
+	return deployed
+}
+
+// componentPaths returns the binaries that override the greptime artifact, and the base data dirs
+// of the components that store data under the data dir of replica, both are keyed by component name.
+func componentPaths(cfg *config.BareMetalClusterComponentsConfig, dataDir string) (binaries, dataDirs map[string]string) {
+	binaries, dataDirs = make(map[string]string), map[string]string{
+		components.EtcdComponentName:  dataDir,
+		components.KafkaComponentName: dataDir,
+	}
+
+	datanodeDataDir := func(datanode *config.Datanode) string {
+		if len(datanode.DataDir) > 0 {
+			return datanode.DataDir
+		}
+		return dataDir
+	}
+
+	if standalone := cfg.Standalone; standalone != nil {
+		binaries[components.StandaloneComponentName] = standalone.BinaryPath
+		dataDirs[components.StandaloneComponentName] = dataDir
+		return binaries, dataDirs
+	}
+
+	datanodeName := string(greptimedbclusterv1alpha1.DatanodeComponentKind)
+	binaries[datanodeName] = cfg.Datanode.BinaryPath
+	dataDirs[datanodeName] = datanodeDataDir(cfg.Datanode)
+	for _, group := range cfg.DatanodeGroups {
+		binaries[components.DatanodeGroupName(group.Name)] = group.BinaryPath
+		dataDirs[components.DatanodeGroupName(group.Name)] = datanodeDataDir(&group.Datanode)
+	}
+	binaries[string(greptimedbclusterv1alpha1.FrontendComponentKind)] = cfg.Frontend.BinaryPath
+	binaries[components.MetaSrvComponentName] = cfg.MetaSrv.BinaryPath
+	if cfg.Flownode != nil {
+		binaries[components.FlownodeComponentName] = cfg.Flownode.BinaryPath
+	}
+
+	return binaries, dataDirs
+}
+
+// readState reads the runtime state of cluster, it returns nil if the state is not recorded,
+// e.g. the cluster is created by the previous versions of gtctl.
+func readState(statePath string) (*config.BareMetalClusterState, error) {
+	in, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state config.BareMetalClusterState
+	if err = yaml.Unmarshal(in, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
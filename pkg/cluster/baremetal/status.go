@@ -0,0 +1,254 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/term"
+)
+
+// DefaultStatusInterval is the default interval of refreshing the status in watch mode.
+const DefaultStatusInterval = 2 * time.Second
+
+// cpuSample is the cpu time of the process of replica sampled at some time, the cpu usage
+// of replica is calculated by the samples of two refreshes in watch mode.
+type cpuSample struct {
+	pid     int
+	cpuTime time.Duration
+	at      time.Time
+}
+
+// ReplicaStatusView is the status of one replica output in the json or yaml format by checking the status of cluster.
+// The uptime, cpu and memory usage are omitted if the replica is not running or they can't be collected.
+type ReplicaStatusView struct {
+	Replica       string                  `json:"replica" yaml:"replica"`
+	Host          string                  `json:"host,omitempty" yaml:"host,omitempty"`
+	Pid           int                     `json:"pid" yaml:"pid"`
+	State         string                  `json:"state" yaml:"state"`
+	UptimeSeconds int64                   `json:"uptimeSeconds,omitempty" yaml:"uptimeSeconds,omitempty"`
+	Restarts      int                     `json:"restarts" yaml:"restarts"`
+	Crashes       int                     `json:"crashes" yaml:"crashes"`
+	LastCrash     *components.CrashRecord `json:"lastCrash,omitempty" yaml:"lastCrash,omitempty"`
+	CPUPercent    *float64                `json:"cpuPercent,omitempty" yaml:"cpuPercent,omitempty"`
+	MemoryBytes   uint64                  `json:"memoryBytes,omitempty" yaml:"memoryBytes,omitempty"`
+	Endpoints     map[string]string       `json:"endpoints" yaml:"endpoints"`
+	Reason        string                  `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Status renders the status of each replica of cluster, which distinguishes the dead replicas
+// from the alive but unhealthy ones. It fails if any replica is not running. In watch mode, the
+// status is refreshed until the context is done, and it never fails on the replicas that are not running.
+func (c *Cluster) Status(ctx context.Context, options *opt.StatusOptions) error {
+	if !options.Watch {
+		bulk, views, failed, err := c.collectStatus(ctx, options.Name, nil)
+		if err != nil {
+			return err
+		}
+		if opt.IsMachineReadable(options.Output) {
+			if err = opt.RenderOutput(options.Writer, options.Output, views); err != nil {
+				return err
+			}
+		} else {
+			c.renderStatus(options.Table, bulk)
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("replicas of cluster '%s' are not running: %s", options.Name, strings.Join(failed, ", "))
+		}
+		return nil
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultStatusInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	samples := make(map[string]cpuSample)
+	for {
+		// The cluster is reloaded on each refresh, since it may have been scaled by the other gtctl processes.
+		bulk, _, _, err := c.collectStatus(ctx, options.Name, samples)
+		if err != nil {
+			return err
+		}
+
+		if options.Writer != nil {
+			term.ClearScreen(options.Writer)
+			fmt.Fprintf(options.Writer, "Every %s: status of cluster '%s'\t%s\n\n",
+				interval, options.Name, time.Now().Format(time.RFC1123))
+		}
+		c.renderStatus(options.Table, bulk)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectStatus collects the status of each replica of cluster as the rows of table and the views,
+// and the replicas that are not running. The cpu usage is calculated from the previous samples
+// if they are given, which are updated in place.
+func (c *Cluster) collectStatus(ctx context.Context, name string, samples map[string]cpuSample) (
+	bulk [][]string, views []*ReplicaStatusView, failed []string, err error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: name})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.loadComponents(cluster)
+	if c.isMultiHost() {
+		bulk, views, failed = c.collectRemoteStatus(ctx)
+		return bulk, views, failed, nil
+	}
+
+	csd := c.mm.GetClusterScopeDirs()
+	crashes, err := components.ReadCrashes(csd.CrashesDir)
+	if err != nil {
+		c.logger.V(3).Infof("failed to read the crashes of cluster '%s': %v", name, err)
+	}
+	crashesByReplica := make(map[string][]components.CrashRecord)
+	for _, crash := range crashes {
+		crashesByReplica[crash.Replica] = append(crashesByReplica[crash.Replica], crash)
+	}
+
+	for _, component := range c.orderedComponents() {
+		endpoints := make(map[string]map[string]string)
+		for _, addr := range component.ListenAddrs() {
+			if endpoints[addr.Replica] == nil {
+				endpoints[addr.Replica] = make(map[string]string)
+			}
+			endpoints[addr.Replica][addr.Arg] = addr.Addr
+		}
+
+		for _, status := range component.Status(ctx) {
+			restarts := collectRestartsForBareMetal(csd.PidsDir, status.Replica)
+			view := &ReplicaStatusView{
+				Replica:   status.Replica,
+				Pid:       status.Pid,
+				State:     string(status.State),
+				Endpoints: endpoints[status.Replica],
+				Reason:    status.Reason,
+				Crashes:   len(crashesByReplica[status.Replica]),
+			}
+			if view.Crashes > 0 {
+				view.LastCrash = &crashesByReplica[status.Replica][view.Crashes-1]
+				// The dead replica links to the crash of its last process.
+				if status.State == components.ReplicaStateDead && view.LastCrash.Pid == status.Pid {
+					view.Reason = fmt.Sprintf("%s, crash recorded in '%s'", view.Reason,
+						filepath.Join(csd.CrashesDir, view.LastCrash.Dir))
+				}
+			}
+			view.Restarts, _ = strconv.Atoi(strings.TrimSpace(restarts))
+			if view.Endpoints == nil {
+				view.Endpoints = make(map[string]string)
+			}
+
+			pid, uptime, cpu, memory := "N/A", "N/A", "N/A", "N/A"
+			if status.Pid > 0 {
+				pid = strconv.Itoa(status.Pid)
+			}
+			if status.State == components.ReplicaStateRunning || status.State == components.ReplicaStateUnhealthy {
+				if !status.StartTime.IsZero() {
+					elapsed := time.Since(status.StartTime).Round(time.Second)
+					uptime = elapsed.String()
+					view.UptimeSeconds = int64(elapsed.Seconds())
+				}
+				if stats, err := components.ReadProcessStats(status.Pid); err == nil {
+					percent, ok := cpuPercent(status, stats, samples)
+					if ok {
+						view.CPUPercent = &percent
+					}
+					cpu = formatCPU(percent, ok)
+					memory = formatBytes(stats.RSS)
+					view.MemoryBytes = stats.RSS
+				}
+			}
+
+			bulk = append(bulk, []string{status.Replica, pid, string(status.State), uptime, restarts,
+				strconv.Itoa(view.Crashes), cpu, memory, stateEndpoints(endpoints[status.Replica]), view.Reason})
+			views = append(views, view)
+			if status.State != components.ReplicaStateRunning {
+				failed = append(failed, fmt.Sprintf("%s (%s)", status.Replica, status.State))
+			}
+		}
+	}
+
+	return bulk, views, failed, nil
+}
+
+// renderStatus renders the status of replicas, the rows of previous rendering are cleared.
+func (c *Cluster) renderStatus(table *tablewriter.Table, bulk [][]string) {
+	table.ClearRows()
+	table.SetHeader([]string{"REPLICA", "PID", "STATE", "UPTIME", "RESTARTS", "CRASHES", "CPU", "MEMORY", "ENDPOINTS", "REASON"})
+	table.AppendBulk(bulk)
+	table.Render()
+}
+
+// formatCPU formats the cpu usage returned by cpuPercent, e.g. "12.5%".
+func formatCPU(percent float64, ok bool) string {
+	if !ok {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.1f%%", percent)
+}
+
+// cpuPercent returns the cpu usage of replica since the previous sample of the same process,
+// or the average cpu usage since the process was started if there is no such sample.
+func cpuPercent(status components.ReplicaStatus, stats *components.ProcessStats, samples map[string]cpuSample) (float64, bool) {
+	now := time.Now()
+	cpuTime, elapsed := stats.CPUTime, now.Sub(status.StartTime)
+	if prev, ok := samples[status.Replica]; ok && prev.pid == status.Pid {
+		cpuTime, elapsed = stats.CPUTime-prev.cpuTime, now.Sub(prev.at)
+	} else if status.StartTime.IsZero() {
+		elapsed = 0
+	}
+	if samples != nil {
+		samples[status.Replica] = cpuSample{pid: status.Pid, cpuTime: stats.CPUTime, at: now}
+	}
+
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(cpuTime) / float64(elapsed) * 100, true
+}
+
+// formatBytes formats the bytes in binary units, e.g. "1.5GiB".
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512B", formatBytes(512))
+	assert.Equal(t, "1.5KiB", formatBytes(1536))
+	assert.Equal(t, "100.0MiB", formatBytes(100*1024*1024))
+	assert.Equal(t, "2.0GiB", formatBytes(2*1024*1024*1024))
+}
+
+func TestCPUUsage(t *testing.T) {
+	status := components.ReplicaStatus{Replica: "frontend.0", Pid: 100, StartTime: time.Now().Add(-10 * time.Second)}
+	samples := make(map[string]cpuSample)
+
+	// The average usage since the process was started is used without the previous sample.
+	usage := formatCPU(cpuPercent(status, &components.ProcessStats{CPUTime: 5 * time.Second}, samples))
+	assert.Equal(t, "50.0%", usage)
+	assert.Equal(t, 5*time.Second, samples["frontend.0"].cpuTime)
+
+	// The usage since the previous sample of the same process.
+	samples["frontend.0"] = cpuSample{pid: 100, cpuTime: 5 * time.Second, at: time.Now().Add(-2 * time.Second)}
+	usage = formatCPU(cpuPercent(status, &components.ProcessStats{CPUTime: 7 * time.Second}, samples))
+	assert.Equal(t, "100.0%", usage)
+
+	// The sample of the restarted process is not used.
+	samples["frontend.0"] = cpuSample{pid: 99, cpuTime: 20 * time.Second, at: time.Now().Add(-time.Second)}
+	usage = formatCPU(cpuPercent(status, &components.ProcessStats{CPUTime: time.Second}, samples))
+	assert.Equal(t, "10.0%", usage)
+
+	// The usage is unknown without the start time.
+	usage = formatCPU(cpuPercent(components.ReplicaStatus{Replica: "frontend.1"}, &components.ProcessStats{}, nil))
+	assert.Equal(t, "N/A", usage)
+}
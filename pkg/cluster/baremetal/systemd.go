@@ -0,0 +1,327 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/systemd"
+)
+
+// ExportSystemd returns the systemd units of the replicas of cluster, so the topology created by gtctl can be
+// handed over to the hosts managed by systemd. Each replica runs in its own service with the same command line
+// as gtctl runs it, the services of each component are ordered after and depend on the ones of the components
+// it depends on, i.e. etcd → metasrv → datanode → flownode → frontend, and all of them are grouped by the target,
+// which is the first unit. The env of each replica is written to its environment file under the cluster dir, which
+// is only readable by the owner, so the secrets in it, e.g. the password of metadata store, are not in the units.
+func (c *Cluster) ExportSystemd(ctx context.Context, options *opt.ExportSystemdOptions) ([]*systemd.Unit, error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return nil, err
+	}
+	if err = notSupportedOnHosts(cluster, "export systemd"); err != nil {
+		return nil, err
+	}
+	c.loadComponents(cluster)
+
+	// The commands of replicas are recorded in dry-run mode, the files they use have been generated on creating.
+	csd := c.mm.GetClusterScopeDirs()
+	dryRun := &components.DryRun{}
+	c.cc = NewClusterComponents(c.config.Cluster, components.WorkingDirs{
+		DataDir: csd.DataDir,
+		LogsDir: csd.LogsDir,
+		PidsDir: csd.PidsDir,
+		DryRun:  dryRun,
+	}, &c.wg, c.logger, c.useMemoryMeta)
+
+	greptime, err := c.greptimeBinary(ctx, options.UseGreptimeCNArtifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("greptimedb-%s", options.Name)
+	target := &systemd.Unit{
+		Name:        systemd.TargetName(prefix),
+		Description: fmt.Sprintf("GreptimeDB cluster '%s'", options.Name),
+		WantedBy:    []string{"multi-user.target"},
+	}
+	units := []*systemd.Unit{target}
+
+	resources, restarts := componentSettings(c.config.Cluster)
+	var previous []string
+	for _, tier := range c.dependencyTiers() {
+		var current []string
+		for _, component := range tier {
+			binary := greptime
+			switch component.Name() {
+			case components.EtcdComponentName:
+				if binary, err = c.etcdBinary(ctx, options.UseGreptimeCNArtifacts); err != nil {
+					return nil, err
+				}
+			case components.KafkaComponentName:
+				binary = path.Join(c.config.Cluster.WAL.Kafka.Embedded.Home, "bin", "kafka-server-start.sh")
+			}
+
+			recorded := len(dryRun.Commands)
+			if err = component.Start(c.ctx, c.stop, binary); err != nil {
+				return nil, err
+			}
+
+			for _, service := range componentServices(dryRun.Commands[recorded:]) {
+				service.User = options.User
+				service.WorkingDirectory = csd.BaseDir
+				service.StandardOutput = "append:" + components.ReplicaLogFile(csd.LogsDir, service.replica)
+				service.StandardError = "inherit"
+				service.TimeoutStopSec = int(c.drainTimeout.Seconds())
+				applyRestart(service.Service, restarts[component.Name()])
+				applyResources(service.Service, resources[component.Name()])
+				if err = writeEnvironmentFile(service, path.Join(csd.BaseDir, systemdEnvDir)); err != nil {
+					return nil, err
+				}
+
+				name := systemd.ServiceName(prefix, service.replica)
+				units = append(units, &systemd.Unit{
+					Name:        name,
+					Description: fmt.Sprintf("GreptimeDB cluster '%s' replica '%s'", options.Name, service.replica),
+					After:       previous,
+					Requires:    previous,
+					PartOf:      []string{target.Name},
+					Service:     service.Service,
+					WantedBy:    []string{target.Name},
+				})
+				current = append(current, name)
+			}
+		}
+		target.Wants = append(target.Wants, current...)
+		previous = current
+	}
+
+	return units, nil
+}
+
+// dependencyTiers returns the deployed components grouped by their dependencies, the components of
+// each tier depend on the ones of the previous tier.
+func (c *Cluster) dependencyTiers() [][]components.ClusterComponent {
+	if c.cc.Standalone != nil {
+		return [][]components.ClusterComponent{{c.cc.Standalone}}
+	}
+
+	var tiers [][]components.ClusterComponent
+	var storages []components.ClusterComponent
+	if c.useEmbeddedEtcd() {
+		storages = append(storages, c.cc.Etcd)
+	}
+	if c.cc.Kafka != nil {
+		storages = append(storages, c.cc.Kafka)
+	}
+	if len(storages) > 0 {
+		tiers = append(tiers, storages)
+	}
+
+	return append(tiers, c.clusterTiers()...)
+}
+
+// clusterTiers returns the dependency tiers of the distributed components without the etcd and kafka.
+func (c *Cluster) clusterTiers() [][]components.ClusterComponent {
+	tiers := [][]components.ClusterComponent{{c.cc.MetaSrv}, c.cc.datanodes()}
+	if c.cc.Flownode != nil {
+		tiers = append(tiers, []components.ClusterComponent{c.cc.Flownode})
+	}
+	return append(tiers, []components.ClusterComponent{c.cc.Frontend})
+}
+
+// systemdEnvDir is the dir of the environment files of the exported services under the cluster dir.
+const systemdEnvDir = "systemd"
+
+// replicaService is the service of one replica.
+type replicaService struct {
+	*systemd.Service
+	replica string
+}
+
+// componentServices converts the recorded commands of one component to the services of its replicas. The hooks
+// run before and after each replica starts, and the commands run before the replica with the same name, e.g. the
+// formatting of storage of kafka, run before it starts. Like the replica, they run with the env of service, so
+// only the env that differs from it is set by "env".
+func componentServices(commands []components.DryRunCommand) []*replicaService {
+	var preStart, postStart []components.DryRunCommand
+	var services []*replicaService
+	byReplica := make(map[string]*replicaService)
+	earlier := make(map[string][]components.DryRunCommand)
+	for _, command := range commands {
+		switch {
+		case strings.HasSuffix(command.Name, fmt.Sprintf(" %s hook", components.HookPreStart)):
+			preStart = append(preStart, command)
+		case strings.HasSuffix(command.Name, fmt.Sprintf(" %s hook", components.HookPostStart)):
+			postStart = append(postStart, command)
+		default:
+			service, ok := byReplica[command.Name]
+			if !ok {
+				service = &replicaService{Service: &systemd.Service{}, replica: command.Name}
+				byReplica[command.Name] = service
+				services = append(services, service)
+			} else {
+				earlier[command.Name] = append(earlier[command.Name], components.DryRunCommand{
+					Env: service.Environment, Argv: service.ExecStart})
+			}
+			service.Environment, service.ExecStart = command.Env, command.Argv
+		}
+	}
+
+	for _, service := range services {
+		for _, command := range append(append([]components.DryRunCommand{}, preStart...), earlier[service.replica]...) {
+			service.ExecStartPre = append(service.ExecStartPre, withEnv(command, service.Environment))
+		}
+		for _, command := range postStart {
+			service.ExecStartPost = append(service.ExecStartPost, withEnv(command, service.Environment))
+		}
+	}
+	return services
+}
+
+// withEnv returns the argv of command that runs with its env by "env", the env that is
+// the same as the one of service is omitted.
+func withEnv(command components.DryRunCommand, serviceEnv map[string]string) []string {
+	keys := make([]string, 0, len(command.Env))
+	for k, v := range command.Env {
+		if value, ok := serviceEnv[k]; !ok || value != v {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return command.Argv
+	}
+	sort.Strings(keys)
+
+	argv := []string{"env"}
+	for _, k := range keys {
+		argv = append(argv, fmt.Sprintf("%s=%s", k, command.Env[k]))
+	}
+	return append(argv, command.Argv...)
+}
+
+// writeEnvironmentFile moves the env of service to its environment file under dir, which is only readable by the owner.
+func writeEnvironmentFile(service *replicaService, dir string) error {
+	if len(service.Environment) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	envFile := path.Join(dir, fmt.Sprintf("%s.env", service.replica))
+	if err := os.WriteFile(envFile, systemd.RenderEnvironmentFile(service.Environment), 0600); err != nil {
+		return err
+	}
+	// The existing file keeps its mode on writing.
+	if err := os.Chmod(envFile, 0600); err != nil {
+		return err
+	}
+
+	service.EnvironmentFile, service.Environment = envFile, nil
+	return nil
+}
+
+// componentSettings returns the resource limits and the restart policies of the components, keyed by component name.
+func componentSettings(cfg *config.BareMetalClusterComponentsConfig) (map[string]*config.Resources, map[string]*config.Restart) {
+	resources, restarts := make(map[string]*config.Resources), make(map[string]*config.Restart)
+	if standalone := cfg.Standalone; standalone != nil {
+		resources[components.StandaloneComponentName], restarts[components.StandaloneComponentName] =
+			standalone.Resources, standalone.Restart
+		return resources, restarts
+	}
+
+	datanodeName := string(greptimedbclusterv1alpha1.DatanodeComponentKind)
+	resources[datanodeName], restarts[datanodeName] = cfg.Datanode.Resources, cfg.Datanode.Restart
+	for _, group := range cfg.DatanodeGroups {
+		name := components.DatanodeGroupName(group.Name)
+		resources[name], restarts[name] = group.Resources, group.Restart
+	}
+	frontendName := string(greptimedbclusterv1alpha1.FrontendComponentKind)
+	resources[frontendName], restarts[frontendName] = cfg.Frontend.Resources, cfg.Frontend.Restart
+	resources[components.MetaSrvComponentName], restarts[components.MetaSrvComponentName] =
+		cfg.MetaSrv.Resources, cfg.MetaSrv.Restart
+	if cfg.Flownode != nil {
+		resources[components.FlownodeComponentName], restarts[components.FlownodeComponentName] =
+			cfg.Flownode.Resources, cfg.Flownode.Restart
+	}
+
+	return resources, restarts
+}
+
+// applyRestart applies the restart policy of component to the service. The replicas are restarted
+// on failure by default, since the replicas managed by systemd are expected to be long-lived.
+func applyRestart(service *systemd.Service, restart *config.Restart) {
+	service.Restart = config.RestartPolicyOnFailure
+	if restart == nil {
+		return
+	}
+
+	switch restart.Policy {
+	case config.RestartPolicyNever:
+		service.Restart = "no"
+	case config.RestartPolicyAlways:
+		service.Restart = config.RestartPolicyAlways
+	}
+	service.RestartSec = int(restart.InitialBackoff.Seconds())
+}
+
+// applyResources applies the resource limits of component to the service, the cgroup of
+// replica is managed by systemd instead of the parent group.
+func applyResources(service *systemd.Service, resources *config.Resources) {
+	if resources == nil {
+		return
+	}
+
+	service.LimitNOFILE = resources.MaxOpenFiles
+	service.Nice = resources.Nice
+	if cgroup := resources.Cgroup; cgroup != nil {
+		service.MemoryMax = cgroup.MemoryMax
+		service.CPUQuota = cpuQuota(cgroup.CPUMax)
+	}
+}
+
+// cpuQuota converts the "cpu.max" of cgroup to the CPUQuota of systemd, e.g. "200%" for "200000 100000".
+func cpuQuota(cpuMax string) string {
+	fields := strings.Fields(cpuMax)
+	if len(fields) == 0 || fields[0] == "max" {
+		return ""
+	}
+
+	quota, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return ""
+	}
+	period := 100000
+	if len(fields) > 1 {
+		if period, err = strconv.Atoi(fields[1]); err != nil || period <= 0 {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%d%%", quota*100/period)
+}
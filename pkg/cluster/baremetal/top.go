@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/term"
+)
+
+// Top renders the cpu, memory and open files of the process, and the disk usage of the data dir of each
+// replica of cluster. They are refreshed by the interval until the context is done unless Once is set,
+// and the cpu usage is the average since the process was started for the first rendering.
+func (c *Cluster) Top(ctx context.Context, options *opt.TopOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "top"); err != nil {
+		return err
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultStatusInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	samples := make(map[string]cpuSample)
+	for {
+		// The cluster is reloaded on each refresh, since it may have been scaled by the other gtctl processes.
+		bulk, err := c.collectTop(ctx, options.Name, samples)
+		if err != nil {
+			return err
+		}
+
+		if !options.Once && options.Writer != nil {
+			term.ClearScreen(options.Writer)
+			fmt.Fprintf(options.Writer, "Every %s: resource usage of cluster '%s'\t%s\n\n",
+				interval, options.Name, time.Now().Format(time.RFC1123))
+		}
+		c.renderTop(options.Table, bulk)
+		if options.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectTop collects the resource usage of each replica of cluster as the rows of table, the cpu usage
+// is calculated from the previous samples, which are updated in place.
+func (c *Cluster) collectTop(ctx context.Context, name string, samples map[string]cpuSample) ([][]string, error) {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	c.loadComponents(cluster)
+
+	var bulk [][]string
+	_, dataDirs := componentPaths(c.config.Cluster, c.mm.GetClusterScopeDirs().DataDir)
+	for _, component := range c.orderedComponents() {
+		for _, status := range component.Status(ctx) {
+			pid, cpu, memory, files, disk := "N/A", "N/A", "N/A", "N/A", "N/A"
+			if status.Pid > 0 {
+				pid = strconv.Itoa(status.Pid)
+			}
+			if status.State == components.ReplicaStateRunning || status.State == components.ReplicaStateUnhealthy {
+				if stats, err := components.ReadProcessStats(status.Pid); err == nil {
+					cpu = formatCPU(cpuPercent(status, stats, samples))
+					memory = formatBytes(stats.RSS)
+				}
+				if n, err := components.ReadOpenFiles(status.Pid); err == nil {
+					files = strconv.Itoa(n)
+				}
+			}
+			if dataDir, ok := dataDirs[component.Name()]; ok {
+				if size, err := file.DirSize(path.Join(dataDir, status.Replica)); err == nil {
+					disk = formatBytes(uint64(size))
+				}
+			}
+
+			bulk = append(bulk, []string{status.Replica, pid, string(status.State), cpu, memory, files, disk})
+		}
+	}
+
+	return bulk, nil
+}
+
+// renderTop renders the resource usage of replicas, the rows of previous rendering are cleared.
+func (c *Cluster) renderTop(table *tablewriter.Table, bulk [][]string) {
+	table.ClearRows()
+	table.SetHeader([]string{"REPLICA", "PID", "STATE", "CPU", "MEMORY", "OPEN FILES", "DISK"})
+	table.AppendBulk(bulk)
+	table.Render()
+}
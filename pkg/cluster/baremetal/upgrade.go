@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+)
+
+// upgradeStep restarts one replica of component with the new binary during the rolling upgrade.
+type upgradeStep struct {
+	component components.ClusterComponent
+	replica   int
+}
+
+// Upgrade replaces the greptime binary of a running cluster by restarting the replicas one at a time
+// in the order of metasrv, datanodes, flownode and frontend. The cluster is verified to be healthy
+// after each replica is restarted, and the restarted replicas are rolled back to the previous binary
+// if any replica fails to be upgraded.
+func (c *Cluster) Upgrade(ctx context.Context, options *opt.UpgradeOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+	if err = notSupportedOnHosts(cluster, "upgrade"); err != nil {
+		return err
+	}
+
+	running, err := c.isClusterAlive(cluster)
+	if err != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, err)
+	}
+	if !running {
+		return fmt.Errorf("cluster '%s' is not running", options.Name)
+	}
+
+	c.loadComponents(cluster)
+
+	previous := c.config.Cluster.Artifact
+	if len(previous.Local) == 0 && previous.Version == options.GreptimeVersion {
+		c.logger.V(0).Infof("Cluster '%s' is already running greptime '%s'", options.Name, options.GreptimeVersion)
+		return nil
+	}
+
+	oldBinary, err := c.greptimeBinary(ctx, options.UseGreptimeCNArtifacts)
+	if err != nil {
+		return err
+	}
+	c.config.Cluster.Artifact = &config.Artifact{Version: options.GreptimeVersion}
+	newBinary, err := c.greptimeBinary(ctx, options.UseGreptimeCNArtifacts)
+	if err != nil {
+		c.config.Cluster.Artifact = previous
+		return err
+	}
+
+	steps := c.upgradeSteps(ctx)
+	c.warnUnsupervised(upgradedComponents(steps)...)
+	for i, step := range steps {
+		c.logger.V(0).Infof("Upgrading replica %d of '%s' to greptime '%s'...",
+			step.replica, step.component.Name(), options.GreptimeVersion)
+		if err = c.upgradeReplica(ctx, step, newBinary); err == nil {
+			err = c.verifyHealth(ctx)
+		}
+		if err != nil {
+			c.logger.Warnf("Failed to upgrade replica %d of '%s': %v, rolling back...", step.replica, step.component.Name(), err)
+			c.rollback(ctx, steps[:i+1], oldBinary)
+			c.config.Cluster.Artifact = previous
+			return fmt.Errorf("failed to upgrade cluster '%s' to greptime '%s': %v", options.Name, options.GreptimeVersion, err)
+		}
+	}
+
+	if err = c.mm.UpdateClusterMetadata(cluster); err != nil {
+		return fmt.Errorf("failed to update the metadata of cluster '%s': %v", options.Name, err)
+	}
+	c.recordState(ctx)
+	c.logger.V(0).Infof("Cluster '%s' is upgraded to greptime '%s'!", options.Name, options.GreptimeVersion)
+	c.events.Publish(&events.Event{
+		Type:       events.TypeUpgradeCompleted,
+		Message:    fmt.Sprintf("upgraded to greptime '%s'", options.GreptimeVersion),
+		Attributes: map[string]string{"version": options.GreptimeVersion},
+	})
+
+	return nil
+}
+
+// upgradeSteps returns the replicas to be restarted in order. The components whose binaries
+// are overridden in the config are skipped, since they are not affected by the greptime artifact.
+func (c *Cluster) upgradeSteps(ctx context.Context) []upgradeStep {
+	ordered := append([]components.ClusterComponent{c.cc.Standalone, c.cc.MetaSrv}, c.cc.datanodes()...)
+	ordered = append(ordered, c.cc.Flownode, c.cc.Frontend)
+
+	binaries, _ := componentPaths(c.config.Cluster, "")
+	var steps []upgradeStep
+	for _, component := range ordered {
+		if component == nil {
+			continue
+		}
+		if len(binaries[component.Name()]) > 0 {
+			c.logger.Warnf("Component '%s' is skipped since it runs binary '%s'", component.Name(), binaries[component.Name()])
+			continue
+		}
+		for replica := range component.Status(ctx) {
+			steps = append(steps, upgradeStep{component: component, replica: replica})
+		}
+	}
+	return steps
+}
+
+// upgradedComponents returns the names of the components that are upgraded by the steps.
+func upgradedComponents(steps []upgradeStep) []string {
+	var names []string
+	for i, step := range steps {
+		if i == 0 || steps[i-1].component != step.component {
+			names = append(names, step.component.Name())
+		}
+	}
+	return names
+}
+
+// upgradeReplica stops the replica of step and starts it with the binary.
+func (c *Cluster) upgradeReplica(ctx context.Context, step upgradeStep, binary string) error {
+	scalable, ok := step.component.(components.ScalableComponent)
+	if !ok {
+		// The component has only one replica, e.g. the standalone.
+		if err := step.component.Stop(ctx); err != nil {
+			return err
+		}
+		return step.component.Start(c.ctx, c.stop, binary)
+	}
+
+	if err := scalable.StopReplicas(ctx, step.replica, step.replica+1); err != nil {
+		return err
+	}
+	return scalable.StartReplicas(c.ctx, c.stop, binary, step.replica, step.replica+1)
+}
+
+// verifyHealth checks whether all the components of cluster are healthy.
+func (c *Cluster) verifyHealth(ctx context.Context) error {
+	for _, component := range c.orderedComponents() {
+		if !component.IsRunning(ctx) {
+			return fmt.Errorf("component '%s' is not healthy", component.Name())
+		}
+	}
+	return nil
+}
+
+// rollback restarts the replicas of steps with the previous binary in the reverse order.
+// It keeps on rolling back the others if any replica fails to be rolled back.
+func (c *Cluster) rollback(ctx context.Context, steps []upgradeStep, binary string) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if err := c.upgradeReplica(ctx, step, binary); err != nil {
+			c.logger.Warnf("Failed to roll back replica %d of '%s': %v", step.replica, step.component.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestUpgradeSteps(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.MetaSrv.Replicas = 1
+	cfg.Cluster.Datanode.Replicas = 2
+	cfg.Cluster.Frontend.Replicas = 1
+	cfg.Cluster.Flownode = &config.Flownode{Replicas: 1, BinaryPath: "/usr/local/bin/greptime"}
+
+	l := logger.New(io.Discard, 0)
+	c := &Cluster{
+		config: cfg,
+		cc:     NewClusterComponents(cfg.Cluster, components.WorkingDirs{PidsDir: t.TempDir()}, nil, l, false),
+		logger: l,
+	}
+
+	var steps []string
+	upgradeSteps := c.upgradeSteps(context.Background())
+	for _, step := range upgradeSteps {
+		steps = append(steps, fmt.Sprintf("%s.%d", step.component.Name(), step.replica))
+	}
+
+	// The flownode is skipped since its binary is overridden.
+	assert.Equal(t, []string{"metasrv.0", "datanode.0", "datanode.1", "frontend.0"}, steps)
+	assert.Equal(t, []string{"metasrv", "datanode", "frontend"}, upgradedComponents(upgradeSteps))
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+)
+
+// verify waits for the frontend, or the standalone, to serve SQL through its MySQL and HTTP endpoints
+// before the creation succeeds. The first running replica is verified, or the first replica on the hosts.
+func (c *Cluster) verify(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Verify == nil || c.dryRun != nil {
+		return nil
+	}
+
+	var addrs connector.VerifyAddrs
+	if c.isMultiHost() {
+		addrs = remoteVerifyAddrs(c.cc.Frontend)
+	} else {
+		// The endpoint that is not configured is skipped.
+		addrs.MySQL, _ = c.connectAddr(ctx, connectArgs[opt.MySQL])
+		addrs.HTTP, _ = c.connectAddr(ctx, connectArgs[opt.HTTP])
+	}
+
+	spinner := options.Spinner
+	if spinner != nil {
+		spinner.Start("Verifying GreptimeDB serves SQL...")
+	}
+	if err := connector.Verify(ctx, addrs, options.Verify, c.logger); err != nil {
+		if spinner != nil {
+			spinner.Stop(false, "Verifying GreptimeDB serves SQL failed")
+		}
+		return fmt.Errorf("error verifying cluster '%s': %v", options.Name, err)
+	}
+	if spinner != nil {
+		spinner.Stop(true, "Verifying GreptimeDB serves SQL successfully 🎉")
+	}
+	return nil
+}
+
+// remoteVerifyAddrs returns the MySQL and HTTP addresses of the first replica of frontend on the hosts.
+func remoteVerifyAddrs(frontend components.ClusterComponent) connector.VerifyAddrs {
+	var addrs connector.VerifyAddrs
+	for _, addr := range frontend.ListenAddrs() {
+		var target *string
+		switch addr.Arg {
+		case connectArgs[opt.MySQL]:
+			target = &addrs.MySQL
+		case connectArgs[opt.HTTP]:
+			target = &addrs.HTTP
+		default:
+			continue
+		}
+		if len(*target) > 0 {
+			continue
+		}
+		if verifyAddr, err := components.HealthCheckAddr(addr.Addr, ""); err == nil {
+			*target = verifyAddr
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+const (
+	docker = "docker"
+
+	// ComposeDir is the directory under the working directory of gtctl that
+	// the compose files of clusters are stored in, one directory per cluster.
+	ComposeDir = "docker"
+
+	// ComposeFileName is the file name of the compose file of cluster.
+	ComposeFileName = "docker-compose.yaml"
+)
+
+// Cluster runs the components of GreptimeDB cluster in the docker containers by docker compose,
+// the cluster is described by the same config as bare-metal mode.
+type Cluster struct {
+	config        *config.BareMetalClusterConfig
+	imageRegistry string
+	useMemoryMeta bool
+
+	// dryRun writes the compose file to the writer instead of running it.
+	dryRun bool
+	writer io.Writer
+
+	mm     metadata.Manager
+	logger logger.Logger
+}
+
+var _ opt.Deployer = &Cluster{}
+
+type Option func(cluster *Cluster)
+
+// WithReplaceConfig replaces the default cluster config with given config.
+func WithReplaceConfig(cfg *config.BareMetalClusterConfig) Option {
+	return func(c *Cluster) {
+		c.config = cfg
+	}
+}
+
+// WithImageRegistry pulls the images of GreptimeDB from the registry.
+func WithImageRegistry(registry string) Option {
+	return func(c *Cluster) {
+		c.imageRegistry = registry
+	}
+}
+
+func WithMetastore(useMemoryMeta bool) Option {
+	return func(c *Cluster) {
+		c.useMemoryMeta = useMemoryMeta
+	}
+}
+
+// WithDryRun writes the compose file to the writer, which is stdout if it's nil, instead of running it.
+func WithDryRun(dryRun bool, writer io.Writer) Option {
+	return func(c *Cluster) {
+		c.dryRun = dryRun
+		c.writer = writer
+	}
+}
+
+func NewCluster(l logger.Logger, opts ...Option) (opt.Deployer, error) {
+	mm, err := metadata.New("")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cluster{
+		config: config.DefaultBareMetalConfig(),
+		mm:     mm,
+		logger: l,
+		writer: os.Stdout,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.writer == nil {
+		c.writer = os.Stdout
+	}
+
+	return c, nil
+}
+
+// composeFilePath returns the path of the compose file of the cluster named name.
+func (c *Cluster) composeFilePath(name string) string {
+	return filepath.Join(c.mm.GetWorkingDir(), ComposeDir, name, ComposeFileName)
+}
+
+// compose runs the docker compose command on the project of the cluster named name.
+func (c *Cluster) compose(ctx context.Context, name string, stdout io.Writer, args ...string) error {
+	if _, err := exec.LookPath(docker); err != nil {
+		return fmt.Errorf("'%s' is required to run the cluster in docker mode: %v", docker, err)
+	}
+
+	args = append([]string{"compose", "--project-name", ProjectName(name), "--file", c.composeFilePath(name)}, args...)
+	c.logger.V(3).Infof("Running '%s %s'", docker, strings.Join(args, " "))
+
+	cmd := exec.CommandContext(ctx, docker, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running '%s compose %s': %v", docker, args[5], err)
+	}
+	return nil
+}
+
+// exists returns whether the compose file of the cluster named name exists.
+func (c *Cluster) exists(name string) (bool, error) {
+	_, err := os.Stat(c.composeFilePath(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *Cluster) Get(ctx context.Context, options *opt.GetOptions) error {
+	return notSupported("get, use 'gtctl cluster status --docker' instead")
+}
+
+func (c *Cluster) List(ctx context.Context, options *opt.ListOptions) error {
+	return notSupported("list")
+}
+
+func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
+	return notSupported("scale")
+}
+
+func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) error {
+	return notSupported("connect")
+}
+
+func (c *Cluster) Restart(ctx context.Context, options *opt.RestartOptions) error {
+	return notSupported("restart")
+}
+
+func (c *Cluster) Exec(ctx context.Context, options *opt.ExecOptions) error {
+	return notSupported("exec")
+}
+
+func (c *Cluster) Upgrade(ctx context.Context, options *opt.UpgradeOptions) error {
+	return notSupported("upgrade")
+}
+
+func notSupported(operation string) error {
+	return opt.NotSupportedError(operation, opt.ModeDocker)
+}
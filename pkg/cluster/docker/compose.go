@@ -0,0 +1,496 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	// DefaultImage is the image of GreptimeDB that all the components run.
+	DefaultImage = "greptime/greptimedb"
+
+	// DefaultEtcdImage is the image of etcd that metasrv stores the metadata in.
+	DefaultEtcdImage = "quay.io/coreos/etcd"
+
+	etcdServiceName = components.EtcdComponentName
+	etcdClientPort  = "2379"
+	etcdDataDir     = "/etcd-data"
+
+	// dataDir and configDir are the directories of data and config files in the containers.
+	dataDir   = "/greptimedb/data"
+	configDir = "/etc/greptimedb"
+
+	restartPolicy = "on-failure"
+
+	datanodeName   = string(greptimedbclusterv1alpha1.DatanodeComponentKind)
+	frontendName   = string(greptimedbclusterv1alpha1.FrontendComponentKind)
+	standaloneName = "standalone"
+)
+
+// ComposeFile is the docker compose file that runs a GreptimeDB cluster, see https://docs.docker.com/compose/compose-file.
+type ComposeFile struct {
+	Name     string              `yaml:"name"`
+	Services map[string]*Service `yaml:"services"`
+	Volumes  map[string]*Volume  `yaml:"volumes,omitempty"`
+}
+
+// Service is one container of the compose file, each replica of components runs in its own container.
+type Service struct {
+	Image       string            `yaml:"image"`
+	Command     []string          `yaml:"command"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Ports       []string          `yaml:"ports,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	DependsOn   []string          `yaml:"depends_on,omitempty"`
+	Restart     string            `yaml:"restart,omitempty"`
+}
+
+// Volume is the named volume that keeps the data of containers.
+type Volume struct{}
+
+// ProjectName returns the compose project name of the cluster named name.
+func ProjectName(name string) string {
+	return "gtctl-" + strings.ToLower(name)
+}
+
+// NewComposeFile renders the compose file of the cluster named name from the same cluster config as bare-metal mode.
+// The containers reach each other by the service names, and only the ports of frontends, or standalone, are published
+// to the host by the listen addresses in config, so the cluster is accessed in the same way as on bare-metal.
+// The settings that only make sense for the processes on the host, e.g. readiness, resources and hooks, are ignored.
+func NewComposeFile(name string, cfg *config.BareMetalClusterConfig, imageRegistry string, useMemoryMeta bool) (*ComposeFile, error) {
+	cluster := cfg.Cluster
+	if err := validateConfig(cluster); err != nil {
+		return nil, err
+	}
+
+	image := DefaultImage
+	if len(imageRegistry) > 0 {
+		image = strings.TrimSuffix(imageRegistry, "/") + "/" + image
+	}
+	version := cluster.Artifact.Version
+	if len(version) == 0 {
+		version = artifacts.LatestVersionTag
+	}
+
+	b := &composeBuilder{
+		compose: &ComposeFile{
+			Name:     ProjectName(name),
+			Services: make(map[string]*Service),
+			Volumes:  make(map[string]*Volume),
+		},
+		image:      fmt.Sprintf("%s:%s", image, version),
+		portOffset: cluster.PortOffset,
+	}
+
+	if cluster.Standalone != nil {
+		standaloneEnv := mergeEnv(cluster.Env, components.LogFormatEnv(cluster.LogFormat, standaloneName))
+		if err := b.addStandalone(cluster.Standalone, standaloneEnv); err != nil {
+			return nil, err
+		}
+		return b.compose, nil
+	}
+
+	storeAddr := cluster.MetaSrv.StoreAddr
+	if !useMemoryMeta && cluster.MetaSrv.Backend == config.MetaSrvBackendEmbeddedEtcd {
+		b.addEtcd(cfg.Etcd)
+		storeAddr = net.JoinHostPort(etcdServiceName, etcdClientPort)
+	}
+
+	metaSrvAddrs, err := b.addMetaSrv(cluster, storeAddr, useMemoryMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	datanodeEnv := mergeEnv(mergeEnv(mergeEnv(cluster.Env, components.WALEnv(cluster.WAL, datanodeName)),
+		components.HeartbeatEnv(cluster.MetaSrv.Heartbeat, datanodeName)),
+		components.LogFormatEnv(cluster.LogFormat, datanodeName))
+	if err = b.addDatanode(cluster.Datanode, metaSrvAddrs, mergeEnv(datanodeEnv, cluster.Datanode.Env)); err != nil {
+		return nil, err
+	}
+
+	if cluster.Flownode != nil {
+		flownodeEnv := mergeEnv(mergeEnv(cluster.Env, components.HeartbeatEnv(cluster.MetaSrv.Heartbeat, components.FlownodeComponentName)),
+			components.LogFormatEnv(cluster.LogFormat, components.FlownodeComponentName))
+		if err = b.addFlownode(cluster.Flownode, metaSrvAddrs, mergeEnv(flownodeEnv, cluster.Flownode.Env)); err != nil {
+			return nil, err
+		}
+	}
+
+	frontendEnv := mergeEnv(mergeEnv(cluster.Env, components.HeartbeatEnv(cluster.MetaSrv.Heartbeat, frontendName)),
+		components.LogFormatEnv(cluster.LogFormat, frontendName))
+	if err = b.addFrontend(cluster.Frontend, metaSrvAddrs, mergeEnv(frontendEnv, cluster.Frontend.Env)); err != nil {
+		return nil, err
+	}
+
+	return b.compose, nil
+}
+
+// validateConfig rejects the settings that can't be applied to the containers.
+func validateConfig(cluster *config.BareMetalClusterComponentsConfig) error {
+	if len(cluster.Artifact.Local) > 0 {
+		return fmt.Errorf("the local greptime binary is not supported in docker mode, set the version of image instead")
+	}
+	if strings.HasPrefix(cluster.Artifact.Version, artifacts.DevBuildVersionPrefix) {
+		return fmt.Errorf("the build of greptime is not supported in docker mode, set the version of image instead")
+	}
+	if len(cluster.DatanodeGroups) > 0 {
+		return fmt.Errorf("the datanode groups are not supported in docker mode")
+	}
+	if cluster.WAL != nil && cluster.WAL.Kafka != nil && cluster.WAL.Kafka.Embedded != nil {
+		return fmt.Errorf("the embedded kafka WAL is not supported in docker mode, set the broker endpoints of kafka instead")
+	}
+	if cluster.Standalone != nil {
+		if len(cluster.Standalone.BinaryPath) > 0 {
+			return fmt.Errorf("the binary path of standalone is not supported in docker mode")
+		}
+		return nil
+	}
+	if cluster.MetaSrv.BackendStorage != nil {
+		return fmt.Errorf("the backend storage of metasrv is not supported in docker mode")
+	}
+	if cluster.Datanode.Storage != nil {
+		return fmt.Errorf("the object storage of datanode is not supported in docker mode, set it in the config file of datanode instead")
+	}
+	if cluster.Frontend.TLS != nil {
+		return fmt.Errorf("the TLS of frontend is not supported in docker mode, set it in the config file of frontend instead")
+	}
+	for component, binaryPath := range map[string]string{
+		components.MetaSrvComponentName: cluster.MetaSrv.BinaryPath,
+		datanodeName:                    cluster.Datanode.BinaryPath,
+		frontendName:                    cluster.Frontend.BinaryPath,
+	} {
+		if len(binaryPath) > 0 {
+			return fmt.Errorf("the binary path of %s is not supported in docker mode", component)
+		}
+	}
+	return nil
+}
+
+type composeBuilder struct {
+	compose    *ComposeFile
+	image      string
+	portOffset int
+}
+
+func (b *composeBuilder) addEtcd(etcd *config.Etcd) {
+	version := artifacts.DefaultEtcdBinVersion
+	if etcd != nil && etcd.Artifact != nil && len(etcd.Artifact.Version) > 0 && etcd.Artifact.Version != artifacts.LatestVersionTag {
+		version = etcd.Artifact.Version
+	}
+
+	volume := etcdServiceName + "-data"
+	b.compose.Volumes[volume] = &Volume{}
+	b.compose.Services[etcdServiceName] = &Service{
+		Image: fmt.Sprintf("%s:%s", DefaultEtcdImage, version),
+		Command: []string{
+			"/usr/local/bin/etcd",
+			"--name", etcdServiceName,
+			"--data-dir", etcdDataDir,
+			"--listen-client-urls", "http://0.0.0.0:" + etcdClientPort,
+			"--advertise-client-urls", fmt.Sprintf("http://%s:%s", etcdServiceName, etcdClientPort),
+		},
+		Volumes: []string{volume + ":" + etcdDataDir},
+		Restart: restartPolicy,
+	}
+}
+
+// addMetaSrv adds the metasrv replicas and returns their addresses that the other components connect to.
+func (b *composeBuilder) addMetaSrv(cluster *config.BareMetalClusterComponentsConfig, storeAddr string, useMemoryMeta bool) (string, error) {
+	metaSrv := cluster.MetaSrv
+	serverPort, err := port(metaSrv.ServerAddr)
+	if err != nil {
+		return "", err
+	}
+	httpPort, err := port(metaSrv.HTTPAddr)
+	if err != nil {
+		return "", err
+	}
+
+	env := mergeEnv(mergeEnv(mergeEnv(cluster.Env, components.WALEnv(cluster.WAL, components.MetaSrvComponentName)),
+		components.LogFormatEnv(cluster.LogFormat, components.MetaSrvComponentName)), metaSrv.Env)
+
+	var addrs []string
+	for i := 0; i < metaSrv.Replicas; i++ {
+		name := replicaName(components.MetaSrvComponentName, i)
+		addrs = append(addrs, net.JoinHostPort(name, serverPort))
+
+		args := []string{
+			logLevelArg(metaSrv.LogLevel),
+			components.MetaSrvComponentName, "start",
+			"--bind-addr=0.0.0.0:" + serverPort,
+			fmt.Sprintf("--server-addr=%s", net.JoinHostPort(name, serverPort)),
+			"--http-addr=0.0.0.0:" + httpPort,
+		}
+		if useMemoryMeta {
+			args = append(args, "--use-memory-store=true")
+		} else {
+			args = append(args, fmt.Sprintf("--store-addr=%s", storeAddr))
+		}
+		if len(metaSrv.Selector) > 0 {
+			args = append(args, fmt.Sprintf("--selector=%s", metaSrv.Selector))
+		}
+		if metaSrv.EnableRegionFailover {
+			args = append(args, "--enable-region-failover=true")
+		}
+
+		service := &Service{Image: b.image, Environment: env, Restart: restartPolicy}
+		if _, ok := b.compose.Services[etcdServiceName]; ok {
+			service.DependsOn = []string{etcdServiceName}
+		}
+		service.Command = b.withConfig(service, name, metaSrv.Config, metaSrv.Configs, i, args)
+		b.compose.Services[name] = service
+	}
+
+	return strings.Join(addrs, ","), nil
+}
+
+func (b *composeBuilder) addDatanode(datanode *config.Datanode, metaSrvAddrs string, env map[string]string) error {
+	rpcPort, err := port(datanode.RPCAddr)
+	if err != nil {
+		return err
+	}
+	httpPort, err := port(datanode.HTTPAddr)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < datanode.Replicas; i++ {
+		name := replicaName(datanodeName, i)
+		volume := name + "-data"
+		b.compose.Volumes[volume] = &Volume{}
+
+		args := []string{
+			logLevelArg(datanode.LogLevel),
+			datanodeName, "start",
+			fmt.Sprintf("--node-id=%d", datanode.NodeID+i),
+			fmt.Sprintf("--metasrv-addrs=%s", metaSrvAddrs),
+			fmt.Sprintf("--data-home=%s", dataDir),
+			"--http-addr=0.0.0.0:" + httpPort,
+			"--rpc-addr=0.0.0.0:" + rpcPort,
+			fmt.Sprintf("--rpc-hostname=%s", net.JoinHostPort(name, rpcPort)),
+		}
+
+		service := &Service{
+			Image:       b.image,
+			Environment: env,
+			Volumes:     []string{volume + ":" + dataDir},
+			DependsOn:   []string{replicaName(components.MetaSrvComponentName, 0)},
+			Restart:     restartPolicy,
+		}
+		service.Command = b.withConfig(service, name, datanode.Config, datanode.Configs, i, args)
+		b.compose.Services[name] = service
+	}
+	return nil
+}
+
+func (b *composeBuilder) addFlownode(flownode *config.Flownode, metaSrvAddrs string, env map[string]string) error {
+	if len(flownode.BinaryPath) > 0 {
+		return fmt.Errorf("the binary path of %s is not supported in docker mode", components.FlownodeComponentName)
+	}
+	rpcPort, err := port(flownode.RPCAddr)
+	if err != nil {
+		return err
+	}
+	httpPort, err := port(flownode.HTTPAddr)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < flownode.Replicas; i++ {
+		name := replicaName(components.FlownodeComponentName, i)
+		args := []string{
+			logLevelArg(flownode.LogLevel),
+			components.FlownodeComponentName, "start",
+			fmt.Sprintf("--node-id=%d", i),
+			fmt.Sprintf("--metasrv-addrs=%s", metaSrvAddrs),
+			"--http-addr=0.0.0.0:" + httpPort,
+			"--rpc-addr=0.0.0.0:" + rpcPort,
+			fmt.Sprintf("--rpc-hostname=%s", net.JoinHostPort(name, rpcPort)),
+		}
+
+		service := &Service{
+			Image:       b.image,
+			Environment: env,
+			DependsOn:   []string{replicaName(components.MetaSrvComponentName, 0)},
+			Restart:     restartPolicy,
+		}
+		service.Command = b.withConfig(service, name, flownode.Config, flownode.Configs, i, args)
+		b.compose.Services[name] = service
+	}
+	return nil
+}
+
+func (b *composeBuilder) addFrontend(frontend *config.Frontend, metaSrvAddrs string, env map[string]string) error {
+	for i := 0; i < frontend.Replicas; i++ {
+		name := replicaName(frontendName, i)
+		args := []string{
+			logLevelArg(frontend.LogLevel),
+			frontendName, "start",
+			fmt.Sprintf("--metasrv-addrs=%s", metaSrvAddrs),
+		}
+
+		service := &Service{
+			Image:       b.image,
+			Environment: env,
+			DependsOn:   []string{replicaName(components.MetaSrvComponentName, 0)},
+			Restart:     restartPolicy,
+		}
+		var err error
+		if args, err = b.publish(service, frontend.ReplicaAddrs, i, args, map[string]string{
+			"--http-addr":     frontend.HTTPAddr,
+			"--rpc-addr":      frontend.GRPCAddr,
+			"--mysql-addr":    frontend.MysqlAddr,
+			"--postgres-addr": frontend.PostgresAddr,
+		}); err != nil {
+			return err
+		}
+		if rpcPort, err := port(frontend.GRPCAddr); err == nil {
+			args = append(args, fmt.Sprintf("--rpc-hostname=%s", net.JoinHostPort(name, rpcPort)))
+		}
+		if len(frontend.UserProvider) > 0 {
+			args = append(args, fmt.Sprintf("--user-provider=%s", frontend.UserProvider))
+		}
+		service.Command = b.withConfig(service, name, frontend.Config, frontend.Configs, i, args)
+		b.compose.Services[name] = service
+	}
+	return nil
+}
+
+func (b *composeBuilder) addStandalone(standalone *config.Standalone, clusterEnv map[string]string) error {
+	volume := standaloneName + "-data"
+	b.compose.Volumes[volume] = &Volume{}
+
+	args := []string{
+		logLevelArg(standalone.LogLevel),
+		standaloneName, "start",
+		fmt.Sprintf("--data-home=%s", dataDir),
+	}
+	service := &Service{
+		Image:       b.image,
+		Environment: mergeEnv(clusterEnv, standalone.Env),
+		Volumes:     []string{volume + ":" + dataDir},
+		Restart:     restartPolicy,
+	}
+
+	var err error
+	if args, err = b.publish(service, standalone.ReplicaAddrs, 0, args, map[string]string{
+		"--http-addr":     standalone.HTTPAddr,
+		"--rpc-addr":      standalone.GRPCAddr,
+		"--mysql-addr":    standalone.MysqlAddr,
+		"--postgres-addr": standalone.PostgresAddr,
+	}); err != nil {
+		return err
+	}
+	if len(standalone.UserProvider) > 0 {
+		args = append(args, fmt.Sprintf("--user-provider=%s", standalone.UserProvider))
+	}
+	service.Command = b.withConfig(service, standaloneName, standalone.Config, nil, 0, args)
+	b.compose.Services[standaloneName] = service
+	return nil
+}
+
+// publish listens on all the interfaces of container by the ports of the addresses, and publishes them
+// to the host ports of the replica, which are offset by the index of replica like on bare-metal.
+func (b *composeBuilder) publish(service *Service, replicaAddrs config.ReplicaAddrs, replica int,
+	args []string, addrs map[string]string) ([]string, error) {
+	// The args are in a fixed order, so the compose file is stable.
+	for _, arg := range []string{"--http-addr", "--rpc-addr", "--mysql-addr", "--postgres-addr"} {
+		addr := addrs[arg]
+		if len(addr) == 0 {
+			continue
+		}
+		containerPort, err := port(addr)
+		if err != nil {
+			return nil, err
+		}
+		hostPort, err := port(components.ReplicaAddr(replicaAddrs, arg, addr, replica))
+		if err != nil {
+			return nil, err
+		}
+		if b.portOffset > 0 {
+			p, _ := strconv.Atoi(hostPort)
+			hostPort = strconv.Itoa(p + b.portOffset)
+		}
+
+		args = append(args, fmt.Sprintf("%s=0.0.0.0:%s", arg, containerPort))
+		service.Ports = append(service.Ports, fmt.Sprintf("%s:%s", hostPort, containerPort))
+	}
+	return args, nil
+}
+
+// withConfig mounts the config file of the replica into the container and appends its arg.
+func (b *composeBuilder) withConfig(service *Service, name, cfg string, configs map[int]string, replica int, args []string) []string {
+	if replicaConfig, ok := configs[replica]; ok && len(replicaConfig) > 0 {
+		cfg = replicaConfig
+	}
+	if len(cfg) == 0 {
+		return args
+	}
+
+	if abs, err := filepath.Abs(cfg); err == nil {
+		cfg = abs
+	}
+	target := fmt.Sprintf("%s/%s.toml", configDir, name)
+	service.Volumes = append(service.Volumes, fmt.Sprintf("%s:%s:ro", cfg, target))
+	return components.GenerateConfigArg(target, nil, replica, args)
+}
+
+func replicaName(component string, replica int) string {
+	return fmt.Sprintf("%s-%d", component, replica)
+}
+
+func logLevelArg(logLevel string) string {
+	if len(logLevel) == 0 {
+		logLevel = components.DefaultLogLevel
+	}
+	return fmt.Sprintf("--log-level=%s", logLevel)
+}
+
+func port(addr string) (string, error) {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address '%s': %v", addr, err)
+	}
+	return p, nil
+}
+
+// mergeEnv merges the env of cluster level and component level, the latter takes precedence.
+func mergeEnv(cluster, component map[string]string) map[string]string {
+	if len(cluster) == 0 {
+		return component
+	}
+
+	merged := make(map[string]string, len(cluster)+len(component))
+	for k, v := range cluster {
+		merged[k] = v
+	}
+	for k, v := range component {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestNewComposeFile(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.Artifact.Version = "v0.9.0"
+	cfg.Cluster.Datanode.Replicas = 2
+	cfg.Cluster.Frontend.Replicas = 2
+	cfg.Cluster.PortOffset = 100
+
+	compose, err := NewComposeFile("mycluster", cfg, "", false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "gtctl-mycluster", compose.Name)
+	assert.Len(t, compose.Services, 6)
+	assert.Contains(t, compose.Volumes, "etcd-data")
+	assert.Contains(t, compose.Volumes, "datanode-1-data")
+
+	metaSrv := compose.Services["metasrv-0"]
+	assert.Equal(t, "greptime/greptimedb:v0.9.0", metaSrv.Image)
+	assert.Equal(t, []string{"etcd"}, metaSrv.DependsOn)
+	assert.Contains(t, metaSrv.Command, "--server-addr=metasrv-0:3002")
+	assert.Contains(t, metaSrv.Command, "--store-addr=etcd:2379")
+
+	datanode := compose.Services["datanode-1"]
+	assert.Contains(t, datanode.Command, "--node-id=1")
+	assert.Contains(t, datanode.Command, "--metasrv-addrs=metasrv-0:3002")
+	assert.Contains(t, datanode.Command, "--rpc-hostname=datanode-1:14100")
+	assert.Empty(t, datanode.Ports)
+
+	// The host ports of frontends are offset by the index of replica and the port offset of cluster.
+	frontend := compose.Services["frontend-1"]
+	assert.Contains(t, frontend.Command, "--mysql-addr=0.0.0.0:4002")
+	assert.Equal(t, []string{"4101:4000", "4102:4001", "4103:4002", "4104:4003"}, frontend.Ports)
+}
+
+func TestNewComposeFileWithMemoryMeta(t *testing.T) {
+	compose, err := NewComposeFile("mycluster", config.DefaultBareMetalConfig(), "registry.example.com", true)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, compose.Services, "etcd")
+	metaSrv := compose.Services["metasrv-0"]
+	assert.Equal(t, "registry.example.com/greptime/greptimedb:latest", metaSrv.Image)
+	assert.Contains(t, metaSrv.Command, "--use-memory-store=true")
+	assert.Empty(t, metaSrv.DependsOn)
+}
+
+func TestNewComposeFileStandalone(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.Standalone = config.DefaultStandaloneConfig()
+	cfg.Cluster.Standalone.Config = "/tmp/standalone.toml"
+	cfg.Cluster.LogFormat = config.LogFormatJSON
+
+	compose, err := NewComposeFile("mycluster", cfg, "", false)
+	assert.NoError(t, err)
+
+	assert.Len(t, compose.Services, 1)
+	standalone := compose.Services["standalone"]
+	assert.Equal(t, []string{"4000:4000", "4001:4001", "4002:4002", "4003:4003"}, standalone.Ports)
+	assert.Contains(t, standalone.Volumes, "/tmp/standalone.toml:/etc/greptimedb/standalone.toml:ro")
+	assert.Contains(t, standalone.Command, "-c=/etc/greptimedb/standalone.toml")
+	assert.Equal(t, "json", standalone.Environment["GREPTIMEDB_STANDALONE__LOGGING__LOG_FORMAT"])
+}
+
+func TestNewComposeFileUnsupported(t *testing.T) {
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.DatanodeGroups = []*config.DatanodeGroup{{Name: "hot"}}
+
+	_, err := NewComposeFile("mycluster", cfg, "", false)
+	assert.Error(t, err)
+
+	cfg = config.DefaultBareMetalConfig()
+	cfg.Cluster.Artifact.Version = artifacts.DevBuildVersion("1a2b3c4")
+	_, err = NewComposeFile("mycluster", cfg, "", false)
+	assert.Error(t, err)
+}
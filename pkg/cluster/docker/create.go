@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// Create renders the compose file of cluster into its directory, and starts the containers in background
+// until they are running. The cluster is created again with the rendered file if it exists.
+func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error {
+	compose, err := NewComposeFile(options.Name, c.config, c.imageRegistry, c.useMemoryMeta)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(compose)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		_, err = c.writer.Write(out)
+		return err
+	}
+
+	path := c.composeFilePath(options.Name)
+	if err = fileutils.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, out, 0644); err != nil {
+		return err
+	}
+	c.logger.V(3).Infof("The compose file of cluster '%s' is written to '%s'", options.Name, path)
+
+	// The progress of docker compose is shown instead of the spinner.
+	c.logger.V(0).Infof("Starting the containers of cluster '%s'", options.Name)
+	if err = c.compose(ctx, options.Name, os.Stdout, "up", "--detach", "--wait"); err != nil {
+		return fmt.Errorf("error starting the containers of cluster '%s': %v", options.Name, err)
+	}
+	c.logger.V(0).Infof("The containers of cluster '%s' are running 🎉", options.Name)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+// Delete removes the containers of cluster with their volumes, the volumes and the compose file are kept
+// if RetainData is set, so the cluster is created again with its data by 'docker compose up'.
+func (c *Cluster) Delete(ctx context.Context, options *opt.DeleteOptions) error {
+	if len(options.Components) > 0 {
+		return fmt.Errorf("deleting the components of cluster is not supported in docker mode")
+	}
+
+	exists, err := c.exists(options.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("cluster '%s' not found in docker mode", options.Name)
+	}
+
+	args := []string{"down", "--remove-orphans"}
+	if !options.RetainData {
+		args = append(args, "--volumes")
+	}
+	c.logger.V(0).Infof("Deleting the containers of cluster '%s'", options.Name)
+	if err = c.compose(ctx, options.Name, os.Stdout, args...); err != nil {
+		return err
+	}
+
+	if options.RetainData {
+		c.logger.V(0).Infof("The volumes and the compose file '%s' of cluster '%s' are kept", c.composeFilePath(options.Name), options.Name)
+		return nil
+	}
+	if err = os.RemoveAll(filepath.Dir(c.composeFilePath(options.Name))); err != nil {
+		return err
+	}
+	c.logger.V(0).Infof("Cluster '%s' is deleted", options.Name)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+// Logs outputs the logs of the containers of cluster by 'docker compose logs', the logs of the component
+// are the ones of the containers of all its replicas.
+func (c *Cluster) Logs(ctx context.Context, options *opt.LogsOptions) error {
+	exist, err := c.exists(options.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("cluster '%s' not found in docker mode", options.Name)
+	}
+
+	if len(options.Patterns) > 0 || len(options.Level) > 0 {
+		return notSupported("filtering logs by patterns or level")
+	}
+	if options.JSON {
+		return notSupported("printing logs as json")
+	}
+
+	args := []string{"logs"}
+	if options.Tail >= 0 {
+		args = append(args, "--tail", strconv.Itoa(options.Tail))
+	}
+	if options.Follow {
+		args = append(args, "--follow")
+	}
+	if len(options.Since) > 0 {
+		args = append(args, "--since", options.Since)
+	}
+	if len(options.Until) > 0 {
+		args = append(args, "--until", options.Until)
+	}
+
+	if options.Component != "" {
+		containers, err := c.containers(ctx, options.Name)
+		if err != nil {
+			return err
+		}
+		var services []string
+		for _, container := range containers {
+			if container.Service == options.Component || strings.HasPrefix(container.Service, options.Component+"-") {
+				services = append(services, container.Service)
+			}
+		}
+		if len(services) == 0 {
+			return fmt.Errorf("component '%s' not found in cluster '%s'", options.Component, options.Name)
+		}
+		args = append(args, services...)
+	}
+
+	return c.compose(ctx, options.Name, options.Writer, args...)
+}
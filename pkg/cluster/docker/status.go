@@ -0,0 +1,200 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/term"
+)
+
+// DefaultStatusInterval is the default interval of refreshing the status in watch mode.
+const DefaultStatusInterval = 2 * time.Second
+
+const containerStateRunning = "running"
+
+// container is the status of one container reported by 'docker compose ps --format json'.
+type container struct {
+	Name       string       `json:"Name"`
+	Service    string       `json:"Service"`
+	State      string       `json:"State"`
+	Status     string       `json:"Status"`
+	Publishers []*publisher `json:"Publishers"`
+}
+
+type publisher struct {
+	URL           string `json:"URL"`
+	TargetPort    int    `json:"TargetPort"`
+	PublishedPort int    `json:"PublishedPort"`
+	Protocol      string `json:"Protocol"`
+}
+
+// ContainerView is the status of the container of one replica output in the json or yaml format.
+type ContainerView struct {
+	Replica   string   `json:"replica" yaml:"replica"`
+	Container string   `json:"container" yaml:"container"`
+	State     string   `json:"state" yaml:"state"`
+	Status    string   `json:"status" yaml:"status"`
+	Ports     []string `json:"ports" yaml:"ports"`
+}
+
+// Status renders the status of the container of each replica of cluster. It fails if any container is not running,
+// except in watch mode, in which the status is refreshed until the context is done.
+func (c *Cluster) Status(ctx context.Context, options *opt.StatusOptions) error {
+	exists, err := c.exists(options.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("cluster '%s' not found in docker mode", options.Name)
+	}
+
+	if !options.Watch {
+		containers, err := c.containers(ctx, options.Name)
+		if err != nil {
+			return err
+		}
+		if opt.IsMachineReadable(options.Output) {
+			views := make([]*ContainerView, 0, len(containers))
+			for _, container := range containers {
+				views = append(views, &ContainerView{
+					Replica:   container.Service,
+					Container: container.Name,
+					State:     container.State,
+					Status:    container.Status,
+					Ports:     publishedPorts(container),
+				})
+			}
+			if err = opt.RenderOutput(options.Writer, options.Output, views); err != nil {
+				return err
+			}
+		} else {
+			renderStatus(options.Table, containers)
+		}
+
+		var failed []string
+		for _, container := range containers {
+			if container.State != containerStateRunning {
+				failed = append(failed, container.Service)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("replicas of cluster '%s' are not running: %s", options.Name, strings.Join(failed, ", "))
+		}
+		return nil
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultStatusInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		containers, err := c.containers(ctx, options.Name)
+		if err != nil {
+			return err
+		}
+
+		if options.Writer != nil {
+			term.ClearScreen(options.Writer)
+			fmt.Fprintf(options.Writer, "Every %s: status of cluster '%s'\t%s\n\n",
+				interval, options.Name, time.Now().Format(time.RFC1123))
+		}
+		renderStatus(options.Table, containers)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// containers returns the containers of cluster including the stopped ones, in the order of services.
+func (c *Cluster) containers(ctx context.Context, name string) ([]*container, error) {
+	var out bytes.Buffer
+	if err := c.compose(ctx, name, &out, "ps", "--all", "--format", "json"); err != nil {
+		return nil, err
+	}
+	return parseContainers(out.Bytes())
+}
+
+// parseContainers parses the output of 'docker compose ps --format json', which is a JSON array
+// in the early versions of docker compose, and one JSON object per line in the later ones.
+func parseContainers(out []byte) ([]*container, error) {
+	var containers []*container
+
+	out = bytes.TrimSpace(out)
+	if bytes.HasPrefix(out, []byte("[")) {
+		if err := json.Unmarshal(out, &containers); err != nil {
+			return nil, fmt.Errorf("invalid status of containers: %v", err)
+		}
+	} else {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var container container
+			if err := json.Unmarshal(line, &container); err != nil {
+				return nil, fmt.Errorf("invalid status of containers: %v", err)
+			}
+			containers = append(containers, &container)
+		}
+	}
+
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Service < containers[j].Service
+	})
+	return containers, nil
+}
+
+func renderStatus(table *tablewriter.Table, containers []*container) {
+	table.ClearRows()
+	table.SetHeader([]string{"REPLICA", "CONTAINER", "STATE", "STATUS", "PORTS"})
+	for _, container := range containers {
+		table.Append([]string{container.Service, container.Name, container.State, container.Status,
+			strings.Join(publishedPorts(container), ",")})
+	}
+	table.Render()
+}
+
+// publishedPorts returns the ports published by the container, e.g. "4000->4000/tcp".
+func publishedPorts(container *container) []string {
+	// The port is published on both IPv4 and IPv6 addresses.
+	ports := []string{}
+	published := make(map[string]bool)
+	for _, p := range container.Publishers {
+		port := fmt.Sprintf("%d->%d/%s", p.PublishedPort, p.TargetPort, p.Protocol)
+		if p.PublishedPort == 0 || published[port] {
+			continue
+		}
+		published[port] = true
+		ports = append(ports, port)
+	}
+	return ports
+}
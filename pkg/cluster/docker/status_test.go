@@ -0,0 +1,45 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainers(t *testing.T) {
+	lines := `{"Name":"gtctl-mycluster-frontend-0-1","Service":"frontend-0","State":"running","Status":"Up 2 minutes","Publishers":[{"URL":"0.0.0.0","TargetPort":4002,"PublishedPort":4002,"Protocol":"tcp"}]}
+{"Name":"gtctl-mycluster-datanode-0-1","Service":"datanode-0","State":"exited","Status":"Exited (1) 1 minute ago","Publishers":[]}
+`
+	containers, err := parseContainers([]byte(lines))
+	assert.NoError(t, err)
+	assert.Len(t, containers, 2)
+	assert.Equal(t, "datanode-0", containers[0].Service)
+	assert.Equal(t, "exited", containers[0].State)
+	assert.Equal(t, 4002, containers[1].Publishers[0].PublishedPort)
+
+	array := `[{"Name":"gtctl-mycluster-etcd-1","Service":"etcd","State":"running"}]`
+	containers, err = parseContainers([]byte(array))
+	assert.NoError(t, err)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, "etcd", containers[0].Service)
+
+	containers, err = parseContainers(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+}
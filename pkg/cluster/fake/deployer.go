@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fake provides the fake deployer, which records the operations instead of operating the clusters,
+// to test the commands and the plugins without any backend.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+// Call is the operation called on the fake deployer.
+type Call struct {
+	// Operation is the name of the method, e.g. 'Create'.
+	Operation string
+
+	// Options is the options passed to the method.
+	Options interface{}
+}
+
+// Deployer is the fake deployer, which records the calls and returns the error of the operation in Errors.
+type Deployer struct {
+	// Errors are the errors returned by the operations, which are keyed by the name of the method.
+	Errors map[string]error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ opt.Deployer = &Deployer{}
+
+// NewDeployer creates the fake deployer.
+func NewDeployer() *Deployer {
+	return &Deployer{Errors: make(map[string]error)}
+}
+
+// Calls returns the calls in order.
+func (d *Deployer) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Call(nil), d.calls...)
+}
+
+// Operations returns the names of the called methods in order.
+func (d *Deployer) Operations() []string {
+	var operations []string
+	for _, call := range d.Calls() {
+		operations = append(operations, call.Operation)
+	}
+	return operations
+}
+
+func (d *Deployer) record(operation string, options interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, Call{Operation: operation, Options: options})
+	return d.Errors[operation]
+}
+
+func (d *Deployer) Get(ctx context.Context, options *opt.GetOptions) error {
+	return d.record("Get", options)
+}
+
+func (d *Deployer) List(ctx context.Context, options *opt.ListOptions) error {
+	return d.record("List", options)
+}
+
+func (d *Deployer) Create(ctx context.Context, options *opt.CreateOptions) error {
+	return d.record("Create", options)
+}
+
+func (d *Deployer) Delete(ctx context.Context, options *opt.DeleteOptions) error {
+	return d.record("Delete", options)
+}
+
+func (d *Deployer) Scale(ctx context.Context, options *opt.ScaleOptions) error {
+	return d.record("Scale", options)
+}
+
+func (d *Deployer) Upgrade(ctx context.Context, options *opt.UpgradeOptions) error {
+	return d.record("Upgrade", options)
+}
+
+func (d *Deployer) Restart(ctx context.Context, options *opt.RestartOptions) error {
+	return d.record("Restart", options)
+}
+
+func (d *Deployer) Status(ctx context.Context, options *opt.StatusOptions) error {
+	return d.record("Status", options)
+}
+
+func (d *Deployer) Logs(ctx context.Context, options *opt.LogsOptions) error {
+	return d.record("Logs", options)
+}
+
+func (d *Deployer) Connect(ctx context.Context, options *opt.ConnectOptions) error {
+	return d.record("Connect", options)
+}
+
+func (d *Deployer) Exec(ctx context.Context, options *opt.ExecOptions) error {
+	return d.record("Exec", options)
+}
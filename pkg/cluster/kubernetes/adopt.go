@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+// The labels and annotations that the deploying tools set on the resources they manage.
+const (
+	helmReleaseAnnotation    = "meta.helm.sh/release-name"
+	managedByLabel           = "app.kubernetes.io/managed-by"
+	argoCDTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+	argoCDInstanceLabel      = "argocd.argoproj.io/instance"
+	fluxHelmReleaseLabel     = "helm.toolkit.fluxcd.io/name"
+	fluxKustomizationLabel   = "kustomize.toolkit.fluxcd.io/name"
+)
+
+// AdoptedCluster is the cluster that is discovered to be adopted by gtctl.
+type AdoptedCluster struct {
+	*ClusterView
+
+	// ManagedBy is the tool that deploys the cluster, e.g. "Helm" or "Argo CD", it's empty if it's unknown.
+	ManagedBy string
+
+	// Release is the helm release or the GitOps application that the cluster is deployed by.
+	Release string
+}
+
+// Adopt discovers the GreptimeDBCluster or GreptimeDBStandalone that is deployed by the other tools, e.g. helm or
+// GitOps, and reconciled by the operator. Nothing is changed on Kubernetes, the caller registers the discovered
+// cluster, so it can be operated by gtctl without recreating it.
+func (c *Cluster) Adopt(ctx context.Context, options *opt.AdoptOptions) (*AdoptedCluster, error) {
+	var (
+		adopted             = &AdoptedCluster{}
+		labels, annotations map[string]string
+	)
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Namespace: options.Namespace, Name: options.Name})
+	switch {
+	case err == nil:
+		adopted.ClusterView = c.clusterView(ctx, cluster)
+		labels, annotations = cluster.Labels, cluster.Annotations
+	case errors.IsNotFound(err):
+		standalone, err := c.client.GetStandalone(ctx, options.Name, options.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("neither GreptimeDBCluster nor GreptimeDBStandalone '%s' is found in namespace '%s': %v",
+				options.Name, options.Namespace, err)
+		}
+		adopted.ClusterView = c.standaloneView(standalone)
+		labels, annotations = standalone.Labels, standalone.Annotations
+	default:
+		return nil, err
+	}
+
+	adopted.ManagedBy, adopted.Release = managedBy(labels, annotations)
+	if adopted.Phase == "Unknown" {
+		c.logger.Warnf("Cluster '%s' in namespace '%s' has no phase reported, the greptimedb-operator may not be running",
+			options.Name, options.Namespace)
+	}
+
+	return adopted, nil
+}
+
+// managedBy returns the tool that deploys the resource and its release by the labels and annotations of resource.
+// The GitOps tools take precedence over helm, since they may deploy the resources by rendering helm charts.
+func managedBy(labels, annotations map[string]string) (tool, release string) {
+	if id := annotations[argoCDTrackingAnnotation]; len(id) > 0 {
+		// The tracking id is in the form of "<application>:<group>/<kind>:<namespace>/<name>".
+		return "Argo CD", strings.SplitN(id, ":", 2)[0]
+	}
+	if app := labels[argoCDInstanceLabel]; len(app) > 0 {
+		return "Argo CD", app
+	}
+	for _, label := range []string{fluxHelmReleaseLabel, fluxKustomizationLabel} {
+		if name := labels[label]; len(name) > 0 {
+			return "Flux", name
+		}
+	}
+	if name := annotations[helmReleaseAnnotation]; len(name) > 0 {
+		return "Helm", name
+	}
+	return labels[managedByLabel], ""
+}
@@ -17,9 +17,12 @@
 package kubernetes
 
 import (
+	"io"
 	"time"
 
 	"github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/helm"
 	"github.com/GreptimeTeam/gtctl/pkg/kube"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
@@ -32,6 +35,21 @@ type Cluster struct {
 
 	timeout time.Duration
 	dryRun  bool
+
+	// chartRepository is the private chart repository that the charts are loaded from.
+	chartRepository string
+
+	// manifestsWriter and manifestsDir are where the manifests are rendered to in dry-run mode,
+	// the manifests are logged if neither of them is set.
+	manifestsWriter io.Writer
+	manifestsDir    string
+
+	// kubeconfig and kubeContext select the Kubernetes cluster to operate on.
+	kubeconfig  string
+	kubeContext string
+
+	// events publishes the lifecycle events of cluster, nothing is published if it's nil.
+	events *events.Bus
 }
 
 type Option func(cluster *Cluster)
@@ -43,6 +61,14 @@ func WithDryRun(dryRun bool) Option {
 	}
 }
 
+// WithEvents publishes the events that the cluster is ready or upgraded to the bus,
+// the starts and crashes of components are managed by the operator and not published.
+func WithEvents(bus *events.Bus) Option {
+	return func(c *Cluster) {
+		c.events = bus
+	}
+}
+
 // WithTimeout enables Cluster to have a timeout.
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *Cluster) {
@@ -50,28 +76,56 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-func NewCluster(l logger.Logger, opts ...Option) (cluster.Operations, error) {
-	hl, err := helm.NewLoader(l)
-	if err != nil {
-		return nil, err
+// WithChartRepository enables Cluster to load the charts from the private chart repository.
+func WithChartRepository(repository string) Option {
+	return func(c *Cluster) {
+		c.chartRepository = repository
 	}
+}
 
+// WithManifestsOutput renders the manifests in dry-run mode to the writer, or to the files named by the
+// components under the directory if it's set, e.g. for committing them to a GitOps repository.
+func WithManifestsOutput(writer io.Writer, dir string) Option {
+	return func(c *Cluster) {
+		c.manifestsWriter = writer
+		c.manifestsDir = dir
+	}
+}
+
+// WithKubeConfig enables Cluster to operate on the Kubernetes cluster that the context in kubeconfig points to.
+// The default kubeconfig and its current context are used if they are empty.
+func WithKubeConfig(kubeconfig, kubeContext string) Option {
+	return func(c *Cluster) {
+		c.kubeconfig = kubeconfig
+		c.kubeContext = kubeContext
+	}
+}
+
+func NewCluster(l logger.Logger, opts ...Option) (cluster.Deployer, error) {
 	c := &Cluster{
-		helmLoader: hl,
-		logger:     l,
+		logger: l,
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	hl, err := helm.NewLoader(l, helm.WithChartRepository(c.chartRepository))
+	if err != nil {
+		return nil, err
+	}
+	c.helmLoader = hl
+
 	var client *kube.Client
 	if !c.dryRun {
-		client, err = kube.NewClient("")
+		client, err = kube.NewClient(c.kubeconfig, c.kubeContext)
 		if err != nil {
 			return nil, err
 		}
 	}
 	c.client = client
 
+	// The port-forwarding of the connector should target the same Kubernetes cluster.
+	connector.SetKubeConfig(c.kubeconfig, c.kubeContext)
+
 	return c, nil
 }
@@ -19,9 +19,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
-	"strconv"
 
-	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
@@ -29,24 +27,38 @@ import (
 )
 
 func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) error {
-	cluster, err := c.get(ctx, &opt.GetOptions{
-		Namespace: options.Namespace,
-		Name:      options.Name,
-	})
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
 	if err != nil && errors.IsNotFound(err) {
 		c.logger.V(0).Infof("cluster %s in %s not found", options.Name, options.Namespace)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
+
+	protocol := options.Protocol
+	if client := protocol.Client(); len(client) > 0 && !connector.IsClientInstalled(client) {
+		c.logger.Warnf("The '%s' client is not installed, falling back to the built-in SQL shell on HTTP API", client)
+		protocol = opt.HTTP
+	}
 
-	switch options.Protocol {
+	switch protocol {
 	case opt.MySQL:
-		if err = c.connectMySQL(cluster); err != nil {
+		if err = connector.Mysql(endpoint.MySQLPort, endpoint.Service, c.logger); err != nil {
 			return fmt.Errorf("error connecting to mysql: %v", err)
 		}
 	case opt.Postgres:
-		if err = c.connectPostgres(cluster); err != nil {
+		if err = connector.PostgresSQL(endpoint.PostgresPort, endpoint.Service, c.logger); err != nil {
 			return fmt.Errorf("error connecting to postgres: %v", err)
 		}
+	case opt.HTTP:
+		if err = connector.HTTP(endpoint.HTTPPort, endpoint.Service, options.Database, c.logger); err != nil {
+			return fmt.Errorf("error connecting to http: %v", err)
+		}
+	case opt.GRPC:
+		if err = connector.GRPC(endpoint.GRPCPort, endpoint.Service, c.logger); err != nil {
+			return fmt.Errorf("error connecting to grpc: %v", err)
+		}
 	default:
 		return fmt.Errorf("unsupported connect protocol type")
 	}
@@ -54,10 +66,44 @@ func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) erro
 	return nil
 }
 
-func (c *Cluster) connectMySQL(cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) error {
-	return connector.Mysql(strconv.Itoa(int(cluster.Spec.MySQLServicePort)), cluster.Name, c.logger)
+func (c *Cluster) Exec(ctx context.Context, options *opt.ExecOptions) error {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
+	if err != nil {
+		return err
+	}
+
+	if err = connector.ExecForwarded(endpoint.HTTPPort, endpoint.Service, &options.ExecQuery, options.Writer, c.logger); err != nil {
+		return fmt.Errorf("error executing query: %v", err)
+	}
+
+	return nil
 }
 
-func (c *Cluster) connectPostgres(cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) error {
-	return connector.PostgresSQL(strconv.Itoa(int(cluster.Spec.PostgresServicePort)), cluster.Name, c.logger)
+// Bench generates the load of benchmark against the HTTP API of the frontend or standalone service, which is port-forwarded to local.
+func (c *Cluster) Bench(ctx context.Context, options *opt.BenchOptions) (*connector.BenchReport, error) {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := connector.BenchForwarded(ctx, endpoint.HTTPPort, endpoint.Service, &options.BenchOptions, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("error benchmarking: %v", err)
+	}
+
+	return report, nil
+}
+
+// SeedMetrics writes the synthetic metrics through the HTTP API of the frontend or standalone service, which is port-forwarded to local.
+func (c *Cluster) SeedMetrics(ctx context.Context, options *opt.SeedMetricsOptions) error {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
+	if err != nil {
+		return err
+	}
+
+	if err = connector.SeedMetricsForwarded(ctx, endpoint.HTTPPort, endpoint.Service, &options.MetricsOptions, c.logger); err != nil {
+		return fmt.Errorf("error seeding metrics: %v", err)
+	}
+
+	return nil
 }
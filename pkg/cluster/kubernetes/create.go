@@ -19,24 +19,45 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/helm"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
 )
 
 const (
 	AliCloudRegistry = "greptime-registry.cn-hangzhou.cr.aliyuncs.com"
 
-	disableRBACConfig = "auth.rbac.create=false,auth.rbac.token.enabled=false,"
+	// EtcdDisableRBACConfig disables the RBAC of etcd cluster that GreptimeDB connects to without the credentials.
+	EtcdDisableRBACConfig = "auth.rbac.create=false,auth.rbac.token.enabled=false,"
+
+	// kafkaPlaintextConfig runs a single kafka controller with the plaintext listeners, which is enough for trying the remote WAL.
+	kafkaPlaintextConfig = "controller.replicaCount=1,listeners.client.protocol=PLAINTEXT,listeners.controller.protocol=PLAINTEXT,listeners.interbroker.protocol=PLAINTEXT,"
+
+	// readinessReportInterval is the interval of reporting the ready replicas of the cluster being created.
+	readinessReportInterval = 2 * time.Second
 )
 
 func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error {
 	spinner := options.Spinner
 
+	// The steps are numbered in the progress, e.g. '[1/3] Installing GreptimeDB Operator...'.
+	steps, step := 2, 0
+	if options.Standalone == nil {
+		steps++
+		if options.Kafka != nil {
+			steps++
+		}
+	}
 	withSpinner := func(target string, f func(context.Context, *opt.CreateOptions) error) error {
+		step++
 		if !c.dryRun && spinner != nil {
-			spinner.Start(fmt.Sprintf("Installing %s...", target))
+			spinner.Start(status.StepStatus(step, steps, fmt.Sprintf("Installing %s...", target)))
 		}
 
 		if err := f(ctx, options); err != nil {
@@ -57,53 +78,76 @@ func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error
 	if err := withSpinner("GreptimeDB Operator", c.createOperator); err != nil {
 		return err
 	}
-	if err := withSpinner("Etcd cluster", c.createEtcdCluster); err != nil {
-		return err
+	if options.Standalone != nil {
+		if err := withSpinner("GreptimeDB standalone", c.createStandalone); err != nil {
+			return err
+		}
+	} else {
+		if err := withSpinner("Etcd cluster", c.createEtcdCluster); err != nil {
+			return err
+		}
+		if options.Kafka != nil {
+			if err := withSpinner("Kafka cluster", c.createKafkaCluster); err != nil {
+				return err
+			}
+		}
+		if err := withSpinner("GreptimeDB cluster", c.createCluster); err != nil {
+			return err
+		}
 	}
-	if err := withSpinner("GreptimeDB cluster", c.createCluster); err != nil {
-		return err
+
+	if options.Verify != nil && !c.dryRun {
+		if err := c.verify(ctx, options); err != nil {
+			return fmt.Errorf("error verifying cluster '%s': %v", options.Name, err)
+		}
+	}
+	if !c.dryRun {
+		c.events.Publish(&events.Event{
+			Type:      events.TypeClusterReady,
+			Cluster:   options.Name,
+			Namespace: options.Namespace,
+			Message:   "cluster is ready",
+		})
+	}
+	if options.Seed != nil && !c.dryRun {
+		if err := c.seed(ctx, options); err != nil {
+			return fmt.Errorf("error seeding cluster '%s': %v", options.Name, err)
+		}
 	}
 
 	return nil
 }
 
-// createOperator creates GreptimeDB Operator.
-func (c *Cluster) createOperator(ctx context.Context, options *opt.CreateOptions) error {
-	if options.Operator == nil {
-		return fmt.Errorf("missing create greptimedb operator options")
+// seed loads the seed data into the ready cluster or standalone through its port-forwarded HTTP API.
+func (c *Cluster) seed(ctx context.Context, options *opt.CreateOptions) error {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
+	if err != nil {
+		return err
 	}
-	operatorOpt := options.Operator
-	resourceName, resourceNamespace := OperatorName(), options.Namespace
 
-	if operatorOpt.UseGreptimeCNArtifacts && len(operatorOpt.ImageRegistry) == 0 {
-		operatorOpt.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
-	}
+	return connector.SeedForwarded(endpoint.HTTPPort, endpoint.Service, options.Seed, c.logger)
+}
 
-	opts := &helm.LoadOptions{
-		ReleaseName:   resourceName,
-		Namespace:     resourceNamespace,
-		ChartName:     artifacts.GreptimeDBOperatorChartName,
-		ChartVersion:  operatorOpt.GreptimeDBOperatorChartVersion,
-		FromCNRegion:  operatorOpt.UseGreptimeCNArtifacts,
-		ValuesOptions: *operatorOpt,
-		EnableCache:   true,
-		ValuesFile:    operatorOpt.ValuesFile,
-	}
-	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
+// verify waits for the ready cluster or standalone to serve SQL through its port-forwarded MySQL and HTTP APIs.
+func (c *Cluster) verify(ctx context.Context, options *opt.CreateOptions) error {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
 	if err != nil {
 		return err
 	}
 
-	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
-		return nil
-	}
+	return connector.VerifyForwarded(endpoint.MySQLPort, endpoint.HTTPPort, endpoint.Service, options.Verify, c.logger)
+}
 
-	if err = c.client.Apply(ctx, manifests); err != nil {
-		return err
+// createOperator creates GreptimeDB Operator.
+func (c *Cluster) createOperator(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Operator == nil {
+		return fmt.Errorf("missing create greptimedb operator options")
+	}
+	if len(options.Operator.Namespace) == 0 {
+		options.Operator.Namespace = options.Namespace
 	}
 
-	return c.client.WaitForDeploymentReady(ctx, resourceName, resourceNamespace, c.timeout)
+	return c.InstallOperator(ctx, options.Operator)
 }
 
 // createCluster creates GreptimeDB cluster.
@@ -118,6 +162,16 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 		clusterOpt.ConfigValues += fmt.Sprintf("image.registry=%s,initializer.registry=%s,", AliCloudRegistry, AliCloudRegistry)
 	}
 
+	values := clusterOpt.Values
+	if clusterOpt.ObjectStorage != nil {
+		storageValues, err := c.prepareObjectStorage(ctx, resourceName, resourceNamespace, clusterOpt.ObjectStorage)
+		if err != nil {
+			return err
+		}
+		// The values files set in command line override the object storage.
+		values = append([]map[string]interface{}{storageValues}, values...)
+	}
+
 	opts := &helm.LoadOptions{
 		ReleaseName:   resourceName,
 		Namespace:     resourceNamespace,
@@ -127,6 +181,7 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 		ValuesOptions: *clusterOpt,
 		EnableCache:   true,
 		ValuesFile:    clusterOpt.ValuesFile,
+		Values:        values,
 	}
 	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
 	if err != nil {
@@ -134,17 +189,59 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 	}
 
 	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
-		return nil
+		return c.renderManifests("cluster", manifests)
 	}
 
 	if err = c.client.Apply(ctx, manifests); err != nil {
 		return err
 	}
 
+	stop := c.reportReadiness(ctx, options.Spinner, resourceName, resourceNamespace)
+	defer stop()
 	return c.client.WaitForClusterReady(ctx, resourceName, resourceNamespace, c.timeout)
 }
 
+// reportReadiness reports the percentage of the ready replicas of the cluster to the spinner periodically
+// until the returned function is called.
+func (c *Cluster) reportReadiness(ctx context.Context, spinner *status.Spinner, name, namespace string) func() {
+	if spinner == nil {
+		return func() {}
+	}
+
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(readinessReportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				cluster, err := c.client.GetCluster(ctx, name, namespace)
+				if err != nil {
+					continue
+				}
+				replicas := cluster.Status.Frontend.Replicas + cluster.Status.Datanode.Replicas + cluster.Status.Meta.Replicas
+				ready := cluster.Status.Frontend.ReadyReplicas + cluster.Status.Datanode.ReadyReplicas +
+					cluster.Status.Meta.ReadyReplicas
+				if replicas > 0 {
+					spinner.Progress(fmt.Sprintf("%d/%d replicas ready, %d%%", ready, replicas, ready*100/replicas))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
 // createEtcdCluster creates Etcd cluster.
 func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOptions) error {
 	if options.Etcd == nil {
@@ -153,7 +250,7 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 	etcdOpt := options.Etcd
 	resourceName, resourceNamespace := EtcdClusterName(options.Name), options.Namespace
 
-	etcdOpt.ConfigValues += disableRBACConfig
+	etcdOpt.ConfigValues += EtcdDisableRBACConfig
 	if etcdOpt.UseGreptimeCNArtifacts && len(etcdOpt.ImageRegistry) == 0 {
 		etcdOpt.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
 	}
@@ -167,6 +264,7 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 		ValuesOptions: *etcdOpt,
 		EnableCache:   true,
 		ValuesFile:    etcdOpt.ValuesFile,
+		Values:        etcdOpt.Values,
 	}
 	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
 	if err != nil {
@@ -174,8 +272,7 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 	}
 
 	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
-		return nil
+		return c.renderManifests("etcd", manifests)
 	}
 
 	if err = c.client.Apply(ctx, manifests); err != nil {
@@ -185,6 +282,57 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 	return c.client.WaitForEtcdReady(ctx, resourceName, resourceNamespace, c.timeout)
 }
 
+// createKafkaCluster creates a single node kafka cluster in KRaft mode as the remote WAL of cluster.
+func (c *Cluster) createKafkaCluster(ctx context.Context, options *opt.CreateOptions) error {
+	kafkaOpt := options.Kafka
+	resourceName, resourceNamespace := KafkaClusterName(options.Name), options.Namespace
+
+	kafkaOpt.ConfigValues += kafkaPlaintextConfig
+	if kafkaOpt.UseGreptimeCNArtifacts && len(kafkaOpt.ImageRegistry) == 0 {
+		kafkaOpt.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
+	}
+
+	chartVersion := kafkaOpt.KafkaChartVersion
+	if len(chartVersion) == 0 {
+		chartVersion = artifacts.DefaultKafkaChartVersion
+	}
+
+	opts := &helm.LoadOptions{
+		ReleaseName:   resourceName,
+		Namespace:     resourceNamespace,
+		ChartName:     artifacts.KafkaChartName,
+		ChartVersion:  chartVersion,
+		FromCNRegion:  kafkaOpt.UseGreptimeCNArtifacts,
+		ValuesOptions: *kafkaOpt,
+		EnableCache:   true,
+	}
+	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("error while loading helm chart: %v", err)
+	}
+
+	if c.dryRun {
+		return c.renderManifests("kafka", manifests)
+	}
+
+	if err = c.client.Apply(ctx, manifests); err != nil {
+		return fmt.Errorf("error while applying helm chart: %v", err)
+	}
+
+	// The brokers of kafka in KRaft mode are run by the controllers.
+	return c.client.WaitForStatefulSetReady(ctx, resourceName+"-controller", resourceNamespace, c.timeout)
+}
+
+// KafkaClusterName returns the name of the kafka cluster deployed as the remote WAL of cluster.
+func KafkaClusterName(clusterName string) string {
+	return fmt.Sprintf("%s-kafka", clusterName)
+}
+
+// KafkaEndpoint returns the endpoint of the kafka cluster deployed as the remote WAL of cluster.
+func KafkaEndpoint(clusterName, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local:9092", KafkaClusterName(clusterName), namespace)
+}
+
 func EtcdClusterName(clusterName string) string {
 	return fmt.Sprintf("%s-etcd", clusterName)
 }
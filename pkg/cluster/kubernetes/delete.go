@@ -18,42 +18,84 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
 
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 )
 
+const (
+	// componentLabel is the label that the operator sets on the resources of each component,
+	// the PersistentVolumeClaims of datanodes inherit it from their StatefulSet.
+	componentLabel = "app.greptime.io/component"
+
+	// instanceLabel is the label that the etcd chart sets on its resources.
+	instanceLabel = "app.kubernetes.io/instance"
+)
+
 func (c *Cluster) Delete(ctx context.Context, options *opt.DeleteOptions) error {
 	cluster, err := c.get(ctx, &opt.GetOptions{
 		Namespace: options.Namespace,
 		Name:      options.Name,
 	})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
 	if errors.IsNotFound(err) || cluster == nil {
+		if _, err = c.client.GetStandalone(ctx, options.Name, options.Namespace); err == nil {
+			return c.deleteStandalone(ctx, options)
+		}
+		// The leftovers of the cluster can still be cleaned up.
 		c.logger.V(0).Infof("Cluster '%s' in '%s' not found", options.Name, options.Namespace)
-		return nil
-	}
-	if err != nil {
-		return err
+	} else if options.DryRun {
+		c.logger.V(0).Infof("Cluster '%s' in namespace '%s' would be deleted", options.Name, options.Namespace)
+	} else {
+		// TODO: should wait cluster to be terminated?
+		c.logger.V(0).Infof("Deleting cluster '%s' in namespace '%s'...", options.Name, options.Namespace)
+		if err = c.deleteCluster(ctx, options); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("Cluster '%s' in namespace '%s' is deleted!", options.Name, options.Namespace)
 	}
 
-	// TODO: should wait cluster to be terminated?
-	c.logger.V(0).Infof("Deleting cluster '%s' in namespace '%s'...", options.Name, options.Namespace)
-	if err = c.deleteCluster(ctx, options); err != nil {
-		return err
+	if options.DeletePVCs {
+		selector := fmt.Sprintf("%s=%s-%s", componentLabel, options.Name, greptimedbclusterv1alpha1.DatanodeComponentKind)
+		if err = c.deletePVCs(ctx, options, selector); err != nil {
+			return err
+		}
 	}
-	c.logger.V(0).Infof("Cluster '%s' in namespace '%s' is deleted!", options.Name, options.Namespace)
 
 	if options.TearDownEtcd {
-		c.logger.V(0).Infof("Deleting etcd cluster in namespace '%s'...", options.Namespace)
-		if err = c.deleteEtcdCluster(ctx, &opt.DeleteOptions{
-			Namespace: options.Namespace,
-			Name:      EtcdClusterName(options.Name),
-		}); err != nil {
-			return err
+		etcdName := EtcdClusterName(options.Name)
+		if options.DryRun {
+			c.logger.V(0).Infof("Etcd cluster '%s' in namespace '%s' would be deleted", etcdName, options.Namespace)
+		} else {
+			c.logger.V(0).Infof("Deleting etcd cluster in namespace '%s'...", options.Namespace)
+			if err = c.deleteEtcdCluster(ctx, &opt.DeleteOptions{
+				Namespace: options.Namespace,
+				Name:      etcdName,
+			}); err != nil {
+				return err
+			}
+			c.logger.V(0).Infof("Etcd cluster in namespace '%s' is deleted!", options.Namespace)
+		}
+
+		if options.DeletePVCs {
+			if err = c.deletePVCs(ctx, options, fmt.Sprintf("%s=%s", instanceLabel, etcdName)); err != nil {
+				return err
+			}
 		}
-		c.logger.V(0).Infof("Etcd cluster in namespace '%s' is deleted!", options.Namespace)
 	}
+
+	if options.DeleteOperator {
+		if err = c.deleteOperator(ctx, options); err != nil {
+			return fmt.Errorf("error deleting operator: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -61,6 +103,65 @@ func (c *Cluster) deleteCluster(ctx context.Context, options *opt.DeleteOptions)
 	return c.client.DeleteCluster(ctx, options.Name, options.Namespace)
 }
 
+// deleteStandalone deletes the standalone, and its PersistentVolumeClaims if they should be deleted.
+func (c *Cluster) deleteStandalone(ctx context.Context, options *opt.DeleteOptions) error {
+	if options.DryRun {
+		c.logger.V(0).Infof("Standalone '%s' in namespace '%s' would be deleted", options.Name, options.Namespace)
+	} else {
+		c.logger.V(0).Infof("Deleting standalone '%s' in namespace '%s'...", options.Name, options.Namespace)
+		if err := c.client.DeleteStandalone(ctx, options.Name, options.Namespace); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("Standalone '%s' in namespace '%s' is deleted!", options.Name, options.Namespace)
+	}
+
+	if options.DeletePVCs {
+		selector := fmt.Sprintf("%s=%s", componentLabel, StandaloneServiceName(options.Name))
+		if err := c.deletePVCs(ctx, options, selector); err != nil {
+			return err
+		}
+	}
+
+	if options.DeleteOperator {
+		if err := c.deleteOperator(ctx, options); err != nil {
+			return fmt.Errorf("error deleting operator: %v", err)
+		}
+	}
+
+	return nil
+}
+
 func (c *Cluster) deleteEtcdCluster(ctx context.Context, options *opt.DeleteOptions) error {
 	return c.client.DeleteEtcdCluster(ctx, options.Name, options.Namespace)
 }
+
+// deletePVCs deletes the PersistentVolumeClaims in namespace that match the label selector.
+func (c *Cluster) deletePVCs(ctx context.Context, options *opt.DeleteOptions, selector string) error {
+	namespace := options.Namespace
+	pvcs, err := c.client.ListPersistentVolumeClaims(ctx, namespace, selector)
+	if err != nil {
+		return err
+	}
+
+	for _, pvc := range pvcs {
+		if options.DryRun {
+			c.logger.V(0).Infof("PersistentVolumeClaim '%s' in namespace '%s' would be deleted", pvc, namespace)
+			continue
+		}
+		if err = c.client.DeletePersistentVolumeClaim(ctx, pvc, namespace); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("PersistentVolumeClaim '%s' in namespace '%s' is deleted!", pvc, namespace)
+	}
+
+	return nil
+}
+
+// deleteOperator deletes the resources of greptimedb-operator rendered from its chart, except the CRDs.
+func (c *Cluster) deleteOperator(ctx context.Context, options *opt.DeleteOptions) error {
+	return c.UninstallOperator(ctx, &opt.UninstallOperatorOptions{
+		Namespace:      options.OperatorNamespace,
+		WatchNamespace: options.OperatorWatchNamespace,
+		DryRun:         options.DryRun,
+	})
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+)
+
+// effectiveResource is the GreptimeDB resource without its status and the metadata managed by Kubernetes,
+// which can be applied by kubectl to reproduce the cluster.
+type effectiveResource struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   effectiveMetadata `json:"metadata"`
+	Spec       interface{}       `json:"spec"`
+}
+
+type effectiveMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// writeEffectiveConfig writes the effective config of cluster in yaml, which is the spec of its GreptimeDBCluster
+// or GreptimeDBStandalone resource with the defaults merged by the operator.
+func (c *Cluster) writeEffectiveConfig(ctx context.Context, options *opt.GetOptions) error {
+	var resource *effectiveResource
+	cluster, err := c.get(ctx, options)
+	switch {
+	case err == nil:
+		resource = &effectiveResource{
+			APIVersion: greptimedbclusterv1alpha1.GroupVersion.String(),
+			Kind:       "GreptimeDBCluster",
+			Metadata: effectiveMetadata{
+				Name:        cluster.Name,
+				Namespace:   cluster.Namespace,
+				Labels:      cluster.Labels,
+				Annotations: userAnnotations(cluster.Annotations),
+			},
+			Spec: cluster.Spec,
+		}
+	case errors.IsNotFound(err):
+		standalone, err := c.client.GetStandalone(ctx, options.Name, options.Namespace)
+		if err != nil {
+			return fmt.Errorf("cluster not found")
+		}
+		resource = &effectiveResource{
+			APIVersion: greptimedbclusterv1alpha1.GroupVersion.String(),
+			Kind:       kube.GreptimeDBStandaloneKind,
+			Metadata: effectiveMetadata{
+				Name:        standalone.Name,
+				Namespace:   standalone.Namespace,
+				Labels:      standalone.Labels,
+				Annotations: userAnnotations(standalone.Annotations),
+			},
+			Spec: standalone.Spec,
+		}
+	default:
+		return err
+	}
+
+	out, err := yaml.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(options.Writer, "# The effective config of cluster '%s' in namespace '%s'.\n", options.Name, options.Namespace)
+	fmt.Fprintf(options.Writer, "# Reproduce the cluster by 'kubectl apply -f <this file>'.\n")
+	_, err = options.Writer.Write(out)
+	return err
+}
+
+// userAnnotations returns the annotations without the last applied configuration recorded by kubectl.
+func userAnnotations(annotations map[string]string) map[string]string {
+	filtered := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != corev1.LastAppliedConfigAnnotation {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
@@ -19,26 +19,76 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/olekukonko/tablewriter"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
 )
 
+// ClusterView is the status of cluster parsed from its GreptimeDBCluster resource.
+type ClusterView struct {
+	Name         string            `json:"name" yaml:"name"`
+	Namespace    string            `json:"namespace" yaml:"namespace"`
+	Phase        string            `json:"phase" yaml:"phase"`
+	Ready        bool              `json:"ready" yaml:"ready"`
+	Version      string            `json:"version" yaml:"version"`
+	CreationDate string            `json:"creationDate" yaml:"creationDate"`
+	Components   []ComponentView   `json:"components" yaml:"components"`
+	Endpoints    map[string]string `json:"endpoints" yaml:"endpoints"`
+	StorageClass string            `json:"storageClass,omitempty" yaml:"storageClass,omitempty"`
+	StorageSize  string            `json:"storageSize,omitempty" yaml:"storageSize,omitempty"`
+	Monitoring   bool              `json:"monitoring" yaml:"monitoring"`
+	Conditions   []ConditionView   `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// ComponentView is the replicas of one component of cluster.
+type ComponentView struct {
+	Name          string `json:"name" yaml:"name"`
+	Replicas      int32  `json:"replicas" yaml:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas" yaml:"readyReplicas"`
+}
+
+// ConditionView is one condition of cluster.
+type ConditionView struct {
+	Type               string `json:"type" yaml:"type"`
+	Status             string `json:"status" yaml:"status"`
+	Reason             string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Message            string `json:"message,omitempty" yaml:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
+}
+
 func (c *Cluster) Get(ctx context.Context, options *opt.GetOptions) error {
+	if options.ShowConfig {
+		return c.writeEffectiveConfig(ctx, options)
+	}
+
 	cluster, err := c.get(ctx, options)
 	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
+
+	var view *ClusterView
 	if errors.IsNotFound(err) || cluster == nil {
-		return fmt.Errorf("cluster not found")
+		standalone, err := c.client.GetStandalone(ctx, options.Name, options.Namespace)
+		if err != nil {
+			return fmt.Errorf("cluster not found")
+		}
+		view = c.standaloneView(standalone)
+	} else {
+		view = c.clusterView(ctx, cluster)
+	}
+	switch options.Output {
+	case "", opt.OutputFormatTable, opt.OutputFormatWide:
+		c.renderGetView(options.Table, view, options.Output == opt.OutputFormatWide)
+		return nil
+	default:
+		return opt.RenderOutput(options.Writer, options.Output, view)
 	}
-
-	c.logger.V(0).Infof("Cluster '%s' in '%s' namespace is running, create at %s\n",
-		options.Name, options.Namespace, cluster.CreationTimestamp)
-
-	return nil
 }
 
 func (c *Cluster) get(ctx context.Context, options *opt.GetOptions) (*greptimedbclusterv1alpha1.GreptimeDBCluster, error) {
@@ -48,3 +98,114 @@ func (c *Cluster) get(ctx context.Context, options *opt.GetOptions) (*greptimedb
 	}
 	return cluster, nil
 }
+
+// clusterView parses the status of cluster from its spec, status and conditions.
+func (c *Cluster) clusterView(ctx context.Context, cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) *ClusterView {
+	view := &ClusterView{
+		Name:         cluster.Name,
+		Namespace:    cluster.Namespace,
+		Phase:        string(cluster.Status.ClusterPhase),
+		Version:      cluster.Spec.Version,
+		CreationDate: cluster.CreationTimestamp.String(),
+		Components: []ComponentView{
+			{string(greptimedbclusterv1alpha1.FrontendComponentKind), cluster.Status.Frontend.Replicas, cluster.Status.Frontend.ReadyReplicas},
+			{string(greptimedbclusterv1alpha1.DatanodeComponentKind), cluster.Status.Datanode.Replicas, cluster.Status.Datanode.ReadyReplicas},
+			{string(greptimedbclusterv1alpha1.MetaComponentKind), cluster.Status.Meta.Replicas, cluster.Status.Meta.ReadyReplicas},
+		},
+		Endpoints:  make(map[string]string),
+		Monitoring: cluster.Spec.EnablePrometheusMonitor,
+	}
+	if len(view.Phase) == 0 {
+		view.Phase = "Unknown"
+	}
+
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == greptimedbclusterv1alpha1.GreptimeDBClusterReady && condition.Status == corev1.ConditionTrue {
+			view.Ready = true
+		}
+		view.Conditions = append(view.Conditions, ConditionView{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.String(),
+		})
+	}
+
+	host := fmt.Sprintf("%s-frontend.%s.svc.cluster.local", cluster.Name, cluster.Namespace)
+	for protocol, port := range map[string]int32{
+		"mysql":    cluster.Spec.MySQLServicePort,
+		"postgres": cluster.Spec.PostgresServicePort,
+		"http":     cluster.Spec.HTTPServicePort,
+		"grpc":     cluster.Spec.GRPCServicePort,
+	} {
+		if port > 0 {
+			view.Endpoints[protocol] = fmt.Sprintf("%s:%d", host, port)
+		}
+	}
+
+	if datanode := cluster.Spec.Datanode; datanode != nil {
+		if datanode.Storage.StorageClassName != nil {
+			view.StorageClass = *datanode.Storage.StorageClassName
+		}
+		view.StorageSize = datanode.Storage.StorageSize
+	}
+
+	// The monitoring deployed by the chart of cluster comes with the Grafana service.
+	if !view.Monitoring {
+		exist, err := c.client.IsServiceExist(ctx, connector.GrafanaServiceName(cluster.Name), cluster.Namespace)
+		if err != nil {
+			c.logger.V(3).Infof("error checking the grafana service of cluster '%s': %v", cluster.Name, err)
+		}
+		view.Monitoring = exist
+	}
+
+	return view
+}
+
+func (c *Cluster) renderGetView(table *tablewriter.Table, view *ClusterView, wide bool) {
+	c.configListView(table)
+
+	table.SetHeader([]string{"Component", "Replicas", "Ready"})
+	for _, component := range view.Components {
+		table.Append([]string{component.Name, fmt.Sprint(component.Replicas), fmt.Sprint(component.ReadyReplicas)})
+	}
+	table.Render()
+
+	footers := []string{
+		fmt.Sprintf("NAME: %s", view.Name),
+		fmt.Sprintf("NAMESPACE: %s", view.Namespace),
+		fmt.Sprintf("PHASE: %s", view.Phase),
+		fmt.Sprintf("READY: %t", view.Ready),
+		fmt.Sprintf("VERSION: %s", view.Version),
+		fmt.Sprintf("CREATION-DATE: %s", view.CreationDate),
+		fmt.Sprintf("MONITORING: %t", view.Monitoring),
+	}
+	if wide {
+		footers = append(footers, fmt.Sprintf("STORAGE: %s", storageOf(view)))
+		footers = append(footers, "ENDPOINTS:")
+		for _, protocol := range []string{"mysql", "postgres", "http", "grpc"} {
+			if endpoint, ok := view.Endpoints[protocol]; ok {
+				footers = append(footers, fmt.Sprintf("  %s: %s", protocol, endpoint))
+			}
+		}
+		footers = append(footers, "CONDITIONS:")
+		for _, condition := range view.Conditions {
+			footers = append(footers, fmt.Sprintf("  %s=%s %s %s (%s)", condition.Type, condition.Status,
+				condition.Reason, condition.Message, condition.LastTransitionTime))
+		}
+	}
+
+	for _, footer := range footers {
+		c.logger.V(0).Info(footer)
+	}
+}
+
+// storageOf returns the storage class and size of the datanodes of cluster.
+func storageOf(view *ClusterView) string {
+	storage := []string{view.StorageClass, view.StorageSize}
+	if len(storage[0]) == 0 {
+		storage[0] = "<default>"
+	}
+	return strings.TrimSpace(strings.Join(storage, " "))
+}
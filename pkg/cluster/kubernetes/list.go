@@ -36,9 +36,92 @@ func (c *Cluster) List(ctx context.Context, options *opt.ListOptions) error {
 		return fmt.Errorf("clusters not found")
 	}
 
-	c.renderListView(options.Table, clusters)
+	views := make([]*ClusterView, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		if !inNamespace(clusters.Items[i].Namespace, options.Namespace) {
+			continue
+		}
+		views = append(views, c.clusterView(ctx, &clusters.Items[i]))
+	}
 
-	return nil
+	// The GreptimeDBStandalone is not supported by the operators of early versions.
+	standalones, err := c.client.ListStandalones(ctx)
+	if err != nil {
+		c.logger.V(3).Infof("error listing standalones: %v", err)
+	}
+	for i := range standalones {
+		if !inNamespace(standalones[i].Namespace, options.Namespace) {
+			continue
+		}
+		views = append(views, c.standaloneView(&standalones[i]))
+	}
+
+	switch options.Output {
+	case "", opt.OutputFormatTable, opt.OutputFormatWide:
+		c.renderListView(options.Table, views, options.Output == opt.OutputFormatWide)
+		return nil
+	default:
+		return opt.RenderOutput(options.Writer, options.Output, views)
+	}
+}
+
+var _ opt.Summarizer = &Cluster{}
+
+// Summarize summarizes the clusters and standalones in namespace, or in all namespaces if it's empty.
+func (c *Cluster) Summarize(ctx context.Context, namespace string) ([]*opt.ClusterSummary, error) {
+	clusters, err := c.list(ctx)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	var summaries []*opt.ClusterSummary
+	if clusters != nil {
+		for _, cluster := range clusters.Items {
+			if !inNamespace(cluster.Namespace, namespace) {
+				continue
+			}
+			status := string(cluster.Status.ClusterPhase)
+			if len(status) == 0 {
+				status = "Unknown"
+			}
+			summaries = append(summaries, &opt.ClusterSummary{
+				Name:              cluster.Name,
+				Mode:              opt.ModeKubernetes,
+				Namespace:         cluster.Namespace,
+				Version:           cluster.Spec.Version,
+				Status:            status,
+				CreationTimestamp: cluster.CreationTimestamp.Time,
+			})
+		}
+	}
+
+	// The GreptimeDBStandalone is not supported by the operators of early versions.
+	standalones, err := c.client.ListStandalones(ctx)
+	if err != nil {
+		c.logger.V(3).Infof("error listing standalones: %v", err)
+	}
+	for i := range standalones {
+		if !inNamespace(standalones[i].Namespace, namespace) {
+			continue
+		}
+		view := c.standaloneView(&standalones[i])
+		summaries = append(summaries, &opt.ClusterSummary{
+			Name:              view.Name,
+			Mode:              opt.ModeKubernetes,
+			Namespace:         view.Namespace,
+			Version:           view.Version,
+			Status:            view.Phase,
+			CreationTimestamp: standalones[i].CreationTimestamp.Time,
+		})
+	}
+
+	return summaries, nil
+}
+
+// inNamespace returns whether the resource in namespace is selected by the namespace of options,
+// the resources in all namespaces are selected if it's empty.
+func inNamespace(namespace, selected string) bool {
+	return selected == "" || namespace == selected
 }
 
 func (c *Cluster) list(ctx context.Context) (*greptimedbclusterv1alpha1.GreptimeDBClusterList, error) {
@@ -64,17 +147,24 @@ func (c *Cluster) configListView(table *tablewriter.Table) {
 	table.SetNoWhiteSpace(true)
 }
 
-func (c *Cluster) renderListView(table *tablewriter.Table, data *greptimedbclusterv1alpha1.GreptimeDBClusterList) {
+func (c *Cluster) renderListView(table *tablewriter.Table, views []*ClusterView, wide bool) {
 	c.configListView(table)
 
-	table.SetHeader([]string{"Name", "Namespace", "Creation Date"})
+	headers := []string{"Name", "Namespace", "Phase", "Version", "Creation Date"}
+	if wide {
+		headers = append(headers, "Frontend", "Datanode", "Meta", "Storage", "Monitoring")
+	}
+	table.SetHeader(headers)
 	defer table.Render()
 
-	for _, cluster := range data.Items {
-		table.Append([]string{
-			cluster.Name,
-			cluster.Namespace,
-			cluster.CreationTimestamp.String(),
-		})
+	for _, view := range views {
+		row := []string{view.Name, view.Namespace, view.Phase, view.Version, view.CreationDate}
+		if wide {
+			for _, component := range view.Components {
+				row = append(row, fmt.Sprintf("%d/%d", component.ReadyReplicas, component.Replicas))
+			}
+			row = append(row, storageOf(view), fmt.Sprint(view.Monitoring))
+		}
+		table.Append(row)
 	}
 }
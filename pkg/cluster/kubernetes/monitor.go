@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+)
+
+// OpenMonitor forwards the Grafana of the cluster created with monitoring enabled to local.
+func (c *Cluster) OpenMonitor(ctx context.Context, options *opt.MonitorOptions) error {
+	cluster, err := c.get(ctx, &opt.GetOptions{
+		Namespace: options.Namespace,
+		Name:      options.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("cluster %s in %s not found", options.Name, options.Namespace)
+		}
+		return err
+	}
+
+	if err = connector.Grafana(cluster.Namespace, cluster.Name, strconv.Itoa(options.Port), c.logger); err != nil {
+		return fmt.Errorf("error forwarding grafana, is the cluster created with '--enable-monitoring'? %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+func (c *Cluster) Restart(ctx context.Context, options *opt.RestartOptions) error {
+	return opt.NotSupportedError("restart", opt.ModeKubernetes)
+}
+
+func (c *Cluster) Status(ctx context.Context, options *opt.StatusOptions) error {
+	return opt.NotSupportedError("status, use 'gtctl cluster get' instead", opt.ModeKubernetes)
+}
+
+func (c *Cluster) Logs(ctx context.Context, options *opt.LogsOptions) error {
+	return opt.NotSupportedError("logs, use 'kubectl logs' instead", opt.ModeKubernetes)
+}
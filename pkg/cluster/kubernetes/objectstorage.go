@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// bucketCheckTimeout is the timeout of checking whether the bucket of object storage is reachable.
+const bucketCheckTimeout = 10 * time.Second
+
+// The keys of the object storage credentials in the secret, which are read by the cluster chart.
+const (
+	secretKeyAccessKeyID       = "access-key-id"
+	secretKeySecretAccessKey   = "secret-access-key"
+	secretKeyServiceAccountKey = "service-account-key"
+	secretKeyAccountName       = "account-name"
+	secretKeyAccountKey        = "account-key"
+)
+
+// ObjectStorageSecretName returns the name of the secret that stores the object storage credentials of cluster.
+func ObjectStorageSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-object-storage", clusterName)
+}
+
+// objectStorageSecretData returns the data of the secret that stores the credentials of storage.
+func objectStorageSecretData(storage *config.ObjectStorage) (map[string][]byte, error) {
+	if storage.Type == config.ObjectStorageGCS {
+		if len(storage.CredentialsFile) == 0 {
+			return nil, nil
+		}
+		key, err := os.ReadFile(storage.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials of storage: %v", err)
+		}
+		return map[string][]byte{secretKeyServiceAccountKey: key}, nil
+	}
+
+	credentials, err := storage.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string][]byte)
+	for k, v := range map[string]string{
+		secretKeyAccessKeyID:     credentials.AccessKeyID,
+		secretKeySecretAccessKey: credentials.SecretAccessKey,
+		secretKeyAccountName:     credentials.AccountName,
+		secretKeyAccountKey:      credentials.AccountKey,
+	} {
+		if len(v) > 0 {
+			data[k] = []byte(v)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// objectStorageValues returns the values of the cluster chart that configure the object storage,
+// the credentials are referenced by the secret if it's not empty.
+func objectStorageValues(storage *config.ObjectStorage, secretName string) map[string]interface{} {
+	provider := map[string]interface{}{}
+	bucketKey := "bucket"
+	if storage.Type == config.ObjectStorageAzblob {
+		bucketKey = "container"
+	}
+	for k, v := range map[string]string{
+		bucketKey:  storage.Bucket,
+		"root":     storage.Root,
+		"region":   storage.Region,
+		"endpoint": storage.Endpoint,
+	} {
+		if len(v) > 0 {
+			provider[k] = v
+		}
+	}
+
+	objectStorage := map[string]interface{}{storage.Type: provider}
+	if len(secretName) > 0 {
+		objectStorage["credentials"] = map[string]interface{}{"existingSecretName": secretName}
+	}
+	return map[string]interface{}{"objectStorage": objectStorage}
+}
+
+// bucketURL returns the URL of the bucket of storage that is requested to check its reachability.
+func bucketURL(storage *config.ObjectStorage) (string, error) {
+	if len(storage.Endpoint) > 0 {
+		u, err := url.Parse(storage.Endpoint)
+		if err != nil {
+			return "", fmt.Errorf("invalid endpoint '%s' of storage: %v", storage.Endpoint, err)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + storage.Bucket
+		return u.String(), nil
+	}
+
+	switch storage.Type {
+	case config.ObjectStorageS3:
+		region := storage.Region
+		if len(region) == 0 {
+			region = "us-east-1"
+		}
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", storage.Bucket, region), nil
+	case config.ObjectStorageOSS:
+		if len(storage.Region) == 0 {
+			return "", fmt.Errorf("the endpoint or region of oss should be set")
+		}
+		return fmt.Sprintf("https://%s.%s.aliyuncs.com", storage.Bucket, storage.Region), nil
+	case config.ObjectStorageGCS:
+		return fmt.Sprintf("https://storage.googleapis.com/%s", storage.Bucket), nil
+	default:
+		return "", fmt.Errorf("the endpoint of storage '%s' should be set", storage.Type)
+	}
+}
+
+// checkBucketReachable checks whether the bucket of storage is reachable before the cluster is created.
+// Any response except 404 means the bucket exists, since the request is not authorized.
+func checkBucketReachable(ctx context.Context, storage *config.ObjectStorage) error {
+	u, err := bucketURL(storage)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bucketCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("bucket '%s' is not reachable: %v", storage.Bucket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("bucket '%s' does not exist at '%s'", storage.Bucket, u)
+	}
+	return nil
+}
+
+// prepareObjectStorage checks the bucket of object storage, creates the secret of its credentials,
+// and returns the values of the cluster chart that configure it.
+func (c *Cluster) prepareObjectStorage(ctx context.Context, name, namespace string, storage *config.ObjectStorage) (map[string]interface{}, error) {
+	data, err := objectStorageSecretData(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var secretName string
+	if len(data) > 0 {
+		secretName = ObjectStorageSecretName(name)
+	}
+
+	if c.dryRun {
+		if len(secretName) > 0 {
+			manifest, err := secretManifest(secretName, namespace, data)
+			if err != nil {
+				return nil, err
+			}
+			if err = c.renderManifests("object-storage-secret", manifest); err != nil {
+				return nil, err
+			}
+		}
+		return objectStorageValues(storage, secretName), nil
+	}
+
+	if err = checkBucketReachable(ctx, storage); err != nil {
+		return nil, err
+	}
+
+	if len(secretName) > 0 {
+		if err = c.client.ApplySecret(ctx, secretName, namespace, data); err != nil {
+			return nil, fmt.Errorf("error creating secret of object storage: %v", err)
+		}
+		c.logger.V(3).Infof("Secret '%s' of object storage is created in namespace '%s'", secretName, namespace)
+	}
+
+	return objectStorageValues(storage, secretName), nil
+}
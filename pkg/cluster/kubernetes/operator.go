@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/helm"
+)
+
+// chartLabel is the label of the chart that the resources are rendered from.
+const chartLabel = "helm.sh/chart"
+
+// OperatorCRDs are the names of the CustomResourceDefinitions installed with the operator.
+var OperatorCRDs = []string{"greptimedbclusters.greptime.io", "greptimedbstandalones.greptime.io"}
+
+// InstallOperator installs the operator in the namespace of options, or upgrades it if it's already installed,
+// and waits for it to be ready. The CRDs are not installed if SkipCRDs is set.
+func (c *Cluster) InstallOperator(ctx context.Context, options *opt.CreateOperatorOptions) error {
+	resourceName, resourceNamespace := OperatorName(), options.Namespace
+
+	if options.UseGreptimeCNArtifacts && len(options.ImageRegistry) == 0 {
+		options.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
+	}
+
+	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, &helm.LoadOptions{
+		ReleaseName:   resourceName,
+		Namespace:     resourceNamespace,
+		ChartName:     artifacts.GreptimeDBOperatorChartName,
+		ChartVersion:  options.GreptimeDBOperatorChartVersion,
+		FromCNRegion:  options.UseGreptimeCNArtifacts,
+		ValuesOptions: *options,
+		EnableCache:   true,
+		ValuesFile:    options.ValuesFile,
+		Values:        options.Values,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(options.WatchNamespace) > 0 {
+		c.logger.V(1).Infof("Restricting operator to watch only namespace '%s', the CRDs should be installed in advance", options.WatchNamespace)
+		if manifests, err = helm.NamespaceScopedManifests(manifests, options.WatchNamespace); err != nil {
+			return err
+		}
+	} else if options.SkipCRDs {
+		if manifests, err = helm.WithoutCRDs(manifests); err != nil {
+			return err
+		}
+	}
+
+	if c.dryRun {
+		return c.renderManifests("operator", manifests)
+	}
+
+	if err = c.client.Apply(ctx, manifests); err != nil {
+		return err
+	}
+
+	return c.client.WaitForDeploymentReady(ctx, resourceName, resourceNamespace, c.timeout)
+}
+
+// UpgradeOperator upgrades the installed operator to the chart version of options.
+func (c *Cluster) UpgradeOperator(ctx context.Context, options *opt.CreateOperatorOptions) error {
+	if !c.dryRun {
+		deployment, err := c.client.GetDeployment(ctx, OperatorName(), options.Namespace)
+		if err != nil {
+			return err
+		}
+		if deployment == nil {
+			return fmt.Errorf("operator '%s' is not installed in namespace '%s'", OperatorName(), options.Namespace)
+		}
+	}
+
+	return c.InstallOperator(ctx, options)
+}
+
+// UninstallOperator deletes the resources of the operator rendered from its chart. The CRDs are kept
+// unless DeleteCRDs is set, since deleting them deletes all the clusters and standalones.
+func (c *Cluster) UninstallOperator(ctx context.Context, options *opt.UninstallOperatorOptions) error {
+	namespace := options.Namespace
+	if options.DryRun {
+		c.logger.V(0).Infof("Operator '%s' in namespace '%s' would be deleted", OperatorName(), namespace)
+		if options.DeleteCRDs {
+			c.logger.V(0).Infof("CRDs '%s' would be deleted", strings.Join(OperatorCRDs, "', '"))
+		}
+		return nil
+	}
+
+	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, &helm.LoadOptions{
+		ReleaseName:   OperatorName(),
+		Namespace:     namespace,
+		ChartName:     artifacts.GreptimeDBOperatorChartName,
+		ValuesOptions: opt.CreateOperatorOptions{},
+		EnableCache:   true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(options.WatchNamespace) > 0 {
+		if manifests, err = helm.NamespaceScopedManifests(manifests, options.WatchNamespace); err != nil {
+			return err
+		}
+	}
+
+	c.logger.V(0).Infof("Deleting operator '%s' in namespace '%s'...", OperatorName(), namespace)
+	if err = c.client.DeleteManifests(ctx, manifests); err != nil {
+		return err
+	}
+	c.logger.V(0).Infof("Operator '%s' in namespace '%s' is deleted!", OperatorName(), namespace)
+
+	if options.DeleteCRDs {
+		c.logger.V(0).Infof("Deleting CRDs '%s'...", strings.Join(OperatorCRDs, "', '"))
+		if err = c.client.DeleteCRDs(ctx, manifests); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("CRDs are deleted!")
+	}
+
+	return nil
+}
+
+// OperatorStatus renders the deployment of the operator and whether its CRDs are installed.
+func (c *Cluster) OperatorStatus(ctx context.Context, options *opt.OperatorStatusOptions) error {
+	deployment, err := c.client.GetDeployment(ctx, OperatorName(), options.Namespace)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		return fmt.Errorf("operator '%s' is not installed in namespace '%s'", OperatorName(), options.Namespace)
+	}
+
+	var images, watchNamespace []string
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		images = append(images, container.Image)
+		for _, env := range container.Env {
+			if env.Name == helm.WatchNamespaceEnv {
+				watchNamespace = append(watchNamespace, env.Value)
+			}
+		}
+	}
+	if len(watchNamespace) == 0 {
+		watchNamespace = []string{"*"}
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	options.Table.SetHeader([]string{"NAME", "NAMESPACE", "CHART", "IMAGE", "READY", "WATCH-NAMESPACE"})
+	options.Table.Append([]string{
+		OperatorName(),
+		options.Namespace,
+		deployment.Labels[chartLabel],
+		strings.Join(images, "\n"),
+		fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, replicas),
+		strings.Join(watchNamespace, "\n"),
+	})
+	options.Table.Render()
+
+	for _, crd := range OperatorCRDs {
+		exist, err := c.client.IsCRDExist(ctx, crd)
+		if err != nil {
+			return err
+		}
+		state := "installed"
+		if !exist {
+			state = "not installed"
+		}
+		c.logger.V(0).Infof("CRD '%s' is %s", crd, state)
+	}
+
+	return nil
+}
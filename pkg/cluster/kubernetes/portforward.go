@@ -0,0 +1,72 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+)
+
+// PortForward forwards the MySQL, Postgres, HTTP and gRPC ports of the frontend service of cluster, or
+// the service of standalone, to local until ctx is done, and renders the forwarded ports to the table.
+func (c *Cluster) PortForward(ctx context.Context, options *opt.PortForwardOptions) error {
+	endpoint, err := c.serviceEndpoint(ctx, options.Namespace, options.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("cluster %s in %s not found", options.Name, options.Namespace)
+		}
+		return err
+	}
+
+	var ports []connector.ForwardedPort
+	for _, p := range []struct {
+		protocol string
+		port     string
+	}{
+		{"mysql", endpoint.MySQLPort},
+		{"postgres", endpoint.PostgresPort},
+		{"http", endpoint.HTTPPort},
+		{"grpc", endpoint.GRPCPort},
+	} {
+		port, _ := strconv.Atoi(p.port)
+		if port == 0 {
+			continue
+		}
+		ports = append(ports, connector.ForwardedPort{
+			Name:       p.protocol,
+			LocalPort:  strconv.Itoa(port + options.PortOffset),
+			RemotePort: p.port,
+		})
+	}
+
+	if options.Table != nil {
+		options.Table.SetHeader([]string{"PROTOCOL", "LOCAL", "REMOTE"})
+		for _, port := range ports {
+			options.Table.Append([]string{port.Name, net.JoinHostPort(options.Address, port.LocalPort), port.RemotePort})
+		}
+		options.Table.Render()
+	}
+
+	return connector.PortForward(ctx, options.Namespace, endpoint.Service, options.Address, ports, c.logger)
+}
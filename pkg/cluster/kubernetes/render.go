@@ -0,0 +1,72 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// renderManifests outputs the manifests of component in dry-run mode, see WithManifestsOutput.
+func (c *Cluster) renderManifests(component string, manifests []byte) error {
+	if len(c.manifestsDir) > 0 {
+		if err := fileutils.EnsureDir(c.manifestsDir); err != nil {
+			return err
+		}
+		file := filepath.Join(c.manifestsDir, fmt.Sprintf("%s.yaml", component))
+		if err := os.WriteFile(file, manifests, 0644); err != nil {
+			return err
+		}
+		c.logger.V(0).Infof("Manifests of %s are rendered to '%s'", component, file)
+		return nil
+	}
+
+	if c.manifestsWriter != nil {
+		if !bytes.HasPrefix(bytes.TrimSpace(manifests), []byte("---")) {
+			manifests = append([]byte("---\n"), manifests...)
+		}
+		_, err := c.manifestsWriter.Write(manifests)
+		return err
+	}
+
+	c.logger.V(0).Info(string(manifests))
+	return nil
+}
+
+// secretManifest returns the manifest of the opaque secret.
+func secretManifest(name, namespace string, data map[string][]byte) ([]byte, error) {
+	return yaml.Marshal(&corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	})
+}
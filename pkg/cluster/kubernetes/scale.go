@@ -18,6 +18,8 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 
@@ -41,7 +43,62 @@ func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
 		return err
 	}
 
-	return c.client.WaitForClusterReady(ctx, options.Name, options.Namespace, c.timeout)
+	if err = c.waitForReplicas(ctx, options); err != nil {
+		return err
+	}
+	c.logger.V(0).Infof("Cluster %s in %s is scaled, %s has %d ready replicas",
+		options.Name, options.Namespace, options.ComponentType, options.NewReplicas)
+
+	return nil
+}
+
+// waitForReplicas waits until the operator reports that the component has exactly the new replicas
+// and all of them are ready, the progress is printed every time the ready replicas change.
+func (c *Cluster) waitForReplicas(ctx context.Context, options *opt.ScaleOptions) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	lastReady := int32(-1)
+	for {
+		cluster, err := c.client.GetCluster(ctx, options.Name, options.Namespace)
+		if err != nil {
+			return err
+		}
+
+		replicas, ready := componentReplicas(cluster, options.ComponentType)
+		if ready != lastReady {
+			c.logger.V(0).Infof("Waiting for %s to be ready: %d/%d replicas are ready",
+				options.ComponentType, ready, options.NewReplicas)
+			lastReady = ready
+		}
+		if cluster.Status.ClusterPhase == greptimedbclusterv1alpha1.ClusterError {
+			return fmt.Errorf("cluster %s is in phase '%s'", options.Name, cluster.Status.ClusterPhase)
+		}
+		if replicas == options.NewReplicas && ready == options.NewReplicas {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s to be scaled, %d/%d replicas are ready",
+				options.ComponentType, ready, options.NewReplicas)
+		}
+	}
+}
+
+// componentReplicas returns the replicas and ready replicas of the component reported by the operator.
+func componentReplicas(cluster *greptimedbclusterv1alpha1.GreptimeDBCluster,
+	kind greptimedbclusterv1alpha1.ComponentKind) (replicas, ready int32) {
+	switch kind {
+	case greptimedbclusterv1alpha1.FrontendComponentKind:
+		return cluster.Status.Frontend.Replicas, cluster.Status.Frontend.ReadyReplicas
+	case greptimedbclusterv1alpha1.DatanodeComponentKind:
+		return cluster.Status.Datanode.Replicas, cluster.Status.Datanode.ReadyReplicas
+	case greptimedbclusterv1alpha1.MetaComponentKind:
+		return cluster.Status.Meta.Replicas, cluster.Status.Meta.ReadyReplicas
+	}
+	return 0, 0
 }
 
 func (c *Cluster) scale(options *opt.ScaleOptions, cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) {
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+)
+
+const (
+	// StandaloneComponentName is the component name of the standalone, which is the suffix of its resources.
+	StandaloneComponentName = "standalone"
+
+	// DefaultStandaloneImage is the image of the standalone if it's not specified.
+	DefaultStandaloneImage = "greptime/greptimedb:latest"
+
+	standaloneMountPath = "/data/greptimedb"
+)
+
+// StandaloneServiceName returns the name of the service of standalone that is created by the operator.
+func StandaloneServiceName(name string) string {
+	return fmt.Sprintf("%s-%s", name, StandaloneComponentName)
+}
+
+// createStandalone creates GreptimeDB standalone.
+func (c *Cluster) createStandalone(ctx context.Context, options *opt.CreateOptions) error {
+	if options.Standalone == nil {
+		return fmt.Errorf("missing create greptimedb standalone options")
+	}
+	standaloneOpt := options.Standalone
+
+	image := standaloneOpt.Image
+	if len(image) == 0 {
+		image = DefaultStandaloneImage
+	}
+
+	storage := &greptimedbclusterv1alpha1.StorageSpec{
+		Name:                StandaloneComponentName,
+		StorageSize:         standaloneOpt.StorageSize,
+		MountPath:           standaloneMountPath,
+		StorageRetainPolicy: greptimedbclusterv1alpha1.StorageRetainPolicyType(standaloneOpt.RetainPolicy),
+	}
+	// The storage class of command line is "null" if it's not specified.
+	if len(standaloneOpt.StorageClassName) > 0 && standaloneOpt.StorageClassName != "null" {
+		storage.StorageClassName = &standaloneOpt.StorageClassName
+	}
+
+	base := &greptimedbclusterv1alpha1.PodTemplateSpec{
+		MainContainer: &greptimedbclusterv1alpha1.MainContainerSpec{Image: image},
+	}
+	for _, secret := range standaloneOpt.ImagePullSecrets {
+		base.ImagePullSecrets = append(base.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	standalone := &kube.GreptimeDBStandalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      options.Name,
+			Namespace: options.Namespace,
+		},
+		Spec: kube.GreptimeDBStandaloneSpec{
+			Base:                base,
+			HTTPServicePort:     4000,
+			GRPCServicePort:     4001,
+			MySQLServicePort:    4002,
+			PostgresServicePort: 4003,
+			LocalStorage:        storage,
+		},
+	}
+
+	if c.dryRun {
+		manifest, err := yaml.Marshal(standalone)
+		if err != nil {
+			return err
+		}
+		return c.renderManifests("standalone", manifest)
+	}
+
+	if err := c.client.CreateStandalone(ctx, standalone); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("error creating standalone, does the operator support GreptimeDBStandalone? %v", err)
+		}
+		return err
+	}
+
+	return c.client.WaitForStandaloneReady(ctx, options.Name, options.Namespace, c.timeout)
+}
+
+// standaloneView parses the status of standalone from its spec, status and conditions.
+func (c *Cluster) standaloneView(standalone *kube.GreptimeDBStandalone) *ClusterView {
+	var ready int32
+	if standalone.IsReady() {
+		ready = 1
+	}
+
+	view := &ClusterView{
+		Name:         standalone.Name,
+		Namespace:    standalone.Namespace,
+		Phase:        string(standalone.Status.StandalonePhase),
+		Ready:        ready == 1,
+		Version:      standalone.Spec.Version,
+		CreationDate: standalone.CreationTimestamp.String(),
+		Components:   []ComponentView{{StandaloneComponentName, 1, ready}},
+		Endpoints:    make(map[string]string),
+	}
+	if len(view.Phase) == 0 {
+		view.Phase = "Unknown"
+	}
+	if len(view.Version) == 0 && standalone.Spec.Base != nil && standalone.Spec.Base.MainContainer != nil {
+		view.Version = standalone.Spec.Base.MainContainer.Image
+	}
+
+	for _, condition := range standalone.Status.Conditions {
+		view.Conditions = append(view.Conditions, ConditionView{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.String(),
+		})
+	}
+
+	host := fmt.Sprintf("%s.%s.svc.cluster.local", StandaloneServiceName(standalone.Name), standalone.Namespace)
+	for protocol, port := range map[string]int32{
+		"mysql":    standalone.Spec.MySQLServicePort,
+		"postgres": standalone.Spec.PostgresServicePort,
+		"http":     standalone.Spec.HTTPServicePort,
+		"grpc":     standalone.Spec.GRPCServicePort,
+	} {
+		if port > 0 {
+			view.Endpoints[protocol] = fmt.Sprintf("%s:%d", host, port)
+		}
+	}
+
+	if storage := standalone.Spec.LocalStorage; storage != nil {
+		if storage.StorageClassName != nil {
+			view.StorageClass = *storage.StorageClassName
+		}
+		view.StorageSize = storage.StorageSize
+	}
+
+	return view
+}
+
+// serviceEndpoint is the service that serves the requests of cluster or standalone, and its ports.
+type serviceEndpoint struct {
+	Service      string
+	HTTPPort     string
+	GRPCPort     string
+	MySQLPort    string
+	PostgresPort string
+}
+
+// serviceEndpoint returns the frontend service of cluster, or the service of standalone if there is no
+// cluster with the name.
+func (c *Cluster) serviceEndpoint(ctx context.Context, namespace, name string) (*serviceEndpoint, error) {
+	port := func(p int32) string { return strconv.Itoa(int(p)) }
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Namespace: namespace, Name: name})
+	if err == nil {
+		return &serviceEndpoint{
+			Service:      connector.FrontendServiceName(cluster.Name),
+			HTTPPort:     port(cluster.Spec.HTTPServicePort),
+			GRPCPort:     port(cluster.Spec.GRPCServicePort),
+			MySQLPort:    port(cluster.Spec.MySQLServicePort),
+			PostgresPort: port(cluster.Spec.PostgresServicePort),
+		}, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	standalone, standaloneErr := c.client.GetStandalone(ctx, name, namespace)
+	if standaloneErr != nil {
+		// Report the cluster is not found if there is neither cluster nor standalone.
+		return nil, err
+	}
+	return &serviceEndpoint{
+		Service:      StandaloneServiceName(standalone.Name),
+		HTTPPort:     port(standalone.Spec.HTTPServicePort),
+		GRPCPort:     port(standalone.Spec.GRPCServicePort),
+		MySQLPort:    port(standalone.Spec.MySQLServicePort),
+		PostgresPort: port(standalone.Spec.PostgresServicePort),
+	}, nil
+}
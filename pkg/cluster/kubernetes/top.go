@@ -0,0 +1,91 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/term"
+)
+
+// defaultTopInterval is the default interval of refreshing the metrics of pods.
+const defaultTopInterval = 5 * time.Second
+
+// Top renders the cpu and memory usage of the pods of cluster, or standalone, that are reported by the
+// metrics-server, and refreshes them by the interval until the context is done unless Once is set.
+func (c *Cluster) Top(ctx context.Context, options *opt.TopOptions) error {
+	var components []string
+	for _, kind := range []greptimedbclusterv1alpha1.ComponentKind{
+		greptimedbclusterv1alpha1.MetaComponentKind,
+		greptimedbclusterv1alpha1.DatanodeComponentKind,
+		greptimedbclusterv1alpha1.FrontendComponentKind,
+	} {
+		components = append(components, fmt.Sprintf("%s-%s", options.Name, kind))
+	}
+	components = append(components, StandaloneServiceName(options.Name))
+	selector := fmt.Sprintf("%s in (%s)", componentLabel, strings.Join(components, ","))
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = defaultTopInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		metrics, err := c.client.ListPodMetrics(ctx, options.Namespace, selector)
+		if err != nil {
+			return err
+		}
+		if len(metrics) == 0 {
+			return fmt.Errorf("no metrics of the pods of cluster '%s' in '%s'", options.Name, options.Namespace)
+		}
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+
+		bulk := make([][]string, 0, len(metrics))
+		for _, pod := range metrics {
+			bulk = append(bulk, []string{pod.Name, fmt.Sprintf("%dm", pod.CPUMillis), file.HumanSize(pod.MemoryBytes)})
+		}
+
+		if !options.Once && options.Writer != nil {
+			term.ClearScreen(options.Writer)
+			fmt.Fprintf(options.Writer, "Every %s: resource usage of cluster '%s' in '%s'\t%s\n\n",
+				interval, options.Name, options.Namespace, time.Now().Format(time.RFC1123))
+		}
+		options.Table.ClearRows()
+		options.Table.SetHeader([]string{"POD", "CPU", "MEMORY"})
+		options.Table.AppendBulk(bulk)
+		options.Table.Render()
+		if options.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
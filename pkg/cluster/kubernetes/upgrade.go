@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+)
+
+// rolloutStartTimeout is the timeout of waiting for the operator to start rolling out the updated cluster,
+// the cluster is considered as rolled out if its phase is still running after it.
+const rolloutStartTimeout = 30 * time.Second
+
+// Upgrade upgrades the image tag of all the components of cluster to the version, and waits for the
+// operator to roll out the cluster by watching its phase. The cluster is rolled back to its previous
+// spec if the rollout fails or times out. The operator is upgraded first if its chart version is set.
+func (c *Cluster) Upgrade(ctx context.Context, options *opt.UpgradeOptions) error {
+	if len(options.GreptimeDBOperatorChartVersion) > 0 {
+		c.logger.V(0).Infof("Upgrading GreptimeDB operator to chart '%s'...", options.GreptimeDBOperatorChartVersion)
+		if err := c.createOperator(ctx, &opt.CreateOptions{
+			Namespace: options.OperatorNamespace,
+			Operator: &opt.CreateOperatorOptions{
+				GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
+				UseGreptimeCNArtifacts:         options.UseGreptimeCNArtifacts,
+				WatchNamespace:                 options.OperatorWatchNamespace,
+			},
+		}); err != nil {
+			return fmt.Errorf("error upgrading operator: %v", err)
+		}
+	}
+
+	cluster, err := c.get(ctx, &opt.GetOptions{
+		Namespace: options.Namespace,
+		Name:      options.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	previous := cluster.Spec.DeepCopy()
+	if !upgradeImages(&cluster.Spec, options.GreptimeVersion) {
+		c.logger.V(0).Infof("Cluster '%s' is already running greptime '%s'", options.Name, options.GreptimeVersion)
+		return nil
+	}
+
+	c.logger.V(0).Infof("Upgrading cluster '%s' in '%s' to greptime '%s'...", options.Name, options.Namespace, options.GreptimeVersion)
+	if err = c.client.UpdateCluster(ctx, options.Namespace, cluster); err == nil {
+		err = c.waitForRollout(ctx, options.Name, options.Namespace)
+	}
+	if err != nil {
+		c.logger.Warnf("Failed to upgrade cluster '%s': %v, rolling back...", options.Name, err)
+		if rollbackErr := c.rollback(options.Name, options.Namespace, previous); rollbackErr != nil {
+			return fmt.Errorf("error upgrading cluster: %v, and error rolling back: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("error upgrading cluster, the cluster is rolled back: %v", err)
+	}
+
+	c.logger.V(0).Infof("Cluster '%s' is upgraded to greptime '%s'!", options.Name, options.GreptimeVersion)
+	c.events.Publish(&events.Event{
+		Type:       events.TypeUpgradeCompleted,
+		Cluster:    options.Name,
+		Namespace:  options.Namespace,
+		Message:    fmt.Sprintf("upgraded to greptime '%s'", options.GreptimeVersion),
+		Attributes: map[string]string{"version": options.GreptimeVersion},
+	})
+
+	return nil
+}
+
+// rollback restores the spec of cluster and waits for it to be ready. It runs with a new context
+// since the context of upgrade may be timed out.
+func (c *Cluster) rollback(name, namespace string, spec *greptimedbclusterv1alpha1.GreptimeDBClusterSpec) error {
+	ctx := context.Background()
+	cluster, err := c.client.GetCluster(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	cluster.Spec = *spec
+	if err = c.client.UpdateCluster(ctx, namespace, cluster); err != nil {
+		return err
+	}
+
+	return c.client.WaitForClusterReady(ctx, name, namespace, c.timeout)
+}
+
+// waitForRollout waits for the cluster to be rolled out. The rollout is started once the phase of cluster
+// leaves running, and is finished once the cluster is running and all of its replicas are ready again.
+func (c *Cluster) waitForRollout(ctx context.Context, name, namespace string) error {
+	var (
+		started   bool
+		lastPhase greptimedbclusterv1alpha1.ClusterPhase
+		begin     = time.Now()
+		ticker    = time.NewTicker(time.Second)
+	)
+	defer ticker.Stop()
+
+	for {
+		cluster, err := c.client.GetCluster(ctx, name, namespace)
+		if err != nil {
+			return err
+		}
+
+		phase := cluster.Status.ClusterPhase
+		if phase != lastPhase {
+			c.logger.V(0).Infof("Cluster '%s' is in phase '%s'", name, phase)
+			lastPhase = phase
+		}
+
+		switch {
+		case phase == greptimedbclusterv1alpha1.ClusterError:
+			return fmt.Errorf("cluster is in phase '%s': %s", phase, conditionMessages(cluster))
+		case phase != greptimedbclusterv1alpha1.ClusterRunning:
+			started = true
+		case started || time.Since(begin) > rolloutStartTimeout:
+			if isRolledOut(cluster) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the rollout, the last phase is '%s'", phase)
+		}
+	}
+}
+
+// isRolledOut checks whether the cluster is ready and all the replicas of its components are ready.
+func isRolledOut(cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) bool {
+	ready := false
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == greptimedbclusterv1alpha1.GreptimeDBClusterReady && condition.Status == corev1.ConditionTrue {
+			ready = true
+		}
+	}
+
+	status := cluster.Status
+	return ready &&
+		status.Frontend.ReadyReplicas == status.Frontend.Replicas &&
+		status.Meta.ReadyReplicas == status.Meta.Replicas &&
+		status.Datanode.ReadyReplicas == status.Datanode.Replicas
+}
+
+// conditionMessages returns the messages of the conditions of cluster.
+func conditionMessages(cluster *greptimedbclusterv1alpha1.GreptimeDBCluster) string {
+	var messages []string
+	for _, condition := range cluster.Status.Conditions {
+		if len(condition.Message) > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// upgradeImages replaces the tags of the images of all the components with the version, and returns
+// whether the spec is changed.
+func upgradeImages(spec *greptimedbclusterv1alpha1.GreptimeDBClusterSpec, version string) bool {
+	var templates []*greptimedbclusterv1alpha1.PodTemplateSpec
+	templates = append(templates, spec.Base)
+	if spec.Frontend != nil {
+		templates = append(templates, spec.Frontend.Template)
+	}
+	if spec.Meta != nil {
+		templates = append(templates, spec.Meta.Template)
+	}
+	if spec.Datanode != nil {
+		templates = append(templates, spec.Datanode.Template)
+	}
+
+	changed := spec.Version != version
+	spec.Version = version
+	for _, template := range templates {
+		if template == nil || template.MainContainer == nil || len(template.MainContainer.Image) == 0 {
+			continue
+		}
+		image := imageWithTag(template.MainContainer.Image, version)
+		changed = changed || image != template.MainContainer.Image
+		template.MainContainer.Image = image
+	}
+
+	return changed
+}
+
+// imageWithTag replaces the tag, or the digest, of the image with the tag.
+func imageWithTag(image, tag string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
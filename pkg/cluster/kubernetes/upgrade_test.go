@@ -0,0 +1,220 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestImageWithTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"greptime/greptimedb", "greptime/greptimedb:v0.4.0"},
+		{"greptime/greptimedb:v0.3.2", "greptime/greptimedb:v0.4.0"},
+		{"greptime/greptimedb@sha256:0123abcd", "greptime/greptimedb:v0.4.0"},
+		{"greptime/greptimedb:v0.3.2@sha256:0123abcd", "greptime/greptimedb:v0.4.0"},
+		{"localhost:5000/greptime/greptimedb", "localhost:5000/greptime/greptimedb:v0.4.0"},
+		{"localhost:5000/greptime/greptimedb:v0.3.2", "localhost:5000/greptime/greptimedb:v0.4.0"},
+		{"localhost:5000/greptime/greptimedb@sha256:0123abcd", "localhost:5000/greptime/greptimedb:v0.4.0"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, imageWithTag(test.image, "v0.4.0"), test.image)
+	}
+}
+
+func TestUpgradeImages(t *testing.T) {
+	template := func(image string) *greptimedbclusterv1alpha1.PodTemplateSpec {
+		return &greptimedbclusterv1alpha1.PodTemplateSpec{
+			MainContainer: &greptimedbclusterv1alpha1.MainContainerSpec{Image: image},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		spec        *greptimedbclusterv1alpha1.GreptimeDBClusterSpec
+		wantChanged bool
+		wantImages  []string
+	}{
+		{
+			name: "upgrade all the components",
+			spec: &greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+				Version:  "v0.3.2",
+				Base:     template("greptime/greptimedb:v0.3.2"),
+				Frontend: &greptimedbclusterv1alpha1.FrontendSpec{ComponentSpec: greptimedbclusterv1alpha1.ComponentSpec{Template: template("greptime/greptimedb:v0.3.2")}},
+				Meta:     &greptimedbclusterv1alpha1.MetaSpec{ComponentSpec: greptimedbclusterv1alpha1.ComponentSpec{Template: template("greptime/greptimedb@sha256:0123abcd")}},
+				Datanode: &greptimedbclusterv1alpha1.DatanodeSpec{ComponentSpec: greptimedbclusterv1alpha1.ComponentSpec{Template: template("greptime/greptimedb")}},
+			},
+			wantChanged: true,
+			wantImages: []string{
+				"greptime/greptimedb:v0.4.0",
+				"greptime/greptimedb:v0.4.0",
+				"greptime/greptimedb:v0.4.0",
+				"greptime/greptimedb:v0.4.0",
+			},
+		},
+		{
+			name: "skip the components without template or image",
+			spec: &greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+				Version:  "v0.3.2",
+				Base:     template("greptime/greptimedb:v0.3.2"),
+				Frontend: &greptimedbclusterv1alpha1.FrontendSpec{},
+				Meta:     &greptimedbclusterv1alpha1.MetaSpec{ComponentSpec: greptimedbclusterv1alpha1.ComponentSpec{Template: &greptimedbclusterv1alpha1.PodTemplateSpec{}}},
+				Datanode: &greptimedbclusterv1alpha1.DatanodeSpec{ComponentSpec: greptimedbclusterv1alpha1.ComponentSpec{Template: template("")}},
+			},
+			wantChanged: true,
+			wantImages:  []string{"greptime/greptimedb:v0.4.0", "", "", ""},
+		},
+		{
+			name: "already upgraded",
+			spec: &greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+				Version: "v0.4.0",
+				Base:    template("greptime/greptimedb:v0.4.0"),
+			},
+			wantChanged: false,
+			wantImages:  []string{"greptime/greptimedb:v0.4.0", "", "", ""},
+		},
+		{
+			name: "image pinned by digest",
+			spec: &greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+				Version: "v0.4.0",
+				Base:    template("greptime/greptimedb@sha256:0123abcd"),
+			},
+			wantChanged: true,
+			wantImages:  []string{"greptime/greptimedb:v0.4.0", "", "", ""},
+		},
+		{
+			name: "only the version is changed",
+			spec: &greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+				Version: "v0.3.2",
+			},
+			wantChanged: true,
+			wantImages:  []string{"", "", "", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantChanged, upgradeImages(test.spec, "v0.4.0"))
+			assert.Equal(t, "v0.4.0", test.spec.Version)
+			assert.Equal(t, test.wantImages, specImages(test.spec))
+		})
+	}
+}
+
+func TestUpgradeRollback(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "greptime.io", Version: "v1alpha1", Resource: "greptimedbclusters"}
+
+	cluster := &greptimedbclusterv1alpha1.GreptimeDBCluster{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "greptime.io/v1alpha1", Kind: "GreptimeDBCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"},
+		Spec: greptimedbclusterv1alpha1.GreptimeDBClusterSpec{
+			Version: "v0.3.2",
+			Base: &greptimedbclusterv1alpha1.PodTemplateSpec{
+				MainContainer: &greptimedbclusterv1alpha1.MainContainerSpec{Image: "greptime/greptimedb:v0.3.2"},
+			},
+		},
+		Status: greptimedbclusterv1alpha1.GreptimeDBClusterStatus{
+			ClusterPhase: greptimedbclusterv1alpha1.ClusterRunning,
+			Conditions: []greptimedbclusterv1alpha1.GreptimeDBClusterCondition{
+				{Type: greptimedbclusterv1alpha1.GreptimeDBClusterReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+	assert.NoError(t, err)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "GreptimeDBClusterList"},
+		&unstructured.Unstructured{Object: object})
+
+	// The fake operator fails to roll out the new version and recovers the previous one.
+	var versions []string
+	dynamicClient.PrependReactor("update", "greptimedbclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		version, _, _ := unstructured.NestedString(obj.Object, "spec", "version")
+		versions = append(versions, version)
+
+		phase := greptimedbclusterv1alpha1.ClusterRunning
+		if version == "v0.4.0" {
+			phase = greptimedbclusterv1alpha1.ClusterError
+		}
+		assert.NoError(t, unstructured.SetNestedField(obj.Object, string(phase), "status", "clusterPhase"))
+		return false, nil, nil
+	})
+
+	c := &Cluster{
+		client:  kube.NewClientForInterfaces(nil, dynamicClient, nil),
+		logger:  logger.New(io.Discard, 0),
+		timeout: 10 * time.Second,
+	}
+
+	err = c.Upgrade(context.Background(), &opt.UpgradeOptions{
+		Name:            "mycluster",
+		Namespace:       "default",
+		GreptimeVersion: "v0.4.0",
+	})
+	assert.ErrorContains(t, err, "the cluster is rolled back")
+	assert.Equal(t, []string{"v0.4.0", "v0.3.2"}, versions)
+
+	rolledBack, err := c.client.GetCluster(context.Background(), "mycluster", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.3.2", rolledBack.Spec.Version)
+	assert.Equal(t, "greptime/greptimedb:v0.3.2", rolledBack.Spec.Base.MainContainer.Image)
+	assert.Equal(t, greptimedbclusterv1alpha1.ClusterRunning, rolledBack.Status.ClusterPhase)
+}
+
+// specImages returns the images of the base, frontend, meta and datanode templates of spec.
+func specImages(spec *greptimedbclusterv1alpha1.GreptimeDBClusterSpec) []string {
+	image := func(template *greptimedbclusterv1alpha1.PodTemplateSpec) string {
+		if template == nil || template.MainContainer == nil {
+			return ""
+		}
+		return template.MainContainer.Image
+	}
+
+	images := []string{image(spec.Base), "", "", ""}
+	if spec.Frontend != nil {
+		images[1] = image(spec.Frontend.Template)
+	}
+	if spec.Meta != nil {
+		images[2] = image(spec.Meta.Template)
+	}
+	if spec.Datanode != nil {
+		images[3] = image(spec.Datanode.Template)
+	}
+	return images
+}
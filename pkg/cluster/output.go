@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderOutput writes v in the json or yaml format to out. The fields of v should be tagged
+// by both json and yaml with the same names, so the schemas of the two formats are the same.
+func RenderOutput(out io.Writer, format string, v interface{}) error {
+	switch format {
+	case OutputFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputFormatYAML:
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+}
+
+// IsMachineReadable returns whether the output format is json or yaml.
+func IsMachineReadable(format string) bool {
+	return format == OutputFormatJSON || format == OutputFormatYAML
+}
+
+// ValidateOutputFormat validates that the output format is one of the supported formats.
+func ValidateOutputFormat(format string, supported ...string) error {
+	for _, f := range supported {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format '%s', it should be one of '%s'", format, strings.Join(supported, "', '"))
+}
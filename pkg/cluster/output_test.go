@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderOutput(t *testing.T) {
+	type view struct {
+		Name     string `json:"name" yaml:"name"`
+		Replicas int    `json:"replicas" yaml:"replicas"`
+	}
+	v := []view{{Name: "frontend", Replicas: 1}}
+
+	var out bytes.Buffer
+	assert.NoError(t, RenderOutput(&out, OutputFormatJSON, v))
+	assert.Equal(t, "[\n  {\n    \"name\": \"frontend\",\n    \"replicas\": 1\n  }\n]\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, RenderOutput(&out, OutputFormatYAML, v))
+	assert.Equal(t, "- name: frontend\n  replicas: 1\n", out.String())
+
+	assert.Error(t, RenderOutput(&out, OutputFormatTable, v))
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, ValidateOutputFormat(OutputFormatJSON, OutputFormatJSON, OutputFormatYAML))
+	assert.Error(t, ValidateOutputFormat(OutputFormatWide, OutputFormatJSON, OutputFormatYAML))
+	assert.True(t, IsMachineReadable(OutputFormatYAML))
+	assert.False(t, IsMachineReadable(OutputFormatTable))
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// ModeBareMetal is the mode of the clusters running on bare-metal environment.
+	ModeBareMetal = "bare-metal"
+
+	// ModeKubernetes is the mode of the clusters running on Kubernetes.
+	ModeKubernetes = "kubernetes"
+
+	// ModeDocker is the mode of the clusters running in docker containers.
+	ModeDocker = "docker"
+)
+
+// ClusterSummary is the brief of a cluster in any mode, which lists the clusters of all modes in one view.
+type ClusterSummary struct {
+	Name string `json:"name" yaml:"name"`
+	Mode string `json:"mode" yaml:"mode"`
+
+	// Namespace is empty for the clusters on bare-metal environment.
+	Namespace         string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Version           string    `json:"version" yaml:"version"`
+	Status            string    `json:"status" yaml:"status"`
+	CreationTimestamp time.Time `json:"creationTimestamp" yaml:"creationTimestamp"`
+}
+
+// Summarizer summarizes the clusters of one mode.
+type Summarizer interface {
+	// Summarize returns the summaries of the clusters in namespace, or in all namespaces if it's empty.
+	// The namespace is ignored by the modes that have no namespaces.
+	Summarize(ctx context.Context, namespace string) ([]*ClusterSummary, error)
+}
+
+// SortSummaries sorts the summaries by mode, namespace and name.
+func SortSummaries(summaries []*ClusterSummary) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Mode != b.Mode {
+			return a.Mode < b.Mode
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
+
+// RenderSummaries renders the summaries by the table, or writes them in the output format of options.
+func RenderSummaries(options *GetOptions, summaries []*ClusterSummary) error {
+	if IsMachineReadable(options.Output) {
+		return RenderOutput(options.Writer, options.Output, summaries)
+	}
+
+	table := options.Table
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+
+	table.SetHeader([]string{"Name", "Mode", "Namespace", "Version", "Status", "Age"})
+	for _, summary := range summaries {
+		table.Append(summaryRow(summary, time.Now()))
+	}
+	table.Render()
+
+	return nil
+}
+
+func summaryRow(summary *ClusterSummary, now time.Time) []string {
+	namespace, version, age := summary.Namespace, summary.Version, "N/A"
+	if namespace == "" {
+		namespace = "N/A"
+	}
+	if version == "" {
+		version = "N/A"
+	}
+	if !summary.CreationTimestamp.IsZero() {
+		age = duration.HumanDuration(now.Sub(summary.CreationTimestamp))
+	}
+	return []string{summary.Name, summary.Mode, namespace, version, summary.Status, age}
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortSummaries(t *testing.T) {
+	summaries := []*ClusterSummary{
+		{Name: "b", Mode: ModeKubernetes, Namespace: "default"},
+		{Name: "a", Mode: ModeKubernetes, Namespace: "prod"},
+		{Name: "c", Mode: ModeBareMetal},
+		{Name: "a", Mode: ModeKubernetes, Namespace: "default"},
+	}
+	SortSummaries(summaries)
+
+	var names []string
+	for _, summary := range summaries {
+		names = append(names, summary.Mode+"/"+summary.Namespace+"/"+summary.Name)
+	}
+	assert.Equal(t, []string{"bare-metal//c", "kubernetes/default/a", "kubernetes/default/b", "kubernetes/prod/a"}, names)
+}
+
+func TestSummaryRow(t *testing.T) {
+	now := time.Now()
+
+	row := summaryRow(&ClusterSummary{
+		Name:              "mycluster",
+		Mode:              ModeBareMetal,
+		Status:            "Running",
+		CreationTimestamp: now.Add(-3 * time.Hour),
+	}, now)
+	assert.Equal(t, []string{"mycluster", ModeBareMetal, "N/A", "N/A", "Running", "3h"}, row)
+
+	row = summaryRow(&ClusterSummary{Name: "mycluster", Mode: ModeKubernetes, Namespace: "default", Version: "v0.4.1", Status: "Running"}, now)
+	assert.Equal(t, []string{"mycluster", ModeKubernetes, "default", "v0.4.1", "Running", "N/A"}, row)
+}
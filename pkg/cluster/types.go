@@ -18,40 +18,90 @@ package cluster
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"time"
 
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"github.com/olekukonko/tablewriter"
 
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/connector"
 	"github.com/GreptimeTeam/gtctl/pkg/status"
 )
 
-type Operations interface {
+// Deployer deploys and operates the clusters on one backend, e.g. bare-metal, Kubernetes and docker.
+// The commands only depend on it, so a new backend is added by implementing it, and the commands can be
+// tested against the fake one. The backend returns an error for the operation that it doesn't support.
+type Deployer interface {
 	// Get gets the current cluster profile.
 	Get(ctx context.Context, options *GetOptions) error
 
 	// List lists all cluster profiles.
 	List(ctx context.Context, options *ListOptions) error
 
-	// Scale scales the current cluster according to NewReplicas in ScaleOptions,
-	// and refill the OldReplicas in ScaleOptions.
-	Scale(ctx context.Context, options *ScaleOptions) error
-
 	// Create creates a new cluster.
 	Create(ctx context.Context, options *CreateOptions) error
 
 	// Delete deletes a specific cluster.
 	Delete(ctx context.Context, options *DeleteOptions) error
 
+	// Scale scales the current cluster according to NewReplicas in ScaleOptions,
+	// and refill the OldReplicas in ScaleOptions.
+	Scale(ctx context.Context, options *ScaleOptions) error
+
+	// Upgrade upgrades a specific cluster to the version in UpgradeOptions.
+	Upgrade(ctx context.Context, options *UpgradeOptions) error
+
+	// Restart restarts one component of a specific cluster.
+	Restart(ctx context.Context, options *RestartOptions) error
+
+	// Status checks the status of each replica of a specific cluster.
+	Status(ctx context.Context, options *StatusOptions) error
+
+	// Logs outputs the logs of the components of a specific cluster.
+	Logs(ctx context.Context, options *LogsOptions) error
+
 	// Connect connects to a specific cluster.
 	Connect(ctx context.Context, options *ConnectOptions) error
+
+	// Exec executes the SQL or PromQL query on a specific cluster non-interactively.
+	Exec(ctx context.Context, options *ExecOptions) error
+}
+
+// Operations is the former name of Deployer.
+//
+// Deprecated: use Deployer instead.
+type Operations = Deployer
+
+// NotSupportedError returns the error of the operation that the backend of mode doesn't support.
+func NotSupportedError(operation, mode string) error {
+	return fmt.Errorf("%s is not supported in %s mode", operation, mode)
 }
 
+// The output formats of getting, listing and checking the status of clusters, see RenderOutput.
+const (
+	OutputFormatTable = "table"
+	OutputFormatWide  = "wide"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
 type GetOptions struct {
 	Namespace string
 	Name      string
 
 	// Table view render.
-	Table *tablewriter.Table
+	Table *tablewriter.Table `json:"-"`
+
+	// Output is the output format of cluster, it's rendered by Table if it's
+	// empty, 'table' or 'wide', otherwise it's written to Writer.
+	Output string
+	Writer io.Writer `json:"-"`
+
+	// ShowConfig writes the effective config of cluster to Writer in yaml instead of its status,
+	// which can be used to snapshot the cluster or reproduce it elsewhere.
+	ShowConfig bool
 }
 
 type ListOptions struct {
@@ -64,12 +114,221 @@ type ScaleOptions struct {
 	Namespace     string
 	Name          string
 	ComponentType greptimedbclusterv1alpha1.ComponentKind
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+}
+
+type RestartOptions struct {
+	Namespace     string
+	Name          string
+	ComponentType greptimedbclusterv1alpha1.ComponentKind
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+}
+
+// The chaos actions that are taken on the replicas of bare-metal cluster, see ChaosOptions.
+const (
+	// ChaosKill kills the processes of replicas without letting them exit gracefully.
+	ChaosKill = "kill"
+
+	// ChaosPause pauses the processes of replicas until ChaosResume.
+	ChaosPause  = "pause"
+	ChaosResume = "resume"
+
+	// ChaosPartition isolates the replicas from the network until ChaosHeal.
+	ChaosPartition = "partition"
+	ChaosHeal      = "heal"
+)
+
+// ChaosOptions is the fault that is injected into a bare-metal cluster to exercise its failover.
+type ChaosOptions struct {
+	Name          string
+	ComponentType greptimedbclusterv1alpha1.ComponentKind
+
+	// Index is the index of the replica that the action is taken on, it's taken on all the replicas if it's negative.
+	Index int
+
+	// Action is one of ChaosKill, ChaosPause, ChaosResume, ChaosPartition and ChaosHeal.
+	Action string
+}
+
+// UpgradeOptions is the options to upgrade the greptime binary of a cluster.
+type UpgradeOptions struct {
+	Namespace       string
+	Name            string
+	GreptimeVersion string
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+
+	// GreptimeDBOperatorChartVersion is the chart version that the operator is upgraded to before
+	// the cluster on Kubernetes, the operator is not upgraded if it's empty.
+	GreptimeDBOperatorChartVersion string
+	OperatorNamespace              string
+
+	// OperatorWatchNamespace is the only namespace that the upgraded operator watches, see CreateOperatorOptions.
+	OperatorWatchNamespace string
+}
+
+// ApplyOptions is the options to reconcile a running cluster in bare-metal mode to the desired config.
+type ApplyOptions struct {
+	Name   string
+	Config *config.BareMetalClusterConfig
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+}
+
+// AdoptOptions is the options to adopt a cluster on Kubernetes that is not created by gtctl.
+type AdoptOptions struct {
+	Namespace string
+	Name      string
+}
+
+// BackupOptions is the options to back up the data of a cluster.
+type BackupOptions struct {
+	Name string
+
+	// Output is the path of the archive of backup, which is compressed by gzip or zstd
+	// if it ends with '.tar.gz' or '.tar.zst'.
+	Output string
+}
+
+// DiagnoseOptions is the options to collect the diagnostics of a cluster.
+type DiagnoseOptions struct {
+	Name string
+
+	// Output is the path of the archive of diagnostics.
+	Output string
+
+	// Redact replaces the credentials in the collected files with a placeholder.
+	Redact bool
+}
+
+// RestoreOptions is the options to restore a cluster from the archive of backup.
+type RestoreOptions struct {
+	Name string
+
+	// Input is the path of the archive of backup.
+	Input string
+}
+
+// ExportSystemdOptions is the options to export the replicas of a cluster as the systemd units.
+type ExportSystemdOptions struct {
+	Name string
+
+	// User is the user that the replicas are run as, the user of systemd is used if it's empty.
+	User string
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+}
+
+// StatusOptions is the options to check the status of each replica of a cluster.
+type StatusOptions struct {
+	Name string
+
+	// Table view render.
+	Table *tablewriter.Table `json:"-"`
+
+	// Watch refreshes the status by the Interval until the context is done.
+	Watch    bool
+	Interval time.Duration
+
+	// Output is the output format of status, it's rendered by Table if it's empty or 'table',
+	// otherwise it's written to Writer in json or yaml, which is not supported in watch mode.
+	Output string
+
+	// Writer is where the screen is cleared before each refresh in watch mode,
+	// it should be the same as the writer of Table.
+	Writer io.Writer `json:"-"`
+}
+
+// TopOptions is the options to show the live resource usage of each replica of a cluster.
+type TopOptions struct {
+	Namespace string
+	Name      string
+
+	// Table view render.
+	Table *tablewriter.Table `json:"-"`
+
+	// Interval is the interval of refreshing the resource usage until the context is done,
+	// the resource usage is rendered only once if Once is set.
+	Interval time.Duration
+	Once     bool
+
+	// Writer is where the screen is cleared before each refresh, it should be the same as the writer of Table.
+	Writer io.Writer `json:"-"`
+}
+
+// LogsOptions is the options to print the logs of a cluster.
+type LogsOptions struct {
+	Name string
+
+	// Component is the component whose logs are printed, all the components if it's empty.
+	Component string
+
+	// Tail is the number of lines from the end of logs, all the lines if it's negative.
+	Tail   int
+	Follow bool
+
+	// Patterns are the patterns to grep, and Level is the lowest level of the printed lines.
+	Patterns []string
+	Level    string
+
+	// Since and Until are the time range of the printed lines, they are either the RFC3339 timestamps
+	// or the durations before now, e.g. "10m".
+	Since string
+	Until string
+
+	// JSON prints each line as a json object with the replica field, so that the logs can be parsed by jq.
+	JSON bool
+
+	Writer io.Writer `json:"-"`
+}
+
+// StartOptions is the options to start a stopped cluster.
+type StartOptions struct {
+	Name string
+
+	// UseGreptimeCNArtifacts indicates whether to download the binary from CN region if needed.
+	UseGreptimeCNArtifacts bool
+
+	Spinner *status.Spinner `json:"-"`
+}
+
+// StopOptions is the options to stop a running cluster.
+type StopOptions struct {
+	Name string
 }
 
 type DeleteOptions struct {
 	Namespace    string
 	Name         string
 	TearDownEtcd bool
+
+	// DeletePVCs deletes the PersistentVolumeClaims of the datanodes, and of the etcd if it's teared down.
+	DeletePVCs bool
+
+	// DeleteOperator deletes the greptimedb-operator in OperatorNamespace, its CRDs are kept.
+	DeleteOperator    bool
+	OperatorNamespace string
+
+	// OperatorWatchNamespace is the namespace that the operator is installed to watch only, in which its Roles are deleted.
+	OperatorWatchNamespace string
+
+	// DryRun only prints the resources that would be deleted on Kubernetes.
+	DryRun bool
+
+	// RetainData and RetainLogs keep the data and the logs of the deleted cluster or components in bare-metal mode.
+	RetainData bool
+	RetainLogs bool
+
+	// Components are the components whose replicas are cleaned up in bare-metal mode,
+	// the cluster itself is kept. The whole cluster is deleted if it's empty.
+	Components []string
 }
 
 type CreateOptions struct {
@@ -80,7 +339,28 @@ type CreateOptions struct {
 	Operator *CreateOperatorOptions
 	Etcd     *CreateEtcdOptions
 
-	Spinner *status.Spinner
+	// Kafka deploys a kafka cluster as the remote WAL of cluster on Kubernetes if it's set.
+	Kafka *CreateKafkaOptions
+
+	// Standalone deploys a GreptimeDBStandalone instead of the cluster and etcd on Kubernetes if it's set.
+	Standalone *CreateStandaloneOptions
+
+	// Seed is the seed data that is loaded into the cluster once it's healthy.
+	Seed *connector.SeedOptions
+
+	// Verify waits for the cluster to serve SQL before the creation succeeds if it's set.
+	Verify *connector.VerifyOptions
+
+	Spinner *status.Spinner `json:"-"`
+}
+
+// CreateStandaloneOptions is the options to create a GreptimeDB standalone on Kubernetes.
+type CreateStandaloneOptions struct {
+	Image            string
+	ImagePullSecrets []string
+	StorageClassName string
+	StorageSize      string
+	RetainPolicy     string
 }
 
 // CreateClusterOptions is the options to create a GreptimeDB cluster.
@@ -88,14 +368,52 @@ type CreateClusterOptions struct {
 	GreptimeDBChartVersion string
 	UseGreptimeCNArtifacts bool
 	ValuesFile             string
+	Values                 []map[string]interface{}
+
+	// ObjectStorage is the object storage that the datanodes store data in, its credentials
+	// are stored in a secret that is created before the cluster.
+	ObjectStorage *config.ObjectStorage
 
 	ImageRegistry               string `helm:"image.registry"`
+	ImagePullSecrets            string `helm:"image.pullSecrets"`
 	InitializerImageRegistry    string `helm:"initializer.registry"`
 	DatanodeStorageClassName    string `helm:"datanode.storage.storageClassName"`
 	DatanodeStorageSize         string `helm:"datanode.storage.storageSize"`
 	DatanodeStorageRetainPolicy string `helm:"datanode.storage.storageRetainPolicy"`
 	EtcdEndPoints               string `helm:"meta.etcdEndpoints"`
-	ConfigValues                string `helm:"*"`
+
+	// The remote WAL is enabled if the kafka endpoints, e.g. '{kafka.default:9092}', are set.
+	RemoteWALEnabled     string `helm:"remoteWal.enabled"`
+	KafkaBrokerEndpoints string `helm:"remoteWal.kafka.brokerEndpoints"`
+
+	// The flownode is deployed only if it's enabled.
+	FlownodeEnabled  string `helm:"flownode.enabled"`
+	FlownodeReplicas string `helm:"flownode.replicas"`
+
+	// The self-monitoring of cluster, which collects the metrics and logs of all components into a standalone
+	// GreptimeDB instance, and the Grafana with the dashboards on it.
+	MonitoringEnabled string `helm:"monitoring.enabled"`
+	GrafanaEnabled    string `helm:"grafana.enabled"`
+
+	// The resource requests and limits of components.
+	FrontendCPU         string `helm:"frontend.podTemplate.main.resources.requests.cpu"`
+	FrontendMemory      string `helm:"frontend.podTemplate.main.resources.requests.memory"`
+	FrontendCPULimit    string `helm:"frontend.podTemplate.main.resources.limits.cpu"`
+	FrontendMemoryLimit string `helm:"frontend.podTemplate.main.resources.limits.memory"`
+	DatanodeCPU         string `helm:"datanode.podTemplate.main.resources.requests.cpu"`
+	DatanodeMemory      string `helm:"datanode.podTemplate.main.resources.requests.memory"`
+	DatanodeCPULimit    string `helm:"datanode.podTemplate.main.resources.limits.cpu"`
+	DatanodeMemoryLimit string `helm:"datanode.podTemplate.main.resources.limits.memory"`
+	MetaCPU             string `helm:"meta.podTemplate.main.resources.requests.cpu"`
+	MetaMemory          string `helm:"meta.podTemplate.main.resources.requests.memory"`
+	MetaCPULimit        string `helm:"meta.podTemplate.main.resources.limits.cpu"`
+	MetaMemoryLimit     string `helm:"meta.podTemplate.main.resources.limits.memory"`
+	FlownodeCPU         string `helm:"flownode.podTemplate.main.resources.requests.cpu"`
+	FlownodeMemory      string `helm:"flownode.podTemplate.main.resources.requests.memory"`
+	FlownodeCPULimit    string `helm:"flownode.podTemplate.main.resources.limits.cpu"`
+	FlownodeMemoryLimit string `helm:"flownode.podTemplate.main.resources.limits.memory"`
+
+	ConfigValues string `helm:"*"`
 }
 
 // CreateOperatorOptions is the options to create a GreptimeDB operator.
@@ -103,9 +421,44 @@ type CreateOperatorOptions struct {
 	GreptimeDBOperatorChartVersion string
 	UseGreptimeCNArtifacts         bool
 	ValuesFile                     string
+	Values                         []map[string]interface{}
+
+	// Namespace is the namespace that the operator is installed in, the namespace of cluster is used if it's empty.
+	Namespace string
+
+	// WatchNamespace restricts the operator to watch only the namespace if it's set. The operator is granted by the
+	// Roles in the namespace instead of the ClusterRoles, so it can be installed without the cluster-admin rights,
+	// but the CRDs should be installed by the cluster admin in advance.
+	WatchNamespace string
+
+	// SkipCRDs skips installing the CRDs, e.g. they are managed by the cluster admin.
+	SkipCRDs bool
+
+	ImageRegistry    string `helm:"image.registry"`
+	ImagePullSecrets string `helm:"image.pullSecrets"`
+	ConfigValues     string `helm:"*"`
+}
+
+// UninstallOperatorOptions is the options to uninstall the GreptimeDB operator.
+type UninstallOperatorOptions struct {
+	Namespace string
+
+	// WatchNamespace is the namespace that the operator is installed to watch only, in which its Roles are deleted.
+	WatchNamespace string
+
+	// DeleteCRDs deletes the CRDs of the operator, which also deletes all the clusters and standalones.
+	DeleteCRDs bool
 
-	ImageRegistry string `helm:"image.registry"`
-	ConfigValues  string `helm:"*"`
+	// DryRun only prints the resources that would be deleted.
+	DryRun bool
+}
+
+// OperatorStatusOptions is the options to show the status of the GreptimeDB operator.
+type OperatorStatusOptions struct {
+	Namespace string
+
+	// Table view render.
+	Table *tablewriter.Table `json:"-"`
 }
 
 // CreateEtcdOptions is the options to create an etcd cluster.
@@ -113,24 +466,110 @@ type CreateEtcdOptions struct {
 	EtcdChartVersion       string
 	UseGreptimeCNArtifacts bool
 	ValuesFile             string
+	Values                 []map[string]interface{}
 
 	// The parameters reference: https://artifacthub.io/packages/helm/bitnami/etcd.
 	EtcdClusterSize      string `helm:"replicaCount"`
 	ImageRegistry        string `helm:"image.registry"`
+	ImagePullSecrets     string `helm:"image.pullSecrets"`
 	EtcdStorageClassName string `helm:"persistence.storageClass"`
 	EtcdStorageSize      string `helm:"persistence.size"`
 	ConfigValues         string `helm:"*"`
 }
 
+// CreateKafkaOptions is the options to create a kafka cluster.
+type CreateKafkaOptions struct {
+	KafkaChartVersion      string
+	UseGreptimeCNArtifacts bool
+
+	// The parameters reference: https://artifacthub.io/packages/helm/bitnami/kafka.
+	ImageRegistry    string `helm:"image.registry"`
+	ImagePullSecrets string `helm:"image.pullSecrets"`
+	ConfigValues     string `helm:"*"`
+}
+
 type ConnectProtocol int
 
 const (
 	MySQL ConnectProtocol = iota
 	Postgres
+	HTTP
+	GRPC
 )
 
+// Client returns the external client that connects by the protocol, it's empty if the
+// protocol is served by gtctl itself.
+func (p ConnectProtocol) Client() string {
+	switch p {
+	case MySQL:
+		return "mysql"
+	case Postgres:
+		return "psql"
+	default:
+		return ""
+	}
+}
+
 type ConnectOptions struct {
 	Namespace string
 	Name      string
 	Protocol  ConnectProtocol
+
+	// Database is the database that the statements are executed in by the HTTP protocol.
+	Database string
+}
+
+type ExecOptions struct {
+	Namespace string
+	Name      string
+
+	// The query and the output format of its results.
+	connector.ExecQuery
+
+	// Writer is where the results are written to.
+	Writer io.Writer `json:"-"`
+}
+
+type BenchOptions struct {
+	Namespace string
+	Name      string
+
+	// The workload that is generated against the cluster.
+	connector.BenchOptions
+}
+
+type SeedMetricsOptions struct {
+	Namespace string
+	Name      string
+
+	// The synthetic metrics that are written into the cluster.
+	connector.MetricsOptions
+}
+
+type MonitorOptions struct {
+	Namespace string
+	Name      string
+
+	// Port is the local port that the Grafana is forwarded to.
+	Port int
+
+	// Addr is the local address that the aggregated metrics of bare-metal cluster are served on.
+	Addr string
+
+	// OutputDir is the dir that the Prometheus scrape config and Grafana dashboard of bare-metal cluster are written to.
+	OutputDir string
+}
+
+type PortForwardOptions struct {
+	Namespace string
+	Name      string
+
+	// Address is the local address that the ports are forwarded to.
+	Address string
+
+	// PortOffset is added to the port of each service to get its local port.
+	PortOffset int
+
+	// Table view render.
+	Table *tablewriter.Table `json:"-"`
 }
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	backendTLSEnvPrefix = "GREPTIMEDB_METASRV__BACKEND_TLS__"
+
+	// backendStoreAddrsEnv is the environment variable of the store addresses of metasrv, which
+	// passes the addresses carrying the password, so the password is not exposed in argv by 'ps'.
+	backendStoreAddrsEnv = "GREPTIMEDB_METASRV__STORE_ADDRS"
+)
+
+// BackendPassword reads the password of metadata store backend from its password file.
+func BackendPassword(storage *config.BackendStorage) (string, error) {
+	if storage == nil || len(storage.PasswordFile) == 0 {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(storage.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file '%s' of backend storage: %v", storage.PasswordFile, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// BackendStoreAddrs returns the value of '--store-addrs' of metasrv. It's the endpoints
+// for etcd, and the connection URL for postgres and mysql.
+func BackendStoreAddrs(storage *config.BackendStorage, password string) string {
+	if storage.Kind == config.BackendStorageEtcd {
+		return strings.Join(storage.Endpoints, ",")
+	}
+
+	u := &url.URL{
+		Scheme: storage.Kind,
+		Host:   storage.Endpoints[0],
+		Path:   "/" + storage.Database,
+	}
+	if storage.Kind == config.BackendStoragePostgres {
+		u.Scheme = "postgresql"
+	}
+	if len(storage.User) > 0 {
+		if len(password) > 0 {
+			u.User = url.UserPassword(storage.User, password)
+		} else {
+			u.User = url.User(storage.User)
+		}
+	}
+	return u.String()
+}
+
+// backendArgs returns the args of metasrv that configure the metadata store backend,
+// the '--store-addrs' is omitted if storeAddrs is empty, e.g. it's passed by backendStoreAddrsEnv.
+func backendArgs(storage *config.BackendStorage, storeAddrs string) []string {
+	args := []string{fmt.Sprintf("--backend=%s-store", storage.Kind)}
+	if len(storeAddrs) > 0 {
+		args = append(args, fmt.Sprintf("--store-addrs=%s", storeAddrs))
+	}
+	return args
+}
+
+// backendEnv returns the environment variables that configure the TLS of metadata store backend.
+func backendEnv(storage *config.BackendStorage) map[string]string {
+	if storage == nil || storage.TLS == nil {
+		return nil
+	}
+
+	env := make(map[string]string)
+	for k, v := range map[string]string{
+		"MODE":         storage.TLS.Mode,
+		"CERT_PATH":    storage.TLS.CertPath,
+		"KEY_PATH":     storage.TLS.KeyPath,
+		"CA_CERT_PATH": storage.TLS.CACertPath,
+	} {
+		if len(v) > 0 {
+			env[backendTLSEnvPrefix+k] = v
+		}
+	}
+	return env
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+)
+
+// KillProcess kills the process of replica without letting it exit gracefully, which simulates the crash of replica.
+func KillProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return forceKillProcess(p)
+}
+
+// PauseProcess pauses the process of replica, so it's alive but unresponsive until it's resumed by ResumeProcess.
+func PauseProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return suspendProcess(p)
+}
+
+// ResumeProcess resumes the process of replica paused by PauseProcess.
+func ResumeProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return resumeProcess(p)
+}
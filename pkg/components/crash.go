@@ -0,0 +1,300 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	// CrashIndexFileName is the name of the index file under the crashes dir, each line of it is one CrashRecord in json.
+	CrashIndexFileName = "index.jsonl"
+
+	// CrashLogFileName and CrashBacktraceFileName are the names of the captured logs and backtrace under the dir of crash.
+	CrashLogFileName       = "log"
+	CrashBacktraceFileName = "backtrace"
+
+	crashCoreFileName = "core"
+)
+
+// panicPattern matches the first line of the panic of Rust, e.g. "thread 'main' panicked at src/main.rs:1:1",
+// the panic hook of GreptimeDB, and the panic of Go, e.g. etcd.
+var panicPattern = regexp.MustCompile(`panicked at|panic occurred|^panic: `)
+
+// crashIndexMu serializes the appending to the index of crashes by the supervisors of current process.
+var crashIndexMu sync.Mutex
+
+// CrashRecord is one crash of replica recorded in the index of crashes.
+type CrashRecord struct {
+	Replica string    `json:"replica" yaml:"replica"`
+	Pid     int       `json:"pid" yaml:"pid"`
+	Time    time.Time `json:"time" yaml:"time"`
+	Reason  string    `json:"reason" yaml:"reason"`
+
+	// Dir is the dir of the captured logs, backtrace and core dump of the crash under the crashes dir.
+	Dir string `json:"dir" yaml:"dir"`
+
+	// Backtrace tells whether the panic backtrace is found in the logs.
+	Backtrace bool `json:"backtrace,omitempty" yaml:"backtrace,omitempty"`
+
+	// CoreDump is the core file moved into Dir, or where to find it if it's handled by the system, e.g. systemd-coredump.
+	CoreDump string `json:"coreDump,omitempty" yaml:"coreDump,omitempty"`
+}
+
+// recordCrash captures the crash of the process of replica into a new dir under the crashes dir, i.e. the last
+// lines of its logs, which include its stderr, the panic backtrace in them and the core dump if it's enabled.
+// The crash is appended to the index of crashes.
+func recordCrash(option *RunOptions, pid int, reason string) (*CrashRecord, error) {
+	crashDump := option.crashDump
+	if crashDump == nil {
+		crashDump = &config.CrashDump{}
+	}
+	logLines := crashDump.LogLines
+	if logLines == 0 {
+		logLines = config.DefaultCrashLogLines
+	}
+
+	now := time.Now()
+	record := &CrashRecord{
+		Replica: option.Name,
+		Pid:     pid,
+		Time:    now,
+		Reason:  reason,
+		Dir:     fmt.Sprintf("%s-%s-%d", option.Name, now.Format("20060102-150405"), pid),
+	}
+	dir := filepath.Join(option.crashesDir, record.Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	lines, err := tailLines(filepath.Join(option.logDir, logFileName), logLines)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeLines(filepath.Join(dir, CrashLogFileName), lines); err != nil {
+		return nil, err
+	}
+	if backtrace := panicBacktrace(lines); len(backtrace) > 0 {
+		if err = writeLines(filepath.Join(dir, CrashBacktraceFileName), backtrace); err != nil {
+			return nil, err
+		}
+		record.Backtrace = true
+	}
+
+	if crashDump.CoreDump {
+		record.CoreDump = captureCoreDump(pid, option.Binary, filepath.Join(dir, crashCoreFileName))
+	}
+
+	return record, appendCrashIndex(option.crashesDir, record)
+}
+
+// tailLines returns the last n lines of file, it's empty if the file doesn't exist.
+func tailLines(file string, n int) ([]string, error) {
+	f := &logFollower{file: file}
+	if err := f.skipToTail(n); err != nil {
+		return nil, err
+	}
+	return f.readLines()
+}
+
+// panicBacktrace returns the lines from the last panic to the end, they are empty if there is no panic.
+func panicBacktrace(lines []string) []string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if panicPattern.MatchString(lines[i]) {
+			return lines[i:]
+		}
+	}
+	return nil
+}
+
+func writeLines(file string, lines []string) error {
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		content += "\n"
+	}
+	return os.WriteFile(file, []byte(content), 0644)
+}
+
+// captureCoreDump moves the core file of the crashed process to target, it returns the target if the
+// core file is moved, or the hint of finding it otherwise.
+func captureCoreDump(pid int, binary, target string) string {
+	core, hint := findCoreDump(pid, binary)
+	if len(core) == 0 {
+		return hint
+	}
+	if err := moveFile(core, target); err != nil {
+		return fmt.Sprintf("failed to move the core file '%s': %v", core, err)
+	}
+	return target
+}
+
+// findCoreDump finds the core file of the crashed process by the core pattern of system, it returns the
+// hint of finding the core file instead if it's not written to a file or not found.
+func findCoreDump(pid int, binary string) (string, string) {
+	pattern := systemCorePattern()
+	if len(pattern) == 0 {
+		return "", "the core dumps are not supported on this platform"
+	}
+	if strings.HasPrefix(pattern, "|") {
+		return "", fmt.Sprintf("the core dump is piped to '%s', e.g. run 'coredumpctl dump %d' for systemd-coredump",
+			strings.TrimSpace(strings.TrimPrefix(pattern, "|")), pid)
+	}
+
+	expanded := expandCorePattern(pattern, pid, binary)
+	candidates := []string{expanded}
+	// The pid is appended to the core file by the 'kernel.core_uses_pid' of Linux.
+	if !strings.Contains(pattern, "%p") && !strings.Contains(pattern, "%P") {
+		candidates = append(candidates, fmt.Sprintf("%s.%d", expanded, pid))
+	}
+	for _, candidate := range candidates {
+		if matches, err := filepath.Glob(candidate); err == nil && len(matches) > 0 {
+			return matches[len(matches)-1], ""
+		}
+	}
+	return "", fmt.Sprintf("no core file is found by the core pattern '%s'", pattern)
+}
+
+// expandCorePattern expands the specifiers of the core pattern for the process, the specifiers that can't be
+// known after the crash, e.g. the time, are expanded as the wildcards. The relative pattern is relative to the
+// working dir of process, which is inherited from gtctl.
+func expandCorePattern(pattern string, pid int, binary string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		switch pattern[i] {
+		case 'p', 'P':
+			b.WriteString(strconv.Itoa(pid))
+		case 'e':
+			// The executable name is truncated as the comm of process.
+			name := filepath.Base(binary)
+			if len(name) > 15 {
+				name = name[:15]
+			}
+			b.WriteString(name)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('*')
+		}
+	}
+
+	expanded := b.String()
+	if !filepath.IsAbs(expanded) {
+		if wd, err := os.Getwd(); err == nil {
+			expanded = filepath.Join(wd, expanded)
+		}
+	}
+	return expanded
+}
+
+// moveFile moves the file by renaming it, or copying it if they are on different devices.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func appendCrashIndex(crashesDir string, record *CrashRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	crashIndexMu.Lock()
+	defer crashIndexMu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(crashesDir, CrashIndexFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// ReadCrashes reads the crashes recorded under crashesDir in the order of time, it's empty if there is no crash.
+func ReadCrashes(crashesDir string) ([]CrashRecord, error) {
+	f, err := os.Open(filepath.Join(crashesDir, CrashIndexFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []CrashRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		var record CrashRecord
+		// The last line may be incomplete if it's being appended.
+		if err = json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
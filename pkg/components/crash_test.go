@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestRecordCrash(t *testing.T) {
+	option := &RunOptions{
+		Binary:     "greptime",
+		Name:       "datanode.0",
+		logDir:     t.TempDir(),
+		crashesDir: t.TempDir(),
+		crashDump:  &config.CrashDump{LogLines: 4},
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(option.logDir, logFileName), []byte(strings.Join([]string{
+		"2024-01-02T03:04:01.000Z  INFO started",
+		"2024-01-02T03:04:02.000Z  INFO flushed",
+		"thread 'main' panicked at src/datanode.rs:1:1:",
+		"boom",
+		"stack backtrace:",
+	}, "\n")+"\n"), 0644))
+
+	record, err := recordCrash(option, 100, "exit status 101")
+	assert.NoError(t, err)
+	assert.Equal(t, "datanode.0", record.Replica)
+	assert.True(t, record.Backtrace)
+	assert.Empty(t, record.CoreDump)
+
+	log, err := os.ReadFile(filepath.Join(option.crashesDir, record.Dir, CrashLogFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02T03:04:02.000Z  INFO flushed\nthread 'main' panicked at src/datanode.rs:1:1:\nboom\nstack backtrace:\n", string(log))
+	backtrace, err := os.ReadFile(filepath.Join(option.crashesDir, record.Dir, CrashBacktraceFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "thread 'main' panicked at src/datanode.rs:1:1:\nboom\nstack backtrace:\n", string(backtrace))
+
+	_, err = recordCrash(option, 101, "exit status 1")
+	assert.NoError(t, err)
+	crashes, err := ReadCrashes(option.crashesDir)
+	assert.NoError(t, err)
+	assert.Len(t, crashes, 2)
+	assert.Equal(t, 100, crashes[0].Pid)
+	assert.Equal(t, "exit status 101", crashes[0].Reason)
+	assert.Equal(t, 101, crashes[1].Pid)
+
+	crashes, err = ReadCrashes(t.TempDir())
+	assert.NoError(t, err)
+	assert.Empty(t, crashes)
+}
+
+func TestPanicBacktrace(t *testing.T) {
+	assert.Empty(t, panicBacktrace([]string{"INFO started", "ERROR failed"}))
+	assert.Equal(t, []string{"panic: runtime error", "goroutine 1"},
+		panicBacktrace([]string{"INFO started", "panic: runtime error", "goroutine 1"}))
+}
+
+func TestExpandCorePattern(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/var/crash/core.greptime.100.*", expandCorePattern("/var/crash/core.%e.%p.%t", 100, "/opt/bin/greptime"))
+	assert.Equal(t, "/cores/core.100", expandCorePattern("/cores/core.%P", 100, "greptime"))
+	assert.Equal(t, "/tmp/100%", expandCorePattern("/tmp/%p%%", 100, "greptime"))
+	assert.Equal(t, filepath.Join(wd, "core"), expandCorePattern("core", 100, "greptime"))
+}
@@ -19,17 +19,13 @@ package components
 import (
 	"context"
 	"fmt"
-	"net"
-	"net/http"
 	"path"
 	"sync"
-	"time"
 
 	greptimev1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 
 	"github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
-	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
 const (
@@ -41,6 +37,9 @@ type datanode struct {
 	config      *config.Datanode
 	metaSrvAddr string
 
+	// group is the name of datanode group, it's empty for the default datanodes.
+	group string
+
 	workingDirs WorkingDirs
 	wg          *sync.WaitGroup
 	logger      logger.Logger
@@ -60,67 +59,133 @@ func NewDataNode(config *config.Datanode, metaSrvAddr string, workingDirs Workin
 	}
 }
 
+// NewDatanodeGroup creates the datanodes of one datanode group, which are named after the group.
+func NewDatanodeGroup(group string, config *config.Datanode, metaSrvAddr string, workingDirs WorkingDirs,
+	wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
+	return &datanode{
+		config:      config,
+		metaSrvAddr: metaSrvAddr,
+		group:       group,
+		workingDirs: workingDirs,
+		wg:          wg,
+		logger:      logger,
+	}
+}
+
+// DatanodeGroupName returns the component name of the datanode group, e.g. "datanode-hot".
+func DatanodeGroupName(group string) string {
+	return fmt.Sprintf("%s-%s", greptimev1alpha1.DatanodeComponentKind, group)
+}
+
 func (d *datanode) Name() string {
+	if len(d.group) > 0 {
+		return DatanodeGroupName(d.group)
+	}
 	return string(greptimev1alpha1.DatanodeComponentKind)
 }
 
 func (d *datanode) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
-	for i := 0; i < d.config.Replicas; i++ {
-		dirName := fmt.Sprintf("%s.%d", d.Name(), i)
+	return d.StartReplicas(ctx, stop, binary, 0, d.config.Replicas)
+}
+
+func (d *datanode) StartReplicas(ctx context.Context, stop context.CancelFunc, binary string, from, to int) error {
+	binary, err := componentBinary(binary, d.config.BinaryPath)
+	if err != nil {
+		return err
+	}
+	if err := runHooks(ctx, HookPreStart, d.config.Hooks, d, d.config.Env, d.workingDirs, d.logger); err != nil {
+		return err
+	}
+
+	env, err := d.env()
+	if err != nil {
+		return err
+	}
+
+	// The data of datanodes can be placed out of the cluster, e.g. in a local SSD.
+	dataDir := d.workingDirs.DataDir
+	if len(d.config.DataDir) > 0 {
+		dataDir = d.config.DataDir
+	}
+
+	for i := from; i < to; i++ {
+		dirName := replicaDirName(d.Name(), i)
 
-		homeDir := path.Join(d.workingDirs.DataDir, dirName, dataHomeDir)
-		if err := fileutils.EnsureDir(homeDir); err != nil {
+		homeDir := path.Join(dataDir, dirName, dataHomeDir)
+		if err := ensureDir(d.workingDirs, homeDir); err != nil {
 			return err
 		}
 		d.dataHomeDirs = append(d.dataHomeDirs, homeDir)
 
 		datanodeLogDir := path.Join(d.workingDirs.LogsDir, dirName)
-		if err := fileutils.EnsureDir(datanodeLogDir); err != nil {
+		if err := ensureDir(d.workingDirs, datanodeLogDir); err != nil {
 			return err
 		}
 		d.logsDirs = append(d.logsDirs, datanodeLogDir)
 
 		datanodePidDir := path.Join(d.workingDirs.PidsDir, dirName)
-		if err := fileutils.EnsureDir(datanodePidDir); err != nil {
+		if err := ensureDir(d.workingDirs, datanodePidDir); err != nil {
 			return err
 		}
 		d.pidsDirs = append(d.pidsDirs, datanodePidDir)
 
-		walDir := path.Join(d.workingDirs.DataDir, dirName, dataWalDir)
-		if err := fileutils.EnsureDir(walDir); err != nil {
+		walDir := path.Join(dataDir, dirName, dataWalDir)
+		if err := ensureDir(d.workingDirs, walDir); err != nil {
 			return err
 		}
-		d.dataDirs = append(d.dataDirs, path.Join(d.workingDirs.DataDir, dirName))
+		d.dataDirs = append(d.dataDirs, path.Join(dataDir, dirName))
 
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: datanodeLogDir,
-			pidDir: datanodePidDir,
-			args:   d.BuildArgs(i, walDir, homeDir),
+			Binary:      binary,
+			Name:        dirName,
+			logDir:      datanodeLogDir,
+			pidDir:      datanodePidDir,
+			args:        d.BuildArgs(i, walDir, homeDir),
+			resources:   d.config.Resources,
+			env:         env,
+			restart:     d.config.Restart,
+			crashesDir:  d.workingDirs.CrashesDir,
+			crashDump:   d.workingDirs.CrashDump,
+			logRotation: d.workingDirs.LogRotation,
+			events:      d.workingDirs.Events,
+			dryRun:      d.workingDirs.DryRun,
 		}
-		if err := runBinary(ctx, stop, option, d.wg, d.logger); err != nil {
+		if err := runBinary(stop, option, d.wg, d.logger); err != nil {
 			return err
 		}
 	}
 
-	// Checking component running status with intervals.
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+	if err := waitForReady(ctx, d, d.config.Readiness, d.allocatedDirs, d.workingDirs, d.logger); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, HookPostStart, d.config.Hooks, d, d.config.Env, d.workingDirs, d.logger)
+}
 
-CHECKER:
-	for {
-		select {
-		case <-ticker.C:
-			if d.IsRunning(ctx) {
-				break CHECKER
-			}
-		case <-ctx.Done():
-			return fmt.Errorf("status checking failed: %v", ctx.Err())
-		}
+// env returns the environment variables of datanode, which include the ones that configure
+// the object storage. The env in the config takes precedence over the storage ones.
+func (d *datanode) env() (map[string]string, error) {
+	env, err := storageEnv(d.config.Storage)
+	if err != nil {
+		return nil, err
 	}
+	if len(env) == 0 {
+		return d.config.Env, nil
+	}
+
+	for k, v := range d.config.Env {
+		env[k] = v
+	}
+	return env, nil
+}
+
+func (d *datanode) Stop(ctx context.Context) error {
+	return d.StopReplicas(ctx, 0, d.config.Replicas)
+}
 
-	return nil
+func (d *datanode) StopReplicas(ctx context.Context, from, to int) error {
+	runPreStopHooks(ctx, d.config.Hooks, d, d.config.Env, d.workingDirs, d.logger)
+	return stopReplicaRange(ctx, d.Name(), from, to, d.workingDirs, d.logger)
 }
 
 func (d *datanode) BuildArgs(params ...interface{}) []string {
@@ -129,49 +194,54 @@ func (d *datanode) BuildArgs(params ...interface{}) []string {
 		logLevel = DefaultLogLevel
 	}
 
-	nodeID_, _, homeDir := params[0], params[1], params[2]
-	nodeID := nodeID_.(int)
+	replica_, _, homeDir := params[0], params[1], params[2]
+	replica := replica_.(int)
 
 	args := []string{
 		fmt.Sprintf("--log-level=%s", logLevel),
-		d.Name(), "start",
-		fmt.Sprintf("--node-id=%d", nodeID),
+		string(greptimev1alpha1.DatanodeComponentKind), "start",
+		fmt.Sprintf("--node-id=%d", d.config.NodeID+replica),
 		fmt.Sprintf("--metasrv-addrs=%s", d.metaSrvAddr),
 		fmt.Sprintf("--data-home=%s", homeDir),
 	}
-	args = GenerateAddrArg("--http-addr", d.config.HTTPAddr, nodeID, args)
-	args = GenerateAddrArg("--rpc-addr", d.config.RPCAddr, nodeID, args)
+	args = GenerateReplicaAddrArg("--http-addr", d.config.HTTPAddr, d.config.ReplicaAddrs, replica, args)
+	args = GenerateReplicaAddrArg("--rpc-addr", d.config.RPCAddr, d.config.ReplicaAddrs, replica, args)
 
-	if len(d.config.Config) > 0 {
-		args = append(args, fmt.Sprintf("-c=%s", d.config.Config))
-	}
+	args = GenerateConfigArg(d.config.Config, d.config.Configs, replica, args)
 
 	return args
 }
 
-func (d *datanode) IsRunning(_ context.Context) bool {
+func (d *datanode) ListenAddrs() []ListenAddr {
+	var addrs []ListenAddr
 	for i := 0; i < d.config.Replicas; i++ {
-		addr := FormatAddrArg(d.config.HTTPAddr, i)
-		_, httpPort, err := net.SplitHostPort(addr)
-		if err != nil {
-			d.logger.V(5).Infof("failed to split host port in %s: %s", d.Name(), err)
-			return false
-		}
+		addrs = append(addrs, replicaListenAddrs(d.Name(), i, d.config.ReplicaAddrs,
+			"--http-addr", d.config.HTTPAddr,
+			"--rpc-addr", d.config.RPCAddr)...)
+	}
+	return addrs
+}
 
-		rsp, err := http.Get(fmt.Sprintf("http://localhost:%s/health", httpPort))
-		if err != nil {
-			d.logger.V(5).Infof("failed to get %s health: %s", d.Name(), err)
-			return false
-		}
+func (d *datanode) IsRunning(ctx context.Context) bool {
+	return d.healthProbe().isReplicasHealthy(ctx, d.config.Replicas)
+}
 
-		if rsp.StatusCode != http.StatusOK {
-			return false
-		}
+func (d *datanode) Status(ctx context.Context) []ReplicaStatus {
+	probe := d.healthProbe()
+	return replicasStatus(d.Name(), d.config.Replicas, d.workingDirs, func(replica int) bool {
+		return probe.isReplicaHealthy(ctx, replica)
+	})
+}
 
-		if err = rsp.Body.Close(); err != nil {
-			return false
-		}
+func (d *datanode) healthProbe() *healthProbe {
+	return &healthProbe{
+		name:         d.Name(),
+		check:        d.config.HealthCheck,
+		httpAddr:     d.config.HTTPAddr,
+		grpcAddr:     d.config.RPCAddr,
+		grpcArg:      "--rpc-addr",
+		replicaAddrs: d.config.ReplicaAddrs,
+		healthHost:   d.config.HealthHost,
+		logger:       d.logger,
 	}
-
-	return true
 }
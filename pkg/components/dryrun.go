@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// DryRun records the directories, files and commands that the components would create and run in
+// dry-run mode, instead of creating and running them. It's set in the WorkingDirs of components.
+type DryRun struct {
+	Dirs     []string
+	Files    []DryRunFile
+	Commands []DryRunCommand
+}
+
+// DryRunFile is the file that would be generated.
+type DryRunFile struct {
+	Path    string
+	Content string
+}
+
+// DryRunCommand is the command that would be run.
+type DryRunCommand struct {
+	// Name is the name of replica or hook that runs the command, e.g. "frontend.0".
+	Name string
+
+	// Env is the environment variables that are set besides the environment of gtctl.
+	Env map[string]string
+
+	// Argv is the full argv of command, starting with the binary.
+	Argv []string
+}
+
+// AddDir records the directory, the duplicated ones are recorded once.
+func (d *DryRun) AddDir(dir string) {
+	for _, added := range d.Dirs {
+		if added == dir {
+			return
+		}
+	}
+	d.Dirs = append(d.Dirs, dir)
+}
+
+// AddFile records the file with its content.
+func (d *DryRun) AddFile(path, content string) {
+	d.Files = append(d.Files, DryRunFile{Path: path, Content: content})
+}
+
+// AddCommand records the command of argv that is run by name with the env.
+func (d *DryRun) AddCommand(name string, env map[string]string, argv ...string) {
+	d.Commands = append(d.Commands, DryRunCommand{Name: name, Env: env, Argv: argv})
+}
+
+// ensureDir creates the directory, or records it in dry-run mode.
+func ensureDir(workingDirs WorkingDirs, dir string) error {
+	if workingDirs.DryRun != nil {
+		workingDirs.DryRun.AddDir(dir)
+		return nil
+	}
+	return fileutils.EnsureDir(dir)
+}
+
+// writeFile writes the content into the file, or records it in dry-run mode.
+func writeFile(workingDirs WorkingDirs, path, content string) error {
+	if workingDirs.DryRun != nil {
+		workingDirs.DryRun.AddFile(path, content)
+		return nil
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
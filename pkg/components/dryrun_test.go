@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestStandaloneDryRun(t *testing.T) {
+	dir := t.TempDir()
+	dryRun := &DryRun{}
+	workingDirs := WorkingDirs{
+		DataDir: path.Join(dir, "data"),
+		LogsDir: path.Join(dir, "logs"),
+		PidsDir: path.Join(dir, "pids"),
+		DryRun:  dryRun,
+	}
+	cfg := &config.Standalone{
+		HTTPAddr: "0.0.0.0:4000",
+		Env:      map[string]string{"SCHEMA": "metrics"},
+		Hooks:    &config.Hooks{PostStart: []string{"echo started"}},
+	}
+	s := NewStandalone(cfg, workingDirs, nil, logger.New(io.Discard, 0))
+
+	assert.NoError(t, s.Start(context.Background(), nil, "/bin/greptime"))
+
+	assert.Equal(t, []string{
+		path.Join(dir, "data", "standalone.0", dataHomeDir),
+		path.Join(dir, "logs", "standalone.0"),
+		path.Join(dir, "pids", "standalone.0"),
+	}, dryRun.Dirs)
+	assert.Len(t, dryRun.Commands, 2)
+	assert.Equal(t, DryRunCommand{
+		Name: "standalone.0",
+		Env:  cfg.Env,
+		Argv: append([]string{"/bin/greptime"}, s.BuildArgs(path.Join(dir, "data", "standalone.0", dataHomeDir))...),
+	}, dryRun.Commands[0])
+	assert.Equal(t, "standalone postStart hook", dryRun.Commands[1].Name)
+	assert.Equal(t, []string{"sh", "-c", "echo started"}, dryRun.Commands[1].Argv)
+	assert.Equal(t, "metrics", dryRun.Commands[1].Env["SCHEMA"])
+
+	// Nothing is created in dry-run mode.
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
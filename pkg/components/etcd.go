@@ -18,14 +18,25 @@ package components
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
 	"path"
 	"sync"
 
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
-	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
+// EtcdComponentName is the name of the embedded etcd component.
+const EtcdComponentName = "etcd"
+
+// defaultEtcdClientAddr is the address that etcd serves the clients by default.
+const defaultEtcdClientAddr = "localhost:2379"
+
 type etcd struct {
+	clientAddr string
+
 	workingDirs WorkingDirs
 	wg          *sync.WaitGroup
 	logger      logger.Logger
@@ -33,8 +44,10 @@ type etcd struct {
 	allocatedDirs
 }
 
-func NewEtcd(workingDirs WorkingDirs, wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
+// NewEtcd creates the embedded etcd component, which serves the clients on clientAddr.
+func NewEtcd(clientAddr string, workingDirs WorkingDirs, wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
 	return &etcd{
+		clientAddr:  clientAddr,
 		workingDirs: workingDirs,
 		wg:          wg,
 		logger:      logger,
@@ -42,7 +55,7 @@ func NewEtcd(workingDirs WorkingDirs, wg *sync.WaitGroup, logger logger.Logger)
 }
 
 func (e *etcd) Name() string {
-	return "etcd"
+	return EtcdComponentName
 }
 
 func (e *etcd) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
@@ -53,7 +66,7 @@ func (e *etcd) Start(ctx context.Context, stop context.CancelFunc, binary string
 		etcdDirs    = []string{etcdDataDir, etcdLogDir, etcdPidDir}
 	)
 	for _, dir := range etcdDirs {
-		if err := fileutils.EnsureDir(dir); err != nil {
+		if err := ensureDir(e.workingDirs, dir); err != nil {
 			return err
 		}
 	}
@@ -62,24 +75,96 @@ func (e *etcd) Start(ctx context.Context, stop context.CancelFunc, binary string
 	e.pidsDirs = append(e.pidsDirs, etcdPidDir)
 
 	option := &RunOptions{
-		Binary: binary,
-		Name:   e.Name(),
-		logDir: etcdLogDir,
-		pidDir: etcdPidDir,
-		args:   e.BuildArgs(etcdDataDir),
+		Binary:      binary,
+		Name:        e.Name(),
+		logDir:      etcdLogDir,
+		pidDir:      etcdPidDir,
+		args:        e.BuildArgs(etcdDataDir),
+		crashesDir:  e.workingDirs.CrashesDir,
+		crashDump:   e.workingDirs.CrashDump,
+		logRotation: e.workingDirs.LogRotation,
+		events:      e.workingDirs.Events,
+		dryRun:      e.workingDirs.DryRun,
 	}
-	if err := runBinary(ctx, stop, option, e.wg, e.logger); err != nil {
+	if err := runBinary(stop, option, e.wg, e.logger); err != nil {
 		return err
 	}
 
-	return nil
+	return waitForReady(ctx, e, nil, e.allocatedDirs, e.workingDirs, e.logger)
+}
+
+func (e *etcd) Stop(ctx context.Context) error {
+	return stopProcessInTime(ctx, path.Join(e.workingDirs.PidsDir, e.Name()), e.workingDirs.DrainTimeout, e.logger)
 }
 
 func (e *etcd) BuildArgs(params ...interface{}) []string {
-	return []string{"--data-dir", params[0].(string)}
+	args := []string{"--data-dir", params[0].(string)}
+	if len(e.clientAddr) == 0 {
+		return args
+	}
+
+	advertiseAddr, err := HealthCheckAddr(e.clientAddr, "")
+	if err != nil {
+		advertiseAddr = e.clientAddr
+	}
+	peerURL := fmt.Sprintf("http://%s", e.peerAddr())
+	return append(args,
+		"--listen-client-urls", fmt.Sprintf("http://%s", e.clientAddr),
+		"--advertise-client-urls", fmt.Sprintf("http://%s", advertiseAddr),
+		"--listen-peer-urls", peerURL,
+		"--initial-advertise-peer-urls", peerURL,
+		"--initial-cluster", fmt.Sprintf("default=%s", peerURL),
+	)
+}
+
+func (e *etcd) ListenAddrs() []ListenAddr {
+	clientAddr := e.clientAddr
+	if len(clientAddr) == 0 {
+		clientAddr = defaultEtcdClientAddr
+	}
+	return []ListenAddr{
+		{Replica: e.Name(), Arg: "--listen-client-urls", Addr: clientAddr},
+		{Replica: e.Name(), Arg: "--listen-peer-urls", Addr: e.peerAddr()},
+	}
+}
+
+// peerAddr returns the peer address of etcd, which listens on localhost and the next port of
+// the client address, e.g. "localhost:2380" for the default client address "localhost:2379".
+func (e *etcd) peerAddr() string {
+	clientAddr := e.clientAddr
+	if len(clientAddr) == 0 {
+		clientAddr = defaultEtcdClientAddr
+	}
+	_, port, _ := net.SplitHostPort(FormatAddrArg(clientAddr, 1))
+	return net.JoinHostPort("localhost", port)
 }
 
 func (e *etcd) IsRunning(_ context.Context) bool {
-	// Have not implemented the healthy checker now.
-	return false
+	addr, err := HealthCheckAddr(e.ListenAddrs()[0].Addr, "")
+	if err != nil {
+		e.logger.V(5).Infof("failed to get health check address of %s: %s", e.Name(), err)
+		return false
+	}
+
+	rsp, err := http.Get(fmt.Sprintf("http://%s/health", addr))
+	if err != nil {
+		e.logger.V(5).Infof("failed to get %s health: %s", e.Name(), err)
+		return false
+	}
+	defer rsp.Body.Close()
+
+	// The health endpoint of etcd responds with '{"health":"true"}' if it's healthy.
+	var health struct {
+		Health string `json:"health"`
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&health); err != nil {
+		e.logger.V(5).Infof("failed to decode %s health: %s", e.Name(), err)
+		return false
+	}
+	return health.Health == "true"
+}
+
+func (e *etcd) Status(ctx context.Context) []ReplicaStatus {
+	return []ReplicaStatus{replicaStatus(e.Name(), path.Join(e.workingDirs.PidsDir, e.Name()),
+		func() bool { return e.IsRunning(ctx) })}
 }
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestEtcdBuildArgs(t *testing.T) {
+	e := NewEtcd("0.0.0.0:2379", WorkingDirs{}, nil, logger.New(io.Discard, 0))
+	assert.Equal(t, []string{
+		"--data-dir", "/tmp/etcd",
+		"--listen-client-urls", "http://0.0.0.0:2379",
+		"--advertise-client-urls", "http://localhost:2379",
+		"--listen-peer-urls", "http://localhost:2380",
+		"--initial-advertise-peer-urls", "http://localhost:2380",
+		"--initial-cluster", "default=http://localhost:2380",
+	}, e.BuildArgs("/tmp/etcd"))
+
+	assert.Equal(t, []ListenAddr{
+		{Replica: EtcdComponentName, Arg: "--listen-client-urls", Addr: "0.0.0.0:2379"},
+		{Replica: EtcdComponentName, Arg: "--listen-peer-urls", Addr: "localhost:2380"},
+	}, e.ListenAddrs())
+
+	// The peer address follows the client address, so multiple etcds can run on one host.
+	e = NewEtcd("127.0.0.1:12379", WorkingDirs{}, nil, logger.New(io.Discard, 0))
+	assert.Equal(t, "localhost:12380", e.ListenAddrs()[1].Addr)
+}
+
+func TestEtcdIsRunning(t *testing.T) {
+	health := "true"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		fmt.Fprintf(w, `{"health":"%s","reason":""}`, health)
+	}))
+	defer server.Close()
+
+	e := NewEtcd(strings.TrimPrefix(server.URL, "http://"), WorkingDirs{}, nil, logger.New(io.Discard, 0))
+	assert.True(t, e.IsRunning(context.Background()))
+
+	health = "false"
+	assert.False(t, e.IsRunning(context.Background()))
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// FlownodeComponentName is the name of flownode component, the greptimedb-operator
+// has not defined the component kind of flownode yet.
+const FlownodeComponentName = "flownode"
+
+type flownode struct {
+	config      *config.Flownode
+	metaSrvAddr string
+
+	workingDirs WorkingDirs
+	wg          *sync.WaitGroup
+	logger      logger.Logger
+
+	allocatedDirs
+}
+
+func NewFlownode(config *config.Flownode, metaSrvAddr string, workingDirs WorkingDirs,
+	wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
+	return &flownode{
+		config:      config,
+		metaSrvAddr: metaSrvAddr,
+		workingDirs: workingDirs,
+		wg:          wg,
+		logger:      logger,
+	}
+}
+
+func (f *flownode) Name() string {
+	return FlownodeComponentName
+}
+
+func (f *flownode) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+	return f.StartReplicas(ctx, stop, binary, 0, f.config.Replicas)
+}
+
+func (f *flownode) StartReplicas(ctx context.Context, stop context.CancelFunc, binary string, from, to int) error {
+	binary, err := componentBinary(binary, f.config.BinaryPath)
+	if err != nil {
+		return err
+	}
+	if err := runHooks(ctx, HookPreStart, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger); err != nil {
+		return err
+	}
+
+	for i := from; i < to; i++ {
+		dirName := replicaDirName(f.Name(), i)
+
+		flownodeLogDir := path.Join(f.workingDirs.LogsDir, dirName)
+		if err := ensureDir(f.workingDirs, flownodeLogDir); err != nil {
+			return err
+		}
+		f.logsDirs = append(f.logsDirs, flownodeLogDir)
+
+		flownodePidDir := path.Join(f.workingDirs.PidsDir, dirName)
+		if err := ensureDir(f.workingDirs, flownodePidDir); err != nil {
+			return err
+		}
+		f.pidsDirs = append(f.pidsDirs, flownodePidDir)
+
+		option := &RunOptions{
+			Binary:      binary,
+			Name:        dirName,
+			logDir:      flownodeLogDir,
+			pidDir:      flownodePidDir,
+			args:        f.BuildArgs(i),
+			resources:   f.config.Resources,
+			env:         f.config.Env,
+			restart:     f.config.Restart,
+			crashesDir:  f.workingDirs.CrashesDir,
+			crashDump:   f.workingDirs.CrashDump,
+			logRotation: f.workingDirs.LogRotation,
+			events:      f.workingDirs.Events,
+			dryRun:      f.workingDirs.DryRun,
+		}
+		if err := runBinary(stop, option, f.wg, f.logger); err != nil {
+			return err
+		}
+	}
+
+	if err := waitForReady(ctx, f, f.config.Readiness, f.allocatedDirs, f.workingDirs, f.logger); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, HookPostStart, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger)
+}
+
+func (f *flownode) Stop(ctx context.Context) error {
+	return f.StopReplicas(ctx, 0, f.config.Replicas)
+}
+
+func (f *flownode) StopReplicas(ctx context.Context, from, to int) error {
+	runPreStopHooks(ctx, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger)
+	return stopReplicaRange(ctx, f.Name(), from, to, f.workingDirs, f.logger)
+}
+
+func (f *flownode) BuildArgs(params ...interface{}) []string {
+	logLevel := f.config.LogLevel
+	if logLevel == "" {
+		logLevel = DefaultLogLevel
+	}
+
+	nodeID := params[0].(int)
+
+	args := []string{
+		fmt.Sprintf("--log-level=%s", logLevel),
+		f.Name(), "start",
+		fmt.Sprintf("--node-id=%d", nodeID),
+		fmt.Sprintf("--metasrv-addrs=%s", f.metaSrvAddr),
+	}
+	args = GenerateReplicaAddrArg("--http-addr", f.config.HTTPAddr, f.config.ReplicaAddrs, nodeID, args)
+	args = GenerateReplicaAddrArg("--rpc-addr", f.config.RPCAddr, f.config.ReplicaAddrs, nodeID, args)
+
+	args = GenerateConfigArg(f.config.Config, f.config.Configs, nodeID, args)
+
+	return args
+}
+
+func (f *flownode) ListenAddrs() []ListenAddr {
+	var addrs []ListenAddr
+	for i := 0; i < f.config.Replicas; i++ {
+		addrs = append(addrs, replicaListenAddrs(f.Name(), i, f.config.ReplicaAddrs,
+			"--http-addr", f.config.HTTPAddr,
+			"--rpc-addr", f.config.RPCAddr)...)
+	}
+	return addrs
+}
+
+func (f *flownode) IsRunning(ctx context.Context) bool {
+	return f.healthProbe().isReplicasHealthy(ctx, f.config.Replicas)
+}
+
+func (f *flownode) Status(ctx context.Context) []ReplicaStatus {
+	probe := f.healthProbe()
+	return replicasStatus(f.Name(), f.config.Replicas, f.workingDirs, func(replica int) bool {
+		return probe.isReplicaHealthy(ctx, replica)
+	})
+}
+
+func (f *flownode) healthProbe() *healthProbe {
+	return &healthProbe{
+		name:         f.Name(),
+		check:        f.config.HealthCheck,
+		httpAddr:     f.config.HTTPAddr,
+		grpcAddr:     f.config.RPCAddr,
+		grpcArg:      "--rpc-addr",
+		replicaAddrs: f.config.ReplicaAddrs,
+		healthHost:   f.config.HealthHost,
+		logger:       f.logger,
+	}
+}
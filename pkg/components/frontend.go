@@ -19,7 +19,6 @@ package components
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"path"
 	"sync"
 
@@ -27,13 +26,24 @@ import (
 
 	"github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
-	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/certs"
+)
+
+const (
+	// defaultFrontendHTTPAddr is the HTTP address that frontend binds by default.
+	defaultFrontendHTTPAddr = "127.0.0.1:4000"
+
+	// defaultFrontendGRPCAddr is the gRPC address that frontend binds by default.
+	defaultFrontendGRPCAddr = "127.0.0.1:4001"
 )
 
 type frontend struct {
 	config      *config.Frontend
 	metaSrvAddr string
 
+	// tlsPaths is nil if the TLS is not enabled.
+	tlsPaths *certs.Paths
+
 	workingDirs WorkingDirs
 	wg          *sync.WaitGroup
 	logger      logger.Logger
@@ -57,34 +67,87 @@ func (f *frontend) Name() string {
 }
 
 func (f *frontend) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
-	for i := 0; i < f.config.Replicas; i++ {
-		dirName := fmt.Sprintf("%s.%d", f.Name(), i)
+	return f.StartReplicas(ctx, stop, binary, 0, f.config.Replicas)
+}
+
+func (f *frontend) StartReplicas(ctx context.Context, stop context.CancelFunc, binary string, from, to int) error {
+	binary, err := componentBinary(binary, f.config.BinaryPath)
+	if err != nil {
+		return err
+	}
+	if err := runHooks(ctx, HookPreStart, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger); err != nil {
+		return err
+	}
+
+	tlsPaths, err := serverTLSPaths(f.config.TLS, f.workingDirs, CertHosts(f.ListenAddrs()), f.logger)
+	if err != nil {
+		return err
+	}
+	f.tlsPaths = tlsPaths
+	env := f.env()
+
+	for i := from; i < to; i++ {
+		dirName := replicaDirName(f.Name(), i)
 
 		frontendLogDir := path.Join(f.workingDirs.LogsDir, dirName)
-		if err := fileutils.EnsureDir(frontendLogDir); err != nil {
+		if err := ensureDir(f.workingDirs, frontendLogDir); err != nil {
 			return err
 		}
 		f.logsDirs = append(f.logsDirs, frontendLogDir)
 
 		frontendPidDir := path.Join(f.workingDirs.PidsDir, dirName)
-		if err := fileutils.EnsureDir(frontendPidDir); err != nil {
+		if err := ensureDir(f.workingDirs, frontendPidDir); err != nil {
 			return err
 		}
 		f.pidsDirs = append(f.pidsDirs, frontendPidDir)
 
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: frontendLogDir,
-			pidDir: frontendPidDir,
-			args:   f.BuildArgs(i),
+			Binary:      binary,
+			Name:        dirName,
+			logDir:      frontendLogDir,
+			pidDir:      frontendPidDir,
+			args:        f.BuildArgs(i),
+			resources:   f.config.Resources,
+			env:         env,
+			restart:     f.config.Restart,
+			crashesDir:  f.workingDirs.CrashesDir,
+			crashDump:   f.workingDirs.CrashDump,
+			logRotation: f.workingDirs.LogRotation,
+			events:      f.workingDirs.Events,
+			dryRun:      f.workingDirs.DryRun,
 		}
-		if err := runBinary(ctx, stop, option, f.wg, f.logger); err != nil {
+		if err := runBinary(stop, option, f.wg, f.logger); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if err := waitForReady(ctx, f, f.config.Readiness, f.allocatedDirs, f.workingDirs, f.logger); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, HookPostStart, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger)
+}
+
+// env returns the environment variables of frontend, the env in config takes precedence.
+func (f *frontend) env() map[string]string {
+	env := grpcTLSEnv(f.Name(), TLSMode(f.config.TLS), f.tlsPaths)
+	if len(env) == 0 {
+		return f.config.Env
+	}
+
+	for k, v := range f.config.Env {
+		env[k] = v
+	}
+	return env
+}
+
+func (f *frontend) Stop(ctx context.Context) error {
+	return f.StopReplicas(ctx, 0, f.config.Replicas)
+}
+
+func (f *frontend) StopReplicas(ctx context.Context, from, to int) error {
+	runPreStopHooks(ctx, f.config.Hooks, f, f.config.Env, f.workingDirs, f.logger)
+	return stopReplicaRange(ctx, f.Name(), from, to, f.workingDirs, f.logger)
 }
 
 func (f *frontend) BuildArgs(params ...interface{}) []string {
@@ -101,40 +164,60 @@ func (f *frontend) BuildArgs(params ...interface{}) []string {
 		fmt.Sprintf("--metasrv-addrs=%s", f.metaSrvAddr),
 	}
 
-	args = GenerateAddrArg("--http-addr", f.config.HTTPAddr, nodeId, args)
-	args = GenerateAddrArg("--rpc-addr", f.config.GRPCAddr, nodeId, args)
-	args = GenerateAddrArg("--mysql-addr", f.config.MysqlAddr, nodeId, args)
-	args = GenerateAddrArg("--postgres-addr", f.config.PostgresAddr, nodeId, args)
+	args = GenerateReplicaAddrArg("--http-addr", f.config.HTTPAddr, f.config.ReplicaAddrs, nodeId, args)
+	args = GenerateReplicaAddrArg("--rpc-addr", f.config.GRPCAddr, f.config.ReplicaAddrs, nodeId, args)
+	args = GenerateReplicaAddrArg("--mysql-addr", f.config.MysqlAddr, f.config.ReplicaAddrs, nodeId, args)
+	args = GenerateReplicaAddrArg("--postgres-addr", f.config.PostgresAddr, f.config.ReplicaAddrs, nodeId, args)
 
-	if len(f.config.Config) > 0 {
-		args = append(args, fmt.Sprintf("-c=%s", f.config.Config))
-	}
+	args = append(args, tlsArgs(TLSMode(f.config.TLS), f.tlsPaths)...)
+
+	args = GenerateConfigArg(f.config.Config, f.config.Configs, nodeId, args)
 	if len(f.config.UserProvider) > 0 {
 		args = append(args, fmt.Sprintf("--user-provider=%s", f.config.UserProvider))
 	}
 	return args
 }
 
-func (f *frontend) IsRunning(_ context.Context) bool {
+func (f *frontend) ListenAddrs() []ListenAddr {
+	var addrs []ListenAddr
 	for i := 0; i < f.config.Replicas; i++ {
-		addr := FormatAddrArg(f.config.HTTPAddr, i)
-		healthy := fmt.Sprintf("http://%s/health", addr)
+		addrs = append(addrs, replicaListenAddrs(f.Name(), i, f.config.ReplicaAddrs,
+			"--http-addr", f.config.HTTPAddr,
+			"--rpc-addr", f.config.GRPCAddr,
+			"--mysql-addr", f.config.MysqlAddr,
+			"--postgres-addr", f.config.PostgresAddr)...)
+	}
+	return addrs
+}
 
-		resp, err := http.Get(healthy)
-		if err != nil {
-			f.logger.V(5).Infof("Failed to get %s healthy: %s", f.Name(), err)
-			return false
-		}
+func (f *frontend) IsRunning(ctx context.Context) bool {
+	return f.healthProbe().isReplicasHealthy(ctx, f.config.Replicas)
+}
 
-		if resp.StatusCode != http.StatusOK {
-			f.logger.V(5).Infof("%s is not healthy: %s", f.Name(), resp)
-			return false
-		}
+func (f *frontend) Status(ctx context.Context) []ReplicaStatus {
+	probe := f.healthProbe()
+	return replicasStatus(f.Name(), f.config.Replicas, f.workingDirs, func(replica int) bool {
+		return probe.isReplicaHealthy(ctx, replica)
+	})
+}
 
-		if err = resp.Body.Close(); err != nil {
-			f.logger.V(5).Infof("%s is not healthy: %s, err: %s", f.Name(), resp, err)
-			return false
-		}
+func (f *frontend) healthProbe() *healthProbe {
+	probe := &healthProbe{
+		name:         f.Name(),
+		check:        f.config.HealthCheck,
+		httpAddr:     f.config.HTTPAddr,
+		grpcAddr:     f.config.GRPCAddr,
+		grpcArg:      "--rpc-addr",
+		replicaAddrs: f.config.ReplicaAddrs,
+		healthHost:   f.config.HealthHost,
+		tls:          len(TLSMode(f.config.TLS)) > 0,
+		logger:       f.logger,
+	}
+	if len(probe.httpAddr) == 0 {
+		probe.httpAddr = defaultFrontendHTTPAddr
+	}
+	if len(probe.grpcAddr) == 0 {
+		probe.grpcAddr = defaultFrontendGRPCAddr
 	}
-	return true
+	return probe
 }
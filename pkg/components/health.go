@@ -0,0 +1,195 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	// defaultHealthPath is the HTTP path of health API that GreptimeDB serves.
+	defaultHealthPath = "/health"
+
+	// healthCheckTimeout is the max duration of one health check.
+	healthCheckTimeout = 5 * time.Second
+)
+
+// healthProbe checks the health of the replicas of one component through the health check in its config.
+type healthProbe struct {
+	name  string
+	check *config.HealthCheck
+
+	// httpAddr and grpcAddr are the listen addresses of the first replica, and grpcArg is the argument of
+	// grpcAddr. The listen addresses of the other replicas follow them unless overridden by replicaAddrs.
+	httpAddr     string
+	grpcAddr     string
+	grpcArg      string
+	replicaAddrs config.ReplicaAddrs
+	healthHost   string
+
+	// tls is whether the gRPC server serves TLS, the certificate of server is not verified by the health check.
+	tls bool
+
+	logger logger.Logger
+}
+
+// healthChecked is implemented by the components whose health check is configurable.
+type healthChecked interface {
+	healthProbe() *healthProbe
+}
+
+// HealthCheckArg returns the argument of the listen address that the health check of component probes.
+func HealthCheckArg(component ClusterComponent) string {
+	if c, ok := component.(healthChecked); ok {
+		return c.healthProbe().arg()
+	}
+	return "--http-addr"
+}
+
+// CheckHealth checks the health of the replica of component that listens on addr,
+// which is the address of the argument returned by HealthCheckArg.
+func CheckHealth(ctx context.Context, component ClusterComponent, addr string) error {
+	probe := &healthProbe{}
+	if c, ok := component.(healthChecked); ok {
+		probe = c.healthProbe()
+	}
+	return probe.probe(ctx, addr)
+}
+
+func (p *healthProbe) useGRPC() bool {
+	return p.check != nil && p.check.Protocol == config.HealthCheckProtocolGRPC
+}
+
+func (p *healthProbe) arg() string {
+	if p.useGRPC() {
+		return p.grpcArg
+	}
+	return "--http-addr"
+}
+
+// isReplicasHealthy checks the health of all the replicas.
+func (p *healthProbe) isReplicasHealthy(ctx context.Context, replicas int) bool {
+	// The replicas are probed in parallel, so a replica that hangs doesn't delay probing the others.
+	healthy := make([]bool, replicas)
+	var wg sync.WaitGroup
+	for i := 0; i < replicas; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			healthy[i] = p.isReplicaHealthy(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, ok := range healthy {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isReplicaHealthy checks the health of one replica.
+func (p *healthProbe) isReplicaHealthy(ctx context.Context, replica int) bool {
+	listenAddr := p.httpAddr
+	if p.useGRPC() {
+		listenAddr = p.grpcAddr
+	}
+	addr, err := HealthCheckAddr(ReplicaAddr(p.replicaAddrs, p.arg(), listenAddr, replica), p.healthHost)
+	if err != nil {
+		p.logger.V(5).Infof("failed to get health check address of %s: %s", p.name, err)
+		return false
+	}
+
+	if err = p.probe(ctx, addr); err != nil {
+		p.logger.V(5).Infof("%s is not healthy: %s", p.name, err)
+		return false
+	}
+	return true
+}
+
+// probe checks the health of the replica on addr.
+func (p *healthProbe) probe(ctx context.Context, addr string) error {
+	if len(addr) == 0 {
+		return fmt.Errorf("no address to check")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if p.useGRPC() {
+		return p.probeGRPC(ctx, addr)
+	}
+	return p.probeHTTP(ctx, addr)
+}
+
+func (p *healthProbe) probeHTTP(ctx context.Context, addr string) error {
+	path := defaultHealthPath
+	if p.check != nil && len(p.check.Path) > 0 {
+		path = p.check.Path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s%s", addr, path), nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responds with %s", path, rsp.Status)
+	}
+	return nil
+}
+
+func (p *healthProbe) probeGRPC(ctx context.Context, addr string) error {
+	creds := insecure.NewCredentials()
+	if p.tls {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rsp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.check.Service})
+	if err != nil {
+		return err
+	}
+	if rsp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("the gRPC health status is %s", rsp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestHealthCheckPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	cfg := &config.Datanode{HTTPAddr: addr, RPCAddr: "127.0.0.1:4100", Replicas: 1}
+	d := NewDataNode(cfg, "", WorkingDirs{}, nil, logger.New(io.Discard, 0)).(*datanode)
+	assert.False(t, d.IsRunning(context.Background()))
+
+	cfg.HealthCheck = &config.HealthCheck{Path: "/ready"}
+	assert.True(t, d.IsRunning(context.Background()))
+	assert.NoError(t, CheckHealth(context.Background(), d, addr))
+	assert.Equal(t, "--http-addr", HealthCheckArg(d))
+}
+
+func TestHealthCheckArg(t *testing.T) {
+	cfg := &config.MetaSrv{HealthCheck: &config.HealthCheck{Protocol: config.HealthCheckProtocolGRPC}}
+	m := NewMetaSrv(cfg, WorkingDirs{}, nil, logger.New(io.Discard, 0), false)
+	assert.Equal(t, "--bind-addr", HealthCheckArg(m))
+
+	e := NewEtcd("0.0.0.0:2379", WorkingDirs{}, nil, logger.New(io.Discard, 0))
+	assert.Equal(t, "--http-addr", HealthCheckArg(e))
+}
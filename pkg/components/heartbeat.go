@@ -0,0 +1,122 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// joinCheckInterval is the interval of checking whether the datanodes have joined the cluster.
+const joinCheckInterval = time.Second
+
+// HeartbeatEnv returns the environment variables that configure the heartbeat
+// of the component to metasrv, i.e. datanode, flownode and frontend.
+func HeartbeatEnv(heartbeat *config.Heartbeat, component string) map[string]string {
+	if heartbeat == nil {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("GREPTIMEDB_%s__HEARTBEAT__", strings.ToUpper(component))
+	env := make(map[string]string)
+	if heartbeat.Interval > 0 {
+		env[prefix+"INTERVAL"] = humanDuration(heartbeat.Interval)
+	}
+	if heartbeat.RetryInterval > 0 {
+		env[prefix+"RETRY_INTERVAL"] = humanDuration(heartbeat.RetryInterval)
+	}
+	return env
+}
+
+// humanDuration formats the duration in milliseconds, since GreptimeDB
+// can't parse the fractional durations like "1.5s".
+func humanDuration(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// WaitForDatanodes waits for the datanodes with the node ids to join the cluster, i.e. their
+// heartbeats are reported by the admin API of the first replica of metasrv.
+func WaitForDatanodes(ctx context.Context, metaSrv *config.MetaSrv, nodeIDs []int, logger logger.Logger) error {
+	addr, err := HealthCheckAddr(ReplicaAddr(metaSrv.ReplicaAddrs, "--http-addr", metaSrv.HTTPAddr, 0), metaSrv.HealthHost)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(joinCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		missing, err := missingDatanodes(addr, nodeIDs)
+		if err == nil && len(missing) == 0 {
+			return nil
+		}
+		if err != nil {
+			logger.V(5).Infof("failed to get heartbeats from metasrv: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("datanodes %v have not joined the cluster: %v", missing, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// missingDatanodes returns the node ids whose heartbeats are not found in metasrv.
+func missingDatanodes(metaSrvAddr string, nodeIDs []int) ([]int, error) {
+	rsp, err := http.Get(fmt.Sprintf("http://%s/admin/heartbeat", metaSrvAddr))
+	if err != nil {
+		return nodeIDs, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nodeIDs, fmt.Errorf("unexpected status: %s", rsp.Status)
+	}
+
+	// The admin API responds with the stats of heartbeats of each datanode.
+	var heartbeats []struct {
+		Stats []struct {
+			ID int `json:"id"`
+		} `json:"stats"`
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&heartbeats); err != nil {
+		return nodeIDs, err
+	}
+
+	joined := make(map[int]bool)
+	for _, heartbeat := range heartbeats {
+		for _, stat := range heartbeat.Stats {
+			joined[stat.ID] = true
+		}
+	}
+
+	var missing []int
+	for _, id := range nodeIDs {
+		if !joined[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
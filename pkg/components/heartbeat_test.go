@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestHeartbeatEnv(t *testing.T) {
+	assert.Empty(t, HeartbeatEnv(nil, "datanode"))
+	assert.Empty(t, HeartbeatEnv(&config.Heartbeat{}, "datanode"))
+
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_DATANODE__HEARTBEAT__INTERVAL":       "1500ms",
+		"GREPTIMEDB_DATANODE__HEARTBEAT__RETRY_INTERVAL": "3000ms",
+	}, HeartbeatEnv(&config.Heartbeat{
+		Interval:      1500 * time.Millisecond,
+		RetryInterval: 3 * time.Second,
+	}, "datanode"))
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_FLOWNODE__HEARTBEAT__INTERVAL": "1000ms",
+	}, HeartbeatEnv(&config.Heartbeat{Interval: time.Second}, "flownode"))
+}
+
+func TestMetaSrvRegionFailoverArgs(t *testing.T) {
+	cfg := &config.MetaSrv{
+		StoreAddr:  "127.0.0.1:2379",
+		ServerAddr: "0.0.0.0:3002",
+		HTTPAddr:   "0.0.0.0:14001",
+		Replicas:   1,
+	}
+	m := NewMetaSrv(cfg, WorkingDirs{}, nil, logger.New(io.Discard, 0), false).(*metaSrv)
+	args := m.BuildArgs(0, "127.0.0.1:3002", "")
+	assert.NotContains(t, args, "--enable-region-failover=true")
+	for _, arg := range args {
+		assert.NotContains(t, arg, "--selector")
+	}
+
+	cfg.Selector = "lease_based"
+	cfg.EnableRegionFailover = true
+	args = m.BuildArgs(0, "127.0.0.1:3002", "")
+	assert.Contains(t, args, "--selector=lease_based")
+	assert.Contains(t, args, "--enable-region-failover=true")
+}
+
+func TestMissingDatanodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/heartbeat", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"stats":[{"id":1},{"id":3}]}]`))
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	missing, err := missingDatanodes(addr, []int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, missing)
+
+	missing, err = missingDatanodes(addr, []int{1, 3})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	HookPreStart  = "preStart"
+	HookPostStart = "postStart"
+	HookPreStop   = "preStop"
+)
+
+// runHooks runs the hook commands of the phase in order by "sh -c" ("cmd /C" on Windows), and stops at the first failed one.
+// The commands are run with the env of component, and the directories and listen addresses of
+// component are exported as "GTCTL_*" environment variables, e.g. "GTCTL_MYSQL_ADDRS".
+func runHooks(ctx context.Context, phase string, hooks *config.Hooks, component ClusterComponent,
+	env map[string]string, workingDirs WorkingDirs, logger logger.Logger) error {
+	commands := hookCommands(phase, hooks)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	merged := mergeHookEnv(env, hookEnv(component, workingDirs))
+	if workingDirs.DryRun != nil {
+		for _, command := range commands {
+			workingDirs.DryRun.AddCommand(fmt.Sprintf("%s %s hook", component.Name(), phase), merged, shellCommand(command)...)
+		}
+		return nil
+	}
+
+	environ := buildEnv(merged)
+	for _, command := range commands {
+		logger.V(3).Infof("Running %s hook of '%s': %s", phase, component.Name(), command)
+
+		argv := shellCommand(command)
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Env = environ
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s hook '%s' of '%s' failed: %v: %s",
+				phase, command, component.Name(), err, strings.TrimSpace(string(output)))
+		}
+		logger.V(5).Infof("Output of %s hook of '%s': %s", phase, component.Name(), strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// runPreStopHooks runs the preStop hooks if any replica of component is alive. The failure of
+// hooks is only logged, since it should not prevent the component from being stopped.
+func runPreStopHooks(ctx context.Context, hooks *config.Hooks, component ClusterComponent,
+	env map[string]string, workingDirs WorkingDirs, logger logger.Logger) {
+	if len(hookCommands(HookPreStop, hooks)) == 0 {
+		return
+	}
+
+	for _, status := range component.Status(ctx) {
+		if status.State == ReplicaStateRunning || status.State == ReplicaStateUnhealthy {
+			if err := runHooks(ctx, HookPreStop, hooks, component, env, workingDirs, logger); err != nil {
+				logger.Warnf("%v", err)
+			}
+			return
+		}
+	}
+}
+
+// hookCommands returns the hook commands of the phase.
+func hookCommands(phase string, hooks *config.Hooks) []string {
+	if hooks == nil {
+		return nil
+	}
+
+	switch phase {
+	case HookPreStart:
+		return hooks.PreStart
+	case HookPostStart:
+		return hooks.PostStart
+	case HookPreStop:
+		return hooks.PreStop
+	default:
+		return nil
+	}
+}
+
+// hookEnv returns the environment variables that describe the component for hooks.
+// The addresses of replicas are joined by comma for each arg, e.g. "--http-addr"
+// is exported as "GTCTL_HTTP_ADDRS".
+func hookEnv(component ClusterComponent, workingDirs WorkingDirs) map[string]string {
+	env := map[string]string{
+		"GTCTL_COMPONENT": component.Name(),
+		"GTCTL_DATA_DIR":  workingDirs.DataDir,
+		"GTCTL_LOGS_DIR":  workingDirs.LogsDir,
+		"GTCTL_PIDS_DIR":  workingDirs.PidsDir,
+	}
+
+	for _, addr := range component.ListenAddrs() {
+		arg := strings.ReplaceAll(strings.ToUpper(strings.TrimPrefix(addr.Arg, "--")), "-", "_")
+		key := fmt.Sprintf("GTCTL_%sS", arg)
+		if len(env[key]) > 0 {
+			env[key] += ","
+		}
+		env[key] += addr.Addr
+	}
+
+	return env
+}
+
+// mergeHookEnv merges the env of component and the env for hooks, the latter takes precedence.
+func mergeHookEnv(env, hookEnv map[string]string) map[string]string {
+	merged := make(map[string]string, len(env)+len(hookEnv))
+	for k, v := range env {
+		merged[k] = v
+	}
+	for k, v := range hookEnv {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestRunHooks(t *testing.T) {
+	dir := t.TempDir()
+	output := path.Join(dir, "output")
+	workingDirs := WorkingDirs{DataDir: dir}
+	cfg := &config.Standalone{
+		HTTPAddr:  "0.0.0.0:4000",
+		MysqlAddr: "0.0.0.0:4002",
+		Env:       map[string]string{"SCHEMA": "metrics"},
+		Hooks: &config.Hooks{
+			PreStart: []string{
+				`echo "$GTCTL_COMPONENT $GTCTL_MYSQL_ADDRS $SCHEMA" > ` + output,
+				`echo "$GTCTL_DATA_DIR" >> ` + output,
+			},
+			PostStart: []string{"exit 1"},
+		},
+	}
+	l := logger.New(io.Discard, 0)
+	s := NewStandalone(cfg, workingDirs, nil, l)
+
+	assert.NoError(t, runHooks(context.Background(), HookPreStart, cfg.Hooks, s, cfg.Env, workingDirs, l))
+	raw, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "standalone 0.0.0.0:4002 metrics\n"+dir+"\n", string(raw))
+
+	err = runHooks(context.Background(), HookPostStart, cfg.Hooks, s, cfg.Env, workingDirs, l)
+	assert.ErrorContains(t, err, "postStart hook 'exit 1' of 'standalone' failed")
+
+	// There are no preStop hooks.
+	assert.NoError(t, runHooks(context.Background(), HookPreStop, cfg.Hooks, s, cfg.Env, workingDirs, l))
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// KafkaComponentName is the name of the embedded Kafka component.
+	KafkaComponentName = "kafka"
+
+	// DefaultEmbeddedKafkaAddr is the default address of the embedded Kafka broker.
+	DefaultEmbeddedKafkaAddr = "127.0.0.1:9092"
+
+	kafkaPropertiesFileName = "server.properties"
+	kafkaLogsDir            = "kafka-logs"
+)
+
+// EmbeddedKafkaAddr returns the address of the embedded Kafka broker.
+func EmbeddedKafkaAddr(config *config.EmbeddedKafka) string {
+	if len(config.Addr) > 0 {
+		return config.Addr
+	}
+	return DefaultEmbeddedKafkaAddr
+}
+
+type kafka struct {
+	config *config.EmbeddedKafka
+
+	workingDirs WorkingDirs
+	wg          *sync.WaitGroup
+	logger      logger.Logger
+
+	allocatedDirs
+}
+
+func NewKafka(config *config.EmbeddedKafka, workingDirs WorkingDirs,
+	wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
+	return &kafka{
+		config:      config,
+		workingDirs: workingDirs,
+		wg:          wg,
+		logger:      logger,
+	}
+}
+
+func (k *kafka) Name() string {
+	return KafkaComponentName
+}
+
+// Start starts the single-node Kafka in KRaft mode, the binary is the path of "kafka-server-start.sh".
+func (k *kafka) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+	var (
+		kafkaDataDir = path.Join(k.workingDirs.DataDir, k.Name())
+		kafkaLogDir  = path.Join(k.workingDirs.LogsDir, k.Name())
+		kafkaPidDir  = path.Join(k.workingDirs.PidsDir, k.Name())
+		kafkaDirs    = []string{kafkaDataDir, kafkaLogDir, kafkaPidDir}
+	)
+	for _, dir := range kafkaDirs {
+		if err := ensureDir(k.workingDirs, dir); err != nil {
+			return err
+		}
+	}
+	k.dataDirs = append(k.dataDirs, kafkaDataDir)
+	k.logsDirs = append(k.logsDirs, kafkaLogDir)
+	k.pidsDirs = append(k.pidsDirs, kafkaPidDir)
+
+	propertiesFile, err := k.writeProperties(kafkaDataDir)
+	if err != nil {
+		return err
+	}
+	if err = k.formatStorage(path.Dir(binary), kafkaDataDir, propertiesFile); err != nil {
+		return err
+	}
+
+	option := &RunOptions{
+		Binary:      binary,
+		Name:        k.Name(),
+		logDir:      kafkaLogDir,
+		pidDir:      kafkaPidDir,
+		args:        k.BuildArgs(propertiesFile),
+		crashesDir:  k.workingDirs.CrashesDir,
+		crashDump:   k.workingDirs.CrashDump,
+		logRotation: k.workingDirs.LogRotation,
+		events:      k.workingDirs.Events,
+		dryRun:      k.workingDirs.DryRun,
+	}
+	if err = runBinary(stop, option, k.wg, k.logger); err != nil {
+		return err
+	}
+
+	return waitForReady(ctx, k, nil, k.allocatedDirs, k.workingDirs, k.logger)
+}
+
+// writeProperties writes the properties of single-node Kafka in KRaft mode.
+func (k *kafka) writeProperties(dataDir string) (string, error) {
+	addr := EmbeddedKafkaAddr(k.config)
+	controllerAddr, err := k.controllerAddr()
+	if err != nil {
+		return "", err
+	}
+
+	// The advertised addresses should be accessible, so the unspecified host is replaced.
+	advertisedAddr, err := HealthCheckAddr(addr, "")
+	if err != nil {
+		return "", err
+	}
+	advertisedControllerAddr, err := HealthCheckAddr(controllerAddr, "")
+	if err != nil {
+		return "", err
+	}
+
+	properties := []string{
+		"process.roles=broker,controller",
+		"node.id=1",
+		fmt.Sprintf("controller.quorum.voters=1@%s", advertisedControllerAddr),
+		fmt.Sprintf("listeners=PLAINTEXT://%s,CONTROLLER://%s", addr, controllerAddr),
+		fmt.Sprintf("advertised.listeners=PLAINTEXT://%s", advertisedAddr),
+		"controller.listener.names=CONTROLLER",
+		"listener.security.protocol.map=CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+		fmt.Sprintf("log.dirs=%s", path.Join(dataDir, kafkaLogsDir)),
+		"offsets.topic.replication.factor=1",
+		"transaction.state.log.replication.factor=1",
+		"transaction.state.log.min.isr=1",
+	}
+
+	propertiesFile := path.Join(dataDir, kafkaPropertiesFileName)
+	if err = writeFile(k.workingDirs, propertiesFile, strings.Join(properties, "\n")+"\n"); err != nil {
+		return "", err
+	}
+	return propertiesFile, nil
+}
+
+// formatStorage formats the storage of Kafka if it has not been formatted.
+func (k *kafka) formatStorage(binDir, dataDir, propertiesFile string) error {
+	formatted, err := fileutils.IsFileExists(path.Join(dataDir, kafkaLogsDir, "meta.properties"))
+	if err != nil {
+		return err
+	}
+	if formatted {
+		return nil
+	}
+
+	storageBin := path.Join(binDir, "kafka-storage.sh")
+	if k.workingDirs.DryRun != nil {
+		k.workingDirs.DryRun.AddCommand(k.Name(), nil, storageBin, "format", "-t", "<random-uuid>", "-c", propertiesFile)
+		return nil
+	}
+
+	clusterID, err := exec.Command(storageBin, "random-uuid").Output()
+	if err != nil {
+		return fmt.Errorf("failed to generate cluster id of kafka: %v", err)
+	}
+
+	output, err := exec.Command(storageBin, "format", "-t", strings.TrimSpace(string(clusterID)),
+		"-c", propertiesFile).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to format storage of kafka: %v: %s", err, output)
+	}
+	k.logger.V(3).Infof("formatted storage of kafka: %s", output)
+
+	return nil
+}
+
+// controllerAddr returns the address of controller, which is the next port of broker.
+func (k *kafka) controllerAddr() (string, error) {
+	host, port, err := net.SplitHostPort(EmbeddedKafkaAddr(k.config))
+	if err != nil {
+		return "", err
+	}
+	portInt, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(portInt+1)), nil
+}
+
+func (k *kafka) Stop(ctx context.Context) error {
+	return stopProcessInTime(ctx, path.Join(k.workingDirs.PidsDir, k.Name()), k.workingDirs.DrainTimeout, k.logger)
+}
+
+func (k *kafka) BuildArgs(params ...interface{}) []string {
+	return []string{params[0].(string)}
+}
+
+func (k *kafka) ListenAddrs() []ListenAddr {
+	addrs := []ListenAddr{{Replica: k.Name(), Arg: "listeners", Addr: EmbeddedKafkaAddr(k.config)}}
+	if controllerAddr, err := k.controllerAddr(); err == nil {
+		addrs = append(addrs, ListenAddr{Replica: k.Name(), Arg: "controller", Addr: controllerAddr})
+	}
+	return addrs
+}
+
+func (k *kafka) IsRunning(_ context.Context) bool {
+	addr, err := HealthCheckAddr(EmbeddedKafkaAddr(k.config), "")
+	if err != nil {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		k.logger.V(5).Infof("failed to connect to %s: %s", k.Name(), err)
+		return false
+	}
+	_ = conn.Close()
+
+	return true
+}
+
+func (k *kafka) Status(ctx context.Context) []ReplicaStatus {
+	return []ReplicaStatus{replicaStatus(k.Name(), path.Join(k.workingDirs.PidsDir, k.Name()),
+		func() bool { return k.IsRunning(ctx) })}
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// logLevelUnknown is the level of the lines that no level is found in.
+const logLevelUnknown = -1
+
+// logLevels are the severities of the log levels of GreptimeDB, etcd and Kafka.
+var logLevels = map[string]int{
+	"TRACE":   0,
+	"DEBUG":   1,
+	"INFO":    2,
+	"WARN":    3,
+	"WARNING": 3,
+	"ERROR":   4,
+	"FATAL":   5,
+	"PANIC":   5,
+}
+
+var (
+	// logLevelPattern matches the level at the head of line, e.g. "2024-01-02T03:04:05.678Z  INFO ..."
+	// of GreptimeDB and "[2024-01-02 03:04:05,678] WARN ..." of Kafka.
+	logLevelPattern = regexp.MustCompile(`(?i)\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\b`)
+
+	// logTimePattern matches the timestamp at the beginning of line.
+	logTimePattern = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`)
+)
+
+// logLevelHeadLength is the length of the head of line that the level is searched in, so that
+// the levels mentioned in the messages are ignored.
+const logLevelHeadLength = 48
+
+// LogFilter filters the log lines of replicas, the nil filter accepts all the lines. The lines that
+// have no timestamp, e.g. the backtraces, inherit the timestamp and level from the previous lines.
+type LogFilter struct {
+	// Patterns are the patterns to grep, a line is accepted if it matches any of them.
+	Patterns []*regexp.Regexp
+
+	// Level is the lowest level of the accepted lines, all the levels if it's empty.
+	Level string
+
+	// Since and Until are the time range of the accepted lines, they are unbounded if zero.
+	Since time.Time
+	Until time.Time
+}
+
+// NewLogFilter creates the filter from the patterns, level, and the bounds of time range, which are either
+// the RFC3339 timestamps or the durations before now, e.g. "10m".
+func NewLogFilter(patterns []string, level, since, until string, now time.Time) (*LogFilter, error) {
+	filter := &LogFilter{Level: strings.ToUpper(level)}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", pattern, err)
+		}
+		filter.Patterns = append(filter.Patterns, re)
+	}
+
+	if _, ok := logLevels[filter.Level]; len(level) > 0 && !ok {
+		return nil, fmt.Errorf("invalid log level '%s', should be one of 'trace', 'debug', 'info', 'warn' and 'error'", level)
+	}
+
+	var err error
+	if filter.Since, err = parseTimeBound(since, now); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseTimeBound(until, now); err != nil {
+		return nil, err
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return nil, fmt.Errorf("the end of time range '%s' is before the start '%s'", until, since)
+	}
+
+	return filter, nil
+}
+
+// parseTimeBound parses the bound of time range, it's zero if the value is empty.
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, ok := parseLogTime(value); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s', should be a RFC3339 timestamp or a duration like '10m'", value)
+}
+
+// accept checks whether the line of entry is accepted by the filter.
+func (f *LogFilter) accept(entry logEntry) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Level) > 0 && (entry.level == logLevelUnknown || entry.level < logLevels[f.Level]) {
+		return false
+	}
+
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		if entry.time.IsZero() || (!f.Since.IsZero() && entry.time.Before(f.Since)) ||
+			(!f.Until.IsZero() && entry.time.After(f.Until)) {
+			return false
+		}
+	}
+
+	if len(f.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range f.Patterns {
+		if pattern.MatchString(entry.line) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLogLevel returns the level of line, or logLevelUnknown if it's not found.
+func parseLogLevel(line string) int {
+	head := line
+	if len(head) > logLevelHeadLength {
+		head = head[:logLevelHeadLength]
+	}
+	if match := logLevelPattern.FindStringSubmatch(head); match != nil {
+		return logLevels[strings.ToUpper(match[1])]
+	}
+	return logLevelUnknown
+}
+
+// parseJSONLog parses the timestamp and level of the json log line, e.g. '{"timestamp":"...","level":"INFO",...}'
+// of GreptimeDB and '{"level":"warn","ts":"...",...}' of etcd. It's false if the line is not a json object.
+func parseJSONLog(line string) (time.Time, int, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return time.Time{}, logLevelUnknown, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return time.Time{}, logLevelUnknown, false
+	}
+
+	var t time.Time
+	for _, key := range []string{"timestamp", "ts", "time"} {
+		switch value := fields[key].(type) {
+		case string:
+			t, _ = parseLogTime(value)
+		case float64:
+			// The epoch seconds of etcd with '--log-format=json' of the older versions.
+			t = time.Unix(0, int64(value*float64(time.Second)))
+		}
+		if !t.IsZero() {
+			break
+		}
+	}
+
+	level := logLevelUnknown
+	if value, ok := fields["level"].(string); ok {
+		if severity, ok := logLevels[strings.ToUpper(value)]; ok {
+			level = severity
+		}
+	}
+	return t, level, true
+}
+
+// jsonLogLine returns the log line of replica as a json object, the json line is added with the replica
+// field, and the text line is wrapped as the message field.
+func jsonLogLine(replica, line string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &fields) != nil {
+		fields = map[string]json.RawMessage{}
+		message, err := json.Marshal(line)
+		if err != nil {
+			return nil, err
+		}
+		fields["message"] = message
+	}
+
+	name, err := json.Marshal(replica)
+	if err != nil {
+		return nil, err
+	}
+	fields["replica"] = name
+	return json.Marshal(fields)
+}
+
+// parseLogTime returns the timestamp of line, the timestamps without zone are in the local time.
+func parseLogTime(line string) (time.Time, bool) {
+	match := logTimePattern.FindStringSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	value := strings.Replace(strings.Replace(match[1], " ", "T", 1), ",", ".", 1)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05.999999999Z0700", value); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", value, time.Local); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
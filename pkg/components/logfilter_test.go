@@ -0,0 +1,129 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestParseLogTime(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected time.Time
+		ok       bool
+	}{
+		{"2024-01-02T03:04:05.678901Z  INFO servers::grpc: started", time.Date(2024, 1, 2, 3, 4, 5, 678901000, time.UTC), true},
+		{"[2024-01-02 03:04:05,678] WARN kafka started", time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.Local), true},
+		{"   0: backtrace::capture", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		actual, ok := parseLogTime(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.True(t, tt.expected.Equal(actual), tt.line)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	assert.Equal(t, logLevels["INFO"], parseLogLevel("2024-01-02T03:04:05.678901Z  INFO servers::grpc: error is mentioned"))
+	assert.Equal(t, logLevels["WARN"], parseLogLevel("[2024-01-02 03:04:05,678] WARN kafka started"))
+	assert.Equal(t, logLevelUnknown, parseLogLevel("2024-01-02T03:04:05.678901Z  nothing here, though an ERROR is far away"))
+}
+
+func TestParseJSONLog(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected time.Time
+		level    int
+		ok       bool
+	}{
+		{`{"timestamp":"2024-01-02T03:04:05.678901Z","level":"WARN","fields":{"message":"slow"}}`,
+			time.Date(2024, 1, 2, 3, 4, 5, 678901000, time.UTC), logLevels["WARN"], true},
+		{`{"level":"error","ts":"2024-01-02T03:04:05.678Z","msg":"etcd"}`,
+			time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC), logLevels["ERROR"], true},
+		{`{"level":"info","ts":1704164645.5}`, time.Unix(1704164645, 500000000), logLevels["INFO"], true},
+		{`{"message":"no timestamp"}`, time.Time{}, logLevelUnknown, true},
+		{"2024-01-02T03:04:05.678901Z  INFO {not json}", time.Time{}, logLevelUnknown, false},
+	}
+
+	for _, tt := range tests {
+		actual, level, ok := parseJSONLog(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.Equal(t, tt.level, level, tt.line)
+		assert.True(t, tt.expected.Equal(actual), tt.line)
+	}
+}
+
+func TestJSONLogLine(t *testing.T) {
+	line, err := jsonLogLine("frontend.0", `{"level":"INFO","fields":{"message":"started"}}`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"level":"INFO","fields":{"message":"started"},"replica":"frontend.0"}`, string(line))
+
+	line, err = jsonLogLine("frontend.0", `2024-01-02T03:04:05.678901Z  INFO "quoted"`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message":"2024-01-02T03:04:05.678901Z  INFO \"quoted\"","replica":"frontend.0"}`, string(line))
+}
+
+func TestLogFormatEnv(t *testing.T) {
+	assert.Empty(t, LogFormatEnv("", MetaSrvComponentName))
+	assert.Empty(t, LogFormatEnv(config.LogFormatText, MetaSrvComponentName))
+	assert.Equal(t, map[string]string{"GREPTIMEDB_METASRV__LOGGING__LOG_FORMAT": "json"},
+		LogFormatEnv(config.LogFormatJSON, MetaSrvComponentName))
+}
+
+func TestNewLogFilter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	filter, err := NewLogFilter([]string{"grpc", "^panic"}, "warn", "10m", "2024-01-02T03:00:00Z", now)
+	assert.NoError(t, err)
+	assert.Len(t, filter.Patterns, 2)
+	assert.Equal(t, "WARN", filter.Level)
+	assert.Equal(t, now.Add(-10*time.Minute), filter.Since)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC), filter.Until)
+
+	_, err = NewLogFilter([]string{"("}, "", "", "", now)
+	assert.Error(t, err)
+	_, err = NewLogFilter(nil, "verbose", "", "", now)
+	assert.Error(t, err)
+	_, err = NewLogFilter(nil, "", "yesterday", "", now)
+	assert.Error(t, err)
+	_, err = NewLogFilter(nil, "", "1m", "10m", now)
+	assert.Error(t, err)
+}
+
+func TestLogFilterAccept(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	filter, err := NewLogFilter([]string{"grpc"}, "warn", "10m", "", now)
+	assert.NoError(t, err)
+
+	entry := func(line string, level int, ago time.Duration) logEntry {
+		return logEntry{line: line, level: level, time: now.Add(-ago)}
+	}
+	assert.True(t, filter.accept(entry("grpc failed", logLevels["ERROR"], time.Minute)))
+	assert.False(t, filter.accept(entry("grpc started", logLevels["INFO"], time.Minute)))
+	assert.False(t, filter.accept(entry("http failed", logLevels["ERROR"], time.Minute)))
+	assert.False(t, filter.accept(entry("grpc failed", logLevels["ERROR"], time.Hour)))
+	assert.False(t, filter.accept(logEntry{line: "grpc failed", level: logLevels["ERROR"]}))
+
+	var nilFilter *LogFilter
+	assert.True(t, nilFilter.accept(entry("anything", logLevelUnknown, 0)))
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// LogFormatEnv returns the environment variables that configure the format of the logs of component,
+// nothing is configured for the default text format.
+func LogFormatEnv(format, component string) map[string]string {
+	if len(format) == 0 || format == config.LogFormatText {
+		return nil
+	}
+	return map[string]string{
+		fmt.Sprintf("GREPTIMEDB_%s__LOGGING__LOG_FORMAT", strings.ToUpper(component)): format,
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	DefaultLogMaxSizeMB = 100
+	DefaultLogMaxFiles  = 5
+)
+
+// rotatingWriter writes the output of one process to its log file and rotates the
+// log file when it exceeds the max size or the max age.
+//
+// The output is piped through gtctl, so the process can't keep on writing logs once it
+// outlives gtctl, it's only used when the process is stopped as gtctl exits. The log file is
+// rotated by copying it to a backup file and then truncating it, so the readers following
+// the log file by its path, e.g. 'gtctl cluster logs -f', are not broken.
+type rotatingWriter struct {
+	mu sync.Mutex
+
+	logFile  string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	logger   logger.Logger
+
+	// createdAt records when the log file has been written since the last rotation.
+	createdAt time.Time
+}
+
+func newRotatingWriter(logFile string, rotation *config.LogRotation, logger logger.Logger) (*rotatingWriter, error) {
+	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	w := &rotatingWriter{
+		logFile:   logFile,
+		file:      file,
+		size:      info.Size(),
+		maxSize:   DefaultLogMaxSizeMB << 20,
+		maxAge:    rotation.MaxAge,
+		maxFiles:  DefaultLogMaxFiles,
+		logger:    logger,
+		createdAt: time.Now(),
+	}
+	if rotation.MaxSizeMB > 0 {
+		w.maxSize = int64(rotation.MaxSizeMB) << 20
+	}
+	if rotation.MaxFiles > 0 {
+		w.maxFiles = rotation.MaxFiles
+	}
+	return w, nil
+}
+
+// Write writes p to the log file, the log file is rotated before it if it needs to be.
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if w.needRotate(len(p), now) {
+		if err := w.rotate(now); err != nil {
+			// Keep on writing to the current log file rather than losing the logs.
+			w.logger.Warnf("failed to rotate log file '%s': %v", w.logFile, err)
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file.
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+// needRotate returns whether the log file needs to be rotated before writing n bytes into it.
+func (w *rotatingWriter) needRotate(n int, now time.Time) bool {
+	if w.size == 0 {
+		return false
+	}
+	expired := w.maxAge > 0 && now.Sub(w.createdAt) >= w.maxAge
+	return w.size+int64(n) > w.maxSize || expired
+}
+
+// rotate copies the log file to the first backup file and truncates it, the backup files are shifted
+// and the oldest one is overwritten.
+func (w *rotatingWriter) rotate(now time.Time) error {
+	for i := w.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(backupLogFile(w.logFile, i), backupLogFile(w.logFile, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := fileutils.CopyFile(w.logFile, backupLogFile(w.logFile, 1)); err != nil {
+		return err
+	}
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	w.logger.V(3).Infof("rotated log file '%s' with size %d", w.logFile, w.size)
+	w.size = 0
+	w.createdAt = now
+	return nil
+}
+
+// closeOutput closes the output of cmd if it's a rotatingWriter, it should be called after the cmd is waited.
+func closeOutput(cmd *exec.Cmd) {
+	if w, ok := cmd.Stdout.(*rotatingWriter); ok {
+		_ = w.Close()
+	}
+}
+
+// backupLogFile returns the path of the nth rotated log file, e.g. "log.1".
+func backupLogFile(logFile string, n int) string {
+	return fmt.Sprintf("%s.%d", logFile, n)
+}
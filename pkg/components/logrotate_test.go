@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	logFile := path.Join(t.TempDir(), logFileName)
+
+	w, err := newRotatingWriter(logFile, &config.LogRotation{MaxAge: time.Hour, MaxFiles: 2}, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	defer w.Close()
+	w.maxSize = 10
+
+	write := func(content string) {
+		_, err := w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	read := func(file string) string {
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	// Not rotated before reaching the max size.
+	write("short\n")
+	assert.Equal(t, "short\n", read(logFile))
+
+	// Rotated by size before the write exceeds the max size.
+	write(strings.Repeat("1", 10))
+	assert.Equal(t, strings.Repeat("1", 10), read(logFile))
+	assert.Equal(t, "short\n", read(backupLogFile(logFile, 1)))
+
+	// Rotated by age.
+	w.maxSize = 100
+	write("young\n")
+	assert.Equal(t, strings.Repeat("1", 10)+"young\n", read(logFile))
+	w.createdAt = time.Now().Add(-time.Hour)
+	write("aged\n")
+	assert.Equal(t, "aged\n", read(logFile))
+	assert.Equal(t, strings.Repeat("1", 10)+"young\n", read(backupLogFile(logFile, 1)))
+	assert.Equal(t, "short\n", read(backupLogFile(logFile, 2)))
+
+	// The oldest one is removed by exceeding the max files.
+	w.maxSize = 10
+	write(strings.Repeat("3", 10))
+	assert.Equal(t, strings.Repeat("3", 10), read(logFile))
+	assert.Equal(t, "aged\n", read(backupLogFile(logFile, 1)))
+	assert.Equal(t, strings.Repeat("1", 10)+"young\n", read(backupLogFile(logFile, 2)))
+	_, err = os.Stat(backupLogFile(logFile, 3))
+	assert.True(t, os.IsNotExist(err))
+}
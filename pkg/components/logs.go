@@ -0,0 +1,256 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// followLogsInterval is the interval of polling the new logs.
+	followLogsInterval = 200 * time.Millisecond
+
+	// tailReadLimit is the max bytes read from the end of log file to find the tail lines.
+	tailReadLimit = 1 << 20
+)
+
+// logPrefixColors are the colors of replica prefixes, assigned in turn like docker-compose.
+var logPrefixColors = []color.Attribute{
+	color.FgCyan, color.FgYellow, color.FgGreen, color.FgMagenta, color.FgBlue, color.FgRed,
+	color.FgHiCyan, color.FgHiYellow, color.FgHiGreen, color.FgHiMagenta, color.FgHiBlue, color.FgHiRed,
+}
+
+// logFollower follows the log file of one replica.
+type logFollower struct {
+	replica string
+	file    string
+	offset  int64
+	color   *color.Color
+
+	// time and level are of the last line that has them, which are inherited by the following lines.
+	time  time.Time
+	level int
+}
+
+// logEntry is one line of the logs of replica with its timestamp and level.
+type logEntry struct {
+	follower *logFollower
+	line     string
+	time     time.Time
+	level    int
+}
+
+// FollowLogs writes the logs of the replicas under logsDir to w, each line is prefixed by the colored
+// name of replica. Only the replicas accepted by match are included, e.g. "frontend.0" and "etcd",
+// and only the lines accepted by filter are written, which are merged in the order of timestamps.
+// It reads the last tail lines of each log file, or all the lines if tail is negative, and then
+// keeps on following the new logs and the new replicas until the ctx is done if follow is true.
+// The lines are written as json objects with the replica field instead of prefixed if asJSON is true.
+func FollowLogs(ctx context.Context, w io.Writer, logsDir string, match func(replica string) bool,
+	tail int, follow bool, filter *LogFilter, asJSON bool) error {
+	followers := make(map[string]*logFollower)
+	discover := func(initial bool) error {
+		entries, err := os.ReadDir(logsDir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || followers[entry.Name()] != nil || !match(entry.Name()) {
+				continue
+			}
+			f := &logFollower{
+				replica: entry.Name(),
+				file:    filepath.Join(logsDir, entry.Name(), logFileName),
+				color:   color.New(logPrefixColors[len(followers)%len(logPrefixColors)]),
+				level:   logLevelUnknown,
+			}
+			// The replicas started after following are read from the beginning.
+			if initial && tail >= 0 {
+				if err = f.skipToTail(tail); err != nil {
+					return err
+				}
+			}
+			followers[entry.Name()] = f
+		}
+		return nil
+	}
+
+	if err := discover(true); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(followLogsInterval)
+	defer ticker.Stop()
+	for {
+		if err := writeLogs(w, followers, filter, asJSON); err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := discover(false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeLogs writes the new lines of all the followers that are accepted by filter in the order of
+// timestamps, the lines of the same timestamp are in the order of replica names.
+func writeLogs(w io.Writer, followers map[string]*logFollower, filter *LogFilter, asJSON bool) error {
+	replicas := make([]string, 0, len(followers))
+	width := 0
+	for replica := range followers {
+		replicas = append(replicas, replica)
+		if len(replica) > width {
+			width = len(replica)
+		}
+	}
+	sort.Strings(replicas)
+
+	var entries []logEntry
+	for _, replica := range replicas {
+		f := followers[replica]
+		lines, err := f.readLines()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if entry := f.parse(line); filter.accept(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].time.Before(entries[j].time)
+	})
+
+	for _, entry := range entries {
+		if asJSON {
+			line, err := jsonLogLine(entry.follower.replica, entry.line)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(w, "%s\n", line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		prefix := entry.follower.color.Sprintf("%-*s |", width, entry.follower.replica)
+		if _, err := fmt.Fprintf(w, "%s %s\n", prefix, entry.line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parse parses the timestamp and level of line, the line that has no timestamp is the continuation
+// of previous line, e.g. the backtrace, which inherits the timestamp and level of previous line.
+func (f *logFollower) parse(line string) logEntry {
+	if t, level, ok := parseJSONLog(line); ok {
+		f.time, f.level = t, level
+	} else if t, ok := parseLogTime(line); ok {
+		f.time = t
+		f.level = parseLogLevel(line)
+	}
+	return logEntry{follower: f, line: line, time: f.time, level: f.level}
+}
+
+// skipToTail moves the offset to the beginning of the last n lines.
+func (f *logFollower) skipToTail(n int) error {
+	info, err := os.Stat(f.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > tailReadLimit {
+		f.offset = info.Size() - tailReadLimit
+	}
+	start := f.offset
+	lines, err := f.readLines()
+	if err != nil {
+		return err
+	}
+	// The first line may be incomplete if it's not read from the beginning.
+	if start > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+
+	// Move back from the end of the last complete line.
+	for i := len(lines) - 1; i >= 0 && i >= len(lines)-n; i-- {
+		f.offset -= int64(len(lines[i]) + 1)
+	}
+	return nil
+}
+
+// readLines reads the complete lines since the offset, the incomplete last line is left to the next read.
+// The log file is read from the beginning if it has been truncated by rotation.
+func (f *logFollower) readLines() ([]string, error) {
+	file, err := os.Open(f.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < f.offset {
+		f.offset = 0
+	}
+	if _, err = file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.offset += int64(len(line))
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines, nil
+}
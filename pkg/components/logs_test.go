@@ -0,0 +1,138 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowLogs(t *testing.T) {
+	color.NoColor = true
+
+	logsDir := t.TempDir()
+	write := func(replica, content string) {
+		assert.NoError(t, os.MkdirAll(path.Join(logsDir, replica), 0755))
+		f, err := os.OpenFile(path.Join(logsDir, replica, logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		assert.NoError(t, err)
+		_, err = f.WriteString(content)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+	write("frontend.0", "f1\nf2\nf3\n")
+	write("datanode.0", "d1\nd2\nincomplete")
+	write("etcd", "e1\n")
+
+	all := func(string) bool { return true }
+	frontend := func(replica string) bool { return strings.HasPrefix(replica, "frontend.") }
+
+	var out bytes.Buffer
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, all, -1, false, nil, false))
+	assert.Equal(t, strings.Join([]string{
+		"datanode.0 | d1",
+		"datanode.0 | d2",
+		"etcd       | e1",
+		"frontend.0 | f1",
+		"frontend.0 | f2",
+		"frontend.0 | f3",
+	}, "\n")+"\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, frontend, 2, false, nil, false))
+	assert.Equal(t, "frontend.0 | f2\nfrontend.0 | f3\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, frontend, 0, false, nil, false))
+	assert.Empty(t, out.String())
+}
+
+func TestFollowLogsMergedAndFiltered(t *testing.T) {
+	color.NoColor = true
+
+	logsDir := t.TempDir()
+	for replica, content := range map[string]string{
+		"frontend.0": "2024-01-02T03:04:01.000Z  INFO started\n2024-01-02T03:04:04.000Z ERROR grpc failed\n  0: backtrace\n",
+		"datanode.0": "2024-01-02T03:04:02.000Z  WARN grpc slow\n2024-01-02T03:04:03.000Z  INFO flushed\n",
+	} {
+		assert.NoError(t, os.MkdirAll(path.Join(logsDir, replica), 0755))
+		assert.NoError(t, os.WriteFile(path.Join(logsDir, replica, logFileName), []byte(content), 0644))
+	}
+	all := func(string) bool { return true }
+
+	var out bytes.Buffer
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, all, -1, false, nil, false))
+	assert.Equal(t, strings.Join([]string{
+		"frontend.0 | 2024-01-02T03:04:01.000Z  INFO started",
+		"datanode.0 | 2024-01-02T03:04:02.000Z  WARN grpc slow",
+		"datanode.0 | 2024-01-02T03:04:03.000Z  INFO flushed",
+		"frontend.0 | 2024-01-02T03:04:04.000Z ERROR grpc failed",
+		"frontend.0 |   0: backtrace",
+	}, "\n")+"\n", out.String())
+
+	filter, err := NewLogFilter(nil, "warn", "", "", time.Now())
+	assert.NoError(t, err)
+	out.Reset()
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, all, -1, false, filter, false))
+	assert.Equal(t, strings.Join([]string{
+		"datanode.0 | 2024-01-02T03:04:02.000Z  WARN grpc slow",
+		"frontend.0 | 2024-01-02T03:04:04.000Z ERROR grpc failed",
+		"frontend.0 |   0: backtrace",
+	}, "\n")+"\n", out.String())
+
+	filter, err = NewLogFilter([]string{"grpc"}, "", "", "2024-01-02T03:04:03Z", time.Now())
+	assert.NoError(t, err)
+	out.Reset()
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, all, -1, false, filter, false))
+	assert.Equal(t, "datanode.0 | 2024-01-02T03:04:02.000Z  WARN grpc slow\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, FollowLogs(context.Background(), &out, logsDir, all, -1, false, filter, true))
+	assert.JSONEq(t, `{"message":"2024-01-02T03:04:02.000Z  WARN grpc slow","replica":"datanode.0"}`, out.String())
+}
+
+func TestLogFollowerReadLines(t *testing.T) {
+	logFile := path.Join(t.TempDir(), logFileName)
+	f := &logFollower{file: logFile}
+
+	lines, err := f.readLines()
+	assert.NoError(t, err)
+	assert.Empty(t, lines)
+
+	assert.NoError(t, os.WriteFile(logFile, []byte("a\nb"), 0644))
+	lines, err = f.readLines()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, lines)
+
+	assert.NoError(t, os.WriteFile(logFile, []byte("a\nbc\n"), 0644))
+	lines, err = f.readLines()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bc"}, lines)
+
+	// Read from the beginning after the log file is truncated by rotation.
+	assert.NoError(t, os.WriteFile(logFile, []byte("new\n"), 0644))
+	lines, err = f.readLines()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new"}, lines)
+}
@@ -20,17 +20,17 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"net/http"
 	"path"
 	"strconv"
 	"sync"
-	"time"
 
 	"github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
-	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
+// MetaSrvComponentName is the name of metasrv component.
+const MetaSrvComponentName = "metasrv"
+
 type metaSrv struct {
 	config *config.MetaSrv
 
@@ -54,59 +54,127 @@ func NewMetaSrv(config *config.MetaSrv, workingDirs WorkingDirs,
 }
 
 func (m *metaSrv) Name() string {
-	return "metasrv"
+	return MetaSrvComponentName
 }
 
 func (m *metaSrv) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
-	// Default bind address for meta srv.
-	bindAddr := net.JoinHostPort("127.0.0.1", "3002")
-	if len(m.config.BindAddr) > 0 {
-		bindAddr = m.config.BindAddr
+	return m.StartReplicas(ctx, stop, binary, 0, m.config.Replicas)
+}
+
+func (m *metaSrv) StartReplicas(ctx context.Context, stop context.CancelFunc, binary string, from, to int) error {
+	binary, err := componentBinary(binary, m.config.BinaryPath)
+	if err != nil {
+		return err
+	}
+	if err := runHooks(ctx, HookPreStart, m.config.Hooks, m, m.config.Env, m.workingDirs, m.logger); err != nil {
+		return err
 	}
 
-	for i := 0; i < m.config.Replicas; i++ {
-		dirName := fmt.Sprintf("%s.%d", m.Name(), i)
+	bindAddr := m.bindAddr()
+
+	storeAddrs, err := m.storeAddrs()
+	if err != nil {
+		return err
+	}
+	env := m.env()
+	if hasURLPassword(storeAddrs) {
+		env = withStoreAddrsEnv(env, storeAddrs)
+		storeAddrs = ""
+	}
+
+	for i := from; i < to; i++ {
+		dirName := replicaDirName(m.Name(), i)
 
 		metaSrvLogDir := path.Join(m.workingDirs.LogsDir, dirName)
-		if err := fileutils.EnsureDir(metaSrvLogDir); err != nil {
+		if err := ensureDir(m.workingDirs, metaSrvLogDir); err != nil {
 			return err
 		}
 		m.logsDirs = append(m.logsDirs, metaSrvLogDir)
 
 		metaSrvPidDir := path.Join(m.workingDirs.PidsDir, dirName)
-		if err := fileutils.EnsureDir(metaSrvPidDir); err != nil {
+		if err := ensureDir(m.workingDirs, metaSrvPidDir); err != nil {
 			return err
 		}
 		m.pidsDirs = append(m.pidsDirs, metaSrvPidDir)
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: metaSrvLogDir,
-			pidDir: metaSrvPidDir,
-			args:   m.BuildArgs(i, bindAddr),
+			Binary:      binary,
+			Name:        dirName,
+			logDir:      metaSrvLogDir,
+			pidDir:      metaSrvPidDir,
+			args:        m.BuildArgs(i, bindAddr, storeAddrs),
+			resources:   m.config.Resources,
+			env:         env,
+			restart:     m.config.Restart,
+			crashesDir:  m.workingDirs.CrashesDir,
+			crashDump:   m.workingDirs.CrashDump,
+			logRotation: m.workingDirs.LogRotation,
+			events:      m.workingDirs.Events,
+			dryRun:      m.workingDirs.DryRun,
 		}
-		if err := runBinary(ctx, stop, option, m.wg, m.logger); err != nil {
+		if err := runBinary(stop, option, m.wg, m.logger); err != nil {
 			return err
 		}
 	}
 
-	// Checking component running status with intervals.
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+	if err := waitForReady(ctx, m, m.config.Readiness, m.allocatedDirs, m.workingDirs, m.logger); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, HookPostStart, m.config.Hooks, m, m.config.Env, m.workingDirs, m.logger)
+}
 
-CHECKER:
-	for {
-		select {
-		case <-ticker.C:
-			if m.IsRunning(ctx) {
-				break CHECKER
-			}
-		case <-ctx.Done():
-			return fmt.Errorf("status checking failed: %v", ctx.Err())
-		}
+// storeAddrs returns the store addresses of the metadata store backend, it's empty
+// if the backend storage is not specified.
+func (m *metaSrv) storeAddrs() (string, error) {
+	if m.config.BackendStorage == nil {
+		return "", nil
+	}
+
+	password, err := BackendPassword(m.config.BackendStorage)
+	if err != nil {
+		return "", err
+	}
+	return BackendStoreAddrs(m.config.BackendStorage, password), nil
+}
+
+// env returns the environment variables of meta srv, the env in config takes precedence.
+func (m *metaSrv) env() map[string]string {
+	env := backendEnv(m.config.BackendStorage)
+	if len(env) == 0 {
+		return m.config.Env
+	}
+
+	for k, v := range m.config.Env {
+		env[k] = v
 	}
+	return env
+}
 
-	return nil
+// withStoreAddrsEnv returns a copy of env with the store addresses, the one in env takes precedence.
+func withStoreAddrsEnv(env map[string]string, storeAddrs string) map[string]string {
+	merged := map[string]string{backendStoreAddrsEnv: storeAddrs}
+	for k, v := range env {
+		merged[k] = v
+	}
+	return merged
+}
+
+// bindAddr returns the bind address of meta srv.
+func (m *metaSrv) bindAddr() string {
+	if len(m.config.BindAddr) > 0 {
+		return m.config.BindAddr
+	}
+	// Default bind address for meta srv.
+	return net.JoinHostPort("127.0.0.1", "3002")
+}
+
+func (m *metaSrv) Stop(ctx context.Context) error {
+	return m.StopReplicas(ctx, 0, m.config.Replicas)
+}
+
+func (m *metaSrv) StopReplicas(ctx context.Context, from, to int) error {
+	runPreStopHooks(ctx, m.config.Hooks, m, m.config.Env, m.workingDirs, m.logger)
+	return stopReplicaRange(ctx, m.Name(), from, to, m.workingDirs, m.logger)
 }
 
 func (m *metaSrv) BuildArgs(params ...interface{}) []string {
@@ -115,54 +183,75 @@ func (m *metaSrv) BuildArgs(params ...interface{}) []string {
 		logLevel = DefaultLogLevel
 	}
 
-	nodeID_, bindAddr_ := params[0], params[1]
+	nodeID_, bindAddr_, storeAddrs_ := params[0], params[1], params[2]
 	nodeID := nodeID_.(int)
 	bindAddr := bindAddr_.(string)
+	storeAddrs := storeAddrs_.(string)
 
 	args := []string{
 		fmt.Sprintf("--log-level=%s", logLevel),
 		m.Name(), "start",
-		fmt.Sprintf("--store-addr=%s", m.config.StoreAddr),
-		fmt.Sprintf("--server-addr=%s", m.config.ServerAddr),
 	}
-	args = GenerateAddrArg("--http-addr", m.config.HTTPAddr, nodeID, args)
-	args = GenerateAddrArg("--bind-addr", bindAddr, nodeID, args)
+	if m.config.BackendStorage != nil {
+		args = append(args, backendArgs(m.config.BackendStorage, storeAddrs)...)
+	} else {
+		args = append(args, fmt.Sprintf("--store-addr=%s", m.config.StoreAddr))
+	}
+	// The server address is only overridden for the replica explicitly, e.g. the one placed on a remote host.
+	serverAddr := m.config.ServerAddr
+	if addr, ok := m.config.ReplicaAddrs[nodeID]["--server-addr"]; ok && len(addr) > 0 {
+		serverAddr = addr
+	}
+	args = append(args, fmt.Sprintf("--server-addr=%s", serverAddr))
+	args = GenerateReplicaAddrArg("--http-addr", m.config.HTTPAddr, m.config.ReplicaAddrs, nodeID, args)
+	args = GenerateReplicaAddrArg("--bind-addr", bindAddr, m.config.ReplicaAddrs, nodeID, args)
 
 	if m.useMemoryMeta {
 		useMemoryMeta := strconv.FormatBool(m.useMemoryMeta)
 		args = GenerateAddrArg("--use-memory-store", useMemoryMeta, nodeID, args)
 	}
-
-	if len(m.config.Config) > 0 {
-		args = append(args, fmt.Sprintf("-c=%s", m.config.Config))
+	if len(m.config.Selector) > 0 {
+		args = append(args, fmt.Sprintf("--selector=%s", m.config.Selector))
 	}
+	if m.config.EnableRegionFailover {
+		args = append(args, "--enable-region-failover=true")
+	}
+
+	args = GenerateConfigArg(m.config.Config, m.config.Configs, nodeID, args)
 
 	return args
 }
 
-func (m *metaSrv) IsRunning(_ context.Context) bool {
+func (m *metaSrv) ListenAddrs() []ListenAddr {
+	var addrs []ListenAddr
 	for i := 0; i < m.config.Replicas; i++ {
-		addr := FormatAddrArg(m.config.HTTPAddr, i)
-		_, httpPort, err := net.SplitHostPort(addr)
-		if err != nil {
-			m.logger.V(5).Infof("failed to split host port in %s: %s", m.Name(), err)
-			return false
-		}
+		addrs = append(addrs, replicaListenAddrs(m.Name(), i, m.config.ReplicaAddrs,
+			"--http-addr", m.config.HTTPAddr,
+			"--bind-addr", m.bindAddr())...)
+	}
+	return addrs
+}
 
-		rsp, err := http.Get(fmt.Sprintf("http://localhost:%s/health", httpPort))
-		if err != nil {
-			m.logger.V(5).Infof("failed to get %s health: %s", m.Name(), err)
-			return false
-		}
+func (m *metaSrv) IsRunning(ctx context.Context) bool {
+	return m.healthProbe().isReplicasHealthy(ctx, m.config.Replicas)
+}
 
-		if rsp.StatusCode != http.StatusOK {
-			return false
-		}
+func (m *metaSrv) Status(ctx context.Context) []ReplicaStatus {
+	probe := m.healthProbe()
+	return replicasStatus(m.Name(), m.config.Replicas, m.workingDirs, func(replica int) bool {
+		return probe.isReplicaHealthy(ctx, replica)
+	})
+}
 
-		if err = rsp.Body.Close(); err != nil {
-			return false
-		}
+func (m *metaSrv) healthProbe() *healthProbe {
+	return &healthProbe{
+		name:         m.Name(),
+		check:        m.config.HealthCheck,
+		httpAddr:     m.config.HTTPAddr,
+		grpcAddr:     m.bindAddr(),
+		grpcArg:      "--bind-addr",
+		replicaAddrs: m.config.ReplicaAddrs,
+		healthHost:   m.config.HealthHost,
+		logger:       m.logger,
 	}
-
-	return true
 }
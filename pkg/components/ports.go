@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// ListenAddr is the address that one replica of cluster component listens on.
+type ListenAddr struct {
+	// Replica is the name of replica, e.g. "frontend.0".
+	Replica string
+
+	// Arg is the name of arg that specifies the address, e.g. "--http-addr".
+	Arg string
+
+	Addr string
+}
+
+func (l ListenAddr) String() string {
+	return fmt.Sprintf("'%s' of %s (%s)", l.Addr, l.Replica, l.Arg)
+}
+
+// replicaListenAddrs returns the non-empty listen addresses of the replica from the
+// args and their base addresses, see ReplicaAddr for how the address is resolved.
+func replicaListenAddrs(name string, replica int, replicaAddrs config.ReplicaAddrs, argAddrs ...string) []ListenAddr {
+	var addrs []ListenAddr
+	for i := 0; i+1 < len(argAddrs); i += 2 {
+		addr := ReplicaAddr(replicaAddrs, argAddrs[i], argAddrs[i+1], replica)
+		if len(addr) == 0 {
+			continue
+		}
+		addrs = append(addrs, ListenAddr{
+			Replica: replicaDirName(name, replica),
+			Arg:     argAddrs[i],
+			Addr:    addr,
+		})
+	}
+	return addrs
+}
+
+// CheckPortConflicts checks whether any of the addresses is duplicated with
+// the others or is already in use, and reports all the conflicts in one error.
+func CheckPortConflicts(addrs []ListenAddr) error {
+	var conflicts []string
+	for i, addr := range addrs {
+		duplicated := false
+		for _, prev := range addrs[:i] {
+			if isSameListenAddr(prev.Addr, addr.Addr) {
+				conflicts = append(conflicts, fmt.Sprintf("%s is duplicated with %s", addr, prev))
+				duplicated = true
+				break
+			}
+		}
+		if duplicated {
+			continue
+		}
+
+		if err := checkAddrAvailable(addr.Addr); err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("%s is not available: %v", addr, err))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("found %d port conflict(s):\n  - %s", len(conflicts), strings.Join(conflicts, "\n  - "))
+	}
+	return nil
+}
+
+// isSameListenAddr checks whether the two addresses conflict with each other.
+// The addresses with the same port conflict if any of their hosts is unspecified.
+func isSameListenAddr(a, b string) bool {
+	hostA, portA, errA := net.SplitHostPort(a)
+	hostB, portB, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	if portA != portB {
+		return false
+	}
+
+	return hostA == hostB || isUnspecifiedHost(hostA) || isUnspecifiedHost(hostB)
+}
+
+func isUnspecifiedHost(host string) bool {
+	if len(host) == 0 {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// checkAddrAvailable checks whether the address can be listened on.
+func checkAddrAvailable(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return l.Close()
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPortConflicts(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	inUse := l.Addr().String()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	freeAddr := free.Addr().String()
+	assert.NoError(t, free.Close())
+
+	assert.NoError(t, CheckPortConflicts([]ListenAddr{
+		{Replica: "frontend.0", Arg: "--http-addr", Addr: freeAddr},
+	}))
+
+	err = CheckPortConflicts([]ListenAddr{
+		{Replica: "frontend.0", Arg: "--http-addr", Addr: inUse},
+		{Replica: "datanode.0", Arg: "--http-addr", Addr: freeAddr},
+		{Replica: "datanode.1", Arg: "--rpc-addr", Addr: freeAddr},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "2 port conflict(s)")
+	assert.Contains(t, err.Error(), "of frontend.0 (--http-addr) is not available")
+	assert.Contains(t, err.Error(), "of datanode.1 (--rpc-addr) is duplicated with")
+}
+
+func TestIsSameListenAddr(t *testing.T) {
+	assert.True(t, isSameListenAddr("127.0.0.1:4000", "127.0.0.1:4000"))
+	assert.True(t, isSameListenAddr("0.0.0.0:4000", "127.0.0.1:4000"))
+	assert.True(t, isSameListenAddr("[::]:4000", "192.168.1.10:4000"))
+	assert.False(t, isSameListenAddr("127.0.0.1:4000", "192.168.1.10:4000"))
+	assert.False(t, isSameListenAddr("0.0.0.0:4000", "0.0.0.0:4001"))
+}
+
+func TestReplicaListenAddrs(t *testing.T) {
+	assert.Equal(t, []ListenAddr{
+		{Replica: "datanode.1", Arg: "--http-addr", Addr: "0.0.0.0:14301"},
+		{Replica: "datanode.1", Arg: "--rpc-addr", Addr: "0.0.0.0:14101"},
+	}, replicaListenAddrs("datanode", 1, nil,
+		"--http-addr", "0.0.0.0:14300",
+		"--rpc-addr", "0.0.0.0:14100",
+		"--mysql-addr", ""))
+}
@@ -0,0 +1,99 @@
+//go:build !windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// corePatternFile is where the kernel of Linux writes the core files.
+const corePatternFile = "/proc/sys/kernel/core_pattern"
+
+// setProcessGroup runs the process in its own process group, so it will not receive
+// the signals that are sent to the terminal of current gtctl process.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// trackProcess does nothing on Unix, the process is stopped by the signals.
+func trackProcess(_ *os.Process) error {
+	return nil
+}
+
+// resumeStartedProcess does nothing on Unix, the process is not started suspended.
+func resumeStartedProcess(_ *os.Process) error {
+	return nil
+}
+
+// terminateProcess asks the process to exit gracefully by SIGTERM.
+func terminateProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
+
+// forceKillProcess kills the process by SIGKILL.
+func forceKillProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGKILL)
+}
+
+// suspendProcess pauses the process by SIGSTOP until it's resumed.
+func suspendProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGSTOP)
+}
+
+// resumeProcess resumes the paused process by SIGCONT.
+func resumeProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGCONT)
+}
+
+// isProcessAlive checks whether the process is alive by sending signal 0 to it.
+func isProcessAlive(p *os.Process) bool {
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// enableCoreDumps raises the soft limit of the size of core files to the hard limit,
+// which is inherited by the processes started afterwards.
+func enableCoreDumps() error {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CORE, &limit); err != nil {
+		return err
+	}
+	limit.Cur = limit.Max
+	return syscall.Setrlimit(syscall.RLIMIT_CORE, &limit)
+}
+
+// systemCorePattern returns the pattern of where the core files are written, see core(5).
+func systemCorePattern() string {
+	if runtime.GOOS == "darwin" {
+		return "/cores/core.%p"
+	}
+	raw, err := os.ReadFile(corePatternFile)
+	if err != nil {
+		return "core"
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// shellCommand returns the argv that runs the command by the shell.
+func shellCommand(command string) []string {
+	return []string{"sh", "-c", command}
+}
@@ -0,0 +1,181 @@
+//go:build windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code of the process that has not exited yet.
+const stillActive = 259
+
+// setProcessGroup runs the process in its own process group, so it will not receive the
+// Ctrl-C of the console of current gtctl process, and it can be sent Ctrl-Break alone.
+// The process is started suspended, so it can't start any child before it's assigned to
+// its job object by trackProcess, and it runs once it's resumed by resumeStartedProcess.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | windows.CREATE_SUSPENDED}
+}
+
+// trackProcess assigns the process to the job object named after its pid, so the process and all
+// the children it starts, e.g. the java process of kafka, are killed together. The handle of job is
+// duplicated into the process, which keeps the named job object alive as long as the process is alive,
+// so it can be opened and killed by the other gtctl processes. The job kills the left children once
+// the process exits, since the duplicated handle is the last one and is closed with the process.
+func trackProcess(p *os.Process) error {
+	job, err := openJob(p.Pid)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(job)
+
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{}
+	info.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+	if _, err = windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		return err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE|windows.PROCESS_DUP_HANDLE,
+		false, uint32(p.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+
+	if err = windows.AssignProcessToJobObject(job, process); err != nil {
+		return err
+	}
+
+	var duplicated windows.Handle
+	return windows.DuplicateHandle(windows.CurrentProcess(), job, process, &duplicated, 0, false, windows.DUPLICATE_SAME_ACCESS)
+}
+
+// resumeStartedProcess resumes the threads of the process that was started suspended by setProcessGroup.
+func resumeStartedProcess(p *os.Process) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	resumed := false
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != uint32(p.Pid) {
+			continue
+		}
+		thread, openErr := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if openErr != nil {
+			return openErr
+		}
+		_, resumeErr := windows.ResumeThread(thread)
+		_ = windows.CloseHandle(thread)
+		if resumeErr != nil {
+			return resumeErr
+		}
+		resumed = true
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return err
+	}
+	if !resumed {
+		return fmt.Errorf("no thread of process '%d' is found", p.Pid)
+	}
+	return nil
+}
+
+// terminateProcess asks the process to exit gracefully by Ctrl-Break, which is the only console event
+// that can be sent to one process group. It fails if the process is not attached to the console of
+// current gtctl process, e.g. it was started by another terminal, in which case the process is killed.
+func terminateProcess(p *os.Process) error {
+	if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(p.Pid)); err == nil {
+		return nil
+	}
+	return forceKillProcess(p)
+}
+
+// forceKillProcess kills the process and its children in the job object of process.
+func forceKillProcess(p *os.Process) error {
+	if job, err := openJob(p.Pid); err == nil {
+		_ = windows.TerminateJobObject(job, 1)
+		_ = windows.CloseHandle(job)
+	}
+
+	// The process is not in the job if it was started by the previous versions of gtctl.
+	if !isProcessAlive(p) {
+		return os.ErrProcessDone
+	}
+	return p.Kill()
+}
+
+// suspendProcess is not supported on Windows.
+func suspendProcess(_ *os.Process) error {
+	return fmt.Errorf("pausing processes is not supported on windows")
+}
+
+// resumeProcess is not supported on Windows.
+func resumeProcess(_ *os.Process) error {
+	return fmt.Errorf("resuming processes is not supported on windows")
+}
+
+// isProcessAlive checks whether the process is alive by its exit code.
+func isProcessAlive(p *os.Process) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(p.Pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err = windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// enableCoreDumps is not supported on Windows.
+func enableCoreDumps() error {
+	return fmt.Errorf("core dumps are not supported on windows")
+}
+
+// systemCorePattern is empty on Windows, which has no core files.
+func systemCorePattern() string {
+	return ""
+}
+
+// shellCommand returns the argv that runs the command by the command interpreter.
+func shellCommand(command string) []string {
+	return []string{"cmd", "/C", command}
+}
+
+// openJob opens the job object of the process, which is created if it doesn't exist.
+func openJob(pid int) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(fmt.Sprintf("Local\\gtctl-process-%d", pid))
+	if err != nil {
+		return 0, err
+	}
+	return windows.CreateJobObject(nil, name)
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the number of clock ticks per second that the cpu times in /proc are
+// measured in, which is fixed as USER_HZ to the user space on Linux.
+const clockTicks = 100
+
+// ProcessStats is the resource usage of the process of one replica.
+type ProcessStats struct {
+	// CPUTime is the total time that the process has been scheduled in user and kernel mode.
+	CPUTime time.Duration
+
+	// RSS is the resident set size of the process in bytes.
+	RSS uint64
+}
+
+// ReadProcessStats reads the resource usage of process from the proc filesystem,
+// it fails on the systems without the proc filesystem, e.g. macOS.
+func ReadProcessStats(pid int) (*ProcessStats, error) {
+	procDir := path.Join("/proc", strconv.Itoa(pid))
+
+	raw, err := os.ReadFile(path.Join(procDir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	cpuTime, err := parseCPUTime(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid stat of process '%d': %v", pid, err)
+	}
+
+	rss, err := readRSS(path.Join(procDir, "status"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid status of process '%d': %v", pid, err)
+	}
+
+	return &ProcessStats{CPUTime: cpuTime, RSS: rss}, nil
+}
+
+// parseCPUTime parses the utime and stime of process from the content of /proc/<pid>/stat.
+func parseCPUTime(stat string) (time.Duration, error) {
+	// The command name in parentheses may contain spaces, so the fields are split after it.
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("missing command name")
+	}
+
+	// The fields after the command name start from the state, which is the 3rd field,
+	// and the utime and stime are the 14th and 15th fields.
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("expect at least 15 fields, got %d", len(fields)+2)
+	}
+
+	var ticks uint64
+	for _, field := range fields[11:13] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		ticks += v
+	}
+
+	return time.Duration(ticks) * time.Second / clockTicks, nil
+}
+
+// readRSS reads the resident set size of process from the VmRSS of /proc/<pid>/status.
+func readRSS(statusPath string) (uint64, error) {
+	f, err := os.Open(statusPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmRSS:" {
+			continue
+		}
+
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	// The kernel threads and zombie processes have no VmRSS.
+	return 0, nil
+}
+
+// ReadProcessStartTime reads when the process started from the proc filesystem,
+// it fails on the systems without the proc filesystem, e.g. macOS.
+func ReadProcessStartTime(pid int) (time.Time, error) {
+	raw, err := os.ReadFile(path.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	ticks, err := parseStartTicks(string(raw))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid stat of process '%d': %v", pid, err)
+	}
+
+	bootTime, err := readBootTime("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return bootTime.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// parseStartTicks parses the starttime of process, i.e. the clock ticks after the system boots,
+// from the content of /proc/<pid>/stat.
+func parseStartTicks(stat string) (uint64, error) {
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("missing command name")
+	}
+
+	// The starttime is the 22nd field, see parseCPUTime.
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("expect at least 22 fields, got %d", len(fields)+2)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// readBootTime reads the boot time of system from the btime of /proc/stat.
+func readBootTime(statPath string) (time.Time, error) {
+	f, err := os.Open(statPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "btime" {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0), nil
+	}
+	if err = scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("missing btime in '%s'", statPath)
+}
+
+// ReadOpenFiles reads the number of open file descriptors of process from the proc filesystem,
+// it fails on the systems without the proc filesystem, e.g. macOS.
+func ReadOpenFiles(pid int) (int, error) {
+	entries, err := os.ReadDir(path.Join("/proc", strconv.Itoa(pid), "fd"))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// ReadProcessArgs reads the full argv of process from the proc filesystem,
+// it fails on the systems without the proc filesystem, e.g. macOS.
+func ReadProcessArgs(pid int) ([]string, error) {
+	raw, err := os.ReadFile(path.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(raw), "\x00"), "\x00"), nil
+}
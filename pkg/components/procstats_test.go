@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUTime(t *testing.T) {
+	// The command name contains spaces and parentheses, the utime is 250 and the stime is 50.
+	stat := "1234 (greptime (v1) x) S 1 1234 1234 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 8 0 100 0 0"
+	cpuTime, err := parseCPUTime(stat)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, cpuTime)
+
+	_, err = parseCPUTime("1234 greptime S 1")
+	assert.ErrorContains(t, err, "missing command name")
+	_, err = parseCPUTime("1234 (greptime) S 1 1234")
+	assert.ErrorContains(t, err, "expect at least 15 fields")
+}
+
+func TestParseStartTicks(t *testing.T) {
+	stat := "1234 (greptime (v1) x) S 1 1234 1234 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 8 0 100 0 0"
+	ticks, err := parseStartTicks(stat)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), ticks)
+
+	_, err = parseStartTicks("1234 (greptime) S 1 1234 1234 0 -1 4194560 1000 0 0 0 250 50")
+	assert.ErrorContains(t, err, "expect at least 22 fields")
+}
+
+func TestReadProcessStats(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("the proc filesystem is not available")
+	}
+
+	stats, err := ReadProcessStats(os.Getpid())
+	assert.NoError(t, err)
+	assert.Greater(t, stats.RSS, uint64(0))
+
+	files, err := ReadOpenFiles(os.Getpid())
+	assert.NoError(t, err)
+	assert.Greater(t, files, 0)
+
+	args, err := ReadProcessArgs(os.Getpid())
+	assert.NoError(t, err)
+	assert.Equal(t, os.Args, args)
+
+	started, err := ReadProcessStartTime(os.Getpid())
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), started, time.Hour)
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	DefaultReadinessTimeout = 2 * time.Minute
+	DefaultInitialBackoff   = 500 * time.Millisecond
+	DefaultMaxBackoff       = 5 * time.Second
+
+	// logTailLines is the number of lines of logs that reported when a replica fails to be ready.
+	logTailLines = 20
+)
+
+// readinessPolicy returns the readiness policy with the unset fields filled by defaults.
+func readinessPolicy(readiness *config.Readiness) config.Readiness {
+	policy := config.Readiness{}
+	if readiness != nil {
+		policy = *readiness
+	}
+
+	if policy.Timeout == 0 {
+		policy.Timeout = DefaultReadinessTimeout
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = DefaultInitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = DefaultMaxBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+
+	return policy
+}
+
+// waitForReady waits for all the replicas of component to become healthy under the readiness policy.
+// It fails fast with the tail of logs if any replica has exited before it becomes healthy.
+func waitForReady(ctx context.Context, component ClusterComponent, readiness *config.Readiness,
+	dirs allocatedDirs, workingDirs WorkingDirs, logger logger.Logger) error {
+	// Nothing is started in dry-run mode.
+	if workingDirs.DryRun != nil {
+		return nil
+	}
+
+	policy := readinessPolicy(readiness)
+
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+
+	backoff := policy.InitialBackoff
+	for retry := 0; ; retry++ {
+		if component.IsRunning(ctx) {
+			return nil
+		}
+
+		if err := dirs.checkExited(); err != nil {
+			return fmt.Errorf("%s is not ready: %v", component.Name(), err)
+		}
+
+		if policy.MaxRetries > 0 && retry >= policy.MaxRetries {
+			return fmt.Errorf("%s is not ready after %d retries%s", component.Name(), retry, dirs.logsTail())
+		}
+
+		logger.V(5).Infof("%s is not ready, retry after %s", component.Name(), backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			// The replica may exit and cancel the context at the same time.
+			if err := dirs.checkExited(); err != nil {
+				return fmt.Errorf("%s is not ready: %v", component.Name(), err)
+			}
+			return fmt.Errorf("%s is not ready: %v%s", component.Name(), ctx.Err(), dirs.logsTail())
+		}
+
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// checkExited returns an error with the tail of logs if any replica has exited.
+func (ad *allocatedDirs) checkExited() error {
+	for i, pidDir := range ad.pidsDirs {
+		pid, err := readPid(pidDir)
+		if err != nil {
+			return err
+		}
+
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+
+		if !isProcessAlive(p) {
+			var tail string
+			if i < len(ad.logsDirs) {
+				tail = logTail(ad.logsDirs[i])
+			}
+			return fmt.Errorf("replica '%s' (pid '%d') has exited%s", filepath.Base(pidDir), pid, tail)
+		}
+	}
+
+	return nil
+}
+
+// logsTail returns the tail of logs of all the replicas.
+func (ad *allocatedDirs) logsTail() string {
+	var tails []string
+	for _, logDir := range ad.logsDirs {
+		tails = append(tails, logTail(logDir))
+	}
+	return strings.Join(tails, "")
+}
+
+// logTail returns the last lines of the log file under logDir, or an empty string if it can't be read.
+func logTail(logDir string) string {
+	logFile := filepath.Join(logDir, logFileName)
+	raw, err := os.ReadFile(logFile)
+	if err != nil || len(raw) == 0 {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(raw), "\n"), "\n")
+	if len(lines) > logTailLines {
+		lines = lines[len(lines)-logTailLines:]
+	}
+
+	return fmt.Sprintf("\nlast %d lines of '%s':\n%s", len(lines), logFile, strings.Join(lines, "\n"))
+}
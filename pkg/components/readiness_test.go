@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestReadinessPolicy(t *testing.T) {
+	policy := readinessPolicy(nil)
+	assert.Equal(t, DefaultReadinessTimeout, policy.Timeout)
+	assert.Equal(t, DefaultInitialBackoff, policy.InitialBackoff)
+	assert.Equal(t, DefaultMaxBackoff, policy.MaxBackoff)
+	assert.Equal(t, 0, policy.MaxRetries)
+
+	policy = readinessPolicy(&config.Readiness{
+		InitialBackoff: 10 * time.Second,
+		MaxRetries:     3,
+	})
+	assert.Equal(t, DefaultReadinessTimeout, policy.Timeout)
+	assert.Equal(t, 10*time.Second, policy.InitialBackoff)
+	assert.Equal(t, 10*time.Second, policy.MaxBackoff)
+	assert.Equal(t, 3, policy.MaxRetries)
+}
+
+func TestLogTail(t *testing.T) {
+	logDir := t.TempDir()
+	assert.Empty(t, logTail(logDir))
+
+	var lines []string
+	for i := 0; i < logTailLines+5; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	err := os.WriteFile(path.Join(logDir, logFileName), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	assert.NoError(t, err)
+
+	tail := logTail(logDir)
+	assert.Contains(t, tail, fmt.Sprintf("line %d", logTailLines+4))
+	assert.Contains(t, tail, "\nline 5\n")
+	assert.NotContains(t, tail, "\nline 4\n")
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// limitsEnvKey is set in the env of gtctl that is re-executed by newCommand to run the binary of replica.
+// The re-executed gtctl sets the limits to itself and then execs the binary, so the binary inherits the
+// limits from the start.
+const limitsEnvKey = "GTCTL_REPLICA_LIMITS"
+
+// newCommand creates the command to run the binary. If the max open files or niceness is set, the command
+// runs the binary through the re-executed gtctl, see ExecWithLimits.
+func newCommand(option *RunOptions) (*exec.Cmd, error) {
+	env := buildEnv(option.env)
+	resources := option.resources
+	if resources == nil || (resources.MaxOpenFiles == 0 && resources.Nice == 0) {
+		cmd := exec.Command(option.Binary, option.args...)
+		cmd.Env = env
+		return cmd, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("the resource limits of '%s' are not supported on windows", option.Name)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(self, append([]string{option.Binary}, option.args...)...)
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, fmt.Sprintf("%s=%d,%d", limitsEnvKey, resources.MaxOpenFiles, resources.Nice))
+	return cmd, nil
+}
+
+// ExecWithLimits sets the limits to current process and execs the binary in the args if current process
+// is re-executed by newCommand, it returns immediately otherwise. It should be called at the start of main.
+func ExecWithLimits() {
+	limits, ok := os.LookupEnv(limitsEnvKey)
+	if !ok {
+		return
+	}
+
+	var maxOpenFiles, nice int
+	if _, err := fmt.Sscanf(limits, "%d,%d", &maxOpenFiles, &nice); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid %s '%s': %v\n", limitsEnvKey, limits, err)
+		os.Exit(1)
+	}
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "no binary to run with %s\n", limitsEnvKey)
+		os.Exit(1)
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, limitsEnvKey+"=") {
+			env = append(env, kv)
+		}
+	}
+	if err := execWithLimits(os.Args[1:], env, maxOpenFiles, nice); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run '%s' with the limits: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
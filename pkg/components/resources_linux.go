@@ -0,0 +1,85 @@
+//go:build linux
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"syscall"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// cgroupRoot is the mount point of cgroup v2.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// useCgroup makes the command start in the cgroup of replica if it's set, so the process is limited from
+// the start. The returned function should be called to release the group after the command is started.
+func useCgroup(cmd *exec.Cmd, option *RunOptions) (func(), error) {
+	if option.resources == nil || option.resources.Cgroup == nil {
+		return func() {}, nil
+	}
+
+	group, err := setupCgroup(option.resources.Cgroup, option.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup cgroup: %v", err)
+	}
+	dir, err := os.Open(group)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+
+	return func() { _ = dir.Close() }, nil
+}
+
+// setupCgroup creates the group of the replica under the parent group and writes the limits to it.
+func setupCgroup(cgroup *config.Cgroup, replica string) (string, error) {
+	parent := path.Join(cgroupRoot, cgroup.Parent)
+	group := path.Join(parent, replica)
+	if err := fileutils.EnsureDir(group); err != nil {
+		return "", err
+	}
+
+	// Enable the controllers for the sub-groups of parent.
+	if err := os.WriteFile(path.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu"), 0644); err != nil {
+		return "", err
+	}
+
+	if len(cgroup.MemoryMax) > 0 {
+		if err := os.WriteFile(path.Join(group, "memory.max"), []byte(cgroup.MemoryMax), 0644); err != nil {
+			return "", err
+		}
+	}
+	if len(cgroup.CPUMax) > 0 {
+		if err := os.WriteFile(path.Join(group, "cpu.max"), []byte(cgroup.CPUMax), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	return group, nil
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestUseCgroup(t *testing.T) {
+	parent := "gtctl-test.slice"
+	if _, err := os.Stat(path.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		t.Skip("cgroup v2 is not mounted")
+	}
+	if err := os.Mkdir(path.Join(cgroupRoot, parent), 0755); err != nil {
+		t.Skipf("cgroup v2 is not writable: %v", err)
+	}
+	defer os.Remove(path.Join(cgroupRoot, parent))
+
+	option := &RunOptions{
+		Binary:    "sleep",
+		Name:      "test.0",
+		args:      []string{"10"},
+		resources: &config.Resources{Cgroup: &config.Cgroup{Parent: parent, MemoryMax: "1G"}},
+	}
+	cmd, err := newCommand(option)
+	assert.NoError(t, err)
+	release, err := useCgroup(cmd, option)
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Start())
+	release()
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		_ = os.Remove(path.Join(cgroupRoot, parent, option.Name))
+	}()
+
+	// The process is in the group of replica from the start.
+	group, err := os.ReadFile(path.Join("/proc", strconv.Itoa(cmd.Process.Pid), "cgroup"))
+	assert.NoError(t, err)
+	assert.Equal(t, "0::/"+path.Join(parent, option.Name), strings.TrimSpace(string(group)))
+}
@@ -1,3 +1,5 @@
+//go:build !linux
+
 /*
  * Copyright 2023 Greptime Team
  *
@@ -14,23 +16,18 @@
  * limitations under the License.
  */
 
-package baremetal
+package components
 
 import (
-	"context"
 	"fmt"
-
-	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"os/exec"
+	"runtime"
 )
 
-func (c *Cluster) List(ctx context.Context, options *opt.ListOptions) error {
-	return fmt.Errorf("do not support")
-}
-
-func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
-	return fmt.Errorf("do not support")
-}
-
-func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) error {
-	return fmt.Errorf("do not support")
+// useCgroup fails if the cgroup is set, since it's only supported on Linux.
+func useCgroup(_ *exec.Cmd, option *RunOptions) (func(), error) {
+	if option.resources == nil || option.resources.Cgroup == nil {
+		return func() {}, nil
+	}
+	return nil, fmt.Errorf("cgroup is not supported on %s", runtime.GOOS)
 }
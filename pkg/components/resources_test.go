@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestMain(m *testing.M) {
+	// The test binary is re-executed by newCommand to run the binaries with limits.
+	ExecWithLimits()
+	os.Exit(m.Run())
+}
+
+func TestNewCommand(t *testing.T) {
+	option := &RunOptions{
+		Binary: "sh",
+		Name:   "test.0",
+		args:   []string{"-c", "echo hello"},
+	}
+
+	cmd, err := newCommand(option)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", "echo hello"}, cmd.Args)
+	assert.Nil(t, cmd.Env)
+
+	// The cgroup is applied by useCgroup, so the binary runs directly.
+	option.resources = &config.Resources{Cgroup: &config.Cgroup{Parent: "gtctl.slice"}}
+	cmd, err = newCommand(option)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", "echo hello"}, cmd.Args)
+
+	option.resources = &config.Resources{MaxOpenFiles: 256, Nice: 5}
+	cmd, err = newCommand(option)
+	if runtime.GOOS == "windows" {
+		assert.Error(t, err)
+		return
+	}
+	assert.NoError(t, err)
+	self, err := os.Executable()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{self, "sh", "-c", "echo hello"}, cmd.Args)
+	assert.Contains(t, cmd.Env, limitsEnvKey+"=256,5")
+}
+
+func TestExecWithLimits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the resource limits are not supported on windows")
+	}
+
+	option := &RunOptions{
+		Binary:    "sh",
+		Name:      "test.0",
+		args:      []string{"-c", `ulimit -n; ps -o nice= -p $$; env | grep -c "^` + limitsEnvKey + `=" || true`},
+		resources: &config.Resources{MaxOpenFiles: 256, Nice: 5},
+	}
+	cmd, err := newCommand(option)
+	assert.NoError(t, err)
+
+	// The binary inherits the limits and doesn't see the env of limits.
+	output, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"256", "5", "0"}, strings.Fields(string(output)))
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// execWithLimits sets the max open files and niceness of current process, and then replaces
+// it with the binary, which inherits the limits.
+func execWithLimits(args, env []string, maxOpenFiles, nice int) error {
+	binary, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The niceness only applies to the calling thread on Linux, so the same thread must exec the binary.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if maxOpenFiles > 0 {
+		// Use the Setrlimit of syscall, so the runtime won't restore its own limit before exec.
+		limit := &syscall.Rlimit{Cur: uint64(maxOpenFiles), Max: uint64(maxOpenFiles)}
+		if err = syscall.Setrlimit(syscall.RLIMIT_NOFILE, limit); err != nil {
+			return err
+		}
+	}
+	if nice != 0 {
+		if err = syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice); err != nil {
+			return err
+		}
+	}
+
+	return syscall.Exec(binary, args, env)
+}
@@ -17,82 +17,370 @@
 package components
 
 import (
-	"bufio"
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
-	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
+const (
+	pidFileName = "pid"
+	logFileName = "log"
+
+	// startTimeTolerance is the tolerance of comparing the start time of process with when its pid was recorded.
+	startTimeTolerance = 2 * time.Second
+)
+
+// enableCoreDumpsOnce enables the core dumps of gtctl process once, which are inherited by all the components.
+var enableCoreDumpsOnce sync.Once
+
 // RunOptions contains all the options for one component to run on bare-metal.
 type RunOptions struct {
 	Binary string
 	Name   string
 
-	pidDir string
-	logDir string
-	args   []string
+	pidDir    string
+	logDir    string
+	args      []string
+	resources *config.Resources
+	env       map[string]string
+	restart   *config.Restart
+
+	// logRotation rotates the log file by piping the output through gtctl if it's set.
+	logRotation *config.LogRotation
+
+	// crashesDir is where the crashes of the process are recorded with the captures configured by crashDump.
+	crashesDir string
+	crashDump  *config.CrashDump
+
+	// events publishes the starts and crashes of the process.
+	events *events.Bus
+
+	// dryRun records the command instead of running it if it's set.
+	dryRun *DryRun
 }
 
-func runBinary(ctx context.Context, stop context.CancelFunc,
-	option *RunOptions, wg *sync.WaitGroup, logger logger.Logger) error {
-	cmd := exec.CommandContext(ctx, option.Binary, option.args...)
+// runBinary starts the binary in background and supervises it by the restart policy.
+// The process will not be killed when the context is done, it should be stopped by calling stopProcess.
+func runBinary(stop context.CancelFunc, option *RunOptions, wg *sync.WaitGroup, logger logger.Logger) error {
+	if option.dryRun != nil {
+		option.dryRun.AddCommand(option.Name, option.env, append([]string{option.Binary}, option.args...)...)
+		return nil
+	}
 
-	// output to binary.
-	logFile := path.Join(option.logDir, "log")
-	outputFile, err := os.Create(logFile)
+	cmd, err := startBinary(option, logger)
 	if err != nil {
 		return err
 	}
 
-	outputFileWriter := bufio.NewWriter(outputFile)
-	cmd.Stdout = outputFileWriter
-	cmd.Stderr = outputFileWriter
+	s := newSupervisor(option, stop, logger)
+	if err = s.recordRestarts(); err != nil {
+		return err
+	}
+	option.events.Publish(&events.Event{
+		Type:      events.TypeComponentStarted,
+		Component: option.Name,
+		Message:   fmt.Sprintf("started with pid '%d'", cmd.Process.Pid),
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.supervise(cmd)
+	}()
+
+	return nil
+}
+
+// startBinary starts the binary and records its pid under the pid dir.
+func startBinary(option *RunOptions, logger logger.Logger) (*exec.Cmd, error) {
+	cmd, err := newCommand(option)
+	if err != nil {
+		return nil, err
+	}
+
+	logFile := filepath.Join(option.logDir, logFileName)
+	if option.logRotation != nil {
+		output, err := newRotatingWriter(logFile, option.logRotation, logger)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdout = output
+		cmd.Stderr = output
+	} else {
+		// Output to the log file directly, so the process can keep on writing logs
+		// even if it outlives the gtctl process which started it.
+		outputFile, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer outputFile.Close()
+
+		cmd.Stdout = outputFile
+		cmd.Stderr = outputFile
+	}
+
+	setProcessGroup(cmd)
+	releaseCgroup, err := useCgroup(cmd, option)
+	if err != nil {
+		closeOutput(cmd)
+		return nil, fmt.Errorf("failed to apply the cgroup of '%s': %v", option.Name, err)
+	}
+	defer releaseCgroup()
+
+	if option.crashDump != nil && option.crashDump.CoreDump {
+		enableCoreDumpsOnce.Do(func() {
+			if err := enableCoreDumps(); err != nil {
+				logger.Warnf("failed to enable core dumps: %v", err)
+			}
+		})
+	}
 
 	if err = cmd.Start(); err != nil {
-		return err
+		closeOutput(cmd)
+		return nil, err
+	}
+	if err = trackProcess(cmd.Process); err != nil {
+		logger.Warnf("failed to track the children of '%s' (pid '%d'): %v", option.Name, cmd.Process.Pid, err)
+	}
+	if err = resumeStartedProcess(cmd.Process); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		closeOutput(cmd)
+		return nil, fmt.Errorf("failed to resume '%s' (pid '%d'): %v", option.Name, cmd.Process.Pid, err)
 	}
 
 	pid := strconv.Itoa(cmd.Process.Pid)
 	logger.V(3).Infof("run '%s' binary '%s' with args: '%v', log: '%s', pid: '%s'",
-		option.Name, option.Binary, option.args, option.logDir, pid)
+		option.Name, option.Binary, RedactArgs(option.args), option.logDir, pid)
+
+	pidFile := filepath.Join(option.pidDir, pidFileName)
+	if err = os.WriteFile(pidFile, []byte(pid), 0644); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// buildEnv appends the env to the environment of current process, the env takes
+// precedence over the environment variables with the same keys.
+func buildEnv(env map[string]string) []string {
+	if len(env) == 0 {
+		// Use the environment of current process.
+		return nil
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	environ := os.Environ()
+	for _, k := range keys {
+		environ = append(environ, fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	return environ
+}
+
+// componentBinary returns the binary of component, the binaryPath of component overrides the binary of cluster.
+func componentBinary(binary, binaryPath string) (string, error) {
+	if len(binaryPath) == 0 {
+		return binary, nil
+	}
+
+	if exist, _ := fileutils.IsFileExists(binaryPath); !exist {
+		return "", fmt.Errorf("binary '%s' is not exist", binaryPath)
+	}
+	return binaryPath, nil
+}
+
+// ReplicaLogFile returns the log file of the replica under the logs dir, e.g. "<logs dir>/frontend.0/log".
+func ReplicaLogFile(logsDir, replica string) string {
+	return filepath.Join(logsDir, replica, logFileName)
+}
+
+// replicaDirName returns the directory name of the replica of one component.
+func replicaDirName(name string, replica int) string {
+	return fmt.Sprintf("%s.%d", name, replica)
+}
+
+// readPid reads the pid of the process from the pid file under pidDir.
+func readPid(pidDir string) (int, error) {
+	raw, err := os.ReadFile(filepath.Join(pidDir, pidFileName))
+	if err != nil {
+		return 0, err
+	}
 
-	pidFile := path.Join(option.pidDir, "pid")
-	f, err := os.Create(pidFile)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid in '%s': %v", pidDir, err)
+	}
+	return pid, nil
+}
+
+// stopProcess stops the process whose pid is recorded under pidDir gracefully.
+// It asks the process to exit, i.e. SIGTERM on Unix and Ctrl-Break on Windows, and waits for
+// it to exit until the ctx is done, then kills it. It returns nil if the process has already exited.
+func stopProcess(ctx context.Context, pidDir string, logger logger.Logger) error {
+	pid, err := readPid(pidDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	_, err = f.Write([]byte(pid))
+	// The pid that has been reused by another process, e.g. after a reboot, must not be stopped.
+	if !IsRecordedProcessRunning(pidDir) {
+		logger.V(3).Infof("process (pid '%d') recorded in '%s' is not running", pid, pidDir)
+		if err = os.Remove(filepath.Join(pidDir, pidFileName)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	p, err := os.FindProcess(pid)
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		defer wg.Done()
-		wg.Add(1)
-		if err := cmd.Wait(); err != nil {
-			// Caught signal kill and interrupt error then ignore.
-			if exit, ok := err.(*exec.ExitError); ok {
-				if status, ok := exit.Sys().(syscall.WaitStatus); ok && status.Signaled() {
-					if status.Signal() == syscall.SIGKILL || status.Signal() == syscall.SIGINT {
-						return
-					}
-				}
+	// Remove the pid file before sending the signal, which tells the supervisor
+	// that the process is stopped on purpose and should not be restarted.
+	if err = os.Remove(filepath.Join(pidDir, pidFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	logger.V(3).Infof("stopping process (pid '%d') recorded in '%s'", pid, pidDir)
+	if err = terminateProcess(p); err != nil {
+		if isProcessDone(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Waiting for the process to exit.
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !isProcessAlive(p) {
+				return nil
 			}
-			logger.Errorf("component '%s' binary '%s' (pid '%s') exited with error: %v", option.Name, option.Binary, pid, err)
-			logger.Errorf("args: '%v'", option.args)
-			_ = outputFileWriter.Flush()
+		case <-ctx.Done():
+			logger.Warnf("process (pid '%d') recorded in '%s' did not exit in time, killing it", pid, pidDir)
+			return killProcess(p)
+		}
+	}
+}
+
+// stopProcessInTime stops the process recorded under pidDir like stopProcess, the process is killed if it
+// doesn't exit in the drain timeout, which starts when the process is being stopped rather than sharing
+// with the other replicas.
+func stopProcessInTime(ctx context.Context, pidDir string, drainTimeout time.Duration, logger logger.Logger) error {
+	if drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, drainTimeout)
+		defer cancel()
+	}
+	return stopProcess(ctx, pidDir, logger)
+}
 
-			// If one component has failed, stop the whole context.
-			stop()
+// killProcess kills the process and waits for it to exit.
+func killProcess(p *os.Process) error {
+	if err := forceKillProcess(p); err != nil {
+		if isProcessDone(err) {
+			return nil
 		}
-	}()
+		return err
+	}
 
+	for retry := 0; retry < 50; retry++ {
+		if !isProcessAlive(p) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("process (pid '%d') is still alive after being killed", p.Pid)
+}
+
+// isProcessDone checks whether the error of sending signal means the process has already exited.
+func isProcessDone(err error) bool {
+	return err == os.ErrProcessDone || err == syscall.ESRCH
+}
+
+// IsProcessRunning checks whether the process of pid is running.
+func IsProcessRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	return err == nil && isProcessAlive(p)
+}
+
+// IsProcessRunningSince checks whether the process of pid is running, and started before recordedAt, i.e. when
+// its pid was recorded. The process started after it is another one reusing the pid, e.g. after a reboot.
+// The start time is not checked on the systems without the proc filesystem.
+func IsProcessRunningSince(pid int, recordedAt time.Time) bool {
+	if !IsProcessRunning(pid) {
+		return false
+	}
+
+	started, err := ReadProcessStartTime(pid)
+	if err != nil {
+		return true
+	}
+	// The start time is computed from the boot time in seconds, so it's compared with a tolerance.
+	return !started.After(recordedAt.Add(startTimeTolerance))
+}
+
+// IsRecordedProcessRunning checks whether the process whose pid is recorded under pidDir is running,
+// see IsProcessRunningSince.
+func IsRecordedProcessRunning(pidDir string) bool {
+	pid, err := readPid(pidDir)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(pidDir, pidFileName))
+	if err != nil {
+		return false
+	}
+	return IsProcessRunningSince(pid, info.ModTime())
+}
+
+// TerminateProcess asks the process of pid to exit gracefully, see stopProcess.
+func TerminateProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return terminateProcess(p)
+}
+
+// stopReplicas stops all the replicas of one component.
+func stopReplicas(ctx context.Context, name string, replicas int, workingDirs WorkingDirs, logger logger.Logger) error {
+	return stopReplicaRange(ctx, name, 0, replicas, workingDirs, logger)
+}
+
+// stopReplicaRange stops the replicas of one component with the indexes in [from, to).
+func stopReplicaRange(ctx context.Context, name string, from, to int, workingDirs WorkingDirs, logger logger.Logger) error {
+	for i := from; i < to; i++ {
+		pidDir := filepath.Join(workingDirs.PidsDir, replicaDirName(name, i))
+		if err := stopProcessInTime(ctx, pidDir, workingDirs.DrainTimeout, logger); err != nil {
+			return fmt.Errorf("failed to stop '%s': %v", replicaDirName(name, i), err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestComponentBinary(t *testing.T) {
+	binary, err := componentBinary("/opt/greptime", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/greptime", binary)
+
+	patched := path.Join(t.TempDir(), "greptime")
+	assert.NoError(t, os.WriteFile(patched, nil, 0755))
+	binary, err = componentBinary("/opt/greptime", patched)
+	assert.NoError(t, err)
+	assert.Equal(t, patched, binary)
+
+	_, err = componentBinary("/opt/greptime", patched+".missing")
+	assert.Error(t, err)
+}
+
+func TestIsProcessRunning(t *testing.T) {
+	assert.True(t, IsProcessRunning(os.Getpid()))
+
+	argv := shellCommand("exit 0")
+	cmd := exec.Command(argv[0], argv[1:]...)
+	assert.NoError(t, cmd.Run())
+	assert.False(t, IsProcessRunning(cmd.Process.Pid))
+}
+
+func TestStopReplicaRangeDrainTimeout(t *testing.T) {
+	workingDirs := WorkingDirs{PidsDir: t.TempDir(), LogsDir: t.TempDir(), DrainTimeout: 300 * time.Millisecond}
+	l := logger.New(io.Discard, 0)
+
+	// The replicas ignore the graceful stop, so they are killed after the drain timeout.
+	for i := 0; i < 2; i++ {
+		name := replicaDirName("test", i)
+		option := &RunOptions{
+			Binary: "sh",
+			Name:   name,
+			pidDir: path.Join(workingDirs.PidsDir, name),
+			logDir: path.Join(workingDirs.LogsDir, name),
+			args:   []string{"-c", "trap '' TERM; while true; do sleep 0.1; done"},
+		}
+		assert.NoError(t, os.MkdirAll(option.pidDir, 0755))
+		assert.NoError(t, os.MkdirAll(option.logDir, 0755))
+		cmd, err := startBinary(option, l)
+		assert.NoError(t, err)
+		go func() { _ = cmd.Wait() }()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	assert.NoError(t, stopReplicaRange(context.Background(), "test", 0, 2, workingDirs, l))
+
+	// Each replica has its own drain timeout instead of sharing one with the others.
+	assert.GreaterOrEqual(t, time.Since(start), 2*workingDirs.DrainTimeout)
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// StandaloneComponentName is the name of standalone component, which runs
+// all the roles of GreptimeDB in a single process.
+const StandaloneComponentName = "standalone"
+
+type standalone struct {
+	config *config.Standalone
+
+	workingDirs WorkingDirs
+	wg          *sync.WaitGroup
+	logger      logger.Logger
+
+	allocatedDirs
+}
+
+func NewStandalone(config *config.Standalone, workingDirs WorkingDirs,
+	wg *sync.WaitGroup, logger logger.Logger) ClusterComponent {
+	return &standalone{
+		config:      config,
+		workingDirs: workingDirs,
+		wg:          wg,
+		logger:      logger,
+	}
+}
+
+func (s *standalone) Name() string {
+	return StandaloneComponentName
+}
+
+func (s *standalone) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+	binary, err := componentBinary(binary, s.config.BinaryPath)
+	if err != nil {
+		return err
+	}
+	if err := runHooks(ctx, HookPreStart, s.config.Hooks, s, s.config.Env, s.workingDirs, s.logger); err != nil {
+		return err
+	}
+
+	// There is always one replica of standalone.
+	dirName := replicaDirName(s.Name(), 0)
+
+	homeDir := path.Join(s.workingDirs.DataDir, dirName, dataHomeDir)
+	if err := ensureDir(s.workingDirs, homeDir); err != nil {
+		return err
+	}
+	s.dataDirs = append(s.dataDirs, path.Join(s.workingDirs.DataDir, dirName))
+
+	standaloneLogDir := path.Join(s.workingDirs.LogsDir, dirName)
+	if err := ensureDir(s.workingDirs, standaloneLogDir); err != nil {
+		return err
+	}
+	s.logsDirs = append(s.logsDirs, standaloneLogDir)
+
+	standalonePidDir := path.Join(s.workingDirs.PidsDir, dirName)
+	if err := ensureDir(s.workingDirs, standalonePidDir); err != nil {
+		return err
+	}
+	s.pidsDirs = append(s.pidsDirs, standalonePidDir)
+
+	option := &RunOptions{
+		Binary:      binary,
+		Name:        dirName,
+		logDir:      standaloneLogDir,
+		pidDir:      standalonePidDir,
+		args:        s.BuildArgs(homeDir),
+		resources:   s.config.Resources,
+		env:         s.config.Env,
+		restart:     s.config.Restart,
+		crashesDir:  s.workingDirs.CrashesDir,
+		crashDump:   s.workingDirs.CrashDump,
+		logRotation: s.workingDirs.LogRotation,
+		events:      s.workingDirs.Events,
+		dryRun:      s.workingDirs.DryRun,
+	}
+	if err := runBinary(stop, option, s.wg, s.logger); err != nil {
+		return err
+	}
+
+	if err := waitForReady(ctx, s, s.config.Readiness, s.allocatedDirs, s.workingDirs, s.logger); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, HookPostStart, s.config.Hooks, s, s.config.Env, s.workingDirs, s.logger)
+}
+
+func (s *standalone) Stop(ctx context.Context) error {
+	runPreStopHooks(ctx, s.config.Hooks, s, s.config.Env, s.workingDirs, s.logger)
+	return stopReplicas(ctx, s.Name(), 1, s.workingDirs, s.logger)
+}
+
+func (s *standalone) BuildArgs(params ...interface{}) []string {
+	logLevel := s.config.LogLevel
+	if logLevel == "" {
+		logLevel = DefaultLogLevel
+	}
+
+	homeDir := params[0].(string)
+
+	args := []string{
+		fmt.Sprintf("--log-level=%s", logLevel),
+		s.Name(), "start",
+		fmt.Sprintf("--data-home=%s", homeDir),
+	}
+	args = GenerateReplicaAddrArg("--http-addr", s.config.HTTPAddr, s.config.ReplicaAddrs, 0, args)
+	args = GenerateReplicaAddrArg("--rpc-addr", s.config.GRPCAddr, s.config.ReplicaAddrs, 0, args)
+	args = GenerateReplicaAddrArg("--mysql-addr", s.config.MysqlAddr, s.config.ReplicaAddrs, 0, args)
+	args = GenerateReplicaAddrArg("--postgres-addr", s.config.PostgresAddr, s.config.ReplicaAddrs, 0, args)
+
+	args = GenerateConfigArg(s.config.Config, nil, 0, args)
+	if len(s.config.UserProvider) > 0 {
+		args = append(args, fmt.Sprintf("--user-provider=%s", s.config.UserProvider))
+	}
+	return args
+}
+
+func (s *standalone) ListenAddrs() []ListenAddr {
+	return replicaListenAddrs(s.Name(), 0, s.config.ReplicaAddrs,
+		"--http-addr", s.config.HTTPAddr,
+		"--rpc-addr", s.config.GRPCAddr,
+		"--mysql-addr", s.config.MysqlAddr,
+		"--postgres-addr", s.config.PostgresAddr)
+}
+
+func (s *standalone) IsRunning(ctx context.Context) bool {
+	return s.healthProbe().isReplicasHealthy(ctx, 1)
+}
+
+func (s *standalone) Status(ctx context.Context) []ReplicaStatus {
+	probe := s.healthProbe()
+	return replicasStatus(s.Name(), 1, s.workingDirs, func(replica int) bool {
+		return probe.isReplicaHealthy(ctx, replica)
+	})
+}
+
+func (s *standalone) healthProbe() *healthProbe {
+	grpcAddr := s.config.GRPCAddr
+	if len(grpcAddr) == 0 {
+		grpcAddr = defaultFrontendGRPCAddr
+	}
+	return &healthProbe{
+		name:         s.Name(),
+		check:        s.config.HealthCheck,
+		httpAddr:     s.httpAddr(),
+		grpcAddr:     grpcAddr,
+		grpcArg:      "--rpc-addr",
+		replicaAddrs: s.config.ReplicaAddrs,
+		healthHost:   s.config.HealthHost,
+		logger:       s.logger,
+	}
+}
+
+// httpAddr returns the HTTP address of standalone, which is the same as frontend by default.
+func (s *standalone) httpAddr() string {
+	if len(s.config.HTTPAddr) == 0 {
+		return defaultFrontendHTTPAddr
+	}
+	return s.config.HTTPAddr
+}
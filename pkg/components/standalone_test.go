@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestStandaloneBuildArgs(t *testing.T) {
+	cfg := &config.Standalone{
+		HTTPAddr:     "0.0.0.0:4000",
+		GRPCAddr:     "0.0.0.0:4001",
+		MysqlAddr:    "0.0.0.0:4002",
+		Config:       "/tmp/standalone.toml",
+		UserProvider: "static_user_provider:file:/tmp/users",
+		ReplicaAddrs: config.ReplicaAddrs{0: {"--http-addr": "0.0.0.0:14000"}},
+	}
+	s := NewStandalone(cfg, WorkingDirs{}, nil, logger.New(io.Discard, 0))
+
+	assert.Equal(t, []string{
+		"--log-level=info",
+		"standalone", "start",
+		"--data-home=/tmp/standalone.0/home",
+		"--http-addr=0.0.0.0:14000",
+		"--rpc-addr=0.0.0.0:4001",
+		"--mysql-addr=0.0.0.0:4002",
+		"-c=/tmp/standalone.toml",
+		"--user-provider=static_user_provider:file:/tmp/users",
+	}, s.BuildArgs("/tmp/standalone.0/home"))
+
+	assert.Equal(t, []ListenAddr{
+		{Replica: "standalone.0", Arg: "--http-addr", Addr: "0.0.0.0:14000"},
+		{Replica: "standalone.0", Arg: "--rpc-addr", Addr: "0.0.0.0:4001"},
+		{Replica: "standalone.0", Arg: "--mysql-addr", Addr: "0.0.0.0:4002"},
+	}, s.ListenAddrs())
+}
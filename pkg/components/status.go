@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReplicaState is the state of one replica of cluster component.
+type ReplicaState string
+
+const (
+	// ReplicaStateRunning means the process of replica is alive and healthy.
+	ReplicaStateRunning ReplicaState = "running"
+
+	// ReplicaStateUnhealthy means the process of replica is alive but not healthy.
+	ReplicaStateUnhealthy ReplicaState = "unhealthy"
+
+	// ReplicaStateDead means the process of replica has exited unexpectedly.
+	ReplicaStateDead ReplicaState = "dead"
+
+	// ReplicaStateStopped means the replica has not been started or has been stopped on purpose.
+	ReplicaStateStopped ReplicaState = "stopped"
+)
+
+// ReplicaStatus is the status of one replica of cluster component.
+type ReplicaStatus struct {
+	// Replica is the name of replica, e.g. "frontend.0".
+	Replica string
+
+	// Pid is zero if the pid of replica is not recorded.
+	Pid   int
+	State ReplicaState
+
+	// Reason tells why the replica is not running.
+	Reason string
+
+	// StartTime is the time when the process of replica was started, which is
+	// the time the pid is recorded. It's zero if the pid of replica is not recorded.
+	StartTime time.Time
+}
+
+// replicaStatus returns the status of replica by checking the liveness of its recorded process
+// first, and then checking its health only if the process is alive.
+func replicaStatus(replica, pidDir string, healthy func() bool) ReplicaStatus {
+	status := ReplicaStatus{Replica: replica}
+
+	pid, err := readPid(pidDir)
+	if os.IsNotExist(err) {
+		// The pid file is removed when the replica is stopped on purpose.
+		status.State = ReplicaStateStopped
+		return status
+	}
+	if err != nil {
+		status.State = ReplicaStateDead
+		status.Reason = err.Error()
+		return status
+	}
+	status.Pid = pid
+	if info, err := os.Stat(filepath.Join(pidDir, pidFileName)); err == nil {
+		status.StartTime = info.ModTime()
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil || !isProcessAlive(p) {
+		status.State = ReplicaStateDead
+		status.Reason = "process has exited"
+		return status
+	}
+
+	if !healthy() {
+		status.State = ReplicaStateUnhealthy
+		status.Reason = "process is alive but health check failed"
+		return status
+	}
+
+	status.State = ReplicaStateRunning
+	return status
+}
+
+// replicasStatus returns the status of all the replicas of one component.
+func replicasStatus(name string, replicas int, workingDirs WorkingDirs, healthy func(replica int) bool) []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		replica := i
+		dirName := replicaDirName(name, i)
+		statuses = append(statuses, replicaStatus(dirName, filepath.Join(workingDirs.PidsDir, dirName),
+			func() bool { return healthy(replica) }))
+	}
+	return statuses
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaStatus(t *testing.T) {
+	pidDir := t.TempDir()
+	healthy := func() bool { return true }
+	unhealthy := func() bool { return false }
+	writePid := func(pid int) {
+		assert.NoError(t, os.WriteFile(path.Join(pidDir, pidFileName), []byte(strconv.Itoa(pid)), 0644))
+	}
+
+	status := replicaStatus("frontend.0", pidDir, healthy)
+	assert.Equal(t, ReplicaStatus{Replica: "frontend.0", State: ReplicaStateStopped}, status)
+
+	// The current process is alive.
+	writePid(os.Getpid())
+	assert.Equal(t, ReplicaStateRunning, replicaStatus("frontend.0", pidDir, healthy).State)
+	status = replicaStatus("frontend.0", pidDir, unhealthy)
+	assert.Equal(t, ReplicaStateUnhealthy, status.State)
+	assert.Equal(t, os.Getpid(), status.Pid)
+	assert.False(t, status.StartTime.IsZero())
+
+	// The exited process is dead even if the health check passes.
+	cmd := exec.Command("true")
+	assert.NoError(t, cmd.Run())
+	writePid(cmd.Process.Pid)
+	status = replicaStatus("frontend.0", pidDir, healthy)
+	assert.Equal(t, ReplicaStateDead, status.State)
+	assert.Equal(t, cmd.Process.Pid, status.Pid)
+}
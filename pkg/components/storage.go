@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// storageEnvPrefix is the prefix of environment variables that configure the storage of datanode.
+const storageEnvPrefix = "GREPTIMEDB_DATANODE__STORAGE__"
+
+// storageEnv returns the environment variables that configure the object storage of datanode.
+func storageEnv(storage *config.ObjectStorage) (map[string]string, error) {
+	if storage == nil {
+		return nil, nil
+	}
+
+	options := map[string]string{
+		"ROOT":     storage.Root,
+		"ENDPOINT": storage.Endpoint,
+	}
+
+	credentials, err := storage.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	switch storage.Type {
+	case config.ObjectStorageS3:
+		options["TYPE"] = "S3"
+		options["BUCKET"] = storage.Bucket
+		options["REGION"] = storage.Region
+		options["ACCESS_KEY_ID"] = credentials.AccessKeyID
+		options["SECRET_ACCESS_KEY"] = credentials.SecretAccessKey
+	case config.ObjectStorageOSS:
+		options["TYPE"] = "Oss"
+		options["BUCKET"] = storage.Bucket
+		options["ACCESS_KEY_ID"] = credentials.AccessKeyID
+		options["ACCESS_KEY_SECRET"] = credentials.SecretAccessKey
+	case config.ObjectStorageGCS:
+		options["TYPE"] = "Gcs"
+		options["BUCKET"] = storage.Bucket
+		options["CREDENTIAL_PATH"] = storage.CredentialsFile
+	case config.ObjectStorageAzblob:
+		options["TYPE"] = "Azblob"
+		options["CONTAINER"] = storage.Bucket
+		options["ACCOUNT_NAME"] = credentials.AccountName
+		options["ACCOUNT_KEY"] = credentials.AccountKey
+		options["SAS_TOKEN"] = credentials.SASToken
+	default:
+		return nil, fmt.Errorf("unknown storage type '%s'", storage.Type)
+	}
+
+	env := make(map[string]string)
+	for k, v := range options {
+		// Leave the unspecified options to the defaults of datanode.
+		if len(v) > 0 {
+			env[storageEnvPrefix+k] = v
+		}
+	}
+	return env, nil
+}
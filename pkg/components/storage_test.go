@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestStorageEnv(t *testing.T) {
+	credentialsFile := path.Join(t.TempDir(), "credentials.yaml")
+	err := os.WriteFile(credentialsFile, []byte("accessKeyID: id\nsecretAccessKey: secret\n"), 0600)
+	assert.NoError(t, err)
+
+	env, err := storageEnv(&config.ObjectStorage{
+		Type:            config.ObjectStorageS3,
+		Bucket:          "greptimedb",
+		Root:            "mycluster",
+		Region:          "us-west-2",
+		CredentialsFile: credentialsFile,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_DATANODE__STORAGE__TYPE":              "S3",
+		"GREPTIMEDB_DATANODE__STORAGE__BUCKET":            "greptimedb",
+		"GREPTIMEDB_DATANODE__STORAGE__ROOT":              "mycluster",
+		"GREPTIMEDB_DATANODE__STORAGE__REGION":            "us-west-2",
+		"GREPTIMEDB_DATANODE__STORAGE__ACCESS_KEY_ID":     "id",
+		"GREPTIMEDB_DATANODE__STORAGE__SECRET_ACCESS_KEY": "secret",
+	}, env)
+
+	env, err = storageEnv(&config.ObjectStorage{
+		Type:            config.ObjectStorageGCS,
+		Bucket:          "greptimedb",
+		Endpoint:        "https://storage.googleapis.com",
+		CredentialsFile: "/path/to/service-account.json",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_DATANODE__STORAGE__TYPE":            "Gcs",
+		"GREPTIMEDB_DATANODE__STORAGE__BUCKET":          "greptimedb",
+		"GREPTIMEDB_DATANODE__STORAGE__ENDPOINT":        "https://storage.googleapis.com",
+		"GREPTIMEDB_DATANODE__STORAGE__CREDENTIAL_PATH": "/path/to/service-account.json",
+	}, env)
+
+	env, err = storageEnv(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, env)
+
+	_, err = storageEnv(&config.ObjectStorage{
+		Type:            config.ObjectStorageOSS,
+		Bucket:          "greptimedb",
+		CredentialsFile: path.Join(t.TempDir(), "not-exist.yaml"),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	DefaultRestartInitialBackoff = 1 * time.Second
+	DefaultRestartMaxBackoff     = 1 * time.Minute
+
+	// RestartsFileName is the name of file under the pid dir of replica that records its restart count.
+	RestartsFileName = "restarts"
+)
+
+// supervisor watches the process of one replica, and restarts it according to the restart policy.
+type supervisor struct {
+	option *RunOptions
+	policy config.Restart
+	stop   context.CancelFunc
+	logger logger.Logger
+
+	restarts int
+	backoff  time.Duration
+}
+
+func newSupervisor(option *RunOptions, stop context.CancelFunc, logger logger.Logger) *supervisor {
+	policy := config.Restart{Policy: config.RestartPolicyNever}
+	if option.restart != nil {
+		policy = *option.restart
+	}
+
+	if len(policy.Policy) == 0 {
+		policy.Policy = config.RestartPolicyNever
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = DefaultRestartInitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = DefaultRestartMaxBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+
+	return &supervisor{
+		option:  option,
+		policy:  policy,
+		stop:    stop,
+		logger:  logger,
+		backoff: policy.InitialBackoff,
+	}
+}
+
+// supervise waits for the process to exit and restarts it if needed, until
+// the process is stopped on purpose or it's not allowed to be restarted.
+func (s *supervisor) supervise(cmd *exec.Cmd) {
+	for {
+		pid := cmd.Process.Pid
+		startedAt := time.Now()
+		err := cmd.Wait()
+		closeOutput(cmd)
+
+		if s.stoppedOnPurpose(pid) {
+			return
+		}
+
+		if err != nil {
+			s.recordCrash(pid, err)
+			s.option.events.Publish(&events.Event{
+				Type:      events.TypeComponentCrashed,
+				Component: s.option.Name,
+				Message:   fmt.Sprintf("pid '%d' exited with: %v", pid, exitReason(err)),
+			})
+		}
+
+		if !s.shouldRestart(err) {
+			s.exited(pid, err)
+			return
+		}
+
+		// Reset the backoff if the process has been running stably for a while.
+		if time.Since(startedAt) > s.policy.MaxBackoff {
+			s.backoff = s.policy.InitialBackoff
+		}
+
+		s.restarts++
+		s.logger.Warnf("component '%s' (pid '%d') exited with: %v, restarting it in %s (restarts: %d)",
+			s.option.Name, pid, exitReason(err), s.backoff, s.restarts)
+		time.Sleep(s.backoff)
+
+		s.backoff *= 2
+		if s.backoff > s.policy.MaxBackoff {
+			s.backoff = s.policy.MaxBackoff
+		}
+
+		// The replica may be stopped during the backoff.
+		if s.stoppedOnPurpose(pid) {
+			return
+		}
+
+		cmd, err = startBinary(s.option, s.logger)
+		if err != nil {
+			s.logger.Errorf("failed to restart component '%s': %v", s.option.Name, err)
+			s.stop()
+			return
+		}
+		if err = s.recordRestarts(); err != nil {
+			s.logger.Warnf("failed to record restarts of component '%s': %v", s.option.Name, err)
+		}
+		s.logger.V(0).Infof("component '%s' is restarted with pid '%d'", s.option.Name, cmd.Process.Pid)
+		s.option.events.Publish(&events.Event{
+			Type:       events.TypeComponentStarted,
+			Component:  s.option.Name,
+			Message:    fmt.Sprintf("restarted with pid '%d'", cmd.Process.Pid),
+			Attributes: map[string]string{"restarts": strconv.Itoa(s.restarts)},
+		})
+	}
+}
+
+// stoppedOnPurpose checks whether the process is stopped on purpose, in which case
+// its pid has been removed from the pid file by stopProcess.
+func (s *supervisor) stoppedOnPurpose(pid int) bool {
+	recorded, err := readPid(s.option.pidDir)
+	return err != nil || recorded != pid
+}
+
+// shouldRestart checks whether the exited process should be restarted by the policy.
+func (s *supervisor) shouldRestart(err error) bool {
+	if s.policy.MaxRestarts > 0 && s.restarts >= s.policy.MaxRestarts {
+		if s.policy.Policy != config.RestartPolicyNever {
+			s.logger.Warnf("component '%s' has reached the max restarts %d", s.option.Name, s.policy.MaxRestarts)
+		}
+		return false
+	}
+
+	switch s.policy.Policy {
+	case config.RestartPolicyAlways:
+		return true
+	case config.RestartPolicyOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// exited handles the exit of process which will not be restarted.
+func (s *supervisor) exited(pid int, err error) {
+	if err == nil {
+		return
+	}
+	s.logger.Errorf("component '%s' binary '%s' (pid '%d') exited with error: %v", s.option.Name, s.option.Binary, pid, err)
+	s.logger.Errorf("args: '%v'", RedactArgs(s.option.args))
+
+	// If one component has failed, stop the whole context.
+	s.stop()
+}
+
+// recordCrash records the crash of process under the crashes dir, nothing is recorded if it's not set.
+func (s *supervisor) recordCrash(pid int, err error) {
+	if len(s.option.crashesDir) == 0 {
+		return
+	}
+
+	record, err := recordCrash(s.option, pid, exitReason(err))
+	if err != nil {
+		s.logger.Warnf("failed to record the crash of component '%s': %v", s.option.Name, err)
+		return
+	}
+	s.logger.Warnf("component '%s' (pid '%d') crashed, the crash is recorded in '%s'",
+		s.option.Name, pid, filepath.Join(s.option.crashesDir, record.Dir))
+}
+
+// recordRestarts records the restart count under the pid dir, so it can be reported by the cluster status.
+func (s *supervisor) recordRestarts() error {
+	return os.WriteFile(filepath.Join(s.option.pidDir, RestartsFileName), []byte(strconv.Itoa(s.restarts)), 0644)
+}
+
+// exitReason returns the readable reason of process exit.
+func exitReason(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
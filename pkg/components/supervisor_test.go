@@ -0,0 +1,137 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func newTestRunOptions(t *testing.T, script string, restart *config.Restart) *RunOptions {
+	return &RunOptions{
+		Binary:  "sh",
+		Name:    "test.0",
+		pidDir:  t.TempDir(),
+		logDir:  t.TempDir(),
+		args:    []string{"-c", script},
+		restart: restart,
+	}
+}
+
+func TestSupervisorRestartOnFailure(t *testing.T) {
+	option := newTestRunOptions(t, "exit 1", &config.Restart{
+		Policy:         config.RestartPolicyOnFailure,
+		MaxRestarts:    2,
+		InitialBackoff: 10 * time.Millisecond,
+	})
+
+	var wg sync.WaitGroup
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	err := runBinary(stop, option, &wg, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	wg.Wait()
+
+	// The whole context is stopped after reaching the max restarts.
+	assert.Error(t, ctx.Err())
+
+	restarts, err := os.ReadFile(path.Join(option.pidDir, RestartsFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(restarts))
+}
+
+func TestSupervisorRecordCrash(t *testing.T) {
+	option := newTestRunOptions(t, "echo boom; exit 3", nil)
+	option.crashesDir = t.TempDir()
+
+	var wg sync.WaitGroup
+	_, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	err := runBinary(stop, option, &wg, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	wg.Wait()
+
+	crashes, err := ReadCrashes(option.crashesDir)
+	assert.NoError(t, err)
+	assert.Len(t, crashes, 1)
+	assert.Equal(t, "test.0", crashes[0].Replica)
+	assert.Equal(t, "exit status 3", crashes[0].Reason)
+
+	log, err := os.ReadFile(path.Join(option.crashesDir, crashes[0].Dir, CrashLogFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "boom\n", string(log))
+}
+
+func TestSupervisorStopOnPurpose(t *testing.T) {
+	option := newTestRunOptions(t, "sleep 10", &config.Restart{
+		Policy:         config.RestartPolicyAlways,
+		InitialBackoff: 10 * time.Millisecond,
+	})
+
+	var wg sync.WaitGroup
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	l := logger.New(io.Discard, 0)
+	err := runBinary(stop, option, &wg, l)
+	assert.NoError(t, err)
+
+	err = stopProcess(context.Background(), option.pidDir, l)
+	assert.NoError(t, err)
+	wg.Wait()
+
+	// The replica stopped on purpose is neither restarted nor treated as failure.
+	assert.NoError(t, ctx.Err())
+
+	restarts, err := os.ReadFile(path.Join(option.pidDir, RestartsFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "0", string(restarts))
+}
+
+func TestSupervisorRecordKilled(t *testing.T) {
+	// The replica killed by others, e.g. the OOM killer, is a crash rather than being stopped on purpose.
+	option := newTestRunOptions(t, "kill -9 $$", nil)
+	option.crashesDir = t.TempDir()
+
+	var wg sync.WaitGroup
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	err := runBinary(stop, option, &wg, logger.New(io.Discard, 0))
+	assert.NoError(t, err)
+	wg.Wait()
+
+	crashes, err := ReadCrashes(option.crashesDir)
+	assert.NoError(t, err)
+	assert.Len(t, crashes, 1)
+	assert.Equal(t, "signal: killed", crashes[0].Reason)
+
+	// The whole context is stopped since the replica is not restarted.
+	assert.Error(t, ctx.Err())
+}
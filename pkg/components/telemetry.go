@@ -0,0 +1,26 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+// DisableTelemetryEnv returns the env that opts out of the telemetry of greptime,
+// which is reported by the metasrv in cluster mode and by the standalone.
+func DisableTelemetryEnv() map[string]string {
+	return map[string]string{
+		"GREPTIMEDB_METASRV__ENABLE_TELEMETRY":    "false",
+		"GREPTIMEDB_STANDALONE__ENABLE_TELEMETRY": "false",
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/certs"
+)
+
+// certsDirName is the name of directory under the data directory of cluster
+// that keeps the auto-generated certificates.
+const certsDirName = "certs"
+
+// CertsDir returns the directory of the auto-generated certificates of cluster.
+func CertsDir(workingDirs WorkingDirs) string {
+	return path.Join(workingDirs.DataDir, certsDirName)
+}
+
+// TLSMode returns the TLS mode of servers, it's empty if the TLS is not enabled.
+func TLSMode(tls *config.ServerTLS) string {
+	if tls == nil || tls.Mode == config.TLSModeDisable {
+		return ""
+	}
+	if len(tls.Mode) == 0 {
+		return config.TLSModeRequire
+	}
+	return tls.Mode
+}
+
+// serverTLSPaths returns the paths of certificates that the servers use. The self-signed
+// certificates are generated for the hosts if they are auto-generated and don't exist.
+func serverTLSPaths(tls *config.ServerTLS, workingDirs WorkingDirs, hosts []string, logger logger.Logger) (*certs.Paths, error) {
+	if len(TLSMode(tls)) == 0 {
+		return nil, nil
+	}
+	if !tls.AutoGenerate {
+		return &certs.Paths{CACert: tls.CACertPath, ServerCert: tls.CertPath, ServerKey: tls.KeyPath}, nil
+	}
+
+	dir := CertsDir(workingDirs)
+	exists, err := certs.Exists(dir)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return certs.PathsIn(dir), nil
+	}
+
+	if dryRun := workingDirs.DryRun; dryRun != nil {
+		paths := certs.PathsIn(dir)
+		dryRun.AddDir(dir)
+		for _, file := range []string{paths.CACert, paths.ServerCert, paths.ServerKey} {
+			dryRun.AddFile(file, fmt.Sprintf("<generated for the hosts '%s'>", strings.Join(hosts, ",")))
+		}
+		return paths, nil
+	}
+
+	logger.V(3).Infof("Generating self-signed certificates for '%s' in %s", strings.Join(hosts, ","), dir)
+	paths, err := certs.Generate(dir, hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate self-signed certificates: %v", err)
+	}
+	return paths, nil
+}
+
+// CertHosts returns the hosts that the certificates of servers are issued for,
+// which include the local hosts and the specified hosts of the listen addresses.
+func CertHosts(addrs []ListenAddr) []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname)
+	}
+
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		seen[host] = true
+	}
+	for _, addr := range addrs {
+		host, _, err := net.SplitHostPort(addr.Addr)
+		if err != nil || isUnspecifiedHost(host) || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// tlsArgs returns the args that enable the TLS of MySQL and Postgres servers. There are no such args
+// for the HTTP server, which always serves plain HTTP, see config.Frontend.TLS.
+func tlsArgs(mode string, paths *certs.Paths) []string {
+	if paths == nil {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("--tls-mode=%s", mode),
+		fmt.Sprintf("--tls-cert-path=%s", paths.ServerCert),
+		fmt.Sprintf("--tls-key-path=%s", paths.ServerKey),
+	}
+}
+
+// grpcTLSEnv returns the environment variables that enable the TLS of gRPC server.
+func grpcTLSEnv(component, mode string, paths *certs.Paths) map[string]string {
+	if paths == nil {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("GREPTIMEDB_%s__GRPC__TLS__", strings.ToUpper(component))
+	return map[string]string{
+		prefix + "MODE":      mode,
+		prefix + "CERT_PATH": paths.ServerCert,
+		prefix + "KEY_PATH":  paths.ServerKey,
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/certs"
+)
+
+func TestTLSMode(t *testing.T) {
+	assert.Empty(t, TLSMode(nil))
+	assert.Empty(t, TLSMode(&config.ServerTLS{Mode: config.TLSModeDisable}))
+	assert.Equal(t, config.TLSModeRequire, TLSMode(&config.ServerTLS{}))
+	assert.Equal(t, config.TLSModeVerifyFull, TLSMode(&config.ServerTLS{Mode: config.TLSModeVerifyFull}))
+}
+
+func TestServerTLSPaths(t *testing.T) {
+	l := logger.New(io.Discard, 0)
+	workingDirs := WorkingDirs{DataDir: t.TempDir()}
+
+	paths, err := serverTLSPaths(nil, workingDirs, nil, l)
+	assert.NoError(t, err)
+	assert.Nil(t, paths)
+
+	paths, err = serverTLSPaths(&config.ServerTLS{CertPath: "/etc/tls/server.crt", KeyPath: "/etc/tls/server.key"},
+		workingDirs, nil, l)
+	assert.NoError(t, err)
+	assert.Equal(t, &certs.Paths{ServerCert: "/etc/tls/server.crt", ServerKey: "/etc/tls/server.key"}, paths)
+
+	// The auto-generated certificates are reused.
+	paths, err = serverTLSPaths(&config.ServerTLS{AutoGenerate: true}, workingDirs, []string{"localhost"}, l)
+	assert.NoError(t, err)
+	assert.Equal(t, certs.PathsIn(CertsDir(workingDirs)), paths)
+	exists, err := certs.Exists(CertsDir(workingDirs))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.Equal(t, []string{
+		"--tls-mode=require",
+		"--tls-cert-path=" + paths.ServerCert,
+		"--tls-key-path=" + paths.ServerKey,
+	}, tlsArgs(config.TLSModeRequire, paths))
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_FRONTEND__GRPC__TLS__MODE":      "require",
+		"GREPTIMEDB_FRONTEND__GRPC__TLS__CERT_PATH": paths.ServerCert,
+		"GREPTIMEDB_FRONTEND__GRPC__TLS__KEY_PATH":  paths.ServerKey,
+	}, grpcTLSEnv("frontend", config.TLSModeRequire, paths))
+}
+
+func TestCertHosts(t *testing.T) {
+	hosts := CertHosts([]ListenAddr{
+		{Addr: "0.0.0.0:4000"},
+		{Addr: "192.168.1.10:4001"},
+		{Addr: "192.168.1.10:4002"},
+		{Addr: "127.0.0.1:4003"},
+	})
+	assert.Equal(t, []string{"localhost", "127.0.0.1"}, hosts[:2])
+	assert.Equal(t, "192.168.1.10", hosts[len(hosts)-1])
+	assert.NotContains(t, hosts, "0.0.0.0")
+}
@@ -18,6 +18,10 @@ package components
 
 import (
 	"context"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/events"
 )
 
 const (
@@ -29,6 +33,26 @@ type WorkingDirs struct {
 	DataDir string `yaml:"dataDir"`
 	LogsDir string `yaml:"logsDir"`
 	PidsDir string `yaml:"pidsDir"`
+
+	// CrashesDir is where the crashes of replicas are recorded, they are not recorded if it's empty.
+	CrashesDir string `yaml:"crashesDir,omitempty"`
+
+	// CrashDump configures what is recorded for each crash, see config.CrashDump.
+	CrashDump *config.CrashDump `yaml:"-"`
+
+	// LogRotation rotates the log files of replicas through gtctl, they are not rotated if it's nil.
+	// It should only be set when the replicas are stopped as gtctl exits, see RunOptions.
+	LogRotation *config.LogRotation `yaml:"-"`
+
+	// DrainTimeout is the timeout of waiting for each replica to exit gracefully before it's killed,
+	// the replicas are only killed when the ctx of stopping them is done if it's zero.
+	DrainTimeout time.Duration `yaml:"-"`
+
+	// Events publishes the starts and crashes of replicas, nothing is published if it's nil.
+	Events *events.Bus `yaml:"-"`
+
+	// DryRun records what the components would do instead of doing it, if it's set.
+	DryRun *DryRun `yaml:"-"`
 }
 
 // allocatedDirs include all the directories that created during bare-metal mode.
@@ -43,12 +67,34 @@ type ClusterComponent interface {
 	// Start starts cluster component by executing binary.
 	Start(ctx context.Context, stop context.CancelFunc, binary string) error
 
+	// Stop stops all the running replicas of cluster component gracefully. The replicas that do not
+	// exit in the drain timeout of working dirs, or before the ctx is done, will be killed.
+	Stop(ctx context.Context) error
+
 	// BuildArgs build up args for cluster component.
 	BuildArgs(params ...interface{}) []string
 
+	// ListenAddrs returns all the addresses that the replicas of cluster component listen on.
+	ListenAddrs() []ListenAddr
+
 	// IsRunning returns the status of current cluster component.
 	IsRunning(ctx context.Context) bool
 
+	// Status returns the status of each replica of cluster component, which tells
+	// whether the process of replica is dead or alive but unhealthy.
+	Status(ctx context.Context) []ReplicaStatus
+
 	// Name return the name of component.
 	Name() string
 }
+
+// ScalableComponent is the cluster component whose replicas can be started and stopped separately.
+type ScalableComponent interface {
+	ClusterComponent
+
+	// StartReplicas starts the replicas with the indexes in [from, to), and waits for all the replicas to be ready.
+	StartReplicas(ctx context.Context, stop context.CancelFunc, binary string, from, to int) error
+
+	// StopReplicas stops the replicas with the indexes in [from, to) gracefully.
+	StopReplicas(ctx context.Context, from, to int) error
+}
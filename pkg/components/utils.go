@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
 )
 
 // FormatAddrArg formats the given addr and nodeId to a valid socket string.
@@ -48,3 +50,57 @@ func GenerateAddrArg(config string, addr string, nodeId int, args []string) []st
 
 	return append(args, fmt.Sprintf("%s=%s", config, socketAddr))
 }
+
+// GenerateConfigArg pushes the config file arg of the replica into args array, return the new args array.
+// The config file specified for the replica in configs takes precedence over the common config file.
+func GenerateConfigArg(config string, configs map[int]string, replica int, args []string) []string {
+	if replicaConfig, ok := configs[replica]; ok && len(replicaConfig) > 0 {
+		config = replicaConfig
+	}
+
+	// don't generate param if no config file is specified
+	if len(config) == 0 {
+		return args
+	}
+
+	return append(args, fmt.Sprintf("-c=%s", config))
+}
+
+// ReplicaAddr returns the address of the replica for the arg. The address recorded in replicaAddrs
+// takes precedence over the one that offset from the base address by the index of replica.
+func ReplicaAddr(replicaAddrs config.ReplicaAddrs, arg, base string, replica int) string {
+	if addr, ok := replicaAddrs[replica][arg]; ok && len(addr) > 0 {
+		return addr
+	}
+	return FormatAddrArg(base, replica)
+}
+
+// GenerateReplicaAddrArg pushes the address arg of the replica into args array, return the new args array.
+func GenerateReplicaAddrArg(arg, base string, replicaAddrs config.ReplicaAddrs, replica int, args []string) []string {
+	addr := ReplicaAddr(replicaAddrs, arg, base, replica)
+
+	// don't generate param if the socket address is empty
+	if len(addr) == 0 {
+		return args
+	}
+
+	return append(args, fmt.Sprintf("%s=%s", arg, addr))
+}
+
+// HealthCheckAddr returns the address for checking the health of the replica that serves HTTP on httpAddr.
+// The host of httpAddr is used by default, and the unspecified host like "0.0.0.0" falls back to "localhost".
+// The healthHost overrides the host of httpAddr if it's not empty.
+func HealthCheckAddr(httpAddr, healthHost string) (string, error) {
+	host, port, err := net.SplitHostPort(httpAddr)
+	if err != nil {
+		return "", err
+	}
+
+	if len(healthHost) > 0 {
+		host = healthHost
+	} else if isUnspecifiedHost(host) {
+		host = "localhost"
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
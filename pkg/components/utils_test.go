@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestHealthCheckAddr(t *testing.T) {
+	testCases := []struct {
+		name       string
+		httpAddr   string
+		healthHost string
+		expect     string
+	}{
+		{
+			name:     "unspecified ipv4 host",
+			httpAddr: "0.0.0.0:4001",
+			expect:   "localhost:4001",
+		},
+		{
+			name:     "unspecified ipv6 host",
+			httpAddr: "[::]:4000",
+			expect:   "localhost:4000",
+		},
+		{
+			name:     "empty host",
+			httpAddr: ":4000",
+			expect:   "localhost:4000",
+		},
+		{
+			name:     "specified host",
+			httpAddr: "192.168.1.10:14003",
+			expect:   "192.168.1.10:14003",
+		},
+		{
+			name:       "override host",
+			httpAddr:   "0.0.0.0:14001",
+			healthHost: "192.168.1.10",
+			expect:     "192.168.1.10:14001",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := HealthCheckAddr(tc.httpAddr, tc.healthHost)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, actual)
+		})
+	}
+
+	_, err := HealthCheckAddr("", "")
+	assert.Error(t, err)
+}
+
+func TestReplicaAddr(t *testing.T) {
+	replicaAddrs := config.ReplicaAddrs{
+		1: {"--http-addr": "127.0.0.1:38201"},
+	}
+
+	assert.Equal(t, "0.0.0.0:4000", ReplicaAddr(replicaAddrs, "--http-addr", "0.0.0.0:4000", 0))
+	assert.Equal(t, "127.0.0.1:38201", ReplicaAddr(replicaAddrs, "--http-addr", "0.0.0.0:4000", 1))
+	assert.Equal(t, "0.0.0.0:4002", ReplicaAddr(replicaAddrs, "--rpc-addr", "0.0.0.0:4001", 1))
+	assert.Equal(t, "", ReplicaAddr(nil, "--mysql-addr", "", 1))
+
+	assert.Equal(t, []string{"start", "--http-addr=127.0.0.1:38201"},
+		GenerateReplicaAddrArg("--http-addr", "0.0.0.0:4000", replicaAddrs, 1, []string{"start"}))
+	assert.Equal(t, []string{"start"},
+		GenerateReplicaAddrArg("--mysql-addr", "", replicaAddrs, 1, []string{"start"}))
+}
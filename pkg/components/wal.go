@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// WALEnv returns the environment variables that configure the WAL of the component,
+// only metasrv and datanode need to be configured.
+func WALEnv(wal *config.WAL, component string) map[string]string {
+	if wal == nil || len(wal.Provider) == 0 {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("GREPTIMEDB_%s__WAL__", strings.ToUpper(component))
+	env := map[string]string{
+		prefix + "PROVIDER": wal.Provider,
+	}
+	if wal.Provider != config.WALProviderKafka || wal.Kafka == nil {
+		return env
+	}
+
+	endpoints := wal.Kafka.BrokerEndpoints
+	if wal.Kafka.Embedded != nil {
+		endpoints = []string{EmbeddedKafkaAddr(wal.Kafka.Embedded)}
+	}
+	env[prefix+"BROKER_ENDPOINTS"] = strings.Join(endpoints, ",")
+
+	// The topics are managed by metasrv.
+	if component == MetaSrvComponentName {
+		if len(wal.Kafka.TopicNamePrefix) > 0 {
+			env[prefix+"TOPIC_NAME_PREFIX"] = wal.Kafka.TopicNamePrefix
+		}
+		if wal.Kafka.ReplicationFactor > 0 {
+			env[prefix+"REPLICATION_FACTOR"] = strconv.Itoa(wal.Kafka.ReplicationFactor)
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestWALEnv(t *testing.T) {
+	assert.Empty(t, WALEnv(nil, MetaSrvComponentName))
+
+	wal := &config.WAL{
+		Provider: config.WALProviderKafka,
+		Kafka: &config.KafkaWAL{
+			BrokerEndpoints:   []string{"kafka-0:9092", "kafka-1:9092"},
+			TopicNamePrefix:   "greptimedb_wal",
+			ReplicationFactor: 2,
+		},
+	}
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_METASRV__WAL__PROVIDER":           "kafka",
+		"GREPTIMEDB_METASRV__WAL__BROKER_ENDPOINTS":   "kafka-0:9092,kafka-1:9092",
+		"GREPTIMEDB_METASRV__WAL__TOPIC_NAME_PREFIX":  "greptimedb_wal",
+		"GREPTIMEDB_METASRV__WAL__REPLICATION_FACTOR": "2",
+	}, WALEnv(wal, MetaSrvComponentName))
+	assert.Equal(t, map[string]string{
+		"GREPTIMEDB_DATANODE__WAL__PROVIDER":         "kafka",
+		"GREPTIMEDB_DATANODE__WAL__BROKER_ENDPOINTS": "kafka-0:9092,kafka-1:9092",
+	}, WALEnv(wal, "datanode"))
+
+	wal.Kafka.Embedded = &config.EmbeddedKafka{Home: "/opt/kafka"}
+	assert.Equal(t, DefaultEmbeddedKafkaAddr, WALEnv(wal, "datanode")["GREPTIMEDB_DATANODE__WAL__BROKER_ENDPOINTS"])
+}
+
+func TestKafkaProperties(t *testing.T) {
+	k := NewKafka(&config.EmbeddedKafka{Home: "/opt/kafka", Addr: "0.0.0.0:19092"},
+		WorkingDirs{}, nil, logger.New(io.Discard, 0)).(*kafka)
+
+	dataDir := t.TempDir()
+	propertiesFile, err := k.writeProperties(dataDir)
+	assert.NoError(t, err)
+
+	properties, err := os.ReadFile(propertiesFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(properties), "controller.quorum.voters=1@localhost:19093\n")
+	assert.Contains(t, string(properties), "listeners=PLAINTEXT://0.0.0.0:19092,CONTROLLER://0.0.0.0:19093\n")
+	assert.Contains(t, string(properties), "advertised.listeners=PLAINTEXT://localhost:19092\n")
+	assert.Contains(t, string(properties), "log.dirs="+path.Join(dataDir, kafkaLogsDir)+"\n")
+}
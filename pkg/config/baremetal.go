@@ -17,17 +17,99 @@
 package config
 
 import (
+	"fmt"
+	"os"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 )
 
 // BareMetalClusterMetadata stores metadata of a GreptimeDB cluster.
 type BareMetalClusterMetadata struct {
-	Config        *BareMetalClusterConfig `yaml:"config"`
-	CreationDate  time.Time               `yaml:"creationDate"`
-	ClusterDir    string                  `yaml:"clusterDir"`
-	ForegroundPid int                     `yaml:"foregroundPid"`
+	Config       *BareMetalClusterConfig `yaml:"config"`
+	CreationDate time.Time               `yaml:"creationDate"`
+	ClusterDir   string                  `yaml:"clusterDir"`
+
+	// ForegroundPid is the pid of gtctl process that runs the cluster in foreground,
+	// it's zero if the cluster is detached from gtctl.
+	ForegroundPid int `yaml:"foregroundPid"`
+
+	// Detached means the components keep on running after the gtctl process exits,
+	// they are stopped by 'gtctl cluster stop' instead.
+	Detached bool `yaml:"detached,omitempty"`
+
+	// UseMemoryMeta means the metasrv uses the memory storage instead of etcd.
+	UseMemoryMeta bool `yaml:"useMemoryMeta,omitempty"`
+
+	// Checkpoint is the progress of creating the cluster, it's nil once the cluster is created.
+	Checkpoint *CreateCheckpoint `yaml:"checkpoint,omitempty"`
+}
+
+// CreateCheckpoint records the components that have been started in creating the cluster,
+// so the failed creation can be resumed from the failed component.
+type CreateCheckpoint struct {
+	// Completed are the components that have been started and are ready, in the order of starting.
+	Completed []string `yaml:"completed,omitempty"`
+
+	// Failed is the component that failed to start, and Error is why it failed.
+	Failed string `yaml:"failed,omitempty"`
+	Error  string `yaml:"error,omitempty"`
+}
+
+// IsCompleted returns true if the component has been started.
+func (c *CreateCheckpoint) IsCompleted(name string) bool {
+	for _, completed := range c.Completed {
+		if completed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// BareMetalClusterState is the runtime state of a GreptimeDB cluster on bare metal. It's recorded
+// in the cluster dir whenever the cluster is created, restarted, scaled or stopped, so it survives
+// the restarts of gtctl and can be consumed by the other tools.
+type BareMetalClusterState struct {
+	Name          string    `yaml:"name"`
+	ClusterDir    string    `yaml:"clusterDir"`
+	DataDir       string    `yaml:"dataDir"`
+	LogsDir       string    `yaml:"logsDir"`
+	PidsDir       string    `yaml:"pidsDir"`
+	ForegroundPid int       `yaml:"foregroundPid"`
+	StartTime     time.Time `yaml:"startTime"`
+	UpdateTime    time.Time `yaml:"updateTime"`
+
+	// Artifacts are the greptime and etcd artifacts that the cluster runs with, keyed by binary name.
+	Artifacts map[string]*Artifact `yaml:"artifacts"`
+
+	// Components are in the order of starting.
+	Components []*ComponentState `yaml:"components"`
+}
+
+// ComponentState is the runtime state of one component of cluster.
+type ComponentState struct {
+	Name string `yaml:"name"`
+
+	// Binary is the binary that overrides the greptime artifact for the component.
+	Binary string `yaml:"binary,omitempty"`
+
+	Replicas []*ReplicaState `yaml:"replicas"`
+}
+
+// ReplicaState is the runtime state of one replica of component when the cluster state is recorded.
+type ReplicaState struct {
+	Name  string `yaml:"name"`
+	Pid   int    `yaml:"pid,omitempty"`
+	State string `yaml:"state"`
+
+	// Addrs are the listen addresses of replica keyed by args, e.g. "--http-addr".
+	Addrs map[string]string `yaml:"addrs,omitempty"`
+
+	DataDir string `yaml:"dataDir,omitempty"`
+	LogDir  string `yaml:"logDir"`
+	PidDir  string `yaml:"pidDir"`
 }
 
 // BareMetalClusterConfig is the desired state of a GreptimeDB cluster on bare metal.
@@ -39,6 +121,45 @@ type BareMetalClusterMetadata struct {
 type BareMetalClusterConfig struct {
 	Cluster *BareMetalClusterComponentsConfig `yaml:"cluster" validate:"required"`
 	Etcd    *Etcd                             `yaml:"etcd" validate:"required"`
+
+	// Hosts is the inventory of hosts that the components are deployed on over SSH. The cluster is deployed
+	// on the local host if it's empty, otherwise the replicas are placed on the hosts by the Hosts of components.
+	Hosts []*Host `yaml:"hosts,omitempty" validate:"omitempty,dive,required"`
+}
+
+// DefaultHostWorkingDir is the working directory of gtctl on the host, which is relative to the home
+// directory of the SSH user.
+const DefaultHostWorkingDir = ".gtctl"
+
+// Host is the host that the components are deployed on over SSH in multi-host mode.
+type Host struct {
+	// Name is used to place the replicas of components on the host.
+	Name string `yaml:"name" validate:"required,hostname_rfc1123"`
+
+	// Address is the address that gtctl connects to over SSH, the components on the host listen on it
+	// instead of the loopback and unspecified addresses, so it must be reachable by the other hosts.
+	Address string `yaml:"address" validate:"required,hostname|ip"`
+
+	// Port is the port of SSH, the default port of SSH client is used if it's zero.
+	Port int `yaml:"port,omitempty" validate:"gte=0,lt=65536"`
+
+	// User is the user of SSH, the default user of SSH client is used if it's empty.
+	User string `yaml:"user,omitempty"`
+
+	// IdentityFile is the private key of SSH, the default keys and the SSH agent are used if it's empty.
+	IdentityFile string `yaml:"identityFile,omitempty" validate:"omitempty,filepath"`
+
+	// WorkingDir is where the binaries, data, logs and pids of clusters are placed on the host,
+	// it's DefaultHostWorkingDir if it's empty.
+	WorkingDir string `yaml:"workingDir,omitempty"`
+}
+
+// HostWorkingDir returns the working directory of gtctl on the host.
+func (h *Host) HostWorkingDir() string {
+	if len(h.WorkingDir) == 0 {
+		return DefaultHostWorkingDir
+	}
+	return h.WorkingDir
 }
 
 type BareMetalClusterComponentsConfig struct {
@@ -46,8 +167,115 @@ type BareMetalClusterComponentsConfig struct {
 	Frontend *Frontend `yaml:"frontend" validate:"required"`
 	MetaSrv  *MetaSrv  `yaml:"meta" validate:"required"`
 	Datanode *Datanode `yaml:"datanode" validate:"required"`
+
+	// Flownode is optional, the flownode component will not be deployed if it is not specified.
+	Flownode *Flownode `yaml:"flownode,omitempty"`
+
+	// Standalone is optional, the cluster runs a single standalone process instead of
+	// the distributed components if it's specified.
+	Standalone *Standalone `yaml:"standalone,omitempty"`
+
+	// DatanodeGroups are deployed besides the Datanode, each group has its own storage and configs.
+	DatanodeGroups []*DatanodeGroup `yaml:"datanodeGroups,omitempty" validate:"omitempty,dive,required"`
+
+	// Env is the environment variables for all the components, which can be
+	// overridden by the Env of each component.
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// AutoPortAllocation allocates free ports for the listen addresses of each replica,
+	// instead of offsetting the configured port by the index of replica. The allocated
+	// addresses are recorded in the ReplicaAddrs of each component.
+	AutoPortAllocation bool `yaml:"autoPortAllocation,omitempty"`
+
+	// PortOffset shifts the ports of all the listen addresses, and NodeIDBase shifts the node ids
+	// of all the datanodes, so that multiple clusters can run on one host. They are cleared
+	// once they are applied to the addresses and node ids recorded in the cluster metadata.
+	// Any offset that keeps the ports under 65536 is valid, but the cluster is only created if its shifted
+	// ports do not overlap the ports of the other clusters on the host, e.g. the offset 100 shifts the http
+	// port 14001 of metasrv to 14101, which is the rpc port of the 2nd datanode of the cluster without offset.
+	PortOffset int `yaml:"portOffset,omitempty" validate:"gte=0,lt=65536"`
+	NodeIDBase int `yaml:"nodeIDBase,omitempty" validate:"gte=0"`
+
+	// WAL is optional, the datanodes use the local raft-engine WAL if it's not specified.
+	WAL *WAL `yaml:"wal,omitempty"`
+
+	// LogRotation is optional, the log files of components grow unbounded if it's not specified.
+	// The logs are rotated by gtctl while it runs the cluster in the foreground, they are not rotated
+	// in detached mode or with the components that are kept running on failure.
+	LogRotation *LogRotation `yaml:"logRotation,omitempty"`
+
+	// LogFormat is the format of the logs of all the GreptimeDB components, which is LogFormatText if
+	// it's empty. The json logs can be parsed by the tools like vector and jq line by line.
+	LogFormat string `yaml:"logFormat,omitempty" validate:"omitempty,oneof=text json"`
+
+	// CrashDump is optional, the crashes of components are recorded with the last DefaultCrashLogLines
+	// lines of logs and without the core dumps if it's not specified.
+	CrashDump *CrashDump `yaml:"crashDump,omitempty"`
+
+	// StartupConcurrency is the max number of components, e.g. the datanode groups, or hosts that are started
+	// in parallel, it's DefaultStartupConcurrency if it's zero. The components still start in the order of
+	// their dependencies, i.e. metasrv, datanode and frontend.
+	StartupConcurrency int `yaml:"startupConcurrency,omitempty" validate:"gte=0"`
 }
 
+// DefaultStartupConcurrency is the default max number of components or hosts that are started in parallel.
+const DefaultStartupConcurrency = 4
+
+// Concurrency returns the max number of components or hosts that are started in parallel.
+func (c *BareMetalClusterComponentsConfig) Concurrency() int {
+	if c.StartupConcurrency <= 0 {
+		return DefaultStartupConcurrency
+	}
+	return c.StartupConcurrency
+}
+
+// The formats of the logs of GreptimeDB components.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// DefaultCrashLogLines is the default number of the last lines of logs recorded for each crash.
+const DefaultCrashLogLines = 200
+
+// CrashDump configures what is recorded when the component exits abnormally.
+type CrashDump struct {
+	// LogLines is the number of the last lines of logs recorded for each crash, it's DefaultCrashLogLines if zero.
+	LogLines int `yaml:"logLines,omitempty" validate:"gte=0"`
+
+	// CoreDump enables the core dumps of components, the core file of the crashed process is moved
+	// into its crash record if it can be found by the core pattern of system.
+	CoreDump bool `yaml:"coreDump,omitempty"`
+}
+
+// LogRotation rotates the log files of all the components by size and age.
+type LogRotation struct {
+	// MaxSizeMB is the max size in megabytes of one log file before it's rotated.
+	MaxSizeMB int `yaml:"maxSizeMB" validate:"gte=0"`
+
+	// MaxAge is the max duration of writing one log file before it's rotated, zero means no limit.
+	MaxAge time.Duration `yaml:"maxAge" validate:"gte=0"`
+
+	// MaxFiles is the max number of rotated log files to keep, the oldest ones are removed.
+	MaxFiles int `yaml:"maxFiles" validate:"gte=0"`
+}
+
+// Hooks are the shell commands that run before or after the component starts and stops.
+type Hooks struct {
+	// PreStart runs before the replicas are started, the component is not started if any of them fails.
+	PreStart []string `yaml:"preStart,omitempty"`
+
+	// PostStart runs after all the replicas are ready, the failure of them fails the start of component.
+	PostStart []string `yaml:"postStart,omitempty"`
+
+	// PreStop runs before the replicas are stopped, the failure of them is ignored.
+	PreStop []string `yaml:"preStop,omitempty"`
+}
+
+// ReplicaAddrs records the listen addresses of replicas, keyed by the index
+// of replica and then the arg of address, e.g. "--http-addr".
+type ReplicaAddrs map[int]map[string]string
+
 type Artifact struct {
 	// Local is the local path of binary(greptime or etcd).
 	Local string `yaml:"local" validate:"omitempty,filepath"`
@@ -58,40 +286,532 @@ type Artifact struct {
 }
 
 type Datanode struct {
+	// NodeID is the node id of the first replica, the node ids of the others follow it in order.
 	NodeID       int    `yaml:"nodeID" validate:"gte=0"`
 	RPCAddr      string `yaml:"rpcAddr" validate:"required,hostname_port"`
 	HTTPAddr     string `yaml:"httpAddr" validate:"required,hostname_port"`
+	HealthHost   string `yaml:"healthHost" validate:"omitempty,hostname|ip"`
 	DataDir      string `yaml:"dataDir" validate:"omitempty,dirpath"`
 	WalDir       string `yaml:"walDir" validate:"omitempty,dirpath"`
 	ProcedureDir string `yaml:"procedureDir" validate:"omitempty,dirpath"`
 
+	// Storage is optional, the datanode stores data in local file system if it's not specified.
+	Storage *ObjectStorage `yaml:"storage,omitempty"`
+
 	Replicas int    `yaml:"replicas" validate:"gt=0"`
 	Config   string `yaml:"config" validate:"omitempty,filepath"`
-	LogLevel string `yaml:"logLevel"`
+	// Configs overrides Config for the replicas, keyed by the index of replica.
+	Configs  map[int]string `yaml:"configs,omitempty" validate:"omitempty,dive,keys,gte=0,endkeys,filepath"`
+	LogLevel string         `yaml:"logLevel"`
+
+	// BinaryPath overrides the greptime binary of cluster artifact for this component.
+	BinaryPath string `yaml:"binaryPath,omitempty" validate:"omitempty,filepath"`
+
+	Readiness   *Readiness   `yaml:"readiness,omitempty"`
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+	Resources   *Resources   `yaml:"resources,omitempty"`
+	Restart     *Restart     `yaml:"restart,omitempty"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Hooks *Hooks            `yaml:"hooks,omitempty"`
+
+	// ReplicaAddrs overrides the listen addresses of replicas.
+	ReplicaAddrs ReplicaAddrs `yaml:"replicaAddrs,omitempty"`
+
+	// Hosts are the names of hosts that the replicas are placed on in turn in multi-host mode,
+	// all the hosts of cluster are used if it's empty.
+	Hosts []string `yaml:"hosts,omitempty"`
 }
 
-type Frontend struct {
+// Standalone runs all the roles of GreptimeDB in one process, there is always one replica of it.
+type Standalone struct {
 	GRPCAddr     string `yaml:"grpcAddr" validate:"omitempty,hostname_port"`
 	HTTPAddr     string `yaml:"httpAddr" validate:"omitempty,hostname_port"`
 	PostgresAddr string `yaml:"postgresAddr" validate:"omitempty,hostname_port"`
-	MetaAddr     string `yaml:"metaAddr" validate:"omitempty,hostname_port"`
 	MysqlAddr    string `yaml:"mysqlAddr" validate:"omitempty,hostname_port"`
+	HealthHost   string `yaml:"healthHost" validate:"omitempty,hostname|ip"`
 
-	Replicas     int    `yaml:"replicas" validate:"gt=0"`
 	Config       string `yaml:"config" validate:"omitempty,filepath"`
 	LogLevel     string `yaml:"logLevel"`
 	UserProvider string `yaml:"userProvider"`
+
+	// BinaryPath overrides the greptime binary of cluster artifact for this component.
+	BinaryPath string `yaml:"binaryPath,omitempty" validate:"omitempty,filepath"`
+
+	Readiness   *Readiness   `yaml:"readiness,omitempty"`
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+	Resources   *Resources   `yaml:"resources,omitempty"`
+	Restart     *Restart     `yaml:"restart,omitempty"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Hooks *Hooks            `yaml:"hooks,omitempty"`
+
+	// ReplicaAddrs overrides the listen addresses of the replica.
+	ReplicaAddrs ReplicaAddrs `yaml:"replicaAddrs,omitempty"`
+}
+
+// DefaultStandaloneConfig returns the standalone config that listens on the default ports of frontend.
+func DefaultStandaloneConfig() *Standalone {
+	return &Standalone{
+		HTTPAddr:     "0.0.0.0:4000",
+		GRPCAddr:     "0.0.0.0:4001",
+		MysqlAddr:    "0.0.0.0:4002",
+		PostgresAddr: "0.0.0.0:4003",
+	}
+}
+
+// DatanodeGroup is a group of datanodes that are different from the others in storage or configs,
+// e.g. the "hot" group stores data in local SSD and the "cold" group stores data in S3.
+type DatanodeGroup struct {
+	Name string `yaml:"name" validate:"required,alphanum"`
+
+	// The NodeID of group follows the node ids of the previous datanodes if it's not specified.
+	Datanode `yaml:",inline"`
+}
+
+// DatanodeGroupNodeIDs returns the node id of the first replica of each datanode group.
+func (c *BareMetalClusterComponentsConfig) DatanodeGroupNodeIDs() []int {
+	nodeIDs := make([]int, 0, len(c.DatanodeGroups))
+	next := c.Datanode.NodeID + c.Datanode.Replicas
+	for _, group := range c.DatanodeGroups {
+		nodeID := group.NodeID
+		if nodeID == 0 {
+			nodeID = next
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+		next = nodeID + group.Replicas
+	}
+	return nodeIDs
+}
+
+// placements returns the hosts that the replicas of each component are placed on, keyed by the name of component.
+func (c *BareMetalClusterComponentsConfig) placements() map[string][]string {
+	placements := make(map[string][]string)
+	if c.Frontend != nil {
+		placements["Frontend"] = c.Frontend.Hosts
+	}
+	if c.MetaSrv != nil {
+		placements["MetaSrv"] = c.MetaSrv.Hosts
+	}
+	if c.Datanode != nil {
+		placements["Datanode"] = c.Datanode.Hosts
+	}
+	if c.Flownode != nil {
+		placements["Flownode"] = c.Flownode.Hosts
+	}
+	for _, group := range c.DatanodeGroups {
+		if group != nil {
+			placements[fmt.Sprintf("DatanodeGroups[%s]", group.Name)] = group.Hosts
+		}
+	}
+	return placements
 }
 
+// hooks returns the hooks of each component, keyed by the name of component.
+func (c *BareMetalClusterComponentsConfig) hooks() map[string]*Hooks {
+	hooks := make(map[string]*Hooks)
+	if c.Frontend != nil {
+		hooks["Frontend"] = c.Frontend.Hooks
+	}
+	if c.MetaSrv != nil {
+		hooks["MetaSrv"] = c.MetaSrv.Hooks
+	}
+	if c.Datanode != nil {
+		hooks["Datanode"] = c.Datanode.Hooks
+	}
+	if c.Flownode != nil {
+		hooks["Flownode"] = c.Flownode.Hooks
+	}
+	for _, group := range c.DatanodeGroups {
+		if group != nil {
+			hooks[fmt.Sprintf("DatanodeGroups[%s]", group.Name)] = group.Hooks
+		}
+	}
+	return hooks
+}
+
+// resources returns the resource limits of the components, keyed by the names of components.
+func (c *BareMetalClusterComponentsConfig) resources() map[string]*Resources {
+	resources := make(map[string]*Resources)
+	if c.Frontend != nil {
+		resources["Frontend"] = c.Frontend.Resources
+	}
+	if c.MetaSrv != nil {
+		resources["MetaSrv"] = c.MetaSrv.Resources
+	}
+	if c.Datanode != nil {
+		resources["Datanode"] = c.Datanode.Resources
+	}
+	if c.Flownode != nil {
+		resources["Flownode"] = c.Flownode.Resources
+	}
+	for _, group := range c.DatanodeGroups {
+		if group != nil {
+			resources[fmt.Sprintf("DatanodeGroups[%s]", group.Name)] = group.Resources
+		}
+	}
+	return resources
+}
+
+const (
+	ObjectStorageS3     = "s3"
+	ObjectStorageOSS    = "oss"
+	ObjectStorageGCS    = "gcs"
+	ObjectStorageAzblob = "azblob"
+)
+
+// ObjectStorage is the object storage that datanode stores data in.
+type ObjectStorage struct {
+	Type string `yaml:"type" validate:"required,oneof=s3 oss gcs azblob"`
+
+	// Bucket is the bucket of storage, or the container for azblob.
+	Bucket   string `yaml:"bucket" validate:"required"`
+	Root     string `yaml:"root"`
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint" validate:"omitempty,url"`
+
+	// CredentialsFile is the path of credentials. For gcs, it's the credential file of
+	// service account. For the others, it's a YAML file of ObjectStorageCredentials.
+	// The credentials are kept in a separate file to avoid being recorded in the cluster metadata.
+	CredentialsFile string `yaml:"credentialsFile" validate:"omitempty,filepath"`
+}
+
+// Credentials reads the credentials of storage from its credentials file, it's empty if the file is
+// not specified or the storage is gcs, whose credentials file is used as it is.
+func (s *ObjectStorage) Credentials() (*ObjectStorageCredentials, error) {
+	var credentials ObjectStorageCredentials
+	if len(s.CredentialsFile) == 0 || s.Type == ObjectStorageGCS {
+		return &credentials, nil
+	}
+
+	raw, err := os.ReadFile(s.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials of storage: %v", err)
+	}
+	if err = yaml.Unmarshal(raw, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials of storage: %v", err)
+	}
+	return &credentials, nil
+}
+
+// ObjectStorageCredentials is the content of the credentials file of object storage except gcs.
+type ObjectStorageCredentials struct {
+	// AccessKeyID and SecretAccessKey are used by s3 and oss.
+	AccessKeyID     string `yaml:"accessKeyID"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+
+	// AccountName, AccountKey and SASToken are used by azblob.
+	AccountName string `yaml:"accountName"`
+	AccountKey  string `yaml:"accountKey"`
+	SASToken    string `yaml:"sasToken"`
+}
+
+const (
+	WALProviderRaftEngine = "raft_engine"
+	WALProviderKafka      = "kafka"
+)
+
+// WAL is the write-ahead log of the cluster.
+type WAL struct {
+	Provider string    `yaml:"provider" validate:"omitempty,oneof=raft_engine kafka"`
+	Kafka    *KafkaWAL `yaml:"kafka,omitempty"`
+}
+
+// KafkaWAL is the remote WAL backed by Kafka.
+type KafkaWAL struct {
+	BrokerEndpoints   []string `yaml:"brokerEndpoints" validate:"omitempty,dive,hostname_port"`
+	TopicNamePrefix   string   `yaml:"topicNamePrefix"`
+	ReplicationFactor int      `yaml:"replicationFactor" validate:"gte=0"`
+
+	// Embedded is optional, it starts a single-node Kafka as the WAL for local testing,
+	// in which case the BrokerEndpoints is ignored.
+	Embedded *EmbeddedKafka `yaml:"embedded,omitempty"`
+}
+
+// EmbeddedKafka is the single-node Kafka that runs in KRaft mode.
+type EmbeddedKafka struct {
+	// Home is the installation directory of Kafka, which contains "bin/kafka-server-start.sh".
+	Home string `yaml:"home" validate:"required,dirpath"`
+
+	// Addr is the address of broker, the controller listens on the next port of it.
+	Addr string `yaml:"addr" validate:"omitempty,hostname_port"`
+}
+
+const (
+	BackendStorageEtcd     = "etcd"
+	BackendStoragePostgres = "postgres"
+	BackendStorageMySQL    = "mysql"
+)
+
+// BackendStorage is the metadata store backend of metasrv.
+type BackendStorage struct {
+	Kind string `yaml:"kind" validate:"required,oneof=etcd postgres mysql"`
+
+	// Endpoints are the addresses of etcd cluster. For postgres and mysql,
+	// only the first endpoint is used.
+	Endpoints []string `yaml:"endpoints" validate:"required,dive,hostname_port"`
+
+	// Database and User are only used by postgres and mysql.
+	Database string `yaml:"database"`
+	User     string `yaml:"user"`
+
+	// PasswordFile is the path of the file that contains the password. The password is kept
+	// in a separate file to avoid being recorded in the cluster metadata.
+	PasswordFile string `yaml:"passwordFile" validate:"omitempty,filepath"`
+
+	TLS *BackendTLS `yaml:"tls,omitempty"`
+}
+
+const (
+	BackendTLSModeDisable    = "disable"
+	BackendTLSModePrefer     = "prefer"
+	BackendTLSModeRequire    = "require"
+	BackendTLSModeVerifyCA   = "verify_ca"
+	BackendTLSModeVerifyFull = "verify_full"
+)
+
+// BackendTLS is the TLS options of connecting to the metadata store backend.
+type BackendTLS struct {
+	Mode       string `yaml:"mode" validate:"omitempty,oneof=disable prefer require verify_ca verify_full"`
+	CertPath   string `yaml:"certPath" validate:"omitempty,filepath"`
+	KeyPath    string `yaml:"keyPath" validate:"required_with=CertPath,omitempty,filepath"`
+	CACertPath string `yaml:"caCertPath" validate:"omitempty,filepath"`
+}
+
+type Frontend struct {
+	GRPCAddr     string `yaml:"grpcAddr" validate:"omitempty,hostname_port"`
+	HTTPAddr     string `yaml:"httpAddr" validate:"omitempty,hostname_port"`
+	PostgresAddr string `yaml:"postgresAddr" validate:"omitempty,hostname_port"`
+	MetaAddr     string `yaml:"metaAddr" validate:"omitempty,hostname_port"`
+	MysqlAddr    string `yaml:"mysqlAddr" validate:"omitempty,hostname_port"`
+	HealthHost   string `yaml:"healthHost" validate:"omitempty,hostname|ip"`
+
+	Replicas int    `yaml:"replicas" validate:"gt=0"`
+	Config   string `yaml:"config" validate:"omitempty,filepath"`
+	// Configs overrides Config for the replicas, keyed by the index of replica.
+	Configs      map[int]string `yaml:"configs,omitempty" validate:"omitempty,dive,keys,gte=0,endkeys,filepath"`
+	LogLevel     string         `yaml:"logLevel"`
+	UserProvider string         `yaml:"userProvider"`
+
+	// BinaryPath overrides the greptime binary of cluster artifact for this component.
+	BinaryPath string `yaml:"binaryPath,omitempty" validate:"omitempty,filepath"`
+
+	Readiness   *Readiness   `yaml:"readiness,omitempty"`
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+	Resources   *Resources   `yaml:"resources,omitempty"`
+	Restart     *Restart     `yaml:"restart,omitempty"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Hooks *Hooks            `yaml:"hooks,omitempty"`
+
+	// ReplicaAddrs overrides the listen addresses of replicas.
+	ReplicaAddrs ReplicaAddrs `yaml:"replicaAddrs,omitempty"`
+
+	// TLS is applied to the MySQL, Postgres and gRPC servers of frontend. The HTTP server, which also serves
+	// the dashboard, is left out since GreptimeDB has no TLS options for it, it always serves plain HTTP and
+	// should be put behind a reverse proxy that terminates the TLS if it's exposed.
+	TLS *ServerTLS `yaml:"tls,omitempty"`
+
+	// Hosts are the names of hosts that the replicas are placed on in turn in multi-host mode,
+	// all the hosts of cluster are used if it's empty.
+	Hosts []string `yaml:"hosts,omitempty"`
+}
+
+const (
+	TLSModeDisable    = "disable"
+	TLSModePrefer     = "prefer"
+	TLSModeRequire    = "require"
+	TLSModeVerifyCA   = "verify-ca"
+	TLSModeVerifyFull = "verify-full"
+)
+
+// ServerTLS is the TLS options of the servers of component.
+type ServerTLS struct {
+	// Mode is 'require' if it's not specified.
+	Mode     string `yaml:"mode" validate:"omitempty,oneof=disable prefer require verify-ca verify-full"`
+	CertPath string `yaml:"certPath" validate:"required_without=AutoGenerate,omitempty,filepath"`
+	KeyPath  string `yaml:"keyPath" validate:"required_with=CertPath,omitempty,filepath"`
+
+	// CACertPath is the CA certificate that signs the server certificate, it's only used
+	// to tell the clients how to verify the servers.
+	CACertPath string `yaml:"caCertPath" validate:"omitempty,filepath"`
+
+	// AutoGenerate generates the self-signed certificates in the data directory of cluster
+	// if they don't exist, in which case the paths above are ignored.
+	AutoGenerate bool `yaml:"autoGenerate"`
+}
+
+// MetaSrvBackendEmbeddedEtcd makes gtctl deploy an etcd that listens on the StoreAddr of metasrv.
+const MetaSrvBackendEmbeddedEtcd = "embedded-etcd"
+
 type MetaSrv struct {
-	StoreAddr  string `yaml:"storeAddr" validate:"hostname_port"`
+	// Backend is the way of deploying the metadata store. If it's not specified,
+	// metasrv connects to the external metadata store by StoreAddr or BackendStorage.
+	Backend string `yaml:"backend,omitempty" validate:"omitempty,oneof=embedded-etcd,excluded_with=BackendStorage"`
+
+	StoreAddr  string `yaml:"storeAddr" validate:"required_without=BackendStorage,omitempty,hostname_port"`
 	ServerAddr string `yaml:"serverAddr" validate:"hostname_port"`
 	BindAddr   string `yaml:"bindAddr" validate:"omitempty,hostname_port"`
 	HTTPAddr   string `yaml:"httpAddr" validate:"required,hostname_port"`
+	HealthHost string `yaml:"healthHost" validate:"omitempty,hostname|ip"`
 
 	Replicas int    `yaml:"replicas" validate:"gt=0"`
 	Config   string `yaml:"config" validate:"omitempty,filepath"`
-	LogLevel string `yaml:"logLevel"`
+	// Configs overrides Config for the replicas, keyed by the index of replica.
+	Configs  map[int]string `yaml:"configs,omitempty" validate:"omitempty,dive,keys,gte=0,endkeys,filepath"`
+	LogLevel string         `yaml:"logLevel"`
+
+	// BinaryPath overrides the greptime binary of cluster artifact for this component.
+	BinaryPath string `yaml:"binaryPath,omitempty" validate:"omitempty,filepath"`
+
+	Readiness   *Readiness   `yaml:"readiness,omitempty"`
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+	Resources   *Resources   `yaml:"resources,omitempty"`
+	Restart     *Restart     `yaml:"restart,omitempty"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Hooks *Hooks            `yaml:"hooks,omitempty"`
+
+	// ReplicaAddrs overrides the listen addresses of replicas.
+	ReplicaAddrs ReplicaAddrs `yaml:"replicaAddrs,omitempty"`
+
+	// BackendStorage overrides StoreAddr if it's specified.
+	BackendStorage *BackendStorage `yaml:"backendStorage,omitempty"`
+
+	// Selector is the way of selecting datanodes for the new regions, metasrv uses its default if it's not specified.
+	Selector string `yaml:"selector,omitempty" validate:"omitempty,oneof=round_robin lease_based load_based"`
+
+	// EnableRegionFailover migrates the regions of failed datanodes to the others, it requires the Kafka WAL.
+	EnableRegionFailover bool `yaml:"enableRegionFailover,omitempty"`
+
+	// Heartbeat configures the heartbeats that the other components send to metasrv.
+	Heartbeat *Heartbeat `yaml:"heartbeat,omitempty"`
+
+	// Hosts are the names of hosts that the replicas are placed on in turn in multi-host mode,
+	// all the hosts of cluster are used if it's empty.
+	Hosts []string `yaml:"hosts,omitempty"`
+}
+
+// Heartbeat is the heartbeat options of datanode, flownode and frontend,
+// the zero value of each option means the default of GreptimeDB.
+type Heartbeat struct {
+	Interval      time.Duration `yaml:"interval,omitempty" validate:"gte=0"`
+	RetryInterval time.Duration `yaml:"retryInterval,omitempty" validate:"gte=0"`
+}
+
+type Flownode struct {
+	RPCAddr    string `yaml:"rpcAddr" validate:"required,hostname_port"`
+	HTTPAddr   string `yaml:"httpAddr" validate:"required,hostname_port"`
+	HealthHost string `yaml:"healthHost" validate:"omitempty,hostname|ip"`
+
+	Replicas int    `yaml:"replicas" validate:"gt=0"`
+	Config   string `yaml:"config" validate:"omitempty,filepath"`
+	// Configs overrides Config for the replicas, keyed by the index of replica.
+	Configs  map[int]string `yaml:"configs,omitempty" validate:"omitempty,dive,keys,gte=0,endkeys,filepath"`
+	LogLevel string         `yaml:"logLevel"`
+
+	// BinaryPath overrides the greptime binary of cluster artifact for this component.
+	BinaryPath string `yaml:"binaryPath,omitempty" validate:"omitempty,filepath"`
+
+	Readiness   *Readiness   `yaml:"readiness,omitempty"`
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+	Resources   *Resources   `yaml:"resources,omitempty"`
+	Restart     *Restart     `yaml:"restart,omitempty"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Hooks *Hooks            `yaml:"hooks,omitempty"`
+
+	// ReplicaAddrs overrides the listen addresses of replicas.
+	ReplicaAddrs ReplicaAddrs `yaml:"replicaAddrs,omitempty"`
+
+	// Hosts are the names of hosts that the replicas are placed on in turn in multi-host mode,
+	// all the hosts of cluster are used if it's empty.
+	Hosts []string `yaml:"hosts,omitempty"`
+}
+
+// Readiness is the policy of waiting for all the replicas of one component to become healthy.
+// The zero value of each field means using the default policy.
+type Readiness struct {
+	// Timeout is the max duration of waiting.
+	Timeout time.Duration `yaml:"timeout" validate:"gte=0"`
+
+	// InitialBackoff is the interval before the first retry, it's doubled
+	// after each retry until reaching the MaxBackoff.
+	InitialBackoff time.Duration `yaml:"initialBackoff" validate:"gte=0"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff" validate:"gte=0"`
+
+	// MaxRetries is the max number of retries, zero means retrying until timeout.
+	MaxRetries int `yaml:"maxRetries" validate:"gte=0"`
+}
+
+const (
+	HealthCheckProtocolHTTP = "http"
+	HealthCheckProtocolGRPC = "grpc"
+)
+
+// HealthCheck is how the health of the replicas of one component is checked.
+// The zero value checks the HTTP path '/health' on the HTTP address of replica.
+type HealthCheck struct {
+	// Protocol is 'http' if it's not specified. The 'grpc' checks the gRPC address of replica
+	// through the standard gRPC health checking protocol, which works without the HTTP server.
+	Protocol string `yaml:"protocol" validate:"omitempty,oneof=http grpc"`
+
+	// Path is the HTTP path to check, '/health' if it's not specified.
+	Path string `yaml:"path" validate:"omitempty,startswith=/"`
+
+	// Service is the service name that gRPC health checks, the empty name checks the whole server.
+	Service string `yaml:"service"`
+}
+
+// Resources is the resource limits of each replica of one component. They are applied when the
+// process is started, so it's limited from the start.
+type Resources struct {
+	// MaxOpenFiles is the max number of open files, the same as `ulimit -n`.
+	MaxOpenFiles int `yaml:"maxOpenFiles" validate:"gte=0"`
+
+	// Nice is the CPU niceness, which ranges from -20 (the highest priority) to 19 (the lowest priority).
+	Nice int `yaml:"nice" validate:"gte=-20,lte=19"`
+
+	// Cgroup is optional, it only works on Linux with cgroup v2.
+	Cgroup *Cgroup `yaml:"cgroup,omitempty"`
+}
+
+// Cgroup describes the cgroup v2 limits. Each replica is placed in its own
+// sub-group under the Parent group, so the limits are applied per replica.
+type Cgroup struct {
+	// Parent is the path of the parent group relative to the cgroup v2 mount point, e.g. "gtctl.slice".
+	// It should be writable by current user and have the memory and cpu controllers available.
+	Parent string `yaml:"parent" validate:"required"`
+
+	// MemoryMax is written to the "memory.max" of the group, e.g. "2G".
+	MemoryMax string `yaml:"memoryMax"`
+
+	// CPUMax is written to the "cpu.max" of the group, e.g. "200000 100000" for 2 CPUs.
+	CPUMax string `yaml:"cpuMax"`
+}
+
+const (
+	// RestartPolicyNever never restarts the exited replica, which is the default policy.
+	RestartPolicyNever = "never"
+
+	// RestartPolicyOnFailure restarts the replica only if it exits with failure.
+	RestartPolicyOnFailure = "on-failure"
+
+	// RestartPolicyAlways always restarts the exited replica.
+	RestartPolicyAlways = "always"
+)
+
+// Restart is the policy of restarting the replica of one component when it exits unexpectedly.
+type Restart struct {
+	Policy string `yaml:"policy" validate:"omitempty,oneof=never on-failure always"`
+
+	// MaxRestarts is the max number of restarts of each replica, zero means no limit.
+	MaxRestarts int `yaml:"maxRestarts" validate:"gte=0"`
+
+	// InitialBackoff is the interval before the first restart, it's doubled after
+	// each restart until reaching the MaxBackoff.
+	InitialBackoff time.Duration `yaml:"initialBackoff" validate:"gte=0"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff" validate:"gte=0"`
 }
 
 type Etcd struct {
@@ -112,6 +832,7 @@ func DefaultBareMetalConfig() *BareMetalClusterConfig {
 				PostgresAddr: "0.0.0.0:4003",
 			},
 			MetaSrv: &MetaSrv{
+				Backend:    MetaSrvBackendEmbeddedEtcd,
 				Replicas:   1,
 				StoreAddr:  "127.0.0.1:2379",
 				ServerAddr: "0.0.0.0:3002",
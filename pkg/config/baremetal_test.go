@@ -0,0 +1,36 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatanodeGroupNodeIDs(t *testing.T) {
+	cfg := &BareMetalClusterComponentsConfig{
+		Datanode: &Datanode{Replicas: 3},
+		DatanodeGroups: []*DatanodeGroup{
+			{Name: "hot", Datanode: Datanode{Replicas: 2}},
+			{Name: "cold", Datanode: Datanode{Replicas: 1, NodeID: 10}},
+			{Name: "archive", Datanode: Datanode{Replicas: 2}},
+		},
+	}
+
+	assert.Equal(t, []int{3, 10, 11}, cfg.DatanodeGroupNodeIDs())
+}
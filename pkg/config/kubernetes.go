@@ -18,7 +18,10 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
+
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -34,6 +37,13 @@ type SetValues struct {
 	OperatorConfig string
 	ClusterConfig  string
 	EtcdConfig     string
+
+	// ValuesFiles are the values files that are classified by their top-level keys like the
+	// raw config values, and the values of each file are in the order of files.
+	ValuesFiles    []string
+	OperatorValues []map[string]interface{}
+	ClusterValues  []map[string]interface{}
+	EtcdValues     []map[string]interface{}
 }
 
 // Parse parses raw config values and classify it to different
@@ -86,5 +96,68 @@ func (c *SetValues) Parse() error {
 		c.EtcdConfig = strings.Join(etcdConfig, ",")
 	}
 
+	for _, file := range c.ValuesFiles {
+		if err := c.parseValuesFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseValuesFile parses the values file and classifies its values to different categories of config type
+// by the top-level keys, the values without the prefix keys are the values of cluster.
+func (c *SetValues) parseValuesFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]interface{}
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid values file '%s': %v", file, err)
+	}
+
+	var (
+		operatorValues = make(map[string]interface{})
+		clusterValues  = make(map[string]interface{})
+		etcdValues     = make(map[string]interface{})
+	)
+	// The values with prefix keys override the ones without them.
+	for key, value := range values {
+		switch key {
+		case configOperator, configCluster, configEtcd:
+		default:
+			clusterValues[key] = value
+		}
+	}
+	for key, target := range map[string]map[string]interface{}{
+		configOperator: operatorValues,
+		configCluster:  clusterValues,
+		configEtcd:     etcdValues,
+	} {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		prefixed, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid values file '%s': the values of '%s' should be a map", file, key)
+		}
+		for k, v := range prefixed {
+			target[k] = v
+		}
+	}
+
+	if len(operatorValues) > 0 {
+		c.OperatorValues = append(c.OperatorValues, operatorValues)
+	}
+	if len(clusterValues) > 0 {
+		c.ClusterValues = append(c.ClusterValues, clusterValues)
+	}
+	if len(etcdValues) > 0 {
+		c.EtcdValues = append(c.EtcdValues, etcdValues)
+	}
+
 	return nil
 }
@@ -17,6 +17,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -80,3 +82,40 @@ func TestParseConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	assert.NoError(t, os.WriteFile(base, []byte(`
+image:
+  tag: v0.9.0
+cluster:
+  frontend:
+    replicas: 2
+etcd:
+  replicaCount: 3
+`), 0644))
+	override := filepath.Join(dir, "override.yaml")
+	assert.NoError(t, os.WriteFile(override, []byte(`
+operator:
+  resources:
+    limits:
+      cpu: 500m
+`), 0644))
+
+	actual := SetValues{ValuesFiles: []string{base, override}}
+	assert.NoError(t, actual.Parse())
+	assert.Equal(t, []map[string]interface{}{{
+		"image":    map[string]interface{}{"tag": "v0.9.0"},
+		"frontend": map[string]interface{}{"replicas": float64(2)},
+	}}, actual.ClusterValues)
+	assert.Equal(t, []map[string]interface{}{{"replicaCount": float64(3)}}, actual.EtcdValues)
+	assert.Equal(t, []map[string]interface{}{{
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}},
+	}}, actual.OperatorValues)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	assert.NoError(t, os.WriteFile(invalid, []byte("etcd: 3\n"), 0644))
+	actual = SetValues{ValuesFiles: []string{invalid}}
+	assert.ErrorContains(t, actual.Parse(), "should be a map")
+}
@@ -0,0 +1,552 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one problem found in the bare-metal cluster config.
+type Problem struct {
+	// Line is the line of the problematic field in the config file, 0 means
+	// the field is not from the config file, e.g. it's set in command line.
+	Line int
+
+	// Path is the path of the problematic field, e.g. "cluster.frontend.replicas".
+	Path string
+
+	Message string
+}
+
+func (p Problem) String() string {
+	s := p.Message
+	if len(p.Path) > 0 {
+		s = fmt.Sprintf("%s: %s", p.Path, s)
+	}
+	if p.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", p.Line, s)
+	}
+	return s
+}
+
+// Problems are all the problems found in the bare-metal cluster config, which are reported in one error.
+type Problems []Problem
+
+func (p Problems) Error() string {
+	problems := make([]string, 0, len(p))
+	for _, problem := range p {
+		problems = append(problems, problem.String())
+	}
+	return fmt.Sprintf("found %d problem(s) in config:\n  - %s", len(p), strings.Join(problems, "\n  - "))
+}
+
+// typeErrorPattern matches the error of decoding one field of YAML, e.g. "line 3: cannot unmarshal ...".
+var typeErrorPattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// ValidateBareMetalConfig renders the bare-metal cluster config in the same way as RenderBareMetalConfig and
+// validates it. Instead of stopping at the first problem, all the problems are returned at once as Problems,
+// including the unsupported fields, the invalid values, the missing files and the duplicated ports.
+func ValidateBareMetalConfig(base []byte, profile string, values []string, vars map[string]string) (*BareMetalClusterConfig, error) {
+	root, err := renderBareMetalConfig(base, profile, values, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cfg      BareMetalClusterConfig
+		problems Problems
+	)
+
+	if err = root.Decode(&cfg); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return nil, err
+		}
+		for _, msg := range typeErr.Errors {
+			problem := Problem{Message: msg}
+			if match := typeErrorPattern.FindStringSubmatch(msg); match != nil {
+				problem.Line, _ = strconv.Atoi(match[1])
+				problem.Message = match[2]
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	problems = append(problems, unsupportedFields(root, reflect.TypeOf(cfg), nil)...)
+
+	invalid, err := invalidFields(root, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, invalid...)
+	problems = append(problems, missingFiles(root, reflect.ValueOf(cfg), nil)...)
+	problems = append(problems, duplicatedPorts(root, &cfg)...)
+
+	if len(problems) > 0 {
+		// Sort the problems by their lines, and the problems that are not from the config file come last.
+		sort.SliceStable(problems, func(i, j int) bool {
+			if problems[i].Line == 0 || problems[j].Line == 0 {
+				return problems[j].Line == 0 && problems[i].Line != 0
+			}
+			return problems[i].Line < problems[j].Line
+		})
+		return nil, problems
+	}
+
+	return &cfg, nil
+}
+
+// unsupportedFields reports the keys of mappings in the node tree that are not the fields of type t.
+func unsupportedFields(node *yaml.Node, t reflect.Type, path []string) []Problem {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var problems []Problem
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			// The mismatched type has been reported by decoding.
+			return nil
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			fieldPath := appendPath(path, key.Value)
+			field, ok := fields[key.Value]
+			if !ok {
+				problems = append(problems, Problem{Line: key.Line, Path: pathString(fieldPath), Message: "unsupported field"})
+				continue
+			}
+			problems = append(problems, unsupportedFields(val, field.Type, fieldPath)...)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range node.Content {
+			problems = append(problems, unsupportedFields(item, t.Elem(), appendPath(path, strconv.Itoa(i)))...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unsupportedFields(node.Content[i+1], t.Elem(), appendPath(path, node.Content[i].Value))...)
+		}
+	}
+
+	return problems
+}
+
+// invalidFields reports the fields that fail the validation of ValidateConfig.
+func invalidFields(root *yaml.Node, cfg *BareMetalClusterConfig) ([]Problem, error) {
+	err := newValidator().Struct(cfg)
+	if err == nil {
+		return nil, nil
+	}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, fieldErr := range fieldErrs {
+		path := yamlPath(reflect.TypeOf(*cfg), fieldErr.Namespace())
+		problems = append(problems, Problem{
+			Line:    lineOf(root, path),
+			Path:    pathString(path),
+			Message: describeFieldError(fieldErr),
+		})
+	}
+
+	return problems, nil
+}
+
+// describeFieldError describes the failed validation of field in words.
+func describeFieldError(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "":
+		return "is invalid"
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("should be greater than %s", fieldErr.Param())
+	case "gte":
+		return fmt.Sprintf("should be greater than or equal to %s", fieldErr.Param())
+	case "lt":
+		return fmt.Sprintf("should be less than %s", fieldErr.Param())
+	case "lte":
+		return fmt.Sprintf("should be less than or equal to %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("should be one of '%s'", fieldErr.Param())
+	case "hostname_port":
+		return fmt.Sprintf("'%v' is not an address in the form of 'host:port'", fieldErr.Value())
+	}
+
+	if len(fieldErr.Param()) > 0 {
+		return fmt.Sprintf("failed on the '%s=%s' validation", fieldErr.Tag(), fieldErr.Param())
+	}
+	return fmt.Sprintf("failed on the '%s' validation", fieldErr.Tag())
+}
+
+// missingFiles reports the file paths in the config that are not exist.
+func missingFiles(root *yaml.Node, v reflect.Value, path []string) []Problem {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var problems []Problem
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, inline := yamlFieldName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+
+			fieldPath := path
+			if !inline {
+				fieldPath = appendPath(path, name)
+			}
+			if strings.Contains(field.Tag.Get("validate"), "filepath") {
+				problems = append(problems, missingFile(root, v.Field(i), fieldPath)...)
+				continue
+			}
+			problems = append(problems, missingFiles(root, v.Field(i), fieldPath)...)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			problems = append(problems, missingFiles(root, v.Index(i), appendPath(path, strconv.Itoa(i)))...)
+		}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(v) {
+			problems = append(problems, missingFiles(root, v.MapIndex(key), appendPath(path, fmt.Sprint(key.Interface())))...)
+		}
+	}
+
+	return problems
+}
+
+// missingFile reports the file path of the string field, or the file paths of the map field if they are not exist.
+func missingFile(root *yaml.Node, v reflect.Value, path []string) []Problem {
+	switch v.Kind() {
+	case reflect.String:
+		file := v.String()
+		if len(file) == 0 {
+			return nil
+		}
+		if _, err := os.Stat(file); err != nil {
+			return []Problem{{Line: lineOf(root, path), Path: pathString(path), Message: fmt.Sprintf("file '%s' is not exist", file)}}
+		}
+	case reflect.Map:
+		var problems []Problem
+		for _, key := range sortedMapKeys(v) {
+			problems = append(problems, missingFile(root, v.MapIndex(key), appendPath(path, fmt.Sprint(key.Interface())))...)
+		}
+		return problems
+	}
+
+	return nil
+}
+
+// listenAddr is one listen address of the replica of component, and the path of field it's configured by.
+type listenAddr struct {
+	replica string
+	addr    string
+	path    []string
+}
+
+// duplicatedPorts reports the listen addresses of replicas that are duplicated with each other. The replicas listen on
+// the configured ports that are offset by their indexes. The components with the allocated or recorded addresses of
+// replicas are not checked here, whose ports are checked when the cluster is created.
+func duplicatedPorts(root *yaml.Node, cfg *BareMetalClusterConfig) []Problem {
+	c := cfg.Cluster
+	if c == nil || c.AutoPortAllocation {
+		return nil
+	}
+
+	var addrs []listenAddr
+	add := func(name string, replicas int, replicaAddrs ReplicaAddrs, path []string, fieldAddrs ...string) {
+		if len(replicaAddrs) > 0 {
+			return
+		}
+		for i := 0; i < replicas; i++ {
+			for j := 0; j+1 < len(fieldAddrs); j += 2 {
+				addr, err := offsetPort(fieldAddrs[j+1], i)
+				if err != nil || len(addr) == 0 {
+					// The invalid address has been reported by validation.
+					continue
+				}
+				addrs = append(addrs, listenAddr{
+					replica: fmt.Sprintf("%s.%d", name, i),
+					addr:    addr,
+					path:    appendPath(path, fieldAddrs[j]),
+				})
+			}
+		}
+	}
+
+	if standalone := c.Standalone; standalone != nil {
+		add("standalone", 1, standalone.ReplicaAddrs, []string{configCluster, "standalone"},
+			"httpAddr", standalone.HTTPAddr, "grpcAddr", standalone.GRPCAddr,
+			"mysqlAddr", standalone.MysqlAddr, "postgresAddr", standalone.PostgresAddr)
+	} else {
+		if frontend := c.Frontend; frontend != nil {
+			add("frontend", frontend.Replicas, frontend.ReplicaAddrs, []string{configCluster, "frontend"},
+				"httpAddr", frontend.HTTPAddr, "grpcAddr", frontend.GRPCAddr,
+				"mysqlAddr", frontend.MysqlAddr, "postgresAddr", frontend.PostgresAddr)
+		}
+		if metaSrv := c.MetaSrv; metaSrv != nil {
+			add("metasrv", metaSrv.Replicas, metaSrv.ReplicaAddrs, []string{configCluster, "meta"},
+				"httpAddr", metaSrv.HTTPAddr, "bindAddr", metaSrv.BindAddr)
+			if metaSrv.Backend == MetaSrvBackendEmbeddedEtcd {
+				// The embedded etcd listens for peers on the next port of the store address.
+				path := []string{configCluster, "meta", "storeAddr"}
+				if peerAddr, err := offsetPort(metaSrv.StoreAddr, 1); err == nil && len(peerAddr) > 0 {
+					addrs = append(addrs, listenAddr{replica: "etcd", addr: metaSrv.StoreAddr, path: path},
+						listenAddr{replica: "etcd", addr: peerAddr, path: path})
+				}
+			}
+		}
+		if datanode := c.Datanode; datanode != nil {
+			add("datanode", datanode.Replicas, datanode.ReplicaAddrs, []string{configCluster, "datanode"},
+				"httpAddr", datanode.HTTPAddr, "rpcAddr", datanode.RPCAddr)
+		}
+		for i, group := range c.DatanodeGroups {
+			if group == nil {
+				continue
+			}
+			add(fmt.Sprintf("datanode-%s", group.Name), group.Replicas, group.ReplicaAddrs,
+				[]string{configCluster, "datanodeGroups", strconv.Itoa(i)},
+				"httpAddr", group.HTTPAddr, "rpcAddr", group.RPCAddr)
+		}
+		if flownode := c.Flownode; flownode != nil {
+			add("flownode", flownode.Replicas, flownode.ReplicaAddrs, []string{configCluster, "flownode"},
+				"httpAddr", flownode.HTTPAddr, "rpcAddr", flownode.RPCAddr)
+		}
+	}
+
+	var problems []Problem
+	for i, addr := range addrs {
+		for _, prev := range addrs[:i] {
+			if isSameListenAddr(prev.addr, addr.addr) {
+				problems = append(problems, Problem{
+					Line: lineOf(root, addr.path),
+					Path: pathString(addr.path),
+					Message: fmt.Sprintf("'%s' of %s is duplicated with '%s' of %s (%s)",
+						addr.addr, addr.replica, prev.addr, prev.replica, pathString(prev.path)),
+				})
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// offsetPort offsets the port of addr by the index of replica, the empty addr is left to the default of binary.
+func offsetPort(addr string, replica int) (string, error) {
+	if len(addr) == 0 {
+		return addr, nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	portInt, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(portInt+replica)), nil
+}
+
+// isSameListenAddr checks whether the two addresses conflict with each other.
+// The addresses with the same port conflict if any of their hosts is unspecified.
+func isSameListenAddr(a, b string) bool {
+	hostA, portA, _ := net.SplitHostPort(a)
+	hostB, portB, _ := net.SplitHostPort(b)
+	if portA != portB {
+		return false
+	}
+
+	isUnspecified := func(host string) bool {
+		ip := net.ParseIP(host)
+		return len(host) == 0 || (ip != nil && ip.IsUnspecified())
+	}
+	return hostA == hostB || isUnspecified(hostA) || isUnspecified(hostB)
+}
+
+// yamlPath converts the namespace of field reported by validator, e.g. "BareMetalClusterConfig.Cluster.DatanodeGroups[0].Datanode.Replicas",
+// to the path of YAML, e.g. "cluster.datanodeGroups.0.replicas". The conversion stops at the name that is not a field.
+func yamlPath(t reflect.Type, namespace string) []string {
+	var path []string
+	for _, name := range strings.Split(namespace, ".")[1:] {
+		var index string
+		if i := strings.Index(name, "["); i > 0 && strings.HasSuffix(name, "]") {
+			name, index = name[:i], name[i+1:len(name)-1]
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			break
+		}
+
+		t = field.Type
+		if yamlName, inline := yamlFieldName(field); !inline {
+			path = append(path, yamlName)
+		}
+		if len(index) > 0 {
+			path = append(path, index)
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			t = t.Elem()
+		}
+	}
+
+	return path
+}
+
+// lineOf returns the line of the field of path in the node tree, or the line of its nearest parent
+// if the field is not found. It returns 0 if none of them is from the config file.
+func lineOf(root *yaml.Node, path []string) int {
+	var (
+		line int
+		node = root
+	)
+
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			if idx := mappingIndex(node, key); idx > 0 {
+				next = node.Content[idx]
+				if keyLine := node.Content[idx-1].Line; keyLine > 0 {
+					line = keyLine
+				}
+			}
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(node.Content) {
+				next = node.Content[idx]
+				if next.Line > 0 {
+					line = next.Line
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	return line
+}
+
+// yamlFields returns the fields of struct keyed by their names in YAML, the fields of inline structs are included.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline := yamlFieldName(field)
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if inline {
+			inlineType := field.Type
+			for inlineType.Kind() == reflect.Ptr {
+				inlineType = inlineType.Elem()
+			}
+			for inlineName, inlineField := range yamlFields(inlineType) {
+				fields[inlineName] = inlineField
+			}
+			continue
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// yamlFieldName returns the name of field in YAML, and whether the field is inlined.
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	opts := strings.Split(field.Tag.Get("yaml"), ",")
+	for _, opt := range opts[1:] {
+		if opt == "inline" {
+			return "", true
+		}
+	}
+
+	if len(opts[0]) > 0 {
+		return opts[0], false
+	}
+	// The same as the default name of field in YAML.
+	return strings.ToLower(field.Name), false
+}
+
+// sortedMapKeys returns the keys of map in order, so the problems are reported in a stable order.
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// appendPath appends the key to a copy of path, so the paths of siblings will not share the same array.
+func appendPath(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+// pathString formats the path of YAML, the indexes of sequences are formatted as "[0]".
+func pathString(path []string) string {
+	var sb strings.Builder
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil {
+			sb.WriteString(fmt.Sprintf("[%s]", key))
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(key)
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBareMetalConfig(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "validate", "problems.yaml"))
+	assert.NoError(t, err)
+
+	_, err = ValidateBareMetalConfig(raw, "", []string{"datanode.unknownField=1"}, nil)
+	assert.Error(t, err)
+
+	problems, ok := err.(Problems)
+	assert.True(t, ok)
+	assert.Len(t, problems, 6)
+
+	for _, expected := range []string{
+		"line 8: cluster.frontend.config: file '/path/to/not-exist/frontend.toml' is not exist",
+		"line 9: cluster.frontend.unknownField: unsupported field",
+		"line 15: cluster.meta.httpAddr: '14001' is not an address in the form of 'host:port'",
+		// The second datanode listens on the next port of the first one.
+		"line 19: cluster.datanode.httpAddr: '0.0.0.0:4000' of datanode.0 is duplicated with '0.0.0.0:4000' of frontend.0 (cluster.frontend.httpAddr)",
+		"line 19: cluster.datanode.httpAddr: '0.0.0.0:4001' of datanode.1 is duplicated with '0.0.0.0:4001' of frontend.0 (cluster.frontend.grpcAddr)",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+
+	// The problems that are not from the config file come last.
+	assert.Equal(t, "cluster.datanode.unknownField: unsupported field", problems[len(problems)-1].String())
+}
+
+func TestValidateBareMetalConfigWithProfile(t *testing.T) {
+	raw := []byte(`
+cluster:
+  artifact:
+    version: latest
+  frontend:
+    replicas: 1
+    httpAddr: 0.0.0.0:4000
+  meta:
+    replicas: 1
+    backend: embedded-etcd
+    storeAddr: 127.0.0.1:2379
+    serverAddr: 0.0.0.0:3002
+    httpAddr: 0.0.0.0:14001
+  datanode:
+    replicas: 1
+    rpcAddr: 0.0.0.0:14100
+    httpAddr: 0.0.0.0:14300
+etcd:
+  artifact:
+    version: v3.5.7
+`)
+
+	for _, profile := range BareMetalProfiles() {
+		cfg, err := ValidateBareMetalConfig(raw, profile, nil, nil)
+		assert.NoError(t, err, profile)
+		assert.NotNil(t, cfg, profile)
+	}
+
+	_, err := ValidateBareMetalConfig(raw, "", []string{"datanode.replicas=201"}, nil)
+	assert.ErrorContains(t, err, "cluster.datanode.rpcAddr: '0.0.0.0:14300' of datanode.200 is duplicated with "+
+		"'0.0.0.0:14300' of datanode.0 (cluster.datanode.httpAddr)")
+}
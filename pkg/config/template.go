@@ -0,0 +1,290 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProfileMinimal runs one replica of each component, which is the smallest distributed cluster.
+	ProfileMinimal = "minimal"
+
+	// ProfileHA runs multiple replicas of metasrv and datanode, so the cluster survives the failure of one replica.
+	// The frontend keeps one replica, since the ports of replicas are consecutive and overlap with each other.
+	ProfileHA = "ha"
+
+	// ProfileFlow runs a flownode besides the components for stream processing.
+	ProfileFlow = "flow"
+)
+
+// bareMetalProfiles are the overlays of the built-in profiles, which are merged onto the bare-metal cluster config.
+var bareMetalProfiles = map[string]string{
+	ProfileMinimal: `
+cluster:
+  frontend:
+    replicas: 1
+  meta:
+    replicas: 1
+  datanode:
+    replicas: 1
+`,
+	ProfileHA: `
+cluster:
+  frontend:
+    replicas: 1
+  meta:
+    replicas: 3
+  datanode:
+    replicas: 3
+`,
+	ProfileFlow: `
+cluster:
+  flownode:
+    replicas: 1
+    rpcAddr: 0.0.0.0:14600
+    httpAddr: 0.0.0.0:14700
+`,
+}
+
+// variablePattern matches the variables in the form of '${NAME}' in the bare-metal cluster config.
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+
+// BareMetalProfiles returns the names of the built-in profiles in order.
+func BareMetalProfiles() []string {
+	profiles := make([]string, 0, len(bareMetalProfiles))
+	for profile := range bareMetalProfiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// RenderBareMetalConfig renders the bare-metal cluster config from the raw base config. The variables
+// in the base config are expanded first, then the overlay of profile is merged onto it, and the values
+// that set in command line are applied at last, e.g. 'cluster.datanode.replicas=3'.
+//
+// The variables are resolved from vars, and then from the environment. The values without the 'cluster'
+// or 'etcd' prefix are applied onto the cluster, which is the same as the values of kubernetes cluster.
+func RenderBareMetalConfig(base []byte, profile string, values []string, vars map[string]string) (*BareMetalClusterConfig, error) {
+	root, err := renderBareMetalConfig(base, profile, values, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg BareMetalClusterConfig
+	if err = root.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// renderBareMetalConfig renders the bare-metal cluster config into the node tree of YAML. The nodes from
+// the base config keep their lines, so that the problems of rendered config can be located in the base config.
+func renderBareMetalConfig(base []byte, profile string, values []string, vars map[string]string) (*yaml.Node, error) {
+	expanded, err := expandVariables(base, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseMapping(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(profile) > 0 {
+		overlay, ok := bareMetalProfiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile '%s', available profiles: %s",
+				profile, strings.Join(BareMetalProfiles(), ", "))
+		}
+
+		profileRoot, err := parseMapping([]byte(overlay))
+		if err != nil {
+			return nil, err
+		}
+		resetLines(profileRoot)
+		mergeNode(root, profileRoot)
+	}
+
+	for _, raw := range values {
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("cannot parse empty config values")
+		}
+		for _, value := range strings.Split(raw, ",") {
+			if err = setValue(root, strings.TrimSpace(value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// expandVariables replaces the variables in the raw config with their values.
+func expandVariables(raw []byte, vars map[string]string) ([]byte, error) {
+	var missing []string
+	expanded := variablePattern.ReplaceAllFunc(raw, func(match []byte) []byte {
+		name := string(variablePattern.FindSubmatch(match)[1])
+		if val, ok := vars[name]; ok {
+			return []byte(val)
+		}
+		if val, ok := os.LookupEnv(name); ok {
+			return []byte(val)
+		}
+		missing = append(missing, name)
+		return match
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined variables in config: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// parseMapping parses the raw YAML into a mapping node, the empty YAML is parsed into an empty mapping.
+func parseMapping(raw []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: config should be a mapping", root.Line)
+	}
+	return root, nil
+}
+
+// mergeNode merges the src mapping onto the dst mapping recursively, the values of src override the values
+// of dst with the same key, unless both of them are mappings.
+func mergeNode(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+
+		idx := mappingIndex(dst, key.Value)
+		if idx < 0 {
+			dst.Content = append(dst.Content, key, val)
+			continue
+		}
+		if val.Kind == yaml.MappingNode && dst.Content[idx].Kind == yaml.MappingNode {
+			mergeNode(dst.Content[idx], val)
+			continue
+		}
+		dst.Content[idx] = val
+	}
+}
+
+// setValue sets the value in the form of 'key1.key2=val' onto the root mapping, the missing mappings in the
+// path are created, and the items of sequence are indexed by number, e.g. 'cluster.datanodeGroups.0.replicas=2'.
+func setValue(root *yaml.Node, value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || len(kv[0]) == 0 {
+		return fmt.Errorf("invalid config value '%s', it should be in the form of 'key=value'", value)
+	}
+
+	keys := strings.Split(kv[0], ".")
+	if keys[0] != configCluster && keys[0] != configEtcd {
+		keys = append([]string{configCluster}, keys...)
+	}
+
+	val, err := parseValue(kv[1])
+	if err != nil {
+		return fmt.Errorf("invalid value of '%s': %v", kv[0], err)
+	}
+
+	node := root
+	for i, key := range keys {
+		var idx int
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			if idx = mappingIndex(node, key); idx < 0 {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, nullNode())
+				idx = len(node.Content) - 1
+			}
+		case yaml.SequenceNode:
+			if idx, err = strconv.Atoi(key); err != nil || idx < 0 || idx >= len(node.Content) {
+				return fmt.Errorf("invalid index '%s' of '%s'", key, strings.Join(keys[:i], "."))
+			}
+		default:
+			return fmt.Errorf("'%s' of '%s' is not a mapping or sequence", strings.Join(keys[:i], "."), kv[0])
+		}
+
+		if i == len(keys)-1 {
+			node.Content[idx] = val
+			break
+		}
+		if isNullNode(node.Content[idx]) {
+			node.Content[idx] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		node = node.Content[idx]
+	}
+
+	return nil
+}
+
+// parseValue parses the value that set in command line as YAML, so the type of value is kept, e.g. '3' is an integer.
+func parseValue(raw string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	}
+
+	resetLines(doc.Content[0])
+	return doc.Content[0], nil
+}
+
+// mappingIndex returns the index of the value of key in the mapping node, or -1 if the key is not found.
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// resetLines clears the lines of the node and its children, which are not from the base config.
+func resetLines(node *yaml.Node) {
+	node.Line, node.Column = 0, 0
+	for _, child := range node.Content {
+		resetLines(child)
+	}
+}
+
+func nullNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+}
+
+func isNullNode(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const baseConfig = `
+cluster:
+  artifact:
+    version: ${GTCTL_TEST_VERSION}
+  frontend:
+    replicas: 1
+    httpAddr: 0.0.0.0:4000
+  meta:
+    replicas: 1
+    serverAddr: 0.0.0.0:3002
+  datanode:
+    replicas: 3
+  datanodeGroups:
+    - name: hot
+      replicas: 1
+etcd:
+  artifact:
+    version: v3.5.7
+`
+
+func TestRenderBareMetalConfig(t *testing.T) {
+	cfg, err := RenderBareMetalConfig([]byte(baseConfig), ProfileHA, []string{
+		"cluster.frontend.httpAddr=0.0.0.0:5000,datanode.replicas=5",
+		"cluster.datanodeGroups.0.replicas=2",
+		"etcd.artifact.version=v3.5.9",
+	}, map[string]string{"GTCTL_TEST_VERSION": "v0.9.0"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.9.0", cfg.Cluster.Artifact.Version)
+	assert.Equal(t, 1, cfg.Cluster.Frontend.Replicas)
+	assert.Equal(t, "0.0.0.0:5000", cfg.Cluster.Frontend.HTTPAddr)
+	assert.Equal(t, 3, cfg.Cluster.MetaSrv.Replicas)
+	assert.Equal(t, "0.0.0.0:3002", cfg.Cluster.MetaSrv.ServerAddr)
+	assert.Equal(t, 5, cfg.Cluster.Datanode.Replicas)
+	assert.Equal(t, 2, cfg.Cluster.DatanodeGroups[0].Replicas)
+	assert.Equal(t, "v3.5.9", cfg.Etcd.Artifact.Version)
+	assert.Nil(t, cfg.Cluster.Flownode)
+
+	cfg, err = RenderBareMetalConfig([]byte(baseConfig), ProfileFlow, nil, map[string]string{"GTCTL_TEST_VERSION": "latest"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cfg.Cluster.Flownode.Replicas)
+	assert.Equal(t, 3, cfg.Cluster.Datanode.Replicas)
+}
+
+func TestRenderBareMetalConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		values  []string
+		vars    map[string]string
+	}{
+		{name: "undefined variable"},
+		{name: "unknown profile", profile: "large", vars: map[string]string{"GTCTL_TEST_VERSION": "latest"}},
+		{name: "missing value", values: []string{"cluster.frontend.replicas"}, vars: map[string]string{"GTCTL_TEST_VERSION": "latest"}},
+		{name: "index out of range", values: []string{"cluster.datanodeGroups.1.replicas=2"}, vars: map[string]string{"GTCTL_TEST_VERSION": "latest"}},
+		{name: "set on scalar", values: []string{"cluster.frontend.replicas.count=2"}, vars: map[string]string{"GTCTL_TEST_VERSION": "latest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderBareMetalConfig([]byte(baseConfig), tt.profile, tt.values, tt.vars)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -30,10 +30,7 @@ func ValidateConfig(config *BareMetalClusterConfig) error {
 		return fmt.Errorf("no config to validate")
 	}
 
-	validate = validator.New()
-
-	// Register custom validation method for Artifact.
-	validate.RegisterStructValidation(ValidateArtifact, Artifact{})
+	validate = newValidator()
 
 	err := validate.Struct(config)
 	if err != nil {
@@ -43,9 +40,143 @@ func ValidateConfig(config *BareMetalClusterConfig) error {
 	return nil
 }
 
+// newValidator creates the validator with the custom validation methods registered.
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Register custom validation method for Artifact.
+	v.RegisterStructValidation(ValidateArtifact, Artifact{})
+
+	// Register custom validation method for WAL.
+	v.RegisterStructValidation(ValidateWAL, WAL{})
+
+	// Register custom validation method for the components.
+	v.RegisterStructValidation(ValidateComponents, BareMetalClusterComponentsConfig{})
+
+	// Register custom validation method for the hosts.
+	v.RegisterStructValidation(ValidateHosts, BareMetalClusterConfig{})
+
+	return v
+}
+
 func ValidateArtifact(sl validator.StructLevel) {
 	artifact := sl.Current().Interface().(Artifact)
 	if len(artifact.Version) == 0 && len(artifact.Local) == 0 {
 		sl.ReportError(sl.Current().Interface(), "Artifact", "Version/Local", "", "")
 	}
 }
+
+func ValidateWAL(sl validator.StructLevel) {
+	wal := sl.Current().Interface().(WAL)
+	if wal.Provider != WALProviderKafka {
+		return
+	}
+	if wal.Kafka == nil || (len(wal.Kafka.BrokerEndpoints) == 0 && wal.Kafka.Embedded == nil) {
+		sl.ReportError(sl.Current().Interface(), "Kafka", "BrokerEndpoints/Embedded", "", "")
+	}
+}
+
+// ValidateComponents validates the options that depend on more than one component.
+func ValidateComponents(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(BareMetalClusterComponentsConfig)
+	validateDatanodeGroups(sl, cfg)
+	validateRegionFailover(sl, cfg)
+}
+
+// validateRegionFailover validates that the region failover is enabled with the Kafka WAL,
+// since the regions can't be recovered from the local WAL of failed datanodes.
+func validateRegionFailover(sl validator.StructLevel, cfg BareMetalClusterComponentsConfig) {
+	if cfg.MetaSrv == nil || !cfg.MetaSrv.EnableRegionFailover {
+		return
+	}
+	if cfg.WAL == nil || cfg.WAL.Provider != WALProviderKafka {
+		sl.ReportError(cfg.MetaSrv.EnableRegionFailover, "MetaSrv.EnableRegionFailover",
+			"EnableRegionFailover", "kafka_wal", "")
+	}
+}
+
+// validateDatanodeGroups validates that the names of datanode groups are unique,
+// and the node ids of all the datanodes are not overlapped.
+func validateDatanodeGroups(sl validator.StructLevel, cfg BareMetalClusterComponentsConfig) {
+	if len(cfg.DatanodeGroups) == 0 || cfg.Datanode == nil {
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, group := range cfg.DatanodeGroups {
+		if group == nil {
+			// The nil group has been reported by field validation.
+			return
+		}
+		if names[group.Name] {
+			sl.ReportError(cfg.DatanodeGroups, "DatanodeGroups", "Name", "unique", group.Name)
+		}
+		names[group.Name] = true
+	}
+
+	nodeIDs := make(map[int]bool)
+	for i := 0; i < cfg.Datanode.Replicas; i++ {
+		nodeIDs[cfg.Datanode.NodeID+i] = true
+	}
+	for i, first := range cfg.DatanodeGroupNodeIDs() {
+		for j := 0; j < cfg.DatanodeGroups[i].Replicas; j++ {
+			if nodeIDs[first+j] {
+				sl.ReportError(cfg.DatanodeGroups, "DatanodeGroups", "NodeID", "overlapped", cfg.DatanodeGroups[i].Name)
+				return
+			}
+			nodeIDs[first+j] = true
+		}
+	}
+}
+
+// ValidateHosts validates that the names of hosts are unique, the components are placed on the known hosts,
+// and the multi-host mode is not used with the components that only run on the local host.
+func ValidateHosts(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(BareMetalClusterConfig)
+	if cfg.Cluster == nil {
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, host := range cfg.Hosts {
+		if host == nil {
+			// The nil host has been reported by field validation.
+			return
+		}
+		if names[host.Name] {
+			sl.ReportError(cfg.Hosts, "Hosts", "Name", "unique", host.Name)
+		}
+		names[host.Name] = true
+	}
+
+	for component, hosts := range cfg.Cluster.placements() {
+		for _, host := range hosts {
+			if !names[host] {
+				sl.ReportError(hosts, component+".Hosts", "Hosts", "known_host", host)
+			}
+		}
+	}
+
+	if len(cfg.Hosts) == 0 {
+		return
+	}
+	if cfg.Cluster.Standalone != nil {
+		sl.ReportError(cfg.Cluster.Standalone, "Standalone", "Standalone", "excluded_with_hosts", "")
+	}
+	if cfg.Cluster.WAL != nil && cfg.Cluster.WAL.Kafka != nil && cfg.Cluster.WAL.Kafka.Embedded != nil {
+		sl.ReportError(cfg.Cluster.WAL.Kafka.Embedded, "WAL.Kafka.Embedded", "Embedded", "excluded_with_hosts", "")
+	}
+	for component, hooks := range cfg.Cluster.hooks() {
+		if hooks != nil {
+			sl.ReportError(hooks, component+".Hooks", "Hooks", "excluded_with_hosts", "")
+		}
+	}
+	for component, resources := range cfg.Cluster.resources() {
+		if resources != nil {
+			sl.ReportError(resources, component+".Resources", "Resources", "excluded_with_hosts", "")
+		}
+	}
+	if cfg.Cluster.AutoPortAllocation {
+		sl.ReportError(cfg.Cluster.AutoPortAllocation, "AutoPortAllocation", "AutoPortAllocation", "excluded_with_hosts", "")
+	}
+}
@@ -52,6 +52,53 @@ func TestValidateConfig(t *testing.T) {
 				"Config.Cluster.Datanode.Replicas",
 			},
 		},
+		{
+			name:   "invalid_configs",
+			expect: false,
+			errKey: []string{
+				"Config.Cluster.Frontend.Configs",
+			},
+		},
+		{
+			name:   "invalid_wal",
+			expect: false,
+			errKey: []string{
+				"Config.Cluster.WAL.Kafka",
+			},
+		},
+		{
+			name:   "invalid_backend_storage",
+			expect: false,
+			errKey: []string{
+				"Config.Cluster.MetaSrv.BackendStorage.Kind",
+			},
+		},
+		{
+			name:   "invalid_datanode_groups",
+			expect: false,
+			errKey: []string{
+				"Config.Cluster.DatanodeGroups",
+				"'unique'",
+				"'overlapped'",
+			},
+		},
+		{
+			name:   "invalid_region_failover",
+			expect: false,
+			errKey: []string{
+				"Config.Cluster.MetaSrv.EnableRegionFailover",
+			},
+		},
+		{
+			name:   "invalid_hosts",
+			expect: false,
+			errKey: []string{
+				"Config.Hosts",
+				"'unique'",
+				"'known_host'",
+				"Config.Datanode.Hooks",
+			},
+		},
 		{
 			name:   "invalid_artifact",
 			expect: false,
@@ -0,0 +1,587 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// BenchWorkloadCPUOnly is the workload of TSBS that every simulated host reports 10 cpu metrics
+// into the 'cpu' table in each sample interval.
+const BenchWorkloadCPUOnly = "cpu-only"
+
+const (
+	DefaultBenchScale         = 100
+	DefaultBenchDuration      = 5 * time.Minute
+	DefaultBenchWorkers       = 4
+	DefaultBenchBatchSize     = 1000
+	DefaultBenchQueryInterval = time.Second
+	DefaultBenchDatabase      = "gtctl_bench"
+
+	// benchSampleInterval is the simulated interval between the samples of one host, like TSBS.
+	benchSampleInterval = 10 * time.Second
+
+	// benchProgressInterval is the interval of logging the progress of ingestion.
+	benchProgressInterval = 10 * time.Second
+
+	// benchTable is the table that the cpu-only workload is written into.
+	benchTable = "cpu"
+)
+
+// benchCPUFields are the fields of each row of the cpu-only workload.
+var benchCPUFields = []string{
+	"usage_user", "usage_system", "usage_idle", "usage_nice", "usage_iowait",
+	"usage_irq", "usage_softirq", "usage_steal", "usage_guest", "usage_guest_nice",
+}
+
+// benchHostTags are the values of the tags of simulated hosts, which are picked by the host index.
+var benchHostTags = []struct {
+	key    string
+	values []string
+}{
+	{"region", []string{"us-east-1", "us-west-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "sa-east-1"}},
+	{"datacenter", []string{"a", "b", "c"}},
+	{"rack", []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+	{"os", []string{"Ubuntu16.10", "Ubuntu16.04LTS", "Ubuntu15.10"}},
+	{"arch", []string{"x64", "x86"}},
+	{"team", []string{"SF", "NYC", "LON", "CHI"}},
+	{"service", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}},
+	{"service_version", []string{"0", "1"}},
+	{"service_environment", []string{"production", "staging", "test"}},
+}
+
+// BenchOptions is the load that is generated against a GreptimeDB cluster.
+type BenchOptions struct {
+	// Database is created if it doesn't exist, the data of workload is left in it after the benchmark.
+	Database string
+
+	// Workload is the kind of generated data and queries, only BenchWorkloadCPUOnly is supported now.
+	Workload string
+
+	// Scale is the number of simulated hosts.
+	Scale int
+
+	// Duration is how long the load is generated for.
+	Duration time.Duration
+
+	// Workers is the number of concurrent writers, each of them writes the samples of a share of hosts.
+	Workers int
+
+	// BatchSize is the max number of rows written by one request.
+	BatchSize int
+
+	// QueryInterval is the interval between the queries issued during the ingestion, no query
+	// is issued if it's negative.
+	QueryInterval time.Duration
+}
+
+// BenchReport is the result of a benchmark.
+type BenchReport struct {
+	Workload        string  `json:"workload" yaml:"workload"`
+	Scale           int     `json:"scale" yaml:"scale"`
+	DurationSeconds float64 `json:"durationSeconds" yaml:"durationSeconds"`
+
+	// Rows is the number of written rows, and Metrics is the number of written field values.
+	Rows             int64   `json:"rows" yaml:"rows"`
+	Metrics          int64   `json:"metrics" yaml:"metrics"`
+	RowsPerSecond    float64 `json:"rowsPerSecond" yaml:"rowsPerSecond"`
+	MetricsPerSecond float64 `json:"metricsPerSecond" yaml:"metricsPerSecond"`
+	WriteErrors      int64   `json:"writeErrors" yaml:"writeErrors"`
+
+	// Write is the latency of write requests, and Queries are the latencies of each kind of query.
+	Write   *BenchLatency   `json:"write" yaml:"write"`
+	Queries []*BenchLatency `json:"queries" yaml:"queries"`
+}
+
+// BenchLatency is the latency statistics of a kind of request in milliseconds, the failed requests are not counted in.
+type BenchLatency struct {
+	Name   string  `json:"name" yaml:"name"`
+	Count  int     `json:"count" yaml:"count"`
+	Errors int     `json:"errors" yaml:"errors"`
+	Mean   float64 `json:"mean" yaml:"mean"`
+	P50    float64 `json:"p50" yaml:"p50"`
+	P95    float64 `json:"p95" yaml:"p95"`
+	P99    float64 `json:"p99" yaml:"p99"`
+	Max    float64 `json:"max" yaml:"max"`
+}
+
+// Bench generates the load of workload through the HTTP API of the frontend serving HTTP on addr: the samples are written
+// by the InfluxDB line protocol while the queries of TSBS are issued periodically on the written data. It runs until the
+// duration elapses or ctx is done, and reports the ingest throughput and the latencies of writes and queries.
+func Bench(ctx context.Context, addr string, options *BenchOptions, l logger.Logger) (*BenchReport, error) {
+	o := options.withDefaults()
+	if o.Workload != BenchWorkloadCPUOnly {
+		return nil, fmt.Errorf("unsupported workload '%s', only '%s' is supported", o.Workload, BenchWorkloadCPUOnly)
+	}
+	if o.Scale <= 0 || o.Workers <= 0 || o.BatchSize <= 0 {
+		return nil, fmt.Errorf("scale, workers and batch size should be positive")
+	}
+	if o.Workers > o.Scale {
+		o.Workers = o.Scale
+	}
+
+	client := &http.Client{Timeout: httpSQLRequestTimeout}
+	if _, err := requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+		url.Values{"db": []string{verifyDatabase}},
+		url.Values{"sql": []string{"CREATE DATABASE IF NOT EXISTS " + o.Database}}); err != nil {
+		return nil, fmt.Errorf("error creating database '%s': %v", o.Database, err)
+	}
+
+	l.V(0).Infof("Generating the '%s' workload of %d hosts into database '%s' for %s...", o.Workload, o.Scale, o.Database, o.Duration)
+
+	runCtx, cancel := context.WithTimeout(ctx, o.Duration)
+	defer cancel()
+
+	var (
+		b = &bencher{
+			addr:     addr,
+			client:   client,
+			options:  &o,
+			start:    time.Now().Truncate(time.Hour),
+			progress: make([]int64, o.Workers),
+			write:    newBenchRecorder("write"),
+		}
+		queries = newBenchQueries()
+		wg      sync.WaitGroup
+	)
+
+	started := time.Now()
+	for i := 0; i < o.Workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			b.ingest(runCtx, worker)
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.logProgress(runCtx, started, l)
+	}()
+	if o.QueryInterval >= 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.query(runCtx, queries)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	rows := atomic.LoadInt64(&b.rows)
+	if rows == 0 && b.write.errors > 0 {
+		return nil, fmt.Errorf("no row is written: %v", b.write.lastErr)
+	}
+
+	report := &BenchReport{
+		Workload:        o.Workload,
+		Scale:           o.Scale,
+		DurationSeconds: elapsed.Seconds(),
+		Rows:            rows,
+		Metrics:         rows * int64(len(benchCPUFields)),
+		WriteErrors:     int64(b.write.errors),
+		Write:           b.write.latency(),
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		report.RowsPerSecond = float64(report.Rows) / seconds
+		report.MetricsPerSecond = float64(report.Metrics) / seconds
+	}
+	for _, q := range queries {
+		report.Queries = append(report.Queries, q.recorder.latency())
+	}
+	if b.write.errors > 0 {
+		l.Warnf("%d write requests failed, the last error: %v", b.write.errors, b.write.lastErr)
+	}
+
+	return report, nil
+}
+
+// BenchForwarded runs the benchmark on a GreptimeDB cluster through its HTTP API that is port-forwarded to local.
+func BenchForwarded(ctx context.Context, port, service string, options *BenchOptions, l logger.Logger) (*BenchReport, error) {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return nil, err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return nil, err
+	}
+
+	return Bench(ctx, addr, options, l)
+}
+
+// withDefaults returns the options with the unset ones defaulted.
+func (o *BenchOptions) withDefaults() BenchOptions {
+	options := *o
+	if len(options.Database) == 0 {
+		options.Database = DefaultBenchDatabase
+	}
+	if len(options.Workload) == 0 {
+		options.Workload = BenchWorkloadCPUOnly
+	}
+	if options.Scale == 0 {
+		options.Scale = DefaultBenchScale
+	}
+	if options.Duration <= 0 {
+		options.Duration = DefaultBenchDuration
+	}
+	if options.Workers == 0 {
+		options.Workers = DefaultBenchWorkers
+	}
+	if options.BatchSize == 0 {
+		options.BatchSize = DefaultBenchBatchSize
+	}
+	if options.QueryInterval == 0 {
+		options.QueryInterval = DefaultBenchQueryInterval
+	}
+	return options
+}
+
+// bencher is the state of a running benchmark.
+type bencher struct {
+	addr    string
+	client  *http.Client
+	options *BenchOptions
+
+	// start is the simulated time of the first samples, and progress is the simulated time
+	// in unix milliseconds that each worker has written the samples before.
+	start    time.Time
+	progress []int64
+
+	rows  int64
+	write *benchRecorder
+}
+
+// ingest writes the samples of the hosts of worker, e.g. the ones whose index modulo the number of workers is
+// worker, in batches until ctx is done. The simulated time advances by benchSampleInterval once all of the
+// hosts have been sampled, so the samples are written as fast as the cluster accepts them.
+func (b *bencher) ingest(ctx context.Context, worker int) {
+	var hosts []*benchHost
+	for i := worker; i < b.options.Scale; i += b.options.Workers {
+		hosts = append(hosts, newBenchHost(i))
+	}
+
+	var (
+		batch strings.Builder
+		rows  int
+	)
+	for ts := b.start; ; ts = ts.Add(benchSampleInterval) {
+		for _, host := range hosts {
+			host.writeLine(&batch, ts)
+			rows++
+			if rows < b.options.BatchSize {
+				continue
+			}
+			if !b.flush(ctx, batch.String(), rows) {
+				return
+			}
+			batch.Reset()
+			rows = 0
+		}
+		if rows > 0 {
+			if !b.flush(ctx, batch.String(), rows) {
+				return
+			}
+			batch.Reset()
+			rows = 0
+		}
+		atomic.StoreInt64(&b.progress[worker], ts.Add(benchSampleInterval).UnixMilli())
+	}
+}
+
+// flush writes the lines of rows, and returns false once ctx is done.
+func (b *bencher) flush(ctx context.Context, lines string, rows int) bool {
+	begin := time.Now()
+	err := writeLineProtocol(ctx, b.client, b.addr, b.options.Database, lines)
+	if ctx.Err() != nil {
+		// The request is interrupted by the end of benchmark.
+		return false
+	}
+	b.write.record(time.Since(begin), err)
+	if err == nil {
+		atomic.AddInt64(&b.rows, int64(rows))
+	}
+	return true
+}
+
+// written returns the simulated time before which all of the workers have written the samples.
+func (b *bencher) written() time.Time {
+	written := int64(math.MaxInt64)
+	for i := range b.progress {
+		if p := atomic.LoadInt64(&b.progress[i]); p < written {
+			written = p
+		}
+	}
+	return time.UnixMilli(written)
+}
+
+// query issues the queries in turn every QueryInterval once the samples of the first sample interval are written.
+func (b *bencher) query(ctx context.Context, queries []*benchQuery) {
+	var (
+		r      = rand.New(rand.NewSource(time.Now().UnixNano()))
+		ticker = time.NewTicker(b.options.QueryInterval)
+		next   int
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		end := b.written()
+		if !end.After(b.start) {
+			continue
+		}
+
+		q := queries[next%len(queries)]
+		next++
+		host := fmt.Sprintf("host_%d", r.Intn(b.options.Scale))
+		begin := time.Now()
+		_, err := requestHTTPSQL(ctx, b.client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", b.addr),
+			url.Values{"db": []string{b.options.Database}}, url.Values{"sql": []string{q.sql(host, end)}})
+		if ctx.Err() != nil {
+			return
+		}
+		q.recorder.record(time.Since(begin), err)
+	}
+}
+
+// logProgress logs the ingested rows and the throughput every benchProgressInterval until ctx is done.
+func (b *bencher) logProgress(ctx context.Context, started time.Time, l logger.Logger) {
+	ticker := time.NewTicker(benchProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rows := atomic.LoadInt64(&b.rows)
+			l.V(0).Infof("Written %d rows in %s (%.1f rows/s)", rows,
+				time.Since(started).Truncate(time.Second), float64(rows)/time.Since(started).Seconds())
+		}
+	}
+}
+
+// writeLineProtocol writes the lines of InfluxDB line protocol in milliseconds precision into the database.
+func writeLineProtocol(ctx context.Context, client *http.Client, addr, database, lines string) error {
+	params := url.Values{"db": []string{database}, "precision": []string{"ms"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("http://%s/v1/influxdb/write?%s", addr, params.Encode()), strings.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode/100 != 2 {
+		raw, _ := io.ReadAll(rsp.Body)
+		return fmt.Errorf("unexpected response (%s): %s", rsp.Status, strings.TrimSpace(string(raw)))
+	}
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	return nil
+}
+
+// benchHost is a simulated host of the cpu-only workload, whose metrics are random walks in [0, 100].
+type benchHost struct {
+	tags   string
+	values []float64
+	rand   *rand.Rand
+}
+
+func newBenchHost(index int) *benchHost {
+	host := &benchHost{
+		rand:   rand.New(rand.NewSource(int64(index))),
+		values: make([]float64, len(benchCPUFields)),
+	}
+
+	var tags strings.Builder
+	fmt.Fprintf(&tags, "%s,hostname=host_%d", benchTable, index)
+	for _, tag := range benchHostTags {
+		fmt.Fprintf(&tags, ",%s=%s", tag.key, tag.values[host.rand.Intn(len(tag.values))])
+	}
+	host.tags = tags.String()
+
+	for i := range host.values {
+		host.values[i] = host.rand.Float64() * 100
+	}
+	return host
+}
+
+// writeLine advances the metrics of host and writes them as a line of InfluxDB line protocol at ts.
+func (h *benchHost) writeLine(w *strings.Builder, ts time.Time) {
+	w.WriteString(h.tags)
+	for i, field := range benchCPUFields {
+		h.values[i] = math.Max(0, math.Min(100, h.values[i]+h.rand.Float64()*2-1))
+		if i == 0 {
+			w.WriteByte(' ')
+		} else {
+			w.WriteByte(',')
+		}
+		w.WriteString(field)
+		w.WriteByte('=')
+		w.WriteString(strconv.FormatFloat(h.values[i], 'f', 2, 64))
+	}
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatInt(ts.UnixMilli(), 10))
+	w.WriteByte('\n')
+}
+
+// benchQuery is a kind of query of TSBS on the cpu table.
+type benchQuery struct {
+	// sql returns the query on the host in the window that ends at end.
+	sql      func(host string, end time.Time) string
+	recorder *benchRecorder
+}
+
+// newBenchQueries returns the queries of the cpu-only workload, which are named after the ones of TSBS.
+func newBenchQueries() []*benchQuery {
+	between := func(end time.Time, window time.Duration) string {
+		return fmt.Sprintf("greptime_timestamp >= %s AND greptime_timestamp < %s",
+			benchTimeLiteral(end.Add(-window)), benchTimeLiteral(end))
+	}
+	maxAll := make([]string, 0, len(benchCPUFields))
+	for _, field := range benchCPUFields {
+		maxAll = append(maxAll, fmt.Sprintf("max(%s)", field))
+	}
+
+	return []*benchQuery{
+		{
+			recorder: newBenchRecorder("single-groupby-1-1-1"),
+			sql: func(host string, end time.Time) string {
+				return fmt.Sprintf("SELECT date_bin(INTERVAL '1 minute', greptime_timestamp) AS minute, max(usage_user) FROM %s "+
+					"WHERE hostname = %s AND %s GROUP BY minute ORDER BY minute",
+					benchTable, quoteSQLString(host), between(end, time.Hour))
+			},
+		},
+		{
+			recorder: newBenchRecorder("cpu-max-all-1"),
+			sql: func(host string, end time.Time) string {
+				return fmt.Sprintf("SELECT date_bin(INTERVAL '1 hour', greptime_timestamp) AS hour, %s FROM %s "+
+					"WHERE hostname = %s AND %s GROUP BY hour ORDER BY hour",
+					strings.Join(maxAll, ", "), benchTable, quoteSQLString(host), between(end, 8*time.Hour))
+			},
+		},
+		{
+			recorder: newBenchRecorder("double-groupby-1"),
+			sql: func(_ string, end time.Time) string {
+				return fmt.Sprintf("SELECT date_bin(INTERVAL '1 hour', greptime_timestamp) AS hour, hostname, avg(usage_user) FROM %s "+
+					"WHERE %s GROUP BY hour, hostname ORDER BY hour, hostname",
+					benchTable, between(end, 12*time.Hour))
+			},
+		},
+		{
+			recorder: newBenchRecorder("high-cpu-1"),
+			sql: func(host string, end time.Time) string {
+				return fmt.Sprintf("SELECT * FROM %s WHERE usage_user > 90.0 AND hostname = %s AND %s",
+					benchTable, quoteSQLString(host), between(end, 12*time.Hour))
+			},
+		},
+	}
+}
+
+// benchTimeLiteral returns the literal of the timestamp in UTC with milliseconds.
+func benchTimeLiteral(ts time.Time) string {
+	return quoteSQLString(ts.UTC().Format("2006-01-02 15:04:05.000"))
+}
+
+// benchRecorder records the latencies of the successful requests, and the number and the last error of the failed ones.
+type benchRecorder struct {
+	name      string
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	lastErr   error
+}
+
+func newBenchRecorder(name string) *benchRecorder {
+	return &benchRecorder{name: name}
+}
+
+func (r *benchRecorder) record(latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors++
+		r.lastErr = err
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+}
+
+// latency returns the statistics of the recorded latencies, the percentiles are of the nearest rank.
+func (r *benchRecorder) latency() *BenchLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &BenchLatency{Name: r.name, Count: len(r.latencies), Errors: r.errors}
+	if len(r.latencies) == 0 {
+		return result
+	}
+
+	sorted := make([]time.Duration, len(r.latencies))
+	copy(sorted, r.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	milliseconds := func(d time.Duration) float64 {
+		return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return milliseconds(sorted[rank])
+	}
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	result.Mean = milliseconds(total / time.Duration(len(sorted)))
+	result.P50 = percentile(0.50)
+	result.P95 = percentile(0.95)
+	result.P99 = percentile(0.99)
+	result.Max = milliseconds(sorted[len(sorted)-1])
+
+	return result
+}
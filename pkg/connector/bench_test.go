@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestBench(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		lines      int64
+		statements []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/influxdb/write":
+			assert.Equal(t, "bench", r.URL.Query().Get("db"))
+			assert.Equal(t, "ms", r.URL.Query().Get("precision"))
+			raw, _ := io.ReadAll(r.Body)
+			lines += int64(strings.Count(string(raw), "\n"))
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/sql":
+			statements = append(statements, r.FormValue("sql"))
+			_, _ = w.Write([]byte(`{"output":[{"affectedrows":0}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+	l := logger.New(io.Discard, 0)
+
+	report, err := Bench(context.Background(), addr, &BenchOptions{
+		Database:      "bench",
+		Scale:         10,
+		Duration:      500 * time.Millisecond,
+		Workers:       3,
+		BatchSize:     4,
+		QueryInterval: 20 * time.Millisecond,
+	}, l)
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, BenchWorkloadCPUOnly, report.Workload)
+	assert.Equal(t, 10, report.Scale)
+	assert.Greater(t, report.Rows, int64(0))
+	// The rows of the requests interrupted by the end of benchmark may be written but not counted.
+	assert.LessOrEqual(t, report.Rows, lines)
+	assert.Equal(t, report.Rows*10, report.Metrics)
+	assert.Zero(t, report.WriteErrors)
+	assert.Equal(t, "write", report.Write.Name)
+	assert.Greater(t, report.Write.Count, 0)
+	assert.Len(t, report.Queries, 4)
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS bench", statements[0])
+	assert.Greater(t, len(statements), 1)
+	assert.Contains(t, statements[1], "FROM cpu WHERE hostname = 'host_")
+}
+
+func TestBenchUnsupportedWorkload(t *testing.T) {
+	_, err := Bench(context.Background(), "127.0.0.1:0", &BenchOptions{Workload: "devops"}, logger.New(io.Discard, 0))
+	assert.EqualError(t, err, "unsupported workload 'devops', only 'cpu-only' is supported")
+}
+
+func TestBenchHostWriteLine(t *testing.T) {
+	host := newBenchHost(7)
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var w strings.Builder
+	host.writeLine(&w, ts)
+	host.writeLine(&w, ts.Add(benchSampleInterval))
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	for i, line := range lines {
+		parts := strings.Split(line, " ")
+		assert.Len(t, parts, 3)
+		assert.True(t, strings.HasPrefix(parts[0], "cpu,hostname=host_7,region="))
+		assert.Len(t, strings.Split(parts[0], ","), 11)
+		assert.Len(t, strings.Split(parts[1], ","), 10)
+		assert.True(t, strings.HasPrefix(parts[1], "usage_user="))
+		assert.Equal(t, []string{"1704067200000", "1704067210000"}[i], parts[2])
+	}
+	// The tags of a host are stable.
+	assert.Equal(t, host.tags, newBenchHost(7).tags)
+}
+
+func TestBenchRecorderLatency(t *testing.T) {
+	r := newBenchRecorder("query")
+	for i := 100; i >= 1; i-- {
+		r.record(time.Duration(i)*time.Millisecond, nil)
+	}
+	r.record(time.Second, assert.AnError)
+
+	assert.Equal(t, &BenchLatency{
+		Name:   "query",
+		Count:  100,
+		Errors: 1,
+		Mean:   50.5,
+		P50:    50,
+		P95:    95,
+		P99:    99,
+		Max:    100,
+	}, r.latency())
+	assert.Equal(t, &BenchLatency{Name: "empty"}, newBenchRecorder("empty").latency())
+}
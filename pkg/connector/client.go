@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// addrWaitTimeout is the timeout of waiting for the forwarded or connected address to be reachable.
+const addrWaitTimeout = 30 * time.Second
+
+// FrontendServiceName returns the name of the frontend service of cluster that is created by the operator.
+func FrontendServiceName(clusterName string) string {
+	return clusterName + "-frontend"
+}
+
+// startPortForward forwards the port of the service, e.g. the frontend service of cluster, to the same local port.
+func startPortForward(service, port string, l logger.Logger) (*exec.Cmd, error) {
+	return startServicePortForward("default", service, port, port, l)
+}
+
+// startServicePortForward forwards the remotePort of the service in namespace to the localPort.
+func startServicePortForward(namespace, service, localPort, remotePort string, l logger.Logger) (*exec.Cmd, error) {
+	cmd := kubectlPortForward(context.Background(), namespace, "svc/"+service, fmt.Sprintf("%s:%s", localPort, remotePort))
+	if err := cmd.Start(); err != nil {
+		l.Errorf("Error starting port-forwarding: %v", err)
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// stopPortForward stops the port-forwarding started by startPortForward.
+func stopPortForward(cmd *exec.Cmd, l logger.Logger) {
+	if err := cmd.Process.Kill(); err != nil && err != os.ErrProcessDone {
+		l.Errorf("Error killing port-forwarding process: %v", err)
+		return
+	}
+	_ = cmd.Wait()
+	l.V(1).Info("Shutting down port-forwarding successfully")
+}
+
+// waitForAddr waits for the TCP address to be reachable.
+func waitForAddr(addr string) error {
+	deadline := time.Now().Add(addrWaitTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("'%s' is not reachable: %v", addr, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// runClient runs the client command interactively until it exits.
+func runClient(cmd *exec.Cmd, name string, l logger.Logger) error {
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		l.Errorf("Error running %s client: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// IsClientInstalled checks whether the external client, e.g. "mysql", is installed.
+func IsClientInstalled(client string) bool {
+	_, err := exec.LookPath(client)
+	return err == nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// DefaultPromQLStep is the default resolution step of the PromQL range query.
+const DefaultPromQLStep = "15s"
+
+// ExecQuery is the SQL or PromQL query that is executed non-interactively.
+type ExecQuery struct {
+	Database string
+
+	// Only one of SQL and PromQL is set.
+	SQL    string
+	PromQL string
+
+	// Start, End and Step are the range of PromQL query, the Start and End are RFC3339
+	// or unix timestamps, which default to now, e.g. the query is evaluated at now.
+	Start string
+	End   string
+	Step  string
+
+	// Format is the output format of the results, e.g. "table", "json" or "csv".
+	Format string
+}
+
+// Exec executes the query through the HTTP API of the frontend serving HTTP on addr and writes the
+// results to out. It fails if the query fails, so the scripts can tell it by the exit code of gtctl.
+func Exec(ctx context.Context, addr string, query *ExecQuery, out io.Writer) error {
+	var (
+		client = &http.Client{Timeout: httpSQLRequestTimeout}
+		result *httpSQLResponse
+		err    error
+	)
+
+	switch {
+	case len(query.SQL) > 0 && len(query.PromQL) > 0:
+		return fmt.Errorf("only one of SQL and PromQL query can be executed")
+	case len(query.SQL) > 0:
+		result, err = requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+			url.Values{"db": []string{query.Database}}, url.Values{"sql": []string{query.SQL}})
+	case len(query.PromQL) > 0:
+		now := strconv.FormatInt(time.Now().Unix(), 10)
+		params := url.Values{
+			"db":    []string{query.Database},
+			"query": []string{query.PromQL},
+			"start": []string{valueOr(query.Start, now)},
+			"end":   []string{valueOr(query.End, now)},
+			"step":  []string{valueOr(query.Step, DefaultPromQLStep)},
+		}
+		result, err = requestHTTPSQL(ctx, client, http.MethodGet, fmt.Sprintf("http://%s/v1/promql", addr), params, nil)
+	default:
+		return fmt.Errorf("either SQL or PromQL query should be set")
+	}
+	if err != nil {
+		return err
+	}
+
+	return renderHTTPSQLOutputs(result.Output, valueOr(query.Format, OutputFormatTable), out)
+}
+
+// ExecForwarded executes the query on a GreptimeDB cluster through its HTTP API that is port-forwarded to local.
+func ExecForwarded(port, service string, query *ExecQuery, out io.Writer, l logger.Logger) error {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	return Exec(context.Background(), addr, query, out)
+}
+
+// valueOr returns the value, or the defaultValue if the value is empty.
+func valueOr(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExec(t *testing.T) {
+	var promqlParams map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sql":
+			if r.FormValue("sql") == "BAD" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":1004,"error":"Failed to parse SQL"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"output":[{"records":{"schema":{"column_schemas":[{"name":"host"},` +
+				`{"name":"cpu"}]},"rows":[["a",0.5],["b,c",null]]}},{"affectedrows":2}]}`))
+		case "/v1/promql":
+			promqlParams = make(map[string]string)
+			for k := range r.URL.Query() {
+				promqlParams[k] = r.URL.Query().Get(k)
+			}
+			_, _ = w.Write([]byte(`{"output":[{"records":{"schema":{"column_schemas":[{"name":"ts"},` +
+				`{"name":"val"}]},"rows":[[1700000000000,1.0]]}}]}`))
+		}
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name   string
+		query  ExecQuery
+		output string
+	}{
+		{
+			name:  "json",
+			query: ExecQuery{SQL: "SELECT", Format: OutputFormatJSON},
+			output: `[{"cpu":0.5,"host":"a"},{"cpu":null,"host":"b,c"}]` + "\n" +
+				`{"affectedrows":2}` + "\n",
+		},
+		{
+			name:   "csv",
+			query:  ExecQuery{SQL: "SELECT", Format: OutputFormatCSV},
+			output: "host,cpu\na,0.5\n\"b,c\",\naffectedrows\n2\n",
+		},
+		{
+			name:   "promql",
+			query:  ExecQuery{Database: "metrics", PromQL: "up", Start: "10", End: "20", Format: OutputFormatCSV},
+			output: "ts,val\n1700000000000,1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.NoError(t, Exec(context.Background(), addr, &tt.query, &out))
+			assert.Equal(t, tt.output, out.String())
+		})
+	}
+
+	assert.Equal(t, map[string]string{
+		"db": "metrics", "query": "up", "start": "10", "end": "20", "step": DefaultPromQLStep,
+	}, promqlParams)
+
+	// The failed query is returned as error.
+	err := Exec(context.Background(), addr, &ExecQuery{SQL: "BAD"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "Failed to parse SQL")
+	assert.Error(t, Exec(context.Background(), addr, &ExecQuery{}, &bytes.Buffer{}))
+}
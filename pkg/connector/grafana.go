@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"net"
+	"os/signal"
+	"syscall"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// grafanaServicePort is the port of the Grafana service that is deployed with the cluster.
+const grafanaServicePort = "80"
+
+// GrafanaServiceName returns the name of the Grafana service that is deployed with the cluster by its chart.
+func GrafanaServiceName(clusterName string) string {
+	return clusterName + "-grafana"
+}
+
+// Grafana forwards the Grafana deployed with the cluster in namespace to the local port, until gtctl is interrupted.
+func Grafana(namespace, clusterName, port string, l logger.Logger) error {
+	cmd, err := startServicePortForward(namespace, GrafanaServiceName(clusterName), port, grafanaServicePort, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	l.V(0).Infof("The Grafana of cluster '%s' is forwarded to http://%s, press Ctrl+C to stop forwarding", clusterName, addr)
+	<-ctx.Done()
+
+	return nil
+}
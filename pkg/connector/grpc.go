@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"net"
+	"os/signal"
+	"syscall"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// GRPC forwards the gRPC port of the service, e.g. the frontend service of cluster, to local, so the
+// GreptimeDB clients and SDKs can connect to it, until gtctl is interrupted.
+func GRPC(port, service string, l logger.Logger) error {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	l.V(0).Infof("The gRPC endpoint of service '%s' is forwarded to %s, connect to it by the GreptimeDB clients, "+
+		"press Ctrl+C to stop forwarding", service, addr)
+	<-ctx.Done()
+
+	return nil
+}
+
+// GRPCAt checks the gRPC endpoint of GreptimeDB on addr, which the GreptimeDB clients and SDKs can connect to.
+func GRPCAt(addr string, l logger.Logger) error {
+	if err := waitForAddr(addr); err != nil {
+		return err
+	}
+	l.V(0).Infof("The gRPC endpoint %s is reachable, connect to it by the GreptimeDB clients", addr)
+	return nil
+}
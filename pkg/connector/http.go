@@ -0,0 +1,266 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	httpSQLDefaultAddr    = "127.0.0.1"
+	httpSQLRequestTimeout = 60 * time.Second
+)
+
+// The output formats of the results of statements.
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+	OutputFormatCSV   = "csv"
+)
+
+// HTTP connects to a GreptimeDB cluster and runs the built-in SQL shell on its HTTP API.
+func HTTP(port, service, database string, l logger.Logger) error {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	return SQLShell(context.Background(), addr, database, os.Stdin, os.Stdout)
+}
+
+// httpSQLResponse is the response of the '/v1/sql' API.
+type httpSQLResponse struct {
+	Code            int             `json:"code"`
+	Error           string          `json:"error"`
+	Output          []httpSQLOutput `json:"output"`
+	ExecutionTimeMs int             `json:"execution_time_ms"`
+}
+
+// httpSQLOutput is the output of one statement, which is either the affected rows or the records.
+type httpSQLOutput struct {
+	AffectedRows *int `json:"affectedrows"`
+	Records      *struct {
+		Schema struct {
+			ColumnSchemas []struct {
+				Name string `json:"name"`
+			} `json:"column_schemas"`
+		} `json:"schema"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"records"`
+}
+
+// columns returns the names of columns of the records.
+func (o httpSQLOutput) columns() []string {
+	columns := make([]string, 0, len(o.Records.Schema.ColumnSchemas))
+	for _, column := range o.Records.Schema.ColumnSchemas {
+		columns = append(columns, column.Name)
+	}
+	return columns
+}
+
+// executeHTTPSQL executes the sql through the '/v1/sql' API of addr in the database and renders the outputs
+// in table, the execution time is rendered if timing is set.
+func executeHTTPSQL(ctx context.Context, client *http.Client, addr, database, sql string, timing bool, out io.Writer) error {
+	result, err := requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+		url.Values{"db": []string{database}}, url.Values{"sql": []string{sql}})
+	if err != nil {
+		return err
+	}
+
+	if err = renderHTTPSQLOutputs(result.Output, OutputFormatTable, out); err != nil {
+		return err
+	}
+	if timing {
+		fmt.Fprintf(out, "Time: %d ms\n", result.ExecutionTimeMs)
+	}
+
+	return nil
+}
+
+// requestHTTPSQL requests the API that responds in the format of '/v1/sql' API, e.g. '/v1/promql',
+// with the params in URL and the form in body. The error in the response is returned as the error.
+func requestHTTPSQL(ctx context.Context, client *http.Client, method, endpoint string, params, form url.Values) (*httpSQLResponse, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?"+params.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	raw, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result httpSQLResponse
+	if err = json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("unexpected response (%s): %s", rsp.Status, strings.TrimSpace(string(raw)))
+	}
+	if len(result.Error) > 0 {
+		return nil, fmt.Errorf("%s (code %d)", result.Error, result.Code)
+	}
+
+	return &result, nil
+}
+
+// renderHTTPSQLOutputs renders the outputs of statements in the format.
+func renderHTTPSQLOutputs(outputs []httpSQLOutput, format string, out io.Writer) error {
+	for _, output := range outputs {
+		var err error
+		switch format {
+		case OutputFormatTable:
+			renderTable(output, out)
+		case OutputFormatJSON:
+			err = renderJSON(output, out)
+		case OutputFormatCSV:
+			err = renderCSV(output, out)
+		default:
+			err = fmt.Errorf("unsupported output format '%s'", format)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTable renders the output in table.
+func renderTable(output httpSQLOutput, out io.Writer) {
+	if output.AffectedRows != nil {
+		fmt.Fprintf(out, "Affected Rows: %d\n", *output.AffectedRows)
+		return
+	}
+	if output.Records == nil {
+		return
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(output.columns())
+	for _, row := range output.Records.Rows {
+		values := make([]string, 0, len(row))
+		for _, value := range row {
+			values = append(values, formatHTTPSQLValue(value))
+		}
+		table.Append(values)
+	}
+	table.Render()
+	fmt.Fprintf(out, "%d rows in set\n", len(output.Records.Rows))
+}
+
+// renderJSON renders the output in one line of JSON, the records are rendered as an array
+// of objects keyed by the column names, and the affected rows as '{"affectedrows":N}'.
+func renderJSON(output httpSQLOutput, out io.Writer) error {
+	var value interface{}
+	if output.AffectedRows != nil {
+		value = map[string]int{"affectedrows": *output.AffectedRows}
+	} else if output.Records != nil {
+		columns := output.columns()
+		rows := make([]map[string]interface{}, 0, len(output.Records.Rows))
+		for _, row := range output.Records.Rows {
+			object := make(map[string]interface{}, len(row))
+			for i, v := range row {
+				if i < len(columns) {
+					object[columns[i]] = v
+				}
+			}
+			rows = append(rows, object)
+		}
+		value = rows
+	} else {
+		return nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", raw)
+	return err
+}
+
+// renderCSV renders the output in CSV with the header, the null is rendered as the empty field.
+func renderCSV(output httpSQLOutput, out io.Writer) error {
+	w := csv.NewWriter(out)
+	if output.AffectedRows != nil {
+		_ = w.Write([]string{"affectedrows"})
+		_ = w.Write([]string{strconv.Itoa(*output.AffectedRows)})
+	} else if output.Records != nil {
+		_ = w.Write(output.columns())
+		for _, row := range output.Records.Rows {
+			values := make([]string, 0, len(row))
+			for _, value := range row {
+				if value == nil {
+					values = append(values, "")
+				} else {
+					values = append(values, formatHTTPSQLValue(value))
+				}
+			}
+			_ = w.Write(values)
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// formatHTTPSQLValue formats the value decoded from JSON, the null is rendered as "NULL".
+func formatHTTPSQLValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return v
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(raw)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"os/exec"
+)
+
+// kubeConfigArgs are the args of kubectl that select the Kubernetes cluster to operate on.
+var kubeConfigArgs []string
+
+// SetKubeConfig makes the kubectl commands run by the connector target the context in kubeconfig.
+// The default kubeconfig and its current context of kubectl are used if they are empty.
+func SetKubeConfig(kubeconfig, kubeContext string) {
+	kubeConfigArgs = nil
+	if kubeconfig != "" {
+		kubeConfigArgs = append(kubeConfigArgs, "--kubeconfig", kubeconfig)
+	}
+	if kubeContext != "" {
+		kubeConfigArgs = append(kubeConfigArgs, "--context", kubeContext)
+	}
+}
+
+// kubectlPortForward returns the command of kubectl port-forward in namespace with args.
+func kubectlPortForward(ctx context.Context, namespace string, args ...string) *exec.Cmd {
+	cmdArgs := append([]string{portForward, "-n", namespace}, kubeConfigArgs...)
+	return exec.CommandContext(ctx, kubectl, append(cmdArgs, args...)...)
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	DefaultMetricsTables        = 10
+	DefaultMetricsHosts         = 10
+	DefaultMetricsRowsPerSecond = 1000
+	DefaultMetricsTablePrefix   = "demo_metrics"
+
+	// metricsWriteInterval is the interval of writing the rows of synthetic metrics.
+	metricsWriteInterval = time.Second
+
+	// metricsBatchSize is the max number of rows written by one request.
+	metricsBatchSize = 5000
+
+	// metricsProgressInterval is the interval of logging the written rows.
+	metricsProgressInterval = time.Minute
+)
+
+// metricsRegions are the values of the 'region' tag of the synthetic metrics, which are picked by the host index.
+var metricsRegions = []string{"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1"}
+
+// MetricsOptions is the synthetic metrics that are written into a GreptimeDB cluster continuously.
+type MetricsOptions struct {
+	// Database is created if it doesn't exist.
+	Database string
+
+	// The tables are named TablePrefix followed by their indexes, e.g. 'demo_metrics_0', and each of them
+	// has a series of each host, whose 'value' field is a random walk in [0, 100].
+	TablePrefix string
+	Tables      int
+	Hosts       int
+
+	// RowsPerSecond is the number of rows written per second, which are spread over the series evenly.
+	RowsPerSecond int
+
+	// Duration is how long the metrics are written for, they are written until ctx is done if it's zero.
+	Duration time.Duration
+}
+
+// SeedMetrics writes the synthetic metrics at the current time every second through the HTTP API of the frontend
+// serving HTTP on addr by the InfluxDB line protocol, so the dashboards and queries have something to show. It fails
+// if the first write fails, the later failures are only warned as the cluster may recover from them.
+func SeedMetrics(ctx context.Context, addr string, options *MetricsOptions, l logger.Logger) error {
+	o := options.withDefaults()
+	if o.Tables <= 0 || o.Hosts <= 0 || o.RowsPerSecond <= 0 {
+		return fmt.Errorf("tables, hosts and rows per second should be positive")
+	}
+
+	client := &http.Client{Timeout: httpSQLRequestTimeout}
+	if _, err := requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+		url.Values{"db": []string{verifyDatabase}},
+		url.Values{"sql": []string{"CREATE DATABASE IF NOT EXISTS " + o.Database}}); err != nil {
+		return fmt.Errorf("error creating database '%s': %v", o.Database, err)
+	}
+
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+
+	l.V(0).Infof("Writing %d rows per second into the %d tables '%s_*' of database '%s', press Ctrl+C to stop",
+		o.RowsPerSecond, o.Tables, o.TablePrefix, o.Database)
+
+	var (
+		generator = newMetricsGenerator(&o)
+		ticker    = time.NewTicker(metricsWriteInterval)
+		progress  = time.Now()
+		written   int64
+	)
+	defer ticker.Stop()
+
+	for {
+		for _, batch := range generator.batches(time.Now().Truncate(metricsWriteInterval)) {
+			err := writeLineProtocol(ctx, client, addr, o.Database, batch.lines)
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil {
+				if written == 0 {
+					return fmt.Errorf("error writing metrics: %v", err)
+				}
+				l.Warnf("Failed to write %d rows of metrics: %v", batch.rows, err)
+				continue
+			}
+			written += int64(batch.rows)
+		}
+
+		if time.Since(progress) >= metricsProgressInterval {
+			progress = time.Now()
+			l.V(0).Infof("Written %d rows of metrics", written)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.V(0).Infof("Written %d rows of metrics in total", written)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// SeedMetricsForwarded writes the synthetic metrics into a GreptimeDB cluster through its HTTP API that is port-forwarded to local.
+func SeedMetricsForwarded(ctx context.Context, port, service string, options *MetricsOptions, l logger.Logger) error {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	return SeedMetrics(ctx, addr, options, l)
+}
+
+// withDefaults returns the options with the unset ones defaulted.
+func (o *MetricsOptions) withDefaults() MetricsOptions {
+	options := *o
+	if len(options.Database) == 0 {
+		options.Database = verifyDatabase
+	}
+	if len(options.TablePrefix) == 0 {
+		options.TablePrefix = DefaultMetricsTablePrefix
+	}
+	if options.Tables == 0 {
+		options.Tables = DefaultMetricsTables
+	}
+	if options.Hosts == 0 {
+		options.Hosts = DefaultMetricsHosts
+	}
+	if options.RowsPerSecond == 0 {
+		options.RowsPerSecond = DefaultMetricsRowsPerSecond
+	}
+	return options
+}
+
+// metricsBatch is the lines of InfluxDB line protocol of rows.
+type metricsBatch struct {
+	lines string
+	rows  int
+}
+
+// metricsGenerator generates the rows of the series of each table and host.
+type metricsGenerator struct {
+	options *MetricsOptions
+	rand    *rand.Rand
+
+	// values are the current values of series indexed by table and then host.
+	values [][]float64
+}
+
+func newMetricsGenerator(options *MetricsOptions) *metricsGenerator {
+	g := &metricsGenerator{
+		options: options,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		values:  make([][]float64, options.Tables),
+	}
+	for i := range g.values {
+		g.values[i] = make([]float64, options.Hosts)
+		for j := range g.values[i] {
+			g.values[i][j] = g.rand.Float64() * 100
+		}
+	}
+	return g
+}
+
+// batches generates the rows of one write interval starting at base in batches. The rows go round the series, and
+// the series that have more than one row in the interval have them at the evenly spaced timestamps.
+func (g *metricsGenerator) batches(base time.Time) []metricsBatch {
+	var (
+		series  = g.options.Tables * g.options.Hosts
+		samples = (g.options.RowsPerSecond + series - 1) / series
+		step    = metricsWriteInterval / time.Duration(samples)
+		batches []metricsBatch
+		lines   strings.Builder
+		rows    int
+	)
+
+	for i := 0; i < g.options.RowsPerSecond; i++ {
+		table, host := i%g.options.Tables, (i/g.options.Tables)%g.options.Hosts
+		ts := base.Add(time.Duration(i/series) * step)
+
+		value := math.Max(0, math.Min(100, g.values[table][host]+g.rand.Float64()*4-2))
+		g.values[table][host] = value
+
+		fmt.Fprintf(&lines, "%s_%d,host=host_%d,region=%s value=%s %d\n", g.options.TablePrefix, table,
+			host, metricsRegions[host%len(metricsRegions)], strconv.FormatFloat(value, 'f', 2, 64), ts.UnixMilli())
+		rows++
+
+		if rows >= metricsBatchSize {
+			batches = append(batches, metricsBatch{lines: lines.String(), rows: rows})
+			lines.Reset()
+			rows = 0
+		}
+	}
+	if rows > 0 {
+		batches = append(batches, metricsBatch{lines: lines.String(), rows: rows})
+	}
+
+	return batches
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestSeedMetrics(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		lines      []string
+		statements []string
+		fail       bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/influxdb/write":
+			if fail {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":1004,"error":"Invalid InfluxDB line protocol"}`))
+				return
+			}
+			raw, _ := io.ReadAll(r.Body)
+			lines = append(lines, strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")...)
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/sql":
+			statements = append(statements, r.FormValue("sql"))
+			_, _ = w.Write([]byte(`{"output":[{"affectedrows":1}]}`))
+		}
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+	l := logger.New(io.Discard, 0)
+
+	options := &MetricsOptions{Database: "demo", Tables: 2, Hosts: 2, RowsPerSecond: 8, Duration: 1500 * time.Millisecond}
+	assert.NoError(t, SeedMetrics(context.Background(), addr, options, l))
+
+	mu.Lock()
+	assert.Equal(t, []string{"CREATE DATABASE IF NOT EXISTS demo"}, statements)
+	// The rows are written at the start and once a second.
+	assert.Len(t, lines, 16)
+	assert.True(t, strings.HasPrefix(lines[0], "demo_metrics_0,host=host_0,region=us-east-1 value="))
+	fail = true
+	mu.Unlock()
+
+	err := SeedMetrics(context.Background(), addr, options, l)
+	assert.EqualError(t, err, `error writing metrics: unexpected response (400 Bad Request): {"code":1004,"error":"Invalid InfluxDB line protocol"}`)
+}
+
+func TestMetricsGeneratorBatches(t *testing.T) {
+	options := &MetricsOptions{TablePrefix: "m", Tables: 2, Hosts: 3, RowsPerSecond: 12}
+	base := time.UnixMilli(1700000000000)
+
+	batches := newMetricsGenerator(options).batches(base)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, 12, batches[0].rows)
+
+	var (
+		prefixes []string
+		seen     = make(map[string]bool)
+	)
+	for _, line := range strings.Split(strings.TrimSuffix(batches[0].lines, "\n"), "\n") {
+		parts := strings.Split(line, " ")
+		assert.Len(t, parts, 3)
+		assert.True(t, strings.HasPrefix(parts[1], "value="))
+		prefixes = append(prefixes, parts[0]+" "+parts[2])
+
+		// Each series has at most one row at a timestamp.
+		assert.False(t, seen[parts[0]+" "+parts[2]])
+		seen[parts[0]+" "+parts[2]] = true
+	}
+	assert.Equal(t, []string{
+		"m_0,host=host_0,region=us-east-1 1700000000000",
+		"m_1,host=host_0,region=us-east-1 1700000000000",
+		"m_0,host=host_1,region=us-west-2 1700000000000",
+		"m_1,host=host_1,region=us-west-2 1700000000000",
+		"m_0,host=host_2,region=eu-west-1 1700000000000",
+		"m_1,host=host_2,region=eu-west-1 1700000000000",
+		"m_0,host=host_0,region=us-east-1 1700000000500",
+		"m_1,host=host_0,region=us-east-1 1700000000500",
+		"m_0,host=host_1,region=us-west-2 1700000000500",
+		"m_1,host=host_1,region=us-west-2 1700000000500",
+		"m_0,host=host_2,region=eu-west-1 1700000000500",
+		"m_1,host=host_2,region=eu-west-1 1700000000500",
+	}, prefixes)
+
+	options.RowsPerSecond = metricsBatchSize + 1
+	batches = newMetricsGenerator(options).batches(base)
+	assert.Len(t, batches, 2)
+	assert.Equal(t, metricsBatchSize, batches[0].rows)
+	assert.Equal(t, 1, batches[1].rows)
+}
@@ -43,12 +43,12 @@ const (
 	portForward = "port-forward"
 )
 
-// Mysql connects to a GreptimeDB cluster using mysql protocol.
-func Mysql(port, clusterName string, l logger.Logger) error {
+// Mysql connects to a GreptimeDB cluster using mysql protocol through its service, e.g. the frontend service.
+func Mysql(port, service string, l logger.Logger) error {
 	waitGroup := sync.WaitGroup{}
 
 	// TODO: is there any elegant way to enable port-forward?
-	cmd := exec.CommandContext(context.Background(), kubectl, portForward, "-n", "default", "svc/"+clusterName+"-frontend", fmt.Sprintf("%s:%s", port, port))
+	cmd := kubectlPortForward(context.Background(), "default", "svc/"+service, fmt.Sprintf("%s:%s", port, port))
 	if err := cmd.Start(); err != nil {
 		l.Errorf("Error starting port-forwarding: %v", err)
 		return err
@@ -99,7 +99,7 @@ func Mysql(port, clusterName string, l logger.Logger) error {
 		break
 	}
 
-	cmd = mysqlCommand(port)
+	cmd = mysqlCommand(mySQLDefaultAddr, port)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -126,6 +126,15 @@ func Mysql(port, clusterName string, l logger.Logger) error {
 	return nil
 }
 
-func mysqlCommand(port string) *exec.Cmd {
-	return exec.Command(mySQLDriver, mySQLHostArg, mySQLDefaultAddr, mySQLPortArg, port)
+func mysqlCommand(host, port string) *exec.Cmd {
+	return exec.Command(mySQLDriver, mySQLHostArg, host, mySQLPortArg, port)
+}
+
+// MysqlAt connects to GreptimeDB on addr by the mysql client directly.
+func MysqlAt(addr string, l logger.Logger) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	return runClient(mysqlCommand(host, port), "mysql", l)
 }
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// portForwardRestartDelay is the delay before restarting the dropped port-forwarding.
+const portForwardRestartDelay = time.Second
+
+// ForwardedPort is a port of the service that is forwarded to the local port.
+type ForwardedPort struct {
+	// Name is the name of the protocol that is served on the port, e.g. "mysql".
+	Name       string
+	LocalPort  string
+	RemotePort string
+}
+
+// PortForward forwards the ports of the service in namespace to the local address, and supervises the
+// port-forwarding processes until ctx is done, the dropped port-forwarding is restarted automatically.
+func PortForward(ctx context.Context, namespace, service, address string, ports []ForwardedPort, l logger.Logger) error {
+	if len(ports) == 0 {
+		return fmt.Errorf("no port to forward")
+	}
+
+	var wg sync.WaitGroup
+	for _, port := range ports {
+		wg.Add(1)
+		go func(port ForwardedPort) {
+			defer wg.Done()
+			supervisePortForward(ctx, namespace, service, address, port, l)
+		}(port)
+	}
+
+	for _, port := range ports {
+		if err := waitForAddr(net.JoinHostPort(address, port.LocalPort)); err != nil {
+			l.Warnf("The %s port is not forwarded yet: %v", port.Name, err)
+		}
+	}
+	l.V(0).Infof("The ports of service '%s' are forwarded, press Ctrl+C to stop forwarding", service)
+
+	wg.Wait()
+	return nil
+}
+
+// supervisePortForward runs the port-forwarding of the port, and restarts it once it exits until ctx is done.
+func supervisePortForward(ctx context.Context, namespace, service, address string, port ForwardedPort, l logger.Logger) {
+	for {
+		cmd := kubectlPortForward(ctx, namespace, "--address", address,
+			"svc/"+service, fmt.Sprintf("%s:%s", port.LocalPort, port.RemotePort))
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			l.V(1).Infof("Shutting down %s port-forwarding", port.Name)
+			return
+		}
+		l.Warnf("The %s port-forwarding is dropped: %v, restarting it", port.Name, err)
+
+		select {
+		case <-time.After(portForwardRestartDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
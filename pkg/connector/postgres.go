@@ -42,11 +42,11 @@ const (
 )
 
 // PostgresSQL connects to a GreptimeDB cluster using postgres protocol.
-func PostgresSQL(port, clusterName string, l logger.Logger) error {
+func PostgresSQL(port, service string, l logger.Logger) error {
 	waitGroup := sync.WaitGroup{}
 
 	// TODO: is there any elegant way to enable port-forward?
-	cmd := exec.CommandContext(context.Background(), kubectl, portForward, "-n", "default", "svc/"+clusterName+"-frontend", fmt.Sprintf("%s:%s", port, port))
+	cmd := kubectlPortForward(context.Background(), "default", "svc/"+service, fmt.Sprintf("%s:%s", port, port))
 	if err := cmd.Start(); err != nil {
 		l.Errorf("Error starting port-forwarding: %v", err)
 		return err
@@ -88,7 +88,7 @@ func PostgresSQL(port, clusterName string, l logger.Logger) error {
 		}
 	}
 
-	cmd = postgresSQLCommand(port)
+	cmd = postgresSQLCommand(postgresSQLDefaultAddr, port)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -115,7 +115,16 @@ func PostgresSQL(port, clusterName string, l logger.Logger) error {
 	return nil
 }
 
-func postgresSQLCommand(port string) *exec.Cmd {
-	return exec.Command(postgresSQLDriver, postgresSQLHostArg, postgresSQLDefaultAddr,
+func postgresSQLCommand(host, port string) *exec.Cmd {
+	return exec.Command(postgresSQLDriver, postgresSQLHostArg, host,
 		postgresSQLPortArg, port, postgresSQLDatabaseArg, postgresSQLDatabaseName)
 }
+
+// PostgresSQLAt connects to GreptimeDB on addr by the psql client directly.
+func PostgresSQLAt(addr string, l logger.Logger) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	return runClient(postgresSQLCommand(host, port), "pg", l)
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// seedBatchSize is the max number of rows inserted by one statement when loading the csv file.
+const seedBatchSize = 1000
+
+// SeedOptions is the seed data that is loaded into a GreptimeDB cluster once it's healthy.
+type SeedOptions struct {
+	Database string
+
+	// SQLPath is the SQL script, or the directory of SQL scripts that are executed in the order of their names.
+	SQLPath string
+
+	// DataPath is the csv or parquet file that is loaded into Table after the SQL scripts are executed.
+	// The csv file is inserted through the HTTP API, with its header as the columns, while the parquet
+	// file is copied from by the cluster itself, so it should be accessible by the frontend.
+	DataPath string
+	Table    string
+}
+
+// Seed executes the SQL scripts and loads the data file through the HTTP API of the frontend serving HTTP on addr.
+func Seed(ctx context.Context, addr string, options *SeedOptions, l logger.Logger) error {
+	client := &http.Client{Timeout: httpSQLRequestTimeout}
+	exec := func(sql string) error {
+		_, err := requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+			url.Values{"db": []string{options.Database}}, url.Values{"sql": []string{sql}})
+		return err
+	}
+
+	if len(options.SQLPath) > 0 {
+		scripts, err := sqlScripts(options.SQLPath)
+		if err != nil {
+			return err
+		}
+		for _, script := range scripts {
+			l.V(0).Infof("Executing SQL script '%s'...", script)
+			content, err := os.ReadFile(script)
+			if err != nil {
+				return err
+			}
+			if err = exec(string(content)); err != nil {
+				return fmt.Errorf("error executing SQL script '%s': %v", script, err)
+			}
+		}
+	}
+
+	if len(options.DataPath) > 0 {
+		l.V(0).Infof("Loading data file '%s' into table '%s'...", options.DataPath, options.Table)
+		switch strings.ToLower(filepath.Ext(options.DataPath)) {
+		case ".csv":
+			rows, err := loadCSV(options.DataPath, options.Table, exec)
+			if err != nil {
+				return fmt.Errorf("error loading data file '%s': %v", options.DataPath, err)
+			}
+			l.V(0).Infof("Loaded %d rows into table '%s'", rows, options.Table)
+		case ".parquet":
+			path, err := filepath.Abs(options.DataPath)
+			if err != nil {
+				return err
+			}
+			if err = exec(fmt.Sprintf("COPY %s FROM %s WITH (FORMAT = 'parquet');", options.Table, quoteSQLString(path))); err != nil {
+				return fmt.Errorf("error loading data file '%s': %v", options.DataPath, err)
+			}
+		default:
+			return fmt.Errorf("unsupported data file '%s', only csv and parquet files are supported", options.DataPath)
+		}
+	}
+
+	return nil
+}
+
+// SeedForwarded loads the seed data into a GreptimeDB cluster through its HTTP API that is port-forwarded to local.
+func SeedForwarded(port, service string, options *SeedOptions, l logger.Logger) error {
+	cmd, err := startPortForward(service, port, l)
+	if err != nil {
+		return err
+	}
+	defer stopPortForward(cmd, l)
+
+	addr := net.JoinHostPort(httpSQLDefaultAddr, port)
+	if err = waitForAddr(addr); err != nil {
+		return err
+	}
+
+	return Seed(context.Background(), addr, options, l)
+}
+
+// sqlScripts returns the SQL script of path, or the '.sql' scripts under path sorted by names if it's a directory.
+func sqlScripts(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	scripts, err := filepath.Glob(filepath.Join(path, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("no '.sql' script is found in '%s'", path)
+	}
+	sort.Strings(scripts)
+
+	return scripts, nil
+}
+
+// loadCSV inserts the rows of the csv file into the table in batches, and returns the number of inserted rows.
+// The values are inserted as strings that are casted to the types of columns by the cluster, the empty values
+// are inserted as NULL.
+func loadCSV(path, table string, exec func(sql string) error) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading the header: %v", err)
+	}
+	columns := make([]string, 0, len(header))
+	for _, column := range header {
+		columns = append(columns, fmt.Sprintf("`%s`", strings.ReplaceAll(column, "`", "``")))
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+
+	var (
+		total int
+		batch []string
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := exec(insert + strings.Join(batch, ", ") + ";"); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+
+		values := make([]string, 0, len(record))
+		for _, value := range record {
+			if len(value) == 0 {
+				values = append(values, "NULL")
+			} else {
+				values = append(values, quoteSQLString(value))
+			}
+		}
+		batch = append(batch, fmt.Sprintf("(%s)", strings.Join(values, ", ")))
+
+		if len(batch) >= seedBatchSize {
+			if err = flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+
+	return total, flush()
+}
+
+// quoteSQLString quotes the value as a SQL string literal.
+func quoteSQLString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestSeed(t *testing.T) {
+	var statements []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "metrics", r.URL.Query().Get("db"))
+		sql := r.FormValue("sql")
+		statements = append(statements, sql)
+		if strings.Contains(sql, "missing") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":4001,"error":"Table not found: missing"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"output":[{"affectedrows":1}]}`))
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+	l := logger.New(io.Discard, 0)
+
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "scripts")
+	assert.NoError(t, os.MkdirAll(scripts, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(scripts, "02-insert.sql"), []byte("INSERT INTO monitor VALUES ('a', 1);"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(scripts, "01-create.sql"), []byte("CREATE TABLE monitor (host STRING);"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(scripts, "README.md"), []byte("not a script"), 0644))
+	data := filepath.Join(dir, "monitor.csv")
+	assert.NoError(t, os.WriteFile(data, []byte("host,ts,cpu\nit's,1700000000000,0.5\nb,1700000001000,\n"), 0644))
+
+	options := &SeedOptions{Database: "metrics", SQLPath: scripts, DataPath: data, Table: "monitor"}
+	assert.NoError(t, Seed(context.Background(), addr, options, l))
+	assert.Equal(t, []string{
+		"CREATE TABLE monitor (host STRING);",
+		"INSERT INTO monitor VALUES ('a', 1);",
+		"INSERT INTO monitor (`host`, `ts`, `cpu`) VALUES ('it''s', '1700000000000', '0.5'), ('b', '1700000001000', NULL);",
+	}, statements)
+
+	statements = nil
+	options = &SeedOptions{Database: "metrics", DataPath: filepath.Join(dir, "monitor.parquet"), Table: "missing"}
+	assert.ErrorContains(t, Seed(context.Background(), addr, options, l), "Table not found")
+	assert.Len(t, statements, 1)
+	assert.True(t, strings.HasPrefix(statements[0], "COPY missing FROM '"+dir))
+
+	options = &SeedOptions{Database: "metrics", DataPath: filepath.Join(dir, "monitor.json"), Table: "monitor"}
+	assert.ErrorContains(t, Seed(context.Background(), addr, options, l), "unsupported data file")
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	sqlShellPrompt         = "greptime> "
+	sqlShellContinuePrompt = "       -> "
+)
+
+const sqlShellHelp = `Statements are terminated by ';' and may span multiple lines. Commands:
+  \?, help           Show this help.
+  \q, exit, quit     Leave the shell.
+  \timing            Toggle showing the execution time of statements.
+  \history           Show the statements executed in this session.
+  \c <database>      Switch to the database, the same as 'USE <database>;'.
+`
+
+// useStatementPattern matches the 'USE <database>;' statement, which is handled by the shell,
+// since the HTTP API is stateless.
+var useStatementPattern = regexp.MustCompile(`(?i)^use\s+([\w-]+)\s*;$`)
+
+// sqlShell is the built-in interactive SQL shell that executes the statements through the HTTP API
+// of GreptimeDB, so no external mysql or psql client is required.
+type sqlShell struct {
+	client   *http.Client
+	addr     string
+	database string
+	timing   bool
+	history  []string
+	out      io.Writer
+}
+
+// SQLShell runs the built-in SQL shell that reads the statements from in, executes them through
+// the '/v1/sql' API of the frontend serving HTTP on addr, and writes the results to out.
+// The line editing and the history of statements by arrow keys are supported if in is a terminal.
+// The shell exits on 'exit', 'quit', '\q' or the EOF of in.
+func SQLShell(ctx context.Context, addr, database string, in io.Reader, out io.Writer) error {
+	reader, out, restore, err := newLineReader(in, out)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	s := &sqlShell{
+		client:   &http.Client{Timeout: httpSQLRequestTimeout},
+		addr:     addr,
+		database: database,
+		out:      out,
+	}
+	fmt.Fprintf(out, "Connected to GreptimeDB through HTTP API on %s, type '\\?' for help.\n", addr)
+
+	return s.run(ctx, reader)
+}
+
+// run reads and executes the statements and commands until the shell exits.
+func (s *sqlShell) run(ctx context.Context, reader lineReader) error {
+	var statement strings.Builder
+	for {
+		prompt := sqlShellPrompt
+		if statement.Len() > 0 {
+			prompt = sqlShellContinuePrompt
+		}
+		line, err := reader.ReadLine(prompt)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if statement.Len() == 0 {
+			if line == "" {
+				continue
+			}
+			if exit, ok := s.command(line); ok {
+				if exit {
+					return nil
+				}
+				continue
+			}
+		}
+
+		statement.WriteString(line)
+		if !strings.HasSuffix(line, ";") {
+			statement.WriteString("\n")
+			continue
+		}
+
+		s.execute(ctx, statement.String())
+		statement.Reset()
+	}
+}
+
+// command handles the line if it's a command of shell, and tells whether the shell should exit.
+func (s *sqlShell) command(line string) (exit bool, ok bool) {
+	fields := strings.Fields(strings.TrimSuffix(line, ";"))
+	switch fields[0] {
+	case `\q`, "exit", "quit":
+		return true, true
+	case `\?`, "help":
+		fmt.Fprint(s.out, sqlShellHelp)
+	case `\timing`:
+		s.timing = !s.timing
+		if s.timing {
+			fmt.Fprintln(s.out, "Timing is on.")
+		} else {
+			fmt.Fprintln(s.out, "Timing is off.")
+		}
+	case `\history`:
+		for i, statement := range s.history {
+			fmt.Fprintf(s.out, "%5d  %s\n", i+1, strings.ReplaceAll(statement, "\n", " "))
+		}
+	case `\c`:
+		if len(fields) != 2 {
+			fmt.Fprintln(s.out, `ERROR: usage: \c <database>`)
+		} else {
+			s.useDatabase(fields[1])
+		}
+	default:
+		if strings.HasPrefix(line, `\`) {
+			fmt.Fprintf(s.out, "ERROR: unknown command '%s', type '\\?' for help\n", fields[0])
+			return false, true
+		}
+		return false, false
+	}
+	return false, true
+}
+
+// execute executes the statement and records it in the history.
+func (s *sqlShell) execute(ctx context.Context, statement string) {
+	s.history = append(s.history, statement)
+
+	if matches := useStatementPattern.FindStringSubmatch(statement); matches != nil {
+		s.useDatabase(matches[1])
+		return
+	}
+	if err := executeHTTPSQL(ctx, s.client, s.addr, s.database, statement, s.timing, s.out); err != nil {
+		fmt.Fprintf(s.out, "ERROR: %v\n", err)
+	}
+}
+
+// useDatabase switches the database that the following statements are executed in.
+func (s *sqlShell) useDatabase(database string) {
+	s.database = database
+	fmt.Fprintf(s.out, "Database changed to '%s'\n", database)
+}
+
+// lineReader reads the lines of input after writing the prompt.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// newLineReader returns the line reader of in, and the writer that the output should be written to.
+// The terminal is put into raw mode for line editing, which is restored by the returned restore.
+func newLineReader(in io.Reader, out io.Writer) (lineReader, io.Writer, func(), error) {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fd := int(f.Fd())
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		t := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{in, out}, "")
+		return &terminalReader{terminal: t}, t, func() { _ = term.Restore(fd, state) }, nil
+	}
+
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}, out, func() {}, nil
+}
+
+// terminalReader reads the lines from terminal with line editing and history.
+type terminalReader struct {
+	terminal *term.Terminal
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	r.terminal.SetPrompt(prompt)
+	return r.terminal.ReadLine()
+}
+
+// scannerReader reads the lines from the input that is not a terminal, e.g. a pipe.
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
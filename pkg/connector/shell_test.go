@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLShell(t *testing.T) {
+	var statements, databases []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/sql", r.URL.Path)
+		databases = append(databases, r.URL.Query().Get("db"))
+		sql := r.FormValue("sql")
+		statements = append(statements, sql)
+
+		switch {
+		case strings.HasPrefix(sql, "SELECT"):
+			_, _ = w.Write([]byte(`{"output":[{"records":{"schema":{"column_schemas":[{"name":"host","data_type":"String"},` +
+				`{"name":"cpu","data_type":"Float64"}]},"rows":[["a",0.5],["b",null]]}}],"execution_time_ms":3}`))
+		case strings.HasPrefix(sql, "INSERT"):
+			_, _ = w.Write([]byte(`{"output":[{"affectedrows":2}],"execution_time_ms":1}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":1004,"error":"Failed to parse SQL","execution_time_ms":0}`))
+		}
+	}))
+	defer server.Close()
+
+	in := strings.NewReader(`SELECT host, cpu
+FROM monitor;
+
+\timing
+use public;
+INSERT INTO monitor VALUES ('a', 0.5), ('b', NULL);
+\unknown
+BAD;
+\history
+exit
+SELECT 1;
+`)
+	var out bytes.Buffer
+	addr := strings.TrimPrefix(server.URL, "http://")
+	assert.NoError(t, SQLShell(context.Background(), addr, "metrics", in, &out))
+
+	// The statements after exit are not executed, and the database is switched by the shell.
+	assert.Equal(t, []string{
+		"SELECT host, cpu\nFROM monitor;",
+		"INSERT INTO monitor VALUES ('a', 0.5), ('b', NULL);",
+		"BAD;",
+	}, statements)
+	assert.Equal(t, []string{"metrics", "public", "public"}, databases)
+
+	output := out.String()
+	assert.Contains(t, output, "| host | cpu  |")
+	assert.Contains(t, output, "| b    | NULL |")
+	assert.Contains(t, output, "2 rows in set\ngreptime> ")
+	assert.Contains(t, output, "Timing is on.")
+	assert.Contains(t, output, "Database changed to 'public'")
+	assert.Contains(t, output, "Affected Rows: 2\nTime: 1 ms")
+	assert.Contains(t, output, "ERROR: unknown command '\\unknown'")
+	assert.Contains(t, output, "ERROR: Failed to parse SQL (code 1004)")
+	assert.Contains(t, output, "    2  use public;\n")
+}
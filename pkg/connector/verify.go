@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	// DefaultVerifyTimeout is the max duration of waiting for the cluster to serve SQL.
+	DefaultVerifyTimeout = 2 * time.Minute
+
+	// verifyRetryInterval is the interval between the attempts of verifying one endpoint.
+	verifyRetryInterval = time.Second
+
+	// verifyDatabase is the database that the verification runs in, which always exists.
+	verifyDatabase = "public"
+)
+
+// VerifyOptions is the verification that the cluster actually serves SQL after it's started,
+// which catches the cases where the processes are healthy but the cluster is not functional yet.
+type VerifyOptions struct {
+	// SmokeTest creates a table, writes to it, reads from it and drops it besides 'SELECT 1'.
+	SmokeTest bool
+
+	// Timeout is DefaultVerifyTimeout if it's zero.
+	Timeout time.Duration
+}
+
+// VerifyAddrs are the MySQL and HTTP addresses of the frontend to verify, the empty one is skipped.
+type VerifyAddrs struct {
+	MySQL string
+	HTTP  string
+}
+
+// Verify waits until the statements of verification succeed through every endpoint of addrs, it fails
+// with the last error of the endpoint that doesn't serve SQL before the timeout.
+func Verify(ctx context.Context, addrs VerifyAddrs, options *VerifyOptions, l logger.Logger) error {
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultVerifyTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	endpoints := []struct {
+		protocol string
+		addr     string
+		exec     func(ctx context.Context, addr, sql string) error
+	}{
+		{"MySQL", addrs.MySQL, execMySQL},
+		{"HTTP", addrs.HTTP, execHTTP},
+	}
+
+	verified := 0
+	for _, endpoint := range endpoints {
+		if len(endpoint.addr) == 0 {
+			continue
+		}
+
+		statements := verifyStatements(endpoint.protocol, options.SmokeTest)
+		for attempt := 1; ; attempt++ {
+			err := runStatements(ctx, endpoint.addr, statements, endpoint.exec)
+			if err == nil {
+				break
+			}
+			l.V(3).Infof("Verifying SQL on %s endpoint '%s' failed (attempt %d): %v", endpoint.protocol, endpoint.addr, attempt, err)
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s endpoint '%s' doesn't serve SQL in %s: %v", endpoint.protocol, endpoint.addr, timeout, err)
+			case <-time.After(verifyRetryInterval):
+			}
+		}
+		l.V(1).Infof("Verified SQL on %s endpoint '%s'", endpoint.protocol, endpoint.addr)
+		verified++
+	}
+
+	if verified == 0 {
+		return fmt.Errorf("no MySQL or HTTP endpoint to verify")
+	}
+	return nil
+}
+
+// VerifyForwarded verifies a GreptimeDB cluster through its MySQL and HTTP ports that are port-forwarded to local.
+func VerifyForwarded(mysqlPort, httpPort, service string, options *VerifyOptions, l logger.Logger) error {
+	var addrs VerifyAddrs
+	for _, forward := range []struct {
+		port string
+		addr *string
+	}{{mysqlPort, &addrs.MySQL}, {httpPort, &addrs.HTTP}} {
+		if len(forward.port) == 0 || forward.port == "0" {
+			continue
+		}
+
+		cmd, err := startPortForward(service, forward.port, l)
+		if err != nil {
+			return err
+		}
+		defer stopPortForward(cmd, l)
+
+		addr := net.JoinHostPort(httpSQLDefaultAddr, forward.port)
+		if err = waitForAddr(addr); err != nil {
+			return err
+		}
+		*forward.addr = addr
+	}
+
+	return Verify(context.Background(), addrs, options, l)
+}
+
+// verifyStatements returns the statements that verify one endpoint, the table of smoke test is
+// named after the protocol, so the endpoints don't interfere with each other.
+func verifyStatements(protocol string, smokeTest bool) []string {
+	statements := []string{"SELECT 1"}
+	if smokeTest {
+		table := fmt.Sprintf("gtctl_smoke_test_%s", strings.ToLower(protocol))
+		statements = append(statements,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (ts TIMESTAMP TIME INDEX, val DOUBLE)", table),
+			fmt.Sprintf("INSERT INTO %s VALUES (0, 1.0)", table),
+			fmt.Sprintf("SELECT * FROM %s", table),
+			fmt.Sprintf("DROP TABLE %s", table))
+	}
+	return statements
+}
+
+// runStatements executes the statements in order until one of them fails.
+func runStatements(ctx context.Context, addr string, statements []string,
+	exec func(ctx context.Context, addr, sql string) error) error {
+	for _, statement := range statements {
+		if err := exec(ctx, addr, statement); err != nil {
+			return fmt.Errorf("error executing '%s': %v", statement, err)
+		}
+	}
+	return nil
+}
+
+// execHTTP executes the sql through the '/v1/sql' API of addr.
+func execHTTP(ctx context.Context, addr, sql string) error {
+	client := &http.Client{Timeout: httpSQLRequestTimeout}
+	_, err := requestHTTPSQL(ctx, client, http.MethodPost, fmt.Sprintf("http://%s/v1/sql", addr),
+		url.Values{"db": []string{verifyDatabase}}, url.Values{"sql": []string{sql}})
+	return err
+}
+
+// execMySQL executes the sql through the MySQL protocol of addr and reads all of its results.
+func execMySQL(ctx context.Context, addr, statement string) error {
+	cfg := mysql.Config{
+		Net:                  mySQLDefaultNet,
+		Addr:                 addr,
+		DBName:               verifyDatabase,
+		AllowNativePasswords: true,
+	}
+	db, err := sql.Open(mySQLDriver, cfg.FormatDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+	}
+	return rows.Err()
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		statements []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		statements = append(statements, r.FormValue("sql"))
+		// The first attempt fails as the cluster is not functional yet.
+		if len(statements) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code":1003,"error":"Region not ready"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"output":[{"affectedrows":0}]}`))
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+	l := logger.New(io.Discard, 0)
+
+	err := Verify(context.Background(), VerifyAddrs{HTTP: addr}, &VerifyOptions{SmokeTest: true}, l)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"SELECT 1",
+		"SELECT 1",
+		"CREATE TABLE IF NOT EXISTS gtctl_smoke_test_http (ts TIMESTAMP TIME INDEX, val DOUBLE)",
+		"INSERT INTO gtctl_smoke_test_http VALUES (0, 1.0)",
+		"SELECT * FROM gtctl_smoke_test_http",
+		"DROP TABLE gtctl_smoke_test_http",
+	}, statements)
+
+	err = Verify(context.Background(), VerifyAddrs{}, &VerifyOptions{}, l)
+	assert.Error(t, err)
+}
+
+func TestVerifyTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"code":1003,"error":"Region not ready"}`))
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	err := Verify(context.Background(), VerifyAddrs{HTTP: addr}, &VerifyOptions{Timeout: 100 * time.Millisecond},
+		logger.New(io.Discard, 0))
+	assert.ErrorContains(t, err, "Region not ready")
+}
@@ -0,0 +1,280 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package doctor checks whether the environment is ready to run the clusters, e.g. the resource limits, the free ports
+// and disk space, the access to the Kubernetes API and the artifact endpoints, and tells how to fix the problems found.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/kube"
+)
+
+// Status is the status of one check.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+const (
+	// RecommendedOpenFiles is the recommended limit of open files, since each replica opens lots of
+	// SST files, WAL files and connections.
+	RecommendedOpenFiles = 65536
+
+	// DefaultMinDiskSpace is the default minimum free disk space to store the data and logs of bare-metal clusters.
+	DefaultMinDiskSpace = 10 << 30
+
+	// MinGlibcVersion is the minimum glibc version required by the released greptime binaries for Linux.
+	MinGlibcVersion = "2.28"
+
+	// dnsLookupTimeout is the timeout of resolving one host.
+	dnsLookupTimeout = 5 * time.Second
+)
+
+// Result is the result of one check.
+type Result struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  Status `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+
+	// Remediation tells how to fix the problem, it's empty if the check passes.
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+func passed(name, format string, args ...interface{}) *Result {
+	return &Result{Name: name, Status: StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func warned(name, remediation, format string, args ...interface{}) *Result {
+	return &Result{Name: name, Status: StatusWarn, Message: fmt.Sprintf(format, args...), Remediation: remediation}
+}
+
+func failed(name, remediation, format string, args ...interface{}) *Result {
+	return &Result{Name: name, Status: StatusFail, Message: fmt.Sprintf(format, args...), Remediation: remediation}
+}
+
+// Count returns the number of the failed and warned results.
+func Count(results []*Result) (failures, warnings int) {
+	for _, result := range results {
+		switch result.Status {
+		case StatusFail:
+			failures++
+		case StatusWarn:
+			warnings++
+		}
+	}
+	return failures, warnings
+}
+
+// CheckPorts checks whether the listen addresses of the components are free.
+func CheckPorts(addrs []components.ListenAddr) *Result {
+	const name = "Ports"
+	if len(addrs) == 0 {
+		return passed(name, "the ports are allocated automatically")
+	}
+
+	if err := components.CheckPortConflicts(addrs); err != nil {
+		return failed(name, "Stop the processes listening on the ports, which can be found by 'lsof -i :<port>', "+
+			"or shift the ports by 'portOffset' or enable 'autoPortAllocation' in the cluster config.", "%v", err)
+	}
+	return passed(name, "all the %d listen addresses are available", len(addrs))
+}
+
+// CheckGlibc checks whether the glibc is new enough to run the greptime binaries, it returns nil if it's not Linux.
+func CheckGlibc(ctx context.Context) *Result {
+	const name = "glibc"
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+
+	remediation := fmt.Sprintf("The greptime binaries require glibc %s or newer, run the cluster on a newer "+
+		"glibc-based distribution, or in the docker containers by 'gtctl cluster create --docker'.", MinGlibcVersion)
+	out, err := exec.CommandContext(ctx, "getconf", "GNU_LIBC_VERSION").Output()
+	if err != nil {
+		return failed(name, remediation, "glibc is not found, the C library may be musl: %v", err)
+	}
+
+	version, ok := parseGlibcVersion(string(out))
+	if !ok {
+		return warned(name, remediation, "unknown glibc version '%s'", strings.TrimSpace(string(out)))
+	}
+	if compareVersions(version, MinGlibcVersion) < 0 {
+		return failed(name, remediation, "glibc %s is older than %s", version, MinGlibcVersion)
+	}
+	return passed(name, "glibc %s", version)
+}
+
+var glibcVersionRegexp = regexp.MustCompile(`(\d+\.\d+)`)
+
+// parseGlibcVersion parses the output of 'getconf GNU_LIBC_VERSION', e.g. 'glibc 2.35'.
+func parseGlibcVersion(out string) (string, bool) {
+	match := glibcVersionRegexp.FindString(out)
+	return match, len(match) > 0
+}
+
+// compareVersions compares the versions like '2.35' by their numeric parts.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// CheckCommand checks whether the command is found in PATH, it's only warned if not,
+// since gtctl doesn't depend on it but the remediation steps may do.
+func CheckCommand(command, remediation string) *Result {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return warned(command, remediation, "'%s' is not found in PATH", command)
+	}
+	return passed(command, "found '%s'", path)
+}
+
+// CheckKubeAPI checks whether the Kubernetes API that the context of kubeconfig points to is reachable,
+// and returns the client of it if so.
+func CheckKubeAPI(kubeconfig, kubeContext string) (*kube.Client, *Result) {
+	const name = "Kubernetes API"
+
+	client, err := kube.NewClient(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, failed(name, "Check the kubeconfig and the context by 'kubectl cluster-info', "+
+			"or select another one by '--kubeconfig' and '--context'.", "%v", err)
+	}
+
+	current, err := kube.CurrentContext(kubeconfig, kubeContext)
+	if err != nil {
+		current = "unknown"
+	}
+	return client, passed(name, "connected to the Kubernetes cluster of context '%s'", current)
+}
+
+// AccessReviewer reviews whether the current user is allowed to access the Kubernetes resources.
+type AccessReviewer interface {
+	CanI(ctx context.Context, namespace, verb, group, resource string) (bool, string, error)
+}
+
+var _ AccessReviewer = &kube.Client{}
+
+// kubePermission is the permission that gtctl needs to create and manage the clusters on Kubernetes.
+type kubePermission struct {
+	verb, group, resource string
+
+	// clusterScoped is true if the resource is not namespaced, operator is true if the resource
+	// is created in the namespace of operator rather than the one of cluster.
+	clusterScoped, operator bool
+}
+
+var kubePermissions = []kubePermission{
+	{verb: "create", group: "apiextensions.k8s.io", resource: "customresourcedefinitions", clusterScoped: true},
+	{verb: "create", group: "apps", resource: "deployments", operator: true},
+	{verb: "create", group: "greptime.io", resource: "greptimedbclusters"},
+	{verb: "create", group: "apps", resource: "statefulsets"},
+	{verb: "create", resource: "services"},
+	{verb: "create", resource: "secrets"},
+	{verb: "create", resource: "configmaps"},
+	{verb: "list", resource: "pods"},
+}
+
+// CheckKubePermissions checks whether the current user has the permissions to create the operator
+// in operatorNamespace and the clusters in namespace.
+func CheckKubePermissions(ctx context.Context, reviewer AccessReviewer, namespace, operatorNamespace string) *Result {
+	const name = "Kubernetes permissions"
+
+	var denied []string
+	for _, p := range kubePermissions {
+		ns := namespace
+		if p.clusterScoped {
+			ns = ""
+		} else if p.operator {
+			ns = operatorNamespace
+		}
+
+		resource := p.resource
+		if len(p.group) > 0 {
+			resource = fmt.Sprintf("%s.%s", p.resource, p.group)
+		}
+		allowed, reason, err := reviewer.CanI(ctx, ns, p.verb, p.group, p.resource)
+		if err != nil {
+			return failed(name, "Check whether the user is allowed to create 'selfsubjectaccessreviews' "+
+				"by 'kubectl auth can-i create selfsubjectaccessreviews'.", "failed to review the permissions: %v", err)
+		}
+		if !allowed {
+			target := fmt.Sprintf("%s %s", p.verb, resource)
+			if len(ns) > 0 {
+				target += fmt.Sprintf(" in namespace '%s'", ns)
+			}
+			if len(reason) > 0 {
+				target += fmt.Sprintf(" (%s)", reason)
+			}
+			denied = append(denied, target)
+		}
+	}
+
+	if len(denied) > 0 {
+		return failed(name, "Grant the permissions to the user by the RBAC rules, which can be verified by "+
+			"'kubectl auth can-i <verb> <resource> -n <namespace>'. The operator and its CRDs can also be installed "+
+			"in advance by the cluster administrator.", "not allowed to %s", strings.Join(denied, ", "))
+	}
+	return passed(name, "allowed to create the operator and the clusters")
+}
+
+// CheckDNS checks whether the hosts of the artifact endpoints can be resolved.
+func CheckDNS(ctx context.Context, resolver *net.Resolver, hosts []string) *Result {
+	const name = "DNS"
+
+	var unresolved []string
+	for _, host := range hosts {
+		lookupCtx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+		_, err := resolver.LookupHost(lookupCtx, host)
+		cancel()
+		if err != nil {
+			unresolved = append(unresolved, host)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return failed(name, "Check the DNS servers of the host, e.g. in '/etc/resolv.conf', or download the artifacts "+
+			"from a reachable mirror by 'gtctl config set artifactMirrors <mirror>' or through a proxy by "+
+			"'gtctl config set proxy <url>'.", "failed to resolve %s", strings.Join(unresolved, ", "))
+	}
+	return passed(name, "resolved all the %d artifact endpoints", len(hosts))
+}
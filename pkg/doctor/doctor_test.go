@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+)
+
+func TestCheckPorts(t *testing.T) {
+	assert.Equal(t, StatusPass, CheckPorts(nil).Status)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	freeAddr := free.Addr().String()
+	assert.NoError(t, free.Close())
+
+	result := CheckPorts([]components.ListenAddr{{Replica: "frontend.0", Arg: "--http-addr", Addr: freeAddr}})
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Empty(t, result.Remediation)
+
+	result = CheckPorts([]components.ListenAddr{
+		{Replica: "frontend.0", Arg: "--http-addr", Addr: freeAddr},
+		{Replica: "datanode.0", Arg: "--http-addr", Addr: listener.Addr().String()},
+	})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "datanode.0")
+	assert.NotEmpty(t, result.Remediation)
+}
+
+func TestGlibcVersion(t *testing.T) {
+	version, ok := parseGlibcVersion("glibc 2.35\n")
+	assert.True(t, ok)
+	assert.Equal(t, "2.35", version)
+	_, ok = parseGlibcVersion("musl")
+	assert.False(t, ok)
+
+	assert.Equal(t, 0, compareVersions("2.28", "2.28"))
+	assert.Equal(t, 1, compareVersions("2.35", "2.28"))
+	assert.Equal(t, -1, compareVersions("2.17", "2.28"))
+	assert.Equal(t, 1, compareVersions("3.0", "2.28"))
+	assert.Equal(t, 1, compareVersions("2.28.1", "2.28"))
+}
+
+type fakeReviewer struct {
+	denied map[string]bool
+	err    error
+}
+
+func (r *fakeReviewer) CanI(_ context.Context, namespace, verb, _, resource string) (bool, string, error) {
+	if r.err != nil {
+		return false, "", r.err
+	}
+	key := fmt.Sprintf("%s/%s/%s", namespace, verb, resource)
+	return !r.denied[key], "", nil
+}
+
+func TestCheckKubePermissions(t *testing.T) {
+	ctx := context.Background()
+
+	result := CheckKubePermissions(ctx, &fakeReviewer{}, "greptimedb", "greptimedb-admin")
+	assert.Equal(t, StatusPass, result.Status)
+
+	result = CheckKubePermissions(ctx, &fakeReviewer{denied: map[string]bool{
+		"/create/customresourcedefinitions":   true,
+		"greptimedb-admin/create/deployments": true,
+		"greptimedb/create/secrets":           true,
+	}}, "greptimedb", "greptimedb-admin")
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "not allowed to create customresourcedefinitions.apiextensions.k8s.io, "+
+		"create deployments.apps in namespace 'greptimedb-admin', create secrets in namespace 'greptimedb'", result.Message)
+	assert.NotEmpty(t, result.Remediation)
+
+	result = CheckKubePermissions(ctx, &fakeReviewer{err: fmt.Errorf("forbidden")}, "greptimedb", "greptimedb-admin")
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "forbidden")
+}
+
+func TestCheckDNS(t *testing.T) {
+	ctx := context.Background()
+	resolver := &net.Resolver{PreferGo: true}
+
+	assert.Equal(t, StatusPass, CheckDNS(ctx, resolver, []string{"localhost"}).Status)
+
+	result := CheckDNS(ctx, resolver, []string{"localhost", "artifacts.gtctl.invalid"})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "failed to resolve artifacts.gtctl.invalid", result.Message)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the disk space is not checked on windows")
+	}
+
+	dir := t.TempDir()
+	assert.Equal(t, StatusPass, CheckDiskSpace(dir+"/not/created", 0).Status)
+
+	result := CheckDiskSpace(dir, 1<<62)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, dir)
+}
+
+func TestCount(t *testing.T) {
+	failures, warnings := Count([]*Result{
+		{Status: StatusPass}, {Status: StatusWarn}, {Status: StatusFail}, {Status: StatusFail},
+	})
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, 1, warnings)
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// CheckOpenFiles checks whether the limit of open files is high enough for the replicas.
+func CheckOpenFiles() *Result {
+	const name = "Open files limit"
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return warned(name, "", "failed to get the limit of open files: %v", err)
+	}
+	if limit.Cur >= RecommendedOpenFiles {
+		return passed(name, "the limit of open files is %d", limit.Cur)
+	}
+
+	remediation := "Raise the limit by 'ulimit -n 65536' in the shell that runs gtctl, and set 'nofile' in " +
+		"'/etc/security/limits.conf' to keep it after logging in again."
+	if runtime.GOOS == "darwin" {
+		remediation = "Raise the limit by 'ulimit -n 65536' in the shell that runs gtctl, " +
+			"or 'sudo launchctl limit maxfiles 65536 200000' for all the processes."
+	}
+	return warned(name, remediation, "the limit of open files is %d, which is lower than the recommended %d",
+		limit.Cur, RecommendedOpenFiles)
+}
+
+// CheckDiskSpace checks whether the free space of the disk that dir is on is at least required bytes.
+// The nearest existing parent of dir is checked if it doesn't exist yet.
+func CheckDiskSpace(dir string, required uint64) *Result {
+	const name = "Disk space"
+
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return warned(name, "", "failed to get the free space of '%s': %v", dir, err)
+	}
+
+	free := uint64(stat.Bavail) * uint64(stat.Bsize)
+	if free < required {
+		return failed(name, "Free up the disk space, the data and logs of the clusters in bare-metal mode are stored in "+
+			"'~/.gtctl'.", "%s free in '%s', which is less than %s", formatGiB(free), dir,
+			formatGiB(required))
+	}
+	return passed(name, "%s free in '%s'", formatGiB(free), dir)
+}
+
+// formatGiB formats the bytes in GiB, e.g. "10.5GiB".
+func formatGiB(bytes uint64) string {
+	return fmt.Sprintf("%.1fGiB", float64(bytes)/(1<<30))
+}
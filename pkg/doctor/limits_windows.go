@@ -0,0 +1,29 @@
+//go:build windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doctor
+
+// CheckOpenFiles returns nil on Windows, which has no limit of open files like ulimit.
+func CheckOpenFiles() *Result {
+	return nil
+}
+
+// CheckDiskSpace returns nil on Windows, where the free disk space is not checked.
+func CheckDiskSpace(dir string, required uint64) *Result {
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package events publishes the lifecycle events of clusters, e.g. the components are started or crashed and
+// the cluster is ready or upgraded, to the sinks like stdout, webhooks and Slack, so the CI systems and chatops
+// can react to the changes of clusters managed by gtctl.
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// The types of events.
+const (
+	// TypeComponentStarted is published when a replica of component is started or restarted.
+	TypeComponentStarted = "ComponentStarted"
+
+	// TypeComponentCrashed is published when a replica of component exits abnormally.
+	TypeComponentCrashed = "ComponentCrashed"
+
+	// TypeClusterReady is published when the cluster is created and ready to serve.
+	TypeClusterReady = "ClusterReady"
+
+	// TypeUpgradeCompleted is published when the cluster is upgraded to the new version.
+	TypeUpgradeCompleted = "UpgradeCompleted"
+)
+
+const (
+	// queueSize is the number of events buffered before they are delivered,
+	// the events are dropped if the queue is full, so publishing never blocks the cluster.
+	queueSize = 256
+
+	// sendTimeout is the timeout of delivering one event to one sink.
+	sendTimeout = 10 * time.Second
+)
+
+// Event is a lifecycle event of cluster.
+type Event struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace,omitempty"`
+
+	// Component is the component or the replica of component that the event is about, e.g. 'frontend.0'.
+	Component string `json:"component,omitempty"`
+
+	Message    string            `json:"message,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// String returns the human-readable summary of event.
+func (e *Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] cluster '%s'", e.Type, e.Cluster)
+	if len(e.Namespace) > 0 {
+		fmt.Fprintf(&b, " in namespace '%s'", e.Namespace)
+	}
+	if len(e.Component) > 0 {
+		fmt.Fprintf(&b, ", component '%s'", e.Component)
+	}
+	if len(e.Message) > 0 {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	return b.String()
+}
+
+// Bus delivers the published events of one cluster to the sinks in the background.
+// A nil Bus is valid and drops all the events, so the publishers don't need to check whether it's configured.
+type Bus struct {
+	cluster   string
+	namespace string
+	sinks     []Sink
+	logger    logger.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *Event
+	done   chan struct{}
+}
+
+// NewBus creates the bus for the events of cluster and starts delivering them to the sinks.
+// It returns nil if there are no sinks.
+func NewBus(l logger.Logger, cluster, namespace string, sinks ...Sink) *Bus {
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	b := &Bus{
+		cluster:   cluster,
+		namespace: namespace,
+		sinks:     sinks,
+		logger:    l,
+		queue:     make(chan *Event, queueSize),
+		done:      make(chan struct{}),
+	}
+	go b.deliver()
+
+	return b
+}
+
+// Publish publishes the event asynchronously. The cluster, namespace and timestamp of event are filled by the bus
+// if they are empty. The event is dropped if the bus is closed or too many events are waiting to be delivered.
+func (b *Bus) Publish(event *Event) {
+	if b == nil {
+		return
+	}
+
+	if len(event.Cluster) == 0 {
+		event.Cluster, event.Namespace = b.cluster, b.namespace
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	select {
+	case b.queue <- event:
+	default:
+		b.logger.Warnf("Too many events are waiting to be delivered, event %s is dropped", event)
+	}
+}
+
+// Close stops accepting the events and waits for the published ones to be delivered.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	<-b.done
+}
+
+// deliver sends the events to all the sinks in the order they are published,
+// the failures are only warned since the events are best-effort notifications.
+func (b *Bus) deliver() {
+	defer close(b.done)
+
+	for event := range b.queue {
+		for _, sink := range b.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			if err := sink.Send(ctx, event); err != nil {
+				b.logger.Warnf("Failed to send event %s to %s: %v", event, sink, err)
+			}
+			cancel()
+		}
+	}
+}
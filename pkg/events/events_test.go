@@ -0,0 +1,122 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestBus(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		posted  []Event
+		slacked []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/webhook":
+			var event Event
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+			posted = append(posted, event)
+		case "/slack":
+			var msg map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			slacked = append(slacked, msg["text"])
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	bus := NewBus(logger.New(io.Discard, 0), "mycluster", "", NewWriterSink("buffer", &out),
+		NewWebhookSink(server.URL+"/webhook"), NewSlackSink(server.URL+"/slack"))
+	bus.Publish(&Event{Type: TypeComponentStarted, Component: "frontend.0", Message: "started with pid '42'"})
+	bus.Publish(&Event{Type: TypeClusterReady, Cluster: "other", Namespace: "default"})
+	bus.Close()
+
+	// The events published after the bus is closed are dropped.
+	bus.Publish(&Event{Type: TypeComponentCrashed})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	var first Event
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, TypeComponentStarted, first.Type)
+	assert.Equal(t, "mycluster", first.Cluster)
+	assert.Equal(t, "frontend.0", first.Component)
+	assert.False(t, first.Timestamp.IsZero())
+
+	assert.Len(t, posted, 2)
+	assert.Equal(t, TypeClusterReady, posted[1].Type)
+	assert.Equal(t, "other", posted[1].Cluster)
+	assert.Equal(t, "default", posted[1].Namespace)
+	assert.Equal(t, []string{
+		"[ComponentStarted] cluster 'mycluster', component 'frontend.0': started with pid '42'",
+		"[ClusterReady] cluster 'other' in namespace 'default'",
+	}, slacked)
+
+	// The failures of sinks don't block the others.
+	out.Reset()
+	bus = NewBus(logger.New(io.Discard, 0), "mycluster", "", NewWebhookSink(server.URL+"/unknown"),
+		NewWriterSink("buffer", &out))
+	bus.Publish(&Event{Type: TypeUpgradeCompleted})
+	bus.Close()
+	assert.Contains(t, out.String(), TypeUpgradeCompleted)
+
+	// The nil bus drops all the events.
+	var nilBus *Bus
+	nilBus.Publish(&Event{Type: TypeClusterReady})
+	nilBus.Close()
+	assert.Nil(t, NewBus(logger.New(io.Discard, 0), "mycluster", ""))
+}
+
+func TestParseSinks(t *testing.T) {
+	sinks, err := ParseSinks([]string{"stdout", " stderr", "webhook:https://ci.example.com/hooks/gtctl",
+		"slack:https://hooks.slack.com/services/T0/B0/secret"})
+	assert.NoError(t, err)
+	assert.Len(t, sinks, 4)
+	assert.Equal(t, os.Stdout, sinks[0].(*writerSink).writer)
+	assert.Equal(t, "webhook 'https://ci.example.com/******'", sinks[2].String())
+	assert.Equal(t, "slack 'https://hooks.slack.com/******'", sinks[3].String())
+
+	sinks, err = ParseSinks(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, sinks)
+
+	for _, spec := range []string{"file", "webhook", "webhook:", "slack:hooks.slack.com/services", "webhook:ftp://example.com"} {
+		_, err = ParseSinks([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// The kinds of sinks in the sink specs, see ParseSinks.
+const (
+	SinkStdout  = "stdout"
+	SinkStderr  = "stderr"
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+)
+
+// Sink is where the events are delivered to.
+type Sink interface {
+	// Send delivers the event to the sink.
+	Send(ctx context.Context, event *Event) error
+
+	// String returns the description of sink in the logs, which doesn't leak the secrets in URL.
+	String() string
+}
+
+// ParseSinks parses the sink specs, each one is one of:
+//
+//	stdout, stderr      write one JSON event per line
+//	webhook:<url>       POST the JSON event to the URL
+//	slack:<url>         POST the summary of event to the Slack incoming webhook URL
+func ParseSinks(specs []string) ([]Sink, error) {
+	var sinks []Sink
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		kind, target := spec, ""
+		if i := strings.Index(spec, ":"); i >= 0 {
+			kind, target = spec[:i], spec[i+1:]
+		}
+
+		switch kind {
+		case SinkStdout:
+			sinks = append(sinks, NewWriterSink(SinkStdout, os.Stdout))
+		case SinkStderr:
+			sinks = append(sinks, NewWriterSink(SinkStderr, os.Stderr))
+		case SinkWebhook, SinkSlack:
+			u, err := url.Parse(target)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+				return nil, fmt.Errorf("invalid URL of event sink '%s', it should be an http(s) URL", spec)
+			}
+			if kind == SinkWebhook {
+				sinks = append(sinks, NewWebhookSink(target))
+			} else {
+				sinks = append(sinks, NewSlackSink(target))
+			}
+		default:
+			return nil, fmt.Errorf("invalid event sink '%s', it should be one of '%s', '%s', '%s:<url>' and '%s:<url>'",
+				spec, SinkStdout, SinkStderr, SinkWebhook, SinkSlack)
+		}
+	}
+	return sinks, nil
+}
+
+// writerSink writes one JSON event per line.
+type writerSink struct {
+	name string
+
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+var _ Sink = &writerSink{}
+
+// NewWriterSink creates the sink that writes one JSON event per line to the writer.
+func NewWriterSink(name string, w io.Writer) Sink {
+	return &writerSink{name: name, writer: w}
+}
+
+func (s *writerSink) Send(_ context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(append(data, '\n'))
+	return err
+}
+
+func (s *writerSink) String() string {
+	return s.name
+}
+
+// webhookSink posts the JSON event to the URL.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+var _ Sink = &webhookSink{}
+
+// NewWebhookSink creates the sink that posts the JSON event to the URL.
+func NewWebhookSink(webhookURL string) Sink {
+	return &webhookSink{url: webhookURL, client: &http.Client{}}
+}
+
+func (s *webhookSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, data)
+}
+
+func (s *webhookSink) String() string {
+	return fmt.Sprintf("webhook '%s'", redactURL(s.url))
+}
+
+// slackSink posts the summary of event to the Slack incoming webhook.
+type slackSink struct {
+	url    string
+	client *http.Client
+}
+
+var _ Sink = &slackSink{}
+
+// NewSlackSink creates the sink that posts the summary of event to the Slack incoming webhook URL.
+func NewSlackSink(webhookURL string) Sink {
+	return &slackSink{url: webhookURL, client: &http.Client{}}
+}
+
+func (s *slackSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(map[string]string{"text": event.String()})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, data)
+}
+
+func (s *slackSink) String() string {
+	return fmt.Sprintf("slack '%s'", redactURL(s.url))
+}
+
+// post posts the JSON body to the URL and checks the response status.
+func post(ctx context.Context, client *http.Client, webhookURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status '%s': %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// redactURL keeps only the scheme and host of URL, since the paths of webhooks like Slack's contain the secrets.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "******"
+	}
+	return fmt.Sprintf("%s://%s/******", u.Scheme, u.Host)
+}
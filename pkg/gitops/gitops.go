@@ -0,0 +1,223 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Tool is the GitOps tool that the releases are handed over to.
+type Tool string
+
+const (
+	ToolArgoCD Tool = "argocd"
+	ToolFlux   Tool = "flux"
+)
+
+const (
+	// DefaultArgoCDNamespace is the namespace that the Argo CD Applications are created in by default.
+	DefaultArgoCDNamespace = "argocd"
+
+	// DefaultFluxNamespace is the namespace that the Flux sources and HelmReleases are created in by default.
+	DefaultFluxNamespace = "flux-system"
+
+	// inClusterServer is the API server of the cluster that Argo CD runs in.
+	inClusterServer = "https://kubernetes.default.svc"
+
+	ociScheme = "oci://"
+)
+
+// Release is one helm release to be managed by the GitOps tool.
+type Release struct {
+	// Name and Namespace are the name and namespace of the release.
+	Name      string
+	Namespace string
+
+	// Chart is the chart of the release in the repository, and the Version should be pinned.
+	Chart      string
+	Version    string
+	Repository string
+
+	// Values are the values of the release.
+	Values map[string]interface{}
+}
+
+// ExportOptions is the options to export the releases as the resources of the GitOps tool.
+type ExportOptions struct {
+	Tool Tool
+
+	// Namespace is where the resources of the GitOps tool are created, e.g. 'argocd' or 'flux-system'.
+	Namespace string
+
+	Releases []*Release
+}
+
+// Export returns the manifests of the resources that the GitOps tool manages the releases with,
+// which are the Argo CD Applications, or the Flux HelmRepositories and HelmReleases.
+func Export(options *ExportOptions) ([]byte, error) {
+	namespace := options.Namespace
+	var objs []interface{}
+	switch options.Tool {
+	case ToolArgoCD:
+		if len(namespace) == 0 {
+			namespace = DefaultArgoCDNamespace
+		}
+		for _, release := range options.Releases {
+			app, err := argoCDApplication(release, namespace)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, app)
+		}
+	case ToolFlux:
+		if len(namespace) == 0 {
+			namespace = DefaultFluxNamespace
+		}
+		repositories := make(map[string]bool)
+		for _, release := range options.Releases {
+			repository := fluxHelmRepository(release, namespace)
+			if name := repository["metadata"].(map[string]interface{})["name"].(string); !repositories[name] {
+				repositories[name] = true
+				objs = append(objs, repository)
+			}
+			objs = append(objs, fluxHelmRelease(release, namespace))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported GitOps tool '%s', it should be '%s' or '%s'", options.Tool, ToolArgoCD, ToolFlux)
+	}
+
+	var out bytes.Buffer
+	for _, obj := range objs {
+		raw, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(raw)
+	}
+	return out.Bytes(), nil
+}
+
+func argoCDApplication(release *Release, namespace string) (map[string]interface{}, error) {
+	values, err := yaml.Marshal(release.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	source := map[string]interface{}{
+		// Argo CD takes the OCI registry without the scheme as the repository.
+		"repoURL":        strings.TrimPrefix(release.Repository, ociScheme),
+		"chart":          release.Chart,
+		"targetRevision": release.Version,
+		"helm": map[string]interface{}{
+			"releaseName": release.Name,
+			"values":      string(values),
+		},
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":      release.Name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"source":  source,
+			"destination": map[string]interface{}{
+				"server":    inClusterServer,
+				"namespace": release.Namespace,
+			},
+			"syncPolicy": map[string]interface{}{
+				"syncOptions": []interface{}{"CreateNamespace=true"},
+			},
+		},
+	}, nil
+}
+
+func fluxHelmRepository(release *Release, namespace string) map[string]interface{} {
+	spec := map[string]interface{}{
+		"interval": "1h",
+		"url":      release.Repository,
+	}
+	if strings.HasPrefix(release.Repository, ociScheme) {
+		spec["type"] = "oci"
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "source.toolkit.fluxcd.io/v1beta2",
+		"kind":       "HelmRepository",
+		"metadata": map[string]interface{}{
+			"name":      repositoryName(release.Repository),
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}
+}
+
+func fluxHelmRelease(release *Release, namespace string) map[string]interface{} {
+	spec := map[string]interface{}{
+		"interval":        "10m",
+		"releaseName":     release.Name,
+		"targetNamespace": release.Namespace,
+		"install": map[string]interface{}{
+			"createNamespace": true,
+		},
+		"chart": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"chart":   release.Chart,
+				"version": release.Version,
+				"sourceRef": map[string]interface{}{
+					"kind": "HelmRepository",
+					"name": repositoryName(release.Repository),
+				},
+			},
+		},
+	}
+	if len(release.Values) > 0 {
+		spec["values"] = release.Values
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "helm.toolkit.fluxcd.io/v2beta1",
+		"kind":       "HelmRelease",
+		"metadata": map[string]interface{}{
+			"name":      release.Name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}
+}
+
+// repositoryName returns the name of the HelmRepository of the repository URL, e.g. 'greptimeteam-github-io-helm-charts'.
+func repositoryName(repository string) string {
+	name := repository
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	name = strings.ToLower(strings.Trim(name, "/"))
+	name = strings.NewReplacer(".", "-", "/", "-", ":", "-", "_", "-").Replace(name)
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
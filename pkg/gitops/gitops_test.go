@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func testReleases() []*Release {
+	return []*Release{
+		{
+			Name:       "mycluster",
+			Namespace:  "default",
+			Chart:      "greptimedb-cluster",
+			Version:    "0.1.10",
+			Repository: "https://greptimeteam.github.io/helm-charts",
+			Values:     map[string]interface{}{"meta": map[string]interface{}{"replicas": 3}},
+		},
+		{
+			Name:       "greptimedb-operator",
+			Namespace:  "greptimedb-admin",
+			Chart:      "greptimedb-operator",
+			Version:    "0.1.3",
+			Repository: "https://greptimeteam.github.io/helm-charts",
+		},
+	}
+}
+
+func splitObjects(t *testing.T, manifests []byte) []map[string]interface{} {
+	var objs []map[string]interface{}
+	for _, doc := range strings.Split(string(manifests), "---\n") {
+		var obj map[string]interface{}
+		assert.NoError(t, yaml.Unmarshal([]byte(doc), &obj))
+		if len(obj) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+func TestExportArgoCD(t *testing.T) {
+	out, err := Export(&ExportOptions{Tool: ToolArgoCD, Releases: testReleases()})
+	assert.NoError(t, err)
+
+	objs := splitObjects(t, out)
+	assert.Len(t, objs, 2)
+
+	app := objs[0]
+	assert.Equal(t, "Application", app["kind"])
+	assert.Equal(t, DefaultArgoCDNamespace, app["metadata"].(map[string]interface{})["namespace"])
+
+	spec := app["spec"].(map[string]interface{})
+	source := spec["source"].(map[string]interface{})
+	assert.Equal(t, "greptimedb-cluster", source["chart"])
+	assert.Equal(t, "0.1.10", source["targetRevision"])
+	assert.Equal(t, "https://greptimeteam.github.io/helm-charts", source["repoURL"])
+	assert.Equal(t, "meta:\n  replicas: 3\n", source["helm"].(map[string]interface{})["values"])
+	assert.Equal(t, "default", spec["destination"].(map[string]interface{})["namespace"])
+}
+
+func TestExportFlux(t *testing.T) {
+	out, err := Export(&ExportOptions{Tool: ToolFlux, Namespace: "gitops", Releases: testReleases()})
+	assert.NoError(t, err)
+
+	// The releases from the same repository share one HelmRepository.
+	objs := splitObjects(t, out)
+	assert.Len(t, objs, 3)
+
+	repository := objs[0]
+	assert.Equal(t, "HelmRepository", repository["kind"])
+	assert.Equal(t, "greptimeteam-github-io-helm-charts", repository["metadata"].(map[string]interface{})["name"])
+
+	release := objs[1]
+	assert.Equal(t, "HelmRelease", release["kind"])
+	assert.Equal(t, "gitops", release["metadata"].(map[string]interface{})["namespace"])
+	spec := release["spec"].(map[string]interface{})
+	assert.Equal(t, "default", spec["targetNamespace"])
+	assert.Equal(t, map[string]interface{}{"meta": map[string]interface{}{"replicas": float64(3)}}, spec["values"])
+	chart := spec["chart"].(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, "0.1.10", chart["version"])
+	assert.Equal(t, "greptimeteam-github-io-helm-charts", chart["sourceRef"].(map[string]interface{})["name"])
+
+	// The operator has no values.
+	_, ok := objs[2]["spec"].(map[string]interface{})["values"]
+	assert.False(t, ok)
+}
+
+func TestExportOCIRepository(t *testing.T) {
+	releases := []*Release{{Name: "mycluster", Namespace: "default", Chart: "greptimedb-cluster", Version: "0.1.10",
+		Repository: "oci://registry.example.com/charts"}}
+
+	out, err := Export(&ExportOptions{Tool: ToolFlux, Releases: releases})
+	assert.NoError(t, err)
+	objs := splitObjects(t, out)
+	assert.Equal(t, "oci", objs[0]["spec"].(map[string]interface{})["type"])
+
+	out, err = Export(&ExportOptions{Tool: ToolArgoCD, Releases: releases})
+	assert.NoError(t, err)
+	objs = splitObjects(t, out)
+	assert.Equal(t, "registry.example.com/charts", objs[0]["spec"].(map[string]interface{})["source"].(map[string]interface{})["repoURL"])
+}
+
+func TestExportUnsupportedTool(t *testing.T) {
+	_, err := Export(&ExportOptions{Tool: "jenkins", Releases: testReleases()})
+	assert.Error(t, err)
+}
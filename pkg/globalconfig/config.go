@@ -0,0 +1,316 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package globalconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/GreptimeTeam/gtctl/pkg/events"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// File is the path of the global config file of gtctl relative to the home directory.
+const File = ".gtctl/config.yaml"
+
+// The deployment modes of the clusters.
+const (
+	DeploymentModeKubernetes = "kubernetes"
+	DeploymentModeBareMetal  = "bare-metal"
+	DeploymentModeDocker     = "docker"
+)
+
+// Config is the global config of gtctl, which provides the defaults shared by all the commands.
+// The flags set in the command line take precedence over it.
+type Config struct {
+	// ArtifactMirrors are the mirrors to download the binaries and charts from, they are tried in order
+	// before the official sources. A mirror has the same layout as 'https://downloads.greptime.cn/releases':
+	//
+	//	${mirror}/greptimedb/${version}/greptime-${os}-${arch}-${version}.tar.gz
+	//	${mirror}/etcd/${version}/etcd-${version}-${os}-${arch}.tar.gz
+	//	${mirror}/charts/${name}/${version}/${name}-${version}.tgz
+	//	${mirror}/{greptimedb,etcd,charts/${name}}/latest-version.txt
+	ArtifactMirrors []string `json:"artifactMirrors,omitempty"`
+
+	// Proxy is the HTTP(S) proxy to download the artifacts with, e.g. 'http://proxy.example.com:3128'.
+	// The HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used if it's empty.
+	Proxy string `json:"proxy,omitempty"`
+
+	// Namespace is the default namespace of the clusters.
+	Namespace string `json:"namespace,omitempty"`
+
+	// DeploymentMode is the default deployment mode of the clusters, which is one of 'kubernetes',
+	// 'bare-metal' and 'docker'.
+	DeploymentMode string `json:"deploymentMode,omitempty"`
+
+	// LogLevel is the default verbosity of the logs, higher value produces more output.
+	LogLevel *int32 `json:"logLevel,omitempty"`
+
+	// Telemetry is true to opt in to the usage reporting of gtctl, and false to opt out of it and the telemetry
+	// of the greptime processes started by gtctl. The usage of gtctl is never reported if it's not set.
+	Telemetry *bool `json:"telemetry,omitempty"`
+
+	// TelemetryEndpoint is the HTTP(S) URL that the usage of gtctl is reported to. There is no default endpoint,
+	// so the usage is not reported if it's not set, even if the telemetry is opted in.
+	TelemetryEndpoint string `json:"telemetryEndpoint,omitempty"`
+
+	// GreptimeVersion is the default version of greptime binary.
+	GreptimeVersion string `json:"greptimeVersion,omitempty"`
+
+	// AuditConfigMap is true to also record the mutations of the clusters on Kubernetes into the ConfigMaps in
+	// their namespaces, so the history is shared by all the users of the clusters.
+	AuditConfigMap bool `json:"auditConfigMap,omitempty"`
+
+	// EventSinks are where the lifecycle events of clusters are published to, e.g. 'stdout',
+	// 'webhook:https://ci.example.com/hooks/gtctl' and 'slack:https://hooks.slack.com/services/...'.
+	EventSinks []string `json:"eventSinks,omitempty"`
+}
+
+// key is a key of the global config that can be got and set by 'gtctl config'.
+type key struct {
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+var keys = map[string]key{
+	"artifactMirrors": {
+		get: func(c *Config) string { return strings.Join(c.ArtifactMirrors, ",") },
+		set: func(c *Config, value string) error {
+			c.ArtifactMirrors = nil
+			for _, mirror := range strings.Split(value, ",") {
+				if mirror = strings.TrimSpace(mirror); mirror != "" {
+					c.ArtifactMirrors = append(c.ArtifactMirrors, mirror)
+				}
+			}
+			return nil
+		},
+	},
+	"proxy": {
+		get: func(c *Config) string { return c.Proxy },
+		set: func(c *Config, value string) error { c.Proxy = value; return nil },
+	},
+	"namespace": {
+		get: func(c *Config) string { return c.Namespace },
+		set: func(c *Config, value string) error { c.Namespace = value; return nil },
+	},
+	"deploymentMode": {
+		get: func(c *Config) string { return c.DeploymentMode },
+		set: func(c *Config, value string) error {
+			switch value {
+			case "", DeploymentModeKubernetes, DeploymentModeBareMetal, DeploymentModeDocker:
+				c.DeploymentMode = value
+				return nil
+			default:
+				return fmt.Errorf("invalid deployment mode '%s', it should be one of '%s', '%s' and '%s'",
+					value, DeploymentModeKubernetes, DeploymentModeBareMetal, DeploymentModeDocker)
+			}
+		},
+	},
+	"logLevel": {
+		get: func(c *Config) string {
+			if c.LogLevel == nil {
+				return ""
+			}
+			return strconv.Itoa(int(*c.LogLevel))
+		},
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.LogLevel = nil
+				return nil
+			}
+			level, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || level < 0 {
+				return fmt.Errorf("invalid log level '%s', it should be a non-negative integer", value)
+			}
+			l := int32(level)
+			c.LogLevel = &l
+			return nil
+		},
+	},
+	"telemetry": {
+		get: func(c *Config) string {
+			if c.Telemetry == nil {
+				return ""
+			}
+			return strconv.FormatBool(*c.Telemetry)
+		},
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.Telemetry = nil
+				return nil
+			}
+			enabled, err := ParseSwitch(value)
+			if err != nil {
+				return fmt.Errorf("invalid telemetry '%s', it should be 'on', 'off', 'true' or 'false'", value)
+			}
+			c.Telemetry = &enabled
+			return nil
+		},
+	},
+	"telemetryEndpoint": {
+		get: func(c *Config) string { return c.TelemetryEndpoint },
+		set: func(c *Config, value string) error {
+			if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+				return fmt.Errorf("invalid telemetry endpoint '%s', it should be an HTTP(S) URL", value)
+			}
+			c.TelemetryEndpoint = value
+			return nil
+		},
+	},
+	"greptimeVersion": {
+		get: func(c *Config) string { return c.GreptimeVersion },
+		set: func(c *Config, value string) error { c.GreptimeVersion = value; return nil },
+	},
+	"auditConfigMap": {
+		get: func(c *Config) string { return strconv.FormatBool(c.AuditConfigMap) },
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.AuditConfigMap = false
+				return nil
+			}
+			enabled, err := ParseSwitch(value)
+			if err != nil {
+				return fmt.Errorf("invalid auditConfigMap '%s', it should be 'on', 'off', 'true' or 'false'", value)
+			}
+			c.AuditConfigMap = enabled
+			return nil
+		},
+	},
+	"eventSinks": {
+		get: func(c *Config) string { return strings.Join(c.EventSinks, ",") },
+		set: func(c *Config, value string) error {
+			var sinks []string
+			for _, sink := range strings.Split(value, ",") {
+				if sink = strings.TrimSpace(sink); sink != "" {
+					sinks = append(sinks, sink)
+				}
+			}
+			if _, err := events.ParseSinks(sinks); err != nil {
+				return err
+			}
+			c.EventSinks = sinks
+			return nil
+		},
+	},
+}
+
+// Keys returns the sorted keys of the global config.
+func Keys() []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value of the key, it's empty if the key is not set.
+func (c *Config) Get(name string) (string, error) {
+	k, ok := keys[name]
+	if !ok {
+		return "", fmt.Errorf("unknown key '%s', it should be one of %s", name, strings.Join(Keys(), ", "))
+	}
+	return k.get(c), nil
+}
+
+// Set sets the value of the key, the key is unset if the value is empty.
+func (c *Config) Set(name, value string) error {
+	k, ok := keys[name]
+	if !ok {
+		return fmt.Errorf("unknown key '%s', it should be one of %s", name, strings.Join(Keys(), ", "))
+	}
+	return k.set(c, value)
+}
+
+// TelemetryDisabled returns whether the telemetry is opted out.
+func (c *Config) TelemetryDisabled() bool {
+	return c.Telemetry != nil && !*c.Telemetry
+}
+
+// TelemetryEnabled returns whether the telemetry is explicitly opted in.
+func (c *Config) TelemetryEnabled() bool {
+	return c.Telemetry != nil && *c.Telemetry
+}
+
+// ParseSwitch parses the switch value, which is 'on' or 'off' besides the boolean values accepted by strconv.ParseBool.
+func ParseSwitch(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(value)
+	}
+}
+
+// Load loads the global config from ${homeDir}/.gtctl/config.yaml, the user home directory is used
+// if homeDir is empty. An empty config is returned if the file doesn't exist.
+func Load(homeDir string) (*Config, error) {
+	configFile, err := path(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid global config '%s': %v", configFile, err)
+	}
+	return cfg, nil
+}
+
+// Save saves the global config to ${homeDir}/.gtctl/config.yaml, the user home directory is used if homeDir is empty.
+func Save(homeDir string, cfg *Config) error {
+	configFile, err := path(homeDir)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := fileutils.EnsureDir(filepath.Dir(configFile)); err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, data, 0644)
+}
+
+func path(homeDir string) (string, error) {
+	if homeDir == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		homeDir = dir
+	}
+	return filepath.Join(homeDir, File), nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package globalconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	homeDir := t.TempDir()
+
+	cfg, err := Load(homeDir)
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ArtifactMirrors)
+
+	configFile := filepath.Join(homeDir, File)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0755))
+	assert.NoError(t, os.WriteFile(configFile, []byte(`
+artifactMirrors:
+  - https://mirror.example.com/greptime
+  - greptime-cn
+proxy: http://proxy.example.com:3128
+namespace: greptimedb
+deploymentMode: bare-metal
+logLevel: 3
+telemetry: false
+`), 0644))
+
+	cfg, err = Load(homeDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://mirror.example.com/greptime", "greptime-cn"}, cfg.ArtifactMirrors)
+	assert.Equal(t, "http://proxy.example.com:3128", cfg.Proxy)
+	assert.Equal(t, "greptimedb", cfg.Namespace)
+	assert.Equal(t, DeploymentModeBareMetal, cfg.DeploymentMode)
+	assert.Equal(t, int32(3), *cfg.LogLevel)
+	assert.True(t, cfg.TelemetryDisabled())
+
+	assert.NoError(t, os.WriteFile(configFile, []byte("mirrors: []\n"), 0644))
+	_, err = Load(homeDir)
+	assert.Error(t, err)
+}
+
+func TestSetAndGet(t *testing.T) {
+	homeDir := t.TempDir()
+	cfg := &Config{}
+
+	tests := []struct {
+		key, value, want string
+	}{
+		{"artifactMirrors", " https://a.example.com, greptime-cn,", "https://a.example.com,greptime-cn"},
+		{"namespace", "greptimedb", "greptimedb"},
+		{"deploymentMode", "docker", "docker"},
+		{"logLevel", "2", "2"},
+		{"telemetry", "on", "true"},
+		{"telemetry", "false", "false"},
+		{"telemetryEndpoint", "https://telemetry.example.com/events", "https://telemetry.example.com/events"},
+		{"greptimeVersion", "v0.9.0", "v0.9.0"},
+		{"auditConfigMap", "on", "true"},
+		{"eventSinks", "stdout, slack:https://hooks.slack.com/services/T0/B0/X,", "stdout,slack:https://hooks.slack.com/services/T0/B0/X"},
+	}
+	for _, tt := range tests {
+		assert.NoError(t, cfg.Set(tt.key, tt.value))
+		got, err := cfg.Get(tt.key)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	assert.Error(t, cfg.Set("deploymentMode", "k8s"))
+	assert.Error(t, cfg.Set("logLevel", "-1"))
+	assert.Error(t, cfg.Set("telemetry", "maybe"))
+	assert.Error(t, cfg.Set("telemetryEndpoint", "telemetry.example.com"))
+	assert.Error(t, cfg.Set("eventSinks", "webhook:ftp://example.com"))
+	assert.Error(t, cfg.Set("unknown", "value"))
+	_, err := cfg.Get("unknown")
+	assert.Error(t, err)
+
+	assert.NoError(t, Save(homeDir, cfg))
+	loaded, err := Load(homeDir)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	// The empty value unsets the key.
+	assert.NoError(t, loaded.Set("logLevel", ""))
+	assert.Nil(t, loaded.LogLevel)
+	assert.False(t, (&Config{}).TelemetryDisabled())
+	assert.False(t, (&Config{}).TelemetryEnabled())
+
+	assert.NoError(t, loaded.Set("telemetry", "off"))
+	assert.True(t, loaded.TelemetryDisabled())
+	assert.False(t, loaded.TelemetryEnabled())
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"helm.sh/helm/v3/pkg/action"
@@ -31,6 +32,7 @@ import (
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
 var (
@@ -88,6 +90,21 @@ func WithHomeDir(dir string) Option {
 	}
 }
 
+// WithChartRepository loads the charts from the private chart repository instead of the public ones.
+func WithChartRepository(repository string) Option {
+	return func(r *Loader) {
+		if len(repository) == 0 {
+			return
+		}
+		am, err := artifacts.NewManager(r.logger, artifacts.WithChartRepository(repository))
+		if err != nil {
+			r.logger.Errorf("failed to create artifacts manager: %v", err)
+			os.Exit(1)
+		}
+		r.am = am
+	}
+}
+
 // LoadOptions is the options for running LoadAndRenderChart.
 type LoadOptions struct {
 	// ReleaseName is the name of the release.
@@ -111,53 +128,84 @@ type LoadOptions struct {
 	// ValuesFile is the path to the values file.
 	ValuesFile string
 
+	// Values are the values that are deep-merged onto the values file in order.
+	Values []map[string]interface{}
+
 	// EnableCache indicates whether to enable the cache.
 	EnableCache bool
 }
 
 // LoadAndRenderChart loads the chart from the remote charts and render the manifests with the values.
 func (r *Loader) LoadAndRenderChart(ctx context.Context, opts *LoadOptions) ([]byte, error) {
-	values, err := ToHelmValues(opts.ValuesOptions, opts.ValuesFile)
+	values, err := ToHelmValues(opts.ValuesOptions, opts.ValuesFile, opts.Values...)
 	if err != nil {
 		return nil, err
 	}
 	r.logger.V(3).Infof("create '%s' with values: %v", opts.ReleaseName, values)
 
-	if opts.ChartVersion == "" {
-		opts.ChartVersion = artifacts.LatestVersionTag
+	chartFile, err := r.DownloadChart(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	src, err := r.am.NewSource(opts.ChartName, opts.ChartVersion, artifacts.ArtifactTypeChart, opts.FromCNRegion)
+	data, err := os.ReadFile(chartFile)
 	if err != nil {
 		return nil, err
 	}
-
-	destDir, err := r.mm.AllocateArtifactFilePath(src, false)
+	helmChart, err := loader.LoadArchive(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
-	chartFile, err := r.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{EnableCache: opts.EnableCache})
+	manifests, err := r.generateManifests(ctx, opts.ReleaseName, opts.Namespace, helmChart, values)
 	if err != nil {
 		return nil, err
 	}
+	r.logger.V(3).Infof("create '%s' with manifests: %s", opts.ReleaseName, string(manifests))
 
-	data, err := os.ReadFile(chartFile)
+	return manifests, nil
+}
+
+// DownloadChart downloads the chart of opts into the cache directory and returns the path of the chart file.
+// The latest chart version of opts is resolved to the specific version.
+func (r *Loader) DownloadChart(ctx context.Context, opts *LoadOptions) (string, error) {
+	if opts.ChartVersion == "" {
+		opts.ChartVersion = artifacts.LatestVersionTag
+	}
+
+	src, err := r.am.NewSource(opts.ChartName, opts.ChartVersion, artifacts.ArtifactTypeChart, opts.FromCNRegion)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	helmChart, err := loader.LoadArchive(bytes.NewReader(data))
+	if opts.ChartVersion == artifacts.LatestVersionTag {
+		r.logger.V(0).Infof("Using the latest version '%s' of chart '%s', pin the chart version for reproducible deployments", src.Version, opts.ChartName)
+	}
+	opts.ChartVersion = src.Version
+
+	destDir, err := r.mm.AllocateArtifactFilePath(src, false)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	manifests, err := r.generateManifests(ctx, opts.ReleaseName, opts.Namespace, helmChart, values)
+	return r.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{EnableCache: opts.EnableCache})
+}
+
+// ImportChart imports the chart file of the version into the cache directory, so it's loaded without being downloaded.
+func (r *Loader) ImportChart(chartFile, chartName, version string) error {
+	src, err := r.am.NewSource(chartName, version, artifacts.ArtifactTypeChart, false)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	r.logger.V(3).Infof("create '%s' with manifests: %s", opts.ReleaseName, string(manifests))
 
-	return manifests, nil
+	destDir, err := r.mm.AllocateArtifactFilePath(src, false)
+	if err != nil {
+		return err
+	}
+	if err = fileutils.EnsureDir(destDir); err != nil {
+		return err
+	}
+
+	return fileutils.CopyFile(chartFile, filepath.Join(destDir, src.FileName))
 }
 
 func (r *Loader) generateManifests(ctx context.Context, releaseName, namespace string, chart *chart.Chart, values map[string]interface{}) ([]byte, error) {
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// WatchNamespaceEnv is the environment variable that restricts the operator to watch only one namespace.
+const WatchNamespaceEnv = "WATCH_NAMESPACE"
+
+// clusterScopedResources are the resources that the operator may access but can't be granted by Roles.
+var clusterScopedResources = map[string]bool{
+	"customresourcedefinitions":       true,
+	"namespaces":                      true,
+	"nodes":                           true,
+	"persistentvolumes":               true,
+	"storageclasses":                  true,
+	"clusterroles":                    true,
+	"clusterrolebindings":             true,
+	"mutatingwebhookconfigurations":   true,
+	"validatingwebhookconfigurations": true,
+}
+
+// manifestSeparator separates the objects in the rendered manifests.
+var manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// NamespaceScopedManifests rewrites the rendered manifests of the operator to only watch the namespace, so it can
+// be installed by the users without cluster-admin rights:
+//   - The CustomResourceDefinitions are dropped, they should be installed by the cluster admin in advance.
+//   - The ClusterRoles and ClusterRoleBindings are converted to the Roles and RoleBindings in the namespace,
+//     and the rules of the cluster-scoped resources are dropped.
+//   - The containers of Deployments are restricted to the namespace by the WatchNamespaceEnv.
+func NamespaceScopedManifests(manifests []byte, namespace string) ([]byte, error) {
+	return transformManifests(manifests, func(obj map[string]interface{}) (bool, error) {
+		switch obj["kind"] {
+		case "CustomResourceDefinition":
+			return false, nil
+		case "ClusterRole":
+			obj["kind"] = "Role"
+			setNamespace(obj, namespace)
+			obj["rules"] = namespacedRules(obj["rules"])
+		case "ClusterRoleBinding":
+			obj["kind"] = "RoleBinding"
+			setNamespace(obj, namespace)
+			if roleRef, ok := obj["roleRef"].(map[string]interface{}); ok && roleRef["kind"] == "ClusterRole" {
+				roleRef["kind"] = "Role"
+			}
+		case "Deployment":
+			if err := setWatchNamespace(obj, namespace); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// WithoutCRDs drops the CustomResourceDefinitions from the rendered manifests.
+func WithoutCRDs(manifests []byte) ([]byte, error) {
+	return transformManifests(manifests, func(obj map[string]interface{}) (bool, error) {
+		return obj["kind"] != "CustomResourceDefinition", nil
+	})
+}
+
+// transformManifests calls fn with each object in the manifests, which modifies the object in place
+// and returns whether to keep it.
+func transformManifests(manifests []byte, fn func(obj map[string]interface{}) (bool, error)) ([]byte, error) {
+	var out bytes.Buffer
+	for _, doc := range manifestSeparator.Split(string(manifests), -1) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %v", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		keep, err := fn(obj)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			continue
+		}
+
+		raw, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(raw)
+	}
+
+	return out.Bytes(), nil
+}
+
+func setNamespace(obj map[string]interface{}, namespace string) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		obj["metadata"] = metadata
+	}
+	metadata["namespace"] = namespace
+}
+
+// namespacedRules drops the cluster-scoped resources from the rules since they can't be granted by Roles,
+// the rules without any resources left are dropped.
+func namespacedRules(rules interface{}) []interface{} {
+	list, _ := rules.([]interface{})
+	ret := make([]interface{}, 0, len(list))
+	for _, rule := range list {
+		r, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		resources, _ := r["resources"].([]interface{})
+		namespaced := make([]interface{}, 0, len(resources))
+		for _, resource := range resources {
+			if name, ok := resource.(string); ok && !clusterScopedResources[strings.Split(name, "/")[0]] {
+				namespaced = append(namespaced, resource)
+			}
+		}
+		if len(namespaced) == 0 {
+			continue
+		}
+		r["resources"] = namespaced
+		delete(r, "nonResourceURLs")
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// setWatchNamespace sets the WatchNamespaceEnv of all the containers in the Deployment.
+func setWatchNamespace(obj map[string]interface{}, namespace string) error {
+	containers, ok := nestedSlice(obj, "spec", "template", "spec", "containers")
+	if !ok {
+		return fmt.Errorf("no containers found in deployment")
+	}
+
+	for _, container := range containers {
+		c, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		env, _ := c["env"].([]interface{})
+		c["env"] = append(env, map[string]interface{}{"name": WatchNamespaceEnv, "value": namespace})
+	}
+	return nil
+}
+
+func nestedSlice(obj map[string]interface{}, fields ...string) ([]interface{}, bool) {
+	var cur interface{} = obj
+	for _, field := range fields {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = m[field]
+	}
+	s, ok := cur.([]interface{})
+	return s, ok
+}
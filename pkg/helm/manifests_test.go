@@ -0,0 +1,126 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+const operatorManifests = `---
+# Source: greptimedb-operator/templates/crds.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: greptimedbclusters.greptime.io
+---
+# Source: greptimedb-operator/templates/role.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: greptimedb-operator-role
+rules:
+- apiGroups:
+  - apiextensions.k8s.io
+  resources:
+  - customresourcedefinitions
+  verbs:
+  - get
+- apiGroups:
+  - ""
+  resources:
+  - pods
+  - nodes
+  verbs:
+  - get
+  - list
+---
+# Source: greptimedb-operator/templates/role-binding.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: greptimedb-operator-role-binding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: greptimedb-operator-role
+subjects:
+- kind: ServiceAccount
+  name: greptimedb-operator
+  namespace: operator
+---
+# Source: greptimedb-operator/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: greptimedb-operator
+  namespace: operator
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: greptime/greptimedb-operator:latest
+`
+
+func TestNamespaceScopedManifests(t *testing.T) {
+	out, err := NamespaceScopedManifests([]byte(operatorManifests), "mynamespace")
+	assert.NoError(t, err)
+
+	var objs []map[string]interface{}
+	for _, doc := range manifestSeparator.Split(string(out), -1) {
+		var obj map[string]interface{}
+		assert.NoError(t, yaml.Unmarshal([]byte(doc), &obj))
+		if len(obj) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+	assert.Len(t, objs, 3)
+
+	role := objs[0]
+	assert.Equal(t, "Role", role["kind"])
+	assert.Equal(t, "mynamespace", role["metadata"].(map[string]interface{})["namespace"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"apiGroups": []interface{}{""},
+			"resources": []interface{}{"pods"},
+			"verbs":     []interface{}{"get", "list"},
+		},
+	}, role["rules"])
+
+	binding := objs[1]
+	assert.Equal(t, "RoleBinding", binding["kind"])
+	assert.Equal(t, "mynamespace", binding["metadata"].(map[string]interface{})["namespace"])
+	assert.Equal(t, "Role", binding["roleRef"].(map[string]interface{})["kind"])
+	assert.Equal(t, "operator", binding["subjects"].([]interface{})[0].(map[string]interface{})["namespace"])
+
+	containers, ok := nestedSlice(objs[2], "spec", "template", "spec", "containers")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": WatchNamespaceEnv, "value": "mynamespace"},
+	}, containers[0].(map[string]interface{})["env"])
+}
+
+func TestWithoutCRDs(t *testing.T) {
+	out, err := WithoutCRDs([]byte(operatorManifests))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "CustomResourceDefinition")
+	assert.Contains(t, string(out), "kind: ClusterRole\n")
+	assert.Contains(t, string(out), "kind: Deployment\n")
+}
@@ -50,8 +50,9 @@ func NewFromFile(filename string) (Values, error) {
 
 // ToHelmValues converts the input struct that contains special helm annotations `helm:"values"` and the local yaml values file to a map that can be used as helm values.
 // If there is the same key in both the input struct and the local yaml values file, the value in the input struct will be used.
+// The extraValues are deep-merged onto the values file in order, and are overridden by the input struct.
 // valuesFile can be empty.
-func ToHelmValues(input interface{}, valuesFile string) (Values, error) {
+func ToHelmValues(input interface{}, valuesFile string, extraValues ...map[string]interface{}) (Values, error) {
 	var (
 		base Values
 		err  error
@@ -64,6 +65,10 @@ func ToHelmValues(input interface{}, valuesFile string) (Values, error) {
 		}
 	}
 
+	for _, extra := range extraValues {
+		base = mergeMaps(base, extra)
+	}
+
 	vals, err := struct2Values(input)
 	if err != nil {
 		return nil, err
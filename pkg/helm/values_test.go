@@ -18,7 +18,10 @@ package helm
 
 import (
 	"os"
+	"reflect"
 	"testing"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 )
 
 func TestNewFromFile(t *testing.T) {
@@ -72,3 +75,86 @@ func TestToHelmValues(t *testing.T) {
 		t.Errorf("expected %s, got %s", string(original), string(output))
 	}
 }
+
+func TestToHelmValuesWithComponents(t *testing.T) {
+	v, err := ToHelmValues(opt.CreateClusterOptions{
+		FlownodeEnabled:     "true",
+		FlownodeReplicas:    "2",
+		FlownodeCPU:         "500m",
+		FlownodeCPULimit:    "500m",
+		FlownodeMemoryLimit: "1Gi",
+		FrontendMemory:      "2Gi",
+	}, "testdata/db-values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Values{
+		"meta": map[string]interface{}{"replicas": float64(1)},
+		"frontend": map[string]interface{}{
+			"replicas": float64(3),
+			"podTemplate": map[string]interface{}{
+				"main": map[string]interface{}{
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{"memory": "2Gi"},
+					},
+				},
+			},
+		},
+		"flownode": map[string]interface{}{
+			"enabled":  true,
+			"replicas": int64(2),
+			"podTemplate": map[string]interface{}{
+				"main": map[string]interface{}{
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{"cpu": "500m"},
+						"limits":   map[string]interface{}{"cpu": "500m", "memory": "1Gi"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, v) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestToHelmValuesWithRemoteWAL(t *testing.T) {
+	v, err := ToHelmValues(opt.CreateClusterOptions{
+		RemoteWALEnabled:     "true",
+		KafkaBrokerEndpoints: "{kafka-0:9092,kafka-1:9092}",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Values{
+		"remoteWal": map[string]interface{}{
+			"enabled": true,
+			"kafka": map[string]interface{}{
+				"brokerEndpoints": []interface{}{"kafka-0:9092", "kafka-1:9092"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, v) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestToHelmValuesWithExtraValues(t *testing.T) {
+	v, err := ToHelmValues(opt.CreateClusterOptions{ConfigValues: "meta.replicas=3"}, "testdata/db-values.yaml",
+		map[string]interface{}{"frontend": map[string]interface{}{"replicas": 2, "service": "LoadBalancer"}},
+		map[string]interface{}{"frontend": map[string]interface{}{"replicas": 4}, "meta": map[string]interface{}{"replicas": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The extra values are merged in order, and are overridden by the input struct.
+	expected := Values{
+		"meta":     map[string]interface{}{"replicas": int64(3)},
+		"frontend": map[string]interface{}{"replicas": 4, "service": "LoadBalancer"},
+	}
+	if !reflect.DeepEqual(expected, v) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
@@ -20,16 +20,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -41,7 +42,6 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 
 	greptimev1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 
@@ -63,19 +63,35 @@ var greptimeDBClusterGVR = schema.GroupVersionResource{
 	Resource: "greptimedbclusters",
 }
 
-func NewClient(kubeconfig string) (*Client, error) {
-	if kubeconfig == "" {
-		if home := homedir.HomeDir(); home != "" {
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		} else {
-			return nil, fmt.Errorf("kubeconfig not found")
-		}
-	}
+var customResourceDefinitionGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// podMetricsGVR is the metrics of pods served by the metrics-server.
+var podMetricsGVR = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
 
-	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// PodMetrics is the resource usage of all the containers of one pod reported by the metrics-server.
+type PodMetrics struct {
+	Name string
+
+	// CPUMillis is the cpu usage in millicores.
+	CPUMillis   int64
+	MemoryBytes int64
+}
+
+// NewClient creates the client of the Kubernetes cluster that the context in kubeconfig points to.
+// The default kubeconfig, e.g. '~/.kube/config' or the one in $KUBECONFIG, is used if kubeconfig is empty,
+// and the current context of kubeconfig is used if kubeContext is empty.
+func NewClient(kubeconfig, kubeContext string) (*Client, error) {
+	config, err := newClientConfig(kubeconfig, kubeContext).ClientConfig()
 	if err != nil {
-		panic(err.Error())
+		return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
 	}
 
 	kubeClient, err := kubernetes.NewForConfig(config)
@@ -120,7 +136,76 @@ func NewClient(kubeconfig string) (*Client, error) {
 	}, nil
 }
 
+// NewClientForInterfaces creates the client with the given clients, e.g. the fake ones in tests.
+func NewClientForInterfaces(kubeClient kubernetes.Interface, dynamicKubeClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) *Client {
+	return &Client{
+		kubeClient:        kubeClient,
+		dynamicKubeClient: dynamicKubeClient,
+		discoveryClient:   discoveryClient,
+	}
+}
+
+// CurrentContext returns the name of the context that the client created by the same kubeconfig and kubeContext targets.
+func CurrentContext(kubeconfig, kubeContext string) (string, error) {
+	if kubeContext != "" {
+		return kubeContext, nil
+	}
+
+	raw, err := newClientConfig(kubeconfig, "").RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	if raw.CurrentContext == "" {
+		return "", fmt.Errorf("current context is not set in kubeconfig")
+	}
+	return raw.CurrentContext, nil
+}
+
+// newClientConfig loads the kubeconfig in the same way as kubectl does.
+func newClientConfig(kubeconfig, kubeContext string) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+}
+
 func (c *Client) Apply(ctx context.Context, manifests []byte) error {
+	return c.forEachObject(manifests, func(ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		_, err := ri.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: "application/apply-patch"})
+		return err
+	})
+}
+
+// DeleteManifests deletes the objects in the manifests except the CustomResourceDefinitions, since
+// deleting them deletes all the custom resources in the cluster. The objects not found are ignored.
+func (c *Client) DeleteManifests(ctx context.Context, manifests []byte) error {
+	return c.forEachObject(manifests, func(ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			return nil
+		}
+		if err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteCRDs deletes the CustomResourceDefinitions in the manifests, which also deletes all their custom resources.
+// The CRDs not found are ignored.
+func (c *Client) DeleteCRDs(ctx context.Context, manifests []byte) error {
+	return c.forEachObject(manifests, func(ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			return nil
+		}
+		if err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// forEachObject calls fn with each object in the manifests and the dynamic client of its resource.
+func (c *Client) forEachObject(manifests []byte, fn func(ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error) error {
 	builder := resource.NewLocalBuilder().
 		// Configure with a scheme to get typed objects in the versions registered with the scheme.
 		// As an alternative, could call Unstructured() to get unstructured objects.
@@ -164,24 +249,19 @@ func (c *Client) Apply(ctx context.Context, manifests []byte) error {
 			Resource: strings.ToLower(gvk.Kind) + "s",
 		}
 
+		obj := &unstructured.Unstructured{Object: unstructuredObj}
+
+		var ri dynamic.ResourceInterface = c.dynamicKubeClient.Resource(gvr)
 		if isNamespaced[gvr.Resource] {
 			ns := "default"
 			if item.Namespace != "" {
 				ns = item.Namespace
 			}
-			_, err = c.dynamicKubeClient.Resource(gvr).Namespace(ns).Apply(ctx, item.Name,
-				&unstructured.Unstructured{Object: unstructuredObj},
-				metav1.ApplyOptions{FieldManager: "application/apply-patch"})
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err = c.dynamicKubeClient.Resource(gvr).Apply(ctx, item.Name,
-				&unstructured.Unstructured{Object: unstructuredObj},
-				metav1.ApplyOptions{FieldManager: "application/apply-patch"})
-			if err != nil {
-				return err
-			}
+			ri = c.dynamicKubeClient.Resource(gvr).Namespace(ns)
+		}
+
+		if err = fn(ri, obj); err != nil {
+			return err
 		}
 	}
 
@@ -210,6 +290,186 @@ func (c *Client) UpdateCluster(ctx context.Context, namespace string, cluster *g
 	return err
 }
 
+// IsServiceExist checks whether the service exists in namespace.
+func (c *Client) IsServiceExist(ctx context.Context, name, namespace string) (bool, error) {
+	_, err := c.kubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsCRDExist checks whether the CustomResourceDefinition exists.
+func (c *Client) IsCRDExist(ctx context.Context, name string) (bool, error) {
+	_, err := c.dynamicKubeClient.Resource(customResourceDefinitionGVR).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetDeployment returns the deployment in namespace, it returns nil if the deployment is not found.
+func (c *Client) GetDeployment(ctx context.Context, name, namespace string) (*appsv1.Deployment, error) {
+	deployment, err := c.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+// ApplySecret creates the opaque secret in namespace, or updates its data if it already exists.
+func (c *Client) ApplySecret(ctx context.Context, name, namespace string, data map[string][]byte) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	_, err := c.kubeClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		_, err = c.kubeClient.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// GetConfigMapData returns the data of the ConfigMap in namespace, it's nil if the ConfigMap doesn't exist.
+func (c *Client) GetConfigMapData(ctx context.Context, name, namespace string) (map[string]string, error) {
+	configMap, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return configMap.Data, nil
+}
+
+// ApplyConfigMap creates the ConfigMap in namespace, or updates its data if it already exists.
+func (c *Client) ApplyConfigMap(ctx context.Context, name, namespace string, data map[string]string) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+
+	_, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// ListPersistentVolumeClaims lists the names of the PersistentVolumeClaims in namespace that match the label selector.
+func (c *Client) ListPersistentVolumeClaims(ctx context.Context, namespace, selector string) ([]string, error) {
+	pvcs, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(pvcs.Items))
+	for _, pvc := range pvcs.Items {
+		names = append(names, pvc.Name)
+	}
+	return names, nil
+}
+
+// ListPodMetrics lists the metrics of the pods in namespace that match the label selector,
+// it fails if the metrics-server is not installed in the Kubernetes cluster.
+func (c *Client) ListPodMetrics(ctx context.Context, namespace, selector string) ([]PodMetrics, error) {
+	list, err := c.dynamicKubeClient.Resource(podMetricsGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("the metrics API is not available, is the metrics-server installed? %v", err)
+		}
+		return nil, err
+	}
+
+	metrics := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return nil, err
+		}
+
+		pod := PodMetrics{Name: item.GetName()}
+		for _, container := range containers {
+			fields, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usage, _, err := unstructured.NestedStringMap(fields, "usage")
+			if err != nil {
+				return nil, err
+			}
+			if cpu, err := apiresource.ParseQuantity(usage["cpu"]); err == nil {
+				pod.CPUMillis += cpu.MilliValue()
+			}
+			if memory, err := apiresource.ParseQuantity(usage["memory"]); err == nil {
+				pod.MemoryBytes += memory.Value()
+			}
+		}
+		metrics = append(metrics, pod)
+	}
+	return metrics, nil
+}
+
+// ListNamespaces lists the names of all the namespaces.
+func (c *Client) ListNamespaces(ctx context.Context) ([]string, error) {
+	namespaces, err := c.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
+// CanI checks whether the current user is allowed to perform the verb on the resource in the namespace,
+// like 'kubectl auth can-i'. The namespace is empty for the cluster-scoped resources.
+// The reason of denial is returned if it's not allowed.
+func (c *Client) CanI(ctx context.Context, namespace, verb, group, resource string) (bool, string, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     group,
+				Resource:  resource,
+			},
+		},
+	}
+	result, err := c.kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	return result.Status.Allowed, result.Status.Reason, nil
+}
+
+func (c *Client) DeletePersistentVolumeClaim(ctx context.Context, name, namespace string) error {
+	err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func (c *Client) DeleteEtcdCluster(ctx context.Context, name, namespace string) error {
 	if err := c.kubeClient.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 		return err
@@ -255,6 +515,17 @@ func (c *Client) WaitForEtcdReady(ctx context.Context, name, namespace string, t
 	return wait.PollImmediate(time.Second, timeout, conditionFunc)
 }
 
+func (c *Client) WaitForStatefulSetReady(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	conditionFunc := func() (bool, error) {
+		return c.IsStatefulSetReady(ctx, name, namespace)
+	}
+
+	if int(timeout) < 0 {
+		return wait.PollInfinite(time.Second, conditionFunc)
+	}
+	return wait.PollImmediate(time.Second, timeout, conditionFunc)
+}
+
 func (c *Client) isDeploymentReady(ctx context.Context, name, namespace string) (bool, error) {
 	deployment, err := c.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube
+
+import (
+	"context"
+	"time"
+
+	greptimev1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// GreptimeDBStandaloneKind is the kind of the GreptimeDBStandalone resource.
+const GreptimeDBStandaloneKind = "GreptimeDBStandalone"
+
+var greptimeDBStandaloneGVR = schema.GroupVersionResource{
+	Group:    "greptime.io",
+	Version:  "v1alpha1",
+	Resource: "greptimedbstandalones",
+}
+
+// GreptimeDBStandalone is the GreptimeDBStandalone resource that is reconciled by the operator.
+// The vendored operator APIs don't include it, so only the fields used by gtctl are defined.
+type GreptimeDBStandalone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GreptimeDBStandaloneSpec   `json:"spec,omitempty"`
+	Status GreptimeDBStandaloneStatus `json:"status,omitempty"`
+}
+
+type GreptimeDBStandaloneSpec struct {
+	Base *greptimev1alpha1.PodTemplateSpec `json:"base,omitempty"`
+
+	HTTPServicePort     int32 `json:"httpServicePort,omitempty"`
+	GRPCServicePort     int32 `json:"grpcServicePort,omitempty"`
+	MySQLServicePort    int32 `json:"mysqlServicePort,omitempty"`
+	PostgresServicePort int32 `json:"postgresServicePort,omitempty"`
+
+	Version string `json:"version,omitempty"`
+
+	LocalStorage *greptimev1alpha1.StorageSpec `json:"localStorage,omitempty"`
+}
+
+type GreptimeDBStandaloneStatus struct {
+	StandalonePhase greptimev1alpha1.ClusterPhase                 `json:"standalonePhase,omitempty"`
+	Conditions      []greptimev1alpha1.GreptimeDBClusterCondition `json:"conditions,omitempty"`
+}
+
+// IsReady checks whether the standalone is running.
+func (s *GreptimeDBStandalone) IsReady() bool {
+	if s.Status.StandalonePhase == greptimev1alpha1.ClusterRunning {
+		return true
+	}
+	for _, condition := range s.Status.Conditions {
+		if condition.Type == greptimev1alpha1.GreptimeDBClusterReady && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) CreateStandalone(ctx context.Context, standalone *GreptimeDBStandalone) error {
+	standalone.APIVersion = greptimeDBStandaloneGVR.GroupVersion().String()
+	standalone.Kind = GreptimeDBStandaloneKind
+
+	unstructuredObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(standalone)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.dynamicKubeClient.Resource(greptimeDBStandaloneGVR).Namespace(standalone.Namespace).Apply(ctx, standalone.Name,
+		&unstructured.Unstructured{Object: unstructuredObject}, metav1.ApplyOptions{FieldManager: "application/apply-patch"})
+	return err
+}
+
+func (c *Client) GetStandalone(ctx context.Context, name, namespace string) (*GreptimeDBStandalone, error) {
+	unstructuredObject, err := c.dynamicKubeClient.Resource(greptimeDBStandaloneGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var standalone GreptimeDBStandalone
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObject.UnstructuredContent(), &standalone); err != nil {
+		return nil, err
+	}
+
+	return &standalone, nil
+}
+
+func (c *Client) ListStandalones(ctx context.Context) ([]GreptimeDBStandalone, error) {
+	unstructuredList, err := c.dynamicKubeClient.Resource(greptimeDBStandaloneGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	standalones := make([]GreptimeDBStandalone, 0, len(unstructuredList.Items))
+	for _, item := range unstructuredList.Items {
+		var standalone GreptimeDBStandalone
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &standalone); err != nil {
+			return nil, err
+		}
+		standalones = append(standalones, standalone)
+	}
+
+	return standalones, nil
+}
+
+func (c *Client) DeleteStandalone(ctx context.Context, name, namespace string) error {
+	return c.dynamicKubeClient.Resource(greptimeDBStandaloneGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (c *Client) WaitForStandaloneReady(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	conditionFunc := func() (bool, error) {
+		standalone, err := c.GetStandalone(ctx, name, namespace)
+		if err != nil {
+			return false, nil
+		}
+		return standalone.IsReady(), nil
+	}
+
+	if int(timeout) < 0 {
+		return wait.PollInfinite(time.Second, conditionFunc)
+	}
+	return wait.PollImmediate(time.Second, timeout, conditionFunc)
+}
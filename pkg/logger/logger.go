@@ -123,6 +123,19 @@ func (l *logger) SetVerbosity(verbosity log.Level) {
 	atomic.StoreInt32((*int32)(&l.verbosity), int32(verbosity))
 }
 
+// SetColored sets whether the logs are colored, which also applies to the texts formatted by Bold.
+func (l *logger) SetColored(colored bool) {
+	l.colored = colored
+	color.NoColor = !colored
+}
+
+// SetWriter sets the writer that the logs are written to.
+func (l *logger) SetWriter(writer io.Writer) {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	l.writer = writer
+}
+
 // infoLogger implements log.InfoLogger for logger.
 type infoLogger struct {
 	logger  *logger
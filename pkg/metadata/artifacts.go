@@ -0,0 +1,267 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	semverutils "github.com/GreptimeTeam/gtctl/pkg/utils/semver"
+)
+
+const (
+	// ArtifactsDir is the directory of all the downloaded artifacts under the working directory.
+	ArtifactsDir = "artifacts"
+
+	chartsDir   = "charts"
+	binariesDir = "binaries"
+)
+
+// CachedArtifact is one version of the artifact that is downloaded to ${HomeDir}/${BaseDir}/artifacts.
+type CachedArtifact struct {
+	Name    string
+	Version string
+	Type    artifacts.ArtifactType
+
+	// Path is the directory of this version of the artifact.
+	Path string
+
+	// Size is the total size in bytes of the files in Path.
+	Size int64
+
+	// ModTime is the last time that the files in Path are modified, e.g. downloaded.
+	ModTime time.Time
+}
+
+// ListArtifacts returns all the cached artifacts, sorted by type and name, and the newest version comes first.
+func (m *manager) ListArtifacts() ([]*CachedArtifact, error) {
+	var cached []*CachedArtifact
+	for typ, dir := range map[artifacts.ArtifactType]string{
+		artifacts.ArtifactTypeChart:  chartsDir,
+		artifacts.ArtifactTypeBinary: binariesDir,
+	} {
+		typeDir := filepath.Join(m.workingDir, ArtifactsDir, dir)
+		names, err := readDirNames(typeDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			versions, err := readDirNames(filepath.Join(typeDir, name))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, version := range versions {
+				artifact := &CachedArtifact{
+					Name:    name,
+					Version: version,
+					Type:    typ,
+					Path:    filepath.Join(typeDir, name, version),
+				}
+				if err := artifact.stat(); err != nil {
+					return nil, err
+				}
+				cached = append(cached, artifact)
+			}
+		}
+	}
+
+	sort.SliceStable(cached, func(i, j int) bool {
+		if cached[i].Type != cached[j].Type {
+			return cached[i].Type < cached[j].Type
+		}
+		if cached[i].Name != cached[j].Name {
+			return cached[i].Name < cached[j].Name
+		}
+		return newerThan(cached[i], cached[j])
+	})
+
+	return cached, nil
+}
+
+// PruneArtifacts removes all but the newest keep versions of every cached artifact, and returns the removed ones.
+// The binaries used by the clusters are never removed, and they don't count in the kept versions.
+func (m *manager) PruneArtifacts(keep int) ([]*CachedArtifact, error) {
+	cached, err := m.ListArtifacts()
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := m.usedBinaries()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pruned []*CachedArtifact
+		kept   = make(map[string]int)
+		listed = make(map[string]bool)
+	)
+	for _, artifact := range cached {
+		key := string(artifact.Type) + "/" + artifact.Name
+		newest := !listed[key]
+		listed[key] = true
+
+		if artifact.Type == artifacts.ArtifactTypeBinary && used.contains(artifact, newest) {
+			continue
+		}
+		if kept[key] < keep {
+			kept[key]++
+			continue
+		}
+
+		if err := os.RemoveAll(artifact.Path); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, artifact)
+	}
+
+	return pruned, nil
+}
+
+// usedBinaries is the binaries that the clusters run with.
+type usedBinaries struct {
+	// paths are the local paths of binaries, e.g. the ones built from source.
+	paths []string
+
+	// versions are the downloaded versions of binaries, keyed by the names of binaries.
+	versions map[string]map[string]bool
+}
+
+// usedBinaries collects the binaries in the metadata of all the clusters. It fails if any metadata
+// can't be read, so the binaries of that cluster won't be removed by mistake.
+func (m *manager) usedBinaries() (*usedBinaries, error) {
+	clusters, err := m.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+
+	used := &usedBinaries{versions: make(map[string]map[string]bool)}
+	for _, cluster := range clusters {
+		out, err := os.ReadFile(filepath.Join(m.workingDir, cluster, fmt.Sprintf("%s.yaml", cluster)))
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata config.BareMetalClusterMetadata
+		if err = yaml.Unmarshal(out, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to read the metadata of cluster '%s': %v", cluster, err)
+		}
+		if metadata.Config == nil {
+			continue
+		}
+		if metadata.Config.Cluster != nil {
+			used.add(artifacts.GreptimeBinName, metadata.Config.Cluster.Artifact)
+		}
+		if metadata.Config.Etcd != nil {
+			used.add(artifacts.EtcdBinName, metadata.Config.Etcd.Artifact)
+		}
+	}
+
+	return used, nil
+}
+
+func (u *usedBinaries) add(name string, artifact *config.Artifact) {
+	if artifact == nil {
+		return
+	}
+	if len(artifact.Local) > 0 {
+		u.paths = append(u.paths, artifact.Local)
+		return
+	}
+
+	if u.versions[name] == nil {
+		u.versions[name] = make(map[string]bool)
+	}
+	u.versions[name][artifact.Version] = true
+}
+
+// contains returns true if the cached binary is used by any cluster. The versions like 'latest' are resolved
+// when the binaries are downloaded, so they are regarded as the newest cached version of the binary.
+func (u *usedBinaries) contains(artifact *CachedArtifact, newest bool) bool {
+	for _, p := range u.paths {
+		if strings.HasPrefix(filepath.Clean(p), artifact.Path+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	versions := u.versions[artifact.Name]
+	if versions[artifact.Version] {
+		return true
+	}
+	return newest && (versions[artifacts.LatestVersionTag] || versions[artifacts.NightlyVersionTag] || versions[""])
+}
+
+// stat sums up the size of the files in the artifact directory and finds the latest modification time.
+func (a *CachedArtifact) stat() error {
+	return filepath.WalkDir(a.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(a.ModTime) {
+			a.ModTime = info.ModTime()
+		}
+		if !d.IsDir() {
+			a.Size += info.Size()
+		}
+		return nil
+	})
+}
+
+// newerThan compares the versions in semver, and falls back to the modification time
+// if either of them is not a semantic version, e.g. 'latest'.
+func newerThan(a, b *CachedArtifact) bool {
+	greater, err := semverutils.Compare(a.Version, b.Version)
+	if err == nil {
+		return greater
+	}
+	return a.ModTime.After(b.ModTime)
+}
+
+// readDirNames returns the names of the sub-directories of dir, it returns nil if dir doesn't exist.
+func readDirNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestListAndPruneArtifacts(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	cached, err := m.ListArtifacts()
+	assert.NoError(t, err)
+	assert.Empty(t, cached)
+
+	for _, src := range []*artifacts.Source{
+		{Name: artifacts.GreptimeBinName, Version: "v0.4.0", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeBinName, Version: "v0.10.0", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeBinName, Version: "v0.4.1", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.EtcdBinName, Version: "v3.5.7", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeDBClusterChartName, Version: "0.1.2", Type: artifacts.ArtifactTypeChart},
+	} {
+		dir, err := m.AllocateArtifactFilePath(src, false)
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, src.Name+".tgz"), make([]byte, 1024), 0644))
+	}
+
+	cached, err = m.ListArtifacts()
+	assert.NoError(t, err)
+
+	var got []string
+	for _, artifact := range cached {
+		got = append(got, string(artifact.Type)+"/"+artifact.Name+"/"+artifact.Version)
+		assert.Equal(t, int64(1024), artifact.Size)
+	}
+	assert.Equal(t, []string{
+		"binary/etcd/v3.5.7",
+		"binary/greptime/v0.10.0",
+		"binary/greptime/v0.4.1",
+		"binary/greptime/v0.4.0",
+		"chart/greptimedb-cluster/0.1.2",
+	}, got)
+
+	pruned, err := m.PruneArtifacts(2)
+	assert.NoError(t, err)
+	assert.Len(t, pruned, 1)
+	assert.Equal(t, "v0.4.0", pruned[0].Version)
+	_, err = os.Stat(pruned[0].Path)
+	assert.True(t, os.IsNotExist(err))
+
+	cached, err = m.ListArtifacts()
+	assert.NoError(t, err)
+	assert.Len(t, cached, 4)
+}
+
+func TestPruneUsedArtifacts(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, src := range []*artifacts.Source{
+		{Name: artifacts.GreptimeBinName, Version: "v0.4.0", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeBinName, Version: "v0.4.1", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeBinName, Version: "v0.5.0-abcdef0", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.GreptimeBinName, Version: "v0.10.0", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.EtcdBinName, Version: "v3.5.6", Type: artifacts.ArtifactTypeBinary},
+		{Name: artifacts.EtcdBinName, Version: "v3.5.7", Type: artifacts.ArtifactTypeBinary},
+	} {
+		dir, err := m.AllocateArtifactFilePath(src, true)
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, src.Name), make([]byte, 1024), 0755))
+	}
+
+	// The clusters use a downloaded version, a binary built from source and the latest etcd.
+	sourceBuild, err := m.AllocateArtifactFilePath(&artifacts.Source{
+		Name: artifacts.GreptimeBinName, Version: "v0.5.0-abcdef0", Type: artifacts.ArtifactTypeBinary}, true)
+	assert.NoError(t, err)
+	for name, cfg := range map[string]*config.BareMetalClusterConfig{
+		"old": {
+			Cluster: &config.BareMetalClusterComponentsConfig{Artifact: &config.Artifact{Version: "v0.4.0"}},
+			Etcd:    &config.Etcd{Artifact: &config.Artifact{Version: artifacts.LatestVersionTag}},
+		},
+		"dev": {
+			Cluster: &config.BareMetalClusterComponentsConfig{
+				Artifact: &config.Artifact{Local: filepath.Join(sourceBuild, artifacts.GreptimeBinName)},
+			},
+		},
+	} {
+		m.AllocateClusterScopeDirs(name)
+		assert.NoError(t, m.CreateClusterScopeDirs(cfg))
+	}
+
+	pruned, err := m.PruneArtifacts(1)
+	assert.NoError(t, err)
+	assert.Len(t, pruned, 1)
+	assert.Equal(t, "binary/greptime/v0.4.1", string(pruned[0].Type)+"/"+pruned[0].Name+"/"+pruned[0].Version)
+
+	pruned, err = m.PruneArtifacts(0)
+	assert.NoError(t, err)
+	assert.Len(t, pruned, 2)
+	for _, artifact := range pruned {
+		assert.Contains(t, []string{"v0.10.0", "v3.5.6"}, artifact.Version)
+	}
+
+	cached, err := m.ListArtifacts()
+	assert.NoError(t, err)
+	var got []string
+	for _, artifact := range cached {
+		got = append(got, artifact.Name+"/"+artifact.Version)
+	}
+	assert.Equal(t, []string{"etcd/v3.5.7", "greptime/v0.5.0-abcdef0", "greptime/v0.4.0"}, got)
+}
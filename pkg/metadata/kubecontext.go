@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// KubeContext is the kubeconfig and context that one cluster on Kubernetes is created with or adopted from.
+type KubeContext struct {
+	// Kubeconfig is empty if the default kubeconfig is used.
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	Context    string `yaml:"context"`
+
+	// Adopted is true if the cluster is not created by gtctl but registered by 'gtctl cluster adopt'.
+	Adopted bool `yaml:"adopted,omitempty"`
+
+	// ManagedBy is the tool that deploys the adopted cluster, e.g. "Helm" or "Argo CD", it's empty if it's unknown.
+	ManagedBy string `yaml:"managedBy,omitempty"`
+}
+
+func (m *manager) RecordKubeContext(namespace, name string, kubeContext *KubeContext) error {
+	contexts, err := m.readKubeContexts()
+	if err != nil {
+		return err
+	}
+	contexts[kubeContextKey(namespace, name)] = kubeContext
+
+	if err = fileutils.EnsureDir(m.workingDir); err != nil {
+		return err
+	}
+	return writeYAML(m.kubeContextsPath(), contexts)
+}
+
+func (m *manager) GetKubeContext(namespace, name string) (*KubeContext, error) {
+	contexts, err := m.readKubeContexts()
+	if err != nil {
+		return nil, err
+	}
+	return contexts[kubeContextKey(namespace, name)], nil
+}
+
+func (m *manager) RemoveKubeContext(namespace, name string) error {
+	contexts, err := m.readKubeContexts()
+	if err != nil {
+		return err
+	}
+
+	key := kubeContextKey(namespace, name)
+	if _, ok := contexts[key]; !ok {
+		return nil
+	}
+	delete(contexts, key)
+
+	return writeYAML(m.kubeContextsPath(), contexts)
+}
+
+// readKubeContexts reads all the recorded contexts, keyed by the namespaced names of clusters.
+func (m *manager) readKubeContexts() (map[string]*KubeContext, error) {
+	contexts := make(map[string]*KubeContext)
+
+	in, err := os.ReadFile(m.kubeContextsPath())
+	if os.IsNotExist(err) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(in, &contexts); err != nil {
+		return nil, fmt.Errorf("invalid recorded kube contexts in '%s': %v", m.kubeContextsPath(), err)
+	}
+	return contexts, nil
+}
+
+func (m *manager) kubeContextsPath() string {
+	return filepath.Join(m.workingDir, KubeContextsFileName)
+}
+
+func kubeContextKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
@@ -49,9 +49,35 @@ type Manager interface {
 	// CreateClusterScopeDirs creates cluster scope directories and config path that allocated by AllocateClusterScopeDirs.
 	CreateClusterScopeDirs(cfg *config.BareMetalClusterConfig) error
 
+	// UpdateClusterMetadata overwrites the metadata of current cluster, e.g. after the cluster is scaled.
+	UpdateClusterMetadata(metadata *config.BareMetalClusterMetadata) error
+
+	// WriteClusterState overwrites the runtime state of current cluster.
+	WriteClusterState(state *config.BareMetalClusterState) error
+
+	// ListClusters returns the names of all the clusters under the working directory.
+	ListClusters() ([]string, error)
+
 	// GetClusterScopeDirs returns the cluster scope directory of current cluster.
 	GetClusterScopeDirs() *ClusterScopeDirs
 
+	// RecordKubeContext records the kubeconfig and context that the cluster on Kubernetes is created with.
+	RecordKubeContext(namespace, name string, kubeContext *KubeContext) error
+
+	// GetKubeContext returns the recorded kubeconfig and context of the cluster on Kubernetes,
+	// it returns nil if they are not recorded.
+	GetKubeContext(namespace, name string) (*KubeContext, error)
+
+	// RemoveKubeContext removes the recorded kubeconfig and context of the cluster on Kubernetes.
+	RemoveKubeContext(namespace, name string) error
+
+	// ListArtifacts returns all the versions of the artifacts that are downloaded to the working directory.
+	ListArtifacts() ([]*CachedArtifact, error)
+
+	// PruneArtifacts removes all but the newest keep versions of every downloaded artifact.
+	// The binaries used by the clusters are kept.
+	PruneArtifacts(keep int) ([]*CachedArtifact, error)
+
 	// Clean cleans up all the metadata. It will remove the working directory.
 	Clean() error
 }
@@ -64,6 +90,15 @@ const (
 	ClusterLogsDir = "logs"
 	ClusterDataDir = "data"
 	ClusterPidsDir = "pids"
+
+	// ClusterCrashesDir is the dir of the crashes of components, see components.ReadCrashes.
+	ClusterCrashesDir = "crashes"
+
+	// ClusterStateFileName is the file name of the runtime state of one cluster.
+	ClusterStateFileName = "cluster.yaml"
+
+	// KubeContextsFileName is the file name of the recorded contexts of the clusters on Kubernetes.
+	KubeContextsFileName = "kube-contexts.yaml"
 )
 
 type ClusterScopeDirs struct {
@@ -71,7 +106,9 @@ type ClusterScopeDirs struct {
 	LogsDir    string
 	DataDir    string
 	PidsDir    string
+	CrashesDir string
 	ConfigPath string
+	StatePath  string
 }
 
 type manager struct {
@@ -108,8 +145,12 @@ func (m *manager) AllocateClusterScopeDirs(clusterName string) {
 	csd.DataDir = path.Join(csd.BaseDir, ClusterDataDir)
 	// ${HomeDir}/${BaseDir}/${ClusterName}/pids
 	csd.PidsDir = path.Join(csd.BaseDir, ClusterPidsDir)
+	// ${HomeDir}/${BaseDir}/${ClusterName}/crashes
+	csd.CrashesDir = path.Join(csd.BaseDir, ClusterCrashesDir)
 	// ${HomeDir}/${BaseDir}/${ClusterName}/${ClusterName}.yaml
 	csd.ConfigPath = filepath.Join(csd.BaseDir, fmt.Sprintf("%s.yaml", clusterName))
+	// ${HomeDir}/${BaseDir}/${ClusterName}/cluster.yaml
+	csd.StatePath = filepath.Join(csd.BaseDir, ClusterStateFileName)
 
 	m.clusterDir = csd
 }
@@ -118,13 +159,21 @@ func (m *manager) AllocateArtifactFilePath(src *artifacts.Source, installBinary
 	var filePath string
 	switch src.Type {
 	case artifacts.ArtifactTypeChart:
-		filePath = filepath.Join(m.workingDir, "artifacts", "charts", src.Name, src.Version, "pkg")
+		filePath = filepath.Join(m.workingDir, ArtifactsDir, chartsDir, src.Name, src.Version, "pkg")
 	case artifacts.ArtifactTypeBinary:
+		versionDir := filepath.Join(m.workingDir, ArtifactsDir, binariesDir, src.Name, src.Version)
+
+		// The binaries of the other platforms are kept apart from the ones of the current platform,
+		// e.g. 'greptime/v0.4.1/linux-arm64/bin'.
+		if src.Platform != (artifacts.Platform{}) && !src.Platform.IsCurrent() {
+			versionDir = filepath.Join(versionDir, src.Platform.OS+"-"+src.Platform.Arch)
+		}
+
 		if installBinary {
 			// TODO(zyy17): It seems that we need to call AllocateArtifactFilePath() twice to get the correct path. Can we make it easier?
-			filePath = filepath.Join(m.workingDir, "artifacts", "binaries", src.Name, src.Version, "bin")
+			filePath = filepath.Join(versionDir, "bin")
 		} else {
-			filePath = filepath.Join(m.workingDir, "artifacts", "binaries", src.Name, src.Version, "pkg")
+			filePath = filepath.Join(versionDir, "pkg")
 		}
 	default:
 		return "", fmt.Errorf("unknown artifact type: %s", src.Type)
@@ -179,6 +228,64 @@ func (m *manager) CreateClusterScopeDirs(cfg *config.BareMetalClusterConfig) err
 	return nil
 }
 
+func (m *manager) UpdateClusterMetadata(metadata *config.BareMetalClusterMetadata) error {
+	if m.clusterDir == nil {
+		return fmt.Errorf("unallocated cluster dir, please initialize a metadata manager with cluster name provided")
+	}
+
+	return writeYAML(m.clusterDir.ConfigPath, metadata)
+}
+
+func (m *manager) WriteClusterState(state *config.BareMetalClusterState) error {
+	if m.clusterDir == nil {
+		return fmt.Errorf("unallocated cluster dir, please initialize a metadata manager with cluster name provided")
+	}
+
+	return writeYAML(m.clusterDir.StatePath, state)
+}
+
+func (m *manager) ListClusters() ([]string, error) {
+	entries, err := os.ReadDir(m.workingDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The directory that contains the config of cluster is the cluster scope directory,
+	// the others, e.g. the artifacts directory, are skipped.
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ok, err := fileutils.IsFileExists(filepath.Join(m.workingDir, entry.Name(), fmt.Sprintf("%s.yaml", entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// writeYAML writes the object in YAML to a temporary file and renames it, so the file will not be half-written.
+func writeYAML(filePath string, obj interface{}) error {
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, out, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
 func (m *manager) SetHomeDir(dir string) error {
 	m.workingDir = filepath.Join(dir, BaseDir)
 	return nil
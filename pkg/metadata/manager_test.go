@@ -40,6 +40,12 @@ func TestMetadataManager(t *testing.T) {
 		t.Fatalf("failed to create metadata manager: %v", err)
 	}
 
+	// The binaries of the other platform are kept apart.
+	other := artifacts.Platform{OS: "linux", Arch: "arm64"}
+	if other.IsCurrent() {
+		other.Arch = "amd64"
+	}
+
 	tests := []struct {
 		src              *artifacts.Source
 		wantedDestDir    string
@@ -62,6 +68,26 @@ func TestMetadataManager(t *testing.T) {
 			wantedDestDir:    filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "pkg"),
 			wantedInstallDir: filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "bin"),
 		},
+		{
+			src: &artifacts.Source{
+				Name:     artifacts.EtcdBinName,
+				Version:  artifacts.DefaultEtcdBinVersion,
+				Type:     artifacts.ArtifactTypeBinary,
+				Platform: artifacts.CurrentPlatform(),
+			},
+			wantedDestDir:    filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "pkg"),
+			wantedInstallDir: filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "bin"),
+		},
+		{
+			src: &artifacts.Source{
+				Name:     artifacts.EtcdBinName,
+				Version:  artifacts.DefaultEtcdBinVersion,
+				Type:     artifacts.ArtifactTypeBinary,
+				Platform: other,
+			},
+			wantedDestDir:    filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "linux-"+other.Arch, "pkg"),
+			wantedInstallDir: filepath.Join(tempDir, BaseDir, "artifacts", "binaries", artifacts.EtcdBinName, artifacts.DefaultEtcdBinVersion, "linux-"+other.Arch, "bin"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -145,3 +171,119 @@ func TestMetadataManagerWithClusterConfigPath(t *testing.T) {
 	err = m.Clean()
 	assert.NoError(t, err)
 }
+
+func TestUpdateClusterMetadata(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	m.AllocateClusterScopeDirs("test")
+	assert.NoError(t, m.CreateClusterScopeDirs(config.DefaultBareMetalConfig()))
+
+	csd := m.GetClusterScopeDirs()
+	cnt, err := os.ReadFile(csd.ConfigPath)
+	assert.NoError(t, err)
+	var metadata config.BareMetalClusterMetadata
+	assert.NoError(t, yaml.Unmarshal(cnt, &metadata))
+
+	metadata.Config.Cluster.Datanode.Replicas = 5
+	assert.NoError(t, m.UpdateClusterMetadata(&metadata))
+
+	cnt, err = os.ReadFile(csd.ConfigPath)
+	assert.NoError(t, err)
+	var actual config.BareMetalClusterMetadata
+	assert.NoError(t, yaml.Unmarshal(cnt, &actual))
+	assert.Equal(t, 5, actual.Config.Cluster.Datanode.Replicas)
+	assert.Equal(t, metadata.ForegroundPid, actual.ForegroundPid)
+}
+
+func TestWriteClusterState(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	m.AllocateClusterScopeDirs("test")
+	assert.NoError(t, m.CreateClusterScopeDirs(config.DefaultBareMetalConfig()))
+
+	expect := &config.BareMetalClusterState{
+		Name:          "test",
+		ForegroundPid: 123,
+		Artifacts: map[string]*config.Artifact{
+			artifacts.GreptimeBinName: {Version: "latest"},
+		},
+		Components: []*config.ComponentState{{
+			Name: "frontend",
+			Replicas: []*config.ReplicaState{{
+				Name:  "frontend.0",
+				Pid:   456,
+				State: "running",
+				Addrs: map[string]string{"--http-addr": "127.0.0.1:4000"},
+			}},
+		}},
+	}
+	assert.NoError(t, m.WriteClusterState(expect))
+
+	csd := m.GetClusterScopeDirs()
+	assert.Equal(t, filepath.Join(csd.BaseDir, ClusterStateFileName), csd.StatePath)
+	cnt, err := os.ReadFile(csd.StatePath)
+	assert.NoError(t, err)
+	var actual config.BareMetalClusterState
+	assert.NoError(t, yaml.Unmarshal(cnt, &actual))
+	assert.Equal(t, expect, &actual)
+}
+
+func TestListClusters(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	names, err := m.ListClusters()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	for _, name := range []string{"b", "a"} {
+		m.AllocateClusterScopeDirs(name)
+		assert.NoError(t, m.CreateClusterScopeDirs(config.DefaultBareMetalConfig()))
+	}
+	// The directory without the config of cluster is not a cluster.
+	assert.NoError(t, os.MkdirAll(filepath.Join(m.GetWorkingDir(), "artifacts"), 0755))
+
+	names, err = m.ListClusters()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestKubeContexts(t *testing.T) {
+	m, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	kubeContext, err := m.GetKubeContext("default", "mycluster")
+	assert.NoError(t, err)
+	assert.Nil(t, kubeContext)
+
+	expect := &KubeContext{Kubeconfig: "/path/to/kubeconfig", Context: "prod"}
+	assert.NoError(t, m.RecordKubeContext("default", "mycluster", expect))
+	assert.NoError(t, m.RecordKubeContext("test", "mycluster", &KubeContext{Context: "kind-test"}))
+	adopted := &KubeContext{Context: "prod", Adopted: true, ManagedBy: "Helm"}
+	assert.NoError(t, m.RecordKubeContext("default", "adopted", adopted))
+
+	kubeContext, err = m.GetKubeContext("default", "mycluster")
+	assert.NoError(t, err)
+	assert.Equal(t, expect, kubeContext)
+
+	// The recorded contexts are not clusters.
+	names, err := m.ListClusters()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	kubeContext, err = m.GetKubeContext("default", "adopted")
+	assert.NoError(t, err)
+	assert.Equal(t, adopted, kubeContext)
+
+	assert.NoError(t, m.RemoveKubeContext("default", "mycluster"))
+	assert.NoError(t, m.RemoveKubeContext("default", "notexist"))
+	kubeContext, err = m.GetKubeContext("default", "mycluster")
+	assert.NoError(t, err)
+	assert.Nil(t, kubeContext)
+
+	kubeContext, err = m.GetKubeContext("test", "mycluster")
+	assert.NoError(t, err)
+	assert.Equal(t, &KubeContext{Context: "kind-test"}, kubeContext)
+}
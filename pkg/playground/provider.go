@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package playground
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	// ProviderKind creates the local Kubernetes cluster by kind, see https://kind.sigs.k8s.io.
+	ProviderKind = "kind"
+
+	// ProviderK3d creates the local Kubernetes cluster by k3d, see https://k3d.io.
+	ProviderK3d = "k3d"
+
+	// DefaultName is the default name of the local Kubernetes cluster of playground.
+	DefaultName = "gtctl-playground"
+)
+
+// Provider creates and deletes the local Kubernetes cluster of playground.
+type Provider interface {
+	// Name returns the name of the provider, which is also its binary.
+	Name() string
+
+	// Exists returns whether the local Kubernetes cluster named name exists.
+	Exists(ctx context.Context, name string) (bool, error)
+
+	// Create creates the local Kubernetes cluster named name, and waits until it's ready.
+	Create(ctx context.Context, name string) error
+
+	// Delete deletes the local Kubernetes cluster named name with everything deployed on it.
+	Delete(ctx context.Context, name string) error
+
+	// KubeContext returns the kubeconfig context of the local Kubernetes cluster named name.
+	KubeContext(name string) string
+}
+
+// NewProvider returns the provider of name, the binary of provider should be installed.
+func NewProvider(name string, l logger.Logger) (Provider, error) {
+	var p Provider
+	switch name {
+	case ProviderKind:
+		p = &kind{logger: l}
+	case ProviderK3d:
+		p = &k3d{logger: l}
+	default:
+		return nil, fmt.Errorf("unsupported playground provider '%s', it should be '%s' or '%s'", name, ProviderKind, ProviderK3d)
+	}
+
+	if _, err := exec.LookPath(p.Name()); err != nil {
+		return nil, fmt.Errorf("'%s' is required to create the local Kubernetes cluster of playground: %v", p.Name(), err)
+	}
+	return p, nil
+}
+
+type kind struct {
+	logger logger.Logger
+}
+
+var _ Provider = &kind{}
+
+func (k *kind) Name() string {
+	return ProviderKind
+}
+
+func (k *kind) Exists(ctx context.Context, name string) (bool, error) {
+	output, err := exec.CommandContext(ctx, ProviderKind, "get", "clusters").Output()
+	if err != nil {
+		return false, fmt.Errorf("error listing the clusters of %s: %v", ProviderKind, err)
+	}
+	return containsLine(output, name), nil
+}
+
+func (k *kind) Create(ctx context.Context, name string) error {
+	return run(ctx, k.logger, ProviderKind, "create", "cluster", "--name", name, "--wait", "5m")
+}
+
+func (k *kind) Delete(ctx context.Context, name string) error {
+	return run(ctx, k.logger, ProviderKind, "delete", "cluster", "--name", name)
+}
+
+func (k *kind) KubeContext(name string) string {
+	return "kind-" + name
+}
+
+type k3d struct {
+	logger logger.Logger
+}
+
+var _ Provider = &k3d{}
+
+func (k *k3d) Name() string {
+	return ProviderK3d
+}
+
+func (k *k3d) Exists(ctx context.Context, name string) (bool, error) {
+	output, err := exec.CommandContext(ctx, ProviderK3d, "cluster", "list", "--no-headers").Output()
+	if err != nil {
+		return false, fmt.Errorf("error listing the clusters of %s: %v", ProviderK3d, err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (k *k3d) Create(ctx context.Context, name string) error {
+	return run(ctx, k.logger, ProviderK3d, "cluster", "create", name, "--wait", "--timeout", "5m")
+}
+
+func (k *k3d) Delete(ctx context.Context, name string) error {
+	return run(ctx, k.logger, ProviderK3d, "cluster", "delete", name)
+}
+
+func (k *k3d) KubeContext(name string) string {
+	return "k3d-" + name
+}
+
+// run runs the command of provider and outputs to the terminal.
+func run(ctx context.Context, l logger.Logger, provider string, args ...string) error {
+	l.V(3).Infof("Running '%s %s'", provider, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, provider, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running '%s %s': %v", provider, strings.Join(args[:2], " "), err)
+	}
+	return nil
+}
+
+// containsLine returns whether one of the lines of output is s.
+func containsLine(output []byte, s string) bool {
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		if string(bytes.TrimSpace(line)) == s {
+			return true
+		}
+	}
+	return false
+}
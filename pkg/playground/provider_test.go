@@ -0,0 +1,40 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package playground
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubeContext(t *testing.T) {
+	assert.Equal(t, "kind-gtctl-playground", (&kind{}).KubeContext(DefaultName))
+	assert.Equal(t, "k3d-gtctl-playground", (&k3d{}).KubeContext(DefaultName))
+}
+
+func TestNewProviderUnsupported(t *testing.T) {
+	_, err := NewProvider("minikube", nil)
+	assert.Error(t, err)
+}
+
+func TestContainsLine(t *testing.T) {
+	output := []byte("gtctl-playground\nkind\n")
+	assert.True(t, containsLine(output, "gtctl-playground"))
+	assert.True(t, containsLine(output, "kind"))
+	assert.False(t, containsLine(output, "gtctl"))
+}
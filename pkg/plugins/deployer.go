@@ -0,0 +1,294 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// DeployerOptionsEnvKey is the environment variable that tells the deployer plugin the path of the JSON file
+// of the operation options. The deployer may write the options back into the file, which are decoded into the
+// options of gtctl, e.g. the OldReplicas of the ScaleOptions.
+const DeployerOptionsEnvKey = "GTCTL_DEPLOYER_OPTIONS"
+
+// The operations of deployer, which are passed to the deployer plugin as its only arg.
+const (
+	OperationGet     = "get"
+	OperationList    = "list"
+	OperationCreate  = "create"
+	OperationDelete  = "delete"
+	OperationScale   = "scale"
+	OperationUpgrade = "upgrade"
+	OperationRestart = "restart"
+	OperationStatus  = "status"
+	OperationLogs    = "logs"
+	OperationConnect = "connect"
+	OperationExec    = "exec"
+)
+
+// DeployerFactory creates the deployer, which deploys and operates the clusters on the custom target.
+type DeployerFactory func(l logger.Logger) (opt.Deployer, error)
+
+var (
+	deployersMu sync.RWMutex
+	deployers   = make(map[string]DeployerFactory)
+)
+
+// RegisterDeployer registers the deployer compiled into gtctl, it takes precedence over the deployer plugin of the same name.
+func RegisterDeployer(name string, factory DeployerFactory) {
+	deployersMu.Lock()
+	defer deployersMu.Unlock()
+	deployers[name] = factory
+}
+
+func registeredDeployers() []string {
+	deployersMu.RLock()
+	defer deployersMu.RUnlock()
+
+	names := make([]string, 0, len(deployers))
+	for name := range deployers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDeployer creates the deployer of name, which is registered by RegisterDeployer, or runs the executable
+// 'gtctl-deployer-<name>' in the deployer search paths for each operation, see ServeDeployer.
+func (m *Manager) NewDeployer(name string, l logger.Logger) (opt.Deployer, error) {
+	deployersMu.RLock()
+	factory, ok := deployers[name]
+	deployersMu.RUnlock()
+	if ok {
+		return factory(l)
+	}
+
+	pluginPath, err := m.searchDeployer(name)
+	if err != nil {
+		return nil, err
+	}
+	return &execDeployer{name: name, path: pluginPath}, nil
+}
+
+func (m *Manager) deployerPrefix() string {
+	return m.prefix + "deployer-"
+}
+
+func (m *Manager) searchDeployer(name string) (string, error) {
+	fileName := m.deployerPrefix() + name
+	for _, path := range m.deployerSearchPaths {
+		pluginPath := filepath.Join(path, fileName)
+		if isExecutable(pluginPath) {
+			return pluginPath, nil
+		}
+	}
+	return "", fmt.Errorf("deployer '%s' not found, its executable '%s' should be in the plugins directory of gtctl "+
+		"or the absolute paths of $%s", name, fileName, PluginSearchPathsEnvKey)
+}
+
+// execDeployer runs the deployer plugin for each operation. The options are passed to the plugin in the JSON file,
+// except the ones that can't be encoded, e.g. the writer of outputs, which is the stdout of the plugin instead.
+type execDeployer struct {
+	name string
+	path string
+}
+
+var _ opt.Deployer = &execDeployer{}
+
+func (d *execDeployer) run(ctx context.Context, operation string, options interface{}, w io.Writer) error {
+	f, err := os.CreateTemp("", "gtctl-deployer-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err = json.NewEncoder(f).Encode(options); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if w == nil {
+		w = os.Stdout
+	}
+	cmd := exec.CommandContext(ctx, d.path, operation)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", DeployerOptionsEnvKey, f.Name()))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("deployer '%s' failed to %s: %v", d.name, operation, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, options); err != nil {
+		return fmt.Errorf("invalid options written back by deployer '%s': %v", d.name, err)
+	}
+	return nil
+}
+
+func (d *execDeployer) Get(ctx context.Context, options *opt.GetOptions) error {
+	return d.run(ctx, OperationGet, options, options.Writer)
+}
+
+func (d *execDeployer) List(ctx context.Context, options *opt.ListOptions) error {
+	return d.run(ctx, OperationList, options, options.Writer)
+}
+
+func (d *execDeployer) Create(ctx context.Context, options *opt.CreateOptions) error {
+	return d.run(ctx, OperationCreate, options, nil)
+}
+
+func (d *execDeployer) Delete(ctx context.Context, options *opt.DeleteOptions) error {
+	return d.run(ctx, OperationDelete, options, nil)
+}
+
+func (d *execDeployer) Scale(ctx context.Context, options *opt.ScaleOptions) error {
+	return d.run(ctx, OperationScale, options, nil)
+}
+
+func (d *execDeployer) Upgrade(ctx context.Context, options *opt.UpgradeOptions) error {
+	return d.run(ctx, OperationUpgrade, options, nil)
+}
+
+func (d *execDeployer) Restart(ctx context.Context, options *opt.RestartOptions) error {
+	return d.run(ctx, OperationRestart, options, nil)
+}
+
+func (d *execDeployer) Status(ctx context.Context, options *opt.StatusOptions) error {
+	return d.run(ctx, OperationStatus, options, options.Writer)
+}
+
+func (d *execDeployer) Logs(ctx context.Context, options *opt.LogsOptions) error {
+	return d.run(ctx, OperationLogs, options, options.Writer)
+}
+
+func (d *execDeployer) Connect(ctx context.Context, options *opt.ConnectOptions) error {
+	return d.run(ctx, OperationConnect, options, nil)
+}
+
+func (d *execDeployer) Exec(ctx context.Context, options *opt.ExecOptions) error {
+	return d.run(ctx, OperationExec, options, options.Writer)
+}
+
+// ServeDeployer serves the operation of gtctl by the deployer, it's the main function of the deployer plugin
+// written in Go, e.g.
+//
+//	func main() {
+//		if err := plugins.ServeDeployer(NewDeployer()); err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			os.Exit(1)
+//		}
+//	}
+//
+// The deployer plugin is built as the executable 'gtctl-deployer-<name>', so it doesn't have to be built with the
+// same versions of gtctl and Go toolchain, and can be written in any language by following the same protocol.
+func ServeDeployer(d opt.Deployer) error {
+	if len(os.Args) != 2 {
+		return fmt.Errorf("usage: %s <operation>, it should be run by gtctl", filepath.Base(os.Args[0]))
+	}
+	optionsPath := os.Getenv(DeployerOptionsEnvKey)
+	if len(optionsPath) == 0 {
+		return fmt.Errorf("$%s is not set, it should be run by gtctl", DeployerOptionsEnvKey)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return serveDeployer(ctx, d, os.Args[1], optionsPath, os.Stdout)
+}
+
+func serveDeployer(ctx context.Context, d opt.Deployer, operation, optionsPath string, w io.Writer) error {
+	var (
+		options interface{}
+		call    func() error
+	)
+	switch operation {
+	case OperationGet:
+		o := &opt.GetOptions{Table: tablewriter.NewWriter(w), Writer: w}
+		options, call = o, func() error { return d.Get(ctx, o) }
+	case OperationList:
+		o := &opt.ListOptions{GetOptions: opt.GetOptions{Table: tablewriter.NewWriter(w), Writer: w}}
+		options, call = o, func() error { return d.List(ctx, o) }
+	case OperationCreate:
+		o := &opt.CreateOptions{}
+		options, call = o, func() error { return d.Create(ctx, o) }
+	case OperationDelete:
+		o := &opt.DeleteOptions{}
+		options, call = o, func() error { return d.Delete(ctx, o) }
+	case OperationScale:
+		o := &opt.ScaleOptions{}
+		options, call = o, func() error { return d.Scale(ctx, o) }
+	case OperationUpgrade:
+		o := &opt.UpgradeOptions{}
+		options, call = o, func() error { return d.Upgrade(ctx, o) }
+	case OperationRestart:
+		o := &opt.RestartOptions{}
+		options, call = o, func() error { return d.Restart(ctx, o) }
+	case OperationStatus:
+		o := &opt.StatusOptions{Table: tablewriter.NewWriter(w), Writer: w}
+		options, call = o, func() error { return d.Status(ctx, o) }
+	case OperationLogs:
+		o := &opt.LogsOptions{Writer: w}
+		options, call = o, func() error { return d.Logs(ctx, o) }
+	case OperationConnect:
+		o := &opt.ConnectOptions{}
+		options, call = o, func() error { return d.Connect(ctx, o) }
+	case OperationExec:
+		o := &opt.ExecOptions{Writer: w}
+		options, call = o, func() error { return d.Exec(ctx, o) }
+	default:
+		return fmt.Errorf("unknown operation '%s'", operation)
+	}
+
+	data, err := os.ReadFile(optionsPath)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, options); err != nil {
+		return fmt.Errorf("invalid options of operation '%s': %v", operation, err)
+	}
+	if err = call(); err != nil {
+		return err
+	}
+
+	// Write the options back, e.g. the OldReplicas refilled by Scale.
+	if data, err = json.Marshal(options); err != nil {
+		return err
+	}
+	return os.WriteFile(optionsPath, data, 0600)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/fake"
+)
+
+func TestExecDeployer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the deployer plugin is a shell script")
+	}
+
+	// The deployer prints the operation and its options, and refills the old replicas.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = fail ] && exit 3
+echo "$1"
+cat "$GTCTL_DEPLOYER_OPTIONS"
+echo '{"OldReplicas":1}' > "$GTCTL_DEPLOYER_OPTIONS"
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gtctl-deployer-acme"), []byte(script), 0755))
+	t.Setenv(PluginSearchPathsEnvKey, dir)
+	pm, err := NewManager()
+	assert.NoError(t, err)
+
+	d, err := pm.NewDeployer("acme", nil)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, d.Get(context.Background(), &opt.GetOptions{Name: "mycluster", Writer: &out}))
+	assert.Contains(t, out.String(), "get\n")
+	assert.Contains(t, out.String(), `"Name":"mycluster"`)
+
+	options := &opt.ScaleOptions{Name: "mycluster", NewReplicas: 3}
+	assert.NoError(t, d.Scale(context.Background(), options))
+	assert.Equal(t, int32(1), options.OldReplicas)
+	assert.Equal(t, int32(3), options.NewReplicas)
+
+	err = d.(*execDeployer).run(context.Background(), "fail", &opt.StopOptions{}, nil)
+	assert.ErrorContains(t, err, "deployer 'acme' failed to fail")
+}
+
+func TestServeDeployer(t *testing.T) {
+	optionsPath := filepath.Join(t.TempDir(), "options.json")
+	data, err := json.Marshal(&opt.ScaleOptions{Name: "mycluster", NewReplicas: 3})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(optionsPath, data, 0600))
+
+	d := fake.NewDeployer()
+	var out bytes.Buffer
+	assert.NoError(t, serveDeployer(context.Background(), d, OperationScale, optionsPath, &out))
+	assert.Equal(t, []string{"Scale"}, d.Operations())
+	assert.Equal(t, &opt.ScaleOptions{Name: "mycluster", NewReplicas: 3}, d.Calls()[0].Options)
+
+	d.Errors["Create"] = errors.New("boom")
+	assert.NoError(t, os.WriteFile(optionsPath, []byte("{}"), 0600))
+	assert.EqualError(t, serveDeployer(context.Background(), d, OperationCreate, optionsPath, &out), "boom")
+	assert.Error(t, serveDeployer(context.Background(), d, "unknown", optionsPath, &out))
+}
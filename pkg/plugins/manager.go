@@ -21,6 +21,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
@@ -32,14 +34,44 @@ const (
 
 	// PluginSearchPathsEnvKey is the environment variable key for the plugin search paths.
 	// If we set this variable, the plugin manager will search the paths provided by this variable.
-	// If we don't set this variable, the plugin manager will search the current working directory and the $PATH.
+	// If we don't set this variable, the plugin manager will search the plugins directory of gtctl,
+	// the current working directory and the $PATH.
+	//
+	// The deployer plugins operate the clusters with the options of gtctl, e.g. the credentials of object
+	// storage, so they are only searched in the absolute paths of this variable, or the plugins directory of
+	// gtctl if it's not set, but never in the current working directory, the $PATH or the relative paths.
 	PluginSearchPathsEnvKey = "GTCTL_PLUGIN_PATHS"
+
+	// PluginsDir is the directory of the plugins relative to the home directory.
+	PluginsDir = ".gtctl/plugins"
+)
+
+// The kinds of plugins.
+const (
+	// KindCommand is the executable 'gtctl-<name>' that runs as the subcommand 'gtctl <name>', the dashes in
+	// the name are the nested subcommands, e.g. 'gtctl-foo-bar' runs as 'gtctl foo bar'.
+	KindCommand = "command"
+
+	// KindDeployer is the executable 'gtctl-deployer-<name>' that deploys clusters to the custom target, see ServeDeployer.
+	KindDeployer = "deployer"
 )
 
+// Plugin is one of the discovered plugins.
+type Plugin struct {
+	Name string
+	Kind string
+
+	// Path is empty for the deployers compiled into gtctl.
+	Path string
+}
+
 // Manager manages and executes the plugins.
 type Manager struct {
 	prefix      string
 	searchPaths []string
+
+	// deployerSearchPaths are the absolute paths to search the deployer plugins.
+	deployerSearchPaths []string
 }
 
 func NewManager() (*Manager, error) {
@@ -50,8 +82,19 @@ func NewManager() (*Manager, error) {
 
 	pluginSearchPaths := os.Getenv(PluginSearchPathsEnvKey)
 	if len(pluginSearchPaths) > 0 {
-		m.searchPaths = append(m.searchPaths, strings.Split(pluginSearchPaths, ":")...)
+		m.searchPaths = append(m.searchPaths, filepath.SplitList(pluginSearchPaths)...)
+		for _, path := range m.searchPaths {
+			if filepath.IsAbs(path) {
+				m.deployerSearchPaths = append(m.deployerSearchPaths, path)
+			}
+		}
 	} else {
+		// Search the plugins directory of gtctl.
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			m.searchPaths = append(m.searchPaths, filepath.Join(homeDir, PluginsDir))
+			m.deployerSearchPaths = append(m.deployerSearchPaths, filepath.Join(homeDir, PluginsDir))
+		}
+
 		// Search the current working directory.
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -62,16 +105,16 @@ func NewManager() (*Manager, error) {
 		// Search the $PATH.
 		pathEnv := os.Getenv("PATH")
 		if len(pathEnv) > 0 {
-			m.searchPaths = append(m.searchPaths, strings.Split(pathEnv, ":")...)
+			m.searchPaths = append(m.searchPaths, filepath.SplitList(pathEnv)...)
 		}
 	}
 
 	return m, nil
 }
 
-// ShouldRun returns true whether you should run the plugin.
-func (m *Manager) ShouldRun(name string) bool {
-	_, err := m.searchPlugins(name)
+// ShouldRun returns true whether you should run the plugin of the subcommand in args.
+func (m *Manager) ShouldRun(args ...string) bool {
+	_, _, err := m.lookup(args)
 	return err == nil
 }
 
@@ -81,12 +124,12 @@ func (m *Manager) Run(args []string) error {
 		return nil // No arguments provided, normal help message will be shown.
 	}
 
-	pluginPath, err := m.searchPlugins(args[0])
+	pluginPath, pluginArgs, err := m.lookup(args)
 	if err != nil {
 		return err
 	}
 
-	pluginCmd := exec.Command(pluginPath, args[1:]...)
+	pluginCmd := exec.Command(pluginPath, pluginArgs...)
 	pluginCmd.Stdin = os.Stdin
 	pluginCmd.Stdout = os.Stdout
 	pluginCmd.Stderr = os.Stderr
@@ -97,6 +140,92 @@ func (m *Manager) Run(args []string) error {
 	return nil
 }
 
+// Plugins returns all the discovered plugins sorted by kind and name, the plugin found first in the search paths
+// takes precedence over the ones of the same name.
+func (m *Manager) Plugins() ([]*Plugin, error) {
+	var (
+		plugins []*Plugin
+		seen    = make(map[string]bool)
+	)
+	add := func(plugin *Plugin) {
+		if key := plugin.Kind + "/" + plugin.Name; !seen[key] {
+			seen[key] = true
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	for _, name := range registeredDeployers() {
+		add(&Plugin{Name: name, Kind: KindDeployer})
+	}
+	for _, path := range m.deployerSearchPaths {
+		for _, name := range m.pluginFiles(path) {
+			pluginPath := filepath.Join(path, name)
+			if strings.HasPrefix(name, m.deployerPrefix()) && isExecutable(pluginPath) {
+				add(&Plugin{Name: strings.TrimPrefix(name, m.deployerPrefix()), Kind: KindDeployer, Path: pluginPath})
+			}
+		}
+	}
+	for _, path := range m.searchPaths {
+		for _, name := range m.pluginFiles(path) {
+			if strings.HasPrefix(name, m.deployerPrefix()) {
+				continue
+			}
+			if pluginPath := filepath.Join(path, name); isExecutable(pluginPath) {
+				add(&Plugin{Name: strings.TrimPrefix(name, m.prefix), Kind: KindCommand, Path: pluginPath})
+			}
+		}
+	}
+
+	sort.SliceStable(plugins, func(i, j int) bool {
+		if plugins[i].Kind != plugins[j].Kind {
+			return plugins[i].Kind < plugins[j].Kind
+		}
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, nil
+}
+
+// pluginFiles returns the names of the files with the plugin prefix in the dir.
+func (m *Manager) pluginFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// The search paths like the ones in $PATH may not exist.
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), m.prefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// lookup returns the path of the plugin of the longest subcommand in args and the rest args for it,
+// e.g. 'gtctl-foo-bar' takes precedence over 'gtctl-foo' for the args 'foo bar baz'.
+func (m *Manager) lookup(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("no plugin name provided")
+	}
+
+	var names []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		names = append(names, arg)
+	}
+
+	for i := len(names); i > 0; i-- {
+		if pluginPath, err := m.searchPlugins(strings.Join(names[:i], "-")); err == nil {
+			return pluginPath, args[i:], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("error: unknown command %q for \"gtctl\"", args[0])
+}
+
 func (m *Manager) searchPlugins(name string) (string, error) {
 	if len(m.searchPaths) == 0 {
 		return "", fmt.Errorf("no plugin search paths provided")
@@ -104,9 +233,13 @@ func (m *Manager) searchPlugins(name string) (string, error) {
 
 	// Construct plugin binary name gtctl-<subcmd>.
 	pluginName := m.prefix + name
+	if strings.HasPrefix(pluginName, m.deployerPrefix()) {
+		// The deployers are run by '--deployer' rather than as the subcommands.
+		return "", fmt.Errorf("error: unknown command %q for \"gtctl\"", name)
+	}
 	for _, path := range m.searchPaths {
 		pluginPath := filepath.Join(path, pluginName)
-		if exist, _ := fileutils.IsFileExists(pluginPath); !exist {
+		if exist, _ := fileutils.IsFileExists(pluginPath); !exist || !isExecutable(pluginPath) {
 			continue
 		}
 
@@ -115,3 +248,12 @@ func (m *Manager) searchPlugins(name string) (string, error) {
 
 	return "", fmt.Errorf("error: unknown command %q for \"gtctl\"", name)
 }
+
+// isExecutable returns whether the file is executable, which is always true on Windows.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
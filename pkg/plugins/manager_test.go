@@ -17,7 +17,16 @@
 package plugins
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/fake"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
 func TestPluginManager(t *testing.T) {
@@ -34,3 +43,78 @@ func TestPluginManager(t *testing.T) {
 		}
 	}
 }
+
+func TestNestedPlugins(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gtctl-foo-bar"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gtctl-baz"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gtctl-deployer-acme"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PluginSearchPathsEnvKey, dir)
+	pm, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pluginPath, args, err := pm.lookup([]string{"foo", "bar", "baz", "--qux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pluginPath != filepath.Join(dir, "gtctl-foo-bar") || !reflect.DeepEqual(args, []string{"baz", "--qux"}) {
+		t.Errorf("unexpected plugin '%s' with args %v", pluginPath, args)
+	}
+
+	// The plugin that is not executable is skipped.
+	if pm.ShouldRun("baz") {
+		t.Errorf("the plugin that is not executable should not run")
+	}
+
+	memory := fake.NewDeployer()
+	RegisterDeployer("memory", func(l logger.Logger) (opt.Deployer, error) { return memory, nil })
+	plugins, err := pm.Plugins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Plugin{
+		{Name: "foo-bar", Kind: KindCommand, Path: filepath.Join(dir, "gtctl-foo-bar")},
+		{Name: "acme", Kind: KindDeployer, Path: filepath.Join(dir, "gtctl-deployer-acme")},
+		{Name: "memory", Kind: KindDeployer},
+	}
+	if !reflect.DeepEqual(plugins, want) {
+		t.Errorf("unexpected plugins %v", plugins)
+	}
+
+	deployer, err := pm.NewDeployer("memory", nil)
+	if err != nil {
+		t.Fatalf("failed to create the registered deployer: %v", err)
+	}
+	if err := deployer.Create(context.Background(), &opt.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(memory.Operations(), []string{"Create"}) {
+		t.Errorf("unexpected operations %v", memory.Operations())
+	}
+	if _, err := pm.NewDeployer("unknown", nil); err == nil {
+		t.Errorf("the unknown deployer should not be created")
+	}
+}
+
+func TestDeployerSearchPaths(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PluginSearchPathsEnvKey, strings.Join([]string{"./testdata", dir, "."}, string(os.PathListSeparator)))
+	pm, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The relative paths are only searched for the command plugins.
+	if !reflect.DeepEqual(pm.deployerSearchPaths, []string{dir}) {
+		t.Errorf("unexpected deployer search paths %v", pm.deployerSearchPaths)
+	}
+}
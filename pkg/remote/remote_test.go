@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "--http-addr=10.0.0.1:4000", Quote("--http-addr=10.0.0.1:4000"))
+	assert.Equal(t, "''", Quote(""))
+	assert.Equal(t, "'a b'", Quote("a b"))
+	assert.Equal(t, `'it'\''s'`, Quote("it's"))
+}
+
+func TestClientArgs(t *testing.T) {
+	c := NewClient(&config.Host{Name: "node1", Address: "10.0.0.1"})
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "10.0.0.1", "--", "uptime"}, c.sshArgs("uptime"))
+
+	c = NewClient(&config.Host{Name: "node1", Address: "10.0.0.1", Port: 2222, User: "greptime", IdentityFile: "/tmp/id"})
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "-i", "/tmp/id", "-p", "2222", "greptime@10.0.0.1", "--", "uptime"},
+		c.sshArgs("uptime"))
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "-i", "/tmp/id", "-q", "-P", "2222", "/bin/greptime", "greptime@10.0.0.1:.gtctl/bin/greptime"},
+		c.scpArgs("/bin/greptime", ".gtctl/bin/greptime"))
+}
+
+func TestSupervise(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid is not available")
+	}
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, EnvFileName)
+	assert.NoError(t, os.WriteFile(envFile, RenderEnvFile(map[string]string{"GREPTIMEDB_FRONTEND__NAME": "a 'b'"}), 0600))
+	p := &Process{
+		Name:    "frontend.0",
+		Binary:  "sh",
+		Args:    []string{"-c", `echo "$GREPTIMEDB_FRONTEND__NAME"; exec sleep 60`},
+		EnvFile: envFile,
+		PidDir:  filepath.Join(dir, "pids", "frontend.0"),
+		LogDir:  filepath.Join(dir, "logs", "frontend.0"),
+	}
+	// The env is only in the env file.
+	assert.NotContains(t, StartScript(p), "a 'b'")
+
+	run := func(script string) string {
+		output, err := exec.Command("sh", "-c", script).Output()
+		if err != nil {
+			t.Fatalf("failed to run script '%s': %v", script, err)
+		}
+		return string(output)
+	}
+	probe := func() *ProcessState {
+		states, err := ParseProbes(run(ProbeScript(p.Name, p.PidDir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return states[p.Name]
+	}
+
+	run(StartScript(p))
+	var state *ProcessState
+	for i := 0; i < 50; i++ {
+		if state = probe(); state.Alive {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.True(t, state.Alive)
+	assert.Greater(t, state.Pid, 0)
+	assert.Equal(t, 0, state.Restarts)
+	assert.Eventually(t, func() bool {
+		log, _ := os.ReadFile(filepath.Join(p.LogDir, logFileName))
+		return string(log) == "a 'b'\n"
+	}, 5*time.Second, 100*time.Millisecond)
+
+	run(StopScript(p.PidDir, 5*time.Second))
+	state = probe()
+	assert.False(t, state.Alive)
+	assert.Equal(t, 0, state.Pid)
+}
+
+func TestParseProbes(t *testing.T) {
+	states, err := ParseProbes("frontend.0 123 1 2\ndatanode.0 0 0 0\n")
+	assert.NoError(t, err)
+	assert.Equal(t, &ProcessState{Name: "frontend.0", Pid: 123, Alive: true, Restarts: 2}, states["frontend.0"])
+	assert.Equal(t, &ProcessState{Name: "datanode.0"}, states["datanode.0"])
+
+	_, err = ParseProbes("frontend.0 123")
+	assert.Error(t, err)
+}
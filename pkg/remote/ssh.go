@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remote runs the commands and supervises the processes on the hosts over SSH,
+// which deploys the bare-metal cluster on multiple hosts.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+// Client runs the shell scripts and copies the files on the host by the ssh and scp of OpenSSH,
+// so the SSH config, the known hosts and the agent of current user are honored.
+type Client struct {
+	host *config.Host
+}
+
+// NewClient creates the client of host.
+func NewClient(host *config.Host) *Client {
+	return &Client{host: host}
+}
+
+// Host returns the host of client.
+func (c *Client) Host() *config.Host {
+	return c.host
+}
+
+// Run runs the shell script on the host, and returns its standard output.
+func (c *Client) Run(ctx context.Context, script string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", c.sshArgs(script)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run on host '%s': %v: %s", c.host.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Copy copies the local file to the path on the host, the path is relative to the home directory of SSH user.
+func (c *Client) Copy(ctx context.Context, local, remote string) error {
+	output, err := exec.CommandContext(ctx, "scp", c.scpArgs(local, remote)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to copy '%s' to host '%s': %v: %s", local, c.host.Name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Checksum returns the sha256 checksum of the file on the host, it's empty if the file doesn't exist.
+func (c *Client) Checksum(ctx context.Context, path string) (string, error) {
+	output, err := c.Run(ctx, fmt.Sprintf("if [ -f %[1]s ]; then sha256sum %[1]s | cut -d ' ' -f 1; fi", Quote(path)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// commonArgs returns the options shared by ssh and scp. The batch mode fails instead of prompting for the password.
+func (c *Client) commonArgs() []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if len(c.host.IdentityFile) > 0 {
+		args = append(args, "-i", c.host.IdentityFile)
+	}
+	return args
+}
+
+func (c *Client) sshArgs(script string) []string {
+	args := c.commonArgs()
+	if c.host.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(c.host.Port))
+	}
+	return append(args, c.target(), "--", script)
+}
+
+func (c *Client) scpArgs(local, remote string) []string {
+	args := append(c.commonArgs(), "-q")
+	if c.host.Port > 0 {
+		args = append(args, "-P", strconv.Itoa(c.host.Port))
+	}
+	return append(args, local, fmt.Sprintf("%s:%s", c.target(), remote))
+}
+
+// target returns the destination of ssh, e.g. 'greptime@10.0.0.1'.
+func (c *Client) target() string {
+	if len(c.host.User) > 0 {
+		return fmt.Sprintf("%s@%s", c.host.User, c.host.Address)
+	}
+	return c.host.Address
+}
+
+// Quote quotes s as one word of shell.
+func Quote(s string) string {
+	if len(s) > 0 && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@%+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The files under the pid dir and the log dir of replica are named as the ones of the local replicas.
+	pidFileName      = "pid"
+	restartsFileName = "restarts"
+	logFileName      = "log"
+
+	// EnvFileName is the name of the env file of process under its pid dir, see RenderEnvFile.
+	EnvFileName = "env"
+
+	// RestartBackoff is the interval of restarting the exited process.
+	RestartBackoff = 3 * time.Second
+)
+
+// Process is the process that is supervised on the host.
+type Process struct {
+	// Name is the name of replica, e.g. "frontend.0".
+	Name string
+
+	Binary string
+	Args   []string
+
+	// EnvFile is the file rendered by RenderEnvFile on the host, which is sourced before the process starts.
+	// It should be only readable by the owner, so the secrets in env are not in the command lines.
+	EnvFile string
+
+	PidDir string
+	LogDir string
+}
+
+// ProcessState is the state of the supervised process probed on the host.
+type ProcessState struct {
+	Name     string
+	Pid      int
+	Alive    bool
+	Restarts int
+}
+
+// StartScript returns the script that starts the process in background on the host. The process runs in
+// its own session, and is restarted after RestartBackoff whenever it exits until it's stopped by StopScript,
+// which removes the pid file first. The pid of session is recorded in the pid file. The script runs in a
+// subshell, so the scripts of multiple processes can be joined into one.
+func StartScript(p *Process) string {
+	pidFile := path.Join(p.PidDir, pidFileName)
+	restartsFile := path.Join(p.PidDir, restartsFileName)
+	logFile := path.Join(p.LogDir, logFileName)
+
+	command := []string{Quote(p.Binary)}
+	for _, arg := range p.Args {
+		command = append(command, Quote(arg))
+	}
+
+	loop := fmt.Sprintf("n=0; while :; do %s >> %s 2>&1; [ -f %s ] || break; n=$((n+1)); echo $n > %s; sleep %d; done",
+		strings.Join(command, " "), Quote(logFile), Quote(pidFile), Quote(restartsFile), int(RestartBackoff.Seconds()))
+	if len(p.EnvFile) > 0 {
+		envFile := p.EnvFile
+		if !path.IsAbs(envFile) {
+			// The file in current dir is not found by "." without the slash.
+			envFile = "./" + envFile
+		}
+		loop = fmt.Sprintf(". %s || exit 1; %s", Quote(envFile), loop)
+	}
+
+	return fmt.Sprintf("(mkdir -p %s %s && echo 0 > %s || exit 1; nohup setsid sh -c %s > /dev/null 2>&1 < /dev/null & echo $! > %s)",
+		Quote(p.PidDir), Quote(p.LogDir), Quote(restartsFile), Quote(loop), Quote(pidFile))
+}
+
+// RenderEnvFile renders the env as the content of the env file of process, which exports the env to the process.
+func RenderEnvFile(env map[string]string) []byte {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "export %s=%s\n", k, Quote(env[k]))
+	}
+	return []byte(buf.String())
+}
+
+// StopScript returns the script that stops the process started by StartScript, all the processes in
+// its session are terminated, and they are killed if they are still alive after the timeout. Like StartScript,
+// the script runs in a subshell.
+func StopScript(pidDir string, timeout time.Duration) string {
+	pidFile := path.Join(pidDir, pidFileName)
+	seconds := int(timeout.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return fmt.Sprintf(`([ -f %[1]s ] || exit 0; pid=$(cat %[1]s); rm -f %[1]s; kill -TERM -"$pid" 2>/dev/null || exit 0; `+
+		`i=0; while [ $i -lt %[2]d ]; do kill -0 -"$pid" 2>/dev/null || exit 0; sleep 1; i=$((i+1)); done; `+
+		`kill -KILL -"$pid" 2>/dev/null; exit 0)`, Quote(pidFile), seconds)
+}
+
+// ProbeScript returns the script that prints the state of the process started by StartScript in one line,
+// the output of the scripts of multiple processes are parsed by ParseProbes.
+func ProbeScript(name, pidDir string) string {
+	pidFile := path.Join(pidDir, pidFileName)
+	restartsFile := path.Join(pidDir, restartsFileName)
+
+	return fmt.Sprintf(`pid=$(cat %[2]s 2>/dev/null || echo 0); alive=0; `+
+		`[ "$pid" -gt 0 ] && kill -0 -"$pid" 2>/dev/null && alive=1; `+
+		`echo %[1]s "$pid" "$alive" "$(cat %[3]s 2>/dev/null || echo 0)"`,
+		Quote(name), Quote(pidFile), Quote(restartsFile))
+}
+
+// ParseProbes parses the output of the probe scripts, the states are keyed by the name of process.
+func ParseProbes(output string) (map[string]*ProcessState, error) {
+	states := make(map[string]*ProcessState)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid probe of process: '%s'", line)
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid of process '%s': %v", fields[0], err)
+		}
+		restarts, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid restarts of process '%s': %v", fields[0], err)
+		}
+		states[fields[0]] = &ProcessState{Name: fields[0], Pid: pid, Alive: fields[2] == "1", Restarts: restarts}
+	}
+	return states, nil
+}
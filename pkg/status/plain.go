@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package status
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// PlainEnvKey is the environment variable that forces the plain progress, which is set by '--plain'.
+	PlainEnvKey = "GTCTL_PLAIN"
+
+	// plainTimeFormat is the format of the timestamp of the plain progress lines.
+	plainTimeFormat = "2006-01-02T15:04:05Z07:00"
+)
+
+// IsPlain returns whether the progress should be reported in plain lines instead of the spinners and
+// the progress bars, which is forced by PlainEnvKey, or it's not a terminal that out writes to.
+func IsPlain(out io.Writer) bool {
+	if plain, err := strconv.ParseBool(os.Getenv(PlainEnvKey)); err == nil && plain {
+		return true
+	}
+	f, ok := out.(*os.File)
+	return !ok || !term.IsTerminal(int(f.Fd()))
+}
+
+// PlainLine returns the plain progress line of the message that is prefixed with the current timestamp.
+func PlainLine(message string) string {
+	return fmt.Sprintf("%s %s\n", time.Now().Format(plainTimeFormat), message)
+}
@@ -18,6 +18,9 @@ package status
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -46,30 +49,116 @@ const (
 	defaultDelay = 100 * time.Millisecond
 )
 
+// Spinner reports the progress of the long running steps, e.g. installing the components of cluster.
+// It's a spinner on the terminal, and the timestamped lines in the plain mode, e.g. in the CI logs.
 type Spinner struct {
 	spinner *spinner.Spinner
+	out     io.Writer
+	plain   bool
+
+	// The status of the running step and the one with its progress.
+	mu      sync.Mutex
+	step    string
+	status  string
+	started time.Time
 }
 
-func NewSpinner() (*Spinner, error) {
-	s := spinner.New(spinnerFrames, defaultDelay)
-	if err := s.Color("fgHiWhite", "bold"); err != nil {
+// Option is the option of the spinner.
+type Option func(*Spinner)
+
+// WithWriter writes the progress to out, the default is stdout.
+func WithWriter(out io.Writer) Option {
+	return func(s *Spinner) {
+		s.out = out
+	}
+}
+
+// WithPlain reports the progress in plain lines if plain is true.
+func WithPlain(plain bool) Option {
+	return func(s *Spinner) {
+		s.plain = s.plain || plain
+	}
+}
+
+func NewSpinner(opts ...Option) (*Spinner, error) {
+	s := &Spinner{out: os.Stdout}
+	for _, opt := range opts {
+		opt(s)
+	}
+	// The spinner is only rendered if stdout is a terminal, whichever it writes to.
+	s.plain = s.plain || IsPlain(s.out) || IsPlain(os.Stdout)
+	if s.plain {
+		return s, nil
+	}
+
+	s.spinner = spinner.New(spinnerFrames, defaultDelay, spinner.WithWriter(s.out))
+	if err := s.spinner.Color("fgHiWhite", "bold"); err != nil {
 		return nil, err
 	}
-	return &Spinner{
-		spinner: s,
-	}, nil
+	return s, nil
 }
 
 func (s *Spinner) Start(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.step, s.status, s.started = status, status, time.Now()
+	if s.plain {
+		fmt.Fprint(s.out, PlainLine(status))
+		return
+	}
 	s.spinner.Start()
 	s.spinner.Suffix = fmt.Sprintf(" %s", status)
 }
 
+// Progress reports the progress of the running step, e.g. the component being started or the percentage of
+// the ready replicas, which follows the status of the step. The progress is printed in a new line in the plain
+// mode only if it changes.
+func (s *Spinner) Progress(progress string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := fmt.Sprintf("%s (%s)", s.step, progress)
+	if status == s.status {
+		return
+	}
+	s.status = status
+	if s.plain {
+		fmt.Fprint(s.out, PlainLine(status))
+		return
+	}
+	s.spinner.Lock()
+	s.spinner.Suffix = fmt.Sprintf(" %s", status)
+	s.spinner.Unlock()
+}
+
 func (s *Spinner) Stop(success bool, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := time.Since(s.started).Round(time.Second)
+	if s.plain {
+		result := "done"
+		if !success {
+			result = "failed"
+		}
+		fmt.Fprint(s.out, PlainLine(fmt.Sprintf("%s (%s in %s)", status, result, elapsed)))
+		return
+	}
+
 	if success {
-		s.spinner.FinalMSG = fmt.Sprintf(" \x1b[32m✓\x1b[0m %s\n", status)
+		s.spinner.FinalMSG = fmt.Sprintf(" \x1b[32m✓\x1b[0m %s \x1b[2m(%s)\x1b[0m\n", status, elapsed)
 	} else {
-		s.spinner.FinalMSG = fmt.Sprintf(" \x1b[31m✗\x1b[0m %s 😵‍💫\n", status)
+		s.spinner.FinalMSG = fmt.Sprintf(" \x1b[31m✗\x1b[0m %s 😵‍💫 \x1b[2m(%s)\x1b[0m\n", status, elapsed)
 	}
 	s.spinner.Stop()
 }
+
+// StepStatus returns the status of the step in all the steps, e.g. '[1/3] Installing Etcd Cluster...'.
+func StepStatus(step, total int, status string) string {
+	return fmt.Sprintf("[%d/%d] %s", step, total, status)
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package systemd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Unit is one systemd unit, the empty settings are omitted in rendering.
+type Unit struct {
+	// Name is the file name of unit, e.g. "greptimedb-mycluster-frontend.0.service".
+	Name string
+
+	Description string
+
+	// After and Requires are the names of the units that the unit is ordered after and depends on.
+	After    []string
+	Requires []string
+	Wants    []string
+	PartOf   []string
+
+	// Service is nil for the units that are not services, e.g. the target.
+	Service *Service
+
+	// WantedBy are the units that enable the unit when it's installed.
+	WantedBy []string
+}
+
+// Service is the [Service] section of the service unit.
+type Service struct {
+	User             string
+	WorkingDirectory string
+	Environment      map[string]string
+
+	// EnvironmentFile is the file of the environment variables that should not be seen in the unit, e.g. passwords,
+	// see RenderEnvironmentFile.
+	EnvironmentFile string
+
+	// ExecStartPre, ExecStart and ExecStartPost are the argv of commands, which are escaped in rendering.
+	ExecStartPre  [][]string
+	ExecStart     []string
+	ExecStartPost [][]string
+
+	// Restart is the restart policy of systemd, e.g. "on-failure".
+	Restart    string
+	RestartSec int
+
+	TimeoutStopSec int
+	StandardOutput string
+	StandardError  string
+
+	LimitNOFILE int
+	Nice        int
+	MemoryMax   string
+	CPUQuota    string
+}
+
+// TargetName returns the name of the target unit, e.g. "greptimedb-mycluster.target".
+func TargetName(prefix string) string {
+	return fmt.Sprintf("%s.target", prefix)
+}
+
+// ServiceName returns the name of the service unit, e.g. "greptimedb-mycluster-frontend.0.service".
+func ServiceName(prefix, name string) string {
+	return fmt.Sprintf("%s-%s.service", prefix, name)
+}
+
+// Render renders the unit as the content of unit file.
+func (u *Unit) Render() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("[Unit]\n")
+	writeSetting(&buf, "Description", u.Description)
+	writeList(&buf, "After", u.After)
+	writeList(&buf, "Requires", u.Requires)
+	writeList(&buf, "Wants", u.Wants)
+	writeList(&buf, "PartOf", u.PartOf)
+
+	if s := u.Service; s != nil {
+		buf.WriteString("\n[Service]\n")
+		writeSetting(&buf, "Type", "simple")
+		writeSetting(&buf, "User", s.User)
+		writeSetting(&buf, "WorkingDirectory", s.WorkingDirectory)
+
+		writeSetting(&buf, "EnvironmentFile", quoteIfNonEmpty(s.EnvironmentFile))
+		keys := make([]string, 0, len(s.Environment))
+		for k := range s.Environment {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeSetting(&buf, "Environment", Quote(fmt.Sprintf("%s=%s", k, s.Environment[k])))
+		}
+
+		for _, argv := range s.ExecStartPre {
+			writeSetting(&buf, "ExecStartPre", CommandLine(argv))
+		}
+		writeSetting(&buf, "ExecStart", CommandLine(s.ExecStart))
+		for _, argv := range s.ExecStartPost {
+			writeSetting(&buf, "ExecStartPost", CommandLine(argv))
+		}
+
+		writeSetting(&buf, "Restart", s.Restart)
+		writeInt(&buf, "RestartSec", s.RestartSec)
+		writeInt(&buf, "TimeoutStopSec", s.TimeoutStopSec)
+		writeSetting(&buf, "StandardOutput", s.StandardOutput)
+		writeSetting(&buf, "StandardError", s.StandardError)
+		writeInt(&buf, "LimitNOFILE", s.LimitNOFILE)
+		writeInt(&buf, "Nice", s.Nice)
+		writeSetting(&buf, "MemoryMax", s.MemoryMax)
+		writeSetting(&buf, "CPUQuota", s.CPUQuota)
+	}
+
+	if len(u.WantedBy) > 0 {
+		buf.WriteString("\n[Install]\n")
+		writeList(&buf, "WantedBy", u.WantedBy)
+	}
+
+	return buf.Bytes()
+}
+
+// RenderEnvironmentFile renders the env as the content of the environment file, the values are
+// double-quoted and escaped, so they are passed as is.
+func RenderEnvironmentFile(env map[string]string) []byte {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", k, escaper.Replace(env[k]))
+	}
+	return buf.Bytes()
+}
+
+// CommandLine renders the argv as the command line of systemd, the words are quoted if needed.
+func CommandLine(argv []string) string {
+	words := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		words = append(words, Quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// Quote quotes the word for the command lines and the settings of systemd. The specifiers and
+// the variables are escaped, so the word is passed to the command as is.
+func Quote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if len(s) > 0 && !strings.ContainsAny(s, " \t\n\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func quoteIfNonEmpty(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return Quote(s)
+}
+
+func writeSetting(buf *bytes.Buffer, key, value string) {
+	if len(value) > 0 {
+		fmt.Fprintf(buf, "%s=%s\n", key, value)
+	}
+}
+
+func writeInt(buf *bytes.Buffer, key string, value int) {
+	if value != 0 {
+		fmt.Fprintf(buf, "%s=%d\n", key, value)
+	}
+}
+
+func writeList(buf *bytes.Buffer, key string, values []string) {
+	if len(values) > 0 {
+		fmt.Fprintf(buf, "%s=%s\n", key, strings.Join(values, " "))
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+)
+
+// The failure categories of the errors, the messages of errors are never reported since they may carry
+// the names of clusters and the hosts.
+const (
+	FailureTimeout    = "timeout"
+	FailureCanceled   = "canceled"
+	FailurePermission = "permission"
+	FailureNetwork    = "network"
+	FailureNotFound   = "not-found"
+	FailureUsage      = "usage"
+	FailureOther      = "other"
+)
+
+// Categorize returns the failure category of err, it's empty if err is nil. Most errors of gtctl are formatted
+// without wrapping, so the category is also inferred from the message.
+func Categorize(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var netErr net.Error
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || containsAny(msg, "deadline exceeded", "timeout", "timed out"):
+		return FailureTimeout
+	case errors.Is(err, context.Canceled) || strings.Contains(msg, "context canceled"):
+		return FailureCanceled
+	case errors.Is(err, os.ErrPermission) || containsAny(msg, "permission denied", "forbidden", "unauthorized"):
+		return FailurePermission
+	case errors.As(err, &netErr) || containsAny(msg, "connection refused", "no such host", "connection reset", "network is unreachable"):
+		return FailureNetwork
+	case errors.Is(err, os.ErrNotExist) || containsAny(msg, "not found", "no such file"):
+		return FailureNotFound
+	case containsAny(msg, "unknown command", "unknown flag", "unknown shorthand flag", "required flag", "accepts ", "invalid", "unsupported"):
+		return FailureUsage
+	default:
+		return FailureOther
+	}
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package telemetry reports the usage of gtctl, which is opted in by 'gtctl config set telemetry on', to the
+// endpoint set by 'gtctl config set telemetryEndpoint <url>'. There is no default endpoint.
+// The reported events are anonymous: they carry a random installation ID, the command path and the names
+// of the flags set, but never the arguments, the values of flags, the names of clusters or the hosts.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// EnvKey is the environment variable that turns off the telemetry regardless of the global config,
+	// e.g. 'GTCTL_TELEMETRY=off'.
+	EnvKey = "GTCTL_TELEMETRY"
+
+	// IDFile is the path of the file that keeps the installation ID relative to the home directory.
+	IDFile = ".gtctl/telemetry-id"
+
+	// unassignedID is the installation ID previewed before the first event is reported.
+	unassignedID = "unassigned"
+
+	reportTimeout = 2 * time.Second
+)
+
+// Event is the usage of one gtctl command.
+type Event struct {
+	// InstallationID is generated randomly on the first report, it's not derived from the host.
+	InstallationID string `json:"installationID"`
+	Version        string `json:"version"`
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+
+	// Command is the path of the command without the binary name, e.g. 'cluster create'.
+	Command string `json:"command"`
+
+	// Flags are the names of the flags set in the command line, their values are not reported.
+	Flags []string `json:"flags,omitempty"`
+
+	// DeploymentMode is one of 'kubernetes', 'bare-metal' and 'docker', it's empty if the command has no modes.
+	DeploymentMode string `json:"deploymentMode,omitempty"`
+
+	Success bool `json:"success"`
+
+	// FailureCategory is the coarse category of the error, see Categorize.
+	FailureCategory string `json:"failureCategory,omitempty"`
+
+	DurationMillis int64     `json:"durationMillis"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Enabled returns whether the usage should be reported, it requires the explicit opt-in and the endpoint
+// in the global config, and is turned off by the environment variable.
+func Enabled(cfg *globalconfig.Config) bool {
+	if env := os.Getenv(EnvKey); env != "" {
+		if enabled, err := globalconfig.ParseSwitch(env); err == nil && !enabled {
+			return false
+		}
+	}
+	return cfg.TelemetryEnabled() && len(cfg.TelemetryEndpoint) > 0
+}
+
+// Reporter reports the events to the endpoint.
+type Reporter struct {
+	endpoint string
+	client   *http.Client
+	homeDir  string
+
+	// If preview is not nil, the events are written to it instead of being reported.
+	preview io.Writer
+}
+
+type Option func(*Reporter)
+
+// WithEndpoint sets the endpoint that the events are reported to, which is required unless they are previewed.
+func WithEndpoint(endpoint string) Option {
+	return func(r *Reporter) {
+		r.endpoint = endpoint
+	}
+}
+
+// WithHomeDir sets the home directory where the installation ID is kept, the user home directory is used by default.
+func WithHomeDir(homeDir string) Option {
+	return func(r *Reporter) {
+		r.homeDir = homeDir
+	}
+}
+
+// WithPreview writes the full payload of the events to out instead of reporting them.
+func WithPreview(out io.Writer) Option {
+	return func(r *Reporter) {
+		r.preview = out
+	}
+}
+
+func NewReporter(opts ...Option) *Reporter {
+	r := &Reporter{
+		client: &http.Client{Timeout: reportTimeout},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// NewEvent creates the event of the command that has run for elapsed and failed with err if it's not nil.
+func NewEvent(version, command string, flags []string, deploymentMode string, elapsed time.Duration, err error) *Event {
+	return &Event{
+		Version:         version,
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		Command:         command,
+		Flags:           flags,
+		DeploymentMode:  deploymentMode,
+		Success:         err == nil,
+		FailureCategory: Categorize(err),
+		DurationMillis:  elapsed.Milliseconds(),
+		Timestamp:       time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// Report reports the event, or writes it to the preview writer. The installation ID is generated on the first report,
+// and it's not generated by the preview.
+func (r *Reporter) Report(ctx context.Context, event *Event) error {
+	if r.preview == nil && len(r.endpoint) == 0 {
+		return fmt.Errorf("the telemetry endpoint is not set")
+	}
+
+	id, err := r.installationID(r.preview == nil)
+	if err != nil {
+		return err
+	}
+	event.InstallationID = id
+
+	if r.preview != nil {
+		encoder := json.NewEncoder(r.preview)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(event)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to report the telemetry to '%s': %s", r.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// installationID returns the installation ID kept in the home directory, it's generated and kept if create is true.
+func (r *Reporter) installationID(create bool) (string, error) {
+	homeDir := r.homeDir
+	if homeDir == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		homeDir = dir
+	}
+	idFile := filepath.Join(homeDir, IDFile)
+
+	data, err := os.ReadFile(idFile)
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if !create {
+		return unassignedID, nil
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+
+	if err := fileutils.EnsureDir(filepath.Dir(idFile)); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(idFile, []byte(id), 0600); err != nil {
+		return "", err
+	}
+	return id, nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/globalconfig"
+)
+
+func TestEnabled(t *testing.T) {
+	on, off := true, false
+
+	endpoint := "https://telemetry.example.com/events"
+
+	t.Setenv(EnvKey, "")
+	assert.False(t, Enabled(&globalconfig.Config{}))
+	assert.False(t, Enabled(&globalconfig.Config{Telemetry: &off, TelemetryEndpoint: endpoint}))
+	assert.True(t, Enabled(&globalconfig.Config{Telemetry: &on, TelemetryEndpoint: endpoint}))
+
+	// There is no default endpoint.
+	assert.False(t, Enabled(&globalconfig.Config{Telemetry: &on}))
+
+	// The environment variable only turns off the telemetry.
+	t.Setenv(EnvKey, "off")
+	assert.False(t, Enabled(&globalconfig.Config{Telemetry: &on, TelemetryEndpoint: endpoint}))
+	t.Setenv(EnvKey, "on")
+	assert.False(t, Enabled(&globalconfig.Config{}))
+}
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("waiting for cluster: %w", context.DeadlineExceeded), FailureTimeout},
+		{context.Canceled, FailureCanceled},
+		{fmt.Errorf("open /root/.gtctl: permission denied"), FailurePermission},
+		{fmt.Errorf("dial tcp 127.0.0.1:6443: connect: connection refused"), FailureNetwork},
+		{fmt.Errorf("cluster 'mycluster' not found"), FailureNotFound},
+		{fmt.Errorf("unknown flag: --foo"), FailureUsage},
+		{errors.New("something wrong"), FailureOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Categorize(tt.err))
+	}
+}
+
+func TestReport(t *testing.T) {
+	homeDir := t.TempDir()
+
+	var received []*Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var event Event
+		assert.NoError(t, json.Unmarshal(data, &event))
+		received = append(received, &event)
+	}))
+	defer server.Close()
+
+	// The preview doesn't generate the installation ID.
+	var preview bytes.Buffer
+	event := NewEvent("v0.1.9", "cluster create", []string{"bare-metal"}, globalconfig.DeploymentModeBareMetal, 3*time.Second, errors.New("boom"))
+	assert.NoError(t, NewReporter(WithHomeDir(homeDir), WithPreview(&preview)).Report(context.Background(), event))
+	assert.Contains(t, preview.String(), `"installationID": "unassigned"`)
+	assert.Contains(t, preview.String(), `"failureCategory": "other"`)
+	assert.Empty(t, received)
+	_, err := os.Stat(filepath.Join(homeDir, IDFile))
+	assert.True(t, os.IsNotExist(err))
+
+	// The endpoint is required to report.
+	assert.Error(t, NewReporter(WithHomeDir(homeDir)).Report(context.Background(), event))
+
+	reporter := NewReporter(WithHomeDir(homeDir), WithEndpoint(server.URL))
+	assert.NoError(t, reporter.Report(context.Background(), NewEvent("v0.1.9", "cluster list", nil, "", time.Second, nil)))
+	assert.NoError(t, reporter.Report(context.Background(), NewEvent("v0.1.9", "cluster list", nil, "", time.Second, nil)))
+	assert.Len(t, received, 2)
+	assert.Len(t, received[0].InstallationID, 32)
+	assert.Equal(t, received[0].InstallationID, received[1].InstallationID)
+	assert.True(t, received[0].Success)
+	assert.Equal(t, int64(1000), received[0].DurationMillis)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(homeDir, IDFile))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
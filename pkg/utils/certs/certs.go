@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"time"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	CACertFileName     = "ca.crt"
+	ServerCertFileName = "server.crt"
+	ServerKeyFileName  = "server.key"
+
+	// validity is the validity period of the generated certificates.
+	validity = 365 * 24 * time.Hour
+)
+
+// Paths are the paths of the certificates in one directory.
+type Paths struct {
+	CACert     string
+	ServerCert string
+	ServerKey  string
+}
+
+// PathsIn returns the paths of the certificates in dir.
+func PathsIn(dir string) *Paths {
+	return &Paths{
+		CACert:     path.Join(dir, CACertFileName),
+		ServerCert: path.Join(dir, ServerCertFileName),
+		ServerKey:  path.Join(dir, ServerKeyFileName),
+	}
+}
+
+// Exists returns whether the server certificate and key are both in dir.
+func Exists(dir string) (bool, error) {
+	paths := PathsIn(dir)
+	for _, file := range []string{paths.ServerCert, paths.ServerKey} {
+		exists, err := fileutils.IsFileExists(file)
+		if err != nil || !exists {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Generate generates a self-signed CA and a server certificate signed by it for the hosts,
+// and writes them into dir. The existing certificates in dir are overwritten.
+func Generate(dir string, hosts []string) (*Paths, error) {
+	if err := fileutils.EnsureDir(dir); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{Organization: []string{"gtctl"}, CommonName: "gtctl self-signed CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{Organization: []string{"gtctl"}, CommonName: "greptimedb"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, ca, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server certificate: %v", err)
+	}
+	serverKeyDER, err := x509.MarshalPKCS8PrivateKey(serverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := PathsIn(dir)
+	if err = writePEM(paths.CACert, "CERTIFICATE", caDER, 0644); err != nil {
+		return nil, err
+	}
+	if err = writePEM(paths.ServerCert, "CERTIFICATE", serverDER, 0644); err != nil {
+		return nil, err
+	}
+	if err = writePEM(paths.ServerKey, "PRIVATE KEY", serverKeyDER, 0600); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func writePEM(file, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(file, data, perm); err != nil {
+		return fmt.Errorf("failed to write '%s': %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := path.Join(t.TempDir(), "certs")
+
+	exists, err := Exists(dir)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	paths, err := Generate(dir, []string{"localhost", "127.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, PathsIn(dir), paths)
+
+	exists, err = Exists(dir)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// The server certificate is signed by the CA for the hosts.
+	pair, err := tls.LoadX509KeyPair(paths.ServerCert, paths.ServerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	assert.NoError(t, err)
+
+	caPEM, err := os.ReadFile(paths.CACert)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	assert.True(t, roots.AppendCertsFromPEM(caPEM))
+
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots})
+	assert.Error(t, err)
+
+	info, err := os.Stat(paths.ServerKey)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveTarGz archives the files or directories into the gzipped tarball. Each of them is placed
+// under its key in the tarball, e.g. the directory "/data" keyed by "data" is archived as "data/...".
+// The file or directory that does not exist is skipped.
+func ArchiveTarGz(output string, paths map[string]string) error {
+	return archiveTar(output, paths, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+}
+
+// ArchiveTarball archives the files or directories into the tarball like ArchiveTarGz,
+// which is compressed by gzip or zstd according to the extension of output.
+func ArchiveTarball(output string, paths map[string]string) error {
+	switch {
+	case strings.HasSuffix(output, TarGzExtension), strings.HasSuffix(output, TgzExtension):
+		return ArchiveTarGz(output, paths)
+	case strings.HasSuffix(output, TarZstExtension):
+		return archiveTar(output, paths, func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		})
+	default:
+		return fmt.Errorf("unsupported tarball '%s', it should end with '%s', '%s' or '%s'",
+			output, TarGzExtension, TgzExtension, TarZstExtension)
+	}
+}
+
+// archiveTar archives the files or directories into the tarball compressed by the writer of compress.
+func archiveTar(output string, paths map[string]string, compress func(io.Writer) (io.WriteCloser, error)) (err error) {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	cw, err := compress(f)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err = archivePath(tw, name, paths[name]); err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+// archivePath writes the file, or the files under the directory, to the tarball with the prefix.
+func archivePath(tw *tar.Writer, prefix, root string) error {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		src, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+}
+
+// ExtractTarGz extracts the gzipped tarball to the destination directory. Unlike Uncompress,
+// it streams the tarball, and rejects the entries that would be extracted out of dst, including
+// the symlinks pointing out of dst and the entries written through an extracted symlink.
+func ExtractTarGz(file, dst string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	return extractTar(gr, dst)
+}
+
+// ExtractTarball extracts the tarball like ExtractTarGz, which is compressed by gzip
+// or zstd according to the extension of file.
+func ExtractTarball(file, dst string) error {
+	if !strings.HasSuffix(file, TarZstExtension) {
+		return ExtractTarGz(file, dst)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	return extractTar(zr, dst)
+}
+
+// extractTar extracts the uncompressed tarball from r to the destination directory.
+func extractTar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, header.Name)
+		if !withinDir(dst, target) {
+			return fmt.Errorf("entry '%s' is out of '%s'", header.Name, dst)
+		}
+		// The lexical check above is not enough if a symlink extracted earlier is on the path,
+		// e.g. 'data/x -> /etc' followed by 'data/x/passwd'.
+		if err = checkNoSymlink(dst, target); err != nil {
+			return fmt.Errorf("entry '%s' is rejected: %v", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = extractFile(tr, target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) || !withinDir(dst, filepath.Join(filepath.Dir(target), header.Linkname)) {
+				return fmt.Errorf("symlink '%s' to '%s' is out of '%s'", header.Name, header.Linkname, dst)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err = os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported type of entry '%s'", header.Name)
+		}
+	}
+}
+
+// withinDir returns true if the target is the dir or under it, lexically.
+func withinDir(dir, target string) bool {
+	dir, target = filepath.Clean(dir), filepath.Clean(target)
+	return target == dir || strings.HasPrefix(target, dir+string(os.PathSeparator))
+}
+
+// checkNoSymlink returns an error if the target, or any of its parents under dst, is an existing symlink,
+// so that nothing is written through a symlink out of dst.
+func checkNoSymlink(dst, target string) error {
+	rel, err := filepath.Rel(filepath.Clean(dst), target)
+	if err != nil || rel == "." {
+		return err
+	}
+
+	current := filepath.Clean(dst)
+	for _, name := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, name)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("'%s' is a symlink", current)
+		}
+	}
+	return nil
+}
+
+// extractFile writes the content of the current entry of tarball to the target.
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	w, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
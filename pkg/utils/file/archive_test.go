@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveTarGz(t *testing.T) {
+	src := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "datanode.0", "home"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "datanode.0", "home", "data"), []byte("helloworld"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "metadata.yaml"), []byte("name: test"), 0600))
+	config := filepath.Join(t.TempDir(), "test.yaml")
+	assert.NoError(t, os.WriteFile(config, []byte("cluster: {}"), 0644))
+
+	output := filepath.Join(t.TempDir(), "backup.tar.gz")
+	assert.NoError(t, ArchiveTarGz(output, map[string]string{
+		"data":        src,
+		"config.yaml": config,
+		"missing":     filepath.Join(src, "missing"),
+	}))
+
+	dst := t.TempDir()
+	assert.NoError(t, ExtractTarGz(output, dst))
+
+	data, err := os.ReadFile(filepath.Join(dst, "data", "datanode.0", "home", "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "helloworld", string(data))
+
+	info, err := os.Stat(filepath.Join(dst, "data", "metadata.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err = os.ReadFile(filepath.Join(dst, "config.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster: {}", string(data))
+
+	_, err = os.Stat(filepath.Join(dst, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestArchiveTarball(t *testing.T) {
+	src := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "data"), []byte("helloworld"), 0644))
+
+	for _, name := range []string{"backup.tar.gz", "backup.tgz", "backup.tar.zst"} {
+		output := filepath.Join(t.TempDir(), name)
+		assert.NoError(t, ArchiveTarball(output, map[string]string{"data": src}))
+
+		dst := t.TempDir()
+		assert.NoError(t, ExtractTarball(output, dst))
+		data, err := os.ReadFile(filepath.Join(dst, "data", "data"))
+		assert.NoError(t, err)
+		assert.Equal(t, "helloworld", string(data))
+	}
+
+	// The zstd archive is not gzipped.
+	output := filepath.Join(t.TempDir(), "backup.tar.zst")
+	assert.NoError(t, ArchiveTarball(output, map[string]string{"data": src}))
+	assert.Error(t, ExtractTarGz(output, t.TempDir()))
+
+	assert.Error(t, ArchiveTarball(filepath.Join(t.TempDir(), "backup.zip"), map[string]string{"data": src}))
+}
+
+func TestExtractTarGzRejectsSymlinkEscape(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{
+			name: "absolute-link-then-write-through",
+			entries: []tar.Header{
+				{Name: "data/x", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+				{Name: "data/x/passwd", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+		{
+			name: "relative-link-escape",
+			entries: []tar.Header{
+				{Name: "data/x", Typeflag: tar.TypeSymlink, Linkname: "../../outside"},
+			},
+		},
+		{
+			name: "write-through-inner-link",
+			entries: []tar.Header{
+				{Name: "data/", Typeflag: tar.TypeDir, Mode: 0755},
+				{Name: "data/inner", Typeflag: tar.TypeDir, Mode: 0755},
+				{Name: "data/x", Typeflag: tar.TypeSymlink, Linkname: "inner"},
+				{Name: "data/x/file", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			outside := filepath.Join(root, "outside")
+			assert.NoError(t, os.MkdirAll(outside, 0755))
+
+			archive := filepath.Join(root, "crafted.tar.gz")
+			writeTarGz(t, archive, tt.entries)
+
+			dst := filepath.Join(root, "dst")
+			assert.Error(t, ExtractTarGz(archive, dst))
+
+			files, err := os.ReadDir(outside)
+			assert.NoError(t, err)
+			assert.Empty(t, files)
+			_, err = os.Stat(filepath.Join(dst, "data", "inner", "file"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// writeTarGz writes the entries into a gzipped tarball, the regular files are written with a fixed content.
+func writeTarGz(t *testing.T, output string, entries []tar.Header) {
+	f, err := os.Create(output)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	content := []byte("pwned")
+	for i := range entries {
+		if entries[i].Typeflag == tar.TypeReg {
+			entries[i].Size = int64(len(content))
+		}
+		assert.NoError(t, tw.WriteHeader(&entries[i]))
+		if entries[i].Typeflag == tar.TypeReg {
+			_, err = tw.Write(content)
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+}
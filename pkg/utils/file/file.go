@@ -89,12 +89,89 @@ func CopyFile(src, dst string) error {
 	return w.Sync()
 }
 
+// ReplaceFile atomically replaces dst with the content of src, the mode of dst is kept if it exists.
+// The content is copied to a temporary file in the directory of dst first, which is renamed to dst then,
+// so dst is either the old one or the new one even if it's interrupted, e.g. the running executable.
+func ReplaceFile(src, dst string) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(dst); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-")
+	if err != nil {
+		return err
+	}
+	tempFile := w.Name()
+	defer os.Remove(tempFile)
+
+	if _, err = io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Sync(); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tempFile, mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, dst)
+}
+
+// DirSize returns the total size in bytes of the regular files under the directory recursively,
+// the files that are removed while walking are skipped.
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// HumanSize returns the size in bytes in the binary units, e.g. '1.5KiB' and '20.0MiB'.
+func HumanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 const (
-	ZipExtension   = ".zip"
-	TarGzExtension = ".tar.gz"
-	TgzExtension   = ".tgz"
-	GzExtension    = ".gz"
-	TarExtension   = ".tar"
+	ZipExtension    = ".zip"
+	TarGzExtension  = ".tar.gz"
+	TarZstExtension = ".tar.zst"
+	TgzExtension    = ".tgz"
+	GzExtension     = ".gz"
+	TarExtension    = ".tar"
 )
 
 // Uncompress uncompresses the file to the destination directory.
@@ -59,3 +59,87 @@ func TestUncompress(t *testing.T) {
 		}
 	}
 }
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1536, "1.5KiB"},
+		{20 << 20, "20.0MiB"},
+		{3 << 30, "3.0GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := HumanSize(tt.size); got != tt.want {
+			t.Errorf("HumanSize(%d) = %s, want %s", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := path.Join(dir, "new"), path.Join(dir, "gtctl")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceFile(src, dst); err != nil {
+		t.Fatalf("failed to replace file: %v", err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("got content '%s', want 'new'", data)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0750))
+	}
+
+	// No temporary file is left.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d files, want 2", len(entries))
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(path.Join(dir, "wal"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(dir, "manifest"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(dir, "wal", "000001.log"), make([]byte, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := DirSize(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 1124 {
+		t.Errorf("got size %d, want 1124", size)
+	}
+
+	// The missing dir is empty, e.g. the replica has not written any data yet.
+	if size, err = DirSize(path.Join(dir, "missing")); err != nil || size != 0 {
+		t.Errorf("got size %d and error %v, want 0 and no error", size, err)
+	}
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package term
+
+import (
+	"fmt"
+	"io"
+)
+
+// clearScreen moves the cursor to the top left and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// ClearScreen clears the terminal of w before redrawing it, e.g. in watch mode.
+func ClearScreen(w io.Writer) {
+	fmt.Fprint(w, clearScreen)
+}